@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/configopaque"
 	"go.opentelemetry.io/collector/config/configtls"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
@@ -18,6 +19,8 @@ import (
 // Config defines configuration for Elastic exporter.
 type Config struct {
 	exporterhelper.QueueSettings `mapstructure:"sending_queue"`
+	// RetrySettings retries the batches which failed to be indexed in the sync bulk mode.
+	exporterhelper.RetrySettings `mapstructure:"retry_on_failure"`
 	// Endpoints holds the Elasticsearch URLs the exporter should send events to.
 	//
 	// This setting is required if CloudID is not set and if the
@@ -49,6 +52,17 @@ type Config struct {
 	TracesIndex string `mapstructure:"traces_index"`
 	// fall back to pure TracesIndex, if 'elasticsearch.index.prefix' or 'elasticsearch.index.suffix' are not found in resource or attribute (prio: resource > attribute)
 	TracesDynamicIndex DynamicIndexSetting `mapstructure:"traces_dynamic_index"`
+	// This setting is required when metrics pipelines used.
+	MetricsIndex string `mapstructure:"metrics_index"`
+	// fall back to pure MetricsIndex, if 'elasticsearch.index.prefix' or 'elasticsearch.index.suffix' are not found in resource or attribute (prio: resource > attribute)
+	MetricsDynamicIndex DynamicIndexSetting `mapstructure:"metrics_dynamic_index"`
+
+	// DataStream routes the logs and the spans to the data streams {type}-{dataset}-{namespace},
+	// the logs_index, traces_index and their dynamic index settings are ignored when enabled.
+	DataStream DataStreamSettings `mapstructure:"data_stream"`
+
+	// ILM configures the lifecycle of the data streams and of the indices of the jaeger mapping mode per signal.
+	ILM ILMSettings `mapstructure:"ilm"`
 
 	// only works when mapping mode used `jaeger`
 	JaegerIndexAliasSettings JaegerIndexAliasSettings `mapstructure:"jaeger_index_alias"`
@@ -64,6 +78,13 @@ type Config struct {
 	Retry              RetrySettings     `mapstructure:"retry"`
 	Flush              FlushSettings     `mapstructure:"flush"`
 	Mapping            MappingsSettings  `mapstructure:"mapping"`
+
+	// BulkMode configures whether the documents are buffered by a bulk indexer shared by the batches,
+	// or indexed in bulk requests of their own the batches wait for, see the bulk modes.
+	BulkMode string `mapstructure:"bulk_mode"`
+
+	// DeadLetterQueue stores the documents which failed to be indexed and replays them.
+	DeadLetterQueue DeadLetterQueueSettings `mapstructure:"dead_letter_queue"`
 }
 
 type DynamicIndexSetting struct {
@@ -93,8 +114,70 @@ type HTTPClientSettings struct {
 type JaegerIndexAliasSettings struct {
 	Span        string `mapstructure:"span"`
 	ServiceName string `mapstructure:"service"`
-	ILM         ILM    `mapstructure:"ilm"`
-	//Dependencies string
+	// Log is the write alias of the companion index of the logs, written with the jaeger mapping mode.
+	Log string `mapstructure:"log"`
+
+	// Deprecated: `jaeger_index_alias::ilm` is deprecated and replaced with `ilm::traces` and `ilm::logs`,
+	// their unset settings are filled with it.
+	ILM ILM `mapstructure:"ilm"`
+
+	// Dependencies configures the dependency links between the services, aggregated out of the spans.
+	Dependencies JaegerDependenciesSettings `mapstructure:"dependencies"`
+}
+
+// JaegerDependenciesSettings defines how the parent/child relationships of the spans are buffered,
+// and how often the dependency links are written for the "System Architecture" view of the Jaeger UI.
+type JaegerDependenciesSettings struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// Alias is the write alias of the dependencies index.
+	Alias string `mapstructure:"alias"`
+
+	// FlushInterval is the interval the dependency links are computed and written at.
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+
+	// Window is how long the spans are kept to resolve the parents of the spans of later batches.
+	Window time.Duration `mapstructure:"window"`
+
+	// MaxSpans bounds the number of spans kept in the window, the oldest spans are evicted first.
+	MaxSpans int `mapstructure:"max_spans"`
+}
+
+// DataStreamSettings defines the data streams the logs and the spans are written to.
+//
+// https://www.elastic.co/guide/en/fleet/current/data-streams.html#data-streams-naming-scheme
+type DataStreamSettings struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// Dataset and Namespace are used when the `data_stream.dataset` and `data_stream.namespace`
+	// attributes are not found in resource or record (prio: resource > attribute).
+	Dataset   string `mapstructure:"dataset"`
+	Namespace string `mapstructure:"namespace"`
+}
+
+// ILMSettings defines the lifecycle of the rolled over indices per signal, the metrics index is not
+// rolled over.
+type ILMSettings struct {
+	Logs   ILM `mapstructure:"logs"`
+	Traces ILM `mapstructure:"traces"`
+}
+
+// ILM defines the settings and the lifecycle policy of rolled over indices, the unset settings are
+// defaulted when the policy is installed.
+type ILM struct {
+	ShardNum           int64  `mapstructure:"shards_num"`
+	ReplicaNum         int64  `mapstructure:"replica_num"`
+	RefreshInterval    string `mapstructure:"refresh_interval"`
+	TranslogDurability string `mapstructure:"translog_durability"`
+	// max_primary_shard_size: This is the maximum size of the primary shards in the index. As with max_size, replicas are ignored.
+	MaxShardsSize string `mapstructure:"max_primary_shard_size"`
+	// max_size: This is the total size of all primary shards in the index. Replicas are not counted toward the maximum index size.
+	MaxSize string `mapstructure:"max_size"`
+	MaxAge  string `mapstructure:"max_age"`
+	// WarmMinAge is the age after the rollover at which the indices become read-only, the warm phase
+	// is skipped when it is not set.
+	WarmMinAge string `mapstructure:"warm_min_age"`
+	TTL        string `mapstructure:"ttl"`
 }
 
 // AuthenticationSettings defines user authentication related settings.
@@ -157,14 +240,37 @@ type RetrySettings struct {
 	MaxInterval time.Duration `mapstructure:"max_interval"`
 }
 
+// DeadLetterQueueSettings defines where the documents which failed to be indexed are stored after the
+// retries, and how they are replayed.
+type DeadLetterQueueSettings struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// StorageID is the storage extension the documents are stored in, e.g. file_storage.
+	StorageID *component.ID `mapstructure:"storage"`
+
+	// Directory holds the files the documents are stored in when no storage extension is configured.
+	Directory string `mapstructure:"directory"`
+
+	// MaxItems bounds the number of stored documents, the documents failing while it is reached are dropped.
+	MaxItems int `mapstructure:"max_items"`
+
+	// ReplayInterval is the interval the stored documents are re-submitted at, they are only re-submitted
+	// on start and on demand when it is 0.
+	ReplayInterval time.Duration `mapstructure:"replay_interval"`
+
+	// MaxReplays is how many times a document is re-submitted before it is dropped, 0 means no limit.
+	MaxReplays int `mapstructure:"max_replays"`
+}
+
 type MappingsSettings struct {
 	// Mode configures the field mappings.
 	Mode string `mapstructure:"mode"`
 
-	// Additional field mappings.
+	// Additional field mappings, renaming the document keys and the keys prefixed with them.
+	// An empty name removes the fields.
 	Fields map[string]string `mapstructure:"fields"`
 
-	// File to read additional fields mappings from.
+	// File to read additional fields mappings from, see mappingDefinition.
 	File string `mapstructure:"file"`
 
 	// Try to find and remove duplicate fields
@@ -173,6 +279,16 @@ type MappingsSettings struct {
 	Dedot bool `mapstructure:"dedot"`
 }
 
+// Enum values for BulkMode.
+const (
+	// BulkModeAsync adds the documents to the bulk indexer and returns, the failures are logged or stored
+	// in the dead letter queue.
+	BulkModeAsync = "async"
+	// BulkModeSync indexes the documents of a batch in bulk requests of their own and returns the
+	// failures, so that the sending queue and retry_on_failure retry them.
+	BulkModeSync = "sync"
+)
+
 type MappingMode string
 
 // Enum values for MappingMode.
@@ -180,11 +296,13 @@ const (
 	MappingNone   = "none"
 	MappingECS    = "ecs"
 	MappingJaeger = "jaeger"
+	MappingOTel   = "otel"
 )
 
 var (
 	errConfigNoEndpoint    = errors.New("endpoints or cloudid must be specified")
 	errConfigEmptyEndpoint = errors.New("endpoints must not include empty entries")
+	errConfigDataStream    = errors.New("data_stream can not be enabled with the jaeger mapping mode")
 )
 
 func (m MappingMode) String() string {
@@ -195,6 +313,8 @@ func (m MappingMode) String() string {
 		return "ecs"
 	case MappingJaeger:
 		return "jaeger"
+	case MappingOTel:
+		return "otel"
 	default:
 		return ""
 	}
@@ -206,6 +326,7 @@ var mappingModes = func() map[string]MappingMode {
 		MappingNone,
 		MappingECS,
 		MappingJaeger,
+		MappingOTel,
 	} {
 		table[strings.ToLower(m.String())] = m
 	}
@@ -237,5 +358,89 @@ func (cfg *Config) Validate() error {
 		return fmt.Errorf("unknown mapping mode %v", cfg.Mapping.Mode)
 	}
 
+	if _, err := newFieldMapping(cfg.Mapping); err != nil {
+		return err
+	}
+
+	if cfg.BulkMode != BulkModeAsync && cfg.BulkMode != BulkModeSync {
+		return fmt.Errorf("unknown bulk mode %v", cfg.BulkMode)
+	}
+	if cfg.RetrySettings.Enabled && cfg.BulkMode != BulkModeSync {
+		// the async bulk mode returns the encoding errors of the batches, which would be sent again
+		return errors.New("retry_on_failure requires bulk_mode: sync")
+	}
+
+	if cfg.DataStream.Enabled {
+		if mappingModes[cfg.Mapping.Mode] == MappingJaeger {
+			return errConfigDataStream
+		}
+		if cfg.DataStream.Dataset == "" || cfg.DataStream.Namespace == "" {
+			return errors.New("data_stream dataset and namespace must not be empty")
+		}
+	}
+
+	if dependencies := cfg.JaegerIndexAliasSettings.Dependencies; dependencies.Enabled && mappingModes[cfg.Mapping.Mode] == MappingJaeger {
+		if dependencies.Alias == "" {
+			return errors.New("jaeger_index_alias::dependencies::alias must not be empty")
+		}
+		if dependencies.FlushInterval <= 0 || dependencies.Window <= 0 || dependencies.MaxSpans <= 0 {
+			return errors.New("jaeger_index_alias::dependencies flush_interval, window and max_spans must be positive")
+		}
+	}
+
+	if dlq := cfg.DeadLetterQueue; dlq.Enabled {
+		if dlq.StorageID == nil && dlq.Directory == "" {
+			return errors.New("dead_letter_queue storage or directory must be specified")
+		}
+		if dlq.MaxItems <= 0 {
+			return errors.New("dead_letter_queue::max_items must be positive")
+		}
+		if dlq.ReplayInterval < 0 || dlq.MaxReplays < 0 {
+			return errors.New("dead_letter_queue replay_interval and max_replays must not be negative")
+		}
+	}
+
 	return nil
 }
+
+// signalILM returns the lifecycle of the indices of a signal, the unset settings are filled with the
+// deprecated settings of the jaeger indices in the jaeger mapping mode, then defaulted.
+func (cfg *Config) signalILM(signal string) ILM {
+	ilm := cfg.ILM.Traces
+	if signal == dataStreamTypeLogs {
+		ilm = cfg.ILM.Logs
+	}
+	var fallback ILM
+	if mappingModes[cfg.Mapping.Mode] == MappingJaeger {
+		fallback = cfg.JaegerIndexAliasSettings.ILM
+	}
+
+	if ilm.ShardNum == 0 {
+		ilm.ShardNum = fallback.ShardNum
+	}
+	if ilm.ReplicaNum == 0 {
+		ilm.ReplicaNum = fallback.ReplicaNum
+	}
+	if ilm.RefreshInterval == "" {
+		ilm.RefreshInterval = fallback.RefreshInterval
+	}
+	if ilm.TranslogDurability == "" {
+		ilm.TranslogDurability = fallback.TranslogDurability
+	}
+	if ilm.MaxShardsSize == "" {
+		ilm.MaxShardsSize = fallback.MaxShardsSize
+	}
+	if ilm.MaxSize == "" {
+		ilm.MaxSize = fallback.MaxSize
+	}
+	if ilm.MaxAge == "" {
+		ilm.MaxAge = fallback.MaxAge
+	}
+	if ilm.WarmMinAge == "" {
+		ilm.WarmMinAge = fallback.WarmMinAge
+	}
+	if ilm.TTL == "" {
+		ilm.TTL = fallback.TTL
+	}
+	return withILMDefaults(ilm)
+}
@@ -33,12 +33,25 @@ func TestLoad_DeprecatedIndexConfigOption(t *testing.T) {
 			NumConsumers: exporterhelper.NewDefaultQueueSettings().NumConsumers,
 			QueueSize:    exporterhelper.NewDefaultQueueSettings().QueueSize,
 		},
-		Endpoints:   []string{"http://localhost:9200"},
-		CloudID:     "TRNMxjXlNJEt",
-		Index:       "my_log_index",
-		LogsIndex:   "logs-generic-default",
-		TracesIndex: "traces-generic-default",
-		Pipeline:    "mypipeline",
+		RetrySettings: exporterhelper.RetrySettings{
+			Enabled:             false,
+			InitialInterval:     exporterhelper.NewDefaultRetrySettings().InitialInterval,
+			RandomizationFactor: exporterhelper.NewDefaultRetrySettings().RandomizationFactor,
+			Multiplier:          exporterhelper.NewDefaultRetrySettings().Multiplier,
+			MaxInterval:         exporterhelper.NewDefaultRetrySettings().MaxInterval,
+			MaxElapsedTime:      exporterhelper.NewDefaultRetrySettings().MaxElapsedTime,
+		},
+		Endpoints:    []string{"http://localhost:9200"},
+		CloudID:      "TRNMxjXlNJEt",
+		Index:        "my_log_index",
+		LogsIndex:    "logs-generic-default",
+		TracesIndex:  "traces-generic-default",
+		MetricsIndex: "metrics-generic-default",
+		DataStream: DataStreamSettings{
+			Dataset:   "generic",
+			Namespace: "default",
+		},
+		Pipeline: "mypipeline",
 		HTTPClientSettings: HTTPClientSettings{
 			Authentication: AuthenticationSettings{
 				User:     "elastic",
@@ -63,10 +76,26 @@ func TestLoad_DeprecatedIndexConfigOption(t *testing.T) {
 			MaxInterval:     1 * time.Minute,
 		},
 		Mapping: MappingsSettings{
-			Mode:  "ecs",
+			Mode:  "none",
 			Dedup: true,
 			Dedot: true,
 		},
+		BulkMode: BulkModeAsync,
+		JaegerIndexAliasSettings: JaegerIndexAliasSettings{
+			Log: "jaeger-log-write",
+			Dependencies: JaegerDependenciesSettings{
+				Enabled:       true,
+				Alias:         "jaeger-dependencies-write",
+				FlushInterval: time.Minute,
+				Window:        5 * time.Minute,
+				MaxSpans:      100000,
+			},
+		},
+		DeadLetterQueue: DeadLetterQueueSettings{
+			MaxItems:       10000,
+			ReplayInterval: 5 * time.Minute,
+			MaxReplays:     5,
+		},
 	})
 }
 
@@ -95,12 +124,25 @@ func TestLoadConfig(t *testing.T) {
 					NumConsumers: exporterhelper.NewDefaultQueueSettings().NumConsumers,
 					QueueSize:    exporterhelper.NewDefaultQueueSettings().QueueSize,
 				},
-				Endpoints:   []string{"https://elastic.example.com:9200"},
-				CloudID:     "TRNMxjXlNJEt",
-				Index:       "",
-				LogsIndex:   "logs-generic-default",
-				TracesIndex: "trace_index",
-				Pipeline:    "mypipeline",
+				RetrySettings: exporterhelper.RetrySettings{
+					Enabled:             false,
+					InitialInterval:     exporterhelper.NewDefaultRetrySettings().InitialInterval,
+					RandomizationFactor: exporterhelper.NewDefaultRetrySettings().RandomizationFactor,
+					Multiplier:          exporterhelper.NewDefaultRetrySettings().Multiplier,
+					MaxInterval:         exporterhelper.NewDefaultRetrySettings().MaxInterval,
+					MaxElapsedTime:      exporterhelper.NewDefaultRetrySettings().MaxElapsedTime,
+				},
+				Endpoints:    []string{"https://elastic.example.com:9200"},
+				CloudID:      "TRNMxjXlNJEt",
+				Index:        "",
+				LogsIndex:    "logs-generic-default",
+				TracesIndex:  "trace_index",
+				MetricsIndex: "metrics-generic-default",
+				DataStream: DataStreamSettings{
+					Dataset:   "generic",
+					Namespace: "default",
+				},
+				Pipeline: "mypipeline",
 				HTTPClientSettings: HTTPClientSettings{
 					Authentication: AuthenticationSettings{
 						User:     "elastic",
@@ -129,9 +171,23 @@ func TestLoadConfig(t *testing.T) {
 					Dedup: true,
 					Dedot: true,
 				},
+				BulkMode: BulkModeAsync,
 				JaegerIndexAliasSettings: JaegerIndexAliasSettings{
 					Span:        "jaeger-span",
 					ServiceName: "jaeger-service",
+					Log:         "jaeger-log-write",
+					Dependencies: JaegerDependenciesSettings{
+						Enabled:       true,
+						Alias:         "jaeger-dependencies-write",
+						FlushInterval: time.Minute,
+						Window:        5 * time.Minute,
+						MaxSpans:      100000,
+					},
+				},
+				DeadLetterQueue: DeadLetterQueueSettings{
+					MaxItems:       10000,
+					ReplayInterval: 5 * time.Minute,
+					MaxReplays:     5,
 				},
 			},
 		},
@@ -144,12 +200,25 @@ func TestLoadConfig(t *testing.T) {
 					NumConsumers: exporterhelper.NewDefaultQueueSettings().NumConsumers,
 					QueueSize:    exporterhelper.NewDefaultQueueSettings().QueueSize,
 				},
-				Endpoints:   []string{"http://localhost:9200"},
-				CloudID:     "TRNMxjXlNJEt",
-				Index:       "",
-				LogsIndex:   "my_log_index",
-				TracesIndex: "traces-generic-default",
-				Pipeline:    "mypipeline",
+				RetrySettings: exporterhelper.RetrySettings{
+					Enabled:             false,
+					InitialInterval:     exporterhelper.NewDefaultRetrySettings().InitialInterval,
+					RandomizationFactor: exporterhelper.NewDefaultRetrySettings().RandomizationFactor,
+					Multiplier:          exporterhelper.NewDefaultRetrySettings().Multiplier,
+					MaxInterval:         exporterhelper.NewDefaultRetrySettings().MaxInterval,
+					MaxElapsedTime:      exporterhelper.NewDefaultRetrySettings().MaxElapsedTime,
+				},
+				Endpoints:    []string{"http://localhost:9200"},
+				CloudID:      "TRNMxjXlNJEt",
+				Index:        "",
+				LogsIndex:    "my_log_index",
+				TracesIndex:  "traces-generic-default",
+				MetricsIndex: "metrics-generic-default",
+				DataStream: DataStreamSettings{
+					Dataset:   "generic",
+					Namespace: "default",
+				},
+				Pipeline: "mypipeline",
 				HTTPClientSettings: HTTPClientSettings{
 					Authentication: AuthenticationSettings{
 						User:     "elastic",
@@ -174,12 +243,70 @@ func TestLoadConfig(t *testing.T) {
 					MaxInterval:     1 * time.Minute,
 				},
 				Mapping: MappingsSettings{
-					Mode:  "ecs",
+					Mode:  "none",
 					Dedup: true,
 					Dedot: true,
 				},
+				BulkMode: BulkModeAsync,
+				JaegerIndexAliasSettings: JaegerIndexAliasSettings{
+					Log: "jaeger-log-write",
+					Dependencies: JaegerDependenciesSettings{
+						Enabled:       true,
+						Alias:         "jaeger-dependencies-write",
+						FlushInterval: time.Minute,
+						Window:        5 * time.Minute,
+						MaxSpans:      100000,
+					},
+				},
+				DeadLetterQueue: DeadLetterQueueSettings{
+					MaxItems:       10000,
+					ReplayInterval: 5 * time.Minute,
+					MaxReplays:     5,
+				},
 			},
 		},
+		{
+			id:         component.NewIDWithName(metadata.Type, "data_stream"),
+			configFile: "config.yaml",
+			expected: withDefaultConfig(func(cfg *Config) {
+				cfg.Endpoints = []string{"http://localhost:9200"}
+				cfg.DataStream = DataStreamSettings{
+					Enabled:   true,
+					Dataset:   "checkout",
+					Namespace: "production",
+				}
+				cfg.ILM.Logs = ILM{
+					MaxAge:     "1d",
+					WarmMinAge: "2d",
+					TTL:        "7d",
+				}
+			}),
+		},
+		{
+			id:         component.NewIDWithName(metadata.Type, "sync"),
+			configFile: "config.yaml",
+			expected: withDefaultConfig(func(cfg *Config) {
+				cfg.Endpoints = []string{"http://localhost:9200"}
+				cfg.BulkMode = BulkModeSync
+				cfg.RetrySettings.Enabled = true
+				cfg.RetrySettings.MaxElapsedTime = 10 * time.Minute
+				cfg.QueueSettings.Enabled = true
+			}),
+		},
+		{
+			id:         component.NewIDWithName(metadata.Type, "dead_letter_queue"),
+			configFile: "config.yaml",
+			expected: withDefaultConfig(func(cfg *Config) {
+				cfg.Endpoints = []string{"http://localhost:9200"}
+				cfg.DeadLetterQueue = DeadLetterQueueSettings{
+					Enabled:        true,
+					Directory:      "/var/lib/otelcol/elasticsearch",
+					MaxItems:       500,
+					ReplayInterval: time.Minute,
+					MaxReplays:     5,
+				}
+			}),
+		},
 	}
 
 	for _, tt := range tests {
@@ -207,3 +334,90 @@ func withDefaultConfig(fns ...func(*Config)) *Config {
 	}
 	return cfg
 }
+
+func TestConfig_ValidateDataStream(t *testing.T) {
+	cfg := withDefaultConfig(func(cfg *Config) {
+		cfg.Endpoints = []string{"http://localhost:9200"}
+		cfg.DataStream.Enabled = true
+	})
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Mapping.Mode = "jaeger"
+	assert.ErrorIs(t, cfg.Validate(), errConfigDataStream)
+
+	cfg.Mapping.Mode = "ecs"
+	cfg.DataStream.Namespace = ""
+	assert.Error(t, cfg.Validate())
+}
+
+func TestConfig_ValidateMapping(t *testing.T) {
+	cfg := withDefaultConfig(func(cfg *Config) {
+		cfg.Endpoints = []string{"http://localhost:9200"}
+		cfg.Mapping.File = filepath.Join("testdata", "mapping.yaml")
+		cfg.Mapping.Fields = map[string]string{"Scope": ""}
+	})
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Mapping.Fields = map[string]string{"Scope.": "scope"}
+	assert.Error(t, cfg.Validate())
+
+	cfg.Mapping.Fields = nil
+	cfg.Mapping.File = filepath.Join("testdata", "missing.yaml")
+	assert.Error(t, cfg.Validate())
+}
+
+func TestConfig_SignalILM(t *testing.T) {
+	cfg := withDefaultConfig(func(cfg *Config) {
+		cfg.ILM.Logs = ILM{TTL: "90d", ReplicaNum: 2}
+		cfg.JaegerIndexAliasSettings.ILM = ILM{ShardNum: 3}
+	})
+	logs := cfg.signalILM(dataStreamTypeLogs)
+	assert.Equal(t, "90d", logs.TTL)
+	assert.Equal(t, int64(2), logs.ReplicaNum)
+	assert.Equal(t, "7d", logs.MaxAge)
+	// the jaeger indices settings are only used by the jaeger mapping mode
+	assert.Equal(t, int64(1), logs.ShardNum)
+	assert.Equal(t, "30d", cfg.signalILM(dataStreamTypeTraces).TTL)
+
+	cfg.Mapping.Mode = "jaeger"
+	traces := cfg.signalILM(dataStreamTypeTraces)
+	assert.Equal(t, int64(3), traces.ShardNum)
+	assert.Equal(t, int64(0), traces.ReplicaNum)
+	assert.Equal(t, "30d", traces.TTL)
+}
+
+func TestConfig_ValidateBulkMode(t *testing.T) {
+	cfg := withDefaultConfig(func(cfg *Config) {
+		cfg.Endpoints = []string{"http://localhost:9200"}
+		cfg.BulkMode = BulkModeSync
+	})
+	assert.NoError(t, cfg.Validate())
+
+	cfg.RetrySettings.Enabled = true
+	assert.NoError(t, cfg.Validate())
+
+	// the async bulk mode would send the whole batch again
+	cfg.BulkMode = BulkModeAsync
+	assert.Error(t, cfg.Validate())
+
+	cfg.BulkMode = "batch"
+	assert.Error(t, cfg.Validate())
+}
+
+func TestConfig_ValidateDeadLetterQueue(t *testing.T) {
+	cfg := withDefaultConfig(func(cfg *Config) {
+		cfg.Endpoints = []string{"http://localhost:9200"}
+		cfg.DeadLetterQueue.Enabled = true
+	})
+	assert.Error(t, cfg.Validate())
+
+	cfg.DeadLetterQueue.Directory = t.TempDir()
+	assert.NoError(t, cfg.Validate())
+
+	cfg.DeadLetterQueue.MaxItems = 0
+	assert.Error(t, cfg.Validate())
+
+	cfg.DeadLetterQueue.MaxItems = 1
+	cfg.DeadLetterQueue.MaxReplays = -1
+	assert.Error(t, cfg.Validate())
+}
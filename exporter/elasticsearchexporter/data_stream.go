@@ -0,0 +1,53 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearchexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/elasticsearchexporter"
+
+import (
+	"fmt"
+	"strings"
+)
+
+// data stream attribute key constants
+const (
+	dataStreamDataset   = "data_stream.dataset"
+	dataStreamNamespace = "data_stream.namespace"
+)
+
+// data stream types
+const (
+	dataStreamTypeLogs   = "logs"
+	dataStreamTypeTraces = "traces"
+)
+
+// dataStreamReplacer replaces the characters which are not allowed in the data stream names.
+var dataStreamReplacer = strings.NewReplacer(
+	"-", "_", "\\", "_", "/", "_", "*", "_", "?", "_", "\"", "_",
+	"<", "_", ">", "_", "|", "_", " ", "_", ",", "_", "#", "_", ":", "_",
+)
+
+// maxDataStreamFieldLength bounds the dataset and the namespace, the data stream name must not
+// be longer than 255 bytes.
+const maxDataStreamFieldLength = 100
+
+// dataStreamIndex returns the data stream {type}-{dataset}-{namespace} of a record, the dataset and
+// the namespace are retrieved out of resource and record with the settings as fallback.
+func dataStreamIndex(dsType string, settings DataStreamSettings, resource attrGetter, record attrGetter) string {
+	dataset := getFromBothResourceAndAttribute(dataStreamDataset, resource, record)
+	if dataset == "" {
+		dataset = settings.Dataset
+	}
+	namespace := getFromBothResourceAndAttribute(dataStreamNamespace, resource, record)
+	if namespace == "" {
+		namespace = settings.Namespace
+	}
+	return fmt.Sprintf("%s-%s-%s", dsType, sanitizeDataStreamField(dataset), sanitizeDataStreamField(namespace))
+}
+
+func sanitizeDataStreamField(field string) string {
+	field = dataStreamReplacer.Replace(strings.ToLower(field))
+	if len(field) > maxDataStreamFieldLength {
+		field = field[:maxDataStreamFieldLength]
+	}
+	return field
+}
@@ -0,0 +1,27 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearchexporter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func TestDataStreamIndex(t *testing.T) {
+	settings := DataStreamSettings{Enabled: true, Dataset: "generic", Namespace: "default"}
+	record := plog.NewLogRecord()
+	resource := plog.NewResourceLogs().Resource()
+	assert.Equal(t, "logs-generic-default", dataStreamIndex(dataStreamTypeLogs, settings, resource, record))
+
+	record.Attributes().PutStr(dataStreamDataset, "nginx.error")
+	record.Attributes().PutStr(dataStreamNamespace, "staging")
+	resource.Attributes().PutStr(dataStreamNamespace, "Prod:EU-1")
+	assert.Equal(t, "logs-nginx.error-prod_eu_1", dataStreamIndex(dataStreamTypeLogs, settings, resource, record))
+
+	record.Attributes().PutStr(dataStreamDataset, strings.Repeat("a", 200))
+	assert.Equal(t, "traces-"+strings.Repeat("a", maxDataStreamFieldLength)+"-prod_eu_1", dataStreamIndex(dataStreamTypeTraces, settings, resource, record))
+}
@@ -0,0 +1,650 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearchexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/elasticsearchexporter"
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// deadLetterReplayBatch is the number of documents taken out of the store at once when replaying.
+const deadLetterReplayBatch = 100
+
+// deadLetterItem is a document which failed to be indexed, with the reason of the last failure.
+type deadLetterItem struct {
+	Index     string          `json:"index"`
+	Document  json.RawMessage `json:"document"`
+	Status    int             `json:"status"`
+	Reason    string          `json:"reason"`
+	Timestamp time.Time       `json:"timestamp"`
+	Replays   int             `json:"replays"`
+}
+
+// deadLetterStore persists the encoded dead letter items in order.
+type deadLetterStore interface {
+	// append stores the items after the stored ones.
+	append(ctx context.Context, items ...[]byte) error
+	// read returns at most n of the stored items, from the offset-th one.
+	read(ctx context.Context, offset, n int) ([][]byte, error)
+	// remove removes the n first stored items.
+	remove(ctx context.Context, n int) error
+	len() int
+	close(ctx context.Context) error
+}
+
+// deadLetterQueue stores the documents which failed to be indexed after the retries, in a storage
+// extension or in a local file, and re-submits them on start, periodically and on demand with
+// ReplayDeadLetterQueue. The documents which
+// fail again are stored again until they are replayed MaxReplays times. A document is removed from
+// the store once it is indexed or stored again, so that the documents are not lost when the replay
+// fails or the collector stops, at the cost of re-submitting them again.
+type deadLetterQueue struct {
+	logger      *zap.Logger
+	settings    DeadLetterQueueSettings
+	id          component.ID
+	signal      string
+	bulkIndexer esBulkIndexerCurrent
+	now         func() time.Time
+
+	mu    sync.Mutex
+	store deadLetterStore
+	// replaying serializes the replays, which remove the items from the start of the store.
+	replaying sync.Mutex
+
+	dropped      metric.Int64Counter
+	registration metric.Registration
+	attributes   metric.MeasurementOption
+
+	// replays are the on-demand replays, run by the replay goroutine which sends back their result.
+	replays chan chan replayResult
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+type replayResult struct {
+	replayed int
+	err      error
+}
+
+// deadLetterQueues are the started queues by exporter, for the on-demand replays.
+var deadLetterQueues = struct {
+	sync.Mutex
+	byID map[component.ID][]*deadLetterQueue
+}{byID: map[component.ID][]*deadLetterQueue{}}
+
+// ReplayDeadLetterQueue re-submits the documents stored in the dead letter queues of the started
+// elasticsearch exporter id, e.g. from an extension once a mapping conflict is fixed, rather than
+// waiting for the next replay_interval. It returns the number of re-submitted documents.
+func ReplayDeadLetterQueue(ctx context.Context, id component.ID) (int, error) {
+	deadLetterQueues.Lock()
+	queues := append([]*deadLetterQueue(nil), deadLetterQueues.byID[id]...)
+	deadLetterQueues.Unlock()
+	if len(queues) == 0 {
+		return 0, fmt.Errorf("no dead letter queue started for exporter %q", id)
+	}
+	replayed := 0
+	var errs error
+	for _, q := range queues {
+		n, err := q.requestReplay(ctx)
+		replayed += n
+		errs = multierr.Append(errs, err)
+	}
+	return replayed, errs
+}
+
+// newDeadLetterQueue returns nil when the dead letter queue is disabled, the methods of a nil queue drop
+// the documents as the exporter does without dead letter queue.
+func newDeadLetterQueue(set exporter.CreateSettings, settings DeadLetterQueueSettings, signal string, bulkIndexer esBulkIndexerCurrent) (*deadLetterQueue, error) {
+	if !settings.Enabled {
+		return nil, nil
+	}
+	q := &deadLetterQueue{
+		logger:      set.Logger,
+		settings:    settings,
+		id:          set.ID,
+		signal:      signal,
+		bulkIndexer: bulkIndexer,
+		now:         time.Now,
+		attributes:  metric.WithAttributes(attribute.String("exporter", set.ID.String()), attribute.String("signal", signal)),
+		replays:     make(chan chan replayResult),
+	}
+
+	meter := set.MeterProvider.Meter("github.com/open-telemetry/opentelemetry-collector-contrib/exporter/elasticsearchexporter")
+	depth, err := meter.Int64ObservableGauge("elasticsearch_dead_letter_queue_depth",
+		metric.WithDescription("Number of documents stored in the dead letter queue"))
+	if err != nil {
+		return nil, err
+	}
+	q.registration, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		o.ObserveInt64(depth, int64(q.depth()), q.attributes)
+		return nil
+	}, depth)
+	if err != nil {
+		return nil, err
+	}
+	q.dropped, err = meter.Int64Counter("elasticsearch_dead_letter_queue_dropped",
+		metric.WithDescription("Number of documents dropped by the dead letter queue, because it was full or they were replayed too many times"))
+	if err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// start opens the store, then replays the stored documents in the background.
+func (q *deadLetterQueue) start(ctx context.Context, host component.Host) error {
+	if q == nil {
+		return nil
+	}
+	store, err := q.openStore(ctx, host)
+	if err != nil {
+		return fmt.Errorf("failed to open the dead letter queue: %w", err)
+	}
+	q.mu.Lock()
+	q.store = store
+	q.mu.Unlock()
+
+	q.stop = make(chan struct{})
+	q.done = make(chan struct{})
+	go func() {
+		defer close(q.done)
+		q.replayAndLog(context.Background())
+		// without replay interval, the documents are only re-submitted on demand
+		var tick <-chan time.Time
+		if q.settings.ReplayInterval > 0 {
+			ticker := time.NewTicker(q.settings.ReplayInterval)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+		for {
+			select {
+			case <-tick:
+				q.replayAndLog(context.Background())
+			case result := <-q.replays:
+				replayed, err := q.replayAndLog(context.Background())
+				result <- replayResult{replayed: replayed, err: err}
+			case <-q.stop:
+				return
+			}
+		}
+	}()
+
+	deadLetterQueues.Lock()
+	deadLetterQueues.byID[q.id] = append(deadLetterQueues.byID[q.id], q)
+	deadLetterQueues.Unlock()
+	return nil
+}
+
+// requestReplay runs a replay in the replay goroutine and waits for its result.
+func (q *deadLetterQueue) requestReplay(ctx context.Context) (int, error) {
+	result := make(chan replayResult, 1)
+	select {
+	case q.replays <- result:
+	case <-q.done:
+		return 0, errors.New("the dead letter queue is stopped")
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+	select {
+	case r := <-result:
+		return r.replayed, r.err
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+func (q *deadLetterQueue) openStore(ctx context.Context, host component.Host) (deadLetterStore, error) {
+	if q.settings.StorageID == nil {
+		name := strings.ReplaceAll(q.id.String(), "/", "_") + "_" + q.signal + ".jsonl"
+		return newFileDeadLetterStore(filepath.Join(q.settings.Directory, name))
+	}
+	ext, ok := host.GetExtensions()[*q.settings.StorageID]
+	if !ok {
+		return nil, fmt.Errorf("storage extension %q not found", q.settings.StorageID)
+	}
+	storageExt, ok := ext.(storage.Extension)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a storage extension", q.settings.StorageID)
+	}
+	client, err := storageExt.GetClient(ctx, component.KindExporter, q.id, "dead_letter_queue_"+q.signal)
+	if err != nil {
+		return nil, err
+	}
+	return newStorageDeadLetterStore(ctx, client)
+}
+
+// stopReplay stops the periodic replay, it is called before the bulk indexer is closed.
+func (q *deadLetterQueue) stopReplay() {
+	if q == nil || q.stop == nil {
+		return
+	}
+	deadLetterQueues.Lock()
+	queues := deadLetterQueues.byID[q.id][:0]
+	for _, other := range deadLetterQueues.byID[q.id] {
+		if other != q {
+			queues = append(queues, other)
+		}
+	}
+	if len(queues) == 0 {
+		delete(deadLetterQueues.byID, q.id)
+	} else {
+		deadLetterQueues.byID[q.id] = queues
+	}
+	deadLetterQueues.Unlock()
+
+	close(q.stop)
+	<-q.done
+	q.stop = nil
+}
+
+// shutdown closes the store, it is called after the bulk indexer is closed so that the documents failing
+// in the last bulk requests are stored.
+func (q *deadLetterQueue) shutdown(ctx context.Context) error {
+	if q == nil {
+		return nil
+	}
+	q.stopReplay()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	err := q.registration.Unregister()
+	if q.store != nil {
+		err = multierr.Append(err, q.store.close(ctx))
+		q.store = nil
+	}
+	return err
+}
+
+func (q *deadLetterQueue) depth() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.store == nil {
+		return 0
+	}
+	return q.store.len()
+}
+
+// add stores a document which failed to be indexed. It reports whether the document was stored, the
+// document is dropped when the queue is full, not started, or disabled.
+func (q *deadLetterQueue) add(ctx context.Context, item deadLetterItem) bool {
+	return q.enqueue(ctx, item, false)
+}
+
+// enqueue stores item, a replayed item is stored even when the queue is full as it takes the place of
+// its original, removed once the replay is acknowledged.
+func (q *deadLetterQueue) enqueue(ctx context.Context, item deadLetterItem, replayed bool) bool {
+	if q == nil {
+		return false
+	}
+	if item.Timestamp.IsZero() {
+		item.Timestamp = q.now().UTC()
+	}
+	encoded, err := json.Marshal(item)
+	if err == nil {
+		q.mu.Lock()
+		switch {
+		case q.store == nil:
+			err = errors.New("the dead letter queue is not started")
+		case !replayed && q.store.len() >= q.settings.MaxItems:
+			err = errors.New("the dead letter queue is full")
+		default:
+			err = q.store.append(ctx, encoded)
+		}
+		q.mu.Unlock()
+	}
+	if err != nil {
+		q.drop(ctx, item, err)
+		return false
+	}
+	return true
+}
+
+func (q *deadLetterQueue) drop(ctx context.Context, item deadLetterItem, err error) {
+	q.dropped.Add(ctx, 1, q.attributes)
+	q.logger.Error("Drop docs: failed to store the document in the dead letter queue",
+		zap.String("name", item.Index),
+		zap.Int("status", item.Status),
+		zap.String("reason", item.Reason),
+		zap.NamedError("error", err))
+}
+
+func (q *deadLetterQueue) replayAndLog(ctx context.Context) (int, error) {
+	n, err := q.replay(ctx)
+	if err != nil {
+		q.logger.Error("Failed to replay the dead letter queue", zap.Int("replayed", n), zap.Error(err))
+	} else if n > 0 {
+		q.logger.Info("Replayed the dead letter queue", zap.Int("replayed", n))
+	}
+	return n, err
+}
+
+// replay re-submits the documents stored when it is called, the documents failing again are stored
+// after them. The re-submitted documents are removed from the store once they are all acknowledged, or
+// the acknowledged ones when the replay is stopped. It returns the number of re-submitted documents.
+func (q *deadLetterQueue) replay(ctx context.Context) (int, error) {
+	q.replaying.Lock()
+	defer q.replaying.Unlock()
+
+	pending := q.depth()
+	// acks are closed once the documents are indexed, stored again or dropped, in the store order
+	var acks []chan struct{}
+	var err error
+	for len(acks) < pending {
+		q.mu.Lock()
+		var batch [][]byte
+		if q.store != nil {
+			batch, err = q.store.read(ctx, len(acks), minInt(pending-len(acks), deadLetterReplayBatch))
+		}
+		q.mu.Unlock()
+		if err != nil || len(batch) == 0 {
+			break
+		}
+
+		for _, encoded := range batch {
+			ack := make(chan struct{})
+			var item deadLetterItem
+			if err = json.Unmarshal(encoded, &item); err != nil {
+				q.logger.Error("Drop docs: invalid document in the dead letter queue", zap.Error(err))
+				err = nil
+				close(ack)
+			} else if err = q.push(ctx, item, ack); err != nil {
+				break
+			}
+			acks = append(acks, ack)
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	acked := 0
+wait:
+	for _, ack := range acks {
+		select {
+		case <-ack:
+			acked++
+		case <-ctx.Done():
+			err = multierr.Append(err, ctx.Err())
+			break wait
+		case <-q.stop:
+			// the documents not acknowledged yet are re-submitted on the next start
+			break wait
+		}
+	}
+	q.mu.Lock()
+	if q.store != nil && acked > 0 {
+		err = multierr.Append(err, q.store.remove(ctx, acked))
+	}
+	q.mu.Unlock()
+	return acked, err
+}
+
+// push re-submits a document without retrying it, the document is stored again when it fails. ack is
+// closed once the document is indexed, stored again or dropped.
+func (q *deadLetterQueue) push(ctx context.Context, item deadLetterItem, ack chan struct{}) error {
+	return q.bulkIndexer.Add(ctx, esBulkIndexerItem{
+		Action: createAction,
+		Index:  item.Index,
+		Body:   bytes.NewReader(item.Document),
+		OnSuccess: func(context.Context, esBulkIndexerItem, esBulkIndexerResponseItem) {
+			close(ack)
+		},
+		OnFailure: func(ctx context.Context, _ esBulkIndexerItem, resp esBulkIndexerResponseItem, err error) {
+			defer close(ack)
+			item.Replays++
+			item.Status = resp.Status
+			item.Reason = failureReason(resp, err)
+			item.Timestamp = q.now().UTC()
+			if q.settings.MaxReplays > 0 && item.Replays >= q.settings.MaxReplays {
+				q.drop(ctx, item, fmt.Errorf("the document was replayed %d times", item.Replays))
+				return
+			}
+			q.enqueue(ctx, item, true)
+		},
+	})
+}
+
+// failureReason describes the failure of a bulk item, the error of the response or of the request.
+func failureReason(resp esBulkIndexerResponseItem, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	if resp.Error.Type == "" {
+		return http.StatusText(resp.Status)
+	}
+	reason := resp.Error.Type + ": " + resp.Error.Reason
+	if resp.Error.Cause.Type != "" {
+		reason += " (caused by " + resp.Error.Cause.Type + ": " + resp.Error.Cause.Reason + ")"
+	}
+	return reason
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// storageDeadLetterStore stores the items in a storage extension, under the keys of their sequence
+// numbers. The sequence numbers of the first item and of the next item are stored with them.
+type storageDeadLetterStore struct {
+	client     storage.Client
+	head, tail uint64
+}
+
+const (
+	deadLetterHeadKey = "head"
+	deadLetterTailKey = "tail"
+)
+
+func newStorageDeadLetterStore(ctx context.Context, client storage.Client) (*storageDeadLetterStore, error) {
+	head, tail := storage.GetOperation(deadLetterHeadKey), storage.GetOperation(deadLetterTailKey)
+	if err := client.Batch(ctx, head, tail); err != nil {
+		return nil, err
+	}
+	s := &storageDeadLetterStore{client: client}
+	for _, field := range []struct {
+		value *uint64
+		op    storage.Operation
+	}{{&s.head, head}, {&s.tail, tail}} {
+		if field.op.Value == nil {
+			continue
+		}
+		value, err := strconv.ParseUint(string(field.op.Value), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dead letter queue %s: %w", field.op.Key, err)
+		}
+		*field.value = value
+	}
+	if s.tail < s.head {
+		return nil, fmt.Errorf("invalid dead letter queue: head %d after tail %d", s.head, s.tail)
+	}
+	return s, nil
+}
+
+func deadLetterItemKey(seq uint64) string {
+	return "item_" + strconv.FormatUint(seq, 10)
+}
+
+func (s *storageDeadLetterStore) append(ctx context.Context, items ...[]byte) error {
+	ops := make([]storage.Operation, 0, len(items)+1)
+	for i, item := range items {
+		ops = append(ops, storage.SetOperation(deadLetterItemKey(s.tail+uint64(i)), item))
+	}
+	tail := s.tail + uint64(len(items))
+	ops = append(ops, storage.SetOperation(deadLetterTailKey, []byte(strconv.FormatUint(tail, 10))))
+	if err := s.client.Batch(ctx, ops...); err != nil {
+		return err
+	}
+	s.tail = tail
+	return nil
+}
+
+func (s *storageDeadLetterStore) read(ctx context.Context, offset, n int) ([][]byte, error) {
+	start := s.head + uint64(offset)
+	if start >= s.tail {
+		return nil, nil
+	}
+	count := uint64(minInt(n, int(s.tail-start)))
+	gets := make([]storage.Operation, 0, count)
+	for seq := start; seq < start+count; seq++ {
+		gets = append(gets, storage.GetOperation(deadLetterItemKey(seq)))
+	}
+	if err := s.client.Batch(ctx, gets...); err != nil {
+		return nil, err
+	}
+
+	items := make([][]byte, 0, count)
+	for _, op := range gets {
+		if op.Value != nil {
+			items = append(items, op.Value)
+		}
+	}
+	return items, nil
+}
+
+func (s *storageDeadLetterStore) remove(ctx context.Context, n int) error {
+	count := uint64(minInt(n, s.len()))
+	deletes := make([]storage.Operation, 0, count+1)
+	for seq := s.head; seq < s.head+count; seq++ {
+		deletes = append(deletes, storage.DeleteOperation(deadLetterItemKey(seq)))
+	}
+	head := s.head + count
+	deletes = append(deletes, storage.SetOperation(deadLetterHeadKey, []byte(strconv.FormatUint(head, 10))))
+	if err := s.client.Batch(ctx, deletes...); err != nil {
+		return err
+	}
+	s.head = head
+	return nil
+}
+
+func (s *storageDeadLetterStore) len() int {
+	return int(s.tail - s.head)
+}
+
+func (s *storageDeadLetterStore) close(ctx context.Context) error {
+	return s.client.Close(ctx)
+}
+
+// fileDeadLetterStore stores the items in a local file, one item per line. The remaining items are
+// written to a new file which replaces the file when items are removed.
+type fileDeadLetterStore struct {
+	path  string
+	count int
+}
+
+func newFileDeadLetterStore(path string) (*fileDeadLetterStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	items, err := readDeadLetterFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &fileDeadLetterStore{path: path, count: len(items)}, nil
+}
+
+func readDeadLetterFile(path string) ([][]byte, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var items [][]byte
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			items = append(items, line)
+		}
+		if errors.Is(err, io.EOF) {
+			return items, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (s *fileDeadLetterStore) append(_ context.Context, items ...[]byte) error {
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	for _, item := range items {
+		buf.Write(item)
+		buf.WriteByte('\n')
+	}
+	_, err = f.Write(buf.Bytes())
+	err = multierr.Combine(err, f.Sync(), f.Close())
+	if err != nil {
+		return err
+	}
+	s.count += len(items)
+	return nil
+}
+
+func (s *fileDeadLetterStore) read(_ context.Context, offset, n int) ([][]byte, error) {
+	items, err := readDeadLetterFile(s.path)
+	if err != nil || offset >= len(items) {
+		return nil, err
+	}
+	return items[offset:minInt(offset+n, len(items))], nil
+}
+
+func (s *fileDeadLetterStore) remove(_ context.Context, n int) error {
+	items, err := readDeadLetterFile(s.path)
+	if err != nil {
+		return err
+	}
+	n = minInt(n, len(items))
+
+	tmp := s.path + ".tmp"
+	var buf bytes.Buffer
+	for _, item := range items[n:] {
+		buf.Write(item)
+		buf.WriteByte('\n')
+	}
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return err
+	}
+	s.count = len(items) - n
+	return nil
+}
+
+func (s *fileDeadLetterStore) len() int {
+	return s.count
+}
+
+func (s *fileDeadLetterStore) close(context.Context) error {
+	return nil
+}
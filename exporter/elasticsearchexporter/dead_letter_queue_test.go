@@ -0,0 +1,302 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearchexporter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// memoryStorageClient is a storage.Client keeping the values in memory.
+type memoryStorageClient struct {
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+func newMemoryStorageClient() *memoryStorageClient {
+	return &memoryStorageClient{values: map[string][]byte{}}
+}
+
+func (c *memoryStorageClient) Get(ctx context.Context, key string) ([]byte, error) {
+	op := storage.GetOperation(key)
+	err := c.Batch(ctx, op)
+	return op.Value, err
+}
+
+func (c *memoryStorageClient) Set(ctx context.Context, key string, value []byte) error {
+	return c.Batch(ctx, storage.SetOperation(key, value))
+}
+
+func (c *memoryStorageClient) Delete(ctx context.Context, key string) error {
+	return c.Batch(ctx, storage.DeleteOperation(key))
+}
+
+func (c *memoryStorageClient) Batch(_ context.Context, ops ...storage.Operation) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, op := range ops {
+		switch op.Type {
+		case storage.Get:
+			op.Value = c.values[op.Key]
+		case storage.Set:
+			c.values[op.Key] = op.Value
+		case storage.Delete:
+			delete(c.values, op.Key)
+		}
+	}
+	return nil
+}
+
+func (c *memoryStorageClient) Close(context.Context) error {
+	return nil
+}
+
+func TestDeadLetterStores(t *testing.T) {
+	client := newMemoryStorageClient()
+	path := filepath.Join(t.TempDir(), "dlq", "elasticsearch_logs.jsonl")
+
+	stores := map[string]func(t *testing.T) deadLetterStore{
+		"file": func(t *testing.T) deadLetterStore {
+			store, err := newFileDeadLetterStore(path)
+			require.NoError(t, err)
+			return store
+		},
+		"storage": func(t *testing.T) deadLetterStore {
+			store, err := newStorageDeadLetterStore(context.TODO(), client)
+			require.NoError(t, err)
+			return store
+		},
+	}
+
+	for name, open := range stores {
+		open := open
+		t.Run(name, func(t *testing.T) {
+			store := open(t)
+			assert.Equal(t, 0, store.len())
+			require.NoError(t, store.append(context.TODO(), []byte(`{"a":1}`), []byte(`{"a":2}`)))
+			require.NoError(t, store.append(context.TODO(), []byte(`{"a":3}`)))
+			assert.Equal(t, 3, store.len())
+
+			items, err := store.read(context.TODO(), 1, 5)
+			require.NoError(t, err)
+			assert.Equal(t, [][]byte{[]byte(`{"a":2}`), []byte(`{"a":3}`)}, items)
+			// the items are kept until they are removed
+			assert.Equal(t, 3, store.len())
+			require.NoError(t, store.remove(context.TODO(), 2))
+			require.NoError(t, store.close(context.TODO()))
+
+			// the remaining items are kept across restarts
+			store = open(t)
+			assert.Equal(t, 1, store.len())
+			items, err = store.read(context.TODO(), 0, 10)
+			require.NoError(t, err)
+			assert.Equal(t, [][]byte{[]byte(`{"a":3}`)}, items)
+			require.NoError(t, store.remove(context.TODO(), 10))
+			assert.Equal(t, 0, store.len())
+
+			items, err = store.read(context.TODO(), 0, 10)
+			require.NoError(t, err)
+			assert.Empty(t, items)
+			require.NoError(t, store.close(context.TODO()))
+		})
+	}
+}
+
+func TestExporter_DeadLetterQueue(t *testing.T) {
+	newTestDeadLetterQueueExporter := func(t *testing.T, url string, maxReplays int) (*elasticsearchLogsExporter, *observer.ObservedLogs) {
+		core, logs := observer.New(zap.ErrorLevel)
+		set := newTestCreateSettings(t)
+		set.Logger = zap.New(core)
+		exporter, err := newLogsExporter(set, withTestExporterConfig(func(cfg *Config) {
+			cfg.DeadLetterQueue.Enabled = true
+			cfg.DeadLetterQueue.Directory = t.TempDir()
+			cfg.DeadLetterQueue.ReplayInterval = 0
+			cfg.DeadLetterQueue.MaxReplays = maxReplays
+		})(url))
+		require.NoError(t, err)
+		require.NoError(t, exporter.deadLetterQueue.start(context.TODO(), componenttest.NewNopHost()))
+		t.Cleanup(func() {
+			require.NoError(t, exporter.Shutdown(context.TODO()))
+		})
+		return exporter, logs
+	}
+
+	t.Run("store and replay failed documents", func(t *testing.T) {
+		var failing atomic.Bool
+		failing.Store(true)
+		rec := newBulkRecorder()
+		server := newESTestServer(t, func(docs []itemRequest) ([]itemResponse, error) {
+			if failing.Load() {
+				return itemsReportStatus(docs, http.StatusBadRequest)
+			}
+			rec.Record(docs)
+			return itemsAllOK(docs)
+		})
+
+		exporter, _ := newTestDeadLetterQueueExporter(t, server.URL, 5)
+		mustSend(t, exporter, `{"message": "test1"}`)
+		require.Eventually(t, func() bool { return exporter.deadLetterQueue.depth() == 1 }, time.Second, 10*time.Millisecond)
+
+		failing.Store(false)
+		replayed, err := exporter.deadLetterQueue.replay(context.TODO())
+		require.NoError(t, err)
+		assert.Equal(t, 1, replayed)
+
+		rec.WaitItems(1)
+		assert.JSONEq(t, `{"message": "test1"}`, string(rec.Items()[0].Document))
+		assert.Equal(t, 0, exporter.deadLetterQueue.depth())
+	})
+
+	t.Run("keep documents until they are acknowledged", func(t *testing.T) {
+		var failing atomic.Bool
+		failing.Store(true)
+		release := make(chan struct{})
+		server := newESTestServer(t, func(docs []itemRequest) ([]itemResponse, error) {
+			if failing.Load() {
+				return itemsReportStatus(docs, http.StatusBadRequest)
+			}
+			<-release
+			return itemsAllOK(docs)
+		})
+
+		exporter, _ := newTestDeadLetterQueueExporter(t, server.URL, 5)
+		mustSend(t, exporter, `{"message": "test1"}`)
+		require.Eventually(t, func() bool { return exporter.deadLetterQueue.depth() == 1 }, time.Second, 10*time.Millisecond)
+
+		failing.Store(false)
+		done := make(chan int)
+		go func() {
+			replayed, err := exporter.deadLetterQueue.replay(context.TODO())
+			assert.NoError(t, err)
+			done <- replayed
+		}()
+		// the document is still stored while the bulk request is in flight
+		time.Sleep(50 * time.Millisecond)
+		assert.Equal(t, 1, exporter.deadLetterQueue.depth())
+
+		close(release)
+		assert.Equal(t, 1, <-done)
+		assert.Equal(t, 0, exporter.deadLetterQueue.depth())
+	})
+
+	t.Run("drop documents replayed too many times", func(t *testing.T) {
+		server := newESTestServer(t, func(docs []itemRequest) ([]itemResponse, error) {
+			return itemsReportStatus(docs, http.StatusBadRequest)
+		})
+
+		exporter, logs := newTestDeadLetterQueueExporter(t, server.URL, 2)
+		mustSend(t, exporter, `{"message": "test1"}`)
+		require.Eventually(t, func() bool { return exporter.deadLetterQueue.depth() == 1 }, time.Second, 10*time.Millisecond)
+
+		// the first replay stores the document again
+		_, err := exporter.deadLetterQueue.replay(context.TODO())
+		require.NoError(t, err)
+		require.Eventually(t, func() bool { return exporter.deadLetterQueue.depth() == 1 }, time.Second, 10*time.Millisecond)
+
+		exporter.deadLetterQueue.mu.Lock()
+		items, err := exporter.deadLetterQueue.store.read(context.TODO(), 0, 1)
+		exporter.deadLetterQueue.mu.Unlock()
+		require.NoError(t, err)
+		var item deadLetterItem
+		require.NoError(t, json.Unmarshal(items[0], &item))
+		assert.Equal(t, 1, item.Replays)
+		assert.Equal(t, http.StatusBadRequest, item.Status)
+
+		// the second replay drops it
+		_, err = exporter.deadLetterQueue.replay(context.TODO())
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			return logs.FilterMessage("Drop docs: failed to store the document in the dead letter queue").Len() == 1
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, 0, exporter.deadLetterQueue.depth())
+	})
+
+	t.Run("replay on demand", func(t *testing.T) {
+		var failing atomic.Bool
+		failing.Store(true)
+		rec := newBulkRecorder()
+		server := newESTestServer(t, func(docs []itemRequest) ([]itemResponse, error) {
+			if failing.Load() {
+				return itemsReportStatus(docs, http.StatusBadRequest)
+			}
+			rec.Record(docs)
+			return itemsAllOK(docs)
+		})
+
+		exporter, _ := newTestDeadLetterQueueExporter(t, server.URL, 5)
+		mustSend(t, exporter, `{"message": "test1"}`)
+		require.Eventually(t, func() bool { return exporter.deadLetterQueue.depth() == 1 }, time.Second, 10*time.Millisecond)
+
+		failing.Store(false)
+		replayed, err := ReplayDeadLetterQueue(context.TODO(), exporter.deadLetterQueue.id)
+		require.NoError(t, err)
+		assert.Equal(t, 1, replayed)
+		rec.WaitItems(1)
+		assert.Equal(t, 0, exporter.deadLetterQueue.depth())
+
+		// the queue can't be replayed once stopped
+		exporter.deadLetterQueue.stopReplay()
+		_, err = ReplayDeadLetterQueue(context.TODO(), exporter.deadLetterQueue.id)
+		assert.Error(t, err)
+	})
+
+	t.Run("store replayed documents again when full", func(t *testing.T) {
+		server := newESTestServer(t, func(docs []itemRequest) ([]itemResponse, error) {
+			return itemsReportStatus(docs, http.StatusBadRequest)
+		})
+
+		exporter, logs := newTestDeadLetterQueueExporter(t, server.URL, 5)
+		exporter.deadLetterQueue.settings.MaxItems = 2
+		for i := 0; i < 2; i++ {
+			require.True(t, exporter.deadLetterQueue.add(context.TODO(), deadLetterItem{Index: "logs", Document: json.RawMessage(`{}`)}))
+		}
+
+		// the replayed documents failing again take the place of their originals
+		replayed, err := exporter.deadLetterQueue.replay(context.TODO())
+		require.NoError(t, err)
+		assert.Equal(t, 2, replayed)
+		assert.Equal(t, 2, exporter.deadLetterQueue.depth())
+		assert.Equal(t, 0, logs.FilterMessage("Drop docs: failed to store the document in the dead letter queue").Len())
+
+		exporter.deadLetterQueue.mu.Lock()
+		items, err := exporter.deadLetterQueue.store.read(context.TODO(), 0, 2)
+		exporter.deadLetterQueue.mu.Unlock()
+		require.NoError(t, err)
+		for _, encoded := range items {
+			var item deadLetterItem
+			require.NoError(t, json.Unmarshal(encoded, &item))
+			// the documents may also be replayed by the replay on start
+			assert.GreaterOrEqual(t, item.Replays, 1)
+		}
+
+		// the queue is still full for the new documents
+		assert.False(t, exporter.deadLetterQueue.add(context.TODO(), deadLetterItem{Index: "logs", Document: json.RawMessage(`{}`)}))
+	})
+
+	t.Run("drop documents when full", func(t *testing.T) {
+		server := newESTestServer(t, func(docs []itemRequest) ([]itemResponse, error) {
+			return itemsReportStatus(docs, http.StatusBadRequest)
+		})
+
+		exporter, logs := newTestDeadLetterQueueExporter(t, server.URL, 5)
+		exporter.deadLetterQueue.settings.MaxItems = 1
+		assert.True(t, exporter.deadLetterQueue.add(context.TODO(), deadLetterItem{Index: "logs", Document: json.RawMessage(`{}`)}))
+		assert.False(t, exporter.deadLetterQueue.add(context.TODO(), deadLetterItem{Index: "logs", Document: json.RawMessage(`{}`)}))
+		assert.Equal(t, 1, exporter.deadLetterQueue.depth())
+		assert.Equal(t, 1, logs.FilterMessage("Drop docs: failed to store the document in the dead letter queue").Len())
+	})
+}
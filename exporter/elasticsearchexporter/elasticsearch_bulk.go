@@ -9,16 +9,20 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/elastic/go-elasticsearch/v7/esapi"
 	"io"
 	"net/http"
+	"sort"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
 	elasticsearch7 "github.com/elastic/go-elasticsearch/v7"
 	esutil7 "github.com/elastic/go-elasticsearch/v7/esutil"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/common/sanitize"
+	"go.uber.org/multierr"
 	"go.uber.org/zap"
 )
 
@@ -184,7 +188,15 @@ func shouldRetryEvent(status int) bool {
 	return false
 }
 
-func pushDocuments(ctx context.Context, logger *zap.Logger, index string, documentId string, document []byte, bulkIndexer esBulkIndexerCurrent, maxAttempts int) error {
+// errEmptyDocument is returned instead of adding an empty document to the bulk request.
+var errEmptyDocument = errors.New("document is empty")
+
+// pushDocuments adds a document to the bulk request. The documents failing after the retries are
+// stored in the dead letter queue, or dropped when it is nil.
+func pushDocuments(ctx context.Context, logger *zap.Logger, index string, documentId string, document []byte, bulkIndexer esBulkIndexerCurrent, maxAttempts int, deadLetterQueue *deadLetterQueue) error {
+	if len(document) == 0 {
+		return errEmptyDocument
+	}
 	attempts := 1
 	body := bytes.NewReader(document)
 	var item esutil7.BulkIndexerItem
@@ -207,6 +219,12 @@ func pushDocuments(ctx context.Context, logger *zap.Logger, index string, docume
 				_, _ = body.Seek(0, io.SeekStart)
 				_ = bulkIndexer.Add(ctx, item)
 
+			case deadLetterQueue.add(ctx, deadLetterItem{Index: index, Document: document, Status: resp.Status, Reason: failureReason(resp, err)}):
+				logger.Debug("Stored the document in the dead letter queue",
+					zap.String("name", index),
+					zap.Int("attempt", attempts),
+					zap.Int("status", resp.Status))
+
 			case resp.Status == 0 && err != nil:
 				// Encoding error. We didn't even attempt to send the event
 				logger.Error("Drop docs: failed to add docs to the bulk request buffer.",
@@ -229,3 +247,269 @@ func pushDocuments(ctx context.Context, logger *zap.Logger, index string, docume
 
 	return bulkIndexer.Add(ctx, item)
 }
+
+// noRecord is the record of the documents which don't encode a record of the batch, such as the
+// services and operations of the jaeger mapping mode.
+const noRecord = -1
+
+// bulkDocument is a document of a synchronous bulk request. Record is the position in the batch of the
+// log record, span or data point the document encodes, or noRecord.
+type bulkDocument struct {
+	index      string
+	documentID string
+	document   []byte
+	record     int
+}
+
+// bulkAction is the action line preceding a document in a bulk request.
+type bulkAction struct {
+	Create struct {
+		Index      string `json:"_index"`
+		DocumentID string `json:"_id,omitempty"`
+	} `json:"create"`
+}
+
+// bulkRequestError is returned when Elasticsearch rejects a whole bulk request.
+type bulkRequestError struct {
+	status int
+	body   string
+}
+
+func (e *bulkRequestError) Error() string {
+	return fmt.Sprintf("bulk request failed (status=%d): %s", e.status, e.body)
+}
+
+// syncBulkIndexer indexes the documents of a batch in bulk requests of their own and waits for the
+// responses, so that the failed documents are returned to the caller instead of being only logged.
+type syncBulkIndexer struct {
+	logger          *zap.Logger
+	client          *esClientCurrent
+	pipeline        string
+	timeout         time.Duration
+	flushBytes      int
+	maxAttempts     int
+	backoff         func(int) time.Duration
+	deadLetterQueue *deadLetterQueue
+}
+
+// newSyncBulkIndexer returns nil in the async bulk mode.
+func newSyncBulkIndexer(logger *zap.Logger, client *esClientCurrent, config *Config, maxAttempts int, deadLetterQueue *deadLetterQueue) *syncBulkIndexer {
+	if config.BulkMode != BulkModeSync {
+		return nil
+	}
+	return &syncBulkIndexer{
+		logger:          logger,
+		client:          client,
+		pipeline:        config.Pipeline,
+		timeout:         config.Timeout,
+		flushBytes:      config.Flush.Bytes,
+		maxAttempts:     maxAttempts,
+		backoff:         createElasticsearchBackoffFunc(&config.Retry),
+		deadLetterQueue: deadLetterQueue,
+	}
+}
+
+// index indexes the documents, the documents failing with a retryable status are retried up to
+// maxAttempts times. It returns the records of the documents which still fail with a retryable status
+// or could not be sent, and the errors of all the failed documents. The documents failing with another
+// status are stored in the dead letter queue, their errors are returned when it is disabled or full.
+// The failures of the noRecord documents are only logged, they are written again with the next
+// documents of their records.
+func (s *syncBulkIndexer) index(ctx context.Context, docs []bulkDocument) (map[int]bool, error) {
+	failed := map[int]bool{}
+	failures := bulkFailures{}
+	pending := docs[:0:0]
+	for _, doc := range docs {
+		if len(doc.document) == 0 {
+			failures.add(0, errEmptyDocument.Error())
+			continue
+		}
+		pending = append(pending, doc)
+	}
+
+	for attempt := 1; len(pending) > 0; attempt++ {
+		if attempt > 1 {
+			if err := s.wait(ctx, attempt-1); err != nil {
+				for _, doc := range pending {
+					if doc.record != noRecord {
+						failed[doc.record] = true
+					}
+				}
+				failures.add(0, err.Error())
+				break
+			}
+		}
+
+		var retry []bulkDocument
+		for _, chunk := range s.chunks(pending) {
+			items, err := s.send(ctx, chunk)
+			if err != nil {
+				var reqErr *bulkRequestError
+				retryable := !errors.As(err, &reqErr) || shouldRetryEvent(reqErr.status)
+				for _, doc := range chunk {
+					switch {
+					case doc.record == noRecord:
+						s.dropUnrecorded(doc, 0, err.Error())
+						continue
+					case retryable:
+						failed[doc.record] = true
+					}
+					failures.add(0, err.Error())
+				}
+				continue
+			}
+
+			for i, item := range items {
+				doc := chunk[i]
+				switch {
+				case item.Status >= 200 && item.Status < 300:
+				case item.Status == http.StatusConflict && doc.documentID != "":
+					// the document with this id was already created
+				case shouldRetryEvent(item.Status) && attempt < s.maxAttempts:
+					s.logger.Debug("Retrying to index",
+						zap.String("name", doc.index),
+						zap.Int("attempt", attempt),
+						zap.Int("status", item.Status))
+					retry = append(retry, doc)
+				case doc.record == noRecord:
+					s.dropUnrecorded(doc, item.Status, failureReason(item, nil))
+				case shouldRetryEvent(item.Status):
+					failed[doc.record] = true
+					failures.add(item.Status, failureReason(item, nil))
+				case s.deadLetterQueue.add(ctx, deadLetterItem{Index: doc.index, Document: doc.document, Status: item.Status, Reason: failureReason(item, nil)}):
+					s.logger.Debug("Stored the document in the dead letter queue",
+						zap.String("name", doc.index),
+						zap.Int("attempt", attempt),
+						zap.Int("status", item.Status))
+				default:
+					failures.add(item.Status, failureReason(item, nil))
+				}
+			}
+		}
+		pending = retry
+	}
+
+	return failed, failures.err()
+}
+
+// dropUnrecorded logs the failure of a noRecord document.
+func (s *syncBulkIndexer) dropUnrecorded(doc bulkDocument, status int, reason string) {
+	s.logger.Error("Drop docs: failed to index the document",
+		zap.String("name", doc.index),
+		zap.String("id", doc.documentID),
+		zap.Int("status", status),
+		zap.String("reason", reason))
+}
+
+// wait waits for the backoff of the attempt, or until the context is done.
+func (s *syncBulkIndexer) wait(ctx context.Context, attempt int) error {
+	if s.backoff == nil {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(s.backoff(attempt))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// chunks splits the documents so that the bodies of the bulk requests stay below the flush bytes.
+func (s *syncBulkIndexer) chunks(docs []bulkDocument) [][]bulkDocument {
+	if s.flushBytes <= 0 {
+		return [][]bulkDocument{docs}
+	}
+	var chunks [][]bulkDocument
+	start, size := 0, 0
+	for i, doc := range docs {
+		if size > 0 && size+len(doc.document) > s.flushBytes {
+			chunks = append(chunks, docs[start:i])
+			start, size = i, 0
+		}
+		size += len(doc.document)
+	}
+	return append(chunks, docs[start:])
+}
+
+// send sends a bulk request and returns the response items in the order of the documents.
+func (s *syncBulkIndexer) send(ctx context.Context, docs []bulkDocument) ([]esBulkIndexerResponseItem, error) {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, doc := range docs {
+		var action bulkAction
+		action.Create.Index = doc.index
+		action.Create.DocumentID = doc.documentID
+		if err := enc.Encode(action); err != nil {
+			return nil, err
+		}
+		body.Write(doc.document)
+		body.WriteByte('\n')
+	}
+
+	req := esapi.BulkRequest{Body: &body, Pipeline: s.pipeline}
+	if s.timeout > 0 {
+		req.Timeout = s.timeout
+	}
+	resp, err := req.Do(ctx, s.client)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.IsError() {
+		msg, _ := io.ReadAll(resp.Body)
+		return nil, &bulkRequestError{status: resp.StatusCode, body: string(msg)}
+	}
+
+	var blk esutil7.BulkIndexerResponse
+	if err := json.NewDecoder(resp.Body).Decode(&blk); err != nil {
+		return nil, fmt.Errorf("failed to decode the bulk response: %w", err)
+	}
+	if len(blk.Items) != len(docs) {
+		return nil, fmt.Errorf("bulk response has %d items for %d documents", len(blk.Items), len(docs))
+	}
+	items := make([]esBulkIndexerResponseItem, len(docs))
+	for i, item := range blk.Items {
+		for _, info := range item {
+			items[i] = info
+		}
+	}
+	return items, nil
+}
+
+// bulkFailures counts the failed documents per status and reason, so that the error of a batch does
+// not repeat the same reason for every document.
+type bulkFailures map[bulkFailure]int
+
+type bulkFailure struct {
+	status int
+	reason string
+}
+
+func (f bulkFailures) add(status int, reason string) {
+	f[bulkFailure{status: status, reason: reason}]++
+}
+
+func (f bulkFailures) err() error {
+	keys := make([]bulkFailure, 0, len(f))
+	for key := range f {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].status != keys[j].status {
+			return keys[i].status < keys[j].status
+		}
+		return keys[i].reason < keys[j].reason
+	})
+
+	var errs []error
+	for _, key := range keys {
+		if key.status == 0 {
+			errs = append(errs, fmt.Errorf("failed to index %d documents: %s", f[key], key.reason))
+		} else {
+			errs = append(errs, fmt.Errorf("failed to index %d documents (status=%d): %s", f[key], key.status, key.reason))
+		}
+	}
+	return multierr.Combine(errs...)
+}
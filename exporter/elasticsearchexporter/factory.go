@@ -19,8 +19,14 @@ import (
 
 const (
 	// The value of "type" key in configuration.
-	defaultLogsIndex   = "logs-generic-default"
-	defaultTracesIndex = "traces-generic-default"
+	defaultLogsIndex               = "logs-generic-default"
+	defaultTracesIndex             = "traces-generic-default"
+	defaultMetricsIndex            = "metrics-generic-default"
+	defaultJaegerLogAlias          = "jaeger-log-write"
+	defaultJaegerDependenciesAlias = "jaeger-dependencies-write"
+
+	defaultDataStreamDataset   = "generic"
+	defaultDataStreamNamespace = "default"
 )
 
 // NewFactory creates a factory for Elastic exporter.
@@ -30,20 +36,29 @@ func NewFactory() exporter.Factory {
 		createDefaultConfig,
 		exporter.WithLogs(createLogsExporter, metadata.LogsStability),
 		exporter.WithTraces(createTracesExporter, metadata.TracesStability),
+		exporter.WithMetrics(createMetricsExporter, metadata.MetricsStability),
 	)
 }
 
 func createDefaultConfig() component.Config {
 	qs := exporterhelper.NewDefaultQueueSettings()
 	qs.Enabled = false
+	rs := exporterhelper.NewDefaultRetrySettings()
+	rs.Enabled = false
 	return &Config{
 		QueueSettings: qs,
+		RetrySettings: rs,
 		HTTPClientSettings: HTTPClientSettings{
 			Timeout: 90 * time.Second,
 		},
-		Index:       "",
-		LogsIndex:   defaultLogsIndex,
-		TracesIndex: defaultTracesIndex,
+		Index:        "",
+		LogsIndex:    defaultLogsIndex,
+		TracesIndex:  defaultTracesIndex,
+		MetricsIndex: defaultMetricsIndex,
+		DataStream: DataStreamSettings{
+			Dataset:   defaultDataStreamDataset,
+			Namespace: defaultDataStreamNamespace,
+		},
 		Retry: RetrySettings{
 			Enabled:         true,
 			MaxRequests:     3,
@@ -51,10 +66,26 @@ func createDefaultConfig() component.Config {
 			MaxInterval:     1 * time.Minute,
 		},
 		Mapping: MappingsSettings{
-			Mode:  "ecs",
+			Mode:  "none",
 			Dedup: true,
 			Dedot: true,
 		},
+		BulkMode: BulkModeAsync,
+		JaegerIndexAliasSettings: JaegerIndexAliasSettings{
+			Log: defaultJaegerLogAlias,
+			Dependencies: JaegerDependenciesSettings{
+				Enabled:       true,
+				Alias:         defaultJaegerDependenciesAlias,
+				FlushInterval: time.Minute,
+				Window:        5 * time.Minute,
+				MaxSpans:      100000,
+			},
+		},
+		DeadLetterQueue: DeadLetterQueueSettings{
+			MaxItems:       10000,
+			ReplayInterval: 5 * time.Minute,
+			MaxReplays:     5,
+		},
 	}
 }
 
@@ -71,7 +102,7 @@ func createLogsExporter(
 		set.Logger.Warn("index option are deprecated and replaced with logs_index and traces_index.")
 	}
 
-	logsExporter, err := newLogsExporter(set.Logger, cf)
+	logsExporter, err := newLogsExporter(set, cf)
 	if err != nil {
 		return nil, fmt.Errorf("cannot configure Elasticsearch logs logsExporter: %w", err)
 	}
@@ -81,8 +112,10 @@ func createLogsExporter(
 		set,
 		cfg,
 		logsExporter.pushLogsData,
+		exporterhelper.WithStart(logsExporter.Start),
 		exporterhelper.WithShutdown(logsExporter.Shutdown),
 		exporterhelper.WithQueue(cf.QueueSettings),
+		exporterhelper.WithRetry(cf.RetrySettings),
 	)
 }
 
@@ -91,7 +124,7 @@ func createTracesExporter(ctx context.Context,
 	cfg component.Config) (exporter.Traces, error) {
 
 	cf := cfg.(*Config)
-	tracesExporter, err := newTracesExporter(set.Logger, cf)
+	tracesExporter, err := newTracesExporter(set, cf)
 	if err != nil {
 		return nil, fmt.Errorf("cannot configure Elasticsearch traces tracesExporter: %w", err)
 	}
@@ -100,6 +133,31 @@ func createTracesExporter(ctx context.Context,
 		set,
 		cfg,
 		tracesExporter.pushTraceData,
+		exporterhelper.WithStart(tracesExporter.Start),
 		exporterhelper.WithShutdown(tracesExporter.Shutdown),
-		exporterhelper.WithQueue(cf.QueueSettings))
+		exporterhelper.WithQueue(cf.QueueSettings),
+		exporterhelper.WithRetry(cf.RetrySettings))
+}
+
+// createMetricsExporter creates a new exporter for metrics.
+//
+// Every data point is indexed as a document into Elasticsearch.
+func createMetricsExporter(ctx context.Context,
+	set exporter.CreateSettings,
+	cfg component.Config) (exporter.Metrics, error) {
+
+	cf := cfg.(*Config)
+	metricsExporter, err := newMetricsExporter(set, cf)
+	if err != nil {
+		return nil, fmt.Errorf("cannot configure Elasticsearch metrics metricsExporter: %w", err)
+	}
+	return exporterhelper.NewMetricsExporter(
+		ctx,
+		set,
+		cfg,
+		metricsExporter.pushMetricsData,
+		exporterhelper.WithStart(metricsExporter.Start),
+		exporterhelper.WithShutdown(metricsExporter.Shutdown),
+		exporterhelper.WithQueue(cf.QueueSettings),
+		exporterhelper.WithRetry(cf.RetrySettings))
 }
@@ -0,0 +1,473 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearchexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/elasticsearchexporter"
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+	"go.uber.org/zap"
+)
+
+// indexAssets holds the templates of the ILM policies, the component templates and the index templates
+// installed by the exporter. Every asset carries a version, bumped when the asset changes.
+//
+//go:embed assets
+var indexAssets embed.FS
+
+var indexAssetFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// signalMetrics is the signal of the metrics, which are written to plain indices.
+const signalMetrics = "metrics"
+
+// The priorities of the index templates are unique, Elasticsearch rejects the templates which patterns
+// overlap with the same priority. They are higher than the priorities of the built-in templates.
+const (
+	dataStreamTemplatePriority = 200
+	indexTemplatePriority      = 210
+	jaegerTemplatePriority     = 220
+)
+
+// jaegerPolicyName is the ILM policy of the span, service and dependencies indices of the jaeger mapping mode.
+const jaegerPolicyName = "jaeger-ilm-policy"
+
+// jaegerLogPolicyName is the ILM policy of the companion index of the logs of the jaeger mapping mode.
+const jaegerLogPolicyName = "jaeger-log-ilm-policy"
+
+// jaegerTraceIndices are the rolled over indices of the spans with the jaeger mapping mode, in the
+// order of their template priorities.
+var jaegerTraceIndices = []string{"jaeger-span", "jaeger-service", "jaeger-dependencies"}
+
+// jaegerLogIndex is the rolled over companion index of the logs with the jaeger mapping mode.
+const jaegerLogIndex = "jaeger-log"
+
+// errInsufficientPrivileges fails the start of the exporter when Elasticsearch denies the management of
+// the templates, the policies or the indices.
+var errInsufficientPrivileges = errors.New("insufficient privileges, the manage_index_templates and manage_ilm " +
+	"cluster privileges and the manage privilege on the rollover indices are required")
+
+type assetKind int
+
+const (
+	ilmPolicyAsset assetKind = iota
+	componentTemplateAsset
+	indexTemplateAsset
+)
+
+func (k assetKind) String() string {
+	switch k {
+	case ilmPolicyAsset:
+		return "ILM policy"
+	case componentTemplateAsset:
+		return "component template"
+	case indexTemplateAsset:
+		return "index template"
+	default:
+		return ""
+	}
+}
+
+// assetParams are the parameters the asset templates are rendered with.
+type assetParams struct {
+	Pattern       string
+	Priority      int
+	Mappings      string
+	Policy        string
+	RolloverAlias string
+	ReadAlias     string
+	ILM           ILM
+	Fingerprint   string
+}
+
+// assetMeta is the metadata written by the exporter to its assets.
+type assetMeta struct {
+	Version     int64  `json:"version"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// assetHeader decodes the version of an asset, a template carries its version at the top level while a
+// policy carries it in its metadata.
+type assetHeader struct {
+	Version int64     `json:"version"`
+	Meta    assetMeta `json:"_meta"`
+	Policy  *struct {
+		Meta assetMeta `json:"_meta"`
+	} `json:"policy"`
+}
+
+func (h assetHeader) versionAndFingerprint() (int64, string) {
+	if h.Policy != nil {
+		return h.Policy.Meta.Version, h.Policy.Meta.Fingerprint
+	}
+	return h.Version, h.Meta.Fingerprint
+}
+
+// indexAsset is a rendered asset. Its fingerprint is the hash of the asset rendered without
+// fingerprint, it changes with the settings the asset is rendered with, e.g. the TTL of a policy.
+type indexAsset struct {
+	kind        assetKind
+	name        string
+	body        []byte
+	version     int64
+	fingerprint string
+}
+
+func renderAsset(kind assetKind, name, file string, params assetParams) (indexAsset, error) {
+	tmpl, err := template.New(path.Base(file)).Funcs(indexAssetFuncs).ParseFS(indexAssets, file)
+	if err != nil {
+		return indexAsset{}, err
+	}
+	render := func(params assetParams) ([]byte, error) {
+		var buf bytes.Buffer
+		err := tmpl.Execute(&buf, params)
+		return buf.Bytes(), err
+	}
+
+	params.Fingerprint = ""
+	unsigned, err := render(params)
+	if err != nil {
+		return indexAsset{}, err
+	}
+	hash := fnv.New64a()
+	_, _ = hash.Write(unsigned)
+	params.Fingerprint = strconv.FormatUint(hash.Sum64(), 16)
+	body, err := render(params)
+	if err != nil {
+		return indexAsset{}, err
+	}
+
+	var header assetHeader
+	if err := json.Unmarshal(body, &header); err != nil {
+		return indexAsset{}, fmt.Errorf("invalid %s %q: %w", kind, name, err)
+	}
+	version, fingerprint := header.versionAndFingerprint()
+	return indexAsset{kind: kind, name: name, body: body, version: version, fingerprint: fingerprint}, nil
+}
+
+// rolloverIndex is the first index of a rollover alias, it is created with the write alias.
+type rolloverIndex struct {
+	index string
+	alias string
+}
+
+// indexManager installs the ILM policies, the component templates and the index templates of an
+// exporter, then creates the first indices of the rollover aliases. The assets are rendered when the
+// exporter is created and applied on start: the missing assets are installed, the assets of an older
+// version or rendered with other settings are upgraded, the assets of a newer version are kept.
+type indexManager struct {
+	logger   *zap.Logger
+	client   *esClientCurrent
+	assets   []indexAsset
+	rollover []rolloverIndex
+}
+
+// newIndexManager renders the assets of a signal with the mapping mode and the data stream settings, index
+// is the index the signal is written to when the data stream mode is not enabled.
+func newIndexManager(logger *zap.Logger, client *esClientCurrent, cfg *Config, signal, index string) (*indexManager, error) {
+	m := &indexManager{logger: logger, client: client}
+	mode := mappingModes[cfg.Mapping.Mode]
+	signalPriority := map[string]int{dataStreamTypeLogs: 0, dataStreamTypeTraces: 1, signalMetrics: 2}[signal]
+
+	var err error
+	switch {
+	case signal == signalMetrics:
+		err = m.addIndexTemplate(index, index, signalMetrics, indexTemplatePriority+signalPriority)
+	case mode == MappingJaeger:
+		err = m.addJaeger(cfg, signal)
+	case cfg.DataStream.Enabled:
+		err = m.addDataStream(cfg, signal, mode)
+	case mode == MappingECS:
+		err = m.addIndexTemplate("ecs-"+index, index, MappingECS, indexTemplatePriority+signalPriority)
+	case mode == MappingOTel:
+		err = m.addIndexTemplate("otel-native-"+index, index, MappingOTel, indexTemplatePriority+signalPriority)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *indexManager) add(kind assetKind, name, file string, params assetParams) error {
+	asset, err := renderAsset(kind, name, file, params)
+	if err != nil {
+		return err
+	}
+	m.assets = append(m.assets, asset)
+	return nil
+}
+
+// addIndexTemplate adds the mappings of a mapping mode, and the template of the index. Its pattern
+// matches the dynamically prefixed and suffixed indices.
+func (m *indexManager) addIndexTemplate(name, index, mappings string, priority int) error {
+	if err := m.add(componentTemplateAsset, "otel-mappings-"+mappings, "assets/mappings/"+mappings+".json", assetParams{}); err != nil {
+		return err
+	}
+	return m.add(indexTemplateAsset, name, "assets/index.json", assetParams{
+		Pattern:  "*" + index + "*",
+		Priority: priority,
+		Mappings: "otel-mappings-" + mappings,
+	})
+}
+
+// addDataStream adds the lifecycle policy, the mappings of the mapping mode and the template of the data
+// streams of a signal.
+func (m *indexManager) addDataStream(cfg *Config, signal string, mode MappingMode) error {
+	ilm := cfg.signalILM(signal)
+	policy := "otel-" + signal + "-ilm-policy"
+	if err := m.add(ilmPolicyAsset, policy, "assets/ilm_policy.json", assetParams{ILM: ilm}); err != nil {
+		return err
+	}
+	mappings := mode.String()
+	if err := m.add(componentTemplateAsset, "otel-mappings-"+mappings, "assets/mappings/"+mappings+".json", assetParams{}); err != nil {
+		return err
+	}
+	return m.add(indexTemplateAsset, "otel-"+signal, "assets/data_stream.json", assetParams{
+		Pattern:  signal + "-*-*",
+		Priority: dataStreamTemplatePriority,
+		Mappings: "otel-mappings-" + mappings,
+		Policy:   policy,
+		ILM:      ilm,
+	})
+}
+
+// addJaeger adds the lifecycle policy, the mappings and the templates of the rolled over indices of the
+// jaeger mapping mode, and their first indices.
+func (m *indexManager) addJaeger(cfg *Config, signal string) error {
+	policy, indices, priority := jaegerPolicyName, jaegerTraceIndices, jaegerTemplatePriority
+	if signal == dataStreamTypeLogs {
+		policy, indices, priority = jaegerLogPolicyName, []string{jaegerLogIndex}, jaegerTemplatePriority+len(jaegerTraceIndices)
+	}
+	ilm := cfg.signalILM(signal)
+	if err := m.add(ilmPolicyAsset, policy, "assets/ilm_policy.json", assetParams{ILM: ilm}); err != nil {
+		return err
+	}
+	for i, index := range indices {
+		mappings := "otel-mappings-" + index
+		if err := m.add(componentTemplateAsset, mappings, "assets/jaeger/"+strings.TrimPrefix(index, "jaeger-")+".json", assetParams{}); err != nil {
+			return err
+		}
+		if err := m.add(indexTemplateAsset, index, "assets/jaeger/template.json", assetParams{
+			Pattern:       "*" + index + "-*",
+			Priority:      priority + i,
+			Mappings:      mappings,
+			Policy:        policy,
+			RolloverAlias: index + "-write",
+			ReadAlias:     index + "-read",
+			ILM:           ilm,
+		}); err != nil {
+			return err
+		}
+		m.rollover = append(m.rollover, rolloverIndex{index: index + "-000001", alias: index + "-write"})
+	}
+	return nil
+}
+
+// apply installs or upgrades the assets, then creates the first indices of the rollover aliases. It fails
+// when the privileges are insufficient, the other errors are logged so that the exporter starts while
+// Elasticsearch is unavailable.
+func (m *indexManager) apply(ctx context.Context) error {
+	for _, asset := range m.assets {
+		if err := m.applyAsset(ctx, asset); err != nil {
+			if errors.Is(err, errInsufficientPrivileges) {
+				return err
+			}
+			m.logger.Error("Failed to install the "+asset.kind.String(), zap.String("name", asset.name), zap.Error(err))
+		}
+	}
+	for _, rollover := range m.rollover {
+		if err := m.createRolloverIndex(ctx, rollover); err != nil {
+			if errors.Is(err, errInsufficientPrivileges) {
+				return err
+			}
+			m.logger.Error("Failed to create the first index of the rollover alias", zap.String("alias", rollover.alias), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+func (m *indexManager) applyAsset(ctx context.Context, asset indexAsset) error {
+	installed, found, err := m.installed(ctx, asset)
+	if err != nil {
+		return err
+	}
+	if found {
+		version, fingerprint := installed.versionAndFingerprint()
+		if version > asset.version {
+			m.logger.Debug("Kept the "+asset.kind.String()+" of a newer version", zap.String("name", asset.name), zap.Int64("version", version))
+			return nil
+		}
+		if version == asset.version && fingerprint == asset.fingerprint {
+			return nil
+		}
+	}
+
+	var resp *esapi.Response
+	switch asset.kind {
+	case ilmPolicyAsset:
+		resp, err = esapi.ILMPutLifecycleRequest{Policy: asset.name, Body: bytes.NewReader(asset.body)}.Do(ctx, m.client)
+	case componentTemplateAsset:
+		resp, err = esapi.ClusterPutComponentTemplateRequest{Name: asset.name, Body: bytes.NewReader(asset.body)}.Do(ctx, m.client)
+	case indexTemplateAsset:
+		resp, err = esapi.IndicesPutIndexTemplateRequest{Name: asset.name, Body: bytes.NewReader(asset.body)}.Do(ctx, m.client)
+	}
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.IsError() {
+		return responseError(resp, "install the "+asset.kind.String()+" "+strconv.Quote(asset.name))
+	}
+	m.logger.Info("Installed the "+asset.kind.String(), zap.String("name", asset.name), zap.Int64("version", asset.version))
+	return nil
+}
+
+// installed returns the header of the installed asset, found is false when the asset is not installed.
+func (m *indexManager) installed(ctx context.Context, asset indexAsset) (header assetHeader, found bool, err error) {
+	var resp *esapi.Response
+	switch asset.kind {
+	case ilmPolicyAsset:
+		resp, err = esapi.ILMGetLifecycleRequest{Policy: asset.name}.Do(ctx, m.client)
+	case componentTemplateAsset:
+		resp, err = esapi.ClusterGetComponentTemplateRequest{Name: []string{asset.name}}.Do(ctx, m.client)
+	case indexTemplateAsset:
+		resp, err = esapi.IndicesGetIndexTemplateRequest{Name: asset.name}.Do(ctx, m.client)
+	}
+	if err != nil {
+		return header, false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return header, false, nil
+	}
+	if resp.IsError() {
+		return header, false, responseError(resp, "get the "+asset.kind.String()+" "+strconv.Quote(asset.name))
+	}
+
+	switch asset.kind {
+	case ilmPolicyAsset:
+		var policies map[string]json.RawMessage
+		if err = json.NewDecoder(resp.Body).Decode(&policies); err != nil || policies[asset.name] == nil {
+			return header, false, err
+		}
+		err = json.Unmarshal(policies[asset.name], &header)
+		return header, err == nil, err
+	case componentTemplateAsset:
+		var templates struct {
+			ComponentTemplates []struct {
+				ComponentTemplate assetHeader `json:"component_template"`
+			} `json:"component_templates"`
+		}
+		if err = json.NewDecoder(resp.Body).Decode(&templates); err != nil || len(templates.ComponentTemplates) == 0 {
+			return header, false, err
+		}
+		return templates.ComponentTemplates[0].ComponentTemplate, true, nil
+	default:
+		var templates struct {
+			IndexTemplates []struct {
+				IndexTemplate assetHeader `json:"index_template"`
+			} `json:"index_templates"`
+		}
+		if err = json.NewDecoder(resp.Body).Decode(&templates); err != nil || len(templates.IndexTemplates) == 0 {
+			return header, false, err
+		}
+		return templates.IndexTemplates[0].IndexTemplate, true, nil
+	}
+}
+
+// createRolloverIndex creates the first index of a rollover alias when the alias does not exist.
+func (m *indexManager) createRolloverIndex(ctx context.Context, rollover rolloverIndex) error {
+	resp, err := esapi.IndicesExistsAliasRequest{Name: []string{rollover.alias}}.Do(ctx, m.client)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		return responseError(resp, "get the alias "+strconv.Quote(rollover.alias))
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"aliases": map[string]interface{}{rollover.alias: map[string]bool{"is_write_index": true}},
+	})
+	if err != nil {
+		return err
+	}
+	resp, err = esapi.IndicesCreateRequest{Index: rollover.index, Body: bytes.NewReader(body)}.Do(ctx, m.client)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.IsError() {
+		err = responseError(resp, "create the index "+strconv.Quote(rollover.index))
+		// another collector created the index concurrently
+		if strings.Contains(err.Error(), "resource_already_exists_exception") {
+			return nil
+		}
+		return err
+	}
+	m.logger.Info("Created the first index of the rollover alias", zap.String("index", rollover.index), zap.String("alias", rollover.alias))
+	return nil
+}
+
+func responseError(resp *esapi.Response, action string) error {
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("failed to %s: %w: %s", action, errInsufficientPrivileges, body)
+	}
+	return fmt.Errorf("failed to %s: %s: %s", action, resp.Status(), body)
+}
+
+// withILMDefaults fills the unset ILM settings.
+func withILMDefaults(ilm ILM) ILM {
+	if ilm.ShardNum <= 0 {
+		ilm.ShardNum = 1
+	}
+
+	if ilm.ReplicaNum < 0 {
+		ilm.ReplicaNum = 0
+	}
+
+	if len(ilm.RefreshInterval) == 0 {
+		ilm.RefreshInterval = "5s"
+	}
+	if len(ilm.TranslogDurability) == 0 {
+		ilm.TranslogDurability = "async"
+	}
+
+	if len(ilm.MaxShardsSize) == 0 {
+		ilm.MaxShardsSize = "10gb"
+	}
+
+	if len(ilm.MaxAge) == 0 {
+		ilm.MaxAge = "7d"
+	}
+
+	if len(ilm.MaxSize) == 0 {
+		ilm.MaxSize = "20gb"
+	}
+
+	if len(ilm.TTL) == 0 {
+		ilm.TTL = "30d"
+	}
+	return ilm
+}
@@ -0,0 +1,285 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearchexporter
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestNewIndexManager(t *testing.T) {
+	tests := map[string]struct {
+		signal   string
+		index    string
+		cfg      func(cfg *Config)
+		assets   []string
+		rollover []string
+	}{
+		"none mapping mode": {
+			signal: dataStreamTypeLogs,
+			index:  defaultLogsIndex,
+		},
+		"ecs mapping mode": {
+			signal: dataStreamTypeLogs,
+			index:  defaultLogsIndex,
+			cfg:    func(cfg *Config) { cfg.Mapping.Mode = "ecs" },
+			assets: []string{"component template otel-mappings-ecs", "index template ecs-logs-generic-default"},
+		},
+		"otel mapping mode with data streams": {
+			signal: dataStreamTypeTraces,
+			index:  defaultTracesIndex,
+			cfg: func(cfg *Config) {
+				cfg.Mapping.Mode = "otel"
+				cfg.DataStream.Enabled = true
+			},
+			assets: []string{"ILM policy otel-traces-ilm-policy", "component template otel-mappings-otel", "index template otel-traces"},
+		},
+		"jaeger spans": {
+			signal: dataStreamTypeTraces,
+			index:  defaultTracesIndex,
+			cfg:    func(cfg *Config) { cfg.Mapping.Mode = "jaeger" },
+			assets: []string{
+				"ILM policy jaeger-ilm-policy",
+				"component template otel-mappings-jaeger-span", "index template jaeger-span",
+				"component template otel-mappings-jaeger-service", "index template jaeger-service",
+				"component template otel-mappings-jaeger-dependencies", "index template jaeger-dependencies",
+			},
+			rollover: []string{"jaeger-span-write", "jaeger-service-write", "jaeger-dependencies-write"},
+		},
+		"jaeger logs": {
+			signal:   dataStreamTypeLogs,
+			index:    defaultLogsIndex,
+			cfg:      func(cfg *Config) { cfg.Mapping.Mode = "jaeger" },
+			assets:   []string{"ILM policy jaeger-log-ilm-policy", "component template otel-mappings-jaeger-log", "index template jaeger-log"},
+			rollover: []string{"jaeger-log-write"},
+		},
+		"metrics": {
+			signal: signalMetrics,
+			index:  defaultMetricsIndex,
+			assets: []string{"component template otel-mappings-metrics", "index template metrics-generic-default"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := withDefaultConfig(func(cfg *Config) {
+				if test.cfg != nil {
+					test.cfg(cfg)
+				}
+			})
+			m, err := newIndexManager(zap.NewNop(), nil, cfg, test.signal, test.index)
+			require.NoError(t, err)
+
+			var assets []string
+			for _, asset := range m.assets {
+				assets = append(assets, asset.kind.String()+" "+asset.name)
+				assert.True(t, json.Valid(asset.body), asset.name)
+				assert.Equal(t, int64(1), asset.version, asset.name)
+				assert.NotEmpty(t, asset.fingerprint, asset.name)
+			}
+			assert.Equal(t, test.assets, assets)
+
+			var rollover []string
+			for _, r := range m.rollover {
+				rollover = append(rollover, r.alias)
+			}
+			assert.Equal(t, test.rollover, rollover)
+		})
+	}
+}
+
+func TestIndexTemplateAssets(t *testing.T) {
+	cfg := withDefaultConfig(func(cfg *Config) {
+		cfg.Mapping.Mode = "jaeger"
+		cfg.JaegerIndexAliasSettings.ILM.ReplicaNum = 2
+	})
+	m, err := newIndexManager(zap.NewNop(), nil, cfg, dataStreamTypeTraces, defaultTracesIndex)
+	require.NoError(t, err)
+
+	var template map[string]interface{}
+	require.NoError(t, json.Unmarshal(m.assets[2].body, &template))
+	assert.Equal(t, []interface{}{"*jaeger-span-*"}, template["index_patterns"])
+	assert.Equal(t, float64(jaegerTemplatePriority), template["priority"])
+	assert.Equal(t, []interface{}{"otel-mappings-jaeger-span"}, template["composed_of"])
+	settings := template["template"].(map[string]interface{})["settings"].(map[string]interface{})["index"].(map[string]interface{})
+	assert.Equal(t, float64(2), settings["number_of_replicas"])
+	assert.Equal(t, map[string]interface{}{"name": jaegerPolicyName, "rollover_alias": "jaeger-span-write"}, settings["lifecycle"])
+	assert.Equal(t, map[string]interface{}{"jaeger-span-read": map[string]interface{}{}}, template["template"].(map[string]interface{})["aliases"])
+
+	cfg = withDefaultConfig(func(cfg *Config) {
+		cfg.Mapping.Mode = "ecs"
+		cfg.DataStream.Enabled = true
+	})
+	m, err = newIndexManager(zap.NewNop(), nil, cfg, dataStreamTypeLogs, defaultLogsIndex)
+	require.NoError(t, err)
+	var component map[string]interface{}
+	require.NoError(t, json.Unmarshal(m.assets[1].body, &component))
+	properties := component["template"].(map[string]interface{})["mappings"].(map[string]interface{})["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "long"}, properties["http.response.status_code"])
+	require.NoError(t, json.Unmarshal(m.assets[2].body, &template))
+	assert.Equal(t, []interface{}{"logs-*-*"}, template["index_patterns"])
+	assert.Equal(t, map[string]interface{}{}, template["data_stream"])
+}
+
+func TestILMPolicyAsset(t *testing.T) {
+	policy, err := renderAsset(ilmPolicyAsset, "policy", "assets/ilm_policy.json", assetParams{ILM: withILMDefaults(ILM{})})
+	require.NoError(t, err)
+	var body struct {
+		Policy struct {
+			Phases map[string]map[string]interface{} `json:"phases"`
+		} `json:"policy"`
+	}
+	require.NoError(t, json.Unmarshal(policy.body, &body))
+	assert.NotContains(t, body.Policy.Phases, "warm")
+	assert.Equal(t, "30d", body.Policy.Phases["delete"]["min_age"])
+
+	warm, err := renderAsset(ilmPolicyAsset, "policy", "assets/ilm_policy.json", assetParams{ILM: withILMDefaults(ILM{WarmMinAge: "2d"})})
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(warm.body, &body))
+	assert.Equal(t, "2d", body.Policy.Phases["warm"]["min_age"])
+	assert.Equal(t, policy.version, warm.version)
+	assert.NotEqual(t, policy.fingerprint, warm.fingerprint)
+}
+
+// fakeIndexManagementServer stores the policies, the templates and the aliases installed by the exporter.
+type fakeIndexManagementServer struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	puts    []string
+	status  int
+}
+
+func newFakeIndexManagementServer(t *testing.T) (*fakeIndexManagementServer, string) {
+	fake := &fakeIndexManagementServer{objects: map[string][]byte{}}
+	server := httptest.NewServer(http.HandlerFunc(fake.handle))
+	t.Cleanup(server.Close)
+	return fake, server.URL
+}
+
+func (f *fakeIndexManagementServer) handle(w http.ResponseWriter, req *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Add("X-Elastic-Product", "Elasticsearch")
+	if req.URL.Path == "/" {
+		_, _ = w.Write([]byte(`{"version":{"number":"` + currentESVersion + `"}}`))
+		return
+	}
+	if f.status != 0 {
+		w.WriteHeader(f.status)
+		_, _ = w.Write([]byte(`{"error":{"type":"security_exception"}}`))
+		return
+	}
+
+	if req.Method == http.MethodPut {
+		body, _ := io.ReadAll(req.Body)
+		f.objects[req.URL.Path] = body
+		f.puts = append(f.puts, req.URL.Path)
+		if !strings.HasPrefix(req.URL.Path, "/_") {
+			var index struct {
+				Aliases map[string]interface{} `json:"aliases"`
+			}
+			_ = json.Unmarshal(body, &index)
+			for alias := range index.Aliases {
+				f.objects["/_alias/"+alias] = body
+			}
+		}
+		_, _ = w.Write([]byte(`{"acknowledged":true}`))
+		return
+	}
+
+	object, ok := f.objects[req.URL.Path]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	name := req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:]
+	switch {
+	case strings.HasPrefix(req.URL.Path, "/_ilm/policy/"):
+		_, _ = w.Write([]byte(`{"` + name + `":{"version":7,` + string(object)[1:] + `}`))
+	case strings.HasPrefix(req.URL.Path, "/_component_template/"):
+		_, _ = w.Write([]byte(`{"component_templates":[{"name":"` + name + `","component_template":` + string(object) + `}]}`))
+	case strings.HasPrefix(req.URL.Path, "/_index_template/"):
+		_, _ = w.Write([]byte(`{"index_templates":[{"name":"` + name + `","index_template":` + string(object) + `}]}`))
+	}
+}
+
+func (f *fakeIndexManagementServer) takePuts() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	puts := f.puts
+	f.puts = nil
+	return puts
+}
+
+func TestIndexManagerApply(t *testing.T) {
+	fake, url := newFakeIndexManagementServer(t)
+	newManager := func(fns ...func(*Config)) *indexManager {
+		cfg := withTestExporterConfig(append([]func(*Config){func(cfg *Config) { cfg.Mapping.Mode = "jaeger" }}, fns...)...)(url)
+		client, err := newElasticsearchClient(zaptest.NewLogger(t), cfg)
+		require.NoError(t, err)
+		m, err := newIndexManager(zaptest.NewLogger(t), client, cfg, dataStreamTypeLogs, cfg.LogsIndex)
+		require.NoError(t, err)
+		return m
+	}
+
+	require.NoError(t, newManager().apply(context.TODO()))
+	assert.Equal(t, []string{
+		"/_ilm/policy/jaeger-log-ilm-policy",
+		"/_component_template/otel-mappings-jaeger-log",
+		"/_index_template/jaeger-log",
+		"/jaeger-log-000001",
+	}, fake.takePuts())
+
+	t.Run("installed assets are kept", func(t *testing.T) {
+		require.NoError(t, newManager().apply(context.TODO()))
+		assert.Empty(t, fake.takePuts())
+	})
+
+	t.Run("assets rendered with other settings are upgraded", func(t *testing.T) {
+		require.NoError(t, newManager(func(cfg *Config) { cfg.ILM.Logs.TTL = "90d" }).apply(context.TODO()))
+		assert.Equal(t, []string{"/_ilm/policy/jaeger-log-ilm-policy"}, fake.takePuts())
+	})
+
+	t.Run("assets of an older version are upgraded, of a newer version are kept", func(t *testing.T) {
+		m := newManager()
+		fake.mu.Lock()
+		fake.objects["/_index_template/jaeger-log"] = []byte(strings.Replace(string(m.assets[2].body), `"version": 1`, `"version": 2`, 1))
+		fake.objects["/_component_template/otel-mappings-jaeger-log"] = []byte(`{"version": 0, "template": {}}`)
+		fake.mu.Unlock()
+
+		require.NoError(t, m.apply(context.TODO()))
+		assert.Equal(t, []string{"/_ilm/policy/jaeger-log-ilm-policy", "/_component_template/otel-mappings-jaeger-log"}, fake.takePuts())
+	})
+
+	t.Run("insufficient privileges fail the start", func(t *testing.T) {
+		fake.mu.Lock()
+		fake.status = http.StatusForbidden
+		fake.mu.Unlock()
+
+		exporter := newTestLogsExporter(t, url, func(cfg *Config) { cfg.Mapping.Mode = "jaeger" })
+		err := exporter.Start(context.TODO(), componenttest.NewNopHost())
+		assert.ErrorIs(t, err, errInsufficientPrivileges)
+		assert.ErrorContains(t, err, `failed to get the ILM policy "jaeger-log-ilm-policy"`)
+	})
+
+	t.Run("other errors are logged", func(t *testing.T) {
+		fake.mu.Lock()
+		fake.status = http.StatusBadRequest
+		fake.mu.Unlock()
+
+		require.NoError(t, newManager().apply(context.TODO()))
+	})
+}
@@ -36,6 +36,7 @@ import (
 	"io"
 	"math"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -174,6 +175,38 @@ func (doc *Document) AddEvents(key string, events ptrace.SpanEventSlice) {
 	}
 }
 
+// Rename renames the field key and the fields prefixed with `key.`. If name is empty, the fields
+// are removed from the document.
+func (doc *Document) Rename(key, name string) {
+	fields := doc.fields[:0]
+	for _, fld := range doc.fields {
+		switch {
+		case fld.key == key:
+			fld.key = name
+		case len(fld.key) > len(key) && strings.HasPrefix(fld.key, key) && fld.key[len(key)] == '.':
+			if name != "" {
+				fld.key = name + fld.key[len(key):]
+			} else {
+				fld.key = ""
+			}
+		}
+		if fld.key != "" {
+			fields = append(fields, fld)
+		}
+	}
+	doc.fields = fields
+}
+
+// Convert converts the values of the field key to kind. Only KindString, KindInt, KindDouble and
+// KindBool are supported, values that can not be converted are kept as is.
+func (doc *Document) Convert(key string, kind Kind) {
+	for i := range doc.fields {
+		if doc.fields[i].key == key {
+			doc.fields[i].value = doc.fields[i].value.convert(kind)
+		}
+	}
+}
+
 // Sort sorts all fields in the document by key name.
 func (doc *Document) Sort() {
 	sort.SliceStable(doc.fields, func(i, j int) bool {
@@ -443,6 +476,54 @@ func (v *Value) Dedup() {
 	}
 }
 
+func (v Value) convert(kind Kind) Value {
+	if v.kind == kind {
+		return v
+	}
+	if v.kind == KindArr {
+		arr := make([]Value, len(v.arr))
+		for i := range v.arr {
+			arr[i] = v.arr[i].convert(kind)
+		}
+		return ArrValue(arr...)
+	}
+
+	var str string
+	switch v.kind {
+	case KindString:
+		str = v.str
+	case KindInt:
+		str = strconv.FormatInt(int64(v.primitive), 10)
+	case KindDouble:
+		str = strconv.FormatFloat(v.dbl, 'f', -1, 64)
+	case KindBool:
+		str = strconv.FormatBool(v.primitive == 1)
+	default:
+		return v
+	}
+
+	switch kind {
+	case KindString:
+		return StringValue(str)
+	case KindInt:
+		if v.kind == KindDouble {
+			return IntValue(int64(v.dbl))
+		}
+		if i, err := strconv.ParseInt(str, 10, 64); err == nil {
+			return IntValue(i)
+		}
+	case KindDouble:
+		if d, err := strconv.ParseFloat(str, 64); err == nil {
+			return DoubleValue(d)
+		}
+	case KindBool:
+		if b, err := strconv.ParseBool(str); err == nil {
+			return BoolValue(b)
+		}
+	}
+	return v
+}
+
 func (v *Value) IsEmpty() bool {
 	switch v.kind {
 	case KindNil, KindIgnore:
@@ -120,6 +120,67 @@ func TestDocument_Sort(t *testing.T) {
 
 }
 
+func TestDocument_Rename(t *testing.T) {
+	tests := map[string]struct {
+		key, name string
+		want      Document
+	}{
+		"rename field": {
+			key: "Resource.service.name", name: "service.name",
+			want: Document{[]field{{"service.name", StringValue("checkout")}, {"Resource.service.version", StringValue("1.0")}, {"Resource.services", IntValue(2)}}},
+		},
+		"rename prefixed fields": {
+			key: "Resource", name: "resource",
+			want: Document{[]field{{"resource.service.name", StringValue("checkout")}, {"resource.service.version", StringValue("1.0")}, {"resource.services", IntValue(2)}}},
+		},
+		"prefix must end at a dot": {
+			key: "Resource.service", name: "service",
+			want: Document{[]field{{"service.name", StringValue("checkout")}, {"service.version", StringValue("1.0")}, {"Resource.services", IntValue(2)}}},
+		},
+		"remove fields": {
+			key: "Resource.service", name: "",
+			want: Document{[]field{{"Resource.services", IntValue(2)}}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var doc Document
+			doc.AddString("Resource.service.name", "checkout")
+			doc.AddString("Resource.service.version", "1.0")
+			doc.AddInt("Resource.services", 2)
+			doc.Rename(test.key, test.name)
+			assert.Equal(t, test.want, doc)
+		})
+	}
+}
+
+func TestDocument_Convert(t *testing.T) {
+	tests := map[string]struct {
+		value Value
+		kind  Kind
+		want  Value
+	}{
+		"string to int":       {value: StringValue("200"), kind: KindInt, want: IntValue(200)},
+		"invalid int is kept": {value: StringValue("OK"), kind: KindInt, want: StringValue("OK")},
+		"double to int":       {value: DoubleValue(1.5), kind: KindInt, want: IntValue(1)},
+		"int to string":       {value: IntValue(404), kind: KindString, want: StringValue("404")},
+		"string to double":    {value: StringValue("0.25"), kind: KindDouble, want: DoubleValue(0.25)},
+		"string to bool":      {value: StringValue("true"), kind: KindBool, want: BoolValue(true)},
+		"array elements":      {value: ArrValue(StringValue("1"), StringValue("2")), kind: KindInt, want: ArrValue(IntValue(1), IntValue(2))},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var doc Document
+			doc.Add("key", test.value)
+			doc.Add("other", test.value)
+			doc.Convert("key", test.kind)
+			assert.Equal(t, Document{[]field{{"key", test.want}, {"other", test.value}}}, doc)
+		})
+	}
+}
+
 func TestObjectModel_Dedup(t *testing.T) {
 	tests := map[string]struct {
 		build func() Document
@@ -0,0 +1,201 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearchexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/elasticsearchexporter"
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	depmodel "github.com/jaegertracing/jaeger/plugin/storage/es/dependencystore/dbmodel"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+)
+
+type dependencySpanKey struct {
+	traceID pcommon.TraceID
+	spanID  pcommon.SpanID
+}
+
+type dependencySpan struct {
+	service string
+	seen    time.Time
+}
+
+type dependencyEntry struct {
+	key  dependencySpanKey
+	seen time.Time
+}
+
+// dependencyChild is a span which parent was not seen yet.
+type dependencyChild struct {
+	parent  dependencySpanKey
+	service string
+	seen    time.Time
+}
+
+// dependencyAggregator counts the calls between the services out of the parent/child relationships
+// of the spans, and periodically writes them to the dependencies index read by the Jaeger UI.
+//
+// The services of the spans are kept in a window bounded in time and size, so that the parents and
+// the children exported in different batches are linked. The calls within a service are not counted.
+type dependencyAggregator struct {
+	logger      *zap.Logger
+	settings    JaegerDependenciesSettings
+	bulkIndexer esBulkIndexerCurrent
+	maxAttempts int
+	dlq         *deadLetterQueue
+	now         func() time.Time
+
+	mu      sync.Mutex
+	spans   map[dependencySpanKey]dependencySpan
+	order   []dependencyEntry
+	pending []dependencyChild
+	links   map[[2]string]uint64
+
+	started bool
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+func newDependencyAggregator(logger *zap.Logger, settings JaegerDependenciesSettings, bulkIndexer esBulkIndexerCurrent, maxAttempts int, dlq *deadLetterQueue) *dependencyAggregator {
+	return &dependencyAggregator{
+		logger:      logger,
+		settings:    settings,
+		bulkIndexer: bulkIndexer,
+		maxAttempts: maxAttempts,
+		dlq:         dlq,
+		now:         time.Now,
+		spans:       map[dependencySpanKey]dependencySpan{},
+		links:       map[[2]string]uint64{},
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// add records the service of the span, and the call from its parent when the parent was seen.
+func (a *dependencyAggregator) add(service string, span ptrace.Span) {
+	if service == "" {
+		return
+	}
+	now := a.now()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !span.ParentSpanID().IsEmpty() {
+		parentKey := dependencySpanKey{traceID: span.TraceID(), spanID: span.ParentSpanID()}
+		if parent, ok := a.spans[parentKey]; ok {
+			a.link(parent.service, service)
+		} else if len(a.pending) < a.settings.MaxSpans {
+			a.pending = append(a.pending, dependencyChild{parent: parentKey, service: service, seen: now})
+		}
+	}
+
+	key := dependencySpanKey{traceID: span.TraceID(), spanID: span.SpanID()}
+	if _, ok := a.spans[key]; !ok {
+		a.spans[key] = dependencySpan{service: service, seen: now}
+		a.order = append(a.order, dependencyEntry{key: key, seen: now})
+		for len(a.spans) > a.settings.MaxSpans {
+			a.evictOldest()
+		}
+	}
+}
+
+// evictOldest removes the oldest span of the window, the entries of the spans which were removed
+// and added again are skipped.
+func (a *dependencyAggregator) evictOldest() {
+	entry := a.order[0]
+	a.order = a.order[1:]
+	if span, ok := a.spans[entry.key]; ok && span.seen.Equal(entry.seen) {
+		delete(a.spans, entry.key)
+	}
+}
+
+func (a *dependencyAggregator) link(parent, child string) {
+	if parent != child {
+		a.links[[2]string{parent, child}]++
+	}
+}
+
+// collect links the pending children to their parents, expires the spans out of the window, and
+// returns the links counted since the last collect.
+func (a *dependencyAggregator) collect() []depmodel.DependencyLink {
+	now := a.now()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	pending := a.pending[:0]
+	for _, child := range a.pending {
+		if parent, ok := a.spans[child.parent]; ok {
+			a.link(parent.service, child.service)
+		} else if now.Sub(child.seen) < a.settings.Window {
+			pending = append(pending, child)
+		}
+	}
+	a.pending = pending
+
+	for len(a.order) > 0 && now.Sub(a.order[0].seen) >= a.settings.Window {
+		a.evictOldest()
+	}
+
+	links := make([]depmodel.DependencyLink, 0, len(a.links))
+	for link, count := range a.links {
+		links = append(links, depmodel.DependencyLink{Parent: link[0], Child: link[1], CallCount: count})
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].Parent != links[j].Parent {
+			return links[i].Parent < links[j].Parent
+		}
+		return links[i].Child < links[j].Child
+	})
+	a.links = map[[2]string]uint64{}
+	return links
+}
+
+// flush writes the links counted since the last flush, nothing is written if there is none.
+func (a *dependencyAggregator) flush(ctx context.Context) error {
+	links := a.collect()
+	if len(links) == 0 {
+		return nil
+	}
+	document, err := json.Marshal(depmodel.TimeDependencies{Timestamp: a.now().UTC(), Dependencies: links})
+	if err != nil {
+		return err
+	}
+	return pushDocuments(ctx, a.logger, a.settings.Alias, "", document, a.bulkIndexer, a.maxAttempts, a.dlq)
+}
+
+func (a *dependencyAggregator) start() {
+	a.started = true
+	go func() {
+		defer close(a.done)
+		ticker := time.NewTicker(a.settings.FlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := a.flush(context.Background()); err != nil {
+					a.logger.Error("Failed to write the jaeger dependencies", zap.Error(err))
+				}
+			case <-a.stop:
+				return
+			}
+		}
+	}()
+}
+
+// shutdown stops the periodic flush, then writes the remaining links.
+func (a *dependencyAggregator) shutdown(ctx context.Context) error {
+	if a.started {
+		close(a.stop)
+		<-a.done
+		a.started = false
+	}
+	return a.flush(ctx)
+}
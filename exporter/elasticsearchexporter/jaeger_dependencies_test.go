@@ -0,0 +1,148 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearchexporter
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	depmodel "github.com/jaegertracing/jaeger/plugin/storage/es/dependencystore/dbmodel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+)
+
+func newTestDependencySpan(traceID byte, spanID byte, parentID byte) ptrace.Span {
+	span := ptrace.NewSpan()
+	span.SetTraceID([16]byte{traceID})
+	span.SetSpanID([8]byte{spanID})
+	if parentID != 0 {
+		span.SetParentSpanID([8]byte{parentID})
+	}
+	return span
+}
+
+func newTestDependencyAggregator(now *time.Time, maxSpans int) *dependencyAggregator {
+	aggregator := newDependencyAggregator(zap.NewNop(), JaegerDependenciesSettings{
+		Enabled:       true,
+		Alias:         "jaeger-dependencies-write",
+		FlushInterval: time.Minute,
+		Window:        5 * time.Minute,
+		MaxSpans:      maxSpans,
+	}, nil, 1, nil)
+	aggregator.now = func() time.Time { return *now }
+	return aggregator
+}
+
+func TestDependencyAggregator(t *testing.T) {
+	now := time.Date(2023, 4, 19, 3, 4, 5, 0, time.UTC)
+
+	t.Run("links parents and children across batches", func(t *testing.T) {
+		aggregator := newTestDependencyAggregator(&now, 100)
+		aggregator.add("frontend", newTestDependencySpan(1, 1, 0))
+		aggregator.add("cart", newTestDependencySpan(1, 2, 1))
+		aggregator.add("cart", newTestDependencySpan(1, 3, 2))
+		// the child is exported before its parent
+		aggregator.add("db", newTestDependencySpan(1, 5, 4))
+		aggregator.add("cart", newTestDependencySpan(1, 4, 2))
+		aggregator.add("frontend", newTestDependencySpan(2, 2, 1))
+
+		assert.Equal(t, []depmodel.DependencyLink{
+			{Parent: "cart", Child: "db", CallCount: 1},
+			{Parent: "frontend", Child: "cart", CallCount: 1},
+		}, aggregator.collect())
+		assert.Empty(t, aggregator.collect())
+
+		aggregator.add("db", newTestDependencySpan(1, 6, 3))
+		assert.Equal(t, []depmodel.DependencyLink{{Parent: "cart", Child: "db", CallCount: 1}}, aggregator.collect())
+	})
+
+	t.Run("expires the spans out of the window", func(t *testing.T) {
+		aggregator := newTestDependencyAggregator(&now, 100)
+		aggregator.add("frontend", newTestDependencySpan(1, 1, 0))
+		aggregator.add("db", newTestDependencySpan(1, 3, 2))
+		now = now.Add(5 * time.Minute)
+		assert.Empty(t, aggregator.collect())
+		assert.Empty(t, aggregator.spans)
+		assert.Empty(t, aggregator.pending)
+
+		aggregator.add("cart", newTestDependencySpan(1, 2, 1))
+		assert.Empty(t, aggregator.collect())
+	})
+
+	t.Run("bounds the number of spans", func(t *testing.T) {
+		aggregator := newTestDependencyAggregator(&now, 2)
+		aggregator.add("frontend", newTestDependencySpan(1, 1, 0))
+		aggregator.add("cart", newTestDependencySpan(1, 2, 0))
+		aggregator.add("db", newTestDependencySpan(1, 3, 0))
+		assert.Len(t, aggregator.spans, 2)
+
+		// the parent of the first child was evicted
+		aggregator.add("cart", newTestDependencySpan(1, 4, 1))
+		aggregator.add("cache", newTestDependencySpan(1, 5, 3))
+		assert.Equal(t, []depmodel.DependencyLink{{Parent: "db", Child: "cache", CallCount: 1}}, aggregator.collect())
+		assert.Len(t, aggregator.spans, 2)
+		assert.Len(t, aggregator.pending, 1)
+	})
+
+	t.Run("skips the spans without service", func(t *testing.T) {
+		aggregator := newTestDependencyAggregator(&now, 100)
+		aggregator.add("", newTestDependencySpan(1, 1, 0))
+		assert.Empty(t, aggregator.spans)
+	})
+}
+
+func TestDependencyAggregatorFlush(t *testing.T) {
+	rec := newBulkRecorder()
+	server := newESTestServer(t, func(docs []itemRequest) ([]itemResponse, error) {
+		rec.Record(docs)
+		return itemsAllOK(docs)
+	})
+
+	exporter := newTestTracesExporter(t, server.URL, func(cfg *Config) {
+		cfg.Mapping.Mode = "jaeger"
+		cfg.JaegerIndexAliasSettings.Span = "jaeger-span-write"
+		cfg.JaegerIndexAliasSettings.ServiceName = "jaeger-service-write"
+	})
+	require.NotNil(t, exporter.dependencies)
+
+	for _, batch := range []struct {
+		service string
+		span    ptrace.Span
+	}{
+		{service: "frontend", span: newTestDependencySpan(1, 1, 0)},
+		{service: "cart", span: newTestDependencySpan(1, 2, 1)},
+	} {
+		traces := ptrace.NewTraces()
+		resourceSpans := traces.ResourceSpans().AppendEmpty()
+		resourceSpans.Resource().Attributes().PutStr("service.name", batch.service)
+		batch.span.SetName("operation")
+		batch.span.SetStartTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+		batch.span.CopyTo(resourceSpans.ScopeSpans().AppendEmpty().Spans().AppendEmpty())
+		require.NoError(t, exporter.pushTraceData(context.TODO(), traces))
+	}
+	require.NoError(t, exporter.dependencies.flush(context.TODO()))
+
+	rec.WaitItems(5)
+	var dependencies []depmodel.TimeDependencies
+	for _, item := range rec.Items() {
+		action := map[string]map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(item.Action, &action))
+		if action["create"]["_index"] != "jaeger-dependencies-write" {
+			continue
+		}
+		var document depmodel.TimeDependencies
+		require.NoError(t, json.Unmarshal(item.Document, &document))
+		dependencies = append(dependencies, document)
+	}
+	require.Len(t, dependencies, 1)
+	assert.Equal(t, []depmodel.DependencyLink{{Parent: "frontend", Child: "cart", CallCount: 1}}, dependencies[0].Dependencies)
+
+	// nothing is written without new links
+	require.NoError(t, exporter.dependencies.flush(context.TODO()))
+}
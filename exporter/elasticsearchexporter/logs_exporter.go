@@ -9,6 +9,9 @@ import (
 	"context"
 	"fmt"
 
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.uber.org/multierr"
@@ -20,11 +23,15 @@ type elasticsearchLogsExporter struct {
 
 	index        string
 	dynamicIndex bool
+	dataStream   DataStreamSettings
 	maxAttempts  int
 
-	client      *esClientCurrent
-	bulkIndexer esBulkIndexerCurrent
-	model       mappingModel
+	client          *esClientCurrent
+	bulkIndexer     esBulkIndexerCurrent
+	model           mappingModel
+	indexManager    *indexManager
+	deadLetterQueue *deadLetterQueue
+	syncBulkIndexer *syncBulkIndexer
 }
 
 var retryOnStatus = []int{500, 502, 503, 504, 429}
@@ -34,10 +41,11 @@ const (
 	updateAction = "update"
 )
 
-func newLogsExporter(logger *zap.Logger, cfg *Config) (*elasticsearchLogsExporter, error) {
+func newLogsExporter(set exporter.CreateSettings, cfg *Config) (*elasticsearchLogsExporter, error) {
 	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
+	logger := set.Logger
 
 	client, err := newElasticsearchClient(logger, cfg)
 	if err != nil {
@@ -49,35 +57,78 @@ func newLogsExporter(logger *zap.Logger, cfg *Config) (*elasticsearchLogsExporte
 		return nil, err
 	}
 
+	mapping, err := newFieldMapping(cfg.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
 	maxAttempts := 1
 	if cfg.Retry.Enabled {
 		maxAttempts = cfg.Retry.MaxRequests
 	}
 
-	model := &encodeModel{dedup: cfg.Mapping.Dedup, dedot: cfg.Mapping.Dedot}
-
 	indexStr := cfg.LogsIndex
 	if cfg.Index != "" {
 		indexStr = cfg.Index
 	}
+
+	var model mappingModel = &encodeModel{dedup: cfg.Mapping.Dedup, dedot: cfg.Mapping.Dedot, mapping: mapping}
+	switch mappingModes[cfg.Mapping.Mode] {
+	case MappingECS:
+		model = &encodeECSModel{encodeModel{dedup: cfg.Mapping.Dedup, dedot: cfg.Mapping.Dedot, mapping: mapping}}
+	case MappingOTel:
+		model = &encodeOTelModel{encodeModel{dedup: cfg.Mapping.Dedup, dedot: cfg.Mapping.Dedot, mapping: mapping}}
+	case MappingJaeger:
+		model = NewEncodeJaegerModel()
+		indexStr = cfg.JaegerIndexAliasSettings.Log
+	}
+
+	indexManager, err := newIndexManager(logger, client, cfg, dataStreamTypeLogs, indexStr)
+	if err != nil {
+		return nil, err
+	}
+
+	deadLetterQueue, err := newDeadLetterQueue(set, cfg.DeadLetterQueue, dataStreamTypeLogs, bulkIndexer)
+	if err != nil {
+		return nil, err
+	}
+
 	esLogsExp := &elasticsearchLogsExporter{
 		logger:      logger,
 		client:      client,
 		bulkIndexer: bulkIndexer,
 
-		index:        indexStr,
-		dynamicIndex: cfg.LogsDynamicIndex.Enabled,
-		maxAttempts:  maxAttempts,
-		model:        model,
+		index:           indexStr,
+		dynamicIndex:    cfg.LogsDynamicIndex.Enabled,
+		dataStream:      cfg.DataStream,
+		maxAttempts:     maxAttempts,
+		model:           model,
+		indexManager:    indexManager,
+		deadLetterQueue: deadLetterQueue,
+		syncBulkIndexer: newSyncBulkIndexer(logger, client, cfg, maxAttempts, deadLetterQueue),
 	}
 	return esLogsExp, nil
 }
 
+// Start installs the templates of the data streams when the data stream mode is enabled, the mappings
+// of the ecs and otel mapping modes, and the companion index of the logs with the jaeger mapping mode,
+// then opens the dead letter queue.
+func (e *elasticsearchLogsExporter) Start(ctx context.Context, host component.Host) error {
+	if err := e.indexManager.apply(ctx); err != nil {
+		return err
+	}
+	return e.deadLetterQueue.start(ctx, host)
+}
+
 func (e *elasticsearchLogsExporter) Shutdown(ctx context.Context) error {
-	return e.bulkIndexer.Close(ctx)
+	e.deadLetterQueue.stopReplay()
+	return multierr.Combine(e.bulkIndexer.Close(ctx), e.deadLetterQueue.shutdown(ctx))
 }
 
 func (e *elasticsearchLogsExporter) pushLogsData(ctx context.Context, ld plog.Logs) error {
+	if e.syncBulkIndexer != nil {
+		return e.pushLogsDataSync(ctx, ld)
+	}
 	var errs []error
 
 	rls := ld.ResourceLogs()
@@ -88,7 +139,7 @@ func (e *elasticsearchLogsExporter) pushLogsData(ctx context.Context, ld plog.Lo
 		for j := 0; j < ills.Len(); j++ {
 			logs := ills.At(j).LogRecords()
 			for k := 0; k < logs.Len(); k++ {
-				if err := e.pushLogRecord(ctx, resource, logs.At(k)); err != nil {
+				if err := e.pushLogRecord(ctx, resource, ills.At(j).Scope(), logs.At(k)); err != nil {
 					if cerr := ctx.Err(); cerr != nil {
 						return cerr
 					}
@@ -102,18 +153,87 @@ func (e *elasticsearchLogsExporter) pushLogsData(ctx context.Context, ld plog.Lo
 	return multierr.Combine(errs...)
 }
 
-func (e *elasticsearchLogsExporter) pushLogRecord(ctx context.Context, resource pcommon.Resource, record plog.LogRecord) error {
+// pushLogsDataSync indexes the log records in bulk requests of their own. The records failing with a
+// retryable status are returned in a consumererror.Logs for the exporterhelper to retry them, the
+// other failures are permanent.
+func (e *elasticsearchLogsExporter) pushLogsDataSync(ctx context.Context, ld plog.Logs) error {
+	var errs []error
+	var docs []bulkDocument
+
+	record := 0
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		resource := rl.Resource()
+		ills := rl.ScopeLogs()
+		for j := 0; j < ills.Len(); j++ {
+			logs := ills.At(j).LogRecords()
+			for k := 0; k < logs.Len(); k++ {
+				index, document, err := e.encodeLogRecord(resource, ills.At(j).Scope(), logs.At(k))
+				if err != nil {
+					errs = append(errs, err)
+				} else {
+					docs = append(docs, bulkDocument{index: index, document: document, record: record})
+				}
+				record++
+			}
+		}
+	}
+
+	failed, err := e.syncBulkIndexer.index(ctx, docs)
+	err = multierr.Combine(append(errs, err)...)
+	switch {
+	case len(failed) > 0:
+		return consumererror.NewLogs(err, logsSubset(ld, failed))
+	case err != nil:
+		return consumererror.NewPermanent(err)
+	}
+	return nil
+}
+
+func (e *elasticsearchLogsExporter) pushLogRecord(ctx context.Context, resource pcommon.Resource, scope pcommon.InstrumentationScope, record plog.LogRecord) error {
+	index, document, err := e.encodeLogRecord(resource, scope, record)
+	if err != nil {
+		return err
+	}
+	return pushDocuments(ctx, e.logger, index, "", document, e.bulkIndexer, e.maxAttempts, e.deadLetterQueue)
+}
+
+// encodeLogRecord returns the index and the document of a log record.
+func (e *elasticsearchLogsExporter) encodeLogRecord(resource pcommon.Resource, scope pcommon.InstrumentationScope, record plog.LogRecord) (string, []byte, error) {
 	fIndex := e.index
-	if e.dynamicIndex {
+	if e.dataStream.Enabled {
+		fIndex = dataStreamIndex(dataStreamTypeLogs, e.dataStream, resource, record)
+	} else if e.dynamicIndex {
 		prefix := getFromBothResourceAndAttribute(indexPrefix, resource, record)
 		suffix := getFromBothResourceAndAttribute(indexSuffix, resource, record)
 
 		fIndex = fmt.Sprintf("%s%s%s", prefix, fIndex, suffix)
 	}
 
-	document, err := e.model.encodeLog(resource, record)
+	document, err := e.model.encodeLog(resource, scope, record)
 	if err != nil {
-		return fmt.Errorf("Failed to encode log event: %w", err)
+		return "", nil, fmt.Errorf("Failed to encode log event: %w", err)
 	}
-	return pushDocuments(ctx, e.logger, fIndex, "", document, e.bulkIndexer, e.maxAttempts)
+	return fIndex, document, nil
+}
+
+// logsSubset returns a copy of the batch holding the log records at the given positions.
+func logsSubset(ld plog.Logs, records map[int]bool) plog.Logs {
+	subset := plog.NewLogs()
+	ld.CopyTo(subset)
+
+	record := 0
+	subset.ResourceLogs().RemoveIf(func(rl plog.ResourceLogs) bool {
+		rl.ScopeLogs().RemoveIf(func(sl plog.ScopeLogs) bool {
+			sl.LogRecords().RemoveIf(func(plog.LogRecord) bool {
+				keep := records[record]
+				record++
+				return !keep
+			})
+			return sl.LogRecords().Len() == 0
+		})
+		return rl.ScopeLogs().Len() == 0
+	})
+	return subset
 }
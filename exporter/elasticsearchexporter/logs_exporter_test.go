@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"net/http"
 	"runtime"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -17,8 +18,10 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/plog"
 	"go.uber.org/zap"
-	"go.uber.org/zap/zaptest"
 )
 
 func TestExporter_New(t *testing.T) {
@@ -122,7 +125,7 @@ func TestExporter_New(t *testing.T) {
 				t.Setenv(k, v)
 			}
 
-			exporter, err := newLogsExporter(zap.NewNop(), test.config)
+			exporter, err := newLogsExporter(exportertest.NewNopCreateSettings(), test.config)
 			if exporter != nil {
 				defer func() {
 					require.NoError(t, exporter.Shutdown(context.TODO()))
@@ -196,6 +199,73 @@ func TestExporter_PushEvent(t *testing.T) {
 		rec.WaitItems(1)
 	})
 
+	t.Run("publish with data stream", func(t *testing.T) {
+		rec := newBulkRecorder()
+		server := newESTestServer(t, func(docs []itemRequest) ([]itemResponse, error) {
+			rec.Record(docs)
+
+			jsonVal := map[string]map[string]interface{}{}
+			assert.Nil(t, json.Unmarshal(docs[0].Action, &jsonVal))
+			assert.Equal(t, "logs-nginx.access-production", jsonVal["create"]["_index"])
+
+			return itemsAllOK(docs)
+		})
+
+		exporter := newTestLogsExporter(t, server.URL, func(cfg *Config) {
+			cfg.DataStream.Enabled = true
+			cfg.DataStream.Namespace = "production"
+			cfg.LogsDynamicIndex.Enabled = true
+		})
+
+		mustSendLogsWithAttributes(t, exporter,
+			map[string]string{
+				dataStreamDataset: "nginx-access",
+				indexPrefix:       "attrprefix-",
+			},
+			map[string]string{
+				dataStreamDataset: "Nginx.Access",
+			},
+		)
+
+		rec.WaitItems(1)
+	})
+
+	t.Run("publish with jaeger mapping mode", func(t *testing.T) {
+		rec := newBulkRecorder()
+		server := newESTestServer(t, func(docs []itemRequest) ([]itemResponse, error) {
+			rec.Record(docs)
+
+			jsonVal := map[string]map[string]interface{}{}
+			assert.Nil(t, json.Unmarshal(docs[0].Action, &jsonVal))
+			assert.Equal(t, "jaeger-log-write", jsonVal["create"]["_index"])
+
+			document := map[string]interface{}{}
+			assert.Nil(t, json.Unmarshal(docs[0].Document, &document))
+			assert.NotEmpty(t, document["fields"])
+
+			return itemsAllOK(docs)
+		})
+
+		exporter := newTestLogsExporter(t, server.URL, func(cfg *Config) {
+			cfg.Mapping.Mode = "jaeger"
+		})
+
+		mustSendLogsWithAttributes(t, exporter, map[string]string{"key1": "value1"}, map[string]string{})
+
+		rec.WaitItems(1)
+	})
+
+	t.Run("empty document is not sent", func(t *testing.T) {
+		server := newESTestServer(t, func(docs []itemRequest) ([]itemResponse, error) {
+			t.Errorf("unexpected bulk request with %d documents", len(docs))
+			return itemsAllOK(docs)
+		})
+
+		exporter := newTestExporter(t, server.URL)
+		err := pushDocuments(context.TODO(), zap.L(), exporter.index, "", nil, exporter.bulkIndexer, exporter.maxAttempts, exporter.deadLetterQueue)
+		assert.ErrorIs(t, err, errEmptyDocument)
+	})
+
 	t.Run("retry http request", func(t *testing.T) {
 		failures := 0
 		rec := newBulkRecorder()
@@ -362,7 +432,7 @@ func TestExporter_PushEvent(t *testing.T) {
 }
 
 func newTestExporter(t *testing.T, url string, fns ...func(*Config)) *elasticsearchLogsExporter {
-	exporter, err := newLogsExporter(zaptest.NewLogger(t), withTestExporterConfig(fns...)(url))
+	exporter, err := newLogsExporter(newTestCreateSettings(t), withTestExporterConfig(fns...)(url))
 	require.NoError(t, err)
 
 	t.Cleanup(func() {
@@ -385,7 +455,7 @@ func withTestExporterConfig(fns ...func(*Config)) func(string) *Config {
 }
 
 func mustSend(t *testing.T, exporter *elasticsearchLogsExporter, contents string) {
-	err := pushDocuments(context.TODO(), zap.L(), exporter.index, "", []byte(contents), exporter.bulkIndexer, exporter.maxAttempts)
+	err := pushDocuments(context.TODO(), zap.L(), exporter.index, "", []byte(contents), exporter.bulkIndexer, exporter.maxAttempts, exporter.deadLetterQueue)
 	require.NoError(t, err)
 }
 
@@ -395,6 +465,139 @@ func mustSendLogsWithAttributes(t *testing.T, exporter *elasticsearchLogsExporte
 	resSpans := logs.ResourceLogs().At(0)
 	logRecords := resSpans.ScopeLogs().At(0).LogRecords().At(0)
 
-	err := exporter.pushLogRecord(context.TODO(), resSpans.Resource(), logRecords)
+	err := exporter.pushLogRecord(context.TODO(), resSpans.Resource(), resSpans.ScopeLogs().At(0).Scope(), logRecords)
 	require.NoError(t, err)
 }
+
+func TestExporter_PushLogsDataSync(t *testing.T) {
+	newLogs := func(bodies ...string) plog.Logs {
+		logs := plog.NewLogs()
+		records := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+		for _, body := range bodies {
+			records.AppendEmpty().Body().SetStr(body)
+		}
+		return logs
+	}
+	newSyncExporter := func(t *testing.T, url string, fns ...func(*Config)) *elasticsearchLogsExporter {
+		return newTestExporter(t, url, append([]func(*Config){func(cfg *Config) {
+			cfg.BulkMode = BulkModeSync
+			cfg.Retry.InitialInterval = time.Millisecond
+			cfg.Retry.MaxInterval = 10 * time.Millisecond
+		}}, fns...)...)
+	}
+
+	t.Run("wait for the documents to be indexed", func(t *testing.T) {
+		rec := newBulkRecorder()
+		server := newESTestServer(t, func(docs []itemRequest) ([]itemResponse, error) {
+			rec.Record(docs)
+			return itemsAllOK(docs)
+		})
+
+		exporter := newSyncExporter(t, server.URL)
+		require.NoError(t, exporter.pushLogsData(context.TODO(), newLogs("test1", "test2")))
+		assert.Equal(t, 2, rec.NumItems())
+	})
+
+	t.Run("split the documents by flush bytes", func(t *testing.T) {
+		rec := newBulkRecorder()
+		server := newESTestServer(t, func(docs []itemRequest) ([]itemResponse, error) {
+			rec.Record(docs)
+			return itemsAllOK(docs)
+		})
+
+		exporter := newSyncExporter(t, server.URL, func(cfg *Config) {
+			cfg.Flush.Bytes = 1
+		})
+		require.NoError(t, exporter.pushLogsData(context.TODO(), newLogs("test1", "test2", "test3")))
+		assert.Len(t, rec.Requests(), 3)
+	})
+
+	t.Run("only retry failed items", func(t *testing.T) {
+		var attempts atomic.Int64
+		server := newESTestServer(t, func(docs []itemRequest) ([]itemResponse, error) {
+			resp := make([]itemResponse, len(docs))
+			for i, doc := range docs {
+				resp[i].Status = http.StatusOK
+				if strings.Contains(string(doc.Document), "retry") && attempts.Add(1) == 1 {
+					resp[i].Status = http.StatusTooManyRequests
+				}
+			}
+			return resp, nil
+		})
+
+		exporter := newSyncExporter(t, server.URL)
+		require.NoError(t, exporter.pushLogsData(context.TODO(), newLogs("test1", "retry", "test3")))
+		assert.Equal(t, int64(2), attempts.Load())
+	})
+
+	t.Run("return the records failing after the retries", func(t *testing.T) {
+		var attempts atomic.Int64
+		server := newESTestServer(t, func(docs []itemRequest) ([]itemResponse, error) {
+			resp := make([]itemResponse, len(docs))
+			for i, doc := range docs {
+				resp[i].Status = http.StatusOK
+				if strings.Contains(string(doc.Document), "retry") {
+					attempts.Add(1)
+					resp[i].Status = http.StatusTooManyRequests
+				}
+			}
+			return resp, nil
+		})
+
+		exporter := newSyncExporter(t, server.URL)
+		err := exporter.pushLogsData(context.TODO(), newLogs("test1", "retry", "test3"))
+		require.Error(t, err)
+		assert.False(t, consumererror.IsPermanent(err))
+		assert.Equal(t, int64(exporter.maxAttempts), attempts.Load())
+
+		var logsErr consumererror.Logs
+		require.True(t, errors.As(err, &logsErr))
+		failed := logsErr.Data()
+		require.Equal(t, 1, failed.LogRecordCount())
+		assert.Equal(t, "retry", failed.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().Str())
+	})
+
+	t.Run("return the records of failed requests", func(t *testing.T) {
+		server := newESTestServer(t, func(docs []itemRequest) ([]itemResponse, error) {
+			return nil, &httpTestError{message: "oops", status: http.StatusServiceUnavailable}
+		})
+
+		exporter := newSyncExporter(t, server.URL, func(cfg *Config) {
+			cfg.Retry.Enabled = false
+		})
+		err := exporter.pushLogsData(context.TODO(), newLogs("test1", "test2"))
+		var logsErr consumererror.Logs
+		require.True(t, errors.As(err, &logsErr))
+		assert.Equal(t, 2, logsErr.Data().LogRecordCount())
+	})
+
+	t.Run("bad items are permanent failures", func(t *testing.T) {
+		var attempts atomic.Int64
+		server := newESTestServer(t, func(docs []itemRequest) ([]itemResponse, error) {
+			attempts.Add(1)
+			return itemsReportStatus(docs, http.StatusBadRequest)
+		})
+
+		exporter := newSyncExporter(t, server.URL)
+		err := exporter.pushLogsData(context.TODO(), newLogs("test1", "test2"))
+		assert.True(t, consumererror.IsPermanent(err))
+		assert.ErrorContains(t, err, "failed to index 2 documents (status=400)")
+		assert.Equal(t, int64(1), attempts.Load())
+	})
+}
+
+func TestLogsSubset(t *testing.T) {
+	logs := plog.NewLogs()
+	for _, bodies := range [][]string{{"a", "b"}, {"c"}, {"d", "e"}} {
+		records := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+		for _, body := range bodies {
+			records.AppendEmpty().Body().SetStr(body)
+		}
+	}
+
+	subset := logsSubset(logs, map[int]bool{1: true, 4: true})
+	require.Equal(t, 2, subset.ResourceLogs().Len())
+	assert.Equal(t, "b", subset.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().Str())
+	assert.Equal(t, "e", subset.ResourceLogs().At(1).ScopeLogs().At(0).LogRecords().At(0).Body().Str())
+	assert.Equal(t, 5, logs.LogRecordCount())
+}
@@ -0,0 +1,144 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearchexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/elasticsearchexporter"
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/elasticsearchexporter/internal/objmodel"
+)
+
+// mappingDefinition is the content of the `mapping.file`.
+//
+//	top_level:
+//	  - Attributes.http.method
+//	fields:
+//	  Resource.service.name: service.name
+//	types:
+//	  http.method: keyword
+type mappingDefinition struct {
+	// TopLevel lists the attributes and the resource attributes promoted to top-level fields,
+	// e.g. Attributes.http.method becomes http.method.
+	TopLevel []string `yaml:"top_level"`
+
+	// Fields renames the document keys and the keys prefixed with them, an empty name removes them.
+	Fields map[string]string `yaml:"fields"`
+
+	// Types converts the values of the fields, after they are renamed, to an Elasticsearch type.
+	Types map[string]string `yaml:"types"`
+}
+
+// topLevelPrefixes are the objects which fields can be promoted to top-level fields.
+var topLevelPrefixes = []string{"Attributes.", "Resource."}
+
+// mappingTypes maps the Elasticsearch types to the document value kinds.
+var mappingTypes = map[string]objmodel.Kind{
+	"keyword": objmodel.KindString,
+	"text":    objmodel.KindString,
+	"long":    objmodel.KindInt,
+	"integer": objmodel.KindInt,
+	"double":  objmodel.KindDouble,
+	"float":   objmodel.KindDouble,
+	"boolean": objmodel.KindBool,
+}
+
+// fieldMapping applies the mapping file and the mapping fields to the encoded documents.
+type fieldMapping struct {
+	renames [][2]string
+	types   map[string]objmodel.Kind
+}
+
+// newFieldMapping merges the mapping file and the mapping fields, the mapping fields have
+// priority. It returns nil if there is nothing to map.
+func newFieldMapping(settings MappingsSettings) (*fieldMapping, error) {
+	definition := &mappingDefinition{}
+	if settings.File != "" {
+		file, err := os.Open(settings.File)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read mapping file: %w", err)
+		}
+		defer file.Close()
+		decoder := yaml.NewDecoder(file)
+		decoder.KnownFields(true)
+		if err = decoder.Decode(definition); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to parse mapping file %s: %w", settings.File, err)
+		}
+	}
+
+	mapping := &fieldMapping{types: map[string]objmodel.Kind{}}
+	for _, key := range definition.TopLevel {
+		name := ""
+		for _, prefix := range topLevelPrefixes {
+			if strings.HasPrefix(key, prefix) {
+				name = key[len(prefix):]
+			}
+		}
+		if name == "" {
+			return nil, fmt.Errorf("top-level field %q must start with one of %v", key, topLevelPrefixes)
+		}
+		mapping.renames = append(mapping.renames, [2]string{key, name})
+	}
+
+	fields := map[string]string{}
+	for key, name := range definition.Fields {
+		fields[key] = name
+	}
+	for key, name := range settings.Fields {
+		fields[key] = name
+	}
+	keys := make([]string, 0, len(fields))
+	for key, name := range fields {
+		if err := validateMappingField(key, name); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		mapping.renames = append(mapping.renames, [2]string{key, fields[key]})
+	}
+
+	for key, typ := range definition.Types {
+		kind, ok := mappingTypes[typ]
+		if !ok {
+			return nil, fmt.Errorf("unknown type %q of field %q", typ, key)
+		}
+		mapping.types[key] = kind
+	}
+
+	if len(mapping.renames) == 0 && len(mapping.types) == 0 {
+		return nil, nil
+	}
+	return mapping, nil
+}
+
+func validateMappingField(key, name string) error {
+	if key == "" || strings.HasPrefix(key, ".") || strings.HasSuffix(key, ".") {
+		return fmt.Errorf("invalid mapping field %q", key)
+	}
+	if strings.HasPrefix(name, ".") || strings.HasSuffix(name, ".") {
+		return fmt.Errorf("invalid name %q of mapping field %q", name, key)
+	}
+	return nil
+}
+
+// apply renames the fields of the document first, then converts their values.
+func (m *fieldMapping) apply(document *objmodel.Document) {
+	if m == nil {
+		return
+	}
+	for _, rename := range m.renames {
+		document.Rename(rename[0], rename[1])
+	}
+	for key, kind := range m.types {
+		document.Convert(key, kind)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearchexporter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/elasticsearchexporter/internal/objmodel"
+)
+
+func TestNewFieldMapping(t *testing.T) {
+	mapping, err := newFieldMapping(MappingsSettings{})
+	require.NoError(t, err)
+	assert.Nil(t, mapping)
+
+	mapping, err = newFieldMapping(MappingsSettings{
+		File:   filepath.Join("testdata", "mapping.yaml"),
+		Fields: map[string]string{"Resource.service.version": "version", "Scope": "scope"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, [][2]string{
+		{"Attributes.http.method", "http.method"},
+		{"Resource.service.name", "service.name"},
+		{"Resource.service.version", "version"},
+		{"Scope", "scope"},
+	}, mapping.renames)
+	assert.Equal(t, map[string]objmodel.Kind{"Attributes.http.status_code": objmodel.KindInt}, mapping.types)
+}
+
+func TestNewFieldMapping_Invalid(t *testing.T) {
+	for name, settings := range map[string]MappingsSettings{
+		"missing file":      {File: filepath.Join("testdata", "missing.yaml")},
+		"invalid file":      {File: filepath.Join("testdata", "config.yaml")},
+		"empty field":       {Fields: map[string]string{"": "resource"}},
+		"dotted field":      {Fields: map[string]string{"Resource.": "resource"}},
+		"dotted name":       {Fields: map[string]string{"Resource": ".resource"}},
+		"unknown top-level": {File: writeMappingFile(t, "top_level: [Body]")},
+		"unknown type":      {File: writeMappingFile(t, "types: {Body: date}")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := newFieldMapping(settings)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestEncodeLogWithFieldMapping(t *testing.T) {
+	mapping, err := newFieldMapping(MappingsSettings{File: filepath.Join("testdata", "mapping.yaml")})
+	require.NoError(t, err)
+	model := &encodeModel{dedup: true, dedot: true, mapping: mapping}
+
+	logs := newLogsWithAttributeAndResourceMap(
+		map[string]string{"http.method": "GET", "http.status_code": "200"},
+		map[string]string{"service.name": "checkout"},
+	)
+	record := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	record.Body().SetStr("request")
+	body, err := model.encodeLog(logs.ResourceLogs().At(0).Resource(), pcommon.NewInstrumentationScope(), record)
+	require.NoError(t, err)
+	assert.Equal(t, `{"@timestamp":"1970-01-01T00:00:00.000000000Z","Attributes":{"http":{"status_code":200}},"Body":"request",`+
+		`"SeverityNumber":0,"TraceFlags":0,"http":{"method":"GET"},"service":{"name":"checkout"}}`, string(body))
+
+	model.mapping = nil
+	body, err = model.encodeLog(plog.NewResourceLogs().Resource(), pcommon.NewInstrumentationScope(), record)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"Attributes":{"http":{"method":"GET","status_code":"200"}}`)
+}
+
+func writeMappingFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "mapping.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
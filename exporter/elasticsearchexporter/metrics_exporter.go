@@ -0,0 +1,264 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearchexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/elasticsearchexporter"
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+type elasticsearchMetricsExporter struct {
+	logger *zap.Logger
+
+	index        string
+	dynamicIndex bool
+	maxAttempts  int
+
+	client          *esClientCurrent
+	bulkIndexer     esBulkIndexerCurrent
+	model           mappingModel
+	indexManager    *indexManager
+	deadLetterQueue *deadLetterQueue
+	syncBulkIndexer *syncBulkIndexer
+}
+
+func newMetricsExporter(set exporter.CreateSettings, cfg *Config) (*elasticsearchMetricsExporter, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	logger := set.Logger
+
+	client, err := newElasticsearchClient(logger, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	bulkIndexer, err := newBulkIndexer(logger, client, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	mapping, err := newFieldMapping(cfg.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	maxAttempts := 1
+	if cfg.Retry.Enabled {
+		maxAttempts = cfg.Retry.MaxRequests
+	}
+
+	indexManager, err := newIndexManager(logger, client, cfg, signalMetrics, cfg.MetricsIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	deadLetterQueue, err := newDeadLetterQueue(set, cfg.DeadLetterQueue, signalMetrics, bulkIndexer)
+	if err != nil {
+		return nil, err
+	}
+
+	metricsExporter := &elasticsearchMetricsExporter{
+		logger:          logger,
+		client:          client,
+		bulkIndexer:     bulkIndexer,
+		index:           cfg.MetricsIndex,
+		dynamicIndex:    cfg.MetricsDynamicIndex.Enabled,
+		maxAttempts:     maxAttempts,
+		model:           &encodeModel{dedup: cfg.Mapping.Dedup, dedot: cfg.Mapping.Dedot, mapping: mapping},
+		indexManager:    indexManager,
+		deadLetterQueue: deadLetterQueue,
+		syncBulkIndexer: newSyncBulkIndexer(logger, client, cfg, maxAttempts, deadLetterQueue),
+	}
+	return metricsExporter, nil
+}
+
+// Start installs the mappings and the index template of the metrics index, then opens the dead letter queue.
+func (e *elasticsearchMetricsExporter) Start(ctx context.Context, host component.Host) error {
+	if err := e.indexManager.apply(ctx); err != nil {
+		return err
+	}
+	return e.deadLetterQueue.start(ctx, host)
+}
+
+func (e *elasticsearchMetricsExporter) Shutdown(ctx context.Context) error {
+	e.deadLetterQueue.stopReplay()
+	return multierr.Combine(e.bulkIndexer.Close(ctx), e.deadLetterQueue.shutdown(ctx))
+}
+
+func (e *elasticsearchMetricsExporter) pushMetricsData(ctx context.Context, md pmetric.Metrics) error {
+	if e.syncBulkIndexer != nil {
+		return e.pushMetricsDataSync(ctx, md)
+	}
+	var errs []error
+
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		resource := rm.Resource()
+		sms := rm.ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			scope := sms.At(j).Scope()
+			metrics := sms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				metric := metrics.At(k)
+				for _, dp := range metricDataPoints(metric) {
+					if err := e.pushMetricDataPoint(ctx, resource, scope, metric, dp); err != nil {
+						if cerr := ctx.Err(); cerr != nil {
+							return cerr
+						}
+
+						errs = append(errs, err)
+					}
+				}
+			}
+		}
+	}
+
+	return multierr.Combine(errs...)
+}
+
+// pushMetricsDataSync indexes the data points in bulk requests of their own. The data points failing
+// with a retryable status are returned in a consumererror.Metrics for the exporterhelper to retry them,
+// the other failures are permanent.
+func (e *elasticsearchMetricsExporter) pushMetricsDataSync(ctx context.Context, md pmetric.Metrics) error {
+	var errs []error
+	var docs []bulkDocument
+
+	record := 0
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		resource := rm.Resource()
+		sms := rm.ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			scope := sms.At(j).Scope()
+			metrics := sms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				metric := metrics.At(k)
+				for _, dp := range metricDataPoints(metric) {
+					index, document, err := e.encodeMetricDataPoint(resource, scope, metric, dp)
+					if err != nil {
+						errs = append(errs, err)
+					} else {
+						docs = append(docs, bulkDocument{index: index, document: document, record: record})
+					}
+					record++
+				}
+			}
+		}
+	}
+
+	failed, err := e.syncBulkIndexer.index(ctx, docs)
+	err = multierr.Combine(append(errs, err)...)
+	switch {
+	case len(failed) > 0:
+		return consumererror.NewMetrics(err, metricsSubset(md, failed))
+	case err != nil:
+		return consumererror.NewPermanent(err)
+	}
+	return nil
+}
+
+func (e *elasticsearchMetricsExporter) pushMetricDataPoint(ctx context.Context, resource pcommon.Resource, scope pcommon.InstrumentationScope, metric pmetric.Metric, dataPoint metricDataPoint) error {
+	index, document, err := e.encodeMetricDataPoint(resource, scope, metric, dataPoint)
+	if err != nil {
+		return err
+	}
+	return pushDocuments(ctx, e.logger, index, "", document, e.bulkIndexer, e.maxAttempts, e.deadLetterQueue)
+}
+
+// encodeMetricDataPoint returns the index and the document of a data point.
+func (e *elasticsearchMetricsExporter) encodeMetricDataPoint(resource pcommon.Resource, scope pcommon.InstrumentationScope, metric pmetric.Metric, dataPoint metricDataPoint) (string, []byte, error) {
+	fIndex := e.index
+	if e.dynamicIndex {
+		prefix := getFromBothResourceAndAttribute(indexPrefix, resource, dataPoint)
+		suffix := getFromBothResourceAndAttribute(indexSuffix, resource, dataPoint)
+
+		fIndex = fmt.Sprintf("%s%s%s", prefix, fIndex, suffix)
+	}
+
+	document, err := e.model.encodeMetricDataPoint(resource, scope, metric, dataPoint)
+	if err != nil {
+		return "", nil, fmt.Errorf("Failed to encode metric data point: %w", err)
+	}
+	return fIndex, document, nil
+}
+
+// metricDataPoints returns the data points of the metric whatever its type.
+func metricDataPoints(metric pmetric.Metric) []metricDataPoint {
+	var dataPoints []metricDataPoint
+	switch metric.Type() {
+	case pmetric.MetricTypeGauge:
+		dps := metric.Gauge().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dataPoints = append(dataPoints, dps.At(i))
+		}
+	case pmetric.MetricTypeSum:
+		dps := metric.Sum().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dataPoints = append(dataPoints, dps.At(i))
+		}
+	case pmetric.MetricTypeHistogram:
+		dps := metric.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dataPoints = append(dataPoints, dps.At(i))
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		dps := metric.ExponentialHistogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dataPoints = append(dataPoints, dps.At(i))
+		}
+	case pmetric.MetricTypeSummary:
+		dps := metric.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dataPoints = append(dataPoints, dps.At(i))
+		}
+	}
+	return dataPoints
+}
+
+// metricsSubset returns a copy of the batch holding the data points at the given positions, in the
+// order of metricDataPoints.
+func metricsSubset(md pmetric.Metrics, records map[int]bool) pmetric.Metrics {
+	subset := pmetric.NewMetrics()
+	md.CopyTo(subset)
+
+	record := 0
+	removeDataPoint := func() bool {
+		keep := records[record]
+		record++
+		return !keep
+	}
+	subset.ResourceMetrics().RemoveIf(func(rm pmetric.ResourceMetrics) bool {
+		rm.ScopeMetrics().RemoveIf(func(sm pmetric.ScopeMetrics) bool {
+			sm.Metrics().RemoveIf(func(metric pmetric.Metric) bool {
+				switch metric.Type() {
+				case pmetric.MetricTypeGauge:
+					metric.Gauge().DataPoints().RemoveIf(func(pmetric.NumberDataPoint) bool { return removeDataPoint() })
+				case pmetric.MetricTypeSum:
+					metric.Sum().DataPoints().RemoveIf(func(pmetric.NumberDataPoint) bool { return removeDataPoint() })
+				case pmetric.MetricTypeHistogram:
+					metric.Histogram().DataPoints().RemoveIf(func(pmetric.HistogramDataPoint) bool { return removeDataPoint() })
+				case pmetric.MetricTypeExponentialHistogram:
+					metric.ExponentialHistogram().DataPoints().RemoveIf(func(pmetric.ExponentialHistogramDataPoint) bool { return removeDataPoint() })
+				case pmetric.MetricTypeSummary:
+					metric.Summary().DataPoints().RemoveIf(func(pmetric.SummaryDataPoint) bool { return removeDataPoint() })
+				}
+				return len(metricDataPoints(metric)) == 0
+			})
+			return sm.Metrics().Len() == 0
+		})
+		return rm.ScopeMetrics().Len() == 0
+	})
+	return subset
+}
@@ -0,0 +1,152 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearchexporter
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestExporter_PushMetricsData(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows, see https://github.com/open-telemetry/opentelemetry-collector-contrib/issues/10178")
+	}
+
+	t.Run("publish every data point", func(t *testing.T) {
+		rec := newBulkRecorder()
+		server := newESTestServer(t, func(docs []itemRequest) ([]itemResponse, error) {
+			rec.Record(docs)
+			return itemsAllOK(docs)
+		})
+
+		exporter := newTestMetricsExporter(t, server.URL)
+		metrics := pmetric.NewMetrics()
+		ms := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+		gauge := ms.AppendEmpty()
+		gauge.SetName("system.memory.usage")
+		gauge.SetEmptyGauge().DataPoints().AppendEmpty().SetIntValue(1024)
+		gauge.Gauge().DataPoints().AppendEmpty().SetIntValue(2048)
+		summary := ms.AppendEmpty()
+		summary.SetName("rpc.duration")
+		summary.SetEmptySummary().DataPoints().AppendEmpty().SetCount(1)
+
+		require.NoError(t, exporter.pushMetricsData(context.TODO(), metrics))
+		rec.WaitItems(3)
+
+		var names []string
+		for _, item := range rec.Items() {
+			var doc map[string]interface{}
+			require.NoError(t, json.Unmarshal(item.Document, &doc))
+			names = append(names, doc["Name"].(string))
+
+			var action map[string]map[string]interface{}
+			require.NoError(t, json.Unmarshal(item.Action, &action))
+			assert.Equal(t, defaultMetricsIndex, action["create"]["_index"])
+		}
+		assert.ElementsMatch(t, []string{"system.memory.usage", "system.memory.usage", "rpc.duration"}, names)
+	})
+
+	t.Run("publish with dynamic index", func(t *testing.T) {
+		rec := newBulkRecorder()
+		server := newESTestServer(t, func(docs []itemRequest) ([]itemResponse, error) {
+			rec.Record(docs)
+			return itemsAllOK(docs)
+		})
+
+		exporter := newTestMetricsExporter(t, server.URL, func(cfg *Config) {
+			cfg.MetricsIndex = "someindex"
+			cfg.MetricsDynamicIndex.Enabled = true
+		})
+		metrics := pmetric.NewMetrics()
+		rm := metrics.ResourceMetrics().AppendEmpty()
+		rm.Resource().Attributes().PutStr(indexPrefix, "resprefix-")
+		dp := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty().SetEmptyGauge().DataPoints().AppendEmpty()
+		dp.Attributes().PutStr(indexSuffix, "-attrsuffix")
+
+		require.NoError(t, exporter.pushMetricsData(context.TODO(), metrics))
+		rec.WaitItems(1)
+
+		var action map[string]map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Items()[0].Action, &action))
+		assert.Equal(t, "resprefix-someindex-attrsuffix", action["create"]["_index"])
+	})
+}
+
+func TestMetricsExporter_StartInstallsIndexTemplate(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		template []byte
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Add("X-Elastic-Product", "Elasticsearch")
+		_, _ = w.Write([]byte(`{"version":{"number":"` + currentESVersion + `"}}`))
+	})
+	mux.HandleFunc("/_index_template/"+defaultMetricsIndex, func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Add("X-Elastic-Product", "Elasticsearch")
+		if req.Method == http.MethodGet {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		template, _ = io.ReadAll(req.Body)
+		_, _ = w.Write([]byte(`{"acknowledged":true}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	exporter := newTestMetricsExporter(t, server.URL)
+	require.NoError(t, exporter.Start(context.TODO(), componenttest.NewNopHost()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(template, &body))
+	assert.Equal(t, []interface{}{"*" + defaultMetricsIndex + "*"}, body["index_patterns"])
+}
+
+func TestMetricsSubset(t *testing.T) {
+	metrics := pmetric.NewMetrics()
+	ms := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	gauge := ms.AppendEmpty()
+	gauge.SetName("gauge")
+	gauge.SetEmptyGauge().DataPoints().AppendEmpty().SetIntValue(1)
+	gauge.Gauge().DataPoints().AppendEmpty().SetIntValue(2)
+	sum := ms.AppendEmpty()
+	sum.SetName("sum")
+	sum.SetEmptySum().DataPoints().AppendEmpty().SetIntValue(3)
+	histogram := ms.AppendEmpty()
+	histogram.SetName("histogram")
+	histogram.SetEmptyHistogram().DataPoints().AppendEmpty().SetCount(4)
+
+	subset := metricsSubset(metrics, map[int]bool{1: true, 3: true})
+	require.Equal(t, 2, subset.DataPointCount())
+	subsetMetrics := subset.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 2, subsetMetrics.Len())
+	assert.Equal(t, int64(2), subsetMetrics.At(0).Gauge().DataPoints().At(0).IntValue())
+	assert.Equal(t, uint64(4), subsetMetrics.At(1).Histogram().DataPoints().At(0).Count())
+	assert.Equal(t, 4, metrics.DataPointCount())
+}
+
+func newTestMetricsExporter(t *testing.T, url string, fns ...func(*Config)) *elasticsearchMetricsExporter {
+	exporter, err := newMetricsExporter(newTestCreateSettings(t), withTestExporterConfig(fns...)(url))
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, exporter.Shutdown(context.TODO()))
+	})
+	return exporter
+}
@@ -9,6 +9,7 @@ import (
 
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/elasticsearchexporter/internal/objmodel"
@@ -16,9 +17,17 @@ import (
 )
 
 type mappingModel interface {
-	encodeLog(pcommon.Resource, plog.LogRecord) ([]byte, error)
+	encodeLog(pcommon.Resource, pcommon.InstrumentationScope, plog.LogRecord) ([]byte, error)
 	encodeSpan(pcommon.Resource, pcommon.InstrumentationScope, ptrace.Span) ([]byte, error)
 	encodeServiceNameOperation(resource pcommon.Resource, span ptrace.Span) (string, []byte, error)
+	encodeMetricDataPoint(pcommon.Resource, pcommon.InstrumentationScope, pmetric.Metric, metricDataPoint) ([]byte, error)
+}
+
+// metricDataPoint is implemented by the data points of every metric type.
+type metricDataPoint interface {
+	Attributes() pcommon.Map
+	StartTimestamp() pcommon.Timestamp
+	Timestamp() pcommon.Timestamp
 }
 
 // encodeModel tries to keep the event as close to the original open telemetry semantics as is.
@@ -28,17 +37,19 @@ type mappingModel interface {
 //
 // See: https://github.com/open-telemetry/oteps/blob/master/text/logs/0097-log-data-model.md
 type encodeModel struct {
-	dedup bool
-	dedot bool
+	dedup   bool
+	dedot   bool
+	mapping *fieldMapping
 }
 
 const (
-	traceIDField   = "traceID"
-	spanIDField    = "spanID"
-	attributeField = "attribute"
+	traceIDField    = "traceID"
+	spanIDField     = "spanID"
+	traceStateField = "traceState"
+	attributeField  = "attribute"
 )
 
-func (m *encodeModel) encodeLog(resource pcommon.Resource, record plog.LogRecord) ([]byte, error) {
+func (m *encodeModel) encodeLog(resource pcommon.Resource, _ pcommon.InstrumentationScope, record plog.LogRecord) ([]byte, error) {
 	var document objmodel.Document
 	document.AddTimestamp("@timestamp", record.Timestamp()) // We use @timestamp in order to ensure that we can index if the default data stream logs template is used.
 	document.AddTraceID("TraceId", record.TraceID())
@@ -50,18 +61,10 @@ func (m *encodeModel) encodeLog(resource pcommon.Resource, record plog.LogRecord
 	document.AddAttributes("Attributes", record.Attributes())
 	document.AddAttributes("Resource", resource.Attributes())
 
-	if m.dedup {
-		document.Dedup()
-	} else if m.dedot {
-		document.Sort()
-	}
-
-	var buf bytes.Buffer
-	err := document.Serialize(&buf, m.dedot)
-	return buf.Bytes(), err
+	return m.serialize(&document)
 }
 
-func (m *encodeModel) encodeSpan(resource pcommon.Resource, _ pcommon.InstrumentationScope, span ptrace.Span) ([]byte, error) {
+func (m *encodeModel) encodeSpan(resource pcommon.Resource, scope pcommon.InstrumentationScope, span ptrace.Span) ([]byte, error) {
 	var document objmodel.Document
 	document.AddTimestamp("@timestamp", span.StartTimestamp()) // We use @timestamp in order to ensure that we can index if the default data stream logs template is used.
 	document.AddTimestamp("EndTimestamp", span.EndTimestamp())
@@ -70,11 +73,23 @@ func (m *encodeModel) encodeSpan(resource pcommon.Resource, _ pcommon.Instrument
 	document.AddSpanID("ParentSpanId", span.ParentSpanID())
 	document.AddString("Name", span.Name())
 	document.AddString("Kind", traceutil.SpanKindStr(span.Kind()))
+	document.AddString("TraceState", span.TraceState().AsRaw())
 	document.AddInt("TraceStatus", int64(span.Status().Code()))
+	document.AddString("TraceStatusDescription", span.Status().Message())
 	document.AddString("Link", spanLinksToString(span.Links()))
 	document.AddAttributes("Attributes", span.Attributes())
 	document.AddAttributes("Resource", resource.Attributes())
 	document.AddEvents("Events", span.Events())
+	document.AddString("Scope.name", scope.Name())
+	document.AddString("Scope.version", scope.Version())
+	document.AddAttributes("Scope.Attributes", scope.Attributes())
+
+	return m.serialize(&document)
+}
+
+// serialize applies the field mapping to the document, then deduplicates or sorts its fields.
+func (m *encodeModel) serialize(document *objmodel.Document) ([]byte, error) {
+	m.mapping.apply(document)
 
 	if m.dedup {
 		document.Dedup()
@@ -92,6 +107,107 @@ func (m *encodeModel) encodeServiceNameOperation(resource pcommon.Resource, span
 	return "", nil, nil
 }
 
+// encodeMetricDataPoint encodes a single data point with the metadata of its metric, the
+// values depend on the type of the metric.
+func (m *encodeModel) encodeMetricDataPoint(resource pcommon.Resource, scope pcommon.InstrumentationScope, metric pmetric.Metric, dataPoint metricDataPoint) ([]byte, error) {
+	var document objmodel.Document
+	document.AddTimestamp("@timestamp", dataPoint.Timestamp())
+	document.AddTimestamp("StartTimestamp", dataPoint.StartTimestamp())
+	document.AddString("Name", metric.Name())
+	document.AddString("Description", metric.Description())
+	document.AddString("Unit", metric.Unit())
+	document.AddString("Type", metric.Type().String())
+
+	switch metric.Type() {
+	case pmetric.MetricTypeSum:
+		document.AddString("AggregationTemporality", metric.Sum().AggregationTemporality().String())
+		document.Add("IsMonotonic", objmodel.BoolValue(metric.Sum().IsMonotonic()))
+	case pmetric.MetricTypeHistogram:
+		document.AddString("AggregationTemporality", metric.Histogram().AggregationTemporality().String())
+	case pmetric.MetricTypeExponentialHistogram:
+		document.AddString("AggregationTemporality", metric.ExponentialHistogram().AggregationTemporality().String())
+	}
+
+	switch dp := dataPoint.(type) {
+	case pmetric.NumberDataPoint:
+		switch dp.ValueType() {
+		case pmetric.NumberDataPointValueTypeInt:
+			document.AddInt("Value", dp.IntValue())
+		case pmetric.NumberDataPointValueTypeDouble:
+			document.Add("Value", objmodel.DoubleValue(dp.DoubleValue()))
+		}
+		document.AddInt("Flags", int64(dp.Flags()))
+	case pmetric.HistogramDataPoint:
+		document.AddInt("Count", int64(dp.Count()))
+		if dp.HasSum() {
+			document.Add("Sum", objmodel.DoubleValue(dp.Sum()))
+		}
+		if dp.HasMin() {
+			document.Add("Min", objmodel.DoubleValue(dp.Min()))
+		}
+		if dp.HasMax() {
+			document.Add("Max", objmodel.DoubleValue(dp.Max()))
+		}
+		document.Add("BucketCounts", uintArrValue(dp.BucketCounts().AsRaw()))
+		document.Add("ExplicitBounds", doubleArrValue(dp.ExplicitBounds().AsRaw()))
+		document.AddInt("Flags", int64(dp.Flags()))
+	case pmetric.ExponentialHistogramDataPoint:
+		document.AddInt("Count", int64(dp.Count()))
+		if dp.HasSum() {
+			document.Add("Sum", objmodel.DoubleValue(dp.Sum()))
+		}
+		if dp.HasMin() {
+			document.Add("Min", objmodel.DoubleValue(dp.Min()))
+		}
+		if dp.HasMax() {
+			document.Add("Max", objmodel.DoubleValue(dp.Max()))
+		}
+		document.AddInt("Scale", int64(dp.Scale()))
+		document.AddInt("ZeroCount", int64(dp.ZeroCount()))
+		document.AddInt("Positive.Offset", int64(dp.Positive().Offset()))
+		document.Add("Positive.BucketCounts", uintArrValue(dp.Positive().BucketCounts().AsRaw()))
+		document.AddInt("Negative.Offset", int64(dp.Negative().Offset()))
+		document.Add("Negative.BucketCounts", uintArrValue(dp.Negative().BucketCounts().AsRaw()))
+		document.AddInt("Flags", int64(dp.Flags()))
+	case pmetric.SummaryDataPoint:
+		document.AddInt("Count", int64(dp.Count()))
+		document.Add("Sum", objmodel.DoubleValue(dp.Sum()))
+		quantiles := pcommon.NewValueSlice()
+		for i := 0; i < dp.QuantileValues().Len(); i++ {
+			qv := dp.QuantileValues().At(i)
+			quantile := quantiles.Slice().AppendEmpty().SetEmptyMap()
+			quantile.PutDouble("Quantile", qv.Quantile())
+			quantile.PutDouble("Value", qv.Value())
+		}
+		document.AddAttribute("Quantiles", quantiles)
+		document.AddInt("Flags", int64(dp.Flags()))
+	}
+
+	document.AddAttributes("Attributes", dataPoint.Attributes())
+	document.AddAttributes("Resource", resource.Attributes())
+	document.AddString("Scope.name", scope.Name())
+	document.AddString("Scope.version", scope.Version())
+	document.AddAttributes("Scope.Attributes", scope.Attributes())
+
+	return m.serialize(&document)
+}
+
+func uintArrValue(values []uint64) objmodel.Value {
+	arr := make([]objmodel.Value, len(values))
+	for i, v := range values {
+		arr[i] = objmodel.IntValue(int64(v))
+	}
+	return objmodel.ArrValue(arr...)
+}
+
+func doubleArrValue(values []float64) objmodel.Value {
+	arr := make([]objmodel.Value, len(values))
+	for i, v := range values {
+		arr[i] = objmodel.DoubleValue(v)
+	}
+	return objmodel.ArrValue(arr...)
+}
+
 func spanLinksToString(spanLinkSlice ptrace.SpanLinkSlice) string {
 	linkArray := make([]map[string]interface{}, 0, spanLinkSlice.Len())
 	for i := 0; i < spanLinkSlice.Len(); i++ {
@@ -99,6 +215,9 @@ func spanLinksToString(spanLinkSlice ptrace.SpanLinkSlice) string {
 		link := map[string]interface{}{}
 		link[spanIDField] = traceutil.SpanIDToHexOrEmptyString(spanLink.SpanID())
 		link[traceIDField] = traceutil.TraceIDToHexOrEmptyString(spanLink.TraceID())
+		if traceState := spanLink.TraceState().AsRaw(); traceState != "" {
+			link[traceStateField] = traceState
+		}
 		link[attributeField] = spanLink.Attributes().AsRaw()
 		linkArray = append(linkArray, link)
 	}
@@ -0,0 +1,297 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearchexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/elasticsearchexporter"
+
+import (
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/collector/semconv/v1.18.0"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/elasticsearchexporter/internal/objmodel"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/coreinternal/traceutil"
+)
+
+// encodeECSModel maps the logs and the spans to the Elastic Common Schema, following the
+// conventions of the Elastic APM server for the spans:
+// https://www.elastic.co/guide/en/apm/guide/current/open-telemetry-with-elastic.html
+//
+// The attributes which have no ECS field are stored as labels. Metrics are encoded as the encodeModel does.
+type encodeECSModel struct {
+	encodeModel
+}
+
+// ecsAttributes maps the semantic conventions attributes, from resource or record, to the ECS fields.
+var ecsAttributes = map[string]string{
+	semconv.AttributeServiceName:           "service.name",
+	semconv.AttributeServiceVersion:        "service.version",
+	semconv.AttributeServiceInstanceID:     "service.node.name",
+	semconv.AttributeDeploymentEnvironment: "service.environment",
+	semconv.AttributeTelemetrySDKLanguage:  "service.language.name",
+	semconv.AttributeTelemetrySDKVersion:   "agent.version",
+	semconv.AttributeProcessRuntimeName:    "service.runtime.name",
+	semconv.AttributeProcessRuntimeVersion: "service.runtime.version",
+
+	semconv.AttributeCloudProvider:         "cloud.provider",
+	semconv.AttributeCloudAccountID:        "cloud.account.id",
+	semconv.AttributeCloudRegion:           "cloud.region",
+	semconv.AttributeCloudAvailabilityZone: "cloud.availability_zone",
+	semconv.AttributeCloudPlatform:         "cloud.service.name",
+
+	semconv.AttributeHostName: "host.hostname",
+	semconv.AttributeHostID:   "host.id",
+	semconv.AttributeHostArch: "host.architecture",
+	semconv.AttributeHostType: "host.type",
+	semconv.AttributeOSType:   "host.os.platform",
+	semconv.AttributeOSName:   "host.os.name",
+	// os.description is the full operating system name, e.g. Ubuntu 18.04.1 LTS.
+	semconv.AttributeOSDescription: "host.os.full",
+	semconv.AttributeOSVersion:     "host.os.version",
+
+	semconv.AttributeProcessPID:            "process.pid",
+	semconv.AttributeProcessExecutablePath: "process.executable",
+	semconv.AttributeProcessCommandLine:    "process.command_line",
+
+	semconv.AttributeContainerID:        "container.id",
+	semconv.AttributeContainerName:      "container.name",
+	semconv.AttributeContainerImageName: "container.image.name",
+	semconv.AttributeContainerImageTag:  "container.image.tag",
+	semconv.AttributeContainerRuntime:   "container.runtime",
+
+	semconv.AttributeK8SNamespaceName:  "kubernetes.namespace",
+	semconv.AttributeK8SPodName:        "kubernetes.pod.name",
+	semconv.AttributeK8SPodUID:         "kubernetes.pod.uid",
+	semconv.AttributeK8SNodeName:       "kubernetes.node.name",
+	semconv.AttributeK8SDeploymentName: "kubernetes.deployment.name",
+
+	semconv.AttributeHTTPMethod:     "http.request.method",
+	semconv.AttributeHTTPStatusCode: "http.response.status_code",
+	semconv.AttributeHTTPURL:        "url.full",
+	semconv.AttributeHTTPTarget:     "url.original",
+	semconv.AttributeHTTPScheme:     "url.scheme",
+	semconv.AttributeHTTPUserAgent:  "user_agent.original",
+	// the attributes renamed by the semantic conventions v1.21.0
+	"http.request.method":       "http.request.method",
+	"http.response.status_code": "http.response.status_code",
+	"url.full":                  "url.full",
+	"url.path":                  "url.path",
+	"url.query":                 "url.query",
+	"url.scheme":                "url.scheme",
+	"user_agent.original":       "user_agent.original",
+
+	semconv.AttributeEnduserID: "user.id",
+
+	semconv.AttributeExceptionType:       "error.type",
+	semconv.AttributeExceptionMessage:    "error.message",
+	semconv.AttributeExceptionStacktrace: "error.stack_trace",
+
+	"log.file.path": "log.file.path",
+}
+
+// ecsLabelReplacer replaces the characters which are not allowed in the label keys.
+var ecsLabelReplacer = strings.NewReplacer(".", "_", "*", "_", "\"", "_")
+
+func (m *encodeECSModel) encodeLog(resource pcommon.Resource, _ pcommon.InstrumentationScope, record plog.LogRecord) ([]byte, error) {
+	var document objmodel.Document
+	timestamp := record.Timestamp()
+	if timestamp == 0 {
+		timestamp = record.ObservedTimestamp()
+	}
+	document.AddTimestamp("@timestamp", timestamp)
+	document.AddTraceID("trace.id", record.TraceID())
+	document.AddSpanID("span.id", record.SpanID())
+	document.AddString("log.level", ecsLogLevel(record))
+	if record.SeverityNumber() != plog.SeverityNumberUnspecified {
+		document.AddInt("event.severity", int64(record.SeverityNumber()))
+	}
+	document.AddString("message", record.Body().AsString())
+	addECSResource(&document, resource)
+	addECSAttributes(&document, record.Attributes())
+
+	return m.serialize(&document)
+}
+
+func (m *encodeECSModel) encodeSpan(resource pcommon.Resource, scope pcommon.InstrumentationScope, span ptrace.Span) ([]byte, error) {
+	var document objmodel.Document
+	duration := int64(span.EndTimestamp() - span.StartTimestamp())
+	document.AddTimestamp("@timestamp", span.StartTimestamp())
+	document.AddTraceID("trace.id", span.TraceID())
+	document.AddSpanID("parent.id", span.ParentSpanID())
+	document.AddInt("event.duration", duration)
+	document.AddString("event.outcome", ecsEventOutcome(span))
+	document.AddString("event.reason", span.Status().Message())
+	document.AddString("span.kind", strings.TrimPrefix(traceutil.SpanKindStr(span.Kind()), "SPAN_KIND_"))
+	document.AddString("service.framework.name", scope.Name())
+	document.AddString("service.framework.version", scope.Version())
+	if span.Links().Len() > 0 {
+		links := pcommon.NewValueSlice()
+		for i := 0; i < span.Links().Len(); i++ {
+			link := links.Slice().AppendEmpty().SetEmptyMap()
+			link.PutStr("trace.id", traceutil.TraceIDToHexOrEmptyString(span.Links().At(i).TraceID()))
+			link.PutStr("span.id", traceutil.SpanIDToHexOrEmptyString(span.Links().At(i).SpanID()))
+		}
+		document.AddAttribute("span.links", links)
+	}
+
+	// the server and consumer spans, and the root spans, are the transactions of the APM app.
+	if span.Kind() == ptrace.SpanKindServer || span.Kind() == ptrace.SpanKindConsumer || span.ParentSpanID().IsEmpty() {
+		document.AddString("processor.event", "transaction")
+		document.AddSpanID("transaction.id", span.SpanID())
+		document.AddString("transaction.name", span.Name())
+		document.AddString("transaction.type", ecsTransactionType(span))
+		document.AddString("transaction.result", ecsTransactionResult(span))
+		document.AddInt("transaction.duration.us", duration/1000)
+	} else {
+		spanType, subtype := ecsSpanType(span)
+		document.AddString("processor.event", "span")
+		document.AddSpanID("span.id", span.SpanID())
+		document.AddString("span.name", span.Name())
+		document.AddString("span.type", spanType)
+		document.AddString("span.subtype", subtype)
+		document.AddInt("span.duration.us", duration/1000)
+	}
+
+	addECSResource(&document, resource)
+	addECSAttributes(&document, span.Attributes())
+	for i := 0; i < span.Events().Len(); i++ {
+		if event := span.Events().At(i); event.Name() == "exception" {
+			addECSAttributes(&document, event.Attributes())
+		}
+	}
+
+	return m.serialize(&document)
+}
+
+// addECSResource adds the resource attributes and the agent, named after the SDK language as the APM server does.
+func addECSResource(document *objmodel.Document, resource pcommon.Resource) {
+	agentName := "otlp"
+	if language, ok := resource.Attributes().Get(semconv.AttributeTelemetrySDKLanguage); ok {
+		agentName = "opentelemetry/" + language.AsString()
+	}
+	document.AddString("agent.name", agentName)
+	addECSAttributes(document, resource.Attributes())
+}
+
+func addECSAttributes(document *objmodel.Document, attributes pcommon.Map) {
+	attributes.Range(func(key string, value pcommon.Value) bool {
+		if field, ok := ecsAttributes[key]; ok {
+			document.AddAttribute(field, value)
+			return true
+		}
+		if key == semconv.AttributeTelemetrySDKName || key == dataStreamDataset || key == dataStreamNamespace {
+			return true
+		}
+
+		label := ecsLabelReplacer.Replace(key)
+		switch value.Type() {
+		case pcommon.ValueTypeInt, pcommon.ValueTypeDouble:
+			document.AddAttribute("numeric_labels."+label, value)
+		case pcommon.ValueTypeMap:
+			document.AddString("labels."+label, value.AsString())
+		default:
+			document.AddAttribute("labels."+label, value)
+		}
+		return true
+	})
+}
+
+// ecsLogLevel is the severity text, or the name of the severity number.
+func ecsLogLevel(record plog.LogRecord) string {
+	if record.SeverityText() != "" {
+		return record.SeverityText()
+	}
+	number := record.SeverityNumber()
+	switch {
+	case number == plog.SeverityNumberUnspecified:
+		return ""
+	case number <= plog.SeverityNumberTrace4:
+		return "trace"
+	case number <= plog.SeverityNumberDebug4:
+		return "debug"
+	case number <= plog.SeverityNumberInfo4:
+		return "info"
+	case number <= plog.SeverityNumberWarn4:
+		return "warn"
+	case number <= plog.SeverityNumberError4:
+		return "error"
+	default:
+		return "fatal"
+	}
+}
+
+// ecsEventOutcome is the span status, or the HTTP status code if the status is unset.
+func ecsEventOutcome(span ptrace.Span) string {
+	switch span.Status().Code() {
+	case ptrace.StatusCodeOk:
+		return "success"
+	case ptrace.StatusCodeError:
+		return "failure"
+	}
+	statusCode, ok := httpStatusCode(span)
+	if !ok {
+		return "unknown"
+	}
+	// the 4xx are the failures of the clients, not of the servers
+	if statusCode >= 500 || (statusCode >= 400 && span.Kind() != ptrace.SpanKindServer) {
+		return "failure"
+	}
+	return "success"
+}
+
+func ecsTransactionType(span ptrace.Span) string {
+	if span.Kind() == ptrace.SpanKindConsumer {
+		return "messaging"
+	}
+	return "request"
+}
+
+// ecsTransactionResult is the class of the HTTP status code, e.g. HTTP 2xx, or the span status.
+func ecsTransactionResult(span ptrace.Span) string {
+	if statusCode, ok := httpStatusCode(span); ok {
+		return fmt.Sprintf("HTTP %dxx", statusCode/100)
+	}
+	if span.Status().Code() == ptrace.StatusCodeError {
+		return "Error"
+	}
+	return "Success"
+}
+
+// ecsSpanType infers the type and the subtype of a span out of its attributes.
+func ecsSpanType(span ptrace.Span) (string, string) {
+	attributes := span.Attributes()
+	if system, ok := attributes.Get(semconv.AttributeDBSystem); ok {
+		return "db", system.AsString()
+	}
+	if system, ok := attributes.Get(semconv.AttributeMessagingSystem); ok {
+		return "messaging", system.AsString()
+	}
+	if system, ok := attributes.Get(semconv.AttributeRPCSystem); ok {
+		return "external", system.AsString()
+	}
+	if hasHTTPMethod(span) {
+		return "external", "http"
+	}
+	return "app", "internal"
+}
+
+func httpStatusCode(span ptrace.Span) (int64, bool) {
+	for _, key := range []string{semconv.AttributeHTTPStatusCode, "http.response.status_code"} {
+		if value, ok := span.Attributes().Get(key); ok && value.Type() == pcommon.ValueTypeInt {
+			return value.Int(), true
+		}
+	}
+	return 0, false
+}
+
+func hasHTTPMethod(span ptrace.Span) bool {
+	for _, key := range []string{semconv.AttributeHTTPMethod, "http.request.method"} {
+		if _, ok := span.Attributes().Get(key); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearchexporter
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/collector/semconv/v1.18.0"
+)
+
+func TestEncodeLogECS(t *testing.T) {
+	model := &encodeECSModel{encodeModel{dedup: true, dedot: true}}
+	resource := pcommon.NewResource()
+	resource.Attributes().PutStr(semconv.AttributeServiceName, "checkout")
+	resource.Attributes().PutStr(semconv.AttributeHostName, "node-1")
+	resource.Attributes().PutStr(semconv.AttributeTelemetrySDKLanguage, "go")
+	resource.Attributes().PutStr("team", "payments")
+
+	record := plog.NewLogRecord()
+	record.SetTimestamp(pcommon.NewTimestampFromTime(time.Date(2023, 4, 19, 3, 4, 5, 6, time.UTC)))
+	record.SetSeverityNumber(plog.SeverityNumberWarn)
+	record.Body().SetStr("connection refused")
+	record.SetTraceID([16]byte{1, 2, 3, 4, 5, 6, 7, 8, 8, 7, 6, 5, 4, 3, 2, 1})
+	record.Attributes().PutStr(semconv.AttributeExceptionType, "net.OpError")
+	record.Attributes().PutInt("retry.count", 3)
+
+	body, err := model.encodeLog(resource, pcommon.NewInstrumentationScope(), record)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"@timestamp": "2023-04-19T03:04:05.000000006Z",
+		"agent": {"name": "opentelemetry/go"},
+		"error": {"type": "net.OpError"},
+		"event": {"severity": 13},
+		"host": {"hostname": "node-1"},
+		"labels": {"team": "payments"},
+		"log": {"level": "warn"},
+		"message": "connection refused",
+		"numeric_labels": {"retry_count": 3},
+		"service": {"language": {"name": "go"}, "name": "checkout"},
+		"trace": {"id": "01020304050607080807060504030201"}
+	}`, string(body))
+}
+
+func TestEncodeSpanECS(t *testing.T) {
+	model := &encodeECSModel{encodeModel{dedup: true, dedot: true}}
+	resource := pcommon.NewResource()
+	resource.Attributes().PutStr(semconv.AttributeServiceName, "checkout")
+	scope := pcommon.NewInstrumentationScope()
+	scope.SetName("go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp")
+
+	start := time.Date(2023, 4, 19, 3, 4, 5, 0, time.UTC)
+	span := ptrace.NewSpan()
+	span.SetName("POST /cart")
+	span.SetKind(ptrace.SpanKindServer)
+	span.SetTraceID([16]byte{1, 2, 3, 4, 5, 6, 7, 8, 8, 7, 6, 5, 4, 3, 2, 1})
+	span.SetSpanID([8]byte{1, 2, 3, 4, 5, 6, 7, 8})
+	span.SetParentSpanID([8]byte{8, 7, 6, 5, 4, 3, 2, 1})
+	span.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+	span.SetEndTimestamp(pcommon.NewTimestampFromTime(start.Add(1500 * time.Microsecond)))
+	span.Attributes().PutStr(semconv.AttributeHTTPMethod, "POST")
+	span.Attributes().PutInt(semconv.AttributeHTTPStatusCode, 404)
+
+	body, err := model.encodeSpan(resource, scope, span)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"@timestamp": "2023-04-19T03:04:05.000000000Z",
+		"agent": {"name": "otlp"},
+		"event": {"duration": 1500000, "outcome": "success"},
+		"http": {"request": {"method": "POST"}, "response": {"status_code": 404}},
+		"parent": {"id": "0807060504030201"},
+		"processor": {"event": "transaction"},
+		"service": {"framework": {"name": "go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"}, "name": "checkout"},
+		"span": {"kind": "SERVER"},
+		"trace": {"id": "01020304050607080807060504030201"},
+		"transaction": {"duration": {"us": 1500}, "id": "0102030405060708", "name": "POST /cart", "result": "HTTP 4xx", "type": "request"}
+	}`, string(body))
+
+	span.SetKind(ptrace.SpanKindClient)
+	span.Attributes().Clear()
+	span.Attributes().PutStr(semconv.AttributeDBSystem, "postgresql")
+	span.Status().SetCode(ptrace.StatusCodeError)
+	span.Status().SetMessage("deadlock detected")
+	exception := span.Events().AppendEmpty()
+	exception.SetName("exception")
+	exception.Attributes().PutStr(semconv.AttributeExceptionMessage, "deadlock detected")
+	link := span.Links().AppendEmpty()
+	link.SetTraceID([16]byte{1})
+	link.SetSpanID([8]byte{2})
+
+	body, err = model.encodeSpan(resource, scope, span)
+	require.NoError(t, err)
+	var document map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &document))
+	assert.Equal(t, "span", document["processor"].(map[string]interface{})["event"])
+	assert.Equal(t, map[string]interface{}{
+		"id":       "0102030405060708",
+		"name":     "POST /cart",
+		"kind":     "CLIENT",
+		"type":     "db",
+		"subtype":  "postgresql",
+		"duration": map[string]interface{}{"us": 1500.0},
+		"links": []interface{}{map[string]interface{}{
+			"trace": map[string]interface{}{"id": "01000000000000000000000000000000"},
+			"span":  map[string]interface{}{"id": "0200000000000000"},
+		}},
+	}, document["span"])
+	assert.Equal(t, map[string]interface{}{"duration": 1500000.0, "outcome": "failure", "reason": "deadlock detected"}, document["event"])
+	assert.Equal(t, map[string]interface{}{"message": "deadlock detected"}, document["error"])
+	assert.NotContains(t, document, "transaction")
+}
+
+func TestECSEventOutcome(t *testing.T) {
+	tests := map[string]struct {
+		kind       ptrace.SpanKind
+		status     ptrace.StatusCode
+		statusCode int64
+		want       string
+	}{
+		"status ok":           {kind: ptrace.SpanKindClient, status: ptrace.StatusCodeOk, statusCode: 500, want: "success"},
+		"status error":        {kind: ptrace.SpanKindServer, status: ptrace.StatusCodeError, want: "failure"},
+		"unset":               {kind: ptrace.SpanKindInternal, want: "unknown"},
+		"server client error": {kind: ptrace.SpanKindServer, statusCode: 404, want: "success"},
+		"server error":        {kind: ptrace.SpanKindServer, statusCode: 503, want: "failure"},
+		"client error":        {kind: ptrace.SpanKindClient, statusCode: 404, want: "failure"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			span := ptrace.NewSpan()
+			span.SetKind(test.kind)
+			span.Status().SetCode(test.status)
+			if test.statusCode != 0 {
+				span.Attributes().PutInt("http.response.status_code", test.statusCode)
+			}
+			assert.Equal(t, test.want, ecsEventOutcome(span))
+		})
+	}
+}
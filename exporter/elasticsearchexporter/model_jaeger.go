@@ -17,6 +17,7 @@ import (
 	"github.com/jaegertracing/jaeger/plugin/storage/es/spanstore/dbmodel"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 
 	otlp2jaeger "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/jaeger"
@@ -65,7 +66,75 @@ func (m *encodeJaegerModel) encodeSpan(resource pcommon.Resource, scope pcommon.
 	return json.Marshal(convertedSpan)
 }
 
-func (m *encodeJaegerModel) encodeLog(_ pcommon.Resource, _ plog.LogRecord) ([]byte, error) {
+// jaegerLog is the document of a log record in the companion index of the logs, its fields and its
+// process are encoded as the logs and the process of the jaeger spans.
+type jaegerLog struct {
+	TraceID         dbmodel.TraceID    `json:"traceID,omitempty"`
+	SpanID          dbmodel.SpanID     `json:"spanID,omitempty"`
+	Timestamp       uint64             `json:"timestamp"` // microseconds since Unix epoch
+	TimestampMillis uint64             `json:"timestampMillis"`
+	Fields          []dbmodel.KeyValue `json:"fields"`
+	Process         dbmodel.Process    `json:"process"`
+}
+
+// encodeLog converts the log record to the event of a span, so that it is encoded as the jaeger
+// span logs: the body is the `event` field and the severity the `level` field.
+func (m *encodeJaegerModel) encodeLog(resource pcommon.Resource, scope pcommon.InstrumentationScope, record plog.LogRecord) ([]byte, error) {
+	timestamp := record.Timestamp()
+	if timestamp == 0 {
+		timestamp = record.ObservedTimestamp()
+	}
+
+	td := ptrace.NewTraces()
+	resourceSpan := td.ResourceSpans().AppendEmpty()
+	resource.CopyTo(resourceSpan.Resource())
+	ss := resourceSpan.ScopeSpans().AppendEmpty()
+	scope.CopyTo(ss.Scope())
+	span := ss.Spans().AppendEmpty()
+	span.SetTraceID(record.TraceID())
+	span.SetSpanID(record.SpanID())
+	event := span.Events().AppendEmpty()
+	event.SetName(record.Body().AsString())
+	event.SetTimestamp(timestamp)
+	record.Attributes().CopyTo(event.Attributes())
+	if record.SeverityText() != "" {
+		event.Attributes().PutStr("level", record.SeverityText())
+	} else if record.SeverityNumber() != plog.SeverityNumberUnspecified {
+		event.Attributes().PutStr("level", record.SeverityNumber().String())
+	}
+
+	singleBatch, err := otlp2jaeger.ProtoFromTraces(td)
+	if err != nil {
+		return nil, fmt.Errorf("otlp to jaeger error")
+	}
+
+	jSpan := singleBatch[0].GetSpans()[0]
+	if singleBatch[0].Process != nil {
+		jSpan.Process = singleBatch[0].Process
+	} else {
+		jSpan.Process = &model.Process{}
+	}
+	convertedSpan := dbmodel.NewFromDomain(false, []string{}, "@").FromDomainEmbedProcess(jSpan)
+	if len(convertedSpan.Logs) == 0 {
+		return nil, fmt.Errorf("otlp to jaeger error: log record not converted")
+	}
+
+	log := jaegerLog{
+		Timestamp:       convertedSpan.Logs[0].Timestamp,
+		TimestampMillis: convertedSpan.Logs[0].Timestamp / 1000,
+		Fields:          convertedSpan.Logs[0].Fields,
+		Process:         convertedSpan.Process,
+	}
+	if !record.TraceID().IsEmpty() {
+		log.TraceID = convertedSpan.TraceID
+	}
+	if !record.SpanID().IsEmpty() {
+		log.SpanID = convertedSpan.SpanID
+	}
+	return json.Marshal(log)
+}
+
+func (m *encodeJaegerModel) encodeMetricDataPoint(_ pcommon.Resource, _ pcommon.InstrumentationScope, _ pmetric.Metric, _ metricDataPoint) ([]byte, error) {
 	// do nothing
 	return nil, nil
 }
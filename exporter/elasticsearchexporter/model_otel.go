@@ -0,0 +1,131 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearchexporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/elasticsearchexporter"
+
+import (
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/elasticsearchexporter/internal/objmodel"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/coreinternal/traceutil"
+)
+
+// encodeOTelModel encodes the logs and the spans following the OTLP data model: the resource, the scope
+// and the record keep their attributes in separate objects, so that they can not collide.
+//
+// See: https://opentelemetry.io/docs/specs/otel/logs/data-model/
+//
+// Metrics are encoded as the encodeModel does.
+type encodeOTelModel struct {
+	encodeModel
+}
+
+func (m *encodeOTelModel) encodeLog(resource pcommon.Resource, scope pcommon.InstrumentationScope, record plog.LogRecord) ([]byte, error) {
+	var document objmodel.Document
+	timestamp := record.Timestamp()
+	if timestamp == 0 {
+		timestamp = record.ObservedTimestamp()
+	}
+	document.AddTimestamp("@timestamp", timestamp)
+	if record.ObservedTimestamp() != 0 {
+		document.AddTimestamp("observed_timestamp", record.ObservedTimestamp())
+	}
+	document.AddTraceID("trace_id", record.TraceID())
+	document.AddSpanID("span_id", record.SpanID())
+	if record.Flags() != 0 {
+		document.AddInt("flags", int64(record.Flags()))
+	}
+	document.AddString("severity_text", record.SeverityText())
+	if record.SeverityNumber() != plog.SeverityNumberUnspecified {
+		document.AddInt("severity_number", int64(record.SeverityNumber()))
+	}
+	switch record.Body().Type() {
+	case pcommon.ValueTypeEmpty:
+	case pcommon.ValueTypeMap, pcommon.ValueTypeSlice:
+		document.AddAttribute("body.structured", record.Body())
+	default:
+		document.AddString("body.text", record.Body().AsString())
+	}
+	addOTelAttributes(&document, "", record.Attributes(), record.DroppedAttributesCount())
+	addOTelResourceAndScope(&document, resource, scope)
+
+	return m.serialize(&document)
+}
+
+func (m *encodeOTelModel) encodeSpan(resource pcommon.Resource, scope pcommon.InstrumentationScope, span ptrace.Span) ([]byte, error) {
+	var document objmodel.Document
+	document.AddTimestamp("@timestamp", span.StartTimestamp())
+	document.AddTimestamp("end_timestamp", span.EndTimestamp())
+	document.AddInt("duration", int64(span.EndTimestamp()-span.StartTimestamp()))
+	document.AddTraceID("trace_id", span.TraceID())
+	document.AddSpanID("span_id", span.SpanID())
+	document.AddSpanID("parent_span_id", span.ParentSpanID())
+	document.AddString("trace_state", span.TraceState().AsRaw())
+	document.AddString("name", span.Name())
+	document.AddString("kind", traceutil.SpanKindStr(span.Kind()))
+	document.AddString("status.code", traceutil.StatusCodeStr(span.Status().Code()))
+	document.AddString("status.message", span.Status().Message())
+	addOTelAttributes(&document, "", span.Attributes(), span.DroppedAttributesCount())
+	addOTelResourceAndScope(&document, resource, scope)
+
+	if span.Events().Len() > 0 {
+		events := pcommon.NewValueSlice()
+		for i := 0; i < span.Events().Len(); i++ {
+			spanEvent := span.Events().At(i)
+			event := events.Slice().AppendEmpty().SetEmptyMap()
+			event.PutStr("name", spanEvent.Name())
+			event.PutStr("timestamp", spanEvent.Timestamp().AsTime().UTC().Format(time.RFC3339Nano))
+			spanEvent.Attributes().CopyTo(event.PutEmptyMap("attributes"))
+			if spanEvent.DroppedAttributesCount() != 0 {
+				event.PutInt("dropped_attributes_count", int64(spanEvent.DroppedAttributesCount()))
+			}
+		}
+		document.AddAttribute("events", events)
+	}
+	if span.DroppedEventsCount() != 0 {
+		document.AddInt("dropped_events_count", int64(span.DroppedEventsCount()))
+	}
+
+	if span.Links().Len() > 0 {
+		links := pcommon.NewValueSlice()
+		for i := 0; i < span.Links().Len(); i++ {
+			spanLink := span.Links().At(i)
+			link := links.Slice().AppendEmpty().SetEmptyMap()
+			link.PutStr("trace_id", traceutil.TraceIDToHexOrEmptyString(spanLink.TraceID()))
+			link.PutStr("span_id", traceutil.SpanIDToHexOrEmptyString(spanLink.SpanID()))
+			if traceState := spanLink.TraceState().AsRaw(); traceState != "" {
+				link.PutStr("trace_state", traceState)
+			}
+			spanLink.Attributes().CopyTo(link.PutEmptyMap("attributes"))
+			if spanLink.DroppedAttributesCount() != 0 {
+				link.PutInt("dropped_attributes_count", int64(spanLink.DroppedAttributesCount()))
+			}
+		}
+		document.AddAttribute("links", links)
+	}
+	if span.DroppedLinksCount() != 0 {
+		document.AddInt("dropped_links_count", int64(span.DroppedLinksCount()))
+	}
+
+	return m.serialize(&document)
+}
+
+func addOTelResourceAndScope(document *objmodel.Document, resource pcommon.Resource, scope pcommon.InstrumentationScope) {
+	addOTelAttributes(document, "resource.", resource.Attributes(), resource.DroppedAttributesCount())
+	document.AddString("scope.name", scope.Name())
+	document.AddString("scope.version", scope.Version())
+	addOTelAttributes(document, "scope.", scope.Attributes(), scope.DroppedAttributesCount())
+}
+
+// addOTelAttributes adds the attributes under the `attributes` object of the prefix, and their dropped
+// count when attributes were dropped.
+func addOTelAttributes(document *objmodel.Document, prefix string, attributes pcommon.Map, dropped uint32) {
+	document.AddAttributes(prefix+"attributes", attributes)
+	if dropped != 0 {
+		document.AddInt(prefix+"dropped_attributes_count", int64(dropped))
+	}
+}
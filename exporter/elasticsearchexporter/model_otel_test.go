@@ -0,0 +1,109 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package elasticsearchexporter
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func TestEncodeLogOTel(t *testing.T) {
+	model := &encodeOTelModel{encodeModel{dedup: true, dedot: true}}
+	resource := pcommon.NewResource()
+	resource.Attributes().PutStr("service.name", "checkout")
+	resource.SetDroppedAttributesCount(1)
+	scope := pcommon.NewInstrumentationScope()
+	scope.SetName("zap")
+	scope.SetVersion("1.2.3")
+	scope.Attributes().PutStr("service.name", "scope")
+
+	record := plog.NewLogRecord()
+	record.SetTimestamp(pcommon.NewTimestampFromTime(time.Date(2023, 4, 19, 3, 4, 5, 6, time.UTC)))
+	record.SetObservedTimestamp(pcommon.NewTimestampFromTime(time.Date(2023, 4, 19, 3, 4, 6, 0, time.UTC)))
+	record.SetSeverityText("WARN")
+	record.SetSeverityNumber(plog.SeverityNumberWarn)
+	record.SetSpanID([8]byte{1, 2, 3, 4, 5, 6, 7, 8})
+	record.Body().SetStr("connection refused")
+	record.Attributes().PutStr("service.name", "record")
+	record.SetDroppedAttributesCount(2)
+
+	body, err := model.encodeLog(resource, scope, record)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"@timestamp": "2023-04-19T03:04:05.000000006Z",
+		"observed_timestamp": "2023-04-19T03:04:06.000000000Z",
+		"span_id": "0102030405060708",
+		"severity_text": "WARN",
+		"severity_number": 13,
+		"body": {"text": "connection refused"},
+		"attributes": {"service": {"name": "record"}},
+		"dropped_attributes_count": 2,
+		"resource": {"attributes": {"service": {"name": "checkout"}}, "dropped_attributes_count": 1},
+		"scope": {"name": "zap", "version": "1.2.3", "attributes": {"service": {"name": "scope"}}}
+	}`, string(body))
+
+	record.Body().SetEmptyMap().PutStr("user", "alice")
+	body, err = model.encodeLog(resource, scope, record)
+	require.NoError(t, err)
+	var document map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &document))
+	assert.Equal(t, map[string]interface{}{"structured": map[string]interface{}{"user": "alice"}}, document["body"])
+}
+
+func TestEncodeSpanOTel(t *testing.T) {
+	model := &encodeOTelModel{encodeModel{dedup: true, dedot: false}}
+	resource := pcommon.NewResource()
+	resource.Attributes().PutStr("service.name", "checkout")
+	scope := pcommon.NewInstrumentationScope()
+	scope.SetName("otelhttp")
+
+	start := time.Date(2023, 4, 19, 3, 4, 5, 0, time.UTC)
+	span := ptrace.NewSpan()
+	span.SetName("POST /cart")
+	span.SetKind(ptrace.SpanKindServer)
+	span.SetTraceID([16]byte{1, 2, 3, 4, 5, 6, 7, 8, 8, 7, 6, 5, 4, 3, 2, 1})
+	span.SetSpanID([8]byte{1, 2, 3, 4, 5, 6, 7, 8})
+	span.TraceState().FromRaw("vendor=value")
+	span.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+	span.SetEndTimestamp(pcommon.NewTimestampFromTime(start.Add(time.Millisecond)))
+	span.Status().SetCode(ptrace.StatusCodeError)
+	span.Status().SetMessage("deadlock detected")
+	span.Attributes().PutStr("http.method", "POST")
+	span.SetDroppedEventsCount(3)
+	event := span.Events().AppendEmpty()
+	event.SetName("exception")
+	event.SetTimestamp(pcommon.NewTimestampFromTime(start))
+	event.Attributes().PutStr("exception.message", "deadlock detected")
+	link := span.Links().AppendEmpty()
+	link.SetTraceID([16]byte{1})
+	link.SetSpanID([8]byte{2})
+
+	body, err := model.encodeSpan(resource, scope, span)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"@timestamp": "2023-04-19T03:04:05.000000000Z",
+		"end_timestamp": "2023-04-19T03:04:05.001000000Z",
+		"duration": 1000000,
+		"trace_id": "01020304050607080807060504030201",
+		"span_id": "0102030405060708",
+		"trace_state": "vendor=value",
+		"name": "POST /cart",
+		"kind": "SPAN_KIND_SERVER",
+		"status.code": "STATUS_CODE_ERROR",
+		"status.message": "deadlock detected",
+		"attributes.http.method": "POST",
+		"resource.attributes.service.name": "checkout",
+		"scope.name": "otelhttp",
+		"events": [{"name": "exception", "timestamp": "2023-04-19T03:04:05Z", "attributes": {"exception": {"message": "deadlock detected"}}}],
+		"dropped_events_count": 3,
+		"links": [{"trace_id": "01000000000000000000000000000000", "span_id": "0200000000000000"}]
+	}`, string(body))
+}
@@ -9,6 +9,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	semconv "go.opentelemetry.io/collector/semconv/v1.18.0"
 )
@@ -23,6 +25,104 @@ func TestEncodeSpan(t *testing.T) {
 	assert.Equal(t, expectedSpanBody, string(spanByte))
 }
 
+func TestEncodeSpanWithScopeAndStatus(t *testing.T) {
+	model := &encodeModel{dedup: true, dedot: false}
+	td := mockResourceSpans()
+	scope := td.ResourceSpans().At(0).ScopeSpans().At(0).Scope()
+	scope.SetName("io.opentelemetry.contrib.mongodb")
+	scope.SetVersion("1.0.0")
+	scope.Attributes().PutStr("name", "driver")
+	span := td.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0)
+	span.TraceState().FromRaw("rojo=00f067aa0ba902b7")
+	span.Status().SetCode(ptrace.StatusCodeError)
+	span.Status().SetMessage("connection refused")
+	span.Links().At(0).TraceState().FromRaw("congo=t61rcWkgMzE")
+
+	spanByte, err := model.encodeSpan(td.ResourceSpans().At(0).Resource(), scope, span)
+	assert.NoError(t, err)
+	body := string(spanByte)
+	assert.Contains(t, body, `"Scope.name":"io.opentelemetry.contrib.mongodb"`)
+	assert.Contains(t, body, `"Scope.version":"1.0.0"`)
+	assert.Contains(t, body, `"Scope.Attributes.name":"driver"`)
+	assert.Contains(t, body, `"TraceState":"rojo=00f067aa0ba902b7"`)
+	assert.Contains(t, body, `"TraceStatus":2`)
+	assert.Contains(t, body, `"TraceStatusDescription":"connection refused"`)
+	assert.Contains(t, body, `\"traceState\":\"congo=t61rcWkgMzE\"`)
+}
+
+func TestEncodeMetricDataPoint(t *testing.T) {
+	model := &encodeModel{dedup: true, dedot: false}
+	resource := pcommon.NewResource()
+	resource.Attributes().PutStr(semconv.AttributeServiceName, "some-service")
+	scope := pcommon.NewInstrumentationScope()
+	scope.SetName("io.opentelemetry.contrib.redis")
+	ts := pcommon.NewTimestampFromTime(time.Date(2023, 4, 19, 3, 4, 5, 6, time.UTC))
+
+	gauge := pmetric.NewMetric()
+	gauge.SetName("system.cpu.utilization")
+	gauge.SetUnit("1")
+	dp := gauge.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(ts)
+	dp.SetDoubleValue(0.25)
+	dp.Attributes().PutStr("state", "idle")
+	body, err := model.encodeMetricDataPoint(resource, scope, gauge, dp)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"@timestamp":"2023-04-19T03:04:05.000000006Z","Attributes.state":"idle","Flags":0,"Name":"system.cpu.utilization",`+
+		`"Resource.service.name":"some-service","Scope.name":"io.opentelemetry.contrib.redis","StartTimestamp":"1970-01-01T00:00:00.000000000Z",`+
+		`"Type":"Gauge","Unit":"1","Value":0.25}`, string(body))
+
+	sum := pmetric.NewMetric()
+	sum.SetName("http.server.requests")
+	sum.SetEmptySum().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	sum.Sum().SetIsMonotonic(true)
+	sum.Sum().DataPoints().AppendEmpty().SetIntValue(42)
+	body, err = model.encodeMetricDataPoint(resource, scope, sum, sum.Sum().DataPoints().At(0))
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `"AggregationTemporality":"Cumulative","Flags":0,"IsMonotonic":true`)
+	assert.Contains(t, string(body), `"Value":42`)
+
+	histogram := pmetric.NewMetric()
+	histogram.SetName("http.server.duration")
+	hdp := histogram.SetEmptyHistogram().DataPoints().AppendEmpty()
+	hdp.SetCount(3)
+	hdp.SetSum(12.5)
+	hdp.SetMax(10)
+	hdp.BucketCounts().FromRaw([]uint64{1, 2, 0})
+	hdp.ExplicitBounds().FromRaw([]float64{5, 10})
+	body, err = model.encodeMetricDataPoint(resource, scope, histogram, hdp)
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `"BucketCounts":[1,2,0],"Count":3,"ExplicitBounds":[5,10]`)
+	assert.Contains(t, string(body), `"Max":10`)
+	assert.Contains(t, string(body), `"Sum":12.5`)
+	assert.NotContains(t, string(body), `"Min"`)
+
+	exponential := pmetric.NewMetric()
+	exponential.SetName("http.client.duration")
+	edp := exponential.SetEmptyExponentialHistogram().DataPoints().AppendEmpty()
+	edp.SetScale(2)
+	edp.SetZeroCount(1)
+	edp.Positive().SetOffset(-3)
+	edp.Positive().BucketCounts().FromRaw([]uint64{4, 5})
+	body, err = model.encodeMetricDataPoint(resource, scope, exponential, edp)
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `"Positive.BucketCounts":[4,5],"Positive.Offset":-3`)
+	assert.Contains(t, string(body), `"Scale":2`)
+	assert.Contains(t, string(body), `"ZeroCount":1`)
+
+	summary := pmetric.NewMetric()
+	summary.SetName("rpc.duration")
+	sdp := summary.SetEmptySummary().DataPoints().AppendEmpty()
+	sdp.SetCount(10)
+	sdp.SetSum(100)
+	quantile := sdp.QuantileValues().AppendEmpty()
+	quantile.SetQuantile(0.99)
+	quantile.SetValue(20)
+	body, err = model.encodeMetricDataPoint(resource, scope, summary, sdp)
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `"Quantiles":[{"Quantile":0.99,"Value":20}]`)
+	assert.Contains(t, string(body), `"Type":"Summary"`)
+}
+
 func mockResourceSpans() ptrace.Traces {
 	traces := ptrace.NewTraces()
 
@@ -57,3 +157,43 @@ func mockResourceSpans() ptrace.Traces {
 	event.Attributes().PutStr("evnetMockBar", "bar")
 	return traces
 }
+
+func TestEncodeLogJaeger(t *testing.T) {
+	model := NewEncodeJaegerModel()
+	resource := pcommon.NewResource()
+	resource.Attributes().PutStr(semconv.AttributeServiceName, "checkout")
+	resource.Attributes().PutStr(semconv.AttributeHostName, "node-1")
+
+	record := plog.NewLogRecord()
+	record.SetTimestamp(pcommon.NewTimestampFromTime(time.Date(2023, 4, 19, 3, 4, 5, 6000, time.UTC)))
+	record.SetSeverityNumber(plog.SeverityNumberWarn)
+	record.SetTraceID([16]byte{1, 2, 3, 4, 5, 6, 7, 8, 8, 7, 6, 5, 4, 3, 2, 1})
+	record.SetSpanID([8]byte{1, 2, 3, 4, 5, 6, 7, 8})
+	record.Body().SetStr("connection refused")
+	record.Attributes().PutInt("retry", 3)
+
+	body, err := model.encodeLog(resource, pcommon.NewInstrumentationScope(), record)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"traceID": "01020304050607080807060504030201",
+		"spanID": "0102030405060708",
+		"timestamp": 1681873445000006,
+		"timestampMillis": 1681873445000,
+		"fields": [
+			{"key": "event", "type": "string", "value": "connection refused"},
+			{"key": "retry", "type": "int64", "value": "3"},
+			{"key": "level", "type": "string", "value": "Warn"}
+		],
+		"process": {
+			"serviceName": "checkout",
+			"tags": [{"key": "host.name", "type": "string", "value": "node-1"}]
+		}
+	}`, string(body))
+
+	record.SetTraceID(pcommon.NewTraceIDEmpty())
+	record.SetSpanID(pcommon.NewSpanIDEmpty())
+	body, err = model.encodeLog(resource, pcommon.NewInstrumentationScope(), record)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(body), "traceID")
+	assert.NotContains(t, string(body), "spanID")
+}
@@ -8,8 +8,12 @@ package elasticsearchexporter // import "github.com/open-telemetry/opentelemetry
 import (
 	"context"
 	"fmt"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/collector/semconv/v1.18.0"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
 )
@@ -19,20 +23,25 @@ type elasticsearchTracesExporter struct {
 
 	index        string
 	dynamicIndex bool
+	dataStream   DataStreamSettings
 	maxAttempts  int
 
-	client            *esClientCurrent
-	bulkIndexer       esBulkIndexerCurrent
-	model             mappingModel
-	mode              MappingMode
-	jaegerIndices     JaegerIndexAliasSettings
-	elasticsearchInit elasticsearchInit
+	client          *esClientCurrent
+	bulkIndexer     esBulkIndexerCurrent
+	model           mappingModel
+	mode            MappingMode
+	jaegerIndices   JaegerIndexAliasSettings
+	dependencies    *dependencyAggregator
+	indexManager    *indexManager
+	deadLetterQueue *deadLetterQueue
+	syncBulkIndexer *syncBulkIndexer
 }
 
-func newTracesExporter(logger *zap.Logger, cfg *Config) (*elasticsearchTracesExporter, error) {
+func newTracesExporter(set exporter.CreateSettings, cfg *Config) (*elasticsearchTracesExporter, error) {
 	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
+	logger := set.Logger
 
 	client, err := newElasticsearchClient(logger, cfg)
 	if err != nil {
@@ -44,54 +53,97 @@ func newTracesExporter(logger *zap.Logger, cfg *Config) (*elasticsearchTracesExp
 		return nil, err
 	}
 
+	mapping, err := newFieldMapping(cfg.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
 	maxAttempts := 1
 	if cfg.Retry.Enabled {
 		maxAttempts = cfg.Retry.MaxRequests
 	}
 
+	deadLetterQueue, err := newDeadLetterQueue(set, cfg.DeadLetterQueue, dataStreamTypeTraces, bulkIndexer)
+	if err != nil {
+		return nil, err
+	}
+
 	traceExporter := &elasticsearchTracesExporter{
-		logger:        logger,
-		client:        client,
-		bulkIndexer:   bulkIndexer,
-		index:         cfg.TracesIndex,
-		dynamicIndex:  cfg.TracesDynamicIndex.Enabled,
-		maxAttempts:   maxAttempts,
-		jaegerIndices: cfg.JaegerIndexAliasSettings,
+		logger:          logger,
+		client:          client,
+		bulkIndexer:     bulkIndexer,
+		index:           cfg.TracesIndex,
+		dynamicIndex:    cfg.TracesDynamicIndex.Enabled,
+		dataStream:      cfg.DataStream,
+		maxAttempts:     maxAttempts,
+		jaegerIndices:   cfg.JaegerIndexAliasSettings,
+		deadLetterQueue: deadLetterQueue,
+		syncBulkIndexer: newSyncBulkIndexer(logger, client, cfg, maxAttempts, deadLetterQueue),
 	}
 
 	if m, ok := mappingModes[cfg.Mapping.Mode]; ok {
 		switch m {
 		case MappingECS:
-			traceExporter.model = &encodeModel{dedup: cfg.Mapping.Dedup, dedot: cfg.Mapping.Dedot}
+			traceExporter.model = &encodeECSModel{encodeModel{dedup: cfg.Mapping.Dedup, dedot: cfg.Mapping.Dedot, mapping: mapping}}
 			traceExporter.mode = MappingECS
+		case MappingOTel:
+			traceExporter.model = &encodeOTelModel{encodeModel{dedup: cfg.Mapping.Dedup, dedot: cfg.Mapping.Dedot, mapping: mapping}}
+			traceExporter.mode = MappingOTel
 		case MappingJaeger:
 			traceExporter.model = NewEncodeJaegerModel()
 			traceExporter.index = traceExporter.jaegerIndices.Span
 			traceExporter.mode = MappingJaeger
-			traceExporter.elasticsearchInit = elasticsearchInit{
-				log:    logger,
-				client: traceExporter.client,
-				esILM:  cfg.JaegerIndexAliasSettings.ILM,
+			if cfg.JaegerIndexAliasSettings.Dependencies.Enabled {
+				traceExporter.dependencies = newDependencyAggregator(logger, cfg.JaegerIndexAliasSettings.Dependencies, bulkIndexer, maxAttempts, deadLetterQueue)
 			}
-			traceExporter.elasticsearchInit.init()
 
 		default:
-			traceExporter.model = &encodeModel{dedup: cfg.Mapping.Dedup, dedot: cfg.Mapping.Dedot}
-			traceExporter.mode = MappingECS
+			traceExporter.model = &encodeModel{dedup: cfg.Mapping.Dedup, dedot: cfg.Mapping.Dedot, mapping: mapping}
+			traceExporter.mode = MappingNone
 		}
 	}
 
+	traceExporter.indexManager, err = newIndexManager(logger, client, cfg, dataStreamTypeTraces, cfg.TracesIndex)
+	if err != nil {
+		return nil, err
+	}
+
 	return traceExporter, nil
 }
 
+// Start installs the templates of the data streams when the data stream mode is enabled, the mappings
+// of the ecs and otel mapping modes, and the indices of the jaeger mapping mode, then opens the dead
+// letter queue and schedules the writes of the jaeger dependencies.
+func (e *elasticsearchTracesExporter) Start(ctx context.Context, host component.Host) error {
+	if err := e.indexManager.apply(ctx); err != nil {
+		return err
+	}
+	if err := e.deadLetterQueue.start(ctx, host); err != nil {
+		return err
+	}
+	if e.dependencies != nil {
+		e.dependencies.start()
+	}
+	return nil
+}
+
 func (e *elasticsearchTracesExporter) Shutdown(ctx context.Context) error {
-	return e.bulkIndexer.Close(ctx)
+	var errs []error
+	if e.dependencies != nil {
+		errs = append(errs, e.dependencies.shutdown(ctx))
+	}
+	e.deadLetterQueue.stopReplay()
+	errs = append(errs, e.bulkIndexer.Close(ctx), e.deadLetterQueue.shutdown(ctx))
+	return multierr.Combine(errs...)
 }
 
 func (e *elasticsearchTracesExporter) pushTraceData(
 	ctx context.Context,
 	td ptrace.Traces,
 ) error {
+	if e.syncBulkIndexer != nil {
+		return e.pushTraceDataSync(ctx, td)
+	}
 	var errs []error
 	resourceSpans := td.ResourceSpans()
 	for i := 0; i < resourceSpans.Len(); i++ {
@@ -109,6 +161,11 @@ func (e *elasticsearchTracesExporter) pushTraceData(
 					errs = append(errs, err)
 				}
 
+				if e.dependencies != nil {
+					serviceName, _ := findAttributeValue(semconv.AttributeServiceName, resource.Attributes())
+					e.dependencies.add(serviceName, spans.At(k))
+				}
+
 				// only need to push service metadata record for jaeger now.
 				if e.mode == MappingJaeger {
 					if err := e.pushJaegerServiceNameOperationRecord(ctx, resource, spans.At(k)); err != nil {
@@ -125,9 +182,89 @@ func (e *elasticsearchTracesExporter) pushTraceData(
 	return multierr.Combine(errs...)
 }
 
+// pushTraceDataSync indexes the spans, and the services and operations of the jaeger mapping mode, in
+// bulk requests of their own. The spans failing with a retryable status are returned in a
+// consumererror.Traces for the exporterhelper to retry them, the other failures are permanent. The
+// services and operations have deterministic IDs and are written again with the next spans, their
+// failures never fail a span. Only the indexed spans are linked into jaeger dependencies, so that the
+// retried spans are not counted twice.
+func (e *elasticsearchTracesExporter) pushTraceDataSync(ctx context.Context, td ptrace.Traces) error {
+	var errs []error
+	var docs []bulkDocument
+
+	record := 0
+	resourceSpans := td.ResourceSpans()
+	for i := 0; i < resourceSpans.Len(); i++ {
+		il := resourceSpans.At(i)
+		resource := il.Resource()
+		scopeSpans := il.ScopeSpans()
+		for j := 0; j < scopeSpans.Len(); j++ {
+			ils := scopeSpans.At(j)
+			spans := ils.Spans()
+			for k := 0; k < spans.Len(); k++ {
+				index, document, err := e.encodeTraceRecord(resource, ils.Scope(), spans.At(k))
+				if err != nil {
+					errs = append(errs, err)
+				} else {
+					docs = append(docs, bulkDocument{index: index, document: document, record: record})
+				}
+
+				if e.mode == MappingJaeger {
+					id, document, err := e.model.encodeServiceNameOperation(resource, spans.At(k))
+					if err != nil {
+						errs = append(errs, fmt.Errorf("Failed to encode service name and operation record: %w", err))
+					} else {
+						docs = append(docs, bulkDocument{index: e.jaegerIndices.ServiceName, documentID: id, document: document, record: noRecord})
+					}
+				}
+				record++
+			}
+		}
+	}
+
+	failed, err := e.syncBulkIndexer.index(ctx, docs)
+	err = multierr.Combine(append(errs, err)...)
+
+	if e.dependencies != nil {
+		record = 0
+		for i := 0; i < resourceSpans.Len(); i++ {
+			serviceName, _ := findAttributeValue(semconv.AttributeServiceName, resourceSpans.At(i).Resource().Attributes())
+			scopeSpans := resourceSpans.At(i).ScopeSpans()
+			for j := 0; j < scopeSpans.Len(); j++ {
+				spans := scopeSpans.At(j).Spans()
+				for k := 0; k < spans.Len(); k++ {
+					if !failed[record] {
+						e.dependencies.add(serviceName, spans.At(k))
+					}
+					record++
+				}
+			}
+		}
+	}
+
+	switch {
+	case len(failed) > 0:
+		return consumererror.NewTraces(err, tracesSubset(td, failed))
+	case err != nil:
+		return consumererror.NewPermanent(err)
+	}
+	return nil
+}
+
 func (e *elasticsearchTracesExporter) pushTraceRecord(ctx context.Context, resource pcommon.Resource, scope pcommon.InstrumentationScope, span ptrace.Span) error {
+	index, document, err := e.encodeTraceRecord(resource, scope, span)
+	if err != nil {
+		return err
+	}
+	return pushDocuments(ctx, e.logger, index, "", document, e.bulkIndexer, e.maxAttempts, e.deadLetterQueue)
+}
+
+// encodeTraceRecord returns the index and the document of a span.
+func (e *elasticsearchTracesExporter) encodeTraceRecord(resource pcommon.Resource, scope pcommon.InstrumentationScope, span ptrace.Span) (string, []byte, error) {
 	fIndex := e.index
-	if e.dynamicIndex {
+	if e.dataStream.Enabled {
+		fIndex = dataStreamIndex(dataStreamTypeTraces, e.dataStream, resource, span)
+	} else if e.dynamicIndex {
 		prefix := getFromBothResourceAndAttribute(indexPrefix, resource, span)
 		suffix := getFromBothResourceAndAttribute(indexSuffix, resource, span)
 
@@ -136,9 +273,9 @@ func (e *elasticsearchTracesExporter) pushTraceRecord(ctx context.Context, resou
 
 	document, err := e.model.encodeSpan(resource, scope, span)
 	if err != nil {
-		return fmt.Errorf("Failed to encode trace record: %w", err)
+		return "", nil, fmt.Errorf("Failed to encode trace record: %w", err)
 	}
-	return pushDocuments(ctx, e.logger, fIndex, "", document, e.bulkIndexer, e.maxAttempts)
+	return fIndex, document, nil
 }
 
 func (e *elasticsearchTracesExporter) pushJaegerServiceNameOperationRecord(ctx context.Context, resource pcommon.Resource, span ptrace.Span) error {
@@ -146,5 +283,25 @@ func (e *elasticsearchTracesExporter) pushJaegerServiceNameOperationRecord(ctx c
 	if err != nil {
 		return fmt.Errorf("Failed to encode service name and operation record: %w", err)
 	}
-	return pushDocuments(ctx, e.logger, e.jaegerIndices.ServiceName, id, document, e.bulkIndexer, e.maxAttempts)
+	return pushDocuments(ctx, e.logger, e.jaegerIndices.ServiceName, id, document, e.bulkIndexer, e.maxAttempts, e.deadLetterQueue)
+}
+
+// tracesSubset returns a copy of the batch holding the spans at the given positions.
+func tracesSubset(td ptrace.Traces, records map[int]bool) ptrace.Traces {
+	subset := ptrace.NewTraces()
+	td.CopyTo(subset)
+
+	record := 0
+	subset.ResourceSpans().RemoveIf(func(rs ptrace.ResourceSpans) bool {
+		rs.ScopeSpans().RemoveIf(func(ss ptrace.ScopeSpans) bool {
+			ss.Spans().RemoveIf(func(ptrace.Span) bool {
+				keep := records[record]
+				record++
+				return !keep
+			})
+			return ss.Spans().Len() == 0
+		})
+		return rs.ScopeSpans().Len() == 0
+	})
+	return subset
 }
@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"os"
 	"runtime"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -18,8 +19,10 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.uber.org/zap"
-	"go.uber.org/zap/zaptest"
 )
 
 func TestTracesExporter_New(t *testing.T) {
@@ -110,7 +113,7 @@ func TestTracesExporter_New(t *testing.T) {
 				os.Setenv(k, v)
 			}
 
-			exporter, err := newTracesExporter(zap.NewNop(), test.config)
+			exporter, err := newTracesExporter(exportertest.NewNopCreateSettings(), test.config)
 			if exporter != nil {
 				defer func() {
 					require.NoError(t, exporter.Shutdown(context.TODO()))
@@ -186,6 +189,32 @@ func TestExporter_PushTraceRecord(t *testing.T) {
 		rec.WaitItems(1)
 	})
 
+	t.Run("publish with data stream", func(t *testing.T) {
+		rec := newBulkRecorder()
+		server := newESTestServer(t, func(docs []itemRequest) ([]itemResponse, error) {
+			rec.Record(docs)
+
+			jsonVal := map[string]map[string]interface{}{}
+			assert.Nil(t, json.Unmarshal(docs[0].Action, &jsonVal))
+			assert.Equal(t, "traces-generic-checkout_eu", jsonVal["create"]["_index"])
+
+			return itemsAllOK(docs)
+		})
+
+		exporter := newTestTracesExporter(t, server.URL, func(cfg *Config) {
+			cfg.DataStream.Enabled = true
+		})
+
+		mustSendTracesWithAttributes(t, exporter,
+			map[string]string{
+				dataStreamNamespace: "checkout-eu",
+			},
+			map[string]string{},
+		)
+
+		rec.WaitItems(1)
+	})
+
 	t.Run("retry http request", func(t *testing.T) {
 		failures := 0
 		rec := newBulkRecorder()
@@ -349,8 +378,102 @@ func TestExporter_PushTraceRecord(t *testing.T) {
 		assert.Equal(t, [3]int{1, 2, 1}, attempts)
 	})
 }
+
+func TestExporter_PushTraceDataSync(t *testing.T) {
+	var spanAttempts atomic.Int64
+	server := newESTestServer(t, func(docs []itemRequest) ([]itemResponse, error) {
+		resp := make([]itemResponse, len(docs))
+		for i, doc := range docs {
+			resp[i].Status = http.StatusCreated
+			switch {
+			case strings.Contains(string(doc.Action), `"_id"`):
+				// the services and operations are already known
+				resp[i].Status = http.StatusConflict
+			case strings.Contains(string(doc.Document), "retry"):
+				spanAttempts.Add(1)
+				resp[i].Status = http.StatusTooManyRequests
+			}
+		}
+		return resp, nil
+	})
+
+	exporter := newTestTracesExporter(t, server.URL, func(cfg *Config) {
+		cfg.BulkMode = BulkModeSync
+		cfg.Mapping.Mode = "jaeger"
+		cfg.JaegerIndexAliasSettings.Span = "jaeger-span-write"
+		cfg.JaegerIndexAliasSettings.ServiceName = "jaeger-service-write"
+		cfg.Retry.InitialInterval = time.Millisecond
+		cfg.Retry.MaxInterval = 10 * time.Millisecond
+	})
+
+	traces := ptrace.NewTraces()
+	resourceSpans := traces.ResourceSpans().AppendEmpty()
+	resourceSpans.Resource().Attributes().PutStr("service.name", "frontend")
+	spans := resourceSpans.ScopeSpans().AppendEmpty().Spans()
+	for i, name := range []string{"test1", "retry", "test3"} {
+		span := spans.AppendEmpty()
+		span.SetName(name)
+		span.SetTraceID([16]byte{1})
+		span.SetSpanID([8]byte{byte(i + 1)})
+	}
+
+	err := exporter.pushTraceData(context.TODO(), traces)
+	var tracesErr consumererror.Traces
+	require.True(t, errors.As(err, &tracesErr))
+	failed := tracesErr.Data()
+	require.Equal(t, 1, failed.SpanCount())
+	assert.Equal(t, "retry", failed.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Name())
+	assert.Equal(t, "frontend", failed.ResourceSpans().At(0).Resource().Attributes().AsRaw()["service.name"])
+	assert.Equal(t, int64(exporter.maxAttempts), spanAttempts.Load())
+	assert.NotContains(t, err.Error(), "409")
+
+	// the failed span is not linked before it is indexed
+	assert.Len(t, exporter.dependencies.spans, 2)
+}
+
+func TestExporter_PushTraceDataSyncServiceFailure(t *testing.T) {
+	var serviceAttempts atomic.Int64
+	rec := newBulkRecorder()
+	server := newESTestServer(t, func(docs []itemRequest) ([]itemResponse, error) {
+		resp := make([]itemResponse, len(docs))
+		for i, doc := range docs {
+			resp[i].Status = http.StatusCreated
+			if strings.Contains(string(doc.Action), `"_id"`) {
+				serviceAttempts.Add(1)
+				resp[i].Status = http.StatusTooManyRequests
+				continue
+			}
+			rec.Record([]itemRequest{doc})
+		}
+		return resp, nil
+	})
+
+	exporter := newTestTracesExporter(t, server.URL, func(cfg *Config) {
+		cfg.BulkMode = BulkModeSync
+		cfg.Mapping.Mode = "jaeger"
+		cfg.JaegerIndexAliasSettings.Span = "jaeger-span-write"
+		cfg.JaegerIndexAliasSettings.ServiceName = "jaeger-service-write"
+		cfg.Retry.InitialInterval = time.Millisecond
+		cfg.Retry.MaxInterval = 10 * time.Millisecond
+	})
+
+	traces := ptrace.NewTraces()
+	resourceSpans := traces.ResourceSpans().AppendEmpty()
+	resourceSpans.Resource().Attributes().PutStr("service.name", "frontend")
+	span := resourceSpans.ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	span.SetName("test1")
+	span.SetTraceID([16]byte{1})
+	span.SetSpanID([8]byte{1})
+
+	// the indexed span is not returned for a retry, which would index it twice
+	require.NoError(t, exporter.pushTraceData(context.TODO(), traces))
+	assert.Len(t, rec.Items(), 1)
+	assert.Equal(t, int64(exporter.maxAttempts), serviceAttempts.Load())
+	assert.Len(t, exporter.dependencies.spans, 1)
+}
+
 func newTestLogsExporter(t *testing.T, url string, fns ...func(*Config)) *elasticsearchLogsExporter {
-	exporter, err := newLogsExporter(zaptest.NewLogger(t), withTestTracesExporterConfig(fns...)(url))
+	exporter, err := newLogsExporter(newTestCreateSettings(t), withTestTracesExporterConfig(fns...)(url))
 	require.NoError(t, err)
 
 	t.Cleanup(func() {
@@ -360,7 +483,7 @@ func newTestLogsExporter(t *testing.T, url string, fns ...func(*Config)) *elasti
 }
 
 func newTestTracesExporter(t *testing.T, url string, fns ...func(*Config)) *elasticsearchTracesExporter {
-	exporter, err := newTracesExporter(zaptest.NewLogger(t), withTestTracesExporterConfig(fns...)(url))
+	exporter, err := newTracesExporter(newTestCreateSettings(t), withTestTracesExporterConfig(fns...)(url))
 	require.NoError(t, err)
 
 	t.Cleanup(func() {
@@ -383,7 +506,7 @@ func withTestTracesExporterConfig(fns ...func(*Config)) func(string) *Config {
 }
 
 func mustSendTraces(t *testing.T, exporter *elasticsearchTracesExporter, contents string) {
-	err := pushDocuments(context.TODO(), zap.L(), exporter.index, "", []byte(contents), exporter.bulkIndexer, exporter.maxAttempts)
+	err := pushDocuments(context.TODO(), zap.L(), exporter.index, "", []byte(contents), exporter.bulkIndexer, exporter.maxAttempts, nil)
 	require.NoError(t, err)
 }
 
@@ -15,9 +15,12 @@ import (
 	"testing"
 	"time"
 
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/exporter/exportertest"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap/zaptest"
 )
 
 type itemRequest struct {
@@ -251,3 +254,9 @@ func fillResourceAttributeMap(attrs pcommon.Map, mp map[string]string) {
 		attrs.PutStr(k, v)
 	}
 }
+
+func newTestCreateSettings(t *testing.T) exporter.CreateSettings {
+	set := exportertest.NewNopCreateSettings()
+	set.Logger = zaptest.NewLogger(t)
+	return set
+}
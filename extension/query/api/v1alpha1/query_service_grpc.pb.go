@@ -31,12 +31,31 @@ type QueryServiceClient interface {
 	// This can be fixed by first parsing into user-defined envelope with standard JSON library
 	// or string manipulation to remove the envelope. Alternatively generate objects using OpenAPI.
 	GetTrace(ctx context.Context, in *GetTraceRequest, opts ...grpc.CallOption) (*v1.TracesData, error)
+	// ArchiveTrace copies the spans of a trace into the archive storage, which has no retention.
+	// GetTrace reads the archive when the trace is not found in the primary storage.
+	ArchiveTrace(ctx context.Context, in *ArchiveTraceRequest, opts ...grpc.CallOption) (*ArchiveTraceResponse, error)
 	// SearchTraces searches for traces.
 	// See GetTrace for JSON unmarshalling.
 	SearchTraces(ctx context.Context, in *FindTracesRequest, opts ...grpc.CallOption) (*TracesData, error)
+	// SearchSpans returns the matching spans as rows of the requested columns.
+	SearchSpans(ctx context.Context, in *SearchSpansRequest, opts ...grpc.CallOption) (*SearchSpansResponse, error)
+	// Aggregate groups the spans or the log records and aggregates every group.
+	Aggregate(ctx context.Context, in *AggregateRequest, opts ...grpc.CallOption) (*AggregateResponse, error)
 	// SearchTraces searches for traces.
 	// See GetTrace for JSON unmarshalling.
 	SearchLogs(ctx context.Context, in *GetLogsRequest, opts ...grpc.CallOption) (*v11.LogsData, error)
+	// CreateSavedQuery saves a query, its ID is generated.
+	CreateSavedQuery(ctx context.Context, in *SavedQuery, opts ...grpc.CallOption) (*SavedQuery, error)
+	// GetSavedQuery returns a saved query.
+	GetSavedQuery(ctx context.Context, in *GetSavedQueryRequest, opts ...grpc.CallOption) (*SavedQuery, error)
+	// ListSavedQueries returns the saved queries.
+	ListSavedQueries(ctx context.Context, in *ListSavedQueriesRequest, opts ...grpc.CallOption) (*ListSavedQueriesResponse, error)
+	// UpdateSavedQuery replaces a saved query, keeping its ID and creation time.
+	UpdateSavedQuery(ctx context.Context, in *SavedQuery, opts ...grpc.CallOption) (*SavedQuery, error)
+	// DeleteSavedQuery deletes a saved query.
+	DeleteSavedQuery(ctx context.Context, in *DeleteSavedQueryRequest, opts ...grpc.CallOption) (*DeleteSavedQueryResponse, error)
+	// RunSavedQuery runs a saved query over its lookback ending now or at end_time.
+	RunSavedQuery(ctx context.Context, in *RunSavedQueryRequest, opts ...grpc.CallOption) (*RunSavedQueryResponse, error)
 	// GetServices returns service names.
 	GetServices(ctx context.Context, in *GetServicesRequest, opts ...grpc.CallOption) (*ResourcesData, error)
 	// GetOperations returns operation names.
@@ -60,6 +79,15 @@ func (c *queryServiceClient) GetTrace(ctx context.Context, in *GetTraceRequest,
 	return out, nil
 }
 
+func (c *queryServiceClient) ArchiveTrace(ctx context.Context, in *ArchiveTraceRequest, opts ...grpc.CallOption) (*ArchiveTraceResponse, error) {
+	out := new(ArchiveTraceResponse)
+	err := c.cc.Invoke(ctx, "/v1alpha1.QueryService/ArchiveTrace", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *queryServiceClient) SearchTraces(ctx context.Context, in *FindTracesRequest, opts ...grpc.CallOption) (*TracesData, error) {
 	out := new(TracesData)
 	err := c.cc.Invoke(ctx, "/v1alpha1.QueryService/SearchTraces", in, out, opts...)
@@ -69,6 +97,24 @@ func (c *queryServiceClient) SearchTraces(ctx context.Context, in *FindTracesReq
 	return out, nil
 }
 
+func (c *queryServiceClient) SearchSpans(ctx context.Context, in *SearchSpansRequest, opts ...grpc.CallOption) (*SearchSpansResponse, error) {
+	out := new(SearchSpansResponse)
+	err := c.cc.Invoke(ctx, "/v1alpha1.QueryService/SearchSpans", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queryServiceClient) Aggregate(ctx context.Context, in *AggregateRequest, opts ...grpc.CallOption) (*AggregateResponse, error) {
+	out := new(AggregateResponse)
+	err := c.cc.Invoke(ctx, "/v1alpha1.QueryService/Aggregate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *queryServiceClient) SearchLogs(ctx context.Context, in *GetLogsRequest, opts ...grpc.CallOption) (*v11.LogsData, error) {
 	out := new(v11.LogsData)
 	err := c.cc.Invoke(ctx, "/v1alpha1.QueryService/SearchLogs", in, out, opts...)
@@ -78,6 +124,60 @@ func (c *queryServiceClient) SearchLogs(ctx context.Context, in *GetLogsRequest,
 	return out, nil
 }
 
+func (c *queryServiceClient) CreateSavedQuery(ctx context.Context, in *SavedQuery, opts ...grpc.CallOption) (*SavedQuery, error) {
+	out := new(SavedQuery)
+	err := c.cc.Invoke(ctx, "/v1alpha1.QueryService/CreateSavedQuery", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queryServiceClient) GetSavedQuery(ctx context.Context, in *GetSavedQueryRequest, opts ...grpc.CallOption) (*SavedQuery, error) {
+	out := new(SavedQuery)
+	err := c.cc.Invoke(ctx, "/v1alpha1.QueryService/GetSavedQuery", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queryServiceClient) ListSavedQueries(ctx context.Context, in *ListSavedQueriesRequest, opts ...grpc.CallOption) (*ListSavedQueriesResponse, error) {
+	out := new(ListSavedQueriesResponse)
+	err := c.cc.Invoke(ctx, "/v1alpha1.QueryService/ListSavedQueries", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queryServiceClient) UpdateSavedQuery(ctx context.Context, in *SavedQuery, opts ...grpc.CallOption) (*SavedQuery, error) {
+	out := new(SavedQuery)
+	err := c.cc.Invoke(ctx, "/v1alpha1.QueryService/UpdateSavedQuery", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queryServiceClient) DeleteSavedQuery(ctx context.Context, in *DeleteSavedQueryRequest, opts ...grpc.CallOption) (*DeleteSavedQueryResponse, error) {
+	out := new(DeleteSavedQueryResponse)
+	err := c.cc.Invoke(ctx, "/v1alpha1.QueryService/DeleteSavedQuery", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *queryServiceClient) RunSavedQuery(ctx context.Context, in *RunSavedQueryRequest, opts ...grpc.CallOption) (*RunSavedQueryResponse, error) {
+	out := new(RunSavedQueryResponse)
+	err := c.cc.Invoke(ctx, "/v1alpha1.QueryService/RunSavedQuery", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *queryServiceClient) GetServices(ctx context.Context, in *GetServicesRequest, opts ...grpc.CallOption) (*ResourcesData, error) {
 	out := new(ResourcesData)
 	err := c.cc.Invoke(ctx, "/v1alpha1.QueryService/GetServices", in, out, opts...)
@@ -106,12 +206,31 @@ type QueryServiceServer interface {
 	// This can be fixed by first parsing into user-defined envelope with standard JSON library
 	// or string manipulation to remove the envelope. Alternatively generate objects using OpenAPI.
 	GetTrace(context.Context, *GetTraceRequest) (*v1.TracesData, error)
+	// ArchiveTrace copies the spans of a trace into the archive storage, which has no retention.
+	// GetTrace reads the archive when the trace is not found in the primary storage.
+	ArchiveTrace(context.Context, *ArchiveTraceRequest) (*ArchiveTraceResponse, error)
 	// SearchTraces searches for traces.
 	// See GetTrace for JSON unmarshalling.
 	SearchTraces(context.Context, *FindTracesRequest) (*TracesData, error)
+	// SearchSpans returns the matching spans as rows of the requested columns.
+	SearchSpans(context.Context, *SearchSpansRequest) (*SearchSpansResponse, error)
+	// Aggregate groups the spans or the log records and aggregates every group.
+	Aggregate(context.Context, *AggregateRequest) (*AggregateResponse, error)
 	// SearchTraces searches for traces.
 	// See GetTrace for JSON unmarshalling.
 	SearchLogs(context.Context, *GetLogsRequest) (*v11.LogsData, error)
+	// CreateSavedQuery saves a query, its ID is generated.
+	CreateSavedQuery(context.Context, *SavedQuery) (*SavedQuery, error)
+	// GetSavedQuery returns a saved query.
+	GetSavedQuery(context.Context, *GetSavedQueryRequest) (*SavedQuery, error)
+	// ListSavedQueries returns the saved queries.
+	ListSavedQueries(context.Context, *ListSavedQueriesRequest) (*ListSavedQueriesResponse, error)
+	// UpdateSavedQuery replaces a saved query, keeping its ID and creation time.
+	UpdateSavedQuery(context.Context, *SavedQuery) (*SavedQuery, error)
+	// DeleteSavedQuery deletes a saved query.
+	DeleteSavedQuery(context.Context, *DeleteSavedQueryRequest) (*DeleteSavedQueryResponse, error)
+	// RunSavedQuery runs a saved query over its lookback ending now or at end_time.
+	RunSavedQuery(context.Context, *RunSavedQueryRequest) (*RunSavedQueryResponse, error)
 	// GetServices returns service names.
 	GetServices(context.Context, *GetServicesRequest) (*ResourcesData, error)
 	// GetOperations returns operation names.
@@ -125,12 +244,39 @@ type UnimplementedQueryServiceServer struct {
 func (UnimplementedQueryServiceServer) GetTrace(context.Context, *GetTraceRequest) (*v1.TracesData, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetTrace not implemented")
 }
+func (UnimplementedQueryServiceServer) ArchiveTrace(context.Context, *ArchiveTraceRequest) (*ArchiveTraceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ArchiveTrace not implemented")
+}
 func (UnimplementedQueryServiceServer) SearchTraces(context.Context, *FindTracesRequest) (*TracesData, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SearchTraces not implemented")
 }
+func (UnimplementedQueryServiceServer) SearchSpans(context.Context, *SearchSpansRequest) (*SearchSpansResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchSpans not implemented")
+}
+func (UnimplementedQueryServiceServer) Aggregate(context.Context, *AggregateRequest) (*AggregateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Aggregate not implemented")
+}
 func (UnimplementedQueryServiceServer) SearchLogs(context.Context, *GetLogsRequest) (*v11.LogsData, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SearchLogs not implemented")
 }
+func (UnimplementedQueryServiceServer) CreateSavedQuery(context.Context, *SavedQuery) (*SavedQuery, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateSavedQuery not implemented")
+}
+func (UnimplementedQueryServiceServer) GetSavedQuery(context.Context, *GetSavedQueryRequest) (*SavedQuery, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSavedQuery not implemented")
+}
+func (UnimplementedQueryServiceServer) ListSavedQueries(context.Context, *ListSavedQueriesRequest) (*ListSavedQueriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSavedQueries not implemented")
+}
+func (UnimplementedQueryServiceServer) UpdateSavedQuery(context.Context, *SavedQuery) (*SavedQuery, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateSavedQuery not implemented")
+}
+func (UnimplementedQueryServiceServer) DeleteSavedQuery(context.Context, *DeleteSavedQueryRequest) (*DeleteSavedQueryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSavedQuery not implemented")
+}
+func (UnimplementedQueryServiceServer) RunSavedQuery(context.Context, *RunSavedQueryRequest) (*RunSavedQueryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RunSavedQuery not implemented")
+}
 func (UnimplementedQueryServiceServer) GetServices(context.Context, *GetServicesRequest) (*ResourcesData, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetServices not implemented")
 }
@@ -167,6 +313,24 @@ func _QueryService_GetTrace_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _QueryService_ArchiveTrace_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ArchiveTraceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServiceServer).ArchiveTrace(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1alpha1.QueryService/ArchiveTrace",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServiceServer).ArchiveTrace(ctx, req.(*ArchiveTraceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _QueryService_SearchTraces_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(FindTracesRequest)
 	if err := dec(in); err != nil {
@@ -185,6 +349,42 @@ func _QueryService_SearchTraces_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _QueryService_SearchSpans_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchSpansRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServiceServer).SearchSpans(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1alpha1.QueryService/SearchSpans",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServiceServer).SearchSpans(ctx, req.(*SearchSpansRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QueryService_Aggregate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AggregateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServiceServer).Aggregate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1alpha1.QueryService/Aggregate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServiceServer).Aggregate(ctx, req.(*AggregateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _QueryService_SearchLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetLogsRequest)
 	if err := dec(in); err != nil {
@@ -203,6 +403,114 @@ func _QueryService_SearchLogs_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _QueryService_CreateSavedQuery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SavedQuery)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServiceServer).CreateSavedQuery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1alpha1.QueryService/CreateSavedQuery",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServiceServer).CreateSavedQuery(ctx, req.(*SavedQuery))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QueryService_GetSavedQuery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSavedQueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServiceServer).GetSavedQuery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1alpha1.QueryService/GetSavedQuery",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServiceServer).GetSavedQuery(ctx, req.(*GetSavedQueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QueryService_ListSavedQueries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSavedQueriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServiceServer).ListSavedQueries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1alpha1.QueryService/ListSavedQueries",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServiceServer).ListSavedQueries(ctx, req.(*ListSavedQueriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QueryService_UpdateSavedQuery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SavedQuery)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServiceServer).UpdateSavedQuery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1alpha1.QueryService/UpdateSavedQuery",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServiceServer).UpdateSavedQuery(ctx, req.(*SavedQuery))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QueryService_DeleteSavedQuery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSavedQueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServiceServer).DeleteSavedQuery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1alpha1.QueryService/DeleteSavedQuery",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServiceServer).DeleteSavedQuery(ctx, req.(*DeleteSavedQueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _QueryService_RunSavedQuery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunSavedQueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServiceServer).RunSavedQuery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/v1alpha1.QueryService/RunSavedQuery",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServiceServer).RunSavedQuery(ctx, req.(*RunSavedQueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _QueryService_GetServices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetServicesRequest)
 	if err := dec(in); err != nil {
@@ -250,14 +558,50 @@ var QueryService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetTrace",
 			Handler:    _QueryService_GetTrace_Handler,
 		},
+		{
+			MethodName: "ArchiveTrace",
+			Handler:    _QueryService_ArchiveTrace_Handler,
+		},
 		{
 			MethodName: "SearchTraces",
 			Handler:    _QueryService_SearchTraces_Handler,
 		},
+		{
+			MethodName: "SearchSpans",
+			Handler:    _QueryService_SearchSpans_Handler,
+		},
+		{
+			MethodName: "Aggregate",
+			Handler:    _QueryService_Aggregate_Handler,
+		},
 		{
 			MethodName: "SearchLogs",
 			Handler:    _QueryService_SearchLogs_Handler,
 		},
+		{
+			MethodName: "CreateSavedQuery",
+			Handler:    _QueryService_CreateSavedQuery_Handler,
+		},
+		{
+			MethodName: "GetSavedQuery",
+			Handler:    _QueryService_GetSavedQuery_Handler,
+		},
+		{
+			MethodName: "ListSavedQueries",
+			Handler:    _QueryService_ListSavedQueries_Handler,
+		},
+		{
+			MethodName: "UpdateSavedQuery",
+			Handler:    _QueryService_UpdateSavedQuery_Handler,
+		},
+		{
+			MethodName: "DeleteSavedQuery",
+			Handler:    _QueryService_DeleteSavedQuery_Handler,
+		},
+		{
+			MethodName: "RunSavedQuery",
+			Handler:    _QueryService_RunSavedQuery_Handler,
+		},
 		{
 			MethodName: "GetServices",
 			Handler:    _QueryService_GetServices_Handler,
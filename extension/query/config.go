@@ -1,6 +1,9 @@
 package query
 
 import (
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/handler"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource/clickhouse"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource/es"
@@ -19,6 +22,35 @@ type Config struct {
 	TracingQuery *plugin.StorageConfig `mapstructure:"tracing_query"`
 	MetricsQuery *plugin.StorageConfig `mapstructure:"metrics_query"`
 	LoggingQuery *plugin.StorageConfig `mapstructure:"logging_query"`
+	// SavedQueries is the storage of the saved queries, they are disabled without one.
+	SavedQueries *plugin.SavedQueriesConfig `mapstructure:"saved_queries"`
+	HealthCheck  HealthCheckSettings        `mapstructure:"health_check"`
+	Limits       handler.Limits             `mapstructure:"limits"`
+	APIs         APIs                       `mapstructure:"apis"`
+	// Alerting evaluates alert rules over the tracing and logging datasources.
+	Alerting handler.AlertingConfig `mapstructure:"alerting"`
+}
+
+// APIs enables the compatibility HTTP APIs served next to the gRPC gateway.
+type APIs struct {
+	// Zipkin serves the Zipkin v2 read API under `/api/v2`.
+	Zipkin bool `mapstructure:"zipkin"`
+	// Tempo serves the Grafana Tempo search and trace API, with a TraceQL subset, under `/api`.
+	Tempo bool `mapstructure:"tempo"`
+	// Loki serves the Grafana Loki log query API, with a LogQL subset, under `/loki/api/v1`.
+	Loki bool `mapstructure:"loki"`
+	// Prometheus serves the Prometheus query API, with a PromQL engine over the metrics datasource,
+	// under `/api/v1`.
+	Prometheus bool `mapstructure:"prometheus"`
+}
+
+// HealthCheckSettings configures how datasources are checked and initialized.
+type HealthCheckSettings struct {
+	// Interval between datasource connectivity checks, also used to retry
+	// the initialization of datasources that are unavailable at start.
+	Interval time.Duration `mapstructure:"interval"`
+	// Timeout of a single connectivity check.
+	Timeout time.Duration `mapstructure:"timeout"`
 }
 
 type Storage struct {
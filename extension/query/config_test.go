@@ -3,6 +3,7 @@ package query
 import (
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -62,4 +63,9 @@ func TestLoadConfig(t *testing.T) {
 	r0 := cfg.Extensions[component.NewID(typeStr)]
 	queryConfig := r0.(*Config)
 	assert.Equal(t, queryConfig.TracingQuery.StorageType, defaultCfg.(*Config).TracingQuery.StorageType)
+	require.Len(t, queryConfig.Alerting.Rules, 1)
+	assert.Equal(t, 0.05, queryConfig.Alerting.Rules[0].Threshold)
+	assert.Equal(t, 2*time.Minute, queryConfig.Alerting.Rules[0].For)
+	require.Len(t, queryConfig.Alerting.Receivers, 1)
+	assert.Equal(t, "http://localhost:9093", queryConfig.Alerting.Receivers[0].Endpoint)
 }
@@ -2,6 +2,7 @@ package query
 
 import (
 	"context"
+	"time"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/configgrpc"
@@ -9,6 +10,7 @@ import (
 	"go.opentelemetry.io/collector/config/confignet"
 	"go.opentelemetry.io/collector/extension"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/handler"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource/clickhouse"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource/es"
@@ -20,6 +22,15 @@ const (
 	// Default endpoints to bind to.
 	defaultGRPCBindEndpoint = "0.0.0.0:9090"
 	defaultHTTPBindEndpoint = "0.0.0.0:8080"
+
+	defaultHealthCheckInterval = 10 * time.Second
+	defaultHealthCheckTimeout  = 5 * time.Second
+
+	defaultRange            = time.Hour
+	defaultMaxRange         = 7 * 24 * time.Hour
+	defaultRequestTimeout   = 30 * time.Second
+	defaultMaxNumTraces     = 1000
+	defaultMaxSpansPerTrace = 10000
 )
 
 // NewFactory creates a factory for the otlp query extension.
@@ -52,10 +63,22 @@ func createDefaultConfig() component.Config {
 		TracingQuery: &plugin.StorageConfig{},
 		LoggingQuery: &plugin.StorageConfig{},
 		MetricsQuery: &plugin.StorageConfig{},
+		SavedQueries: &plugin.SavedQueriesConfig{},
+		HealthCheck: HealthCheckSettings{
+			Interval: defaultHealthCheckInterval,
+			Timeout:  defaultHealthCheckTimeout,
+		},
+		Limits: handler.Limits{
+			MaxRange:         defaultMaxRange,
+			DefaultRange:     defaultRange,
+			RequestTimeout:   defaultRequestTimeout,
+			MaxNumTraces:     defaultMaxNumTraces,
+			MaxSpansPerTrace: defaultMaxSpansPerTrace,
+		},
 	}
 }
 
 func createExtension(_ context.Context, set extension.CreateSettings, cfg component.Config) (extension.Extension, error) {
 	c := cfg.(*Config)
-	return NewQueryServer(c, set.TelemetrySettings), nil
+	return NewQueryServer(c, set.TelemetrySettings)
 }
@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/api/v1alpha1"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	aggregateDefaultLimit = 10
+	aggregateMaxLimit     = 1000
+	// aggregateMaxBuckets bounds the time buckets of a group, as the points of a Prometheus series.
+	aggregateMaxBuckets = 11000
+)
+
+// spanGroupFields and logGroupFields are the intrinsic fields grouping the spans and the log records.
+var (
+	spanGroupFields = map[string]bool{
+		datasource.FieldServiceName: true,
+		datasource.FieldName:        true,
+		datasource.FieldKind:        true,
+		datasource.FieldStatusCode:  true,
+	}
+	logGroupFields = map[string]bool{
+		datasource.FieldServiceName:  true,
+		datasource.FieldBody:         true,
+		datasource.FieldSeverityText: true,
+	}
+)
+
+// Aggregate groups the spans or the log records and aggregates every group.
+func (t *Handler) Aggregate(ctx context.Context, request *v1alpha1.AggregateRequest) (*v1alpha1.AggregateResponse, error) {
+	agg, err := parseAggregateParameters(request)
+	if err != nil {
+		return nil, err
+	}
+	var start, end time.Time
+	if request.StartTime != nil {
+		start = request.StartTime.AsTime()
+	}
+	if request.EndTime != nil {
+		end = request.EndTime.AsTime()
+	}
+	if err = t.Limits.applyTimeRange(&start, &end, time.Now()); err != nil {
+		return nil, err
+	}
+	if agg.Step > 0 && end.Sub(start)/agg.Step > aggregateMaxBuckets {
+		return nil, status.Errorf(codes.InvalidArgument, "the time range exceeds %d steps of %s", aggregateMaxBuckets, agg.Step)
+	}
+
+	var groups []*datasource.AggregateGroup
+	switch q := request.Query.(type) {
+	case *v1alpha1.AggregateRequest_SpanQuery:
+		if err = checkGroupBy(agg.GroupBy, spanGroupFields); err != nil {
+			return nil, err
+		}
+		if q.SpanQuery.StartTime != nil || q.SpanQuery.EndTime != nil {
+			return nil, status.Error(codes.InvalidArgument, "the time range is the start_time and end_time of the request")
+		}
+		params := &datasource.SpanQueryParameters{}
+		if err = parseSpanFilter(q.SpanQuery, params); err != nil {
+			return nil, err
+		}
+		params.StartTime, params.EndTime = start, end
+		tracing := t.QueryService.Tracing()
+		if tracing == nil {
+			return nil, status.Error(codes.Unavailable, "the tracing datasource is not available")
+		}
+		groups, err = tracing.AggregateSpans(ctx, params, agg)
+	case *v1alpha1.AggregateRequest_LogQuery:
+		if err = checkGroupBy(agg.GroupBy, logGroupFields); err != nil {
+			return nil, err
+		}
+		logql, perr := parseLogQL(q.LogQuery.Logql)
+		if perr != nil {
+			return nil, perr
+		}
+		if logql.metric != nil {
+			return nil, status.Error(codes.InvalidArgument, "log_query must be a LogQL log query, not a metric query")
+		}
+		if t.QueryService.Logging() == nil {
+			return nil, status.Error(codes.Unavailable, "the logging datasource is not available")
+		}
+		params := &datasource.LogQueryParameters{LineFilters: logql.lineFilters, StartTime: start, EndTime: end}
+		loki := &LokiHandler{QueryService: t.QueryService}
+		if params.Matchers, err = loki.resolveMatchers(ctx, logql.matchers, start, end); err != nil {
+			return nil, err
+		}
+		groups, err = t.QueryService.Logging().AggregateLogs(ctx, params, agg)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "span_query or log_query is required")
+	}
+	if errors.Is(err, datasource.ErrUnsupportedAggregation) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		zap.S().Errorf("aggregate failed: %s", err)
+		return nil, err
+	}
+
+	response := &v1alpha1.AggregateResponse{GroupBy: agg.GroupBy}
+	for _, a := range agg.Aggregations {
+		response.Aggregations = append(response.Aggregations, aggregationName(a))
+	}
+	for _, g := range groups {
+		group := &v1alpha1.AggregateGroup{Keys: g.Keys, Values: g.Values}
+		for _, b := range g.Buckets {
+			group.Buckets = append(group.Buckets, &v1alpha1.AggregateBucket{Timestamp: timestamppb.New(b.Timestamp), Values: b.Values})
+		}
+		response.Groups = append(response.Groups, group)
+	}
+	return response, nil
+}
+
+func parseAggregateParameters(request *v1alpha1.AggregateRequest) (*datasource.AggregateParameters, error) {
+	agg := &datasource.AggregateParameters{GroupBy: request.GroupBy, Limit: int(request.Limit)}
+	if agg.Limit == 0 {
+		agg.Limit = aggregateDefaultLimit
+	}
+	if agg.Limit < 0 || agg.Limit > aggregateMaxLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "limit %d must be between 1 and %d", agg.Limit, aggregateMaxLimit)
+	}
+	if request.Step != nil {
+		agg.Step = request.Step.AsDuration()
+		if agg.Step < time.Second {
+			return nil, status.Errorf(codes.InvalidArgument, "step %s must be at least one second", agg.Step)
+		}
+	}
+
+	for _, a := range request.Aggregations {
+		aggregation := datasource.Aggregation{
+			Function:   datasource.AggregationFunction(a.Function),
+			Field:      a.Field,
+			Percentile: a.Percentile,
+		}
+		switch aggregation.Function {
+		case datasource.AggregateCount:
+			aggregation.Field = ""
+		case datasource.AggregateSum, datasource.AggregateAvg, datasource.AggregateMin, datasource.AggregateMax:
+		case datasource.AggregatePercentile:
+			if a.Percentile <= 0 || a.Percentile > 100 {
+				return nil, status.Errorf(codes.InvalidArgument, "percentile %v must be between 0 and 100", a.Percentile)
+			}
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "unknown aggregation function %q", a.Function)
+		}
+		if aggregation.Function != datasource.AggregateCount {
+			key, ok := datasource.AttributeField(a.Field)
+			if a.Field != datasource.FieldDuration && (!ok || key == "") {
+				return nil, status.Errorf(codes.InvalidArgument, "%s field %q must be duration or attributes.<key>", a.Function, a.Field)
+			}
+		}
+		agg.Aggregations = append(agg.Aggregations, aggregation)
+	}
+	if len(agg.Aggregations) == 0 {
+		agg.Aggregations = []datasource.Aggregation{{Function: datasource.AggregateCount}}
+	}
+	return agg, nil
+}
+
+// checkGroupBy accepts the intrinsic fields and the attributes.
+func checkGroupBy(groupBy []string, intrinsics map[string]bool) error {
+	for _, field := range groupBy {
+		if intrinsics[field] {
+			continue
+		}
+		if key, ok := datasource.AttributeField(field); ok && key != "" {
+			continue
+		}
+		if key, ok := datasource.ResourceField(field); ok && key != "" {
+			continue
+		}
+		return status.Errorf(codes.InvalidArgument, "unknown group_by field %q", field)
+	}
+	return nil
+}
+
+// aggregationName renders an aggregation, e.g. avg(duration) or percentile(duration, 99).
+func aggregationName(a datasource.Aggregation) string {
+	switch a.Function {
+	case datasource.AggregateCount:
+		return string(a.Function)
+	case datasource.AggregatePercentile:
+		return fmt.Sprintf("%s(%s, %s)", a.Function, a.Field, strconv.FormatFloat(a.Percentile, 'f', -1, 64))
+	}
+	return fmt.Sprintf("%s(%s)", a.Function, a.Field)
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/api/v1alpha1"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestAggregateSpans(t *testing.T) {
+	bucket := time.Date(2023, 4, 19, 3, 0, 0, 0, time.UTC)
+	query := &mockQuery{groups: []*datasource.AggregateGroup{{
+		Keys:    []string{"/cart", "500"},
+		Values:  []float64{12, 250},
+		Buckets: []datasource.AggregateBucket{{Timestamp: bucket, Values: []float64{12, 250}}},
+	}}}
+	h := &Handler{
+		QueryService: &QueryService{TracingQuerySvc: query},
+		Limits:       Limits{MaxRange: 24 * time.Hour, DefaultRange: time.Hour},
+	}
+
+	end := time.Date(2023, 4, 19, 4, 0, 0, 0, time.UTC)
+	res, err := h.Aggregate(context.Background(), &v1alpha1.AggregateRequest{
+		Query: &v1alpha1.AggregateRequest_SpanQuery{SpanQuery: &v1alpha1.SpanQueryParameters{
+			ServiceName: "checkout",
+			SpanKind:    "SPAN_KIND_SERVER",
+		}},
+		GroupBy: []string{"attributes.http.route", "attributes.http.status_code"},
+		Aggregations: []*v1alpha1.Aggregation{
+			{Function: "count"},
+			{Function: "percentile", Field: "duration", Percentile: 99},
+		},
+		Step:      durationpb.New(time.Minute),
+		StartTime: timestamppb.New(end.Add(-time.Hour)),
+		EndTime:   timestamppb.New(end),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"count", "percentile(duration, 99)"}, res.Aggregations)
+	require.Len(t, res.Groups, 1)
+	assert.Equal(t, []string{"/cart", "500"}, res.Groups[0].Keys)
+	assert.Equal(t, []float64{12, 250}, res.Groups[0].Values)
+	require.Len(t, res.Groups[0].Buckets, 1)
+	assert.True(t, bucket.Equal(res.Groups[0].Buckets[0].Timestamp.AsTime()))
+
+	assert.Equal(t, "checkout", query.lastSpanSearch.ServiceName)
+	assert.Equal(t, "SPAN_KIND_SERVER", query.lastSpanSearch.SpanKind)
+	assert.True(t, end.Equal(query.lastSpanSearch.EndTime))
+	assert.Equal(t, aggregateDefaultLimit, query.lastAggregate.Limit)
+	assert.Equal(t, time.Minute, query.lastAggregate.Step)
+	assert.Equal(t, datasource.Aggregation{Function: datasource.AggregatePercentile, Field: "duration", Percentile: 99}, query.lastAggregate.Aggregations[1])
+}
+
+func TestAggregateLogs(t *testing.T) {
+	logQuery := &mockLogQuery{groups: []*datasource.AggregateGroup{{Keys: []string{"connection refused"}, Values: []float64{42}}}}
+	h := &Handler{
+		QueryService: &QueryService{LoggingQuerySvc: logQuery},
+		Limits:       Limits{MaxRange: 24 * time.Hour, DefaultRange: time.Hour},
+	}
+
+	res, err := h.Aggregate(context.Background(), &v1alpha1.AggregateRequest{
+		Query:   &v1alpha1.AggregateRequest_LogQuery{LogQuery: &v1alpha1.LogQueryParameters{Logql: `{service_name="frontend"} |= "error"`}},
+		GroupBy: []string{"body"},
+		Limit:   10,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"count"}, res.Aggregations)
+	require.Len(t, res.Groups, 1)
+	assert.Equal(t, []float64{42}, res.Groups[0].Values)
+
+	assert.Equal(t, []datasource.LabelMatcher{{Name: "service.name", Type: datasource.MatchEqual, Value: "frontend"}}, logQuery.lastQuery.Matchers)
+	assert.Equal(t, []datasource.LineFilter{{Type: datasource.MatchEqual, Value: "error"}}, logQuery.lastQuery.LineFilters)
+	assert.Equal(t, []datasource.Aggregation{{Function: datasource.AggregateCount}}, logQuery.lastAggregate.Aggregations)
+	assert.Equal(t, time.Hour, logQuery.lastQuery.EndTime.Sub(logQuery.lastQuery.StartTime))
+}
+
+func TestAggregateInvalidArgument(t *testing.T) {
+	h := &Handler{
+		QueryService: &QueryService{TracingQuerySvc: &mockQuery{}, LoggingQuerySvc: &mockLogQuery{}},
+		Limits:       Limits{MaxRange: 24 * time.Hour, DefaultRange: time.Hour},
+	}
+	spans := &v1alpha1.AggregateRequest_SpanQuery{SpanQuery: &v1alpha1.SpanQueryParameters{}}
+	logs := &v1alpha1.AggregateRequest_LogQuery{LogQuery: &v1alpha1.LogQueryParameters{Logql: `{service_name="frontend"}`}}
+	for _, request := range []*v1alpha1.AggregateRequest{
+		{},
+		{Query: spans, GroupBy: []string{"body"}},
+		{Query: spans, GroupBy: []string{"attributes."}},
+		{Query: logs, GroupBy: []string{"kind"}},
+		{Query: spans, Aggregations: []*v1alpha1.Aggregation{{Function: "median", Field: "duration"}}},
+		{Query: spans, Aggregations: []*v1alpha1.Aggregation{{Function: "avg", Field: "name"}}},
+		{Query: spans, Aggregations: []*v1alpha1.Aggregation{{Function: "percentile", Field: "duration", Percentile: 101}}},
+		{Query: spans, Limit: aggregateMaxLimit + 1},
+		{Query: spans, Step: durationpb.New(time.Millisecond)},
+		{Query: spans, Step: durationpb.New(time.Second), StartTime: timestamppb.New(time.Now().Add(-23 * time.Hour))},
+		{Query: &v1alpha1.AggregateRequest_SpanQuery{SpanQuery: &v1alpha1.SpanQueryParameters{StartTime: timestamppb.Now()}}},
+		{Query: &v1alpha1.AggregateRequest_LogQuery{LogQuery: &v1alpha1.LogQueryParameters{Logql: `count_over_time({service_name="frontend"}[1m])`}}},
+	} {
+		_, err := h.Aggregate(context.Background(), request)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), "%v", request)
+	}
+}
@@ -0,0 +1,449 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"go.opentelemetry.io/collector/component"
+	v1 "go.opentelemetry.io/proto/otlp/trace/v1"
+	"go.uber.org/zap"
+)
+
+// The alert rule types.
+const (
+	// AlertRuleTraceErrorRate is the ratio of the matching spans with an error status.
+	AlertRuleTraceErrorRate = "trace_error_rate"
+	// AlertRuleTraceLatency is a quantile of the duration of the matching spans, in seconds.
+	AlertRuleTraceLatency = "trace_latency"
+	// AlertRuleLogCount is the number of log records matching a LogQL log query.
+	AlertRuleLogCount = "log_count"
+)
+
+const (
+	defaultAlertingInterval = time.Minute
+	defaultAlertWindow      = 5 * time.Minute
+	defaultAlertQuantile    = 0.99
+)
+
+// AlertingConfig configures the alert rules evaluated periodically over the query datasources.
+type AlertingConfig struct {
+	// Interval between two evaluations of the rules, one minute by default.
+	Interval time.Duration `mapstructure:"interval"`
+	// Rules are the alert rules, alerting is disabled without rules.
+	Rules []AlertRule `mapstructure:"rules"`
+	// Receivers are notified of the alerts firing and resolved.
+	Receivers []AlertReceiver `mapstructure:"receivers"`
+}
+
+// AlertRule compares a value computed over a recent window of the datasources to a threshold.
+type AlertRule struct {
+	// Name identifies the rule, it is the `alertname` label of its alert.
+	Name string `mapstructure:"name"`
+	// Type is trace_error_rate, trace_latency or log_count.
+	Type string `mapstructure:"type"`
+	// Service, Operation and Tags select the spans of the trace rules, all the spans of the
+	// window are counted.
+	Service   string            `mapstructure:"service"`
+	Operation string            `mapstructure:"operation"`
+	Tags      map[string]string `mapstructure:"tags"`
+	// Quantile of the trace_latency rules, 0.99 by default.
+	Quantile float64 `mapstructure:"quantile"`
+	// LogQL is the log query of the log_count rules, such as `{service_name="frontend"} |= "error"`.
+	LogQL string `mapstructure:"logql"`
+	// Window is the time range, ending at the evaluation, the value is computed over, five minutes
+	// by default.
+	Window time.Duration `mapstructure:"window"`
+	// Comparison of the value to the threshold: `>` (the default), `>=`, `<` or `<=`.
+	Comparison string `mapstructure:"comparison"`
+	// Threshold is a ratio for trace_error_rate, seconds for trace_latency and a number of log
+	// records for log_count.
+	Threshold float64 `mapstructure:"threshold"`
+	// For is how long the condition holds before the pending alert fires, it fires at once by default.
+	For time.Duration `mapstructure:"for"`
+	// Labels and Annotations are added to the alert.
+	Labels      map[string]string `mapstructure:"labels"`
+	Annotations map[string]string `mapstructure:"annotations"`
+	// Receivers are the names of the receivers notified, all of them by default.
+	Receivers []string `mapstructure:"receivers"`
+}
+
+// AlertState is the state of the alert of a rule.
+type AlertState string
+
+const (
+	// AlertInactive is the state of a rule whose condition never held.
+	AlertInactive AlertState = "inactive"
+	// AlertPending is the state of a rule whose condition holds for less than its For duration.
+	AlertPending AlertState = "pending"
+	// AlertFiring is the state of a rule whose condition holds for its For duration.
+	AlertFiring AlertState = "firing"
+	// AlertResolved is the state of a fired rule whose condition stopped holding.
+	AlertResolved AlertState = "resolved"
+)
+
+// Alert is the state of the alert of a rule.
+type Alert struct {
+	Rule  string
+	State AlertState
+	// Value is the value of the last successful evaluation.
+	Value       float64
+	Labels      map[string]string
+	Annotations map[string]string
+	// ActiveAt is when the condition started holding, FiredAt when the alert fired and ResolvedAt
+	// when it was resolved.
+	ActiveAt   time.Time
+	FiredAt    time.Time
+	ResolvedAt time.Time
+	// LastError is the error of the last evaluation, nil if it succeeded.
+	LastError error
+}
+
+// alertRule is a validated rule and the state of its alert.
+type alertRule struct {
+	AlertRule
+	logql     *logqlQuery
+	compare   func(value, threshold float64) bool
+	receivers []*alertReceiver
+	alert     Alert
+}
+
+// AlertEngine evaluates the alert rules periodically and notifies the receivers of the alerts
+// firing and resolved. The state of the alerts is kept in memory.
+type AlertEngine struct {
+	logger       *zap.Logger
+	queryService *QueryService
+	limits       Limits
+	interval     time.Duration
+	rules        []*alertRule
+	receivers    []*alertReceiver
+
+	mu     sync.RWMutex
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewAlertEngine validates the alert rules and creates the HTTP clients of the receivers.
+func NewAlertEngine(config *AlertingConfig, queryService *QueryService, limits Limits,
+	host component.Host, settings component.TelemetrySettings) (*AlertEngine, error) {
+	e := &AlertEngine{
+		logger:       settings.Logger,
+		queryService: queryService,
+		limits:       limits,
+		interval:     config.Interval,
+		stopCh:       make(chan struct{}),
+	}
+	if e.interval <= 0 {
+		e.interval = defaultAlertingInterval
+	}
+
+	receivers := map[string]*alertReceiver{}
+	for i := range config.Receivers {
+		receiver, err := newAlertReceiver(&config.Receivers[i], host, settings)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := receivers[receiver.name]; ok {
+			return nil, fmt.Errorf("duplicate alert receiver %q", receiver.name)
+		}
+		receivers[receiver.name] = receiver
+		e.receivers = append(e.receivers, receiver)
+	}
+
+	names := map[string]bool{}
+	for _, rule := range config.Rules {
+		r, err := newAlertRule(rule, receivers, e.receivers)
+		if err != nil {
+			return nil, fmt.Errorf("alert rule %q: %w", rule.Name, err)
+		}
+		if names[r.Name] {
+			return nil, fmt.Errorf("duplicate alert rule %q", r.Name)
+		}
+		names[r.Name] = true
+		e.rules = append(e.rules, r)
+	}
+	return e, nil
+}
+
+func newAlertRule(rule AlertRule, receivers map[string]*alertReceiver, all []*alertReceiver) (*alertRule, error) {
+	r := &alertRule{AlertRule: rule}
+	if r.Name == "" {
+		return nil, errors.New("name is required")
+	}
+	if r.Window == 0 {
+		r.Window = defaultAlertWindow
+	}
+	if r.Window < 0 || r.For < 0 {
+		return nil, errors.New("window and for must not be negative")
+	}
+
+	switch r.Type {
+	case AlertRuleTraceErrorRate, AlertRuleTraceLatency:
+		if r.LogQL != "" {
+			return nil, fmt.Errorf("logql is not supported by %s rules", r.Type)
+		}
+		if r.Type == AlertRuleTraceLatency && r.Quantile == 0 {
+			r.Quantile = defaultAlertQuantile
+		}
+		if r.Quantile < 0 || r.Quantile > 1 {
+			return nil, fmt.Errorf("quantile %v must be between 0 and 1", r.Quantile)
+		}
+	case AlertRuleLogCount:
+		query, err := parseLogQL(r.LogQL)
+		if err != nil {
+			return nil, err
+		}
+		if query.metric != nil {
+			return nil, errors.New("logql must be a log query")
+		}
+		r.logql = query
+	default:
+		return nil, fmt.Errorf("unknown type %q", r.Type)
+	}
+
+	switch r.Comparison {
+	case "", ">":
+		r.compare = func(value, threshold float64) bool { return value > threshold }
+	case ">=":
+		r.compare = func(value, threshold float64) bool { return value >= threshold }
+	case "<":
+		r.compare = func(value, threshold float64) bool { return value < threshold }
+	case "<=":
+		r.compare = func(value, threshold float64) bool { return value <= threshold }
+	default:
+		return nil, fmt.Errorf("unknown comparison %q", r.Comparison)
+	}
+
+	if len(r.AlertRule.Receivers) == 0 {
+		r.receivers = all
+	}
+	for _, name := range r.AlertRule.Receivers {
+		receiver, ok := receivers[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown receiver %q", name)
+		}
+		r.receivers = append(r.receivers, receiver)
+	}
+
+	r.alert = Alert{Rule: r.Name, State: AlertInactive, Labels: map[string]string{"alertname": r.Name}}
+	for k, v := range r.Labels {
+		r.alert.Labels[k] = v
+	}
+	return r, nil
+}
+
+// Start evaluates the rules every interval until Stop is called.
+func (e *AlertEngine) Start() {
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-e.stopCh:
+				return
+			case now := <-ticker.C:
+				e.evaluate(context.Background(), now)
+			}
+		}
+	}()
+}
+
+// Stop stops the evaluation of the rules and waits for the running one.
+func (e *AlertEngine) Stop() {
+	close(e.stopCh)
+	e.wg.Wait()
+}
+
+// Alerts returns the state of the alert of every rule.
+func (e *AlertEngine) Alerts() []Alert {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	alerts := make([]Alert, 0, len(e.rules))
+	for _, rule := range e.rules {
+		alerts = append(alerts, rule.alert)
+	}
+	return alerts
+}
+
+// evaluate evaluates every rule at now, then notifies the receivers of the alerts whose state changed.
+func (e *AlertEngine) evaluate(ctx context.Context, now time.Time) {
+	changed := map[*alertReceiver][]Alert{}
+	firing := map[*alertReceiver][]Alert{}
+	for _, rule := range e.rules {
+		value, err := e.evaluateRule(ctx, rule, now)
+		e.mu.Lock()
+		previous := rule.alert.State
+		rule.update(value, err, now)
+		alert := rule.alert
+		e.mu.Unlock()
+		if err != nil {
+			e.logger.Warn("Failed to evaluate alert rule", zap.String("rule", rule.Name), zap.Error(err))
+			continue
+		}
+
+		for _, receiver := range rule.receivers {
+			if alert.State != previous && (alert.State == AlertFiring || alert.State == AlertResolved) {
+				changed[receiver] = append(changed[receiver], alert)
+			} else if alert.State == AlertFiring {
+				firing[receiver] = append(firing[receiver], alert)
+			}
+		}
+	}
+
+	for _, receiver := range e.receivers {
+		if err := receiver.notify(ctx, changed[receiver], firing[receiver], now, e.interval); err != nil {
+			e.logger.Warn("Failed to notify alert receiver", zap.String("receiver", receiver.name), zap.Error(err))
+		}
+	}
+}
+
+// update moves the alert of the rule to its next state after an evaluation at now, an evaluation
+// error keeps the state.
+func (r *alertRule) update(value float64, err error, now time.Time) {
+	r.alert.LastError = err
+	if err != nil {
+		return
+	}
+	r.alert.Value = value
+	r.alert.Annotations = r.annotations(value)
+
+	if !math.IsNaN(value) && r.compare(value, r.Threshold) {
+		switch r.alert.State {
+		case AlertInactive, AlertResolved:
+			r.alert.State, r.alert.ActiveAt = AlertPending, now
+			r.alert.FiredAt, r.alert.ResolvedAt = time.Time{}, time.Time{}
+			if r.For == 0 {
+				r.alert.State, r.alert.FiredAt = AlertFiring, now
+			}
+		case AlertPending:
+			if now.Sub(r.alert.ActiveAt) >= r.For {
+				r.alert.State, r.alert.FiredAt = AlertFiring, now
+			}
+		}
+		return
+	}
+
+	switch r.alert.State {
+	case AlertPending:
+		r.alert.State = AlertInactive
+	case AlertFiring:
+		r.alert.State, r.alert.ResolvedAt = AlertResolved, now
+	}
+}
+
+// annotations returns the annotations of the rule with the value and the threshold.
+func (r *alertRule) annotations(value float64) map[string]string {
+	annotations := map[string]string{
+		"value":     strconv.FormatFloat(value, 'g', -1, 64),
+		"threshold": strconv.FormatFloat(r.Threshold, 'g', -1, 64),
+	}
+	for k, v := range r.Annotations {
+		annotations[k] = v
+	}
+	return annotations
+}
+
+// evaluateRule computes the value of a rule over its window ending at now, NaN if there is no data.
+func (e *AlertEngine) evaluateRule(ctx context.Context, rule *alertRule, now time.Time) (float64, error) {
+	if e.limits.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.limits.RequestTimeout)
+		defer cancel()
+	}
+	start := now.Add(-rule.Window)
+
+	if rule.Type == AlertRuleLogCount {
+		if e.queryService.Logging() == nil {
+			return 0, errors.New("the logging datasource is not available")
+		}
+		loki := &LokiHandler{QueryService: e.queryService}
+		matchers, err := loki.resolveMatchers(ctx, rule.logql.matchers, start, now)
+		if err != nil {
+			return 0, err
+		}
+		series, err := e.queryService.Logging().CountLogs(ctx, &datasource.LogQueryParameters{
+			Matchers:    matchers,
+			LineFilters: rule.logql.lineFilters,
+			StartTime:   start,
+			EndTime:     now,
+			Step:        rule.Window,
+		})
+		if err != nil {
+			return 0, err
+		}
+		var count int64
+		for _, s := range series {
+			for _, sample := range s.Samples {
+				count += sample.Count
+			}
+		}
+		return float64(count), nil
+	}
+
+	tracing := e.queryService.Tracing()
+	if tracing == nil {
+		return 0, errors.New("the tracing datasource is not available")
+	}
+	params := &datasource.SpanQueryParameters{
+		ServiceName:   rule.Service,
+		OperationName: rule.Operation,
+		Tags:          rule.Tags,
+		StartTime:     start,
+		EndTime:       now,
+	}
+	if rule.Type == AlertRuleTraceErrorRate {
+		return spanErrorRate(ctx, tracing, params)
+	}
+	return spanLatencyQuantile(ctx, tracing, params, rule.Quantile)
+}
+
+// spanErrorRate returns the ratio of the spans with an error status, 0 without spans.
+func spanErrorRate(ctx context.Context, tracing datasource.Query, params *datasource.SpanQueryParameters) (float64, error) {
+	total, err := aggregateSpans(ctx, tracing, params, datasource.Aggregation{Function: datasource.AggregateCount})
+	if err != nil || total[0] == 0 {
+		return 0, err
+	}
+	failed := *params
+	failed.StatusCode = v1.Status_STATUS_CODE_ERROR.String()
+	failures, err := aggregateSpans(ctx, tracing, &failed, datasource.Aggregation{Function: datasource.AggregateCount})
+	if err != nil {
+		return 0, err
+	}
+	return failures[0] / total[0], nil
+}
+
+// spanLatencyQuantile returns a quantile of the span durations in seconds, NaN without spans.
+func spanLatencyQuantile(ctx context.Context, tracing datasource.Query, params *datasource.SpanQueryParameters, quantile float64) (float64, error) {
+	values, err := aggregateSpans(ctx, tracing, params,
+		datasource.Aggregation{Function: datasource.AggregateCount},
+		datasource.Aggregation{Function: datasource.AggregatePercentile, Field: datasource.FieldDuration, Percentile: quantile * 100})
+	if err != nil {
+		return 0, err
+	}
+	if values[0] == 0 {
+		return math.NaN(), nil
+	}
+	// the durations are aggregated in milliseconds
+	return values[1] / 1000, nil
+}
+
+// aggregateSpans returns the values of the aggregations over all the matching spans, zeros
+// without spans.
+func aggregateSpans(ctx context.Context, tracing datasource.Query, params *datasource.SpanQueryParameters, aggregations ...datasource.Aggregation) ([]float64, error) {
+	groups, err := tracing.AggregateSpans(ctx, params, &datasource.AggregateParameters{Aggregations: aggregations, Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	values := make([]float64, len(aggregations))
+	if len(groups) > 0 {
+		copy(values, groups[0].Values)
+	}
+	return values, nil
+}
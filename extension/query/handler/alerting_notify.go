@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/confighttp"
+)
+
+// The alert receiver types.
+const (
+	// AlertReceiverWebhook posts the alerts whose state changed in the Alertmanager webhook format.
+	AlertReceiverWebhook = "webhook"
+	// AlertReceiverAlertmanager posts the alerts to the Alertmanager v2 API, firing alerts are sent
+	// at every evaluation so Alertmanager doesn't resolve them.
+	AlertReceiverAlertmanager = "alertmanager"
+)
+
+const (
+	alertmanagerAlertsPath = "/api/v2/alerts"
+	// alertWebhookVersion is the version of the Alertmanager webhook payload.
+	alertWebhookVersion = "4"
+	// alertResendFactor times the evaluation interval is how long Alertmanager keeps a firing
+	// alert which isn't sent again, as Prometheus does.
+	alertResendFactor = 4
+)
+
+// AlertReceiver is an HTTP endpoint notified of the alerts.
+type AlertReceiver struct {
+	// Name is referenced by the rules notifying the receiver.
+	Name string `mapstructure:"name"`
+	// Type is webhook or alertmanager.
+	Type string `mapstructure:"type"`
+	// HTTPClientSettings is the webhook URL or the base URL of the Alertmanager.
+	confighttp.HTTPClientSettings `mapstructure:",squash"`
+}
+
+// alertReceiver sends the notifications of a receiver.
+type alertReceiver struct {
+	name     string
+	typ      string
+	endpoint string
+	client   *http.Client
+}
+
+func newAlertReceiver(config *AlertReceiver, host component.Host, settings component.TelemetrySettings) (*alertReceiver, error) {
+	if config.Name == "" {
+		return nil, errors.New("alert receiver name is required")
+	}
+	if config.Endpoint == "" {
+		return nil, fmt.Errorf("alert receiver %q: endpoint is required", config.Name)
+	}
+	r := &alertReceiver{name: config.Name, typ: config.Type, endpoint: config.Endpoint}
+	switch config.Type {
+	case AlertReceiverWebhook:
+	case AlertReceiverAlertmanager:
+		r.endpoint = strings.TrimSuffix(config.Endpoint, "/") + alertmanagerAlertsPath
+	default:
+		return nil, fmt.Errorf("alert receiver %q: unknown type %q", config.Name, config.Type)
+	}
+	client, err := config.ToClient(host, settings)
+	if err != nil {
+		return nil, fmt.Errorf("alert receiver %q: %w", config.Name, err)
+	}
+	r.client = client
+	return r, nil
+}
+
+// alertmanagerAlert is an alert of the Alertmanager v2 API and of its webhook payload.
+type alertmanagerAlert struct {
+	Status      string            `json:"status,omitempty"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	StartsAt    time.Time         `json:"startsAt"`
+	EndsAt      time.Time         `json:"endsAt"`
+}
+
+// alertWebhookMessage is the Alertmanager webhook payload.
+type alertWebhookMessage struct {
+	Version  string               `json:"version"`
+	Status   string               `json:"status"`
+	Receiver string               `json:"receiver"`
+	Alerts   []*alertmanagerAlert `json:"alerts"`
+}
+
+// notify sends the alerts which fired or were resolved at now and, to an Alertmanager, the alerts
+// still firing.
+func (r *alertReceiver) notify(ctx context.Context, changed, firing []Alert, now time.Time, interval time.Duration) error {
+	if r.typ == AlertReceiverWebhook {
+		if len(changed) == 0 {
+			return nil
+		}
+		message := &alertWebhookMessage{Version: alertWebhookVersion, Status: string(AlertResolved), Receiver: r.name}
+		for _, alert := range changed {
+			a := toAlertmanagerAlert(alert, now, interval)
+			a.Status = string(alert.State)
+			if alert.State == AlertFiring {
+				message.Status = string(AlertFiring)
+			}
+			message.Alerts = append(message.Alerts, a)
+		}
+		return r.post(ctx, message)
+	}
+
+	alerts := make([]*alertmanagerAlert, 0, len(changed)+len(firing))
+	for _, alert := range changed {
+		alerts = append(alerts, toAlertmanagerAlert(alert, now, interval))
+	}
+	for _, alert := range firing {
+		alerts = append(alerts, toAlertmanagerAlert(alert, now, interval))
+	}
+	if len(alerts) == 0 {
+		return nil
+	}
+	return r.post(ctx, alerts)
+}
+
+// toAlertmanagerAlert converts an alert, a firing alert ends if it isn't sent again for a few
+// evaluation intervals.
+func toAlertmanagerAlert(alert Alert, now time.Time, interval time.Duration) *alertmanagerAlert {
+	a := &alertmanagerAlert{
+		Labels:      alert.Labels,
+		Annotations: alert.Annotations,
+		StartsAt:    alert.FiredAt,
+		EndsAt:      alert.ResolvedAt,
+	}
+	if alert.State == AlertFiring {
+		a.EndsAt = now.Add(alertResendFactor * interval)
+	}
+	return a
+}
+
+func (r *alertReceiver) post(ctx context.Context, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s responded with status %d", r.endpoint, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/confighttp"
+	v1 "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// alertReceiverServer records the bodies posted to it.
+type alertReceiverServer struct {
+	*httptest.Server
+	mu     sync.Mutex
+	paths  []string
+	bodies [][]byte
+}
+
+func newAlertReceiverServer(t *testing.T) *alertReceiverServer {
+	s := &alertReceiverServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.paths = append(s.paths, r.URL.Path)
+		s.bodies = append(s.bodies, body)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *alertReceiverServer) received() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bodies
+}
+
+// alertSpans aggregates frontend spans lasting 100ms, 200ms and so on, the first failed ones
+// with an error status, as a datasource does.
+func alertSpans(count, failed int) func(query *datasource.SpanQueryParameters, agg *datasource.AggregateParameters) []*datasource.AggregateGroup {
+	return func(query *datasource.SpanQueryParameters, agg *datasource.AggregateParameters) []*datasource.AggregateGroup {
+		var durations []float64
+		for i := 0; i < count; i++ {
+			if query.StatusCode == v1.Status_STATUS_CODE_ERROR.String() && i >= failed {
+				continue
+			}
+			durations = append(durations, float64((i+1)*100))
+		}
+		group := &datasource.AggregateGroup{}
+		for _, a := range agg.Aggregations {
+			switch a.Function {
+			case datasource.AggregateCount:
+				group.Values = append(group.Values, float64(len(durations)))
+			case datasource.AggregatePercentile:
+				group.Values = append(group.Values, durations[int(math.Ceil(a.Percentile/100*float64(len(durations))))-1])
+			}
+		}
+		return []*datasource.AggregateGroup{group}
+	}
+}
+
+func newTestAlertEngine(t *testing.T, config *AlertingConfig, queryService *QueryService) *AlertEngine {
+	engine, err := NewAlertEngine(config, queryService, Limits{}, componenttest.NewNopHost(), componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	return engine
+}
+
+func TestAlertTraceErrorRate(t *testing.T) {
+	webhook := newAlertReceiverServer(t)
+	query := &mockQuery{aggregate: alertSpans(10, 2)}
+	engine := newTestAlertEngine(t, &AlertingConfig{
+		Rules: []AlertRule{{
+			Name:        "frontend-errors",
+			Type:        AlertRuleTraceErrorRate,
+			Service:     "frontend",
+			Threshold:   0.1,
+			For:         time.Minute,
+			Labels:      map[string]string{"severity": "page"},
+			Annotations: map[string]string{"summary": "frontend is failing"},
+		}},
+		Receivers: []AlertReceiver{{Name: "webhook", Type: AlertReceiverWebhook, HTTPClientSettings: confighttp.HTTPClientSettings{Endpoint: webhook.URL}}},
+	}, &QueryService{TracingQuerySvc: query})
+
+	now := time.Unix(1681873445, 0)
+	engine.evaluate(context.Background(), now)
+	// the error spans are counted over all the spans of the window
+	assert.Equal(t, "frontend", query.lastSpanSearch.ServiceName)
+	assert.Equal(t, v1.Status_STATUS_CODE_ERROR.String(), query.lastSpanSearch.StatusCode)
+	assert.Equal(t, now.Add(-defaultAlertWindow), query.lastSpanSearch.StartTime)
+	assert.Equal(t, []datasource.Aggregation{{Function: datasource.AggregateCount}}, query.lastAggregate.Aggregations)
+	alert := engine.Alerts()[0]
+	assert.Equal(t, AlertPending, alert.State)
+	assert.InDelta(t, 0.2, alert.Value, 1e-9)
+	assert.Empty(t, webhook.received())
+
+	// the condition holds for the For duration
+	engine.evaluate(context.Background(), now.Add(time.Minute))
+	assert.Equal(t, AlertFiring, engine.Alerts()[0].State)
+	require.Len(t, webhook.received(), 1)
+	message := &alertWebhookMessage{}
+	require.NoError(t, json.Unmarshal(webhook.received()[0], message))
+	assert.Equal(t, "firing", message.Status)
+	require.Len(t, message.Alerts, 1)
+	assert.Equal(t, map[string]string{"alertname": "frontend-errors", "severity": "page"}, message.Alerts[0].Labels)
+	assert.Equal(t, "0.2", message.Alerts[0].Annotations["value"])
+	assert.Equal(t, "frontend is failing", message.Alerts[0].Annotations["summary"])
+	assert.True(t, now.Add(time.Minute).Equal(message.Alerts[0].StartsAt))
+
+	// a firing alert is notified once
+	engine.evaluate(context.Background(), now.Add(2*time.Minute))
+	assert.Len(t, webhook.received(), 1)
+
+	query.aggregate = alertSpans(10, 0)
+	engine.evaluate(context.Background(), now.Add(3*time.Minute))
+	alert = engine.Alerts()[0]
+	assert.Equal(t, AlertResolved, alert.State)
+	assert.Equal(t, now.Add(3*time.Minute), alert.ResolvedAt)
+	require.Len(t, webhook.received(), 2)
+	require.NoError(t, json.Unmarshal(webhook.received()[1], message))
+	assert.Equal(t, "resolved", message.Status)
+	assert.True(t, now.Add(3*time.Minute).Equal(message.Alerts[0].EndsAt))
+}
+
+func TestAlertTraceLatencyToAlertmanager(t *testing.T) {
+	alertmanager := newAlertReceiverServer(t)
+	query := &mockQuery{aggregate: alertSpans(10, 0)}
+	engine := newTestAlertEngine(t, &AlertingConfig{
+		Interval: 30 * time.Second,
+		Rules: []AlertRule{{
+			Name:      "frontend-latency",
+			Type:      AlertRuleTraceLatency,
+			Service:   "frontend",
+			Operation: "GET /hello",
+			Quantile:  0.9,
+			Threshold: 0.85,
+		}},
+		Receivers: []AlertReceiver{{Name: "alertmanager", Type: AlertReceiverAlertmanager, HTTPClientSettings: confighttp.HTTPClientSettings{Endpoint: alertmanager.URL + "/"}}},
+	}, &QueryService{TracingQuerySvc: query})
+
+	now := time.Unix(1681873445, 0)
+	engine.evaluate(context.Background(), now)
+	alert := engine.Alerts()[0]
+	assert.Equal(t, AlertFiring, alert.State)
+	assert.InDelta(t, 0.9, alert.Value, 1e-9)
+	assert.Equal(t, "GET /hello", query.lastSpanSearch.OperationName)
+	assert.Equal(t, datasource.Aggregation{Function: datasource.AggregatePercentile, Field: datasource.FieldDuration, Percentile: 90}, query.lastAggregate.Aggregations[1])
+
+	// firing alerts are sent again at every evaluation
+	engine.evaluate(context.Background(), now.Add(30*time.Second))
+	require.Len(t, alertmanager.received(), 2)
+	assert.Equal(t, []string{"/api/v2/alerts", "/api/v2/alerts"}, alertmanager.paths)
+	var alerts []*alertmanagerAlert
+	require.NoError(t, json.Unmarshal(alertmanager.received()[1], &alerts))
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "frontend-latency", alerts[0].Labels["alertname"])
+	assert.True(t, now.Equal(alerts[0].StartsAt))
+	assert.True(t, now.Add(30*time.Second+4*30*time.Second).Equal(alerts[0].EndsAt))
+}
+
+func TestAlertLogCount(t *testing.T) {
+	logQuery := &mockLogQuery{series: []*datasource.LogSeries{
+		{Labels: map[string]string{"service.name": "frontend"}, Samples: []datasource.LogSample{{Count: 3}, {Count: 4}}},
+		{Labels: map[string]string{"service.name": "frontend", "host.name": "b"}, Samples: []datasource.LogSample{{Count: 2}}},
+	}}
+	engine := newTestAlertEngine(t, &AlertingConfig{Rules: []AlertRule{{
+		Name:       "frontend-timeouts",
+		Type:       AlertRuleLogCount,
+		LogQL:      `{service_name="frontend"} |= "timeout"`,
+		Window:     10 * time.Minute,
+		Comparison: ">=",
+		Threshold:  9,
+	}}}, &QueryService{LoggingQuerySvc: logQuery})
+
+	now := time.Unix(1681873445, 0)
+	engine.evaluate(context.Background(), now)
+	alert := engine.Alerts()[0]
+	assert.Equal(t, AlertFiring, alert.State)
+	assert.Equal(t, float64(9), alert.Value)
+	assert.Equal(t, []datasource.LabelMatcher{{Name: "service.name", Value: "frontend"}}, logQuery.lastQuery.Matchers)
+	assert.Equal(t, []datasource.LineFilter{{Value: "timeout"}}, logQuery.lastQuery.LineFilters)
+	assert.Equal(t, 10*time.Minute, logQuery.lastQuery.Step)
+
+	// an unavailable datasource keeps the state
+	engine.queryService.Update(func(s *QueryService) { s.LoggingQuerySvc = nil })
+	engine.evaluate(context.Background(), now.Add(time.Minute))
+	alert = engine.Alerts()[0]
+	assert.Equal(t, AlertFiring, alert.State)
+	assert.Error(t, alert.LastError)
+}
+
+func TestAlertRuleValidation(t *testing.T) {
+	receivers := []AlertReceiver{{Name: "webhook", Type: AlertReceiverWebhook, HTTPClientSettings: confighttp.HTTPClientSettings{Endpoint: "http://localhost"}}}
+	for _, rule := range []AlertRule{
+		{Type: AlertRuleTraceErrorRate},
+		{Name: "type", Type: "span_count"},
+		{Name: "quantile", Type: AlertRuleTraceLatency, Quantile: 2},
+		{Name: "metric", Type: AlertRuleLogCount, LogQL: `count_over_time({job="x"}[1m])`},
+		{Name: "logql", Type: AlertRuleTraceErrorRate, LogQL: `{job="x"}`},
+		{Name: "comparison", Type: AlertRuleTraceErrorRate, Comparison: "=="},
+		{Name: "receiver", Type: AlertRuleTraceErrorRate, Receivers: []string{"pager"}},
+		{Name: "window", Type: AlertRuleTraceErrorRate, Window: -time.Minute},
+	} {
+		_, err := NewAlertEngine(&AlertingConfig{Rules: []AlertRule{rule}, Receivers: receivers}, &QueryService{}, Limits{},
+			componenttest.NewNopHost(), componenttest.NewNopTelemetrySettings())
+		assert.Error(t, err, rule.Name)
+	}
+
+	_, err := NewAlertEngine(&AlertingConfig{Receivers: []AlertReceiver{{Name: "pager", Type: "email", HTTPClientSettings: confighttp.HTTPClientSettings{Endpoint: "http://localhost"}}}},
+		&QueryService{}, Limits{}, componenttest.NewNopHost(), componenttest.NewNopTelemetrySettings())
+	assert.Error(t, err)
+}
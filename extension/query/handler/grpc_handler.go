@@ -2,16 +2,21 @@ package handler
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/api/v1alpha1"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
 	v1_logs "go.opentelemetry.io/proto/otlp/logs/v1"
 	v1 "go.opentelemetry.io/proto/otlp/trace/v1"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type Handler struct {
 	QueryService *QueryService
+	Limits       Limits
 }
 
 func (t *Handler) GetOperations(ctx context.Context, req *v1alpha1.GetOperationsRequest) (*v1alpha1.GetOperationsResponse, error) {
@@ -24,7 +29,7 @@ func (t *Handler) GetOperations(ctx context.Context, req *v1alpha1.GetOperations
 		queryParams.SpanKind = req.SpanKind
 	}
 
-	operations, err := t.QueryService.TracingQuerySvc.GetOperations(ctx, queryParams)
+	operations, err := t.QueryService.Tracing().GetOperations(ctx, queryParams)
 	if err != nil {
 		zap.S().Errorf("query operations failed: %s", zap.Error(err).String)
 		return nil, err
@@ -41,10 +46,17 @@ func (t *Handler) SearchTraces(ctx context.Context, request *v1alpha1.FindTraces
 	if err != nil {
 		return nil, err
 	}
+	if err = t.Limits.applyTraceQuery(queryParams, time.Now()); err != nil {
+		return nil, err
+	}
 
-	traces, err := t.QueryService.TracingQuerySvc.SearchTraces(ctx, queryParams)
+	ctx = datasource.ContextWithSpanLimit(ctx, t.Limits.MaxSpansPerTrace)
+	traces, err := t.QueryService.Tracing().SearchTraces(ctx, queryParams)
 	if err != nil {
-		zap.S().Errorf("query tracing failed: %s", zap.Error(err).String)
+		zap.S().Errorf("query tracing failed: %s", err)
+		return nil, spanLimitError(err)
+	}
+	if err = t.Limits.checkTracesSpans(traces); err != nil {
 		return nil, err
 	}
 
@@ -57,18 +69,43 @@ func (t *Handler) SearchLogs(ctx context.Context, request *v1alpha1.GetLogsReque
 }
 
 func (t *Handler) GetTrace(ctx context.Context, request *v1alpha1.GetTraceRequest) (*v1.TracesData, error) {
-	trace, err := t.QueryService.TracingQuerySvc.GetTrace(ctx, request.TraceId)
+	ctx = datasource.ContextWithSpanLimit(ctx, t.Limits.MaxSpansPerTrace)
+	trace, err := t.QueryService.GetTrace(ctx, request.TraceId)
 	if err != nil {
 		return nil, err
 	}
+	if err = t.Limits.checkTraceSpans(trace); err != nil {
+		return nil, err
+	}
 
 	return &v1.TracesData{
 		ResourceSpans: trace.ResourceSpans,
 	}, nil
 }
 
+// ArchiveTrace copies a trace into the archive of the tracing datasource.
+func (t *Handler) ArchiveTrace(ctx context.Context, request *v1alpha1.ArchiveTraceRequest) (*v1alpha1.ArchiveTraceResponse, error) {
+	archive := t.QueryService.TraceArchive()
+	if archive == nil {
+		return nil, status.Error(codes.FailedPrecondition, "the tracing datasource has no trace archive configured")
+	}
+	traceID := strings.ToLower(request.TraceId)
+	if traceID == "" {
+		return nil, status.Error(codes.InvalidArgument, "trace_id is required")
+	}
+	copied, err := archive.ArchiveTrace(ctx, traceID)
+	if err != nil {
+		zap.S().Errorf("archive trace failed: %s", err)
+		return nil, err
+	}
+	if copied == 0 {
+		return nil, status.Errorf(codes.NotFound, "trace %s not found", traceID)
+	}
+	return &v1alpha1.ArchiveTraceResponse{TraceId: traceID, SpanCount: uint32(copied)}, nil
+}
+
 func (t *Handler) GetServices(ctx context.Context, _ *v1alpha1.GetServicesRequest) (*v1alpha1.ResourcesData, error) {
-	kvs, err := t.QueryService.TracingQuerySvc.GetService(ctx)
+	kvs, err := t.QueryService.Tracing().GetService(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestArchiveTrace(t *testing.T) {
+	query := &mockQuery{traces: map[string]*v1.TracesData{mockTraceID: mockTrace()}}
+	archive := &mockTraceArchive{query: query}
+	h := &Handler{
+		QueryService: &QueryService{TracingQuerySvc: query, TraceArchiveSvc: archive},
+		Limits:       Limits{MaxSpansPerTrace: 100},
+	}
+
+	res, err := h.ArchiveTrace(context.Background(), &v1alpha1.ArchiveTraceRequest{TraceId: mockTraceID})
+	require.NoError(t, err)
+	assert.Equal(t, &v1alpha1.ArchiveTraceResponse{TraceId: mockTraceID, SpanCount: 2}, res)
+
+	_, err = h.ArchiveTrace(context.Background(), &v1alpha1.ArchiveTraceRequest{TraceId: "ffff"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = h.ArchiveTrace(context.Background(), &v1alpha1.ArchiveTraceRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// the trace expired from the primary storage is read from the archive
+	delete(query.traces, mockTraceID)
+	trace, err := h.GetTrace(context.Background(), &v1alpha1.GetTraceRequest{TraceId: mockTraceID})
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(mockTrace(), trace))
+
+	trace, err = h.GetTrace(context.Background(), &v1alpha1.GetTraceRequest{TraceId: "ffff"})
+	require.NoError(t, err)
+	assert.Empty(t, trace.ResourceSpans)
+
+	h.QueryService.TraceArchiveSvc = nil
+	_, err = h.ArchiveTrace(context.Background(), &v1alpha1.ArchiveTraceRequest{TraceId: mockTraceID})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
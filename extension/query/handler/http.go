@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// traceIDHexLength is the length of the hex encoded 16 bytes trace ids.
+const traceIDHexLength = 32
+
+// normalizeTraceID lower cases a hex encoded trace id of the compatibility HTTP APIs and pads it
+// to 32 characters, as Tempo and Zipkin do with 64 bits ids.
+func normalizeTraceID(traceID string) (string, error) {
+	traceID = strings.ToLower(traceID)
+	if len(traceID) > traceIDHexLength {
+		return "", status.Errorf(codes.InvalidArgument, "trace id %q is longer than %d characters", traceID, traceIDHexLength)
+	}
+	traceID = strings.Repeat("0", traceIDHexLength-len(traceID)) + traceID
+	if _, err := hex.DecodeString(traceID); err != nil {
+		return "", status.Errorf(codes.InvalidArgument, "trace id %q is not hex encoded", traceID)
+	}
+	return traceID, nil
+}
+
+// beginRequest checks the datasource is available and applies the request timeout and span limit
+// to a request of the compatibility HTTP APIs.
+func beginRequest(r *http.Request, limits Limits, available func() error) (context.Context, context.CancelFunc, error) {
+	if available != nil {
+		if err := available(); err != nil {
+			return nil, nil, err
+		}
+	}
+	ctx := datasource.ContextWithSpanLimit(r.Context(), limits.MaxSpansPerTrace)
+	if limits.RequestTimeout > 0 {
+		ctx, cancel := context.WithTimeout(ctx, limits.RequestTimeout)
+		return ctx, cancel, nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	return ctx, cancel, nil
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		zap.S().Errorf("write response failed: %v", err)
+	}
+}
+
+// writeError maps the gRPC status of err to the HTTP status, as the gateway does.
+func writeError(w http.ResponseWriter, err error) {
+	http.Error(w, status.Convert(err).Message(), runtime.HTTPStatusFromCode(status.Code(err)))
+}
+
+// labelsKey identifies a label set.
+func labelsKey(labels map[string]string) string {
+	set := make(map[string]struct{}, len(labels))
+	for k := range labels {
+		set[k] = struct{}{}
+	}
+	key := make([]byte, 0, 64)
+	for _, k := range sortedKeys(set) {
+		key = strconv.AppendQuote(key, k)
+		key = append(key, '=')
+		key = strconv.AppendQuote(key, labels[k])
+		key = append(key, ',')
+	}
+	return string(key)
+}
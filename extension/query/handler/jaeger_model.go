@@ -0,0 +1,428 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	semconv "go.opentelemetry.io/collector/semconv/v1.6.1"
+	v1_common "go.opentelemetry.io/proto/otlp/common/v1"
+	v1_resource "go.opentelemetry.io/proto/otlp/resource/v1"
+	v1 "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// jaegerTracesFile is the Jaeger JSON of traces, as the Jaeger UI downloads and uploads them.
+// refs: https://github.com/jaegertracing/jaeger/blob/main/model/json/model.go
+type jaegerTracesFile struct {
+	Data []*jaegerTrace `json:"data"`
+}
+
+type jaegerTrace struct {
+	TraceID   string                    `json:"traceID"`
+	Spans     []*jaegerSpan             `json:"spans"`
+	Processes map[string]*jaegerProcess `json:"processes"`
+	Warnings  []string                  `json:"warnings"`
+}
+
+type jaegerSpan struct {
+	TraceID       string            `json:"traceID"`
+	SpanID        string            `json:"spanID"`
+	OperationName string            `json:"operationName"`
+	References    []jaegerReference `json:"references"`
+	StartTime     uint64            `json:"startTime"`
+	Duration      uint64            `json:"duration"`
+	Tags          []jaegerKeyValue  `json:"tags"`
+	Logs          []jaegerLog       `json:"logs"`
+	ProcessID     string            `json:"processID"`
+	Warnings      []string          `json:"warnings"`
+}
+
+type jaegerReference struct {
+	RefType string `json:"refType"`
+	TraceID string `json:"traceID"`
+	SpanID  string `json:"spanID"`
+}
+
+type jaegerProcess struct {
+	ServiceName string           `json:"serviceName"`
+	Tags        []jaegerKeyValue `json:"tags"`
+}
+
+type jaegerLog struct {
+	Timestamp uint64           `json:"timestamp"`
+	Fields    []jaegerKeyValue `json:"fields"`
+}
+
+type jaegerKeyValue struct {
+	Key   string      `json:"key"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+const (
+	jaegerChildOf         = "CHILD_OF"
+	jaegerFollowsFrom     = "FOLLOWS_FROM"
+	jaegerTypeString      = "string"
+	jaegerTypeBool        = "bool"
+	jaegerTypeInt64       = "int64"
+	jaegerTypeFloat64     = "float64"
+	jaegerTypeBinary      = "binary"
+	jaegerTagSpanKind     = "span.kind"
+	jaegerTagStatusDesc   = "otel.status_description"
+	jaegerLogEventField   = "event"
+	jaegerDefaultService  = "unknown_service"
+	jaegerProcessIDPrefix = "p"
+)
+
+// toJaegerTraces renders the OTLP spans as Jaeger traces, in the order the traces first appear.
+func toJaegerTraces(td *v1.TracesData) []*jaegerTrace {
+	traces := []*jaegerTrace{}
+	for _, trace := range splitTraces(td) {
+		jt := &jaegerTrace{Spans: []*jaegerSpan{}, Processes: map[string]*jaegerProcess{}, Warnings: []string{}}
+		for i, rs := range trace.ResourceSpans {
+			processID := jaegerProcessIDPrefix + strconv.Itoa(i+1)
+			jt.Processes[processID] = toJaegerProcess(rs.GetResource())
+			for _, ss := range rs.GetScopeSpans() {
+				for _, span := range ss.GetSpans() {
+					js := toJaegerSpan(span, ss.GetScope())
+					js.ProcessID = processID
+					jt.Spans = append(jt.Spans, js)
+				}
+			}
+		}
+		if len(jt.Spans) > 0 {
+			jt.TraceID = jt.Spans[0].TraceID
+		}
+		traces = append(traces, jt)
+	}
+	return traces
+}
+
+func toJaegerProcess(resource *v1_resource.Resource) *jaegerProcess {
+	process := &jaegerProcess{ServiceName: jaegerDefaultService, Tags: []jaegerKeyValue{}}
+	for _, kv := range resource.GetAttributes() {
+		if kv.Key == semconv.AttributeServiceName {
+			process.ServiceName = datasource.AnyValueString(kv.Value)
+			continue
+		}
+		process.Tags = append(process.Tags, toJaegerKeyValue(kv))
+	}
+	return process
+}
+
+func toJaegerSpan(span *v1.Span, scope *v1_common.InstrumentationScope) *jaegerSpan {
+	js := &jaegerSpan{
+		TraceID:       string(span.TraceId),
+		SpanID:        string(span.SpanId),
+		OperationName: span.Name,
+		References:    []jaegerReference{},
+		StartTime:     span.StartTimeUnixNano / 1e3,
+		Tags:          []jaegerKeyValue{},
+		Logs:          []jaegerLog{},
+		Warnings:      []string{},
+	}
+	if span.EndTimeUnixNano > span.StartTimeUnixNano {
+		js.Duration = (span.EndTimeUnixNano - span.StartTimeUnixNano) / 1e3
+	}
+	if len(span.ParentSpanId) > 0 {
+		js.References = append(js.References, jaegerReference{RefType: jaegerChildOf, TraceID: js.TraceID, SpanID: string(span.ParentSpanId)})
+	}
+	for _, link := range span.Links {
+		js.References = append(js.References, jaegerReference{RefType: jaegerFollowsFrom, TraceID: string(link.TraceId), SpanID: string(link.SpanId)})
+	}
+
+	for _, kv := range span.Attributes {
+		js.Tags = append(js.Tags, toJaegerKeyValue(kv))
+	}
+	stringTag := func(key, value string) {
+		js.Tags = append(js.Tags, jaegerKeyValue{Key: key, Type: jaegerTypeString, Value: value})
+	}
+	if kind := jaegerSpanKind(span.Kind); kind != "" {
+		stringTag(jaegerTagSpanKind, kind)
+	}
+	switch span.GetStatus().GetCode() {
+	case v1.Status_STATUS_CODE_ERROR:
+		stringTag(zipkinTagStatusCode, "ERROR")
+		js.Tags = append(js.Tags, jaegerKeyValue{Key: zipkinTagError, Type: jaegerTypeBool, Value: true})
+		if message := span.GetStatus().GetMessage(); message != "" {
+			stringTag(jaegerTagStatusDesc, message)
+		}
+	case v1.Status_STATUS_CODE_OK:
+		stringTag(zipkinTagStatusCode, "OK")
+	}
+	if span.TraceState != "" {
+		stringTag(zipkinTagTraceState, span.TraceState)
+	}
+	if scope.GetName() != "" {
+		stringTag(zipkinTagLibraryName, scope.GetName())
+	}
+	if scope.GetVersion() != "" {
+		stringTag(zipkinTagLibraryVer, scope.GetVersion())
+	}
+
+	for _, event := range span.Events {
+		log := jaegerLog{
+			Timestamp: event.TimeUnixNano / 1e3,
+			Fields:    []jaegerKeyValue{{Key: jaegerLogEventField, Type: jaegerTypeString, Value: event.Name}},
+		}
+		for _, kv := range event.Attributes {
+			log.Fields = append(log.Fields, toJaegerKeyValue(kv))
+		}
+		js.Logs = append(js.Logs, log)
+	}
+	return js
+}
+
+// toJaegerKeyValue keeps the scalar types of an attribute, the other values are rendered as strings.
+func toJaegerKeyValue(kv *v1_common.KeyValue) jaegerKeyValue {
+	switch v := kv.Value.GetValue().(type) {
+	case *v1_common.AnyValue_BoolValue:
+		return jaegerKeyValue{Key: kv.Key, Type: jaegerTypeBool, Value: v.BoolValue}
+	case *v1_common.AnyValue_IntValue:
+		return jaegerKeyValue{Key: kv.Key, Type: jaegerTypeInt64, Value: v.IntValue}
+	case *v1_common.AnyValue_DoubleValue:
+		return jaegerKeyValue{Key: kv.Key, Type: jaegerTypeFloat64, Value: v.DoubleValue}
+	case *v1_common.AnyValue_BytesValue:
+		return jaegerKeyValue{Key: kv.Key, Type: jaegerTypeBinary, Value: base64.StdEncoding.EncodeToString(v.BytesValue)}
+	}
+	return jaegerKeyValue{Key: kv.Key, Type: jaegerTypeString, Value: datasource.AnyValueString(kv.Value)}
+}
+
+func jaegerSpanKind(kind v1.Span_SpanKind) string {
+	switch kind {
+	case v1.Span_SPAN_KIND_INTERNAL:
+		return "internal"
+	case v1.Span_SPAN_KIND_SERVER:
+		return "server"
+	case v1.Span_SPAN_KIND_CLIENT:
+		return "client"
+	case v1.Span_SPAN_KIND_PRODUCER:
+		return "producer"
+	case v1.Span_SPAN_KIND_CONSUMER:
+		return "consumer"
+	default:
+		return ""
+	}
+}
+
+// decodeJaegerTraces reads a Jaeger JSON file, either the traces under `data` or a single trace.
+func decodeJaegerTraces(content []byte) (*v1.TracesData, error) {
+	var file struct {
+		jaegerTracesFile
+		jaegerTrace
+	}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	if err := decoder.Decode(&file); err != nil {
+		return nil, err
+	}
+	traces := file.Data
+	if len(file.Spans) > 0 {
+		traces = append(traces, &file.jaegerTrace)
+	}
+	td := &v1.TracesData{}
+	for _, trace := range traces {
+		rs, err := fromJaegerTrace(trace)
+		if err != nil {
+			return nil, err
+		}
+		td.ResourceSpans = append(td.ResourceSpans, rs...)
+	}
+	return td, nil
+}
+
+// fromJaegerTrace converts the spans of a trace grouped by process and instrumentation library.
+func fromJaegerTrace(trace *jaegerTrace) ([]*v1.ResourceSpans, error) {
+	var result []*v1.ResourceSpans
+	byProcess := map[string]*v1.ResourceSpans{}
+	scopes := map[string]*v1.ScopeSpans{}
+	for _, js := range trace.Spans {
+		rs, ok := byProcess[js.ProcessID]
+		if !ok {
+			process, ok := trace.Processes[js.ProcessID]
+			if !ok {
+				return nil, fmt.Errorf("span %s refers to the unknown process %q", js.SpanID, js.ProcessID)
+			}
+			resource, err := fromJaegerProcess(process)
+			if err != nil {
+				return nil, err
+			}
+			rs = &v1.ResourceSpans{Resource: resource}
+			byProcess[js.ProcessID] = rs
+			result = append(result, rs)
+		}
+		span, scope, err := fromJaegerSpan(js)
+		if err != nil {
+			return nil, err
+		}
+		scopeKey := js.ProcessID + "\x00" + scope.Name + "\x00" + scope.Version
+		ss, ok := scopes[scopeKey]
+		if !ok {
+			ss = &v1.ScopeSpans{Scope: scope}
+			scopes[scopeKey] = ss
+			rs.ScopeSpans = append(rs.ScopeSpans, ss)
+		}
+		ss.Spans = append(ss.Spans, span)
+	}
+	return result, nil
+}
+
+func fromJaegerProcess(process *jaegerProcess) (*v1_resource.Resource, error) {
+	resource := &v1_resource.Resource{Attributes: []*v1_common.KeyValue{
+		{Key: semconv.AttributeServiceName, Value: &v1_common.AnyValue{Value: &v1_common.AnyValue_StringValue{StringValue: process.ServiceName}}},
+	}}
+	for _, tag := range process.Tags {
+		kv, err := fromJaegerKeyValue(tag)
+		if err != nil {
+			return nil, err
+		}
+		resource.Attributes = append(resource.Attributes, kv)
+	}
+	return resource, nil
+}
+
+// fromJaegerSpan converts a span, the tags set by toJaegerSpan are turned back into the OTLP
+// fields, as are the `error` tag and the first CHILD_OF reference.
+func fromJaegerSpan(js *jaegerSpan) (*v1.Span, *v1_common.InstrumentationScope, error) {
+	span := &v1.Span{
+		TraceId:           normalizeID(js.TraceID, 32),
+		SpanId:            normalizeID(js.SpanID, 16),
+		Name:              js.OperationName,
+		StartTimeUnixNano: js.StartTime * 1e3,
+		EndTimeUnixNano:   (js.StartTime + js.Duration) * 1e3,
+	}
+	for _, ref := range js.References {
+		if ref.RefType == jaegerChildOf && span.ParentSpanId == nil {
+			span.ParentSpanId = normalizeID(ref.SpanID, 16)
+			continue
+		}
+		span.Links = append(span.Links, &v1.Span_Link{TraceId: normalizeID(ref.TraceID, 32), SpanId: normalizeID(ref.SpanID, 16)})
+	}
+
+	scope := &v1_common.InstrumentationScope{}
+	var statusCode, statusMessage string
+	var isError bool
+	for _, tag := range js.Tags {
+		kv, err := fromJaegerKeyValue(tag)
+		if err != nil {
+			return nil, nil, err
+		}
+		value := datasource.AnyValueString(kv.Value)
+		switch tag.Key {
+		case jaegerTagSpanKind:
+			span.Kind = fromJaegerSpanKind(value)
+		case zipkinTagStatusCode:
+			statusCode = value
+		case jaegerTagStatusDesc:
+			statusMessage = value
+		case zipkinTagError:
+			isError = value == "true"
+		case zipkinTagTraceState:
+			span.TraceState = value
+		case zipkinTagLibraryName:
+			scope.Name = value
+		case zipkinTagLibraryVer:
+			scope.Version = value
+		default:
+			span.Attributes = append(span.Attributes, kv)
+		}
+	}
+	switch {
+	case statusCode == "ERROR" || statusCode == "" && isError:
+		span.Status = &v1.Status{Code: v1.Status_STATUS_CODE_ERROR, Message: statusMessage}
+	case statusCode == "OK":
+		span.Status = &v1.Status{Code: v1.Status_STATUS_CODE_OK}
+	default:
+		span.Status = &v1.Status{}
+	}
+
+	for _, log := range js.Logs {
+		event := &v1.Span_Event{TimeUnixNano: log.Timestamp * 1e3}
+		for _, field := range log.Fields {
+			kv, err := fromJaegerKeyValue(field)
+			if err != nil {
+				return nil, nil, err
+			}
+			if field.Key == jaegerLogEventField && event.Name == "" {
+				event.Name = datasource.AnyValueString(kv.Value)
+				continue
+			}
+			event.Attributes = append(event.Attributes, kv)
+		}
+		span.Events = append(span.Events, event)
+	}
+	return span, scope, nil
+}
+
+func fromJaegerKeyValue(tag jaegerKeyValue) (*v1_common.KeyValue, error) {
+	invalid := func() (*v1_common.KeyValue, error) {
+		return nil, fmt.Errorf("invalid %s value %v of tag %s", tag.Type, tag.Value, tag.Key)
+	}
+	value := &v1_common.AnyValue{}
+	switch strings.ToLower(tag.Type) {
+	case jaegerTypeBool:
+		b, ok := tag.Value.(bool)
+		if !ok {
+			return invalid()
+		}
+		value.Value = &v1_common.AnyValue_BoolValue{BoolValue: b}
+	case jaegerTypeInt64:
+		n, ok := tag.Value.(json.Number)
+		if !ok {
+			return invalid()
+		}
+		i, err := n.Int64()
+		if err != nil {
+			return invalid()
+		}
+		value.Value = &v1_common.AnyValue_IntValue{IntValue: i}
+	case jaegerTypeFloat64:
+		n, ok := tag.Value.(json.Number)
+		if !ok {
+			return invalid()
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return invalid()
+		}
+		value.Value = &v1_common.AnyValue_DoubleValue{DoubleValue: f}
+	case jaegerTypeBinary:
+		s, ok := tag.Value.(string)
+		if !ok {
+			return invalid()
+		}
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return invalid()
+		}
+		value.Value = &v1_common.AnyValue_BytesValue{BytesValue: b}
+	default:
+		s, ok := tag.Value.(string)
+		if !ok {
+			s = fmt.Sprint(tag.Value)
+		}
+		value.Value = &v1_common.AnyValue_StringValue{StringValue: s}
+	}
+	return &v1_common.KeyValue{Key: tag.Key, Value: value}, nil
+}
+
+func fromJaegerSpanKind(kind string) v1.Span_SpanKind {
+	switch strings.ToLower(kind) {
+	case "internal":
+		return v1.Span_SPAN_KIND_INTERNAL
+	case "server":
+		return v1.Span_SPAN_KIND_SERVER
+	case "client":
+		return v1.Span_SPAN_KIND_CLIENT
+	case "producer":
+		return v1.Span_SPAN_KIND_PRODUCER
+	case "consumer":
+		return v1.Span_SPAN_KIND_CONSUMER
+	default:
+		return v1.Span_SPAN_KIND_UNSPECIFIED
+	}
+}
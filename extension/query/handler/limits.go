@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/api/v1alpha1"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	v1 "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Limits are the guardrails applied to the queries before they reach the datasources.
+// A zero value disables the corresponding limit.
+type Limits struct {
+	// MaxLookback is how far in the past a query may start.
+	MaxLookback time.Duration `mapstructure:"max_lookback"`
+	// MaxRange is the largest time range a query may cover.
+	MaxRange time.Duration `mapstructure:"max_range"`
+	// DefaultRange is the time range, ending now, used when a query omits it.
+	DefaultRange time.Duration `mapstructure:"default_range"`
+	// RequestTimeout is the deadline of a single request.
+	RequestTimeout time.Duration `mapstructure:"request_timeout"`
+	// MaxNumTraces is the largest number of traces a search may return.
+	MaxNumTraces int `mapstructure:"max_num_traces"`
+	// MaxSpansPerTrace is the largest number of spans returned for a trace.
+	MaxSpansPerTrace int `mapstructure:"max_spans_per_trace"`
+}
+
+// applyTraceQuery fills the omitted time range and rejects the queries over the limits.
+func (l *Limits) applyTraceQuery(params *datasource.TraceQueryParameters, now time.Time) error {
+	if err := l.applyTimeRange(&params.StartTime, &params.EndTime, now); err != nil {
+		return err
+	}
+
+	if params.NumTraces < 0 {
+		return status.Errorf(codes.InvalidArgument, "num_traces %d must not be negative", params.NumTraces)
+	}
+	if l.MaxNumTraces > 0 && params.NumTraces > l.MaxNumTraces {
+		return status.Errorf(codes.InvalidArgument, "num_traces %d exceeds the maximum of %d", params.NumTraces, l.MaxNumTraces)
+	}
+	return nil
+}
+
+// recentTimeRange is the time range of the compatibility HTTP APIs when a query omits it and
+// the limits define no default range.
+const recentTimeRange = time.Hour
+
+// applyRecentTimeRange is applyTimeRange with the omitted range defaulting to the recent hour.
+func (l *Limits) applyRecentTimeRange(start, end *time.Time, now time.Time) error {
+	if start.IsZero() && l.DefaultRange == 0 {
+		if end.IsZero() {
+			*end = now
+		}
+		*start = end.Add(-recentTimeRange)
+	}
+	return l.applyTimeRange(start, end, now)
+}
+
+// applyTimeRange fills the omitted start and end times and rejects the ranges over the limits.
+func (l *Limits) applyTimeRange(start, end *time.Time, now time.Time) error {
+	if end.IsZero() {
+		*end = now
+	}
+	if start.IsZero() && l.DefaultRange > 0 {
+		*start = end.Add(-l.DefaultRange)
+	}
+	if start.IsZero() && (l.MaxRange > 0 || l.MaxLookback > 0) {
+		return status.Error(codes.InvalidArgument, "start time is required")
+	}
+	if !start.IsZero() && !start.Before(*end) {
+		return status.Errorf(codes.InvalidArgument, "start time %s must be before end time %s",
+			start.Format(time.RFC3339), end.Format(time.RFC3339))
+	}
+	if l.MaxRange > 0 && end.Sub(*start) > l.MaxRange {
+		return status.Errorf(codes.InvalidArgument, "time range %s exceeds the maximum of %s", end.Sub(*start), l.MaxRange)
+	}
+	if l.MaxLookback > 0 && start.Before(now.Add(-l.MaxLookback)) {
+		return status.Errorf(codes.InvalidArgument, "start time %s exceeds the maximum lookback of %s",
+			start.Format(time.RFC3339), l.MaxLookback)
+	}
+	return nil
+}
+
+// checkTraceSpans rejects a trace holding more spans than MaxSpansPerTrace.
+func (l *Limits) checkTraceSpans(trace *v1.TracesData) error {
+	if l.MaxSpansPerTrace <= 0 {
+		return nil
+	}
+	count := 0
+	for _, rs := range trace.GetResourceSpans() {
+		for _, ss := range rs.GetScopeSpans() {
+			count += len(ss.GetSpans())
+		}
+	}
+	if count > l.MaxSpansPerTrace {
+		return status.Errorf(codes.ResourceExhausted, "trace has more than %d spans", l.MaxSpansPerTrace)
+	}
+	return nil
+}
+
+// checkTracesSpans rejects a search result holding a trace with more spans than MaxSpansPerTrace.
+func (l *Limits) checkTracesSpans(traces *v1alpha1.TracesData) error {
+	if l.MaxSpansPerTrace <= 0 {
+		return nil
+	}
+	for _, trace := range traces.GetTraces() {
+		if int(trace.SpanCount) > l.MaxSpansPerTrace {
+			return status.Errorf(codes.ResourceExhausted, "trace %s has more than %d spans", trace.TraceId, l.MaxSpansPerTrace)
+		}
+	}
+	return nil
+}
+
+// spanLimitError converts the truncation of the traces by a datasource into ResourceExhausted.
+func spanLimitError(err error) error {
+	if errors.Is(err, datasource.ErrTooManySpans) {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return err
+}
+
+// sampleLimitError converts the truncation of the series by a datasource into ResourceExhausted.
+func sampleLimitError(err error) error {
+	if errors.Is(err, datasource.ErrTooManySamples) {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return err
+}
+
+// UnaryInterceptor bounds the calls served by Handler with RequestTimeout.
+func (l *Limits) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if _, ok := info.Server.(*Handler); !ok || l.RequestTimeout <= 0 {
+			return handler(ctx, req)
+		}
+		ctx, cancel := context.WithTimeout(ctx, l.RequestTimeout)
+		defer cancel()
+		res, err := handler(ctx, req)
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, status.Errorf(codes.DeadlineExceeded, "query exceeded the request timeout of %s", l.RequestTimeout)
+		}
+		return res, err
+	}
+}
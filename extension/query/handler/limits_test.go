@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/api/v1alpha1"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestApplyTraceQuery(t *testing.T) {
+	now := time.Date(2023, 4, 19, 12, 0, 0, 0, time.UTC)
+	limits := &Limits{
+		MaxLookback:  30 * 24 * time.Hour,
+		MaxRange:     24 * time.Hour,
+		DefaultRange: time.Hour,
+		MaxNumTraces: 100,
+	}
+
+	tests := []struct {
+		name   string
+		params datasource.TraceQueryParameters
+		code   codes.Code
+		start  time.Time
+		end    time.Time
+	}{
+		{
+			name:  "default range",
+			code:  codes.OK,
+			start: now.Add(-time.Hour),
+			end:   now,
+		},
+		{
+			name:   "default end",
+			params: datasource.TraceQueryParameters{StartTime: now.Add(-2 * time.Hour)},
+			code:   codes.OK,
+			start:  now.Add(-2 * time.Hour),
+			end:    now,
+		},
+		{
+			name:   "start after end",
+			params: datasource.TraceQueryParameters{StartTime: now, EndTime: now.Add(-time.Minute)},
+			code:   codes.InvalidArgument,
+		},
+		{
+			name:   "range too large",
+			params: datasource.TraceQueryParameters{StartTime: now.Add(-48 * time.Hour), EndTime: now},
+			code:   codes.InvalidArgument,
+		},
+		{
+			name:   "lookback too far",
+			params: datasource.TraceQueryParameters{StartTime: now.Add(-40 * 24 * time.Hour), EndTime: now.Add(-40*24*time.Hour + time.Hour)},
+			code:   codes.InvalidArgument,
+		},
+		{
+			name:   "too many traces",
+			params: datasource.TraceQueryParameters{NumTraces: 1000},
+			code:   codes.InvalidArgument,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := tt.params
+			err := limits.applyTraceQuery(&params, now)
+			assert.Equal(t, tt.code, status.Code(err))
+			if tt.code == codes.OK {
+				assert.Equal(t, tt.start, params.StartTime)
+				assert.Equal(t, tt.end, params.EndTime)
+			}
+		})
+	}
+}
+
+func TestApplyTraceQueryWithoutLimits(t *testing.T) {
+	params := &datasource.TraceQueryParameters{NumTraces: 100000}
+	require.NoError(t, (&Limits{}).applyTraceQuery(params, time.Now()))
+	assert.True(t, params.StartTime.IsZero())
+}
+
+func TestCheckTraceSpans(t *testing.T) {
+	limits := &Limits{MaxSpansPerTrace: 2}
+	trace := &v1.TracesData{ResourceSpans: []*v1.ResourceSpans{
+		{ScopeSpans: []*v1.ScopeSpans{{Spans: []*v1.Span{{}, {}}}}},
+	}}
+	require.NoError(t, limits.checkTraceSpans(trace))
+
+	trace.ResourceSpans[0].ScopeSpans[0].Spans = append(trace.ResourceSpans[0].ScopeSpans[0].Spans, &v1.Span{})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(limits.checkTraceSpans(trace)))
+
+	traces := &v1alpha1.TracesData{Traces: []*v1alpha1.Trace{{TraceId: "0102", SpanCount: 3}}}
+	assert.Equal(t, codes.ResourceExhausted, status.Code(limits.checkTracesSpans(traces)))
+
+	err := fmt.Errorf("%w: more than 10000 spans", datasource.ErrTooManySpans)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(spanLimitError(err)))
+	assert.Equal(t, codes.Unknown, status.Code(spanLimitError(errors.New("connection refused"))))
+
+	err = fmt.Errorf("%w: more than 10000 series", datasource.ErrTooManySamples)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(sampleLimitError(err)))
+	assert.Equal(t, codes.Unknown, status.Code(sampleLimitError(errors.New("connection refused"))))
+}
+
+func TestLimitsUnaryInterceptor(t *testing.T) {
+	limits := &Limits{RequestTimeout: 10 * time.Millisecond}
+	interceptor := limits.UnaryInterceptor()
+	slow := func(ctx context.Context, req interface{}) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{Server: &Handler{}}, slow)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+	res, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{Server: struct{}{}},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			_, ok := ctx.Deadline()
+			return ok, nil
+		})
+	require.NoError(t, err)
+	assert.Equal(t, false, res)
+}
@@ -0,0 +1,404 @@
+package handler
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The LogQL subset supported by the Loki API:
+//
+//	query    = log | metric
+//	log      = selector { filter }
+//	selector = "{" [ matcher { "," matcher } ] "}"
+//	matcher  = label ( "=" | "!=" | "=~" | "!~" ) string
+//	filter   = ( "|=" | "!=" | "|~" | "!~" ) string
+//	metric   = range | "sum" [ grouping ] "(" range ")" [ grouping ]
+//	range    = ( "count_over_time" | "rate" ) "(" log "[" duration "]" ")"
+//	grouping = "by" "(" [ label { "," label } ] ")"
+//
+// The labels are the resource attributes of the log records, see lokiLabelName.
+// refs: https://grafana.com/docs/loki/latest/logql/
+
+const (
+	logqlCountOverTime = "count_over_time"
+	logqlRate          = "rate"
+	logqlSum           = "sum"
+	logqlBy            = "by"
+)
+
+type logqlTokenKind int
+
+const (
+	logqlEOF logqlTokenKind = iota
+	logqlOpenBrace
+	logqlCloseBrace
+	logqlOpenParen
+	logqlCloseParen
+	logqlComma
+	logqlOperator
+	logqlIdentifier
+	logqlString
+	logqlDuration
+)
+
+type logqlToken struct {
+	kind  logqlTokenKind
+	value string
+	pos   int
+}
+
+// logqlOperators are ordered so the longest operator is matched first.
+var logqlOperators = []string{"|=", "|~", "!=", "!~", "=~", "="}
+
+func lexLogQL(input string) ([]logqlToken, error) {
+	var tokens []logqlToken
+	for i := 0; i < len(input); {
+		c := input[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+			continue
+		case strings.IndexByte("{}(),", c) >= 0:
+			kind := map[byte]logqlTokenKind{
+				'{': logqlOpenBrace, '}': logqlCloseBrace, '(': logqlOpenParen, ')': logqlCloseParen, ',': logqlComma,
+			}[c]
+			tokens = append(tokens, logqlToken{kind: kind, value: string(c), pos: i})
+			i++
+			continue
+		case c == '[':
+			end := strings.IndexByte(input[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated range at position %d", i)
+			}
+			tokens = append(tokens, logqlToken{kind: logqlDuration, value: strings.TrimSpace(input[i+1 : i+end]), pos: i})
+			i += end + 1
+			continue
+		case c == '"' || c == '`':
+			end := i + 1
+			for end < len(input) && input[end] != c {
+				if input[end] == '\\' && c == '"' {
+					end++
+				}
+				end++
+			}
+			if end >= len(input) {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+			value, err := strconv.Unquote(input[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at position %d: %w", i, err)
+			}
+			tokens = append(tokens, logqlToken{kind: logqlString, value: value, pos: i})
+			i = end + 1
+			continue
+		case c == '_' || unicode.IsLetter(rune(c)):
+			end := i + 1
+			for end < len(input) && isLokiLabelChar(input[end]) {
+				end++
+			}
+			tokens = append(tokens, logqlToken{kind: logqlIdentifier, value: input[i:end], pos: i})
+			i = end
+			continue
+		}
+
+		matched := false
+		for _, op := range logqlOperators {
+			if strings.HasPrefix(input[i:], op) {
+				tokens = append(tokens, logqlToken{kind: logqlOperator, value: op, pos: i})
+				i += len(op)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+		}
+	}
+	return append(tokens, logqlToken{kind: logqlEOF, pos: len(input)}), nil
+}
+
+// logqlQuery is a parsed LogQL query, the matcher names are Loki label names.
+type logqlQuery struct {
+	matchers    []datasource.LabelMatcher
+	lineFilters []datasource.LineFilter
+	// metric is nil for a log query.
+	metric *logqlMetric
+}
+
+// logqlMetric is the range aggregation of a metric query.
+type logqlMetric struct {
+	function      string
+	rangeInterval time.Duration
+	// sum aggregates the series by the grouping labels.
+	sum     bool
+	groupBy []string
+}
+
+// parseLogQL parses a log or metric query.
+func parseLogQL(input string) (*logqlQuery, error) {
+	return parseLogQLWith(input, (*logqlParser).parseQuery)
+}
+
+// parseLogQLSelector parses a stream selector, as the `match[]` parameters of the series endpoint.
+func parseLogQLSelector(input string) (*logqlQuery, error) {
+	return parseLogQLWith(input, (*logqlParser).parseSelector)
+}
+
+func parseLogQLWith(input string, parse func(p *logqlParser) error) (*logqlQuery, error) {
+	tokens, err := lexLogQL(input)
+	if err == nil {
+		p := &logqlParser{tokens: tokens, query: &logqlQuery{}}
+		err = parse(p)
+		if err == nil && p.peek().kind != logqlEOF {
+			err = p.unexpected()
+		}
+		if err == nil {
+			return p.query, nil
+		}
+	}
+	return nil, status.Errorf(codes.InvalidArgument, "invalid LogQL query: %v", err)
+}
+
+type logqlParser struct {
+	tokens []logqlToken
+	pos    int
+	query  *logqlQuery
+}
+
+func (p *logqlParser) peek() logqlToken {
+	return p.tokens[p.pos]
+}
+
+func (p *logqlParser) next() logqlToken {
+	t := p.tokens[p.pos]
+	if t.kind != logqlEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *logqlParser) unexpected() error {
+	t := p.peek()
+	if t.kind == logqlEOF {
+		return fmt.Errorf("unexpected end of query")
+	}
+	return fmt.Errorf("unexpected %q at position %d", t.value, t.pos)
+}
+
+func (p *logqlParser) expect(kind logqlTokenKind) (logqlToken, error) {
+	if p.peek().kind != kind {
+		return logqlToken{}, p.unexpected()
+	}
+	return p.next(), nil
+}
+
+func (p *logqlParser) parseQuery() error {
+	if p.peek().kind == logqlOpenBrace {
+		return p.parseLog()
+	}
+	p.query.metric = &logqlMetric{}
+	if t := p.peek(); t.kind == logqlIdentifier && t.value == logqlSum {
+		p.next()
+		p.query.metric.sum = true
+		if err := p.parseGrouping(); err != nil {
+			return err
+		}
+		if _, err := p.expect(logqlOpenParen); err != nil {
+			return err
+		}
+		if err := p.parseRange(); err != nil {
+			return err
+		}
+		if _, err := p.expect(logqlCloseParen); err != nil {
+			return err
+		}
+		return p.parseGrouping()
+	}
+	return p.parseRange()
+}
+
+func (p *logqlParser) parseRange() error {
+	t, err := p.expect(logqlIdentifier)
+	if err != nil {
+		return err
+	}
+	if t.value != logqlCountOverTime && t.value != logqlRate {
+		return fmt.Errorf("unsupported function %q at position %d", t.value, t.pos)
+	}
+	p.query.metric.function = t.value
+	if _, err = p.expect(logqlOpenParen); err != nil {
+		return err
+	}
+	if err = p.parseLog(); err != nil {
+		return err
+	}
+	t, err = p.expect(logqlDuration)
+	if err != nil {
+		return err
+	}
+	if p.query.metric.rangeInterval, err = parseLogQLDuration(t.value); err != nil {
+		return fmt.Errorf("invalid range at position %d: %w", t.pos, err)
+	}
+	if p.query.metric.rangeInterval < time.Second {
+		return fmt.Errorf("range at position %d must be at least 1s", t.pos)
+	}
+	_, err = p.expect(logqlCloseParen)
+	return err
+}
+
+func (p *logqlParser) parseGrouping() error {
+	if t := p.peek(); t.kind != logqlIdentifier || t.value != logqlBy {
+		return nil
+	}
+	p.next()
+	if p.query.metric.groupBy != nil {
+		return fmt.Errorf("duplicate grouping at position %d", p.peek().pos)
+	}
+	if _, err := p.expect(logqlOpenParen); err != nil {
+		return err
+	}
+	p.query.metric.groupBy = []string{}
+	for p.peek().kind != logqlCloseParen {
+		if len(p.query.metric.groupBy) > 0 {
+			if _, err := p.expect(logqlComma); err != nil {
+				return err
+			}
+		}
+		t, err := p.expect(logqlIdentifier)
+		if err != nil {
+			return err
+		}
+		p.query.metric.groupBy = append(p.query.metric.groupBy, t.value)
+	}
+	p.next()
+	return nil
+}
+
+func (p *logqlParser) parseLog() error {
+	if err := p.parseSelector(); err != nil {
+		return err
+	}
+	for p.peek().kind == logqlOperator {
+		op := p.next()
+		filter := datasource.LineFilter{}
+		switch op.value {
+		case "|=":
+			filter.Type = datasource.MatchEqual
+		case "!=":
+			filter.Type = datasource.MatchNotEqual
+		case "|~":
+			filter.Type = datasource.MatchRegexp
+		case "!~":
+			filter.Type = datasource.MatchNotRegexp
+		default:
+			return fmt.Errorf("unexpected %q at position %d", op.value, op.pos)
+		}
+		t, err := p.expect(logqlString)
+		if err != nil {
+			return err
+		}
+		if err = checkLogQLRegexp(filter.Type, t); err != nil {
+			return err
+		}
+		filter.Value = t.value
+		p.query.lineFilters = append(p.query.lineFilters, filter)
+	}
+	return nil
+}
+
+func (p *logqlParser) parseSelector() error {
+	if _, err := p.expect(logqlOpenBrace); err != nil {
+		return err
+	}
+	for p.peek().kind != logqlCloseBrace {
+		if len(p.query.matchers) > 0 {
+			if _, err := p.expect(logqlComma); err != nil {
+				return err
+			}
+		}
+		name, err := p.expect(logqlIdentifier)
+		if err != nil {
+			return err
+		}
+		op, err := p.expect(logqlOperator)
+		if err != nil {
+			return err
+		}
+		matcher := datasource.LabelMatcher{Name: name.value}
+		switch op.value {
+		case "=":
+			matcher.Type = datasource.MatchEqual
+		case "!=":
+			matcher.Type = datasource.MatchNotEqual
+		case "=~":
+			matcher.Type = datasource.MatchRegexp
+		case "!~":
+			matcher.Type = datasource.MatchNotRegexp
+		default:
+			return fmt.Errorf("unexpected %q at position %d", op.value, op.pos)
+		}
+		value, err := p.expect(logqlString)
+		if err != nil {
+			return err
+		}
+		if err = checkLogQLRegexp(matcher.Type, value); err != nil {
+			return err
+		}
+		matcher.Value = value.value
+		p.query.matchers = append(p.query.matchers, matcher)
+	}
+	p.next()
+	if len(p.query.matchers) == 0 {
+		return fmt.Errorf("stream selector must contain at least one label matcher")
+	}
+	return nil
+}
+
+func checkLogQLRegexp(matchType datasource.MatchType, t logqlToken) error {
+	if matchType != datasource.MatchRegexp && matchType != datasource.MatchNotRegexp {
+		return nil
+	}
+	if _, err := regexp.Compile(t.value); err != nil {
+		return fmt.Errorf("invalid regular expression at position %d: %w", t.pos, err)
+	}
+	return nil
+}
+
+// parseLogQLDuration parses a Go duration, which may also be in days such as `1d`.
+func parseLogQLDuration(value string) (time.Duration, error) {
+	if days := strings.TrimSuffix(value, "d"); days != value {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
+
+func isLokiLabelChar(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// lokiLabelName sanitizes a resource attribute key into a valid Loki label name,
+// such as `service_name` for `service.name`.
+func lokiLabelName(key string) string {
+	b := []byte(key)
+	for i, c := range b {
+		if !isLokiLabelChar(c) {
+			b[i] = '_'
+		}
+	}
+	if len(b) > 0 && b[0] >= '0' && b[0] <= '9' {
+		return "_" + string(b)
+	}
+	return string(b)
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestParseLogQL(t *testing.T) {
+	query, err := parseLogQL("{service_name=\"frontend\", host_name!~`test-.*`} |= \"error\" != \"timeout\" |~ `refused|reset` !~ \"x\"")
+	require.NoError(t, err)
+	assert.Nil(t, query.metric)
+	assert.Equal(t, []datasource.LabelMatcher{
+		{Name: "service_name", Type: datasource.MatchEqual, Value: "frontend"},
+		{Name: "host_name", Type: datasource.MatchNotRegexp, Value: "test-.*"},
+	}, query.matchers)
+	assert.Equal(t, []datasource.LineFilter{
+		{Type: datasource.MatchEqual, Value: "error"},
+		{Type: datasource.MatchNotEqual, Value: "timeout"},
+		{Type: datasource.MatchRegexp, Value: "refused|reset"},
+		{Type: datasource.MatchNotRegexp, Value: "x"},
+	}, query.lineFilters)
+}
+
+func TestParseLogQLMetric(t *testing.T) {
+	tests := []struct {
+		query  string
+		metric *logqlMetric
+	}{
+		{
+			query:  `count_over_time({job="a"}[5m])`,
+			metric: &logqlMetric{function: logqlCountOverTime, rangeInterval: 5 * time.Minute},
+		},
+		{
+			query:  `rate({job="a"} |= "error" [1d])`,
+			metric: &logqlMetric{function: logqlRate, rangeInterval: 24 * time.Hour},
+		},
+		{
+			query:  `sum(rate({job="a"}[30s]))`,
+			metric: &logqlMetric{function: logqlRate, rangeInterval: 30 * time.Second, sum: true},
+		},
+		{
+			query:  `sum by (service_name, host_name) (count_over_time({job="a"}[1m]))`,
+			metric: &logqlMetric{function: logqlCountOverTime, rangeInterval: time.Minute, sum: true, groupBy: []string{"service_name", "host_name"}},
+		},
+		{
+			query:  `sum(count_over_time({job="a"}[1m])) by (service_name)`,
+			metric: &logqlMetric{function: logqlCountOverTime, rangeInterval: time.Minute, sum: true, groupBy: []string{"service_name"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			query, err := parseLogQL(tt.query)
+			require.NoError(t, err)
+			assert.Equal(t, tt.metric, query.metric)
+			assert.Equal(t, []datasource.LabelMatcher{{Name: "job", Type: datasource.MatchEqual, Value: "a"}}, query.matchers)
+		})
+	}
+}
+
+func TestParseLogQLErrors(t *testing.T) {
+	for _, query := range []string{
+		``,
+		`{}`,
+		`{job="a"`,
+		`{job="a"} |= error`,
+		`{job=~"("}`,
+		`{job="a"} |~ "["`,
+		`{job="a"} | json`,
+		`count_over_time({job="a"})`,
+		`count_over_time({job="a"}[5x])`,
+		`count_over_time({job="a"}[500ms])`,
+		`avg_over_time({job="a"}[5m])`,
+		`sum by (a) (count_over_time({job="a"}[5m])) by (b)`,
+		`{job="a"} extra`,
+	} {
+		t.Run(query, func(t *testing.T) {
+			_, err := parseLogQL(query)
+			require.Error(t, err)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
+
+func TestLokiLabelName(t *testing.T) {
+	assert.Equal(t, "service_name", lokiLabelName("service.name"))
+	assert.Equal(t, "k8s_pod_name", lokiLabelName("k8s.pod.name"))
+	assert.Equal(t, "_0_x", lokiLabelName("0-x"))
+}
@@ -0,0 +1,475 @@
+package handler
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	lokiDefaultLimit = 100
+	lokiMaxLimit     = 5000
+	// lokiMaxPoints is the maximum number of points of a metric series, as in Loki.
+	lokiMaxPoints = 11000
+	// lokiMaxBuckets is the maximum number of buckets counted by the datasource for a metric query.
+	lokiMaxBuckets = 100000
+	// lokiDefaultStepPoints is the number of points of a metric series when the step is omitted.
+	lokiDefaultStepPoints = 250
+	lokiDirectionForward  = "forward"
+	lokiDirectionBackward = "backward"
+)
+
+// LokiHandler serves the query endpoints of the Grafana Loki HTTP API from the logging
+// datasource, with a LogQL subset, see logql.go.
+// refs: https://grafana.com/docs/loki/latest/reference/api/
+type LokiHandler struct {
+	QueryService *QueryService
+	Limits       Limits
+	// Available reports whether the logging datasource can serve queries, optional.
+	Available func() error
+}
+
+// RegisterRoutes adds the Loki endpoints under `/loki/api/v1` to router.
+func (l *LokiHandler) RegisterRoutes(router *mux.Router) {
+	r := router.PathPrefix("/loki/api/v1").Subrouter()
+	r.HandleFunc("/query_range", l.queryRange).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/labels", l.labels).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/label/{name}/values", l.labelValues).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/series", l.series).Methods(http.MethodGet, http.MethodPost)
+}
+
+func (l *LokiHandler) queryRange(w http.ResponseWriter, r *http.Request) {
+	query, err := parseLogQL(r.FormValue("query"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	params, err := parseLokiQueryParameters(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if params.StartTime, params.EndTime, err = l.parseTimeRange(r); err != nil {
+		writeError(w, err)
+		return
+	}
+	ctx, cancel, err := beginRequest(r, l.Limits, l.Available)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer cancel()
+
+	if params.Matchers, err = l.resolveMatchers(ctx, query.matchers, params.StartTime, params.EndTime); err != nil {
+		writeError(w, err)
+		return
+	}
+	params.LineFilters = query.lineFilters
+
+	if query.metric == nil {
+		entries, err := l.QueryService.Logging().FindLogs(ctx, params)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, &lokiResponse{Status: lokiStatusSuccess, Data: &lokiQueryData{
+			ResultType: lokiResultTypeStreams,
+			Result:     toLokiStreams(entries),
+		}})
+		return
+	}
+
+	step, err := parseLokiStep(r.FormValue("step"), params.StartTime, params.EndTime)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	eval, err := newLogQLEvaluation(query.metric, params.StartTime, params.EndTime, step)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	params.StartTime, params.Step = eval.countStart(), eval.bucket
+	series, err := l.QueryService.Logging().CountLogs(ctx, params)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, &lokiResponse{Status: lokiStatusSuccess, Data: &lokiQueryData{
+		ResultType: lokiResultTypeMatrix,
+		Result:     eval.evaluate(series),
+	}})
+}
+
+func (l *LokiHandler) labels(w http.ResponseWriter, r *http.Request) {
+	start, end, err := l.parseTimeRange(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	ctx, cancel, err := beginRequest(r, l.Limits, l.Available)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer cancel()
+
+	keys, err := l.QueryService.Logging().LogLabelNames(ctx, start, end)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	names := map[string]struct{}{}
+	for _, key := range keys {
+		names[lokiLabelName(key)] = struct{}{}
+	}
+	writeJSON(w, &lokiResponse{Status: lokiStatusSuccess, Data: sortedKeys(names)})
+}
+
+func (l *LokiHandler) labelValues(w http.ResponseWriter, r *http.Request) {
+	start, end, err := l.parseTimeRange(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	ctx, cancel, err := beginRequest(r, l.Limits, l.Available)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer cancel()
+
+	keys, err := l.labelKeys(ctx, start, end)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	name := mux.Vars(r)["name"]
+	if key, ok := keys[name]; ok {
+		name = key
+	}
+	values, err := l.QueryService.Logging().LogLabelValues(ctx, name, start, end)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if values == nil {
+		values = []string{}
+	}
+	writeJSON(w, &lokiResponse{Status: lokiStatusSuccess, Data: values})
+}
+
+func (l *LokiHandler) series(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, status.Error(codes.InvalidArgument, err.Error()))
+		return
+	}
+	selectors := r.Form["match[]"]
+	if len(selectors) == 0 {
+		writeError(w, status.Error(codes.InvalidArgument, "at least one match[] parameter is required"))
+		return
+	}
+	var queries []*logqlQuery
+	for _, selector := range selectors {
+		query, err := parseLogQLSelector(selector)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		queries = append(queries, query)
+	}
+	start, end, err := l.parseTimeRange(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	ctx, cancel, err := beginRequest(r, l.Limits, l.Available)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer cancel()
+
+	keys, err := l.labelKeys(ctx, start, end)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	result := []map[string]string{}
+	seen := map[string]struct{}{}
+	for _, query := range queries {
+		streams, err := l.QueryService.Logging().LogStreams(ctx, &datasource.LogQueryParameters{
+			Matchers:  resolveMatchers(query.matchers, keys),
+			StartTime: start,
+			EndTime:   end,
+		})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		for _, stream := range streams {
+			labels := lokiLabels(stream)
+			key := labelsKey(labels)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			result = append(result, labels)
+		}
+	}
+	writeJSON(w, &lokiResponse{Status: lokiStatusSuccess, Data: result})
+}
+
+// parseTimeRange reads the `start` and `end` parameters and applies the limits, the omitted
+// range is the last hour unless the limits define a default range.
+func (l *LokiHandler) parseTimeRange(r *http.Request) (time.Time, time.Time, error) {
+	start, err := parseLokiTime(r.FormValue("start"))
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := parseLokiTime(r.FormValue("end"))
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if err = l.Limits.applyRecentTimeRange(&start, &end, time.Now()); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return start, end, nil
+}
+
+// labelKeys maps the Loki label names to the resource attribute keys they are sanitized from,
+// the first key in order wins when several are sanitized into the same name.
+func (l *LokiHandler) labelKeys(ctx context.Context, start, end time.Time) (map[string]string, error) {
+	names, err := l.QueryService.Logging().LogLabelNames(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	keys := make(map[string]string, len(names))
+	for _, key := range names {
+		if _, ok := keys[lokiLabelName(key)]; !ok {
+			keys[lokiLabelName(key)] = key
+		}
+	}
+	return keys, nil
+}
+
+func (l *LokiHandler) resolveMatchers(ctx context.Context, matchers []datasource.LabelMatcher, start, end time.Time) ([]datasource.LabelMatcher, error) {
+	keys, err := l.labelKeys(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return resolveMatchers(matchers, keys), nil
+}
+
+// resolveMatchers replaces the Loki label names of matchers by the resource attribute keys,
+// the unknown names are kept as is.
+func resolveMatchers(matchers []datasource.LabelMatcher, keys map[string]string) []datasource.LabelMatcher {
+	resolved := make([]datasource.LabelMatcher, len(matchers))
+	for i, m := range matchers {
+		if key, ok := keys[m.Name]; ok {
+			m.Name = key
+		}
+		resolved[i] = m
+	}
+	return resolved
+}
+
+// parseLokiQueryParameters reads the `limit` and `direction` parameters of a query.
+func parseLokiQueryParameters(r *http.Request) (*datasource.LogQueryParameters, error) {
+	params := &datasource.LogQueryParameters{Limit: lokiDefaultLimit}
+	if value := r.FormValue("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid limit %q", value)
+		}
+		if limit > lokiMaxLimit {
+			return nil, status.Errorf(codes.InvalidArgument, "limit %d exceeds the maximum of %d", limit, lokiMaxLimit)
+		}
+		params.Limit = limit
+	}
+	switch direction := strings.ToLower(r.FormValue("direction")); direction {
+	case "", lokiDirectionBackward:
+	case lokiDirectionForward:
+		params.Forward = true
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "invalid direction %q", direction)
+	}
+	return params, nil
+}
+
+// parseLokiTime parses a Unix epoch in nanoseconds, or in seconds up to 10 digits or with
+// a fraction, or an RFC3339 time. It returns the zero time for an empty value.
+func parseLokiTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if strings.Contains(value, ".") {
+		if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+			s, ns := math.Modf(seconds)
+			return time.Unix(int64(s), int64(math.Round(ns*1e9))), nil
+		}
+	}
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if len(value) <= 10 {
+			return time.Unix(n, 0), nil
+		}
+		return time.Unix(0, n), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, status.Errorf(codes.InvalidArgument, "invalid time %q", value)
+	}
+	return t, nil
+}
+
+// parseLokiStep parses a duration or a number of seconds, the default step gives about
+// lokiDefaultStepPoints points over the time range.
+func parseLokiStep(value string, start, end time.Time) (time.Duration, error) {
+	if value == "" {
+		return time.Duration(math.Max(math.Floor(end.Sub(start).Seconds()/lokiDefaultStepPoints), 1)) * time.Second, nil
+	}
+	var step time.Duration
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		step = time.Duration(seconds * float64(time.Second))
+	} else if step, err = parseLogQLDuration(value); err != nil {
+		return 0, status.Errorf(codes.InvalidArgument, "invalid step %q", value)
+	}
+	if step < time.Second {
+		return 0, status.Errorf(codes.InvalidArgument, "step %q must be at least 1s", value)
+	}
+	return step, nil
+}
+
+// logqlEvaluation evaluates a metric query at every step from the bucket counts of the datasource.
+// The value at t is computed from the buckets in [t-range, t), the bucket width divides the range
+// and the step so the windows are made of whole buckets.
+type logqlEvaluation struct {
+	metric        *logqlMetric
+	start, end    time.Time
+	step          time.Duration
+	rangeInterval time.Duration
+	bucket        time.Duration
+}
+
+func newLogQLEvaluation(metric *logqlMetric, start, end time.Time, step time.Duration) (*logqlEvaluation, error) {
+	if points := int64(end.Sub(start)/step) + 1; points > lokiMaxPoints {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"exceeded maximum resolution of %d points per timeseries, try increasing the step", lokiMaxPoints)
+	}
+	rangeSeconds, stepSeconds := int64(metric.rangeInterval/time.Second), int64(step/time.Second)
+	bucketSeconds := gcd(rangeSeconds, stepSeconds)
+	e := &logqlEvaluation{
+		metric: metric,
+		// the evaluation timestamps are aligned on the buckets
+		start:         time.Unix(start.Unix()-start.Unix()%bucketSeconds, 0),
+		end:           end,
+		step:          time.Duration(stepSeconds) * time.Second,
+		rangeInterval: time.Duration(rangeSeconds) * time.Second,
+		bucket:        time.Duration(bucketSeconds) * time.Second,
+	}
+	if e.numBuckets() > lokiMaxBuckets {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"query needs more than %d buckets, try increasing the step or aligning the range on it", lokiMaxBuckets)
+	}
+	return e, nil
+}
+
+// countStart is the start of the first bucket counted.
+func (e *logqlEvaluation) countStart() time.Time {
+	return e.start.Add(-e.rangeInterval)
+}
+
+func (e *logqlEvaluation) numBuckets() int {
+	return int(e.end.Sub(e.countStart())/e.bucket) + 1
+}
+
+func (e *logqlEvaluation) evaluate(series []*datasource.LogSeries) []*lokiSeries {
+	type group struct {
+		labels map[string]string
+		values []float64
+	}
+	var timestamps []time.Time
+	for t := e.start; !t.After(e.end); t = t.Add(e.step) {
+		timestamps = append(timestamps, t)
+	}
+	groups := map[string]*group{}
+	var keys []string
+	windowBuckets := int(e.rangeInterval / e.bucket)
+	for _, s := range series {
+		// counts[i] is the number of records before the bucket i
+		counts := make([]int64, e.numBuckets()+1)
+		for _, sample := range s.Samples {
+			if i := int(sample.Timestamp.Sub(e.countStart()) / e.bucket); i >= 0 && i < len(counts)-1 {
+				counts[i+1] += sample.Count
+			}
+		}
+		for i := 1; i < len(counts); i++ {
+			counts[i] += counts[i-1]
+		}
+
+		labels := e.groupLabels(lokiLabels(s.Labels))
+		key := labelsKey(labels)
+		g, ok := groups[key]
+		if !ok {
+			g = &group{labels: labels, values: make([]float64, len(timestamps))}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		for i, t := range timestamps {
+			end := int(t.Sub(e.countStart()) / e.bucket)
+			g.values[i] += float64(counts[end] - counts[end-windowBuckets])
+		}
+	}
+
+	result := []*lokiSeries{}
+	sort.Strings(keys)
+	for _, key := range keys {
+		g := groups[key]
+		s := &lokiSeries{Metric: g.labels, Values: []promPoint{}}
+		for i, value := range g.values {
+			// as in Loki, the steps without log records have no value
+			if value == 0 {
+				continue
+			}
+			if e.metric.function == logqlRate {
+				value /= e.rangeInterval.Seconds()
+			}
+			s.Values = append(s.Values, promPoint{Timestamp: timestamps[i], Value: value})
+		}
+		if len(s.Values) > 0 {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// groupLabels returns the labels of the result series of a stream.
+func (e *logqlEvaluation) groupLabels(labels map[string]string) map[string]string {
+	if !e.metric.sum {
+		return labels
+	}
+	grouped := map[string]string{}
+	for _, name := range e.metric.groupBy {
+		if value, ok := labels[name]; ok {
+			grouped[name] = value
+		}
+	}
+	return grouped
+}
+
+func gcd(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+)
+
+const (
+	lokiStatusSuccess     = "success"
+	lokiResultTypeStreams = "streams"
+	lokiResultTypeMatrix  = "matrix"
+)
+
+// lokiResponse is the envelope of the Loki API responses.
+type lokiResponse struct {
+	Status string      `json:"status"`
+	Data   interface{} `json:"data"`
+}
+
+type lokiQueryData struct {
+	ResultType string      `json:"resultType"`
+	Result     interface{} `json:"result"`
+}
+
+// lokiStream is the log lines of a stream, as pairs of nanosecond timestamp and line.
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// lokiSeries is a metric series, as pairs of second timestamp and value.
+type lokiSeries struct {
+	Metric map[string]string `json:"metric"`
+	Values []promPoint       `json:"values"`
+}
+
+// lokiLabels sanitizes the resource attribute keys of a stream into Loki label names.
+func lokiLabels(attributes map[string]string) map[string]string {
+	labels := make(map[string]string, len(attributes))
+	for k, v := range attributes {
+		labels[lokiLabelName(k)] = v
+	}
+	return labels
+}
+
+// toLokiStreams groups the log entries by stream, keeping their order.
+func toLokiStreams(entries []*datasource.LogEntry) []*lokiStream {
+	streams := []*lokiStream{}
+	byKey := map[string]*lokiStream{}
+	for _, entry := range entries {
+		labels := lokiLabels(entry.Labels)
+		key := labelsKey(labels)
+		stream, ok := byKey[key]
+		if !ok {
+			stream = &lokiStream{Stream: labels}
+			byKey[key] = stream
+			streams = append(streams, stream)
+		}
+		stream.Values = append(stream.Values, [2]string{strconv.FormatInt(entry.Timestamp.UnixNano(), 10), entry.Line})
+	}
+	return streams
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLokiRouter(query *mockLogQuery) *mux.Router {
+	router := mux.NewRouter()
+	loki := &LokiHandler{
+		QueryService: &QueryService{LoggingQuerySvc: query},
+		Limits:       Limits{MaxRange: 24 * time.Hour},
+	}
+	loki.RegisterRoutes(router)
+	return router
+}
+
+func serveLoki(router *mux.Router, path string, params url.Values) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path+"?"+params.Encode(), nil))
+	return rec
+}
+
+func TestLokiQueryRangeStreams(t *testing.T) {
+	frontend := map[string]string{"service.name": "frontend"}
+	query := &mockLogQuery{entries: []*datasource.LogEntry{
+		{Timestamp: time.Unix(1681873201, 0), Labels: frontend, Line: "error: timeout"},
+		{Timestamp: time.Unix(1681873202, 0), Labels: map[string]string{"service.name": "backend"}, Line: "error: refused"},
+		{Timestamp: time.Unix(1681873203, 0), Labels: frontend, Line: "error: reset"},
+	}}
+	rec := serveLoki(newLokiRouter(query), "/loki/api/v1/query_range", url.Values{
+		"query":     {`{service_name=~"frontend|backend"} |= "error"`},
+		"start":     {"1681873200000000000"},
+		"end":       {"1681876800"},
+		"limit":     {"10"},
+		"direction": {"forward"},
+	})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"status":"success","data":{"resultType":"streams","result":[
+		{"stream":{"service_name":"frontend"},"values":[["1681873201000000000","error: timeout"],["1681873203000000000","error: reset"]]},
+		{"stream":{"service_name":"backend"},"values":[["1681873202000000000","error: refused"]]}
+	]}}`, rec.Body.String())
+
+	assert.Equal(t, &datasource.LogQueryParameters{
+		Matchers:    []datasource.LabelMatcher{{Name: "service.name", Type: datasource.MatchRegexp, Value: "frontend|backend"}},
+		LineFilters: []datasource.LineFilter{{Type: datasource.MatchEqual, Value: "error"}},
+		StartTime:   time.Unix(1681873200, 0),
+		EndTime:     time.Unix(1681876800, 0),
+		Limit:       10,
+		Forward:     true,
+	}, query.lastQuery)
+}
+
+func TestLokiQueryRangeMetric(t *testing.T) {
+	query := &mockLogQuery{series: []*datasource.LogSeries{
+		{Labels: map[string]string{"service.name": "frontend", "host.name": "localhost"}, Samples: []datasource.LogSample{
+			{Timestamp: time.Unix(1681873080, 0), Count: 1},
+			{Timestamp: time.Unix(1681873200, 0), Count: 2},
+			{Timestamp: time.Unix(1681873260, 0), Count: 3},
+		}},
+		{Labels: map[string]string{"service.name": "frontend", "host.name": "other"}, Samples: []datasource.LogSample{
+			{Timestamp: time.Unix(1681873260, 0), Count: 4},
+		}},
+	}}
+	router := newLokiRouter(query)
+	params := url.Values{
+		"query": {`sum by (service_name) (count_over_time({service_name="frontend"}[2m]))`},
+		"start": {"1681873200"},
+		"end":   {"1681873380"},
+		"step":  {"60"},
+	}
+	rec := serveLoki(router, "/loki/api/v1/query_range", params)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"status":"success","data":{"resultType":"matrix","result":[
+		{"metric":{"service_name":"frontend"},"values":[[1681873200,"1"],[1681873260,"2"],[1681873320,"9"],[1681873380,"7"]]}
+	]}}`, rec.Body.String())
+	assert.Equal(t, time.Unix(1681873080, 0), query.lastQuery.StartTime)
+	assert.Equal(t, time.Minute, query.lastQuery.Step)
+
+	params.Set("query", `rate({service_name="frontend"}[2m])`)
+	params.Set("step", "1m")
+	rec = serveLoki(router, "/loki/api/v1/query_range", params)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"status":"success","data":{"resultType":"matrix","result":[
+		{"metric":{"service_name":"frontend","host_name":"localhost"},"values":[[1681873200,"0.008333333333333333"],[1681873260,"0.016666666666666666"],[1681873320,"0.041666666666666664"],[1681873380,"0.025"]]},
+		{"metric":{"service_name":"frontend","host_name":"other"},"values":[[1681873320,"0.03333333333333333"],[1681873380,"0.03333333333333333"]]}
+	]}}`, rec.Body.String())
+}
+
+func TestLokiQueryRangeErrors(t *testing.T) {
+	router := newLokiRouter(&mockLogQuery{})
+	for _, params := range []url.Values{
+		{"query": {`{service_name=}`}},
+		{"query": {`{service_name="a"}`}, "limit": {"5001"}},
+		{"query": {`{service_name="a"}`}, "direction": {"sideways"}},
+		{"query": {`{service_name="a"}`}, "start": {"1681800000"}, "end": {"1681900000"}},
+		{"query": {`count_over_time({service_name="a"}[1m])`}, "start": {"1681800000"}, "end": {"1681850000"}, "step": {"1"}},
+	} {
+		rec := serveLoki(router, "/loki/api/v1/query_range", params)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, params.Encode())
+	}
+}
+
+func TestLokiLabels(t *testing.T) {
+	router := newLokiRouter(&mockLogQuery{})
+
+	rec := serveLoki(router, "/loki/api/v1/labels", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"status":"success","data":["host_name","service_name"]}`, rec.Body.String())
+
+	rec = serveLoki(router, "/loki/api/v1/label/service_name/values", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"status":"success","data":["backend","frontend"]}`, rec.Body.String())
+
+	rec = serveLoki(router, "/loki/api/v1/label/missing/values", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"status":"success","data":[]}`, rec.Body.String())
+}
+
+func TestLokiSeries(t *testing.T) {
+	query := &mockLogQuery{}
+	router := newLokiRouter(query)
+
+	rec := serveLoki(router, "/loki/api/v1/series", url.Values{"match[]": {`{host_name="localhost"}`}})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"status":"success","data":[{"service_name":"frontend","host_name":"localhost"}]}`, rec.Body.String())
+	assert.Equal(t, []datasource.LabelMatcher{{Name: "host.name", Type: datasource.MatchEqual, Value: "localhost"}}, query.lastQuery.Matchers)
+
+	rec = serveLoki(router, "/loki/api/v1/series", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestParseLokiTime(t *testing.T) {
+	tests := map[string]time.Time{
+		"":                     {},
+		"1681873200":           time.Unix(1681873200, 0),
+		"1681873200.5":         time.Unix(1681873200, 500000000),
+		"1681873200000000001":  time.Unix(1681873200, 1),
+		"2023-04-19T03:00:00Z": time.Date(2023, 4, 19, 3, 0, 0, 0, time.UTC),
+	}
+	for value, expected := range tests {
+		actual, err := parseLokiTime(value)
+		require.NoError(t, err, value)
+		assert.True(t, expected.Equal(actual), value)
+	}
+	_, err := parseLokiTime("yesterday")
+	assert.Error(t, err)
+}
@@ -0,0 +1,245 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/api/v1alpha1"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	v1_common "go.opentelemetry.io/proto/otlp/common/v1"
+	v1_logs "go.opentelemetry.io/proto/otlp/logs/v1"
+	v1_resource "go.opentelemetry.io/proto/otlp/resource/v1"
+	v1 "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// mockQuery serves the spans of traces and records the last search parameters.
+type mockQuery struct {
+	traces     map[string]*v1.TracesData
+	lastSearch *datasource.TraceQueryParameters
+	// searches and gets count the calls of FindTraces and GetTrace.
+	searches, gets int
+	// spans are the result of SearchSpans.
+	spans          *v1.TracesData
+	lastSpanSearch *datasource.SpanQueryParameters
+	// groups are the result of AggregateSpans, or aggregate computes it when it's set.
+	groups        []*datasource.AggregateGroup
+	aggregate     func(query *datasource.SpanQueryParameters, agg *datasource.AggregateParameters) []*datasource.AggregateGroup
+	lastAggregate *datasource.AggregateParameters
+}
+
+func (m *mockQuery) GetTrace(_ context.Context, traceID string) (*v1.TracesData, error) {
+	m.gets++
+	if td, ok := m.traces[traceID]; ok {
+		return td, nil
+	}
+	return &v1.TracesData{}, nil
+}
+
+func (m *mockQuery) SearchTraces(ctx context.Context, query *datasource.TraceQueryParameters) (*v1alpha1.TracesData, error) {
+	td, _ := m.FindTraces(ctx, query)
+	return datasource.DocumentsTracesConvert(td)
+}
+
+func (m *mockQuery) FindTraces(_ context.Context, query *datasource.TraceQueryParameters) (*v1.TracesData, error) {
+	m.lastSearch = query
+	m.searches++
+	var rs []*v1.ResourceSpans
+	for _, td := range m.traces {
+		rs = append(rs, td.ResourceSpans...)
+	}
+	return &v1.TracesData{ResourceSpans: rs}, nil
+}
+
+func (m *mockQuery) SearchSpans(_ context.Context, query *datasource.SpanQueryParameters) (*v1.TracesData, error) {
+	m.lastSpanSearch = query
+	return m.spans, nil
+}
+
+func (m *mockQuery) AggregateSpans(_ context.Context, query *datasource.SpanQueryParameters, agg *datasource.AggregateParameters) ([]*datasource.AggregateGroup, error) {
+	m.lastSpanSearch, m.lastAggregate = query, agg
+	if m.aggregate != nil {
+		return m.aggregate(query, agg), nil
+	}
+	return m.groups, nil
+}
+
+func (m *mockQuery) SearchLogs(context.Context) (*v1_logs.LogsData, error) {
+	return nil, nil
+}
+
+func (m *mockQuery) GetLog(context.Context) (*v1_logs.LogsData, error) {
+	return nil, nil
+}
+
+func (m *mockQuery) GetService(context.Context) ([]*v1_resource.Resource, error) {
+	return []*v1_resource.Resource{{Attributes: []*v1_common.KeyValue{stringAttribute("service.name", "frontend")}}}, nil
+}
+
+func (m *mockQuery) GetOperations(context.Context, *datasource.OperationsQueryParameters) ([]string, error) {
+	return []string{"GET /hello"}, nil
+}
+
+// mockTraceArchive copies the traces of a mockQuery.
+type mockTraceArchive struct {
+	query    *mockQuery
+	archived map[string]*v1.TracesData
+}
+
+func (m *mockTraceArchive) ArchiveTrace(_ context.Context, traceID string) (int, error) {
+	td, ok := m.query.traces[traceID]
+	if !ok {
+		return 0, nil
+	}
+	if m.archived == nil {
+		m.archived = map[string]*v1.TracesData{}
+	}
+	m.archived[traceID] = td
+	return countSpans(td), nil
+}
+
+func (m *mockTraceArchive) GetArchivedTrace(_ context.Context, traceID string) (*v1.TracesData, error) {
+	if td, ok := m.archived[traceID]; ok {
+		return td, nil
+	}
+	return &v1.TracesData{}, nil
+}
+
+func countSpans(td *v1.TracesData) int {
+	count := 0
+	forEachSpan(td, func(*v1.Span) { count++ })
+	return count
+}
+
+func stringAttribute(key, value string) *v1_common.KeyValue {
+	return &v1_common.KeyValue{Key: key, Value: &v1_common.AnyValue{Value: &v1_common.AnyValue_StringValue{StringValue: value}}}
+}
+
+// mockTrace is a trace of a server span and its client child span.
+func mockTrace() *v1.TracesData {
+	traceID := []byte("01020304050607080807060504030201")
+	return &v1.TracesData{ResourceSpans: []*v1.ResourceSpans{{
+		Resource: &v1_resource.Resource{Attributes: []*v1_common.KeyValue{
+			stringAttribute("service.name", "frontend"),
+			stringAttribute("host.name", "localhost"),
+		}},
+		ScopeSpans: []*v1.ScopeSpans{{
+			Scope: &v1_common.InstrumentationScope{Name: "net/http", Version: "0.36.0"},
+			Spans: []*v1.Span{
+				{
+					TraceId:           traceID,
+					SpanId:            []byte("0102030405060708"),
+					Name:              "GET /hello",
+					Kind:              v1.Span_SPAN_KIND_SERVER,
+					StartTimeUnixNano: 1681873445000000000,
+					EndTimeUnixNano:   1681873445002000000,
+					Attributes:        []*v1_common.KeyValue{stringAttribute("http.method", "GET")},
+					Status:            &v1.Status{},
+				},
+				{
+					TraceId:           traceID,
+					SpanId:            []byte("1112131415161718"),
+					ParentSpanId:      []byte("0102030405060708"),
+					Name:              "SELECT",
+					Kind:              v1.Span_SPAN_KIND_CLIENT,
+					StartTimeUnixNano: 1681873445000500000,
+					EndTimeUnixNano:   1681873445001500000,
+					Attributes: []*v1_common.KeyValue{
+						stringAttribute("peer.service", "mysql"),
+						stringAttribute("net.peer.ip", "10.0.0.1"),
+						{Key: "net.peer.port", Value: &v1_common.AnyValue{Value: &v1_common.AnyValue_IntValue{IntValue: 3306}}},
+					},
+					Events: []*v1.Span_Event{{TimeUnixNano: 1681873445001000000, Name: "exception",
+						Attributes: []*v1_common.KeyValue{stringAttribute("exception.message", "timeout")}}},
+					Status: &v1.Status{Code: v1.Status_STATUS_CODE_ERROR, Message: "timeout"},
+				},
+			},
+		}},
+	}}}
+}
+
+// mockLogQuery serves canned log records and series and records the last query parameters.
+type mockLogQuery struct {
+	entries   []*datasource.LogEntry
+	series    []*datasource.LogSeries
+	lastQuery *datasource.LogQueryParameters
+	// groups are the result of AggregateLogs.
+	groups        []*datasource.AggregateGroup
+	lastAggregate *datasource.AggregateParameters
+}
+
+func (m *mockLogQuery) FindLogs(_ context.Context, query *datasource.LogQueryParameters) ([]*datasource.LogEntry, error) {
+	m.lastQuery = query
+	return m.entries, nil
+}
+
+func (m *mockLogQuery) CountLogs(_ context.Context, query *datasource.LogQueryParameters) ([]*datasource.LogSeries, error) {
+	m.lastQuery = query
+	return m.series, nil
+}
+
+func (m *mockLogQuery) LogLabelNames(context.Context, time.Time, time.Time) ([]string, error) {
+	return []string{"service.name", "host.name"}, nil
+}
+
+func (m *mockLogQuery) LogLabelValues(_ context.Context, name string, _, _ time.Time) ([]string, error) {
+	if name == "service.name" {
+		return []string{"backend", "frontend"}, nil
+	}
+	return nil, nil
+}
+
+func (m *mockLogQuery) LogStreams(_ context.Context, query *datasource.LogQueryParameters) ([]map[string]string, error) {
+	m.lastQuery = query
+	return []map[string]string{
+		{"service.name": "frontend", "host.name": "localhost"},
+		{"service.name": "frontend", "host.name": "localhost"},
+	}, nil
+}
+
+func (m *mockLogQuery) AggregateLogs(_ context.Context, query *datasource.LogQueryParameters, agg *datasource.AggregateParameters) ([]*datasource.AggregateGroup, error) {
+	m.lastQuery, m.lastAggregate = query, agg
+	return m.groups, nil
+}
+
+// mockMetricQuery serves the samples of series matching the query in its time range and records
+// the queries.
+type mockMetricQuery struct {
+	series  []*datasource.MetricSeries
+	queries []*datasource.MetricQueryParameters
+}
+
+func (m *mockMetricQuery) SelectSeries(_ context.Context, query *datasource.MetricQueryParameters) ([]*datasource.MetricSeries, error) {
+	m.queries = append(m.queries, query)
+	filter, err := datasource.NewLabelFilter(query.Matchers)
+	if err != nil {
+		return nil, err
+	}
+	var result []*datasource.MetricSeries
+	for _, s := range m.series {
+		if !filter.Matches(s.Labels) {
+			continue
+		}
+		selected := &datasource.MetricSeries{Labels: s.Labels}
+		for _, sample := range s.Samples {
+			if !sample.Timestamp.Before(query.StartTime) && !sample.Timestamp.After(query.EndTime) {
+				selected.Samples = append(selected.Samples, sample)
+			}
+		}
+		result = append(result, selected)
+	}
+	return result, nil
+}
+
+func (m *mockMetricQuery) SeriesLabels(ctx context.Context, query *datasource.MetricQueryParameters) ([]map[string]string, error) {
+	series, err := m.SelectSeries(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	var result []map[string]string
+	for _, s := range series {
+		if len(s.Samples) > 0 {
+			result = append(result, s.Labels)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,337 @@
+package handler
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PrometheusHandler serves the query endpoints of the Prometheus HTTP API from the metrics
+// datasource, with a PromQL engine evaluating the queries, see promql.go.
+// refs: https://prometheus.io/docs/prometheus/latest/querying/api/
+type PrometheusHandler struct {
+	QueryService *QueryService
+	Limits       Limits
+	// Available reports whether the metrics datasource can serve queries, optional.
+	Available func() error
+}
+
+// RegisterRoutes adds the Prometheus endpoints under `/api/v1` to router.
+func (p *PrometheusHandler) RegisterRoutes(router *mux.Router) {
+	r := router.PathPrefix("/api/v1").Subrouter()
+	r.HandleFunc("/query", p.query).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/query_range", p.queryRange).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/series", p.series).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/labels", p.labels).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/label/{name}/values", p.labelValues).Methods(http.MethodGet, http.MethodPost)
+}
+
+func (p *PrometheusHandler) query(w http.ResponseWriter, r *http.Request) {
+	expr, err := parsePromQL(r.FormValue("query"))
+	if err != nil {
+		writePromError(w, err)
+		return
+	}
+	t, err := parsePromTime(r.FormValue("time"))
+	if err != nil {
+		writePromError(w, err)
+		return
+	}
+	now := time.Now()
+	if t.IsZero() {
+		t = now
+	}
+	start, end := t.Add(-promLookbackDelta), t
+	if err = p.Limits.applyTimeRange(&start, &end, now); err != nil {
+		writePromError(w, err)
+		return
+	}
+	ctx, cancel, err := beginRequest(r, p.Limits, p.Available)
+	if err != nil {
+		writePromError(w, err)
+		return
+	}
+	defer cancel()
+
+	e, err := newPromEvaluator(ctx, p.QueryService.Metrics(), expr, t, t)
+	if err != nil {
+		writePromError(w, err)
+		return
+	}
+	value, err := e.eval(expr, t)
+	if err != nil {
+		writePromError(w, err)
+		return
+	}
+	writeJSON(w, &promResponse{Status: promStatusSuccess, Data: toPromQueryData(value, t)})
+}
+
+func (p *PrometheusHandler) queryRange(w http.ResponseWriter, r *http.Request) {
+	expr, err := parsePromQL(r.FormValue("query"))
+	if err != nil {
+		writePromError(w, err)
+		return
+	}
+	if t := expr.valueType(); t != promTypeScalar && t != promTypeVector {
+		writePromError(w, status.Errorf(codes.InvalidArgument,
+			"invalid expression type %q for range query, must be scalar or instant vector", t))
+		return
+	}
+	start, err := parsePromTime(r.FormValue("start"))
+	if err != nil {
+		writePromError(w, err)
+		return
+	}
+	end, err := parsePromTime(r.FormValue("end"))
+	if err != nil {
+		writePromError(w, err)
+		return
+	}
+	if start.IsZero() || end.IsZero() {
+		writePromError(w, status.Error(codes.InvalidArgument, "start and end times are required"))
+		return
+	}
+	if end.Before(start) {
+		writePromError(w, status.Error(codes.InvalidArgument, "end timestamp must not be before start time"))
+		return
+	}
+	step, err := parsePromStep(r.FormValue("step"))
+	if err != nil {
+		writePromError(w, err)
+		return
+	}
+	if end.Sub(start)/step >= promPointsLimit {
+		writePromError(w, status.Errorf(codes.InvalidArgument,
+			"exceeded maximum resolution of %d points per timeseries, try decreasing the query resolution (?step=XX)", promPointsLimit))
+		return
+	}
+	rangeStart, rangeEnd := start.Add(-promLookbackDelta), end
+	if err = p.Limits.applyTimeRange(&rangeStart, &rangeEnd, time.Now()); err != nil {
+		writePromError(w, err)
+		return
+	}
+	ctx, cancel, err := beginRequest(r, p.Limits, p.Available)
+	if err != nil {
+		writePromError(w, err)
+		return
+	}
+	defer cancel()
+
+	e, err := newPromEvaluator(ctx, p.QueryService.Metrics(), expr, start, end)
+	if err != nil {
+		writePromError(w, err)
+		return
+	}
+	series, err := e.evalRange(expr, start, end, step)
+	if err != nil {
+		writePromError(w, err)
+		return
+	}
+	writeJSON(w, &promResponse{Status: promStatusSuccess, Data: &promQueryData{
+		ResultType: promResultTypeMatrix,
+		Result:     series,
+	}})
+}
+
+func (p *PrometheusHandler) series(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writePromError(w, status.Error(codes.InvalidArgument, err.Error()))
+		return
+	}
+	if len(r.Form["match[]"]) == 0 {
+		writePromError(w, status.Error(codes.InvalidArgument, "no match[] parameter provided"))
+		return
+	}
+	result, err := p.seriesLabels(r)
+	if err != nil {
+		writePromError(w, err)
+		return
+	}
+	writeJSON(w, &promResponse{Status: promStatusSuccess, Data: result})
+}
+
+func (p *PrometheusHandler) labels(w http.ResponseWriter, r *http.Request) {
+	series, err := p.seriesLabels(r)
+	if err != nil {
+		writePromError(w, err)
+		return
+	}
+	names := map[string]struct{}{}
+	for _, labels := range series {
+		for name := range labels {
+			names[name] = struct{}{}
+		}
+	}
+	writeJSON(w, &promResponse{Status: promStatusSuccess, Data: sortedKeys(names)})
+}
+
+func (p *PrometheusHandler) labelValues(w http.ResponseWriter, r *http.Request) {
+	series, err := p.seriesLabels(r)
+	if err != nil {
+		writePromError(w, err)
+		return
+	}
+	name := mux.Vars(r)["name"]
+	values := map[string]struct{}{}
+	for _, labels := range series {
+		if value, ok := labels[name]; ok {
+			values[value] = struct{}{}
+		}
+	}
+	writeJSON(w, &promResponse{Status: promStatusSuccess, Data: sortedKeys(values)})
+}
+
+// seriesLabels returns the distinct label sets of the series matching any `match[]` selector,
+// or of every series when there is none, in the `start` and `end` time range.
+func (p *PrometheusHandler) seriesLabels(r *http.Request) ([]map[string]string, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	var selectors [][]datasource.LabelMatcher
+	for _, selector := range r.Form["match[]"] {
+		matchers, err := parsePromSelector(selector)
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, matchers)
+	}
+	if len(selectors) == 0 {
+		selectors = [][]datasource.LabelMatcher{{{Name: datasource.MetricNameLabel, Type: datasource.MatchRegexp, Value: ".+"}}}
+	}
+	start, end, err := p.parseTimeRange(r)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel, err := beginRequest(r, p.Limits, p.Available)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	result := []map[string]string{}
+	seen := map[string]struct{}{}
+	for _, matchers := range selectors {
+		series, err := p.QueryService.Metrics().SeriesLabels(ctx, &datasource.MetricQueryParameters{
+			Matchers:  matchers,
+			StartTime: start,
+			EndTime:   end,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, labels := range series {
+			key := labelsKey(labels)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			result = append(result, labels)
+		}
+	}
+	return result, nil
+}
+
+// parseTimeRange reads the `start` and `end` parameters and applies the limits, the omitted
+// range is the last hour unless the limits define a default range.
+func (p *PrometheusHandler) parseTimeRange(r *http.Request) (time.Time, time.Time, error) {
+	start, err := parsePromTime(r.FormValue("start"))
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := parsePromTime(r.FormValue("end"))
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if err = p.Limits.applyRecentTimeRange(&start, &end, time.Now()); err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return start, end, nil
+}
+
+// toPromQueryData encodes the result of an instant query evaluated at t.
+func toPromQueryData(value promValue, t time.Time) *promQueryData {
+	switch v := value.(type) {
+	case promScalar:
+		return &promQueryData{ResultType: promResultTypeScalar, Result: promPoint{Timestamp: t, Value: float64(v)}}
+	case promVector:
+		result := make([]*promResultSample, 0, len(v))
+		for _, s := range v {
+			result = append(result, &promResultSample{Metric: s.labels, Value: promPoint{Timestamp: t, Value: s.value}})
+		}
+		return &promQueryData{ResultType: promResultTypeVector, Result: result}
+	}
+	result := []*promResultSeries{}
+	for _, s := range value.(promMatrix) {
+		series := &promResultSeries{Metric: s.labels, Values: make([]promPoint, 0, len(s.samples))}
+		for _, sample := range s.samples {
+			series.Values = append(series.Values, promPoint{Timestamp: sample.Timestamp, Value: sample.Value})
+		}
+		result = append(result, series)
+	}
+	return &promQueryData{ResultType: promResultTypeMatrix, Result: result}
+}
+
+// parsePromTime parses a Unix epoch in seconds, with an optional fraction, or an RFC3339 time.
+// It returns the zero time for an empty value.
+func parsePromTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		s, ns := math.Modf(seconds)
+		return time.Unix(int64(s), int64(math.Round(ns*1e9))), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, status.Errorf(codes.InvalidArgument, "invalid time %q", value)
+	}
+	return t, nil
+}
+
+// parsePromStep parses a number of seconds or a PromQL duration.
+func parsePromStep(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, status.Error(codes.InvalidArgument, "step is required")
+	}
+	var step time.Duration
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		step = time.Duration(seconds * float64(time.Second))
+	} else if step, err = parsePromDuration(value); err != nil {
+		return 0, status.Errorf(codes.InvalidArgument, "invalid step %q", value)
+	}
+	if step <= 0 {
+		return 0, status.Errorf(codes.InvalidArgument, "step %q must be positive", value)
+	}
+	return step, nil
+}
+
+// writePromError writes err in the Prometheus error format, with the HTTP status Prometheus
+// uses for its error type.
+func writePromError(w http.ResponseWriter, err error) {
+	var errorType string
+	var code int
+	err = sampleLimitError(err)
+	switch status.Code(err) {
+	case codes.InvalidArgument:
+		errorType, code = promErrorBadData, http.StatusBadRequest
+	case codes.NotFound:
+		errorType, code = promErrorNotFound, http.StatusNotFound
+	case codes.DeadlineExceeded, codes.Canceled:
+		errorType, code = promErrorTimeout, http.StatusServiceUnavailable
+	case codes.Unavailable:
+		errorType, code = promErrorUnavailable, http.StatusServiceUnavailable
+	case codes.ResourceExhausted:
+		errorType, code = promErrorExecution, http.StatusUnprocessableEntity
+	default:
+		errorType, code = promErrorInternal, http.StatusInternalServerError
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	writeJSON(w, &promResponse{Status: promStatusError, ErrorType: errorType, Error: status.Convert(err).Message()})
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+const (
+	promStatusSuccess    = "success"
+	promStatusError      = "error"
+	promResultTypeScalar = "scalar"
+	promResultTypeVector = "vector"
+	promResultTypeMatrix = "matrix"
+	promErrorBadData     = "bad_data"
+	promErrorTimeout     = "timeout"
+	promErrorUnavailable = "unavailable"
+	promErrorInternal    = "internal"
+	promErrorNotFound    = "not_found"
+	promErrorExecution   = "execution"
+)
+
+// promResponse is the envelope of the Prometheus API responses.
+type promResponse struct {
+	Status    string      `json:"status"`
+	Data      interface{} `json:"data,omitempty"`
+	ErrorType string      `json:"errorType,omitempty"`
+	Error     string      `json:"error,omitempty"`
+}
+
+type promQueryData struct {
+	ResultType string      `json:"resultType"`
+	Result     interface{} `json:"result"`
+}
+
+// promResultSeries is a series of a matrix result.
+type promResultSeries struct {
+	Metric map[string]string `json:"metric"`
+	Values []promPoint       `json:"values"`
+}
+
+// promResultSample is a sample of a vector result.
+type promResultSample struct {
+	Metric map[string]string `json:"metric"`
+	Value  promPoint         `json:"value"`
+}
+
+// promPoint is the value of a series at Timestamp.
+type promPoint struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// MarshalJSON encodes the point as `[<seconds>, "<value>"]` as Prometheus does.
+func (p promPoint) MarshalJSON() ([]byte, error) {
+	return json.Marshal([2]interface{}{
+		json.Number(strconv.FormatFloat(float64(p.Timestamp.UnixNano())/1e9, 'f', -1, 64)),
+		strconv.FormatFloat(p.Value, 'f', -1, 64),
+	})
+}
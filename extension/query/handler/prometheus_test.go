@@ -0,0 +1,319 @@
+package handler
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// promTestStart is the timestamp of the first sample of the mock series.
+var promTestStart = time.Unix(1681873200, 0)
+
+// mockCounter is a series with a sample every 15s for 10m, increasing by perSecond.
+func mockCounter(labels map[string]string, perSecond float64) *datasource.MetricSeries {
+	s := &datasource.MetricSeries{Labels: labels}
+	for i := 0; i <= 40; i++ {
+		s.Samples = append(s.Samples, datasource.MetricSample{
+			Timestamp: promTestStart.Add(time.Duration(i) * 15 * time.Second),
+			Value:     float64(i) * 15 * perSecond,
+		})
+	}
+	return s
+}
+
+func mockGauge(labels map[string]string, value float64) *datasource.MetricSeries {
+	return &datasource.MetricSeries{Labels: labels, Samples: []datasource.MetricSample{{Timestamp: promTestStart.Add(4 * time.Minute), Value: value}}}
+}
+
+func newMockMetricQuery() *mockMetricQuery {
+	return &mockMetricQuery{series: []*datasource.MetricSeries{
+		mockCounter(map[string]string{"__name__": "http_requests_total", "job": "api", "instance": "a"}, 1),
+		mockCounter(map[string]string{"__name__": "http_requests_total", "job": "api", "instance": "b"}, 2),
+		mockCounter(map[string]string{"__name__": "http_requests_total", "job": "web", "instance": "a"}, 0.5),
+		mockGauge(map[string]string{"__name__": "duration_seconds_bucket", "job": "api", "le": "0.1"}, 10),
+		mockGauge(map[string]string{"__name__": "duration_seconds_bucket", "job": "api", "le": "1"}, 30),
+		mockGauge(map[string]string{"__name__": "duration_seconds_bucket", "job": "api", "le": "+Inf"}, 40),
+	}}
+}
+
+// evalPromQL evaluates query at promTestStart plus at.
+func evalPromQL(t *testing.T, query string, at time.Duration) promValue {
+	expr, err := parsePromQL(query)
+	require.NoError(t, err)
+	ts := promTestStart.Add(at)
+	e, err := newPromEvaluator(context.Background(), newMockMetricQuery(), expr, ts, ts)
+	require.NoError(t, err)
+	value, err := e.eval(expr, ts)
+	require.NoError(t, err)
+	return value
+}
+
+func TestPromQLEvaluation(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected promValue
+	}{
+		{
+			query: `http_requests_total{instance="a"}`,
+			expected: promVector{
+				{labels: map[string]string{"__name__": "http_requests_total", "job": "api", "instance": "a"}, value: 300},
+				{labels: map[string]string{"__name__": "http_requests_total", "job": "web", "instance": "a"}, value: 150},
+			},
+		},
+		{
+			query:    `http_requests_total{job="api", instance="b"} offset 1m`,
+			expected: promVector{{labels: map[string]string{"__name__": "http_requests_total", "job": "api", "instance": "b"}, value: 480}},
+		},
+		{
+			query: `sum by (job) (rate(http_requests_total[1m]))`,
+			expected: promVector{
+				{labels: map[string]string{"job": "api"}, value: 3},
+				{labels: map[string]string{"job": "web"}, value: 0.5},
+			},
+		},
+		{
+			query:    `count without (instance, job) (http_requests_total)`,
+			expected: promVector{{labels: map[string]string{}, value: 3}},
+		},
+		{
+			query:    `topk(1, http_requests_total)`,
+			expected: promVector{{labels: map[string]string{"__name__": "http_requests_total", "job": "api", "instance": "b"}, value: 600}},
+		},
+		{
+			query:    `http_requests_total > 500`,
+			expected: promVector{{labels: map[string]string{"__name__": "http_requests_total", "job": "api", "instance": "b"}, value: 600}},
+		},
+		{
+			query:    `increase(http_requests_total{job="web"}[2m]) / 2 - 1`,
+			expected: promVector{{labels: map[string]string{"job": "web", "instance": "a"}, value: 29}},
+		},
+		{
+			query:    `http_requests_total{job="api", instance="b"} / ignoring(instance) http_requests_total{job="api", instance="a"}`,
+			expected: promVector{{labels: map[string]string{"job": "api"}, value: 2}},
+		},
+		{
+			query:    `http_requests_total{instance="b"} / on(job) http_requests_total{job="api", instance="a"}`,
+			expected: promVector{{labels: map[string]string{"job": "api"}, value: 2}},
+		},
+		{
+			query:    `http_requests_total{instance="a"} unless http_requests_total{job="web"}`,
+			expected: promVector{{labels: map[string]string{"__name__": "http_requests_total", "job": "api", "instance": "a"}, value: 300}},
+		},
+		{
+			query:    `histogram_quantile(0.5, duration_seconds_bucket)`,
+			expected: promVector{{labels: map[string]string{"job": "api"}, value: 0.55}},
+		},
+		{
+			query:    `round(avg_over_time(http_requests_total{instance="b"}[1m]) / 7, 0.5)`,
+			expected: promVector{{labels: map[string]string{"job": "api", "instance": "b"}, value: 79.5}},
+		},
+		{
+			query:    `scalar(max(http_requests_total)) - 2 * time() % 10`,
+			expected: promScalar(600),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			value := evalPromQL(t, tt.query, 5*time.Minute)
+			if vector, ok := tt.expected.(promVector); ok {
+				assert.ElementsMatch(t, vector, value, tt.query)
+				return
+			}
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestPromQLDuplicateMatch(t *testing.T) {
+	expr, err := parsePromQL(`http_requests_total / on(job) http_requests_total`)
+	require.NoError(t, err)
+	e, err := newPromEvaluator(context.Background(), newMockMetricQuery(), expr, promTestStart, promTestStart)
+	require.NoError(t, err)
+	_, err = e.eval(expr, promTestStart)
+	assert.ErrorContains(t, err, "many-to-many matching is not supported")
+}
+
+func TestExtrapolatedRate(t *testing.T) {
+	start := promTestStart
+	samples := []datasource.MetricSample{
+		{Timestamp: start.Add(10 * time.Second), Value: 100},
+		{Timestamp: start.Add(20 * time.Second), Value: 110},
+		{Timestamp: start.Add(30 * time.Second), Value: 5},
+		{Timestamp: start.Add(40 * time.Second), Value: 15},
+	}
+	// the reset from 110 to 5 is compensated: 15 - 100 + 110 = 25 over 30s, extrapolated to 50s
+	increase, ok := extrapolatedRate(samples, start, start.Add(50*time.Second), true, false)
+	require.True(t, ok)
+	assert.InDelta(t, 25.0*50/30, increase, 1e-9)
+
+	// a counter isn't extrapolated below zero
+	samples = []datasource.MetricSample{
+		{Timestamp: start.Add(10 * time.Second), Value: 1},
+		{Timestamp: start.Add(20 * time.Second), Value: 11},
+	}
+	increase, ok = extrapolatedRate(samples, start, start.Add(20*time.Second), true, false)
+	require.True(t, ok)
+	assert.InDelta(t, 11.0, increase, 1e-9)
+
+	_, ok = extrapolatedRate(samples[:1], start, start.Add(20*time.Second), true, true)
+	assert.False(t, ok)
+}
+
+func TestHistogramQuantile(t *testing.T) {
+	bucket := func(le string, count float64) promSample {
+		return promSample{labels: map[string]string{"__name__": "d_bucket", "le": le}, value: count}
+	}
+	vector := promVector{bucket("+Inf", 100), bucket("1", 100), bucket("0.5", 50)}
+	assert.Equal(t, promVector{{labels: map[string]string{}, value: 0.75}}, histogramQuantile(0.75, vector))
+	assert.Equal(t, promVector{{labels: map[string]string{}, value: 1}}, histogramQuantile(1, vector))
+	assert.True(t, math.IsInf(histogramQuantile(2, vector)[0].value, 1))
+	assert.True(t, math.IsNaN(histogramQuantile(0.5, promVector{bucket("1", 10)})[0].value))
+}
+
+func newPrometheusRouter(query *mockMetricQuery) *mux.Router {
+	router := mux.NewRouter()
+	prometheus := &PrometheusHandler{
+		QueryService: &QueryService{MetricsQuerySvc: query},
+		Limits:       Limits{MaxRange: 24 * time.Hour},
+	}
+	prometheus.RegisterRoutes(router)
+	return router
+}
+
+func servePrometheus(router *mux.Router, path string, params url.Values) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path+"?"+params.Encode(), nil))
+	return rec
+}
+
+func promTestTime(offset time.Duration) string {
+	return strconv.FormatInt(promTestStart.Add(offset).Unix(), 10)
+}
+
+func TestPrometheusQuery(t *testing.T) {
+	router := newPrometheusRouter(newMockMetricQuery())
+	rec := servePrometheus(router, "/api/v1/query", url.Values{
+		"query": {`sum(http_requests_total{job="api"})`},
+		"time":  {promTestTime(time.Minute)},
+	})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"status":"success","data":{"resultType":"vector","result":[
+		{"metric":{},"value":[1681873260,"180"]}
+	]}}`, rec.Body.String())
+
+	rec = servePrometheus(router, "/api/v1/query", url.Values{"query": {`1 / 0`}, "time": {"1681873260.5"}})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"status":"success","data":{"resultType":"scalar","result":[1681873260.5,"+Inf"]}}`, rec.Body.String())
+
+	rec = servePrometheus(router, "/api/v1/query", url.Values{
+		"query": {`http_requests_total{job="web"}[30s]`},
+		"time":  {promTestTime(time.Minute)},
+	})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"status":"success","data":{"resultType":"matrix","result":[
+		{"metric":{"__name__":"http_requests_total","job":"web","instance":"a"},"values":[[1681873245,"22.5"],[1681873260,"30"]]}
+	]}}`, rec.Body.String())
+}
+
+func TestPrometheusQueryRange(t *testing.T) {
+	query := newMockMetricQuery()
+	rec := servePrometheus(newPrometheusRouter(query), "/api/v1/query_range", url.Values{
+		"query": {`rate(http_requests_total{instance="a"}[1m]) * 60`},
+		"start": {promTestTime(time.Minute)},
+		"end":   {promTestTime(2 * time.Minute)},
+		"step":  {"30s"},
+	})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"status":"success","data":{"resultType":"matrix","result":[
+		{"metric":{"instance":"a","job":"api"},"values":[[1681873260,"60"],[1681873290,"60"],[1681873320,"60"]]},
+		{"metric":{"instance":"a","job":"web"},"values":[[1681873260,"30"],[1681873290,"30"],[1681873320,"30"]]}
+	]}}`, rec.Body.String())
+
+	// the series are read once for the whole range and the window of the selector
+	require.Len(t, query.queries, 1)
+	assert.Equal(t, promTestStart, query.queries[0].StartTime)
+	assert.Equal(t, promTestStart.Add(2*time.Minute), query.queries[0].EndTime)
+}
+
+func TestPrometheusErrors(t *testing.T) {
+	router := newPrometheusRouter(newMockMetricQuery())
+	tests := []struct {
+		path   string
+		params url.Values
+		error  string
+	}{
+		{
+			path:   "/api/v1/query",
+			params: url.Values{"query": {`sum(`}},
+			error:  "invalid PromQL query: unexpected end of query",
+		},
+		{
+			path:   "/api/v1/query_range",
+			params: url.Values{"query": {`up[5m]`}, "start": {"1"}, "end": {"2"}, "step": {"1"}},
+			error:  `invalid expression type "range vector" for range query, must be scalar or instant vector`,
+		},
+		{
+			path:   "/api/v1/query_range",
+			params: url.Values{"query": {`up`}, "start": {"0"}, "end": {"20000"}, "step": {"1"}},
+			error:  "exceeded maximum resolution of 11000 points per timeseries, try decreasing the query resolution (?step=XX)",
+		},
+		{
+			path:   "/api/v1/query_range",
+			params: url.Values{"query": {`up`}, "start": {"2"}, "end": {"1"}, "step": {"1"}},
+			error:  "end timestamp must not be before start time",
+		},
+		{
+			path:   "/api/v1/query_range",
+			params: url.Values{"query": {`up`}, "start": {"1"}, "end": {"2"}},
+			error:  "step is required",
+		},
+		{
+			path:   "/api/v1/series",
+			params: url.Values{},
+			error:  "no match[] parameter provided",
+		},
+	}
+	for _, tt := range tests {
+		rec := servePrometheus(router, tt.path, tt.params)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, tt.path)
+		assert.JSONEq(t, `{"status":"error","errorType":"bad_data","error":`+strconv.Quote(tt.error)+`}`, rec.Body.String())
+	}
+}
+
+func TestPrometheusSeriesAndLabels(t *testing.T) {
+	router := newPrometheusRouter(newMockMetricQuery())
+	timeRange := url.Values{"start": {promTestTime(0)}, "end": {promTestTime(time.Hour)}}
+	params := url.Values{"match[]": {`{job="web"}`, `http_requests_total{job="web"}`}}
+	for k, v := range timeRange {
+		params[k] = v
+	}
+	rec := servePrometheus(router, "/api/v1/series", params)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"status":"success","data":[{"__name__":"http_requests_total","job":"web","instance":"a"}]}`, rec.Body.String())
+
+	rec = servePrometheus(router, "/api/v1/labels", timeRange)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"status":"success","data":["__name__","instance","job","le"]}`, rec.Body.String())
+
+	rec = servePrometheus(router, "/api/v1/label/__name__/values", timeRange)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"status":"success","data":["duration_seconds_bucket","http_requests_total"]}`, rec.Body.String())
+
+	params = url.Values{"match[]": {`{job="web"}`}}
+	for k, v := range timeRange {
+		params[k] = v
+	}
+	rec = servePrometheus(router, "/api/v1/label/instance/values", params)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"status":"success","data":["a"]}`, rec.Body.String())
+}
@@ -0,0 +1,731 @@
+package handler
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The PromQL subset supported by the Prometheus API:
+//
+//	expr      = unary { binary-op [ "bool" ] [ ( "on" | "ignoring" ) labels ] unary }
+//	unary     = [ "-" | "+" ] primary
+//	primary   = number | "(" expr ")" | selector | call | aggregate
+//	selector  = ( name [ "{" matchers "}" ] | "{" matchers "}" ) [ "[" duration "]" ] [ "offset" duration ]
+//	call      = function "(" [ expr { "," expr } ] ")"
+//	aggregate = aggr-op [ grouping ] "(" [ scalar "," ] expr ")" [ grouping ]
+//	grouping  = ( "by" | "without" ) labels
+//
+// The binary operators are `or`, `and` `unless`, the comparisons, `+` `-`, `*` `/` `%` and `^`
+// from the lowest to the highest precedence. Vectors are matched one-to-one, `group_left` and
+// `group_right` as well as the subqueries are not supported. See promFunctions and
+// promAggregations for the functions and aggregation operators.
+// refs: https://prometheus.io/docs/prometheus/latest/querying/basics/
+
+type promValueType int
+
+const (
+	promTypeScalar promValueType = iota
+	promTypeVector
+	promTypeMatrix
+)
+
+func (t promValueType) String() string {
+	switch t {
+	case promTypeScalar:
+		return "scalar"
+	case promTypeVector:
+		return "instant vector"
+	default:
+		return "range vector"
+	}
+}
+
+// promExpr is a node of a parsed PromQL expression.
+type promExpr interface {
+	valueType() promValueType
+}
+
+type promNumber struct {
+	value float64
+}
+
+// promVectorSelector selects the latest sample of the matching series within the lookback delta.
+type promVectorSelector struct {
+	matchers []datasource.LabelMatcher
+	offset   time.Duration
+}
+
+// promMatrixSelector selects the samples of the matching series within the range.
+type promMatrixSelector struct {
+	selector      *promVectorSelector
+	rangeInterval time.Duration
+}
+
+type promCall struct {
+	function *promFunction
+	args     []promExpr
+}
+
+type promAggregation struct {
+	op *promAggregationOp
+	// param is the parameter of topk, bottomk and quantile.
+	param    promExpr
+	expr     promExpr
+	grouping []string
+	without  bool
+}
+
+type promBinary struct {
+	op         string
+	lhs, rhs   promExpr
+	returnBool bool
+	// matching is nil unless both sides are vectors.
+	matching *promVectorMatching
+}
+
+// promVectorMatching selects the labels identifying the matching samples of two vectors.
+type promVectorMatching struct {
+	on     bool
+	labels []string
+}
+
+type promNegation struct {
+	expr promExpr
+}
+
+func (*promNumber) valueType() promValueType         { return promTypeScalar }
+func (*promVectorSelector) valueType() promValueType { return promTypeVector }
+func (*promMatrixSelector) valueType() promValueType { return promTypeMatrix }
+func (c *promCall) valueType() promValueType         { return c.function.returns }
+func (*promAggregation) valueType() promValueType    { return promTypeVector }
+func (n *promNegation) valueType() promValueType     { return n.expr.valueType() }
+
+func (b *promBinary) valueType() promValueType {
+	if b.lhs.valueType() == promTypeScalar && b.rhs.valueType() == promTypeScalar {
+		return promTypeScalar
+	}
+	return promTypeVector
+}
+
+const (
+	promBool       = "bool"
+	promOn         = "on"
+	promIgnoring   = "ignoring"
+	promBy         = "by"
+	promWithout    = "without"
+	promOffset     = "offset"
+	promOr         = "or"
+	promAnd        = "and"
+	promUnless     = "unless"
+	promGroupLeft  = "group_left"
+	promGroupRight = "group_right"
+)
+
+// promPrecedences are the precedences of the binary operators, `^` is right associative.
+var promPrecedences = map[string]int{
+	promOr:  1,
+	promAnd: 2, promUnless: 2,
+	"==": 3, "!=": 3, "<": 3, "<=": 3, ">": 3, ">=": 3,
+	"+": 4, "-": 4,
+	"*": 5, "/": 5, "%": 5,
+	"^": 6,
+}
+
+func isPromComparison(op string) bool {
+	return promPrecedences[op] == 3
+}
+
+func isPromSetOperator(op string) bool {
+	return op == promOr || op == promAnd || op == promUnless
+}
+
+type promTokenKind int
+
+const (
+	promEOF promTokenKind = iota
+	promOpenBrace
+	promCloseBrace
+	promOpenParen
+	promCloseParen
+	promComma
+	promOperator
+	promIdentifier
+	promString
+	promNumberToken
+	promRange
+)
+
+type promToken struct {
+	kind  promTokenKind
+	value string
+	pos   int
+}
+
+// promOperators are ordered so the longest operator is matched first.
+var promOperators = []string{"==", "!=", "=~", "!~", ">=", "<=", "=", ">", "<", "+", "-", "*", "/", "%", "^"}
+
+func lexPromQL(input string) ([]promToken, error) {
+	var tokens []promToken
+	for i := 0; i < len(input); {
+		c := input[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+			continue
+		case c == '#':
+			// a comment runs until the end of the line
+			for i < len(input) && input[i] != '\n' {
+				i++
+			}
+			continue
+		case strings.IndexByte("{}(),", c) >= 0:
+			kind := map[byte]promTokenKind{
+				'{': promOpenBrace, '}': promCloseBrace, '(': promOpenParen, ')': promCloseParen, ',': promComma,
+			}[c]
+			tokens = append(tokens, promToken{kind: kind, value: string(c), pos: i})
+			i++
+			continue
+		case c == '[':
+			end := strings.IndexByte(input[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated range at position %d", i)
+			}
+			tokens = append(tokens, promToken{kind: promRange, value: strings.TrimSpace(input[i+1 : i+end]), pos: i})
+			i += end + 1
+			continue
+		case c == '"' || c == '\'' || c == '`':
+			end := i + 1
+			for end < len(input) && input[end] != c {
+				if input[end] == '\\' && c != '`' {
+					end++
+				}
+				end++
+			}
+			if end >= len(input) {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+			quoted := input[i : end+1]
+			if c == '\'' {
+				// Go only quotes a single character with single quotes
+				quoted = `"` + strings.ReplaceAll(strings.ReplaceAll(input[i+1:end], `\'`, `'`), `"`, `\"`) + `"`
+			}
+			value, err := strconv.Unquote(quoted)
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at position %d: %w", i, err)
+			}
+			tokens = append(tokens, promToken{kind: promString, value: value, pos: i})
+			i = end + 1
+			continue
+		case c >= '0' && c <= '9' || c == '.' && i+1 < len(input) && input[i+1] >= '0' && input[i+1] <= '9':
+			// numbers and durations such as 1.5, 1e-3, 0x1f or 1h30m
+			end := i + 1
+			for end < len(input) {
+				d := input[end]
+				if (d == '-' || d == '+') && (input[end-1] == 'e' || input[end-1] == 'E') && !strings.ContainsAny(input[i:end-1], "xXhmsdwy") {
+					end++
+					continue
+				}
+				if !(d == '.' || d >= '0' && d <= '9' || d >= 'a' && d <= 'z' || d >= 'A' && d <= 'Z') {
+					break
+				}
+				end++
+			}
+			tokens = append(tokens, promToken{kind: promNumberToken, value: input[i:end], pos: i})
+			i = end
+			continue
+		case c == '_' || c == ':' || unicode.IsLetter(rune(c)):
+			end := i + 1
+			for end < len(input) && isPromNameChar(input[end]) {
+				end++
+			}
+			tokens = append(tokens, promToken{kind: promIdentifier, value: input[i:end], pos: i})
+			i = end
+			continue
+		}
+
+		matched := false
+		for _, op := range promOperators {
+			if strings.HasPrefix(input[i:], op) {
+				tokens = append(tokens, promToken{kind: promOperator, value: op, pos: i})
+				i += len(op)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+		}
+	}
+	return append(tokens, promToken{kind: promEOF, pos: len(input)}), nil
+}
+
+func isPromNameChar(c byte) bool {
+	return c == '_' || c == ':' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// parsePromQL parses an expression, its errors are InvalidArgument.
+func parsePromQL(input string) (promExpr, error) {
+	tokens, err := lexPromQL(input)
+	if err == nil {
+		p := &promParser{tokens: tokens}
+		var expr promExpr
+		if expr, err = p.parseExpr(0); err == nil && p.peek().kind != promEOF {
+			err = p.unexpected()
+		}
+		if err == nil {
+			return expr, nil
+		}
+	}
+	return nil, status.Errorf(codes.InvalidArgument, "invalid PromQL query: %v", err)
+}
+
+// parsePromSelector parses a series selector, as the `match[]` parameters.
+func parsePromSelector(input string) ([]datasource.LabelMatcher, error) {
+	expr, err := parsePromQL(input)
+	if err != nil {
+		return nil, err
+	}
+	selector, ok := expr.(*promVectorSelector)
+	if !ok || selector.offset != 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid series selector %q", input)
+	}
+	return selector.matchers, nil
+}
+
+type promParser struct {
+	tokens []promToken
+	pos    int
+}
+
+func (p *promParser) peek() promToken {
+	return p.tokens[p.pos]
+}
+
+func (p *promParser) next() promToken {
+	t := p.tokens[p.pos]
+	if t.kind != promEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *promParser) unexpected() error {
+	t := p.peek()
+	if t.kind == promEOF {
+		return fmt.Errorf("unexpected end of query")
+	}
+	return fmt.Errorf("unexpected %q at position %d", t.value, t.pos)
+}
+
+func (p *promParser) expect(kind promTokenKind) (promToken, error) {
+	if p.peek().kind != kind {
+		return promToken{}, p.unexpected()
+	}
+	return p.next(), nil
+}
+
+func (p *promParser) isKeyword(keyword string) bool {
+	t := p.peek()
+	return t.kind == promIdentifier && strings.EqualFold(t.value, keyword)
+}
+
+// binaryOperator returns the binary operator at the current token, if any.
+func (p *promParser) binaryOperator() (string, bool) {
+	t := p.peek()
+	switch t.kind {
+	case promOperator:
+		_, ok := promPrecedences[t.value]
+		return t.value, ok
+	case promIdentifier:
+		op := strings.ToLower(t.value)
+		return op, isPromSetOperator(op)
+	}
+	return "", false
+}
+
+func (p *promParser) parseExpr(minPrecedence int) (promExpr, error) {
+	lhs, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.binaryOperator()
+		if !ok || promPrecedences[op] < minPrecedence {
+			return lhs, nil
+		}
+		opToken := p.next()
+		binary := &promBinary{op: op, lhs: lhs}
+		if p.isKeyword(promBool) {
+			p.next()
+			if !isPromComparison(op) {
+				return nil, fmt.Errorf("bool modifier at position %d can only be used on comparison operators", opToken.pos)
+			}
+			binary.returnBool = true
+		}
+		if p.isKeyword(promOn) || p.isKeyword(promIgnoring) {
+			binary.matching = &promVectorMatching{on: p.isKeyword(promOn)}
+			p.next()
+			if binary.matching.labels, err = p.parseLabels(); err != nil {
+				return nil, err
+			}
+		}
+		if p.isKeyword(promGroupLeft) || p.isKeyword(promGroupRight) {
+			return nil, fmt.Errorf("%s at position %d is not supported", p.peek().value, p.peek().pos)
+		}
+
+		next := promPrecedences[op] + 1
+		if op == "^" {
+			next = promPrecedences[op]
+		}
+		if binary.rhs, err = p.parseExpr(next); err != nil {
+			return nil, err
+		}
+		if err = checkPromBinary(binary, opToken.pos); err != nil {
+			return nil, err
+		}
+		lhs = binary
+	}
+}
+
+func checkPromBinary(b *promBinary, pos int) error {
+	lt, rt := b.lhs.valueType(), b.rhs.valueType()
+	if lt == promTypeMatrix || rt == promTypeMatrix {
+		return fmt.Errorf("binary expression at position %d must contain only scalar and instant vector types", pos)
+	}
+	if isPromSetOperator(b.op) && (lt != promTypeVector || rt != promTypeVector) {
+		return fmt.Errorf("set operator %q at position %d not allowed in binary scalar expression", b.op, pos)
+	}
+	if isPromComparison(b.op) && lt == promTypeScalar && rt == promTypeScalar && !b.returnBool {
+		return fmt.Errorf("comparisons between scalars at position %d must use the bool modifier", pos)
+	}
+	if lt == promTypeVector && rt == promTypeVector {
+		if b.matching == nil {
+			b.matching = &promVectorMatching{}
+		}
+	} else if b.matching != nil {
+		return fmt.Errorf("vector matching at position %d only allowed between instant vectors", pos)
+	}
+	return nil
+}
+
+func (p *promParser) parseUnary() (promExpr, error) {
+	if t := p.peek(); t.kind == promOperator && (t.value == "-" || t.value == "+") {
+		p.next()
+		expr, err := p.parseExpr(promPrecedences["^"])
+		if err != nil {
+			return nil, err
+		}
+		if expr.valueType() == promTypeMatrix {
+			return nil, fmt.Errorf("unary expression at position %d only allowed on scalars and instant vectors", t.pos)
+		}
+		if t.value == "+" {
+			return expr, nil
+		}
+		if n, ok := expr.(*promNumber); ok {
+			return &promNumber{value: -n.value}, nil
+		}
+		return &promNegation{expr: expr}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *promParser) parsePrimary() (promExpr, error) {
+	t := p.peek()
+	switch t.kind {
+	case promNumberToken:
+		p.next()
+		value, err := parsePromNumber(t.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", t.value, t.pos)
+		}
+		return &promNumber{value: value}, nil
+	case promOpenParen:
+		p.next()
+		expr, err := p.parseExpr(0)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = p.expect(promCloseParen); err != nil {
+			return nil, err
+		}
+		return expr, nil
+	case promOpenBrace:
+		return p.parseSelector("")
+	case promIdentifier:
+		name := t.value
+		if op, ok := promAggregations[strings.ToLower(name)]; ok {
+			p.next()
+			return p.parseAggregation(op)
+		}
+		if p.tokens[p.pos+1].kind == promOpenParen {
+			function, ok := promFunctions[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown function %q at position %d", name, t.pos)
+			}
+			p.next()
+			return p.parseCall(function, t.pos)
+		}
+		if lower := strings.ToLower(name); lower == "inf" || lower == "nan" {
+			p.next()
+			if lower == "inf" {
+				return &promNumber{value: math.Inf(1)}, nil
+			}
+			return &promNumber{value: math.NaN()}, nil
+		}
+		return p.parseSelector(name)
+	case promString:
+		return nil, fmt.Errorf("string literal at position %d is not supported", t.pos)
+	}
+	return nil, p.unexpected()
+}
+
+func (p *promParser) parseSelector(name string) (promExpr, error) {
+	pos := p.peek().pos
+	selector := &promVectorSelector{}
+	if name != "" {
+		p.next()
+		selector.matchers = append(selector.matchers, datasource.LabelMatcher{Name: datasource.MetricNameLabel, Value: name})
+	}
+	if p.peek().kind == promOpenBrace {
+		p.next()
+		for p.peek().kind != promCloseBrace {
+			label, err := p.expect(promIdentifier)
+			if err != nil {
+				return nil, err
+			}
+			op, err := p.expect(promOperator)
+			if err != nil {
+				return nil, err
+			}
+			matcher := datasource.LabelMatcher{Name: label.value}
+			switch op.value {
+			case "=":
+				matcher.Type = datasource.MatchEqual
+			case "!=":
+				matcher.Type = datasource.MatchNotEqual
+			case "=~":
+				matcher.Type = datasource.MatchRegexp
+			case "!~":
+				matcher.Type = datasource.MatchNotRegexp
+			default:
+				return nil, fmt.Errorf("unexpected %q at position %d", op.value, op.pos)
+			}
+			value, err := p.expect(promString)
+			if err != nil {
+				return nil, err
+			}
+			matcher.Value = value.value
+			selector.matchers = append(selector.matchers, matcher)
+			if p.peek().kind != promComma {
+				break
+			}
+			p.next()
+		}
+		if _, err := p.expect(promCloseBrace); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkPromMatchers(selector.matchers, pos); err != nil {
+		return nil, err
+	}
+
+	var expr promExpr = selector
+	if t := p.peek(); t.kind == promRange {
+		p.next()
+		if strings.Contains(t.value, ":") {
+			return nil, fmt.Errorf("subquery at position %d is not supported", t.pos)
+		}
+		rangeInterval, err := parsePromDuration(t.value)
+		if err != nil || rangeInterval <= 0 {
+			return nil, fmt.Errorf("invalid range %q at position %d", t.value, t.pos)
+		}
+		expr = &promMatrixSelector{selector: selector, rangeInterval: rangeInterval}
+	}
+	if p.isKeyword(promOffset) {
+		p.next()
+		t, err := p.expect(promNumberToken)
+		if err != nil {
+			return nil, err
+		}
+		if selector.offset, err = parsePromDuration(t.value); err != nil {
+			return nil, fmt.Errorf("invalid offset %q at position %d", t.value, t.pos)
+		}
+	}
+	return expr, nil
+}
+
+// checkPromMatchers rejects the invalid regular expressions and, as in Prometheus, the selectors
+// matching every series.
+func checkPromMatchers(matchers []datasource.LabelMatcher, pos int) error {
+	if _, err := datasource.NewLabelFilter(matchers); err != nil {
+		return err
+	}
+	for i := range matchers {
+		single, _ := datasource.NewLabelFilter(matchers[i : i+1])
+		if !single.Matches(map[string]string{}) {
+			return nil
+		}
+	}
+	return fmt.Errorf("vector selector at position %d must contain at least one non-empty matcher", pos)
+}
+
+func (p *promParser) parseCall(function *promFunction, pos int) (promExpr, error) {
+	if _, err := p.expect(promOpenParen); err != nil {
+		return nil, err
+	}
+	call := &promCall{function: function}
+	for p.peek().kind != promCloseParen {
+		if len(call.args) > 0 {
+			if _, err := p.expect(promComma); err != nil {
+				return nil, err
+			}
+		}
+		arg, err := p.parseExpr(0)
+		if err != nil {
+			return nil, err
+		}
+		call.args = append(call.args, arg)
+	}
+	p.next()
+
+	if len(call.args) < len(function.args)-function.optional || len(call.args) > len(function.args) {
+		return nil, fmt.Errorf("function %q at position %d expects %d arguments, got %d", function.name, pos, len(function.args), len(call.args))
+	}
+	for i, arg := range call.args {
+		if arg.valueType() != function.args[i] {
+			return nil, fmt.Errorf("argument %d of function %q at position %d must be a %s, got a %s",
+				i+1, function.name, pos, function.args[i], arg.valueType())
+		}
+	}
+	return call, nil
+}
+
+func (p *promParser) parseAggregation(op *promAggregationOp) (promExpr, error) {
+	aggregation := &promAggregation{op: op}
+	if err := p.parseGrouping(aggregation); err != nil {
+		return nil, err
+	}
+	open, err := p.expect(promOpenParen)
+	if err != nil {
+		return nil, err
+	}
+	if op.param {
+		if aggregation.param, err = p.parseExpr(0); err != nil {
+			return nil, err
+		}
+		if aggregation.param.valueType() != promTypeScalar {
+			return nil, fmt.Errorf("parameter of %s at position %d must be a scalar", op.name, open.pos)
+		}
+		if _, err = p.expect(promComma); err != nil {
+			return nil, err
+		}
+	}
+	if aggregation.expr, err = p.parseExpr(0); err != nil {
+		return nil, err
+	}
+	if aggregation.expr.valueType() != promTypeVector {
+		return nil, fmt.Errorf("%s at position %d expects an instant vector, got a %s", op.name, open.pos, aggregation.expr.valueType())
+	}
+	if _, err = p.expect(promCloseParen); err != nil {
+		return nil, err
+	}
+	if aggregation.grouping == nil {
+		if err = p.parseGrouping(aggregation); err != nil {
+			return nil, err
+		}
+	}
+	return aggregation, nil
+}
+
+func (p *promParser) parseGrouping(aggregation *promAggregation) error {
+	if !p.isKeyword(promBy) && !p.isKeyword(promWithout) {
+		return nil
+	}
+	aggregation.without = p.isKeyword(promWithout)
+	p.next()
+	labels, err := p.parseLabels()
+	if err != nil {
+		return err
+	}
+	aggregation.grouping = labels
+	return nil
+}
+
+// parseLabels parses a parenthesized list of label names, which is never nil.
+func (p *promParser) parseLabels() ([]string, error) {
+	if _, err := p.expect(promOpenParen); err != nil {
+		return nil, err
+	}
+	labels := []string{}
+	for p.peek().kind != promCloseParen {
+		t, err := p.expect(promIdentifier)
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, t.value)
+		if p.peek().kind != promComma {
+			break
+		}
+		p.next()
+	}
+	if _, err := p.expect(promCloseParen); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+func parsePromNumber(value string) (float64, error) {
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return f, nil
+	}
+	n, err := strconv.ParseInt(value, 0, 64)
+	return float64(n), err
+}
+
+// promDurationUnits are the units of a Prometheus duration such as `1h30m`.
+var promDurationUnits = map[string]time.Duration{
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  24 * time.Hour,
+	"w":  7 * 24 * time.Hour,
+	"y":  365 * 24 * time.Hour,
+}
+
+// parsePromDuration parses a duration of integers with the units of promDurationUnits.
+func parsePromDuration(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, fmt.Errorf("empty duration")
+	}
+	var d time.Duration
+	for rest := value; rest != ""; {
+		i := 0
+		for i < len(rest) && rest[i] >= '0' && rest[i] <= '9' {
+			i++
+		}
+		j := i
+		for j < len(rest) && rest[j] >= 'a' && rest[j] <= 'z' {
+			j++
+		}
+		unit, ok := promDurationUnits[rest[i:j]]
+		if i == 0 || !ok {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		n, err := strconv.ParseInt(rest[:i], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		d += time.Duration(n) * unit
+		rest = rest[j:]
+	}
+	return d, nil
+}
@@ -0,0 +1,923 @@
+package handler
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// promLookbackDelta is how far back an instant vector selector looks for the latest sample.
+	promLookbackDelta = 5 * time.Minute
+	// promBucketLabel is the label of the upper bound of a histogram bucket.
+	promBucketLabel = "le"
+)
+
+// promValue is the value of an expression at an evaluation timestamp.
+type promValue interface {
+	valueType() promValueType
+}
+
+type promScalar float64
+
+// promSample is an element of an instant vector.
+type promSample struct {
+	labels map[string]string
+	value  float64
+}
+
+type promVector []promSample
+
+// promRangeSeries is an element of a range vector.
+type promRangeSeries struct {
+	labels  map[string]string
+	samples []datasource.MetricSample
+}
+
+type promMatrix []promRangeSeries
+
+func (promScalar) valueType() promValueType { return promTypeScalar }
+func (promVector) valueType() promValueType { return promTypeVector }
+func (promMatrix) valueType() promValueType { return promTypeMatrix }
+
+// promFunction is a PromQL function, the last optional arguments may be omitted.
+type promFunction struct {
+	name     string
+	args     []promValueType
+	optional int
+	returns  promValueType
+	call     func(call *promCall, args []promValue, t time.Time) promValue
+}
+
+// promAggregationOp is a PromQL aggregation operator, param is set for the operators taking
+// a scalar parameter.
+type promAggregationOp struct {
+	name  string
+	param bool
+}
+
+var promAggregations = map[string]*promAggregationOp{
+	"sum":      {name: "sum"},
+	"avg":      {name: "avg"},
+	"min":      {name: "min"},
+	"max":      {name: "max"},
+	"count":    {name: "count"},
+	"stddev":   {name: "stddev"},
+	"stdvar":   {name: "stdvar"},
+	"topk":     {name: "topk", param: true},
+	"bottomk":  {name: "bottomk", param: true},
+	"quantile": {name: "quantile", param: true},
+}
+
+var promFunctions map[string]*promFunction
+
+func init() {
+	promFunctions = map[string]*promFunction{}
+	add := func(f *promFunction) {
+		promFunctions[f.name] = f
+	}
+
+	rangeFunctions := map[string]func(samples []datasource.MetricSample, start, end time.Time) (float64, bool){
+		"rate": func(samples []datasource.MetricSample, start, end time.Time) (float64, bool) {
+			return extrapolatedRate(samples, start, end, true, true)
+		},
+		"increase": func(samples []datasource.MetricSample, start, end time.Time) (float64, bool) {
+			return extrapolatedRate(samples, start, end, true, false)
+		},
+		"delta": func(samples []datasource.MetricSample, start, end time.Time) (float64, bool) {
+			return extrapolatedRate(samples, start, end, false, false)
+		},
+		"irate": func(samples []datasource.MetricSample, _, _ time.Time) (float64, bool) {
+			return instantRate(samples, true)
+		},
+		"idelta": func(samples []datasource.MetricSample, _, _ time.Time) (float64, bool) {
+			return instantRate(samples, false)
+		},
+		"sum_over_time": func(samples []datasource.MetricSample, _, _ time.Time) (float64, bool) {
+			var sum float64
+			for _, s := range samples {
+				sum += s.Value
+			}
+			return sum, true
+		},
+		"avg_over_time": func(samples []datasource.MetricSample, _, _ time.Time) (float64, bool) {
+			var sum float64
+			for _, s := range samples {
+				sum += s.Value
+			}
+			return sum / float64(len(samples)), true
+		},
+		"min_over_time": func(samples []datasource.MetricSample, _, _ time.Time) (float64, bool) {
+			min := samples[0].Value
+			for _, s := range samples[1:] {
+				if s.Value < min || math.IsNaN(min) {
+					min = s.Value
+				}
+			}
+			return min, true
+		},
+		"max_over_time": func(samples []datasource.MetricSample, _, _ time.Time) (float64, bool) {
+			max := samples[0].Value
+			for _, s := range samples[1:] {
+				if s.Value > max || math.IsNaN(max) {
+					max = s.Value
+				}
+			}
+			return max, true
+		},
+		"count_over_time": func(samples []datasource.MetricSample, _, _ time.Time) (float64, bool) {
+			return float64(len(samples)), true
+		},
+		"last_over_time": func(samples []datasource.MetricSample, _, _ time.Time) (float64, bool) {
+			return samples[len(samples)-1].Value, true
+		},
+	}
+	for name, fn := range rangeFunctions {
+		fn := fn
+		add(&promFunction{
+			name:    name,
+			args:    []promValueType{promTypeMatrix},
+			returns: promTypeVector,
+			call: func(call *promCall, args []promValue, t time.Time) promValue {
+				rangeInterval := call.args[0].(*promMatrixSelector).rangeInterval
+				result := promVector{}
+				for _, s := range args[0].(promMatrix) {
+					if value, ok := fn(s.samples, t.Add(-rangeInterval), t); ok {
+						result = append(result, promSample{labels: dropMetricName(s.labels), value: value})
+					}
+				}
+				return result
+			},
+		})
+	}
+
+	mathFunctions := map[string]func(float64) float64{
+		"abs":   math.Abs,
+		"ceil":  math.Ceil,
+		"floor": math.Floor,
+		"exp":   math.Exp,
+		"ln":    math.Log,
+		"log2":  math.Log2,
+		"log10": math.Log10,
+		"sqrt":  math.Sqrt,
+	}
+	for name, fn := range mathFunctions {
+		fn := fn
+		add(&promFunction{
+			name:    name,
+			args:    []promValueType{promTypeVector},
+			returns: promTypeVector,
+			call: func(_ *promCall, args []promValue, _ time.Time) promValue {
+				return mapVector(args[0].(promVector), fn)
+			},
+		})
+	}
+
+	add(&promFunction{
+		name:     "round",
+		args:     []promValueType{promTypeVector, promTypeScalar},
+		optional: 1,
+		returns:  promTypeVector,
+		call: func(_ *promCall, args []promValue, _ time.Time) promValue {
+			toNearest := 1.0
+			if len(args) > 1 {
+				toNearest = float64(args[1].(promScalar))
+			}
+			inverse := 1 / toNearest
+			return mapVector(args[0].(promVector), func(v float64) float64 {
+				return math.Floor(v*inverse+0.5) / inverse
+			})
+		},
+	})
+	add(&promFunction{
+		name:    "clamp_min",
+		args:    []promValueType{promTypeVector, promTypeScalar},
+		returns: promTypeVector,
+		call: func(_ *promCall, args []promValue, _ time.Time) promValue {
+			min := float64(args[1].(promScalar))
+			return mapVector(args[0].(promVector), func(v float64) float64 { return math.Max(v, min) })
+		},
+	})
+	add(&promFunction{
+		name:    "clamp_max",
+		args:    []promValueType{promTypeVector, promTypeScalar},
+		returns: promTypeVector,
+		call: func(_ *promCall, args []promValue, _ time.Time) promValue {
+			max := float64(args[1].(promScalar))
+			return mapVector(args[0].(promVector), func(v float64) float64 { return math.Min(v, max) })
+		},
+	})
+	add(&promFunction{
+		name:    "clamp",
+		args:    []promValueType{promTypeVector, promTypeScalar, promTypeScalar},
+		returns: promTypeVector,
+		call: func(_ *promCall, args []promValue, _ time.Time) promValue {
+			min, max := float64(args[1].(promScalar)), float64(args[2].(promScalar))
+			if max < min {
+				return promVector{}
+			}
+			return mapVector(args[0].(promVector), func(v float64) float64 { return math.Max(min, math.Min(max, v)) })
+		},
+	})
+	add(&promFunction{
+		name:    "histogram_quantile",
+		args:    []promValueType{promTypeScalar, promTypeVector},
+		returns: promTypeVector,
+		call: func(_ *promCall, args []promValue, _ time.Time) promValue {
+			return histogramQuantile(float64(args[0].(promScalar)), args[1].(promVector))
+		},
+	})
+	add(&promFunction{
+		name:    "scalar",
+		args:    []promValueType{promTypeVector},
+		returns: promTypeScalar,
+		call: func(_ *promCall, args []promValue, _ time.Time) promValue {
+			if v := args[0].(promVector); len(v) == 1 {
+				return promScalar(v[0].value)
+			}
+			return promScalar(math.NaN())
+		},
+	})
+	add(&promFunction{
+		name:    "vector",
+		args:    []promValueType{promTypeScalar},
+		returns: promTypeVector,
+		call: func(_ *promCall, args []promValue, _ time.Time) promValue {
+			return promVector{{labels: map[string]string{}, value: float64(args[0].(promScalar))}}
+		},
+	})
+	add(&promFunction{
+		name:    "time",
+		returns: promTypeScalar,
+		call: func(_ *promCall, _ []promValue, t time.Time) promValue {
+			return promScalar(float64(t.UnixNano()) / 1e9)
+		},
+	})
+}
+
+// promEvaluator evaluates an expression at the timestamps of a query. The series of the
+// selectors are read once for the whole time range, before the evaluation.
+type promEvaluator struct {
+	ctx    context.Context
+	series map[*promVectorSelector][]*datasource.MetricSeries
+}
+
+// newPromEvaluator reads the series selected by expr over [start, end].
+func newPromEvaluator(ctx context.Context, query datasource.MetricQuery, expr promExpr, start, end time.Time) (*promEvaluator, error) {
+	e := &promEvaluator{ctx: ctx, series: map[*promVectorSelector][]*datasource.MetricSeries{}}
+	var load func(expr promExpr, window time.Duration) error
+	load = func(expr promExpr, window time.Duration) error {
+		switch n := expr.(type) {
+		case *promVectorSelector:
+			if _, ok := e.series[n]; ok {
+				return nil
+			}
+			series, err := query.SelectSeries(ctx, &datasource.MetricQueryParameters{
+				Matchers:  n.matchers,
+				StartTime: start.Add(-n.offset - window),
+				EndTime:   end.Add(-n.offset),
+			})
+			if err != nil {
+				return err
+			}
+			e.series[n] = series
+		case *promMatrixSelector:
+			return load(n.selector, n.rangeInterval)
+		case *promCall:
+			for _, arg := range n.args {
+				if err := load(arg, promLookbackDelta); err != nil {
+					return err
+				}
+			}
+		case *promAggregation:
+			if n.param != nil {
+				if err := load(n.param, promLookbackDelta); err != nil {
+					return err
+				}
+			}
+			return load(n.expr, promLookbackDelta)
+		case *promBinary:
+			if err := load(n.lhs, promLookbackDelta); err != nil {
+				return err
+			}
+			return load(n.rhs, promLookbackDelta)
+		case *promNegation:
+			return load(n.expr, promLookbackDelta)
+		}
+		return nil
+	}
+	if err := load(expr, promLookbackDelta); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *promEvaluator) eval(expr promExpr, t time.Time) (promValue, error) {
+	if err := e.ctx.Err(); err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+	switch n := expr.(type) {
+	case *promNumber:
+		return promScalar(n.value), nil
+	case *promVectorSelector:
+		result := promVector{}
+		for _, s := range e.series[n] {
+			samples := samplesInRange(s.Samples, t.Add(-n.offset-promLookbackDelta), t.Add(-n.offset))
+			if len(samples) > 0 {
+				result = append(result, promSample{labels: s.Labels, value: samples[len(samples)-1].Value})
+			}
+		}
+		return result, nil
+	case *promMatrixSelector:
+		result := promMatrix{}
+		for _, s := range e.series[n.selector] {
+			samples := samplesInRange(s.Samples, t.Add(-n.selector.offset-n.rangeInterval), t.Add(-n.selector.offset))
+			if len(samples) > 0 {
+				result = append(result, promRangeSeries{labels: s.Labels, samples: samples})
+			}
+		}
+		return result, nil
+	case *promNegation:
+		value, err := e.eval(n.expr, t)
+		if err != nil {
+			return nil, err
+		}
+		if scalar, ok := value.(promScalar); ok {
+			return -scalar, nil
+		}
+		return mapVector(value.(promVector), func(v float64) float64 { return -v }), nil
+	case *promCall:
+		args := make([]promValue, len(n.args))
+		for i, arg := range n.args {
+			value, err := e.eval(arg, t)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = value
+		}
+		return n.function.call(n, args, t), nil
+	case *promAggregation:
+		return e.evalAggregation(n, t)
+	case *promBinary:
+		return e.evalBinary(n, t)
+	}
+	return nil, status.Errorf(codes.Internal, "unexpected expression %T", expr)
+}
+
+// samplesInRange returns the samples in (start, end].
+func samplesInRange(samples []datasource.MetricSample, start, end time.Time) []datasource.MetricSample {
+	from := sort.Search(len(samples), func(i int) bool { return samples[i].Timestamp.After(start) })
+	to := sort.Search(len(samples), func(i int) bool { return samples[i].Timestamp.After(end) })
+	return samples[from:to]
+}
+
+func (e *promEvaluator) evalAggregation(n *promAggregation, t time.Time) (promValue, error) {
+	value, err := e.eval(n.expr, t)
+	if err != nil {
+		return nil, err
+	}
+	var param float64
+	if n.param != nil {
+		p, err := e.eval(n.param, t)
+		if err != nil {
+			return nil, err
+		}
+		param = float64(p.(promScalar))
+	}
+
+	type group struct {
+		labels  map[string]string
+		samples []promSample
+	}
+	groups := map[string]*group{}
+	var keys []string
+	for _, s := range value.(promVector) {
+		labels := groupingLabels(s.labels, n.grouping, n.without)
+		key := labelsKey(labels)
+		g, ok := groups[key]
+		if !ok {
+			g = &group{labels: labels}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		g.samples = append(g.samples, s)
+	}
+
+	result := promVector{}
+	for _, key := range keys {
+		g := groups[key]
+		switch n.op.name {
+		case "topk", "bottomk":
+			samples := append([]promSample(nil), g.samples...)
+			sort.SliceStable(samples, func(i, j int) bool {
+				if n.op.name == "topk" {
+					return samples[i].value > samples[j].value || math.IsNaN(samples[j].value) && !math.IsNaN(samples[i].value)
+				}
+				return samples[i].value < samples[j].value || math.IsNaN(samples[j].value) && !math.IsNaN(samples[i].value)
+			})
+			if k := int(param); k < len(samples) {
+				if k < 0 {
+					k = 0
+				}
+				samples = samples[:k]
+			}
+			result = append(result, samples...)
+		default:
+			result = append(result, promSample{labels: g.labels, value: aggregate(n.op.name, g.samples, param)})
+		}
+	}
+	return result, nil
+}
+
+// groupingLabels returns the labels of the group of a sample.
+func groupingLabels(labels map[string]string, grouping []string, without bool) map[string]string {
+	grouped := map[string]string{}
+	if without {
+		excluded := map[string]struct{}{datasource.MetricNameLabel: {}}
+		for _, name := range grouping {
+			excluded[name] = struct{}{}
+		}
+		for k, v := range labels {
+			if _, ok := excluded[k]; !ok {
+				grouped[k] = v
+			}
+		}
+		return grouped
+	}
+	for _, name := range grouping {
+		if v, ok := labels[name]; ok {
+			grouped[name] = v
+		}
+	}
+	return grouped
+}
+
+func aggregate(op string, samples []promSample, param float64) float64 {
+	switch op {
+	case "sum", "avg":
+		var sum float64
+		for _, s := range samples {
+			sum += s.value
+		}
+		if op == "avg" {
+			return sum / float64(len(samples))
+		}
+		return sum
+	case "min", "max":
+		result := samples[0].value
+		for _, s := range samples[1:] {
+			if math.IsNaN(result) || op == "min" && s.value < result || op == "max" && s.value > result {
+				result = s.value
+			}
+		}
+		return result
+	case "count":
+		return float64(len(samples))
+	case "stddev", "stdvar":
+		var mean, m2 float64
+		for i, s := range samples {
+			delta := s.value - mean
+			mean += delta / float64(i+1)
+			m2 += delta * (s.value - mean)
+		}
+		variance := m2 / float64(len(samples))
+		if op == "stddev" {
+			return math.Sqrt(variance)
+		}
+		return variance
+	case "quantile":
+		values := make([]float64, len(samples))
+		for i, s := range samples {
+			values[i] = s.value
+		}
+		return quantile(param, values)
+	}
+	return math.NaN()
+}
+
+// quantile interpolates the φ-quantile of values, as the quantile aggregation of Prometheus.
+func quantile(q float64, values []float64) float64 {
+	if len(values) == 0 || math.IsNaN(q) {
+		return math.NaN()
+	}
+	if q < 0 {
+		return math.Inf(-1)
+	}
+	if q > 1 {
+		return math.Inf(1)
+	}
+	sort.Float64s(values)
+	rank := q * float64(len(values)-1)
+	lower := math.Max(0, math.Floor(rank))
+	upper := math.Min(float64(len(values)-1), lower+1)
+	weight := rank - math.Floor(rank)
+	return values[int(lower)]*(1-weight) + values[int(upper)]*weight
+}
+
+func (e *promEvaluator) evalBinary(n *promBinary, t time.Time) (promValue, error) {
+	lhs, err := e.eval(n.lhs, t)
+	if err != nil {
+		return nil, err
+	}
+	rhs, err := e.eval(n.rhs, t)
+	if err != nil {
+		return nil, err
+	}
+
+	switch l := lhs.(type) {
+	case promScalar:
+		if r, ok := rhs.(promScalar); ok {
+			value, _ := binaryOperation(n.op, float64(l), float64(r), true)
+			return promScalar(value), nil
+		}
+		return vectorScalarOperation(n, rhs.(promVector), float64(l), true), nil
+	case promVector:
+		if r, ok := rhs.(promScalar); ok {
+			return vectorScalarOperation(n, l, float64(r), false), nil
+		}
+		return vectorOperation(n, l, rhs.(promVector))
+	}
+	return nil, status.Errorf(codes.Internal, "unexpected binary operand %T", lhs)
+}
+
+// binaryOperation applies an arithmetic or comparison operator, a comparison returns whether it
+// holds, and 1 or 0 when returnBool is set.
+func binaryOperation(op string, lhs, rhs float64, returnBool bool) (float64, bool) {
+	var holds bool
+	switch op {
+	case "+":
+		return lhs + rhs, true
+	case "-":
+		return lhs - rhs, true
+	case "*":
+		return lhs * rhs, true
+	case "/":
+		return lhs / rhs, true
+	case "%":
+		return math.Mod(lhs, rhs), true
+	case "^":
+		return math.Pow(lhs, rhs), true
+	case "==":
+		holds = lhs == rhs
+	case "!=":
+		holds = lhs != rhs
+	case ">":
+		holds = lhs > rhs
+	case "<":
+		holds = lhs < rhs
+	case ">=":
+		holds = lhs >= rhs
+	case "<=":
+		holds = lhs <= rhs
+	}
+	if returnBool {
+		if holds {
+			return 1, true
+		}
+		return 0, true
+	}
+	return lhs, holds
+}
+
+func vectorScalarOperation(n *promBinary, vector promVector, scalar float64, scalarLeft bool) promVector {
+	result := promVector{}
+	for _, s := range vector {
+		lhs, rhs := s.value, scalar
+		if scalarLeft {
+			lhs, rhs = scalar, s.value
+		}
+		value, ok := binaryOperation(n.op, lhs, rhs, n.returnBool)
+		if !ok {
+			continue
+		}
+		labels := s.labels
+		if isPromComparison(n.op) && !n.returnBool {
+			// a filtering comparison keeps the sample value
+			value = s.value
+		} else {
+			labels = dropMetricName(labels)
+		}
+		result = append(result, promSample{labels: labels, value: value})
+	}
+	return result
+}
+
+func vectorOperation(n *promBinary, lhs, rhs promVector) (promVector, error) {
+	signature := func(labels map[string]string) string {
+		return labelsKey(matchingLabels(labels, n.matching))
+	}
+	result := promVector{}
+	switch n.op {
+	case promAnd, promUnless:
+		rhsSignatures := map[string]struct{}{}
+		for _, s := range rhs {
+			rhsSignatures[signature(s.labels)] = struct{}{}
+		}
+		for _, s := range lhs {
+			if _, ok := rhsSignatures[signature(s.labels)]; ok == (n.op == promAnd) {
+				result = append(result, s)
+			}
+		}
+		return result, nil
+	case promOr:
+		lhsSignatures := map[string]struct{}{}
+		for _, s := range lhs {
+			lhsSignatures[signature(s.labels)] = struct{}{}
+			result = append(result, s)
+		}
+		for _, s := range rhs {
+			if _, ok := lhsSignatures[signature(s.labels)]; !ok {
+				result = append(result, s)
+			}
+		}
+		return result, nil
+	}
+
+	rhsBySignature := map[string]promSample{}
+	for _, s := range rhs {
+		sig := signature(s.labels)
+		if _, ok := rhsBySignature[sig]; ok {
+			return nil, status.Errorf(codes.InvalidArgument,
+				"found duplicate series for the match group %s on the right hand-side of the operation, many-to-many matching is not supported", sig)
+		}
+		rhsBySignature[sig] = s
+	}
+	seen := map[string]struct{}{}
+	for _, l := range lhs {
+		sig := signature(l.labels)
+		r, ok := rhsBySignature[sig]
+		if !ok {
+			continue
+		}
+		value, ok := binaryOperation(n.op, l.value, r.value, n.returnBool)
+		if !ok {
+			continue
+		}
+		labels := l.labels
+		if !isPromComparison(n.op) || n.returnBool {
+			labels = dropMetricName(labels)
+		}
+		if n.matching.on {
+			labels = matchingLabels(labels, n.matching)
+		} else {
+			labels = withoutLabels(labels, n.matching.labels)
+		}
+		if _, ok := seen[sig]; ok {
+			return nil, status.Errorf(codes.InvalidArgument,
+				"found duplicate series for the match group %s on the left hand-side of the operation, many-to-many matching is not supported", sig)
+		}
+		seen[sig] = struct{}{}
+		result = append(result, promSample{labels: labels, value: value})
+	}
+	return result, nil
+}
+
+// matchingLabels returns the labels identifying a sample when matching two vectors.
+func matchingLabels(labels map[string]string, matching *promVectorMatching) map[string]string {
+	if matching.on {
+		matched := map[string]string{}
+		for _, name := range matching.labels {
+			if v, ok := labels[name]; ok {
+				matched[name] = v
+			}
+		}
+		return matched
+	}
+	return withoutLabels(dropMetricName(labels), matching.labels)
+}
+
+func withoutLabels(labels map[string]string, names []string) map[string]string {
+	if len(names) == 0 {
+		return labels
+	}
+	result := make(map[string]string, len(labels))
+	for k, v := range labels {
+		result[k] = v
+	}
+	for _, name := range names {
+		delete(result, name)
+	}
+	return result
+}
+
+func dropMetricName(labels map[string]string) map[string]string {
+	if _, ok := labels[datasource.MetricNameLabel]; !ok {
+		return labels
+	}
+	return withoutLabels(labels, []string{datasource.MetricNameLabel})
+}
+
+func mapVector(vector promVector, fn func(float64) float64) promVector {
+	result := make(promVector, 0, len(vector))
+	for _, s := range vector {
+		result = append(result, promSample{labels: dropMetricName(s.labels), value: fn(s.value)})
+	}
+	return result
+}
+
+// extrapolatedRate computes rate, increase and delta as Prometheus does: the counter resets are
+// compensated, and the increase is extrapolated to the boundaries of the range unless the first
+// or last sample is further than 1.1 times the average interval between the samples.
+func extrapolatedRate(samples []datasource.MetricSample, start, end time.Time, isCounter, isRate bool) (float64, bool) {
+	if len(samples) < 2 {
+		return 0, false
+	}
+	first, last := samples[0], samples[len(samples)-1]
+	result := last.Value - first.Value
+	if isCounter {
+		for i := 1; i < len(samples); i++ {
+			if samples[i].Value < samples[i-1].Value {
+				result += samples[i-1].Value
+			}
+		}
+	}
+
+	durationToStart := first.Timestamp.Sub(start).Seconds()
+	durationToEnd := end.Sub(last.Timestamp).Seconds()
+	sampledInterval := last.Timestamp.Sub(first.Timestamp).Seconds()
+	averageInterval := sampledInterval / float64(len(samples)-1)
+	if isCounter && result > 0 && first.Value >= 0 {
+		// a counter can't be extrapolated below zero
+		if durationToZero := sampledInterval * (first.Value / result); durationToZero < durationToStart {
+			durationToStart = durationToZero
+		}
+	}
+
+	threshold := averageInterval * 1.1
+	extrapolatedInterval := sampledInterval
+	if durationToStart < threshold {
+		extrapolatedInterval += durationToStart
+	} else {
+		extrapolatedInterval += averageInterval / 2
+	}
+	if durationToEnd < threshold {
+		extrapolatedInterval += durationToEnd
+	} else {
+		extrapolatedInterval += averageInterval / 2
+	}
+	result *= extrapolatedInterval / sampledInterval
+	if isRate {
+		result /= end.Sub(start).Seconds()
+	}
+	return result, true
+}
+
+// instantRate computes irate and idelta from the last two samples.
+func instantRate(samples []datasource.MetricSample, isRate bool) (float64, bool) {
+	if len(samples) < 2 {
+		return 0, false
+	}
+	last, previous := samples[len(samples)-1], samples[len(samples)-2]
+	result := last.Value - previous.Value
+	if !isRate {
+		return result, true
+	}
+	if last.Value < previous.Value {
+		// counter reset
+		result = last.Value
+	}
+	interval := last.Timestamp.Sub(previous.Timestamp).Seconds()
+	if interval == 0 {
+		return 0, false
+	}
+	return result / interval, true
+}
+
+// histogramQuantile computes the φ-quantile of the `le` buckets of every histogram of vector,
+// with the linear interpolation of Prometheus.
+func histogramQuantile(q float64, vector promVector) promVector {
+	type bucket struct {
+		upperBound float64
+		count      float64
+	}
+	type histogram struct {
+		labels  map[string]string
+		buckets []bucket
+	}
+	histograms := map[string]*histogram{}
+	var keys []string
+	for _, s := range vector {
+		le, ok := s.labels[promBucketLabel]
+		if !ok {
+			continue
+		}
+		upperBound, err := strconv.ParseFloat(le, 64)
+		if err != nil {
+			continue
+		}
+		labels := withoutLabels(dropMetricName(s.labels), []string{promBucketLabel})
+		key := labelsKey(labels)
+		h, ok := histograms[key]
+		if !ok {
+			h = &histogram{labels: labels}
+			histograms[key] = h
+			keys = append(keys, key)
+		}
+		h.buckets = append(h.buckets, bucket{upperBound: upperBound, count: s.value})
+	}
+
+	result := promVector{}
+	for _, key := range keys {
+		h := histograms[key]
+		buckets := h.buckets
+		sort.Slice(buckets, func(i, j int) bool { return buckets[i].upperBound < buckets[j].upperBound })
+
+		var value float64
+		switch {
+		case q < 0:
+			value = math.Inf(-1)
+		case q > 1:
+			value = math.Inf(1)
+		case len(buckets) < 2 || !math.IsInf(buckets[len(buckets)-1].upperBound, 1):
+			value = math.NaN()
+		default:
+			// the counts of buckets scraped at slightly different times may not be monotonic
+			for i := 1; i < len(buckets); i++ {
+				if buckets[i].count < buckets[i-1].count {
+					buckets[i].count = buckets[i-1].count
+				}
+			}
+			value = bucketQuantile(q, buckets[len(buckets)-1].count, func(i int) (float64, float64) {
+				return buckets[i].upperBound, buckets[i].count
+			}, len(buckets))
+		}
+		result = append(result, promSample{labels: h.labels, value: value})
+	}
+	return result
+}
+
+func bucketQuantile(q, observations float64, bucketAt func(i int) (upperBound, count float64), n int) float64 {
+	if observations == 0 || math.IsNaN(q) {
+		return math.NaN()
+	}
+	rank := q * observations
+	b := sort.Search(n-1, func(i int) bool {
+		_, count := bucketAt(i)
+		return count >= rank
+	})
+	if b == n-1 {
+		upperBound, _ := bucketAt(n - 2)
+		return upperBound
+	}
+	upperBound, count := bucketAt(b)
+	if b == 0 && upperBound <= 0 {
+		return upperBound
+	}
+	var lowerBound float64
+	if b > 0 {
+		previousBound, previousCount := bucketAt(b - 1)
+		lowerBound = previousBound
+		count -= previousCount
+		rank -= previousCount
+	}
+	return lowerBound + (upperBound-lowerBound)*(rank/count)
+}
+
+// promPointsLimit is the maximum number of points of a range query, as in Prometheus.
+const promPointsLimit = 11000
+
+// evalRange evaluates expr at every step of [start, end], the scalar results are a series
+// without labels.
+func (e *promEvaluator) evalRange(expr promExpr, start, end time.Time, step time.Duration) ([]*promResultSeries, error) {
+	series := map[string]*promResultSeries{}
+	var keys []string
+	for t := start; !t.After(end); t = t.Add(step) {
+		value, err := e.eval(expr, t)
+		if err != nil {
+			return nil, err
+		}
+		var vector promVector
+		switch v := value.(type) {
+		case promScalar:
+			vector = promVector{{labels: map[string]string{}, value: float64(v)}}
+		case promVector:
+			vector = v
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "invalid expression type %q for range query, must be scalar or instant vector", value.valueType())
+		}
+		for _, s := range vector {
+			key := labelsKey(s.labels)
+			rs, ok := series[key]
+			if !ok {
+				rs = &promResultSeries{Metric: s.labels}
+				series[key] = rs
+				keys = append(keys, key)
+			} else if n := len(rs.Values); n > 0 && rs.Values[n-1].Timestamp.Equal(t) {
+				return nil, status.Errorf(codes.InvalidArgument, "vector cannot contain metrics with the same labelset %s", key)
+			}
+			rs.Values = append(rs.Values, promPoint{Timestamp: t, Value: s.value})
+		}
+	}
+	sort.Strings(keys)
+	result := make([]*promResultSeries, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, series[key])
+	}
+	return result, nil
+}
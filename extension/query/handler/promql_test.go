@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestParsePromQLSelector(t *testing.T) {
+	expr, err := parsePromQL(`http_requests_total{job="api", code=~"5..", instance!="a", path!~'/health.*'} offset 5m`)
+	require.NoError(t, err)
+	assert.Equal(t, &promVectorSelector{
+		matchers: []datasource.LabelMatcher{
+			{Name: datasource.MetricNameLabel, Type: datasource.MatchEqual, Value: "http_requests_total"},
+			{Name: "job", Type: datasource.MatchEqual, Value: "api"},
+			{Name: "code", Type: datasource.MatchRegexp, Value: "5.."},
+			{Name: "instance", Type: datasource.MatchNotEqual, Value: "a"},
+			{Name: "path", Type: datasource.MatchNotRegexp, Value: "/health.*"},
+		},
+		offset: 5 * time.Minute,
+	}, expr)
+
+	expr, err = parsePromQL(`rate({__name__="up"}[1h30m])`)
+	require.NoError(t, err)
+	call := expr.(*promCall)
+	assert.Equal(t, "rate", call.function.name)
+	assert.Equal(t, 90*time.Minute, call.args[0].(*promMatrixSelector).rangeInterval)
+}
+
+func TestParsePromQLPrecedence(t *testing.T) {
+	_, err := parsePromQL(`1 + 2 * 3 > bool 4 or up`)
+	require.Error(t, err, "or needs vector operands")
+
+	expr, err := parsePromQL(`a + b * c unless d`)
+	require.NoError(t, err)
+	unless := expr.(*promBinary)
+	assert.Equal(t, "unless", unless.op)
+	plus := unless.lhs.(*promBinary)
+	assert.Equal(t, "+", plus.op)
+	assert.Equal(t, "*", plus.rhs.(*promBinary).op)
+
+	expr, err = parsePromQL(`2 ^ 3 ^ 2`)
+	require.NoError(t, err)
+	pow := expr.(*promBinary)
+	assert.IsType(t, &promNumber{}, pow.lhs)
+	assert.Equal(t, "^", pow.rhs.(*promBinary).op)
+
+	expr, err = parsePromQL(`-2 ^ 2`)
+	require.NoError(t, err)
+	assert.IsType(t, &promNegation{}, expr)
+}
+
+func TestParsePromQLAggregation(t *testing.T) {
+	for _, query := range []string{
+		`sum by (job, instance) (rate(requests_total[5m]))`,
+		`sum(rate(requests_total[5m])) by (job, instance)`,
+	} {
+		expr, err := parsePromQL(query)
+		require.NoError(t, err, query)
+		aggregation := expr.(*promAggregation)
+		assert.Equal(t, "sum", aggregation.op.name)
+		assert.Equal(t, []string{"job", "instance"}, aggregation.grouping)
+		assert.False(t, aggregation.without)
+	}
+
+	expr, err := parsePromQL(`topk without (instance) (3, up)`)
+	require.NoError(t, err)
+	aggregation := expr.(*promAggregation)
+	assert.True(t, aggregation.without)
+	assert.Equal(t, &promNumber{value: 3}, aggregation.param)
+
+	expr, err = parsePromQL(`a / on (job) b`)
+	require.NoError(t, err)
+	assert.Equal(t, &promVectorMatching{on: true, labels: []string{"job"}}, expr.(*promBinary).matching)
+}
+
+func TestParsePromQLErrors(t *testing.T) {
+	for _, query := range []string{
+		``,
+		`{}`,
+		`{job=""}`,
+		`up{job="a"`,
+		`up[5m] + 1`,
+		`1 > 2`,
+		`1 and up`,
+		`rate(up)`,
+		`rate(up[5m], 1)`,
+		`unknown(up)`,
+		`topk(up)`,
+		`up{job=~"("}`,
+		`up[5x]`,
+		`"string"`,
+		`a * on(job) group_left b`,
+		`sum(up) by`,
+	} {
+		_, err := parsePromQL(query)
+		require.Error(t, err, query)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), query)
+	}
+}
+
+func TestParsePromSelector(t *testing.T) {
+	matchers, err := parsePromSelector(`{job="api"}`)
+	require.NoError(t, err)
+	assert.Equal(t, []datasource.LabelMatcher{{Name: "job", Type: datasource.MatchEqual, Value: "api"}}, matchers)
+
+	_, err = parsePromSelector(`rate(up[5m])`)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestParsePromDuration(t *testing.T) {
+	d, err := parsePromDuration("1d2h30m15s100ms")
+	require.NoError(t, err)
+	assert.Equal(t, 26*time.Hour+30*time.Minute+15*time.Second+100*time.Millisecond, d)
+
+	d, err = parsePromDuration("1w")
+	require.NoError(t, err)
+	assert.Equal(t, 7*24*time.Hour, d)
+
+	_, err = parsePromDuration("5")
+	assert.Error(t, err)
+}
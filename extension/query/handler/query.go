@@ -1,9 +1,88 @@
 package handler
 
-import "github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+import (
+	"context"
+	"sync"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	v1 "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// QueryService holds the datasources of the handlers. The fields are set before the service is shared,
+// afterwards they are replaced with Update and read with the accessors, as the datasources are created in
+// the background while the handlers serve requests.
 type QueryService struct {
+	mu sync.RWMutex
+
 	//ES client
 	// vm client
 	TracingQuerySvc datasource.Query
+	LoggingQuerySvc datasource.LogQuery
+	MetricsQuerySvc datasource.MetricQuery
+	// TraceArchiveSvc is nil when the tracing datasource has no archive configured.
+	TraceArchiveSvc datasource.TraceArchive
+	// DependencySvc is nil when the tracing datasource doesn't store the dependency links.
+	DependencySvc datasource.DependencyReader
+	// SavedQuerySvc is nil when no saved queries storage is configured.
+	SavedQuerySvc datasource.SavedQueryStore
+}
+
+// Update replaces the datasources under the lock of the accessors.
+func (s *QueryService) Update(update func(s *QueryService)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	update(s)
+}
+
+// Tracing returns the tracing datasource, nil until it is created.
+func (s *QueryService) Tracing() datasource.Query {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.TracingQuerySvc
+}
+
+// Logging returns the logging datasource, nil until it is created.
+func (s *QueryService) Logging() datasource.LogQuery {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.LoggingQuerySvc
+}
+
+// Metrics returns the metrics datasource, nil until it is created.
+func (s *QueryService) Metrics() datasource.MetricQuery {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.MetricsQuerySvc
+}
+
+// TraceArchive returns the trace archive, nil when it is not configured or not created yet.
+func (s *QueryService) TraceArchive() datasource.TraceArchive {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.TraceArchiveSvc
+}
+
+// Dependencies returns the dependency reader, nil when the links are not stored or not created yet.
+func (s *QueryService) Dependencies() datasource.DependencyReader {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.DependencySvc
+}
+
+// SavedQueries returns the saved queries store, nil when it is not configured or not created yet.
+func (s *QueryService) SavedQueries() datasource.SavedQueryStore {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.SavedQuerySvc
+}
+
+// GetTrace returns the spans of a trace from the tracing datasource, or from the archive when the
+// datasource doesn't have the trace anymore.
+func (s *QueryService) GetTrace(ctx context.Context, traceID string) (*v1.TracesData, error) {
+	trace, err := s.Tracing().GetTrace(ctx, traceID)
+	archive := s.TraceArchive()
+	if err != nil || len(trace.GetResourceSpans()) > 0 || archive == nil {
+		return trace, err
+	}
+	return archive.GetArchivedTrace(ctx, traceID)
 }
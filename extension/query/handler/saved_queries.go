@@ -0,0 +1,297 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/api/v1alpha1"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	v1_common "go.opentelemetry.io/proto/otlp/common/v1"
+	v1_logs "go.opentelemetry.io/proto/otlp/logs/v1"
+	v1_resource "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// savedQueryIDAlphabet and savedQueryIDLength make short IDs, fitting in chat links.
+	savedQueryIDAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	savedQueryIDLength   = 8
+	// savedQueryIDAttempts is the number of IDs tried when creating a saved query, in case the
+	// random IDs collide with existing ones.
+	savedQueryIDAttempts = 3
+)
+
+// newSavedQueryID returns a random base62 ID.
+func newSavedQueryID() (string, error) {
+	id := make([]byte, savedQueryIDLength)
+	max := big.NewInt(int64(len(savedQueryIDAlphabet)))
+	for i := range id {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		id[i] = savedQueryIDAlphabet[n.Int64()]
+	}
+	return string(id), nil
+}
+
+// CreateSavedQuery validates and stores a saved query under a new ID.
+func (t *Handler) CreateSavedQuery(ctx context.Context, request *v1alpha1.SavedQuery) (*v1alpha1.SavedQuery, error) {
+	store, err := t.savedQueryStore()
+	if err != nil {
+		return nil, err
+	}
+	query := proto.Clone(request).(*v1alpha1.SavedQuery)
+	if err = t.Limits.checkSavedQuery(query); err != nil {
+		return nil, err
+	}
+	now := timestamppb.Now()
+	query.CreateTime, query.UpdateTime = now, now
+	for i := 0; i < savedQueryIDAttempts; i++ {
+		if query.Id, err = newSavedQueryID(); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		if err = store.CreateSavedQuery(ctx, query); !errors.Is(err, datasource.ErrSavedQueryExists) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, savedQueryError(err)
+	}
+	return query, nil
+}
+
+// GetSavedQuery returns a saved query.
+func (t *Handler) GetSavedQuery(ctx context.Context, request *v1alpha1.GetSavedQueryRequest) (*v1alpha1.SavedQuery, error) {
+	store, err := t.savedQueryStore()
+	if err != nil {
+		return nil, err
+	}
+	query, err := store.GetSavedQuery(ctx, request.Id)
+	if err != nil {
+		return nil, savedQueryError(err)
+	}
+	return query, nil
+}
+
+// ListSavedQueries returns the saved queries of an owner, with a tag or both.
+func (t *Handler) ListSavedQueries(ctx context.Context, request *v1alpha1.ListSavedQueriesRequest) (*v1alpha1.ListSavedQueriesResponse, error) {
+	store, err := t.savedQueryStore()
+	if err != nil {
+		return nil, err
+	}
+	queries, err := store.ListSavedQueries(ctx, &datasource.SavedQueryFilter{Owner: request.Owner, Tag: request.Tag})
+	if err != nil {
+		return nil, savedQueryError(err)
+	}
+	return &v1alpha1.ListSavedQueriesResponse{SavedQueries: queries}, nil
+}
+
+// UpdateSavedQuery replaces a saved query, its ID and creation time are kept.
+func (t *Handler) UpdateSavedQuery(ctx context.Context, request *v1alpha1.SavedQuery) (*v1alpha1.SavedQuery, error) {
+	store, err := t.savedQueryStore()
+	if err != nil {
+		return nil, err
+	}
+	existing, err := store.GetSavedQuery(ctx, request.Id)
+	if err != nil {
+		return nil, savedQueryError(err)
+	}
+	query := proto.Clone(request).(*v1alpha1.SavedQuery)
+	if err = t.Limits.checkSavedQuery(query); err != nil {
+		return nil, err
+	}
+	query.CreateTime, query.UpdateTime = existing.CreateTime, timestamppb.Now()
+	if err = store.UpdateSavedQuery(ctx, query); err != nil {
+		return nil, savedQueryError(err)
+	}
+	return query, nil
+}
+
+// DeleteSavedQuery deletes a saved query.
+func (t *Handler) DeleteSavedQuery(ctx context.Context, request *v1alpha1.DeleteSavedQueryRequest) (*v1alpha1.DeleteSavedQueryResponse, error) {
+	store, err := t.savedQueryStore()
+	if err != nil {
+		return nil, err
+	}
+	if err = store.DeleteSavedQuery(ctx, request.Id); err != nil {
+		return nil, savedQueryError(err)
+	}
+	return &v1alpha1.DeleteSavedQueryResponse{}, nil
+}
+
+// RunSavedQuery runs a saved query over its lookback, ending at the requested end time or now.
+func (t *Handler) RunSavedQuery(ctx context.Context, request *v1alpha1.RunSavedQueryRequest) (*v1alpha1.RunSavedQueryResponse, error) {
+	store, err := t.savedQueryStore()
+	if err != nil {
+		return nil, err
+	}
+	query, err := store.GetSavedQuery(ctx, request.Id)
+	if err != nil {
+		return nil, savedQueryError(err)
+	}
+	now := time.Now()
+	end := now
+	if request.EndTime != nil {
+		end = request.EndTime.AsTime()
+	}
+	start := end.Add(-query.Lookback.AsDuration())
+	response := &v1alpha1.RunSavedQueryResponse{SavedQuery: query}
+
+	switch q := query.Query.(type) {
+	case *v1alpha1.SavedQuery_TraceQuery:
+		params, err := parseTraceQueryParameters(&v1alpha1.FindTracesRequest{Query: q.TraceQuery})
+		if err != nil {
+			return nil, err
+		}
+		params.StartTime, params.EndTime = start, end
+		if err = t.Limits.applyTraceQuery(params, now); err != nil {
+			return nil, err
+		}
+		tracing := t.QueryService.Tracing()
+		if tracing == nil {
+			return nil, status.Error(codes.Unavailable, "the tracing datasource is not available")
+		}
+		ctx = datasource.ContextWithSpanLimit(ctx, t.Limits.MaxSpansPerTrace)
+		if response.Traces, err = tracing.SearchTraces(ctx, params); err != nil {
+			return nil, spanLimitError(err)
+		}
+		if err = t.Limits.checkTracesSpans(response.Traces); err != nil {
+			return nil, err
+		}
+	case *v1alpha1.SavedQuery_LogQuery:
+		logql, err := parseLogQL(q.LogQuery.Logql)
+		if err != nil {
+			return nil, err
+		}
+		params := &datasource.LogQueryParameters{
+			LineFilters: logql.lineFilters,
+			StartTime:   start,
+			EndTime:     end,
+			Limit:       int(q.LogQuery.Limit),
+			Forward:     q.LogQuery.Forward,
+		}
+		if params.Limit == 0 {
+			params.Limit = lokiDefaultLimit
+		}
+		if err = t.Limits.applyTimeRange(&params.StartTime, &params.EndTime, now); err != nil {
+			return nil, err
+		}
+		if t.QueryService.Logging() == nil {
+			return nil, status.Error(codes.Unavailable, "the logging datasource is not available")
+		}
+		loki := &LokiHandler{QueryService: t.QueryService}
+		if params.Matchers, err = loki.resolveMatchers(ctx, logql.matchers, params.StartTime, params.EndTime); err != nil {
+			return nil, err
+		}
+		entries, err := t.QueryService.Logging().FindLogs(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		response.Logs = toLogsData(entries)
+	default:
+		return nil, status.Errorf(codes.FailedPrecondition, "saved query %s has no query", query.Id)
+	}
+	response.StartTime, response.EndTime = timestamppb.New(start), timestamppb.New(end)
+	return response, nil
+}
+
+func (t *Handler) savedQueryStore() (datasource.SavedQueryStore, error) {
+	store := t.QueryService.SavedQueries()
+	if store == nil {
+		return nil, status.Error(codes.FailedPrecondition, "no saved queries storage is configured")
+	}
+	return store, nil
+}
+
+// savedQueryError maps the errors of the saved query stores to gRPC codes.
+func savedQueryError(err error) error {
+	switch {
+	case errors.Is(err, datasource.ErrSavedQueryNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, datasource.ErrSavedQueryExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	}
+	return err
+}
+
+// checkSavedQuery rejects the invalid saved queries, an omitted lookback is the default range.
+func (l *Limits) checkSavedQuery(query *v1alpha1.SavedQuery) error {
+	if query.Name == "" {
+		return status.Error(codes.InvalidArgument, "name is required")
+	}
+	if query.Lookback == nil && l.DefaultRange > 0 {
+		query.Lookback = durationpb.New(l.DefaultRange)
+	}
+	if query.Lookback == nil || query.Lookback.AsDuration() <= 0 {
+		return status.Error(codes.InvalidArgument, "lookback must be positive")
+	}
+	if l.MaxRange > 0 && query.Lookback.AsDuration() > l.MaxRange {
+		return status.Errorf(codes.InvalidArgument, "lookback %s exceeds the maximum of %s", query.Lookback.AsDuration(), l.MaxRange)
+	}
+
+	switch q := query.Query.(type) {
+	case *v1alpha1.SavedQuery_TraceQuery:
+		if q.TraceQuery.StartTime != nil || q.TraceQuery.EndTime != nil {
+			return status.Error(codes.InvalidArgument, "saved queries have a lookback instead of start_time and end_time")
+		}
+		if q.TraceQuery.NumTraces < 0 || (l.MaxNumTraces > 0 && int(q.TraceQuery.NumTraces) > l.MaxNumTraces) {
+			return status.Errorf(codes.InvalidArgument, "invalid num_traces %d", q.TraceQuery.NumTraces)
+		}
+	case *v1alpha1.SavedQuery_LogQuery:
+		logql, err := parseLogQL(q.LogQuery.Logql)
+		if err != nil {
+			return err
+		}
+		if logql.metric != nil {
+			return status.Error(codes.InvalidArgument, "saved log queries must be LogQL log queries, not metric queries")
+		}
+		if q.LogQuery.Limit < 0 || q.LogQuery.Limit > lokiMaxLimit {
+			return status.Errorf(codes.InvalidArgument, "limit %d must be between 0 and %d", q.LogQuery.Limit, lokiMaxLimit)
+		}
+	default:
+		return status.Error(codes.InvalidArgument, "trace_query or log_query is required")
+	}
+	return nil
+}
+
+// toLogsData groups the log records by stream, the labels of a stream being the attributes of its
+// resource.
+func toLogsData(entries []*datasource.LogEntry) *v1_logs.LogsData {
+	ld := &v1_logs.LogsData{}
+	byKey := map[string]*v1_logs.ScopeLogs{}
+	for _, entry := range entries {
+		key := labelsKey(entry.Labels)
+		scopeLogs, ok := byKey[key]
+		if !ok {
+			names := make([]string, 0, len(entry.Labels))
+			for name := range entry.Labels {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			resource := &v1_resource.Resource{}
+			for _, name := range names {
+				resource.Attributes = append(resource.Attributes, &v1_common.KeyValue{
+					Key:   name,
+					Value: &v1_common.AnyValue{Value: &v1_common.AnyValue_StringValue{StringValue: entry.Labels[name]}},
+				})
+			}
+			scopeLogs = &v1_logs.ScopeLogs{}
+			ld.ResourceLogs = append(ld.ResourceLogs, &v1_logs.ResourceLogs{Resource: resource, ScopeLogs: []*v1_logs.ScopeLogs{scopeLogs}})
+			byKey[key] = scopeLogs
+		}
+		scopeLogs.LogRecords = append(scopeLogs.LogRecords, &v1_logs.LogRecord{
+			TimeUnixNano: uint64(entry.Timestamp.UnixNano()),
+			Body:         &v1_common.AnyValue{Value: &v1_common.AnyValue_StringValue{StringValue: entry.Line}},
+		})
+	}
+	return ld
+}
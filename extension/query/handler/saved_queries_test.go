@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/api/v1alpha1"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource/file"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func newSavedQueriesHandler(t *testing.T, query *mockQuery, logQuery *mockLogQuery) *Handler {
+	store, err := file.NewSavedQueryStore(filepath.Join(t.TempDir(), "saved_queries.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	return &Handler{
+		QueryService: &QueryService{TracingQuerySvc: query, LoggingQuerySvc: logQuery, SavedQuerySvc: store},
+		Limits:       Limits{MaxRange: 24 * time.Hour, DefaultRange: time.Hour, MaxNumTraces: 100},
+	}
+}
+
+func TestSavedQueriesCRUD(t *testing.T) {
+	h := newSavedQueriesHandler(t, &mockQuery{}, &mockLogQuery{})
+	ctx := context.Background()
+
+	created, err := h.CreateSavedQuery(ctx, &v1alpha1.SavedQuery{
+		Id:    "ignored",
+		Name:  "frontend errors",
+		Owner: "oncall",
+		Tags:  []string{"frontend"},
+		Query: &v1alpha1.SavedQuery_TraceQuery{TraceQuery: &v1alpha1.TraceQueryParameters{ServiceName: "frontend"}},
+	})
+	require.NoError(t, err)
+	assert.Regexp(t, "^[0-9A-Za-z]{8}$", created.Id)
+	assert.Equal(t, time.Hour, created.Lookback.AsDuration())
+	assert.NotNil(t, created.CreateTime)
+
+	got, err := h.GetSavedQuery(ctx, &v1alpha1.GetSavedQueryRequest{Id: created.Id})
+	require.NoError(t, err)
+	assert.Equal(t, "frontend errors", got.Name)
+
+	update := &v1alpha1.SavedQuery{
+		Id:       created.Id,
+		Name:     "frontend slow requests",
+		Owner:    "oncall",
+		Lookback: durationpb.New(6 * time.Hour),
+		Query: &v1alpha1.SavedQuery_TraceQuery{TraceQuery: &v1alpha1.TraceQueryParameters{
+			ServiceName: "frontend",
+			DurationMin: durationpb.New(time.Second),
+		}},
+	}
+	updated, err := h.UpdateSavedQuery(ctx, update)
+	require.NoError(t, err)
+	assert.Equal(t, created.Id, updated.Id)
+	assert.Equal(t, created.CreateTime.AsTime(), updated.CreateTime.AsTime())
+
+	list, err := h.ListSavedQueries(ctx, &v1alpha1.ListSavedQueriesRequest{Owner: "oncall"})
+	require.NoError(t, err)
+	require.Len(t, list.SavedQueries, 1)
+	assert.Equal(t, "frontend slow requests", list.SavedQueries[0].Name)
+	list, err = h.ListSavedQueries(ctx, &v1alpha1.ListSavedQueriesRequest{Tag: "frontend"})
+	require.NoError(t, err)
+	assert.Empty(t, list.SavedQueries)
+
+	_, err = h.DeleteSavedQuery(ctx, &v1alpha1.DeleteSavedQueryRequest{Id: created.Id})
+	require.NoError(t, err)
+	_, err = h.GetSavedQuery(ctx, &v1alpha1.GetSavedQueryRequest{Id: created.Id})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = h.UpdateSavedQuery(ctx, update)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	h.QueryService.SavedQuerySvc = nil
+	_, err = h.ListSavedQueries(ctx, &v1alpha1.ListSavedQueriesRequest{})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestSavedQueryValidation(t *testing.T) {
+	h := newSavedQueriesHandler(t, &mockQuery{}, &mockLogQuery{})
+	traceQuery := &v1alpha1.SavedQuery_TraceQuery{TraceQuery: &v1alpha1.TraceQueryParameters{}}
+	for _, query := range []*v1alpha1.SavedQuery{
+		{Query: traceQuery},
+		{Name: "no query"},
+		{Name: "absolute", Query: &v1alpha1.SavedQuery_TraceQuery{TraceQuery: &v1alpha1.TraceQueryParameters{StartTime: timestamppb.Now()}}},
+		{Name: "too long", Lookback: durationpb.New(48 * time.Hour), Query: traceQuery},
+		{Name: "negative", Lookback: durationpb.New(-time.Hour), Query: traceQuery},
+		{Name: "metric", Query: &v1alpha1.SavedQuery_LogQuery{LogQuery: &v1alpha1.LogQueryParameters{Logql: `rate({job="x"}[1m])`}}},
+		{Name: "invalid", Query: &v1alpha1.SavedQuery_LogQuery{LogQuery: &v1alpha1.LogQueryParameters{Logql: `{job=}`}}},
+	} {
+		_, err := h.CreateSavedQuery(context.Background(), query)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), query.Name)
+	}
+}
+
+func TestRunSavedQuery(t *testing.T) {
+	query := &mockQuery{traces: map[string]*v1.TracesData{mockTraceID: mockTrace()}}
+	logQuery := &mockLogQuery{entries: []*datasource.LogEntry{
+		{Timestamp: time.Unix(1681873445, 0), Labels: map[string]string{"service.name": "frontend"}, Line: "timeout"},
+		{Timestamp: time.Unix(1681873446, 0), Labels: map[string]string{"service.name": "frontend"}, Line: "timeout again"},
+	}}
+	h := newSavedQueriesHandler(t, query, logQuery)
+	ctx := context.Background()
+
+	traces, err := h.CreateSavedQuery(ctx, &v1alpha1.SavedQuery{
+		Name:     "frontend",
+		Lookback: durationpb.New(30 * time.Minute),
+		Query:    &v1alpha1.SavedQuery_TraceQuery{TraceQuery: &v1alpha1.TraceQueryParameters{ServiceName: "frontend", NumTraces: 10}},
+	})
+	require.NoError(t, err)
+	end := time.Now().Add(-time.Hour).Truncate(time.Second)
+	res, err := h.RunSavedQuery(ctx, &v1alpha1.RunSavedQueryRequest{Id: traces.Id, EndTime: timestamppb.New(end)})
+	require.NoError(t, err)
+	require.Len(t, res.Traces.Traces, 1)
+	assert.True(t, end.Add(-30*time.Minute).Equal(res.StartTime.AsTime()))
+	assert.Equal(t, "frontend", query.lastSearch.ServiceName)
+	assert.Equal(t, 10, query.lastSearch.NumTraces)
+	assert.True(t, end.Equal(query.lastSearch.EndTime))
+
+	logs, err := h.CreateSavedQuery(ctx, &v1alpha1.SavedQuery{
+		Name:  "frontend timeouts",
+		Query: &v1alpha1.SavedQuery_LogQuery{LogQuery: &v1alpha1.LogQueryParameters{Logql: `{service_name="frontend"} |= "timeout"`, Limit: 20}},
+	})
+	require.NoError(t, err)
+	res, err = h.RunSavedQuery(ctx, &v1alpha1.RunSavedQueryRequest{Id: logs.Id})
+	require.NoError(t, err)
+	require.Len(t, res.Logs.ResourceLogs, 1)
+	assert.Equal(t, "service.name", res.Logs.ResourceLogs[0].Resource.Attributes[0].Key)
+	assert.Len(t, res.Logs.ResourceLogs[0].ScopeLogs[0].LogRecords, 2)
+	assert.Equal(t, []datasource.LabelMatcher{{Name: "service.name", Value: "frontend"}}, logQuery.lastQuery.Matchers)
+	assert.Equal(t, 20, logQuery.lastQuery.Limit)
+	assert.Equal(t, time.Hour, logQuery.lastQuery.EndTime.Sub(logQuery.lastQuery.StartTime))
+
+	_, err = h.RunSavedQuery(ctx, &v1alpha1.RunSavedQueryRequest{Id: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
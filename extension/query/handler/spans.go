@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/api/v1alpha1"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	v1_common "go.opentelemetry.io/proto/otlp/common/v1"
+	v1 "go.opentelemetry.io/proto/otlp/trace/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	spanSearchDefaultLimit = 100
+	spanSearchMaxLimit     = 1000
+	// spanSearchMaxWindow is the deepest row a span search reaches, the default
+	// `index.max_result_window` of Elasticsearch.
+	spanSearchMaxWindow = 10000
+
+	spanColumnAttributePrefix = "attributes."
+	spanColumnResourcePrefix  = "resource."
+)
+
+// spanColumns render the intrinsic columns of a span row.
+var spanColumns = map[string]func(resource []*v1_common.KeyValue, span *v1.Span) string{
+	"trace_id":       func(_ []*v1_common.KeyValue, span *v1.Span) string { return string(span.TraceId) },
+	"span_id":        func(_ []*v1_common.KeyValue, span *v1.Span) string { return string(span.SpanId) },
+	"parent_span_id": func(_ []*v1_common.KeyValue, span *v1.Span) string { return string(span.ParentSpanId) },
+	"service_name": func(resource []*v1_common.KeyValue, _ *v1.Span) string {
+		return attributeValue(resource, "service.name")
+	},
+	"name": func(_ []*v1_common.KeyValue, span *v1.Span) string { return span.Name },
+	"kind": func(_ []*v1_common.KeyValue, span *v1.Span) string { return span.Kind.String() },
+	"start_time": func(_ []*v1_common.KeyValue, span *v1.Span) string {
+		return time.Unix(0, int64(span.StartTimeUnixNano)).UTC().Format(time.RFC3339Nano)
+	},
+	"end_time": func(_ []*v1_common.KeyValue, span *v1.Span) string {
+		return time.Unix(0, int64(span.EndTimeUnixNano)).UTC().Format(time.RFC3339Nano)
+	},
+	"duration": func(_ []*v1_common.KeyValue, span *v1.Span) string {
+		return time.Duration(span.EndTimeUnixNano - span.StartTimeUnixNano).String()
+	},
+	"status_code":    func(_ []*v1_common.KeyValue, span *v1.Span) string { return span.GetStatus().GetCode().String() },
+	"status_message": func(_ []*v1_common.KeyValue, span *v1.Span) string { return span.GetStatus().GetMessage() },
+}
+
+var defaultSpanColumns = []string{"trace_id", "span_id", "service_name", "name", "kind", "start_time", "duration", "status_code"}
+
+// SearchSpans returns the matching spans as rows of the requested columns.
+func (t *Handler) SearchSpans(ctx context.Context, request *v1alpha1.SearchSpansRequest) (*v1alpha1.SearchSpansResponse, error) {
+	params, err := parseSpanQueryParameters(request)
+	if err != nil {
+		return nil, err
+	}
+	if err = t.Limits.applyTimeRange(&params.StartTime, &params.EndTime, time.Now()); err != nil {
+		return nil, err
+	}
+	columns := request.Columns
+	if len(columns) == 0 {
+		columns = defaultSpanColumns
+	}
+	for _, column := range columns {
+		if _, ok := spanColumns[column]; !ok && spanAttributeColumn(column) == "" && spanResourceColumn(column) == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown column %q", column)
+		}
+	}
+
+	// one more span tells whether there is a next page
+	limit := params.Limit
+	params.Limit++
+	spans, err := t.QueryService.Tracing().SearchSpans(ctx, params)
+	if err != nil {
+		zap.S().Errorf("search spans failed: %s", err)
+		return nil, err
+	}
+
+	response := &v1alpha1.SearchSpansResponse{Columns: columns}
+	for _, rs := range spans.GetResourceSpans() {
+		for _, ss := range rs.ScopeSpans {
+			for _, span := range ss.Spans {
+				if len(response.Rows) == limit {
+					response.NextOffset = int32(params.Offset + limit)
+					return response, nil
+				}
+				response.Rows = append(response.Rows, spanRow(columns, rs.GetResource().GetAttributes(), span))
+			}
+		}
+	}
+	return response, nil
+}
+
+func parseSpanQueryParameters(request *v1alpha1.SearchSpansRequest) (*datasource.SpanQueryParameters, error) {
+	params := &datasource.SpanQueryParameters{
+		SortBy:    datasource.SpanSortField(request.SortBy),
+		Ascending: request.Ascending,
+		Offset:    int(request.Offset),
+		Limit:     int(request.Limit),
+	}
+	switch params.SortBy {
+	case "":
+		params.SortBy = datasource.SpanSortByStartTime
+	case datasource.SpanSortByStartTime, datasource.SpanSortByDuration, datasource.SpanSortByServiceName, datasource.SpanSortByName:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown sort_by %q", request.SortBy)
+	}
+	if params.Limit == 0 {
+		params.Limit = spanSearchDefaultLimit
+	}
+	if params.Limit < 0 || params.Limit > spanSearchMaxLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "limit %d must be between 1 and %d", params.Limit, spanSearchMaxLimit)
+	}
+	if params.Offset < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "offset %d must not be negative", params.Offset)
+	}
+	if params.Offset+params.Limit > spanSearchMaxWindow {
+		return nil, status.Errorf(codes.InvalidArgument, "offset and limit must not go past the first %d spans", spanSearchMaxWindow)
+	}
+
+	if request.Query == nil {
+		return params, nil
+	}
+	if err := parseSpanFilter(request.Query, params); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+// parseSpanFilter sets the filters of q on params.
+func parseSpanFilter(q *v1alpha1.SpanQueryParameters, params *datasource.SpanQueryParameters) error {
+	params.ServiceName = q.ServiceName
+	params.OperationName = q.OperationName
+	params.Tags = q.Attributes
+	if q.SpanKind != "" {
+		if _, ok := v1.Span_SpanKind_value[q.SpanKind]; !ok {
+			return status.Errorf(codes.InvalidArgument, "unknown span_kind %q", q.SpanKind)
+		}
+		params.SpanKind = q.SpanKind
+	}
+	if q.StatusCode != "" {
+		if _, ok := v1.Status_StatusCode_value[q.StatusCode]; !ok {
+			return status.Errorf(codes.InvalidArgument, "unknown status_code %q", q.StatusCode)
+		}
+		params.StatusCode = q.StatusCode
+	}
+	if q.StartTime != nil {
+		params.StartTime = q.StartTime.AsTime()
+	}
+	if q.EndTime != nil {
+		params.EndTime = q.EndTime.AsTime()
+	}
+	if q.DurationMin != nil {
+		params.DurationMin = q.DurationMin.AsDuration()
+	}
+	if q.DurationMax != nil {
+		params.DurationMax = q.DurationMax.AsDuration()
+	}
+	if params.DurationMin < 0 || params.DurationMax < 0 || (params.DurationMax > 0 && params.DurationMin > params.DurationMax) {
+		return status.Error(codes.InvalidArgument, "invalid duration_min and duration_max")
+	}
+	return nil
+}
+
+// spanRow renders the columns of a span.
+func spanRow(columns []string, resource []*v1_common.KeyValue, span *v1.Span) *v1alpha1.SpanRow {
+	row := &v1alpha1.SpanRow{Values: make([]string, len(columns))}
+	for i, column := range columns {
+		if render, ok := spanColumns[column]; ok {
+			row.Values[i] = render(resource, span)
+		} else if key := spanAttributeColumn(column); key != "" {
+			row.Values[i] = attributeValue(span.Attributes, key)
+		} else {
+			row.Values[i] = attributeValue(resource, spanResourceColumn(column))
+		}
+	}
+	return row
+}
+
+// spanAttributeColumn returns the span attribute key of an `attributes.<key>` column.
+func spanAttributeColumn(column string) string {
+	if strings.HasPrefix(column, spanColumnAttributePrefix) {
+		return strings.TrimPrefix(column, spanColumnAttributePrefix)
+	}
+	return ""
+}
+
+// spanResourceColumn returns the resource attribute key of a `resource.<key>` column.
+func spanResourceColumn(column string) string {
+	if strings.HasPrefix(column, spanColumnResourcePrefix) {
+		return strings.TrimPrefix(column, spanColumnResourcePrefix)
+	}
+	return ""
+}
+
+// attributeValue renders the value of the attribute key, empty if it's missing.
+func attributeValue(attributes []*v1_common.KeyValue, key string) string {
+	for _, kv := range attributes {
+		if kv.Key == key {
+			return datasource.AnyValueString(kv.Value)
+		}
+	}
+	return ""
+}
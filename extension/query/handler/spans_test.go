@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/api/v1alpha1"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1_common "go.opentelemetry.io/proto/otlp/common/v1"
+	v1_resource "go.opentelemetry.io/proto/otlp/resource/v1"
+	v1 "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func testSpans(n int) *v1.TracesData {
+	start := time.Date(2023, 4, 19, 3, 0, 0, 0, time.UTC)
+	data := &v1.TracesData{}
+	for i := 0; i < n; i++ {
+		data.ResourceSpans = append(data.ResourceSpans, &v1.ResourceSpans{
+			Resource: &v1_resource.Resource{Attributes: []*v1_common.KeyValue{
+				{Key: "service.name", Value: &v1_common.AnyValue{Value: &v1_common.AnyValue_StringValue{StringValue: "frontend"}}},
+			}},
+			ScopeSpans: []*v1.ScopeSpans{{Spans: []*v1.Span{{
+				TraceId:           []byte("0af7651916cd43dd8448eb211c80319c"),
+				SpanId:            []byte("b7ad6b7169203331"),
+				Name:              "GET /api",
+				Kind:              v1.Span_SPAN_KIND_SERVER,
+				StartTimeUnixNano: uint64(start.UnixNano()),
+				EndTimeUnixNano:   uint64(start.Add(1500 * time.Millisecond).UnixNano()),
+				Attributes: []*v1_common.KeyValue{
+					{Key: "http.status_code", Value: &v1_common.AnyValue{Value: &v1_common.AnyValue_IntValue{IntValue: 500}}},
+				},
+				Status: &v1.Status{Code: v1.Status_STATUS_CODE_ERROR},
+			}}}},
+		})
+	}
+	return data
+}
+
+func TestSearchSpans(t *testing.T) {
+	query := &mockQuery{spans: testSpans(3)}
+	h := &Handler{
+		QueryService: &QueryService{TracingQuerySvc: query},
+		Limits:       Limits{MaxRange: 24 * time.Hour, DefaultRange: time.Hour, MaxNumTraces: 100},
+	}
+
+	res, err := h.SearchSpans(context.Background(), &v1alpha1.SearchSpansRequest{
+		Query: &v1alpha1.SpanQueryParameters{
+			ServiceName: "frontend",
+			StatusCode:  "STATUS_CODE_ERROR",
+			DurationMin: durationpb.New(time.Second),
+		},
+		SortBy: "duration",
+		Limit:  2,
+		Offset: 4,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, defaultSpanColumns, res.Columns)
+	require.Len(t, res.Rows, 2)
+	assert.Equal(t, []string{
+		"0af7651916cd43dd8448eb211c80319c", "b7ad6b7169203331", "frontend", "GET /api",
+		"SPAN_KIND_SERVER", "2023-04-19T03:00:00Z", "1.5s", "STATUS_CODE_ERROR",
+	}, res.Rows[0].Values)
+	assert.Equal(t, int32(6), res.NextOffset)
+
+	require.NotNil(t, query.lastSpanSearch)
+	assert.Equal(t, "frontend", query.lastSpanSearch.ServiceName)
+	assert.Equal(t, datasource.SpanSortByDuration, query.lastSpanSearch.SortBy)
+	assert.Equal(t, time.Second, query.lastSpanSearch.DurationMin)
+	assert.Equal(t, 3, query.lastSpanSearch.Limit)
+	assert.Equal(t, 4, query.lastSpanSearch.Offset)
+	assert.Equal(t, time.Hour, query.lastSpanSearch.EndTime.Sub(query.lastSpanSearch.StartTime))
+
+	res, err = h.SearchSpans(context.Background(), &v1alpha1.SearchSpansRequest{
+		Columns: []string{"name", "attributes.http.status_code", "resource.service.name", "attributes.missing"},
+	})
+	require.NoError(t, err)
+	require.Len(t, res.Rows, 3)
+	assert.Equal(t, []string{"GET /api", "500", "frontend", ""}, res.Rows[0].Values)
+	assert.Zero(t, res.NextOffset)
+	assert.Equal(t, datasource.SpanSortByStartTime, query.lastSpanSearch.SortBy)
+	assert.Equal(t, spanSearchDefaultLimit+1, query.lastSpanSearch.Limit)
+}
+
+func TestSearchSpansInvalidArgument(t *testing.T) {
+	h := &Handler{
+		QueryService: &QueryService{TracingQuerySvc: &mockQuery{spans: testSpans(1)}},
+		Limits:       Limits{MaxRange: 24 * time.Hour, DefaultRange: time.Hour, MaxNumTraces: 100},
+	}
+	for _, request := range []*v1alpha1.SearchSpansRequest{
+		{Columns: []string{"unknown"}},
+		{SortBy: "unknown"},
+		{Limit: spanSearchMaxLimit + 1},
+		{Offset: spanSearchMaxWindow},
+		{Query: &v1alpha1.SpanQueryParameters{StatusCode: "ERROR"}},
+		{Query: &v1alpha1.SpanQueryParameters{SpanKind: "client"}},
+		{Query: &v1alpha1.SpanQueryParameters{DurationMin: durationpb.New(time.Second), DurationMax: durationpb.New(time.Millisecond)}},
+	} {
+		_, err := h.SearchSpans(context.Background(), request)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), "%v", request)
+	}
+}
@@ -0,0 +1,474 @@
+package handler
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	semconv "go.opentelemetry.io/collector/semconv/v1.6.1"
+	v1 "go.opentelemetry.io/proto/otlp/trace/v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	tempoDefaultLimit        = 20
+	tempoDefaultSpansPerSet  = 3
+	tempoProtobufContentType = "application/protobuf"
+	// tempoCandidateFactor is how many candidate traces are fetched per requested trace,
+	// as a TraceQL query filters the candidates after they are read.
+	tempoCandidateFactor = 5
+	// tempoTagSampleSize is the number of recent traces the tag names and values are read from.
+	tempoTagSampleSize = 20
+	// tempoSpanNameTag is the tag of the legacy search matching the span name.
+	tempoSpanNameTag = "name"
+)
+
+// TempoHandler serves the search and trace endpoints of the Grafana Tempo HTTP API from the
+// tracing datasource, with a TraceQL subset, see traceql.go.
+// refs: https://grafana.com/docs/tempo/latest/api_docs/
+type TempoHandler struct {
+	QueryService *QueryService
+	Limits       Limits
+	// Available reports whether the tracing datasource can serve queries, optional.
+	Available func() error
+}
+
+// RegisterRoutes adds the Tempo endpoints under `/api` to router.
+func (t *TempoHandler) RegisterRoutes(router *mux.Router) {
+	r := router.PathPrefix("/api").Subrouter()
+	r.HandleFunc("/echo", t.echo).Methods(http.MethodGet)
+	r.HandleFunc("/search", t.search).Methods(http.MethodGet)
+	r.HandleFunc("/traces/{traceId}", t.getTrace).Methods(http.MethodGet)
+	r.HandleFunc("/search/tags", t.searchTags).Methods(http.MethodGet)
+	r.HandleFunc("/search/tag/{tagName}/values", t.searchTagValues).Methods(http.MethodGet)
+}
+
+// echo is the endpoint Grafana tests the datasource connection with.
+func (t *TempoHandler) echo(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = w.Write([]byte("echo"))
+}
+
+// tempoSearchRequest is a parsed `/api/search` request.
+type tempoSearchRequest struct {
+	params *datasource.TraceQueryParameters
+	// query is nil for a search by tags.
+	query       *traceqlQuery
+	limit       int
+	spansPerSet int
+}
+
+func (t *TempoHandler) search(w http.ResponseWriter, r *http.Request) {
+	req, err := parseTempoSearchRequest(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err = t.Limits.applyTraceQuery(req.params, time.Now()); err != nil {
+		writeError(w, err)
+		return
+	}
+	if req.query != nil {
+		req.params.NumTraces = req.limit * tempoCandidateFactor
+		if t.Limits.MaxNumTraces > 0 && req.params.NumTraces > t.Limits.MaxNumTraces {
+			req.params.NumTraces = t.Limits.MaxNumTraces
+		}
+	}
+	ctx, cancel, err := beginRequest(r, t.Limits, t.Available)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer cancel()
+
+	found, err := t.QueryService.Tracing().FindTraces(ctx, req.params)
+	if err != nil {
+		writeError(w, spanLimitError(err))
+		return
+	}
+	res := &tempoSearchResponse{Traces: []*tempoTrace{}}
+	for _, trace := range splitTraces(found) {
+		if len(res.Traces) >= req.limit {
+			break
+		}
+		if err = t.Limits.checkTraceSpans(trace); err != nil {
+			writeError(w, err)
+			return
+		}
+		res.Metrics.InspectedTraces++
+
+		tt := newTraceQLTrace(trace)
+		if len(tt.spans) == 0 {
+			continue
+		}
+		result := toTempoTrace(string(tt.spans[0].span.TraceId), tt)
+		if req.query != nil {
+			spans := req.query.matchedSpans(tt)
+			if len(spans) == 0 {
+				continue
+			}
+			spanSet := toTempoSpanSet(spans, req.query.attributes, req.spansPerSet)
+			result.SpanSet = spanSet
+			result.SpanSets = []*tempoSpanSet{spanSet}
+		}
+		res.Traces = append(res.Traces, result)
+	}
+	writeJSON(w, res)
+}
+
+// parseTempoSearchRequest translates the `/api/search` query parameters, the TraceQL query `q`
+// takes precedence over the logfmt encoded `tags`.
+func parseTempoSearchRequest(r *http.Request) (*tempoSearchRequest, error) {
+	query := r.URL.Query()
+	req := &tempoSearchRequest{
+		params:      &datasource.TraceQueryParameters{Tags: map[string]string{}},
+		limit:       tempoDefaultLimit,
+		spansPerSet: tempoDefaultSpansPerSet,
+	}
+
+	limit, err := parseTempoInt(query.Get("limit"), "limit")
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 {
+		req.limit = int(limit)
+	}
+	req.params.NumTraces = req.limit
+	spss, err := parseTempoInt(query.Get("spss"), "spss")
+	if err != nil {
+		return nil, err
+	}
+	if spss > 0 {
+		req.spansPerSet = int(spss)
+	}
+
+	start, err := parseTempoInt(query.Get("start"), "start")
+	if err != nil {
+		return nil, err
+	}
+	if start > 0 {
+		req.params.StartTime = time.Unix(start, 0)
+	}
+	end, err := parseTempoInt(query.Get("end"), "end")
+	if err != nil {
+		return nil, err
+	}
+	if end > 0 {
+		req.params.EndTime = time.Unix(end, 0)
+	}
+
+	if req.params.DurationMin, err = parseTempoDuration(query.Get("minDuration"), "minDuration"); err != nil {
+		return nil, err
+	}
+	if req.params.DurationMax, err = parseTempoDuration(query.Get("maxDuration"), "maxDuration"); err != nil {
+		return nil, err
+	}
+
+	if q := strings.TrimSpace(query.Get("q")); q != "" {
+		if req.query, err = parseTraceQL(q); err != nil {
+			return nil, err
+		}
+		req.query.pushdown(req.params)
+		return req, nil
+	}
+
+	tags, err := parseLogfmt(query.Get("tags"))
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range tags {
+		switch key {
+		case semconv.AttributeServiceName:
+			req.params.ServiceName = value
+		case tempoSpanNameTag:
+			req.params.OperationName = value
+		default:
+			req.params.Tags[key] = value
+		}
+	}
+	return req, nil
+}
+
+func parseTempoInt(value, name string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	i, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || i < 0 {
+		return 0, status.Errorf(codes.InvalidArgument, "%s must be a positive integer: %q", name, value)
+	}
+	return i, nil
+}
+
+func parseTempoDuration(value, name string) (*durationpb.Duration, error) {
+	if value == "" {
+		return nil, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "%s must be a positive duration such as 100ms: %q", name, value)
+	}
+	return durationpb.New(d), nil
+}
+
+// parseLogfmt parses the `key=value key="quoted value"` pairs of the Tempo tags parameter.
+func parseLogfmt(input string) (map[string]string, error) {
+	pairs := map[string]string{}
+	for i := 0; i < len(input); {
+		if input[i] == ' ' {
+			i++
+			continue
+		}
+		eq := strings.IndexByte(input[i:], '=')
+		if eq <= 0 || strings.ContainsRune(input[i:i+eq], ' ') {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid tags %q, use key=value pairs", input)
+		}
+		key := input[i : i+eq]
+		i += eq + 1
+
+		if i < len(input) && input[i] == '"' {
+			end := i + 1
+			for end < len(input) && input[end] != '"' {
+				if input[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(input) {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid tags %q, unterminated quote", input)
+			}
+			value, err := strconv.Unquote(input[i : end+1])
+			if err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid tags %q: %v", input, err)
+			}
+			pairs[key] = value
+			i = end + 1
+			continue
+		}
+		end := strings.IndexByte(input[i:], ' ')
+		if end < 0 {
+			end = len(input) - i
+		}
+		pairs[key] = input[i : i+end]
+		i += end
+	}
+	return pairs, nil
+}
+
+func (t *TempoHandler) getTrace(w http.ResponseWriter, r *http.Request) {
+	traceID, err := normalizeTraceID(mux.Vars(r)["traceId"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	ctx, cancel, err := beginRequest(r, t.Limits, t.Available)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer cancel()
+
+	trace, err := t.QueryService.GetTrace(ctx, traceID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err = t.Limits.checkTraceSpans(trace); err != nil {
+		writeError(w, err)
+		return
+	}
+	if len(newTraceQLTrace(trace).spans) == 0 {
+		writeError(w, status.Errorf(codes.NotFound, "trace %s not found", traceID))
+		return
+	}
+
+	trace = withBinaryIDs(trace)
+	// Grafana asks for the protobuf encoding, the Tempo trace message shares the field number of
+	// the OTLP TracesData resource spans.
+	if strings.Contains(r.Header.Get("Accept"), tempoProtobufContentType) {
+		b, err := proto.Marshal(trace)
+		if err != nil {
+			writeError(w, status.Error(codes.Internal, err.Error()))
+			return
+		}
+		w.Header().Set("Content-Type", tempoProtobufContentType)
+		if _, err = w.Write(b); err != nil {
+			zap.S().Errorf("write response failed: %v", err)
+		}
+		return
+	}
+	batches := make([]json.RawMessage, 0, len(trace.ResourceSpans))
+	for _, rs := range trace.ResourceSpans {
+		b, err := protojson.Marshal(rs)
+		if err != nil {
+			writeError(w, status.Error(codes.Internal, err.Error()))
+			return
+		}
+		batches = append(batches, b)
+	}
+	writeJSON(w, map[string]interface{}{"batches": batches})
+}
+
+// withBinaryIDs returns a copy of the trace with the hex encoded ids, as the datasources store
+// them, decoded to the bytes OTLP expects.
+func withBinaryIDs(trace *v1.TracesData) *v1.TracesData {
+	trace = proto.Clone(trace).(*v1.TracesData)
+	for _, rs := range trace.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			for _, span := range ss.Spans {
+				span.TraceId = decodeHexID(span.TraceId)
+				span.SpanId = decodeHexID(span.SpanId)
+				span.ParentSpanId = decodeHexID(span.ParentSpanId)
+				for _, link := range span.Links {
+					link.TraceId = decodeHexID(link.TraceId)
+					link.SpanId = decodeHexID(link.SpanId)
+				}
+			}
+		}
+	}
+	return trace
+}
+
+func decodeHexID(id []byte) []byte {
+	decoded, err := hex.DecodeString(string(id))
+	if err != nil {
+		return id
+	}
+	return decoded
+}
+
+func (t *TempoHandler) searchTags(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel, err := beginRequest(r, t.Limits, t.Available)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer cancel()
+
+	traces, err := t.sampleTraces(ctx, r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	names := map[string]struct{}{semconv.AttributeServiceName: {}}
+	for _, trace := range traces {
+		for _, s := range trace.spans {
+			for _, kv := range s.resource.GetAttributes() {
+				names[kv.Key] = struct{}{}
+			}
+			for _, kv := range s.span.Attributes {
+				names[kv.Key] = struct{}{}
+			}
+		}
+	}
+	writeJSON(w, map[string][]string{"tagNames": sortedKeys(names)})
+}
+
+func (t *TempoHandler) searchTagValues(w http.ResponseWriter, r *http.Request) {
+	tagName := mux.Vars(r)["tagName"]
+	ctx, cancel, err := beginRequest(r, t.Limits, t.Available)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer cancel()
+
+	values := map[string]struct{}{}
+	if tagName == semconv.AttributeServiceName {
+		resources, err := t.QueryService.Tracing().GetService(ctx)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		for _, resource := range resources {
+			for _, kv := range resource.GetAttributes() {
+				if kv.Key == semconv.AttributeServiceName {
+					values[datasource.AnyValueString(kv.Value)] = struct{}{}
+				}
+			}
+		}
+		writeJSON(w, map[string][]string{"tagValues": sortedKeys(values)})
+		return
+	}
+
+	traces, err := t.sampleTraces(ctx, r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	for _, trace := range traces {
+		for _, s := range trace.spans {
+			if tagName == tempoSpanNameTag {
+				values[s.span.Name] = struct{}{}
+				continue
+			}
+			for _, kv := range s.span.Attributes {
+				if kv.Key == tagName {
+					values[datasource.AnyValueString(kv.Value)] = struct{}{}
+				}
+			}
+			for _, kv := range s.resource.GetAttributes() {
+				if kv.Key == tagName {
+					values[datasource.AnyValueString(kv.Value)] = struct{}{}
+				}
+			}
+		}
+	}
+	writeJSON(w, map[string][]string{"tagValues": sortedKeys(values)})
+}
+
+// sampleTraces reads the recent traces the tag names and values are collected from,
+// as the datasources don't index the attribute keys.
+func (t *TempoHandler) sampleTraces(ctx context.Context, r *http.Request) ([]*traceqlTrace, error) {
+	params := &datasource.TraceQueryParameters{Tags: map[string]string{}, NumTraces: tempoTagSampleSize}
+	query := r.URL.Query()
+	start, err := parseTempoInt(query.Get("start"), "start")
+	if err != nil {
+		return nil, err
+	}
+	if start > 0 {
+		params.StartTime = time.Unix(start, 0)
+	}
+	end, err := parseTempoInt(query.Get("end"), "end")
+	if err != nil {
+		return nil, err
+	}
+	if end > 0 {
+		params.EndTime = time.Unix(end, 0)
+	}
+	if err = t.Limits.applyTraceQuery(params, time.Now()); err != nil {
+		return nil, err
+	}
+
+	found, err := t.QueryService.Tracing().FindTraces(ctx, params)
+	if err != nil {
+		return nil, spanLimitError(err)
+	}
+	split := splitTraces(found)
+	traces := make([]*traceqlTrace, 0, len(split))
+	for _, trace := range split {
+		traces = append(traces, newTraceQLTrace(trace))
+	}
+	return traces, nil
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	semconv "go.opentelemetry.io/collector/semconv/v1.6.1"
+	v1_common "go.opentelemetry.io/proto/otlp/common/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// tempoRootSpanNotFound is the root service name Tempo reports when the root span is missing.
+const tempoRootSpanNotFound = "<root span not yet received>"
+
+// tempoSearchResponse is the Tempo search response.
+// refs: https://grafana.com/docs/tempo/latest/api_docs/#search
+type tempoSearchResponse struct {
+	Traces  []*tempoTrace      `json:"traces"`
+	Metrics tempoSearchMetrics `json:"metrics"`
+}
+
+type tempoSearchMetrics struct {
+	InspectedTraces uint32 `json:"inspectedTraces"`
+}
+
+type tempoTrace struct {
+	TraceID           string `json:"traceID"`
+	RootServiceName   string `json:"rootServiceName"`
+	RootTraceName     string `json:"rootTraceName,omitempty"`
+	StartTimeUnixNano string `json:"startTimeUnixNano"`
+	DurationMs        uint64 `json:"durationMs"`
+	// SpanSet is the first of SpanSets, kept for the Grafana versions predating SpanSets.
+	SpanSet  *tempoSpanSet   `json:"spanSet,omitempty"`
+	SpanSets []*tempoSpanSet `json:"spanSets,omitempty"`
+}
+
+type tempoSpanSet struct {
+	Spans   []*tempoSpan `json:"spans"`
+	Matched int          `json:"matched"`
+}
+
+type tempoSpan struct {
+	SpanID            string `json:"spanID"`
+	Name              string `json:"name,omitempty"`
+	StartTimeUnixNano string `json:"startTimeUnixNano"`
+	DurationNanos     string `json:"durationNanos"`
+	// Attributes are OTLP/JSON key values.
+	Attributes []json.RawMessage `json:"attributes,omitempty"`
+}
+
+// toTempoTrace summarizes a trace with its root span and time range.
+func toTempoTrace(traceID string, t *traceqlTrace) *tempoTrace {
+	result := &tempoTrace{TraceID: traceID, RootServiceName: tempoRootSpanNotFound}
+	var start, end uint64
+	for _, s := range t.spans {
+		if start == 0 || s.span.StartTimeUnixNano < start {
+			start = s.span.StartTimeUnixNano
+		}
+		if s.span.EndTimeUnixNano > end {
+			end = s.span.EndTimeUnixNano
+		}
+	}
+	result.StartTimeUnixNano = strconv.FormatUint(start, 10)
+	if end > start {
+		result.DurationMs = (end - start) / 1e6
+	}
+
+	if root := rootTraceQLSpan(t); root != nil {
+		result.RootTraceName = root.span.Name
+		for _, kv := range root.resource.GetAttributes() {
+			if kv.Key == semconv.AttributeServiceName {
+				result.RootServiceName = datasource.AnyValueString(kv.Value)
+			}
+		}
+	}
+	return result
+}
+
+// rootTraceQLSpan returns the span without a parent id, or else the first span whose parent is missing.
+func rootTraceQLSpan(t *traceqlTrace) *traceqlSpan {
+	var orphan *traceqlSpan
+	for _, s := range t.spans {
+		if len(s.span.ParentSpanId) == 0 {
+			return s
+		}
+		if orphan == nil && s.parent == nil {
+			orphan = s
+		}
+	}
+	return orphan
+}
+
+// toTempoSpanSet renders up to limit matched spans with the attributes the query compares.
+func toTempoSpanSet(spans []*traceqlSpan, attributes []traceqlAttribute, limit int) *tempoSpanSet {
+	set := &tempoSpanSet{Matched: len(spans)}
+	for i, s := range spans {
+		if i >= limit {
+			break
+		}
+		span := &tempoSpan{
+			SpanID:            string(s.span.SpanId),
+			Name:              s.span.Name,
+			StartTimeUnixNano: strconv.FormatUint(s.span.StartTimeUnixNano, 10),
+			DurationNanos:     "0",
+		}
+		if s.span.EndTimeUnixNano > s.span.StartTimeUnixNano {
+			span.DurationNanos = strconv.FormatUint(s.span.EndTimeUnixNano-s.span.StartTimeUnixNano, 10)
+		}
+
+		seen := map[string]bool{}
+		for _, attribute := range attributes {
+			if seen[attribute.name] {
+				continue
+			}
+			value, ok := (&comparison{attribute: attribute}).lookup(s)
+			if !ok {
+				continue
+			}
+			seen[attribute.name] = true
+			b, err := protojson.Marshal(&v1_common.KeyValue{Key: attribute.name, Value: value})
+			if err == nil {
+				span.Attributes = append(span.Attributes, b)
+			}
+		}
+		set.Spans = append(set.Spans, span)
+	}
+	return set
+}
@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+const mockTraceID = "01020304050607080807060504030201"
+
+func newTempoRouter(query *mockQuery) *mux.Router {
+	router := mux.NewRouter()
+	tempo := &TempoHandler{
+		QueryService: &QueryService{TracingQuerySvc: query},
+		Limits:       Limits{DefaultRange: time.Hour, MaxSpansPerTrace: 100},
+	}
+	tempo.RegisterRoutes(router)
+	return router
+}
+
+func serveTempo(router *mux.Router, url string, header http.Header) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestTempoEcho(t *testing.T) {
+	rec := serveTempo(newTempoRouter(&mockQuery{}), "/api/echo", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "echo", rec.Body.String())
+}
+
+func TestTempoSearchTraceQL(t *testing.T) {
+	query := &mockQuery{traces: map[string]*v1.TracesData{mockTraceID: mockTrace()}}
+	router := newTempoRouter(query)
+
+	rec := serveTempo(router, `/api/search?q=%7B+resource.service.name%3D%22frontend%22+%7D+%3E%3E+%7B+.peer.service%3D%22mysql%22+%7D&limit=2&start=1681870000&end=1681873500`, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var res tempoSearchResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+
+	require.Equal(t, 1, len(res.Traces))
+	trace := res.Traces[0]
+	assert.Equal(t, mockTraceID, trace.TraceID)
+	assert.Equal(t, "frontend", trace.RootServiceName)
+	assert.Equal(t, "GET /hello", trace.RootTraceName)
+	assert.Equal(t, "1681873445000000000", trace.StartTimeUnixNano)
+	assert.Equal(t, uint64(2), trace.DurationMs)
+	require.Equal(t, 1, len(trace.SpanSets))
+	assert.Equal(t, 1, trace.SpanSets[0].Matched)
+	span := trace.SpanSets[0].Spans[0]
+	assert.Equal(t, "1112131415161718", span.SpanID)
+	assert.Equal(t, "1000000", span.DurationNanos)
+	require.Equal(t, 2, len(span.Attributes))
+	assert.JSONEq(t, `{"key":"service.name","value":{"stringValue":"frontend"}}`, string(span.Attributes[0]))
+	assert.JSONEq(t, `{"key":"peer.service","value":{"stringValue":"mysql"}}`, string(span.Attributes[1]))
+	assert.Equal(t, uint32(1), res.Metrics.InspectedTraces)
+
+	params := query.lastSearch
+	assert.Equal(t, "frontend", params.ServiceName)
+	assert.Equal(t, 10, params.NumTraces)
+	assert.Equal(t, time.Unix(1681870000, 0), params.StartTime)
+	assert.Equal(t, 1, query.searches)
+	assert.Equal(t, 0, query.gets)
+
+	rec = serveTempo(router, `/api/search?q=%7B+status%3Dok+%7D`, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.Empty(t, res.Traces)
+	assert.Equal(t, "STATUS_CODE_OK", query.lastSearch.StatusCode)
+
+	rec = serveTempo(router, `/api/search?q=%7B+span.http.status_code%3D500+%26%26+duration+%3E+2s+%7D`, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, map[string]string{"http.status_code": "500"}, query.lastSearch.Tags)
+	assert.Equal(t, 2*time.Second, query.lastSearch.DurationMin.AsDuration())
+
+	rec = serveTempo(router, `/api/search?q=%7B+status%3D`, nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestTempoSearchTags(t *testing.T) {
+	query := &mockQuery{traces: map[string]*v1.TracesData{mockTraceID: mockTrace()}}
+	router := newTempoRouter(query)
+
+	rec := serveTempo(router, `/api/search?tags=service.name%3Dfrontend+name%3D%22GET+%2Fhello%22+http.method%3DGET&minDuration=1ms`, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var res tempoSearchResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	require.Equal(t, 1, len(res.Traces))
+	assert.Nil(t, res.Traces[0].SpanSets)
+
+	params := query.lastSearch
+	assert.Equal(t, "frontend", params.ServiceName)
+	assert.Equal(t, "GET /hello", params.OperationName)
+	assert.Equal(t, map[string]string{"http.method": "GET"}, params.Tags)
+	assert.Equal(t, time.Millisecond, params.DurationMin.AsDuration())
+	assert.Equal(t, tempoDefaultLimit, params.NumTraces)
+
+	assert.Equal(t, http.StatusBadRequest, serveTempo(router, `/api/search?tags=http.method`, nil).Code)
+	assert.Equal(t, http.StatusBadRequest, serveTempo(router, `/api/search?minDuration=10`, nil).Code)
+}
+
+func TestTempoGetTrace(t *testing.T) {
+	router := newTempoRouter(&mockQuery{traces: map[string]*v1.TracesData{mockTraceID: mockTrace()}})
+
+	rec := serveTempo(router, "/api/traces/"+mockTraceID, http.Header{"Accept": {"application/protobuf"}})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/protobuf", rec.Header().Get("Content-Type"))
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	var trace v1.TracesData
+	require.NoError(t, proto.Unmarshal(body, &trace))
+	spans := trace.ResourceSpans[0].ScopeSpans[0].Spans
+	assert.Equal(t, []byte{1, 2, 3, 4, 5, 6, 7, 8, 8, 7, 6, 5, 4, 3, 2, 1}, spans[0].TraceId)
+	assert.Equal(t, []byte{0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18}, spans[1].SpanId)
+	assert.Equal(t, []byte{1, 2, 3, 4, 5, 6, 7, 8}, spans[1].ParentSpanId)
+
+	rec = serveTempo(router, "/api/traces/"+mockTraceID, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var res struct {
+		Batches []json.RawMessage `json:"batches"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.Equal(t, 1, len(res.Batches))
+
+	assert.Equal(t, http.StatusNotFound, serveTempo(router, "/api/traces/0a0b", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, serveTempo(router, "/api/traces/xyz", nil).Code)
+}
+
+func TestTempoTagNamesAndValues(t *testing.T) {
+	router := newTempoRouter(&mockQuery{traces: map[string]*v1.TracesData{mockTraceID: mockTrace()}})
+
+	var names map[string][]string
+	rec := serveTempo(router, "/api/search/tags", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &names))
+	assert.Equal(t, []string{"host.name", "http.method", "net.peer.ip", "net.peer.port", "peer.service", "service.name"}, names["tagNames"])
+
+	var values map[string][]string
+	rec = serveTempo(router, "/api/search/tag/service.name/values", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &values))
+	assert.Equal(t, []string{"frontend"}, values["tagValues"])
+
+	rec = serveTempo(router, "/api/search/tag/name/values", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &values))
+	assert.Equal(t, []string{"GET /hello", "SELECT"}, values["tagValues"])
+
+	rec = serveTempo(router, "/api/search/tag/net.peer.port/values", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &values))
+	assert.Equal(t, []string{"3306"}, values["tagValues"])
+}
+
+func TestParseLogfmt(t *testing.T) {
+	pairs, err := parseLogfmt(`a=b  c="d e" f=`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "b", "c": "d e", "f": ""}, pairs)
+
+	_, err = parseLogfmt(`a="b`)
+	assert.Error(t, err)
+}
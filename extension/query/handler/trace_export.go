@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/utilities"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/api/v1alpha1"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	v1 "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// traceImportMaxBytes is the largest trace file accepted by the import endpoint.
+	traceImportMaxBytes = 32 << 20
+	// traceImportFormField is the multipart form field of an uploaded trace file.
+	traceImportFormField = "file"
+)
+
+// TraceFileHandler exports traces of the tracing datasource as downloadable files, and imports
+// trace files to render them as the query API does, without writing them to the datasource.
+// The formats are OTLP protobuf, OTLP/JSON with hex IDs, Jaeger JSON and Zipkin v2 JSON.
+type TraceFileHandler struct {
+	QueryService *QueryService
+	Limits       Limits
+	// Available reports whether the tracing datasource can serve queries, optional.
+	Available func() error
+}
+
+// RegisterRoutes adds the export and import endpoints under `/apis/traces/v1alpha1` to router.
+func (h *TraceFileHandler) RegisterRoutes(router *mux.Router) {
+	r := router.PathPrefix("/apis/traces/v1alpha1").Subrouter()
+	r.HandleFunc("/trace/{traceId}/export", h.exportTrace).Methods(http.MethodGet)
+	r.HandleFunc("/export", h.exportTraces).Methods(http.MethodGet)
+	r.HandleFunc("/import", h.importTraces).Methods(http.MethodPost)
+}
+
+// exportTrace downloads a trace, as GetTrace returns it.
+func (h *TraceFileHandler) exportTrace(w http.ResponseWriter, r *http.Request) {
+	format, err := parseTraceFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	traceID, err := normalizeTraceID(mux.Vars(r)["traceId"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	ctx, cancel, err := beginRequest(r, h.Limits, h.Available)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer cancel()
+
+	trace, err := h.QueryService.GetTrace(ctx, traceID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err = h.Limits.checkTraceSpans(trace); err != nil {
+		writeError(w, err)
+		return
+	}
+	if len(trace.GetResourceSpans()) == 0 {
+		writeError(w, status.Errorf(codes.NotFound, "trace %s not found", traceID))
+		return
+	}
+	writeTraceFile(w, format, "trace-"+traceID, trace)
+}
+
+// exportTraces downloads the traces found by a search, with the query parameters of SearchTraces.
+func (h *TraceFileHandler) exportTraces(w http.ResponseWriter, r *http.Request) {
+	format, err := parseTraceFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	request := &v1alpha1.FindTracesRequest{}
+	if err = runtime.PopulateQueryParameters(request, r.URL.Query(), utilities.NewDoubleArray(nil)); err != nil {
+		writeError(w, status.Error(codes.InvalidArgument, err.Error()))
+		return
+	}
+	params, err := parseTraceQueryParameters(request)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err = h.Limits.applyTraceQuery(params, time.Now()); err != nil {
+		writeError(w, err)
+		return
+	}
+	ctx, cancel, err := beginRequest(r, h.Limits, h.Available)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer cancel()
+
+	found, err := h.QueryService.Tracing().FindTraces(ctx, params)
+	if err != nil {
+		writeError(w, spanLimitError(err))
+		return
+	}
+	traces := &v1.TracesData{}
+	for _, trace := range splitTraces(found) {
+		if err = h.Limits.checkTraceSpans(trace); err != nil {
+			writeError(w, err)
+			return
+		}
+		traces.ResourceSpans = append(traces.ResourceSpans, trace.GetResourceSpans()...)
+	}
+	writeTraceFile(w, format, "traces", traces)
+}
+
+func writeTraceFile(w http.ResponseWriter, format traceFormat, name string, td *v1.TracesData) {
+	b, err := encodeTraces(format, td)
+	if err != nil {
+		writeError(w, status.Errorf(codes.Internal, "encode %s traces failed: %v", format, err))
+		return
+	}
+	w.Header().Set("Content-Type", format.contentType())
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": name + format.fileExtension(),
+	}))
+	_, _ = w.Write(b)
+}
+
+// traceImportResponse renders imported traces as the query API does: the summaries as
+// SearchTraces returns them, and the spans of every trace as GetTrace returns them.
+type traceImportResponse struct {
+	Traces json.RawMessage   `json:"traces"`
+	Data   []json.RawMessage `json:"data"`
+}
+
+// importTraces reads a trace file, from the request body or the `file` field of a multipart
+// form, in the `format` parameter or the detected format.
+func (h *TraceFileHandler) importTraces(w http.ResponseWriter, r *http.Request) {
+	content, contentType, err := readTraceFile(w, r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var format traceFormat
+	if value := r.URL.Query().Get("format"); value != "" {
+		format, err = parseTraceFormat(value)
+	} else {
+		format, err = detectTraceFormat(contentType, content)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	td, err := decodeTraces(format, content)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	traces := splitTraces(td)
+	if len(traces) == 0 {
+		writeError(w, status.Error(codes.InvalidArgument, "the trace file has no spans"))
+		return
+	}
+	all := &v1.TracesData{}
+	for _, trace := range traces {
+		all.ResourceSpans = append(all.ResourceSpans, trace.ResourceSpans...)
+	}
+	summaries, err := datasource.DocumentsTracesConvert(all)
+	if err != nil {
+		writeError(w, status.Error(codes.InvalidArgument, err.Error()))
+		return
+	}
+	if err = h.Limits.checkTracesSpans(summaries); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	marshaler := &runtime.JSONPb{}
+	response := &traceImportResponse{}
+	if response.Traces, err = marshaler.Marshal(summaries); err != nil {
+		writeError(w, status.Error(codes.Internal, err.Error()))
+		return
+	}
+	for _, trace := range traces {
+		b, err := marshaler.Marshal(trace)
+		if err != nil {
+			writeError(w, status.Error(codes.Internal, err.Error()))
+			return
+		}
+		response.Data = append(response.Data, b)
+	}
+	writeJSON(w, response)
+}
+
+// readTraceFile returns the uploaded file with its content type, the requests over
+// traceImportMaxBytes are rejected.
+func readTraceFile(w http.ResponseWriter, r *http.Request) ([]byte, string, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, traceImportMaxBytes)
+	contentType := r.Header.Get("Content-Type")
+	body := io.Reader(r.Body)
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == "multipart/form-data" {
+		if err := r.ParseMultipartForm(traceImportMaxBytes); err != nil {
+			return nil, "", traceFileError(err)
+		}
+		file, header, err := r.FormFile(traceImportFormField)
+		if err != nil {
+			return nil, "", status.Errorf(codes.InvalidArgument, "the %q form field is required: %v", traceImportFormField, err)
+		}
+		defer file.Close()
+		body, contentType = file, header.Header.Get("Content-Type")
+	}
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return nil, "", traceFileError(err)
+	}
+	return content, contentType, nil
+}
+
+func traceFileError(err error) error {
+	return status.Errorf(codes.InvalidArgument, "read the trace file failed: %v", err)
+}
@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1_common "go.opentelemetry.io/proto/otlp/common/v1"
+	v1 "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestTraceFormatsOTLPRoundTrip(t *testing.T) {
+	for _, format := range []traceFormat{traceFormatOTLP, traceFormatOTLPJSON} {
+		b, err := encodeTraces(format, mockTrace())
+		require.NoError(t, err)
+		td, err := decodeTraces(format, b)
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(mockTrace(), td), "%s: %v", format, td)
+	}
+
+	// OTLP/JSON has the IDs in hex while OTLP protobuf has them raw
+	b, err := encodeTraces(traceFormatOTLPJSON, mockTrace())
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"traceId":"`+mockTraceID+`"`)
+	assert.Contains(t, string(b), `"parentSpanId":"0102030405060708"`)
+	b, err = encodeTraces(traceFormatOTLP, mockTrace())
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "\x01\x02\x03\x04\x05\x06\x07\x08\x08\x07\x06\x05\x04\x03\x02\x01")
+}
+
+func TestTraceFormatsRoundTrip(t *testing.T) {
+	for _, format := range []traceFormat{traceFormatJaeger, traceFormatZipkin} {
+		b, err := encodeTraces(format, mockTrace())
+		require.NoError(t, err)
+		detected, err := detectTraceFormat(contentTypeJSON, b)
+		require.NoError(t, err)
+		assert.Equal(t, format, detected)
+		td, err := decodeTraces(format, b)
+		require.NoError(t, err, format)
+
+		spans := map[string]*v1.Span{}
+		scopes := map[string]*v1_common.InstrumentationScope{}
+		for _, rs := range td.ResourceSpans {
+			assert.Equal(t, "frontend", rs.Resource.Attributes[0].Value.GetStringValue(), format)
+			for _, ss := range rs.ScopeSpans {
+				for _, span := range ss.Spans {
+					spans[span.Name] = span
+					scopes[span.Name] = ss.Scope
+				}
+			}
+		}
+		require.Len(t, spans, 2, format)
+		server, client := spans["GET /hello"], spans["SELECT"]
+		assert.Equal(t, mockTraceID, string(server.TraceId), format)
+		assert.Equal(t, "0102030405060708", string(server.SpanId), format)
+		assert.Empty(t, server.ParentSpanId, format)
+		assert.Equal(t, v1.Span_SPAN_KIND_SERVER, server.Kind, format)
+		assert.Equal(t, uint64(1681873445002000000), server.EndTimeUnixNano, format)
+		assert.Equal(t, "net/http", scopes["GET /hello"].Name, format)
+		assert.Equal(t, "0.36.0", scopes["GET /hello"].Version, format)
+
+		assert.Equal(t, "0102030405060708", string(client.ParentSpanId), format)
+		assert.Equal(t, v1.Span_SPAN_KIND_CLIENT, client.Kind, format)
+		assert.Equal(t, &v1.Status{Code: v1.Status_STATUS_CODE_ERROR, Message: "timeout"}, client.Status, format)
+		attributes := map[string]string{}
+		for _, kv := range client.Attributes {
+			attributes[kv.Key] = kv.Value.GetStringValue()
+			if kv.Value.GetIntValue() != 0 {
+				attributes[kv.Key] = "int"
+			}
+		}
+		assert.Equal(t, "mysql", attributes["peer.service"], format)
+		assert.Equal(t, "10.0.0.1", attributes["net.peer.ip"], format)
+		require.Len(t, client.Events, 1, format)
+		assert.Equal(t, "exception", client.Events[0].Name, format)
+		assert.Equal(t, "timeout", client.Events[0].Attributes[0].Value.GetStringValue(), format)
+	}
+}
+
+func TestDecodeJaegerTrace(t *testing.T) {
+	// a single trace with 64-bit IDs as the Jaeger clients generate them
+	td, err := decodeTraces(traceFormatJaeger, []byte(`{"traceID":"AbC","spans":[{
+		"traceID":"abc","spanID":"1","operationName":"op","startTime":10,"duration":5,"processID":"p1",
+		"references":[{"refType":"FOLLOWS_FROM","traceID":"abc","spanID":"2"}],
+		"tags":[{"key":"error","type":"bool","value":true},{"key":"retries","type":"int64","value":9007199254740993}],
+		"logs":[{"timestamp":12,"fields":[{"key":"event","type":"string","value":"retry"}]}]
+	}],"processes":{"p1":{"serviceName":"backend","tags":[{"key":"host.name","type":"string","value":"h1"}]}}}`))
+	require.NoError(t, err)
+	require.Len(t, td.ResourceSpans, 1)
+	assert.Equal(t, "host.name", td.ResourceSpans[0].Resource.Attributes[1].Key)
+	span := td.ResourceSpans[0].ScopeSpans[0].Spans[0]
+	assert.Equal(t, "00000000000000000000000000000abc", string(span.TraceId))
+	assert.Equal(t, "0000000000000001", string(span.SpanId))
+	assert.Empty(t, span.ParentSpanId)
+	require.Len(t, span.Links, 1)
+	assert.Equal(t, "0000000000000002", string(span.Links[0].SpanId))
+	assert.Equal(t, uint64(15000), span.EndTimeUnixNano)
+	assert.Equal(t, v1.Status_STATUS_CODE_ERROR, span.Status.Code)
+	assert.Equal(t, int64(9007199254740993), span.Attributes[0].Value.GetIntValue())
+	assert.Equal(t, "retry", span.Events[0].Name)
+
+	_, err = decodeTraces(traceFormatJaeger, []byte(`{"data":[{"spans":[{"spanID":"1","processID":"p9"}]}]}`))
+	assert.ErrorContains(t, err, `unknown process "p9"`)
+}
+
+func TestDetectTraceFormat(t *testing.T) {
+	tests := []struct {
+		contentType string
+		content     string
+		format      traceFormat
+	}{
+		{contentType: contentTypeProtobuf, content: "{", format: traceFormatOTLP},
+		{content: "\n{\x12", format: traceFormatOTLP},
+		{content: ` {"resourceSpans":[]}`, format: traceFormatOTLPJSON},
+		{content: `{"data":[]}`, format: traceFormatJaeger},
+		{content: `[]`, format: traceFormatZipkin},
+	}
+	for _, tt := range tests {
+		format, err := detectTraceFormat(tt.contentType, []byte(tt.content))
+		require.NoError(t, err, tt.content)
+		assert.Equal(t, tt.format, format, tt.content)
+	}
+
+	for _, content := range []string{"", `{"foo":1}`, `{`} {
+		_, err := detectTraceFormat("", []byte(content))
+		assert.Error(t, err, content)
+	}
+}
+
+func newTraceFileRouter(query *mockQuery) *mux.Router {
+	router := mux.NewRouter()
+	h := &TraceFileHandler{
+		QueryService: &QueryService{TracingQuerySvc: query},
+		Limits:       Limits{MaxSpansPerTrace: 100},
+	}
+	h.RegisterRoutes(router)
+	return router
+}
+
+func TestTraceExport(t *testing.T) {
+	query := &mockQuery{traces: map[string]*v1.TracesData{mockTraceID: mockTrace()}}
+	router := newTraceFileRouter(query)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/apis/traces/v1alpha1/trace/"+mockTraceID+"/export?format=otlp", nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, contentTypeProtobuf, rec.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename=trace-`+mockTraceID+`.pb`, rec.Header().Get("Content-Disposition"))
+	td, err := decodeTraces(traceFormatOTLP, rec.Body.Bytes())
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(mockTrace(), td))
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/apis/traces/v1alpha1/export?format=zipkin&query.service_name=frontend", nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, `attachment; filename=traces.json`, rec.Header().Get("Content-Disposition"))
+	var spans []*zipkinSpan
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &spans))
+	assert.Len(t, spans, 2)
+	// the spans are exported from the search, the traces are not read one by one
+	assert.Equal(t, 1, query.searches)
+	assert.Equal(t, 1, query.gets)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/apis/traces/v1alpha1/trace/ffff/export", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/apis/traces/v1alpha1/trace/ffff'--/export", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/apis/traces/v1alpha1/trace/"+mockTraceID+"/export?format=csv", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestTraceImport(t *testing.T) {
+	router := newTraceFileRouter(&mockQuery{})
+	jaeger, err := encodeTraces(traceFormatJaeger, mockTrace())
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/apis/traces/v1alpha1/import", bytes.NewReader(jaeger)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var response struct {
+		Traces struct {
+			Traces []struct {
+				TraceID       string `json:"traceId"`
+				SpanCount     int    `json:"spanCount"`
+				OperationName string `json:"operationName"`
+			} `json:"traces"`
+		} `json:"traces"`
+		Data []json.RawMessage `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.Len(t, response.Traces.Traces, 1)
+	assert.Equal(t, mockTraceID, response.Traces.Traces[0].TraceID)
+	assert.Equal(t, 2, response.Traces.Traces[0].SpanCount)
+	assert.Equal(t, "GET /hello", response.Traces.Traces[0].OperationName)
+	require.Len(t, response.Data, 1)
+	assert.Contains(t, string(response.Data[0]), `"resourceSpans"`)
+
+	// an OTLP file uploaded from a form
+	otlp, err := encodeTraces(traceFormatOTLP, mockTrace())
+	require.NoError(t, err)
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	part, err := form.CreateFormFile("file", "trace.pb")
+	require.NoError(t, err)
+	_, err = part.Write(otlp)
+	require.NoError(t, err)
+	require.NoError(t, form.Close())
+	req := httptest.NewRequest(http.MethodPost, "/apis/traces/v1alpha1/import?format=otlp", body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Traces.Traces[0].SpanCount)
+
+	for _, content := range []string{`[]`, `{"resourceSpans":"x"}`, `not a trace`} {
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/apis/traces/v1alpha1/import", bytes.NewReader([]byte(content))))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, content)
+	}
+}
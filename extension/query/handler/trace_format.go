@@ -0,0 +1,254 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	v1_resource "go.opentelemetry.io/proto/otlp/resource/v1"
+	v1 "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// traceFormat is a file format of exported and imported traces.
+type traceFormat string
+
+const (
+	traceFormatOTLP     traceFormat = "otlp"
+	traceFormatOTLPJSON traceFormat = "otlp_json"
+	traceFormatJaeger   traceFormat = "jaeger"
+	traceFormatZipkin   traceFormat = "zipkin"
+)
+
+const (
+	contentTypeProtobuf = "application/x-protobuf"
+	contentTypeJSON     = "application/json"
+)
+
+// parseTraceFormat reads a format name, the default is OTLP/JSON.
+func parseTraceFormat(value string) (traceFormat, error) {
+	switch format := traceFormat(strings.ToLower(value)); format {
+	case "":
+		return traceFormatOTLPJSON, nil
+	case traceFormatOTLP, traceFormatOTLPJSON, traceFormatJaeger, traceFormatZipkin:
+		return format, nil
+	}
+	return "", status.Errorf(codes.InvalidArgument, "unknown trace format %q, use otlp, otlp_json, jaeger or zipkin", value)
+}
+
+// contentType is the media type of a file of the format.
+func (f traceFormat) contentType() string {
+	if f == traceFormatOTLP {
+		return contentTypeProtobuf
+	}
+	return contentTypeJSON
+}
+
+// fileExtension is the extension of a file of the format.
+func (f traceFormat) fileExtension() string {
+	if f == traceFormatOTLP {
+		return ".pb"
+	}
+	return ".json"
+}
+
+// detectTraceFormat guesses the format of a file from its content type and content: OTLP/JSON
+// has `resourceSpans`, Jaeger JSON has `data` or `spans`, Zipkin JSON is an array of spans, and
+// anything else is OTLP protobuf. The protobuf starts with 0x0a, a new line, so the content is
+// only read as JSON when it is valid JSON.
+func detectTraceFormat(contentType string, content []byte) (traceFormat, error) {
+	if strings.HasPrefix(contentType, contentTypeProtobuf) {
+		return traceFormatOTLP, nil
+	}
+	trimmed := bytes.TrimSpace(content)
+	if len(content) == 0 {
+		return "", status.Error(codes.InvalidArgument, "empty trace file")
+	}
+	if !json.Valid(trimmed) {
+		if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') && content[0] != '\n' {
+			return "", status.Error(codes.InvalidArgument, "invalid JSON trace file")
+		}
+		return traceFormatOTLP, nil
+	}
+	if trimmed[0] == '[' {
+		return traceFormatZipkin, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &fields); err == nil {
+		if _, ok := fields["resourceSpans"]; ok {
+			return traceFormatOTLPJSON, nil
+		}
+		if _, ok := fields["data"]; ok {
+			return traceFormatJaeger, nil
+		}
+		if _, ok := fields["spans"]; ok {
+			return traceFormatJaeger, nil
+		}
+	}
+	return "", status.Error(codes.InvalidArgument, "unknown JSON trace file, expected OTLP/JSON, Jaeger JSON or Zipkin JSON")
+}
+
+// encodeTraces writes the spans in the format.
+func encodeTraces(format traceFormat, td *v1.TracesData) ([]byte, error) {
+	switch format {
+	case traceFormatOTLP:
+		return proto.Marshal(toOTLPWire(td))
+	case traceFormatOTLPJSON:
+		b, err := proto.Marshal(toOTLPWire(td))
+		if err != nil {
+			return nil, err
+		}
+		traces, err := (&ptrace.ProtoUnmarshaler{}).UnmarshalTraces(b)
+		if err != nil {
+			return nil, err
+		}
+		return (&ptrace.JSONMarshaler{}).MarshalTraces(traces)
+	case traceFormatJaeger:
+		return json.Marshal(&jaegerTracesFile{Data: toJaegerTraces(td)})
+	case traceFormatZipkin:
+		spans := toZipkinSpans(td)
+		if spans == nil {
+			spans = []*zipkinSpan{}
+		}
+		return json.Marshal(spans)
+	}
+	return nil, status.Errorf(codes.InvalidArgument, "unknown trace format %q", format)
+}
+
+// decodeTraces reads the spans of a file in the format, its errors are InvalidArgument.
+func decodeTraces(format traceFormat, content []byte) (*v1.TracesData, error) {
+	td := &v1.TracesData{}
+	var err error
+	switch format {
+	case traceFormatOTLP:
+		if err = proto.Unmarshal(content, td); err == nil {
+			fromOTLPWire(td)
+		}
+	case traceFormatOTLPJSON:
+		var traces ptrace.Traces
+		if traces, err = (&ptrace.JSONUnmarshaler{}).UnmarshalTraces(content); err != nil {
+			break
+		}
+		var b []byte
+		if b, err = (&ptrace.ProtoMarshaler{}).MarshalTraces(traces); err != nil {
+			break
+		}
+		if err = proto.Unmarshal(b, td); err == nil {
+			fromOTLPWire(td)
+		}
+	case traceFormatJaeger:
+		td, err = decodeJaegerTraces(content)
+	case traceFormatZipkin:
+		var spans []*zipkinSpan
+		if err = json.Unmarshal(content, &spans); err == nil {
+			td = fromZipkinSpans(spans)
+		}
+	default:
+		err = status.Errorf(codes.InvalidArgument, "unknown trace format %q", format)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid %s trace file: %v", format, err)
+	}
+	return td, nil
+}
+
+// toOTLPWire copies the spans with their IDs as raw bytes, the datasources hold them as
+// hex strings.
+func toOTLPWire(td *v1.TracesData) *v1.TracesData {
+	wire := proto.Clone(td).(*v1.TracesData)
+	decode := func(id []byte) []byte {
+		if raw, err := hex.DecodeString(string(id)); err == nil {
+			return raw
+		}
+		return id
+	}
+	forEachSpan(wire, func(span *v1.Span) {
+		span.TraceId, span.SpanId, span.ParentSpanId = decode(span.TraceId), decode(span.SpanId), decode(span.ParentSpanId)
+		for _, link := range span.Links {
+			link.TraceId, link.SpanId = decode(link.TraceId), decode(link.SpanId)
+		}
+	})
+	return wire
+}
+
+// fromOTLPWire replaces the raw span IDs with hex strings in place.
+func fromOTLPWire(td *v1.TracesData) {
+	encode := func(id []byte) []byte {
+		if len(id) == 0 {
+			return nil
+		}
+		return []byte(hex.EncodeToString(id))
+	}
+	forEachSpan(td, func(span *v1.Span) {
+		span.TraceId, span.SpanId, span.ParentSpanId = encode(span.TraceId), encode(span.SpanId), encode(span.ParentSpanId)
+		for _, link := range span.Links {
+			link.TraceId, link.SpanId = encode(link.TraceId), encode(link.SpanId)
+		}
+	})
+}
+
+func forEachSpan(td *v1.TracesData, fn func(span *v1.Span)) {
+	for _, rs := range td.GetResourceSpans() {
+		for _, ss := range rs.GetScopeSpans() {
+			for _, span := range ss.GetSpans() {
+				fn(span)
+			}
+		}
+	}
+}
+
+// normalizeID lower cases a hex ID and pads it with zeros to size characters, as the 64-bit IDs
+// of Jaeger and Zipkin.
+func normalizeID(id string, size int) []byte {
+	if id == "" {
+		return nil
+	}
+	id = strings.ToLower(id)
+	if len(id) < size {
+		id = strings.Repeat("0", size-len(id)) + id
+	}
+	return []byte(id)
+}
+
+// splitTraces groups the spans by trace in the order the traces first appear, each resource
+// spans of the result holds the spans of a single trace as DocumentsTracesConvert expects.
+func splitTraces(td *v1.TracesData) []*v1.TracesData {
+	var traces []*v1.TracesData
+	byID := map[string]*v1.TracesData{}
+	for _, rs := range td.GetResourceSpans() {
+		resourceSpans := map[string]*v1.ResourceSpans{}
+		for _, ss := range rs.GetScopeSpans() {
+			scopeSpans := map[string]*v1.ScopeSpans{}
+			for _, span := range ss.GetSpans() {
+				traceID := string(span.TraceId)
+				trace, ok := byID[traceID]
+				if !ok {
+					trace = &v1.TracesData{}
+					byID[traceID] = trace
+					traces = append(traces, trace)
+				}
+				r, ok := resourceSpans[traceID]
+				if !ok {
+					r = &v1.ResourceSpans{Resource: rs.Resource, SchemaUrl: rs.SchemaUrl}
+					if r.Resource == nil {
+						r.Resource = &v1_resource.Resource{}
+					}
+					resourceSpans[traceID] = r
+					trace.ResourceSpans = append(trace.ResourceSpans, r)
+				}
+				s, ok := scopeSpans[traceID]
+				if !ok {
+					s = &v1.ScopeSpans{Scope: ss.Scope, SchemaUrl: ss.SchemaUrl}
+					scopeSpans[traceID] = s
+					r.ScopeSpans = append(r.ScopeSpans, s)
+				}
+				s.Spans = append(s.Spans, span)
+			}
+		}
+	}
+	return traces
+}
@@ -0,0 +1,810 @@
+package handler
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	semconv "go.opentelemetry.io/collector/semconv/v1.6.1"
+	v1_common "go.opentelemetry.io/proto/otlp/common/v1"
+	v1_resource "go.opentelemetry.io/proto/otlp/resource/v1"
+	v1 "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// The TraceQL subset supported by the Tempo API:
+//
+//	query      = spanset { ( "&&" | "||" | ">>" | ">" ) spanset }
+//	spanset    = "{" [ field ] "}" | "(" query ")"
+//	field      = comparison { ( "&&" | "||" ) comparison } | "(" field ")"
+//	comparison = attribute ( "=" | "!=" | ">" | ">=" | "<" | "<=" | "=~" | "!~" ) literal
+//	attribute  = "." name | "span." name | "resource." name | "name" | "duration" | "status" | "kind"
+//
+// Queries are evaluated in memory against the spans of the candidate traces, which the datasource
+// searches with the conditions of the query a span of every matching trace satisfies.
+// refs: https://grafana.com/docs/tempo/latest/traceql/
+
+type traceqlTokenKind int
+
+const (
+	tokenEOF traceqlTokenKind = iota
+	tokenOpenBrace
+	tokenCloseBrace
+	tokenOpenParen
+	tokenCloseParen
+	tokenOperator
+	tokenIdentifier
+	tokenString
+	tokenNumber
+)
+
+type traceqlToken struct {
+	kind  traceqlTokenKind
+	value string
+	pos   int
+}
+
+// traceqlOperators are ordered so the longest operator is matched first.
+var traceqlOperators = []string{"&&", "||", ">>", ">=", "<=", "!=", "=~", "!~", ">", "<", "="}
+
+func lexTraceQL(input string) ([]traceqlToken, error) {
+	var tokens []traceqlToken
+	for i := 0; i < len(input); {
+		c := input[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+			continue
+		case c == '{':
+			tokens = append(tokens, traceqlToken{kind: tokenOpenBrace, value: "{", pos: i})
+			i++
+			continue
+		case c == '}':
+			tokens = append(tokens, traceqlToken{kind: tokenCloseBrace, value: "}", pos: i})
+			i++
+			continue
+		case c == '(':
+			tokens = append(tokens, traceqlToken{kind: tokenOpenParen, value: "(", pos: i})
+			i++
+			continue
+		case c == ')':
+			tokens = append(tokens, traceqlToken{kind: tokenCloseParen, value: ")", pos: i})
+			i++
+			continue
+		case c == '"' || c == '`':
+			end := i + 1
+			for end < len(input) && input[end] != c {
+				if input[end] == '\\' && c == '"' {
+					end++
+				}
+				end++
+			}
+			if end >= len(input) {
+				return nil, fmt.Errorf("unterminated string at position %d", i)
+			}
+			value, err := strconv.Unquote(input[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at position %d: %w", i, err)
+			}
+			tokens = append(tokens, traceqlToken{kind: tokenString, value: value, pos: i})
+			i = end + 1
+			continue
+		case c >= '0' && c <= '9' || c == '-' && i+1 < len(input) && input[i+1] >= '0' && input[i+1] <= '9':
+			end := i + 1
+			for end < len(input) && (isTraceQLIdentifierChar(input[end]) && input[end] != '-') {
+				end++
+			}
+			tokens = append(tokens, traceqlToken{kind: tokenNumber, value: input[i:end], pos: i})
+			i = end
+			continue
+		case c == '.' || c == '_' || unicode.IsLetter(rune(c)):
+			end := i + 1
+			for end < len(input) && isTraceQLIdentifierChar(input[end]) {
+				end++
+			}
+			tokens = append(tokens, traceqlToken{kind: tokenIdentifier, value: input[i:end], pos: i})
+			i = end
+			continue
+		}
+
+		matched := false
+		for _, op := range traceqlOperators {
+			if strings.HasPrefix(input[i:], op) {
+				tokens = append(tokens, traceqlToken{kind: tokenOperator, value: op, pos: i})
+				i += len(op)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+		}
+	}
+	return append(tokens, traceqlToken{kind: tokenEOF, pos: len(input)}), nil
+}
+
+func isTraceQLIdentifierChar(c byte) bool {
+	return c == '.' || c == '_' || c == '-' || c == ':' || c == '/' ||
+		c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// traceqlSpan is a span of the evaluated trace with its resource and parent.
+type traceqlSpan struct {
+	index    int
+	span     *v1.Span
+	resource *v1_resource.Resource
+	parent   *traceqlSpan
+}
+
+// traceqlTrace indexes the spans of a trace for the evaluation.
+type traceqlTrace struct {
+	spans []*traceqlSpan
+}
+
+func newTraceQLTrace(td *v1.TracesData) *traceqlTrace {
+	t := &traceqlTrace{}
+	byID := map[string]*traceqlSpan{}
+	for _, rs := range td.GetResourceSpans() {
+		for _, ss := range rs.GetScopeSpans() {
+			for _, span := range ss.GetSpans() {
+				s := &traceqlSpan{index: len(t.spans), span: span, resource: rs.GetResource()}
+				t.spans = append(t.spans, s)
+				byID[string(span.SpanId)] = s
+			}
+		}
+	}
+	for _, s := range t.spans {
+		if len(s.span.ParentSpanId) > 0 {
+			s.parent = byID[string(s.span.ParentSpanId)]
+		}
+	}
+	return t
+}
+
+// spansetExpr evaluates to the spans of a trace it selects, marked by their index.
+type spansetExpr interface {
+	eval(t *traceqlTrace) []bool
+}
+
+type spansetFilter struct {
+	// field is nil for `{}`, which selects every span.
+	field fieldExpr
+}
+
+func (f *spansetFilter) eval(t *traceqlTrace) []bool {
+	set := make([]bool, len(t.spans))
+	for _, s := range t.spans {
+		set[s.index] = f.field == nil || f.field.match(s)
+	}
+	return set
+}
+
+type spansetOperation struct {
+	op          string
+	left, right spansetExpr
+}
+
+func (o *spansetOperation) eval(t *traceqlTrace) []bool {
+	left, right := o.left.eval(t), o.right.eval(t)
+	set := make([]bool, len(t.spans))
+	switch o.op {
+	case "&&":
+		if !anySpan(left) || !anySpan(right) {
+			return set
+		}
+		for i := range set {
+			set[i] = left[i] || right[i]
+		}
+	case "||":
+		for i := range set {
+			set[i] = left[i] || right[i]
+		}
+	case ">":
+		for _, s := range t.spans {
+			set[s.index] = right[s.index] && s.parent != nil && left[s.parent.index]
+		}
+	case ">>":
+		for _, s := range t.spans {
+			if !right[s.index] {
+				continue
+			}
+			// the depth is bounded in case the parent references of a corrupted trace form a cycle
+			for p, depth := s.parent, 0; p != nil && depth < len(t.spans); p, depth = p.parent, depth+1 {
+				if left[p.index] {
+					set[s.index] = true
+					break
+				}
+			}
+		}
+	}
+	return set
+}
+
+func anySpan(set []bool) bool {
+	for _, selected := range set {
+		if selected {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldExpr is a condition on a single span.
+type fieldExpr interface {
+	match(s *traceqlSpan) bool
+}
+
+type fieldOperation struct {
+	op          string
+	left, right fieldExpr
+}
+
+func (o *fieldOperation) match(s *traceqlSpan) bool {
+	if o.op == "&&" {
+		return o.left.match(s) && o.right.match(s)
+	}
+	return o.left.match(s) || o.right.match(s)
+}
+
+const (
+	intrinsicName     = "name"
+	intrinsicDuration = "duration"
+	intrinsicStatus   = "status"
+	intrinsicKind     = "kind"
+
+	scopeSpan     = "span"
+	scopeResource = "resource"
+)
+
+type traceqlAttribute struct {
+	// scope is empty for an unscoped attribute, which is looked up in the span then in the resource.
+	scope     string
+	name      string
+	intrinsic string
+}
+
+type literalKind int
+
+const (
+	literalString literalKind = iota
+	literalNumber
+	literalDuration
+	literalBool
+	literalStatus
+	literalSpanKind
+)
+
+type traceqlLiteral struct {
+	kind   literalKind
+	str    string
+	number float64
+	status v1.Status_StatusCode
+	span   v1.Span_SpanKind
+	regexp *regexp.Regexp
+}
+
+var traceqlStatuses = map[string]v1.Status_StatusCode{
+	"unset": v1.Status_STATUS_CODE_UNSET,
+	"ok":    v1.Status_STATUS_CODE_OK,
+	"error": v1.Status_STATUS_CODE_ERROR,
+}
+
+var traceqlKinds = map[string]v1.Span_SpanKind{
+	"unspecified": v1.Span_SPAN_KIND_UNSPECIFIED,
+	"internal":    v1.Span_SPAN_KIND_INTERNAL,
+	"server":      v1.Span_SPAN_KIND_SERVER,
+	"client":      v1.Span_SPAN_KIND_CLIENT,
+	"producer":    v1.Span_SPAN_KIND_PRODUCER,
+	"consumer":    v1.Span_SPAN_KIND_CONSUMER,
+}
+
+type comparison struct {
+	attribute traceqlAttribute
+	op        string
+	literal   traceqlLiteral
+}
+
+func (c *comparison) match(s *traceqlSpan) bool {
+	switch c.attribute.intrinsic {
+	case intrinsicName:
+		return compareString(s.span.Name, c.op, &c.literal)
+	case intrinsicDuration:
+		if s.span.EndTimeUnixNano < s.span.StartTimeUnixNano {
+			return false
+		}
+		return compareNumber(float64(s.span.EndTimeUnixNano-s.span.StartTimeUnixNano), c.op, c.literal.number)
+	case intrinsicStatus:
+		return (s.span.GetStatus().GetCode() == c.literal.status) == (c.op == "=")
+	case intrinsicKind:
+		return (s.span.Kind == c.literal.span) == (c.op == "=")
+	}
+
+	value, ok := c.lookup(s)
+	if !ok {
+		return false
+	}
+	switch c.literal.kind {
+	case literalNumber, literalDuration:
+		var number float64
+		switch v := value.GetValue().(type) {
+		case *v1_common.AnyValue_IntValue:
+			number = float64(v.IntValue)
+		case *v1_common.AnyValue_DoubleValue:
+			number = v.DoubleValue
+		default:
+			n, err := strconv.ParseFloat(datasource.AnyValueString(value), 64)
+			if err != nil {
+				return false
+			}
+			number = n
+		}
+		return compareNumber(number, c.op, c.literal.number)
+	default:
+		return compareString(datasource.AnyValueString(value), c.op, &c.literal)
+	}
+}
+
+func (c *comparison) lookup(s *traceqlSpan) (*v1_common.AnyValue, bool) {
+	if c.attribute.scope != scopeResource {
+		for _, kv := range s.span.Attributes {
+			if kv.Key == c.attribute.name {
+				return kv.Value, true
+			}
+		}
+	}
+	if c.attribute.scope != scopeSpan {
+		for _, kv := range s.resource.GetAttributes() {
+			if kv.Key == c.attribute.name {
+				return kv.Value, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func compareString(value, op string, literal *traceqlLiteral) bool {
+	switch op {
+	case "=":
+		return value == literal.str
+	case "!=":
+		return value != literal.str
+	case "=~":
+		return literal.regexp.MatchString(value)
+	case "!~":
+		return !literal.regexp.MatchString(value)
+	case ">":
+		return value > literal.str
+	case ">=":
+		return value >= literal.str
+	case "<":
+		return value < literal.str
+	case "<=":
+		return value <= literal.str
+	}
+	return false
+}
+
+func compareNumber(value float64, op string, literal float64) bool {
+	switch op {
+	case "=":
+		return value == literal
+	case "!=":
+		return value != literal
+	case ">":
+		return value > literal
+	case ">=":
+		return value >= literal
+	case "<":
+		return value < literal
+	case "<=":
+		return value <= literal
+	}
+	return false
+}
+
+// traceqlQuery is a parsed TraceQL query.
+type traceqlQuery struct {
+	expr spansetExpr
+	// attributes are the non intrinsic attributes the query compares, returned with the matched spans.
+	attributes []traceqlAttribute
+}
+
+// matchedSpans returns the spans of the trace selected by the query, none if the trace doesn't match.
+func (q *traceqlQuery) matchedSpans(t *traceqlTrace) []*traceqlSpan {
+	var spans []*traceqlSpan
+	for i, selected := range q.expr.eval(t) {
+		if selected {
+			spans = append(spans, t.spans[i])
+		}
+	}
+	return spans
+}
+
+// pushdown narrows params to the traces having a span that satisfies the conditions shared by every
+// span the query selects, so the datasource searches the candidate traces instead of the recent ones.
+func (q *traceqlQuery) pushdown(params *datasource.TraceQueryParameters) {
+	p := pushdownSpanset(q.expr)
+	if p.serviceName != "" {
+		params.ServiceName = p.serviceName
+	}
+	if p.spanName != "" {
+		params.OperationName = p.spanName
+	}
+	if p.statusCode != "" {
+		params.StatusCode = p.statusCode
+	}
+	for key, value := range p.tags {
+		if params.Tags == nil {
+			params.Tags = map[string]string{}
+		}
+		params.Tags[key] = value
+	}
+	if p.durationMin > 0 && (params.DurationMin == nil || params.DurationMin.AsDuration() < p.durationMin) {
+		params.DurationMin = durationpb.New(p.durationMin)
+	}
+	if p.durationMax > 0 && (params.DurationMax == nil || params.DurationMax.AsDuration() > p.durationMax) {
+		params.DurationMax = durationpb.New(p.durationMax)
+	}
+}
+
+// traceqlPushdown are the conditions a span of every matching trace satisfies, zero values are unset.
+type traceqlPushdown struct {
+	serviceName string
+	spanName    string
+	statusCode  string
+	tags        map[string]string
+	durationMin time.Duration
+	durationMax time.Duration
+}
+
+func (p *traceqlPushdown) empty() bool {
+	return p.serviceName == "" && p.spanName == "" && p.statusCode == "" && len(p.tags) == 0 &&
+		p.durationMin == 0 && p.durationMax == 0
+}
+
+// pushdownSpanset returns the conditions of a single spanset, as the spansets of a trace may select
+// different spans.
+func pushdownSpanset(expr spansetExpr) *traceqlPushdown {
+	switch e := expr.(type) {
+	case *spansetFilter:
+		p := &traceqlPushdown{tags: map[string]string{}}
+		pushdownField(e.field, p)
+		return p
+	case *spansetOperation:
+		if e.op == "||" {
+			return &traceqlPushdown{}
+		}
+		if p := pushdownSpanset(e.left); !p.empty() {
+			return p
+		}
+		return pushdownSpanset(e.right)
+	}
+	return &traceqlPushdown{}
+}
+
+func pushdownField(expr fieldExpr, p *traceqlPushdown) {
+	switch e := expr.(type) {
+	case *fieldOperation:
+		if e.op == "&&" {
+			pushdownField(e.left, p)
+			pushdownField(e.right, p)
+		}
+	case *comparison:
+		switch e.attribute.intrinsic {
+		case intrinsicName:
+			if e.op == "=" && e.literal.kind == literalString {
+				p.spanName = e.literal.str
+			}
+		case intrinsicDuration:
+			pushdownDuration(e.op, e.literal.number, p)
+		case intrinsicStatus:
+			if e.op == "=" {
+				p.statusCode = e.literal.status.String()
+			}
+		case "":
+			if e.op != "=" {
+				return
+			}
+			if e.attribute.name == semconv.AttributeServiceName && e.attribute.scope != scopeSpan && e.literal.kind == literalString {
+				p.serviceName = e.literal.str
+				return
+			}
+			if value, ok := tagValue(&e.literal); ok {
+				p.tags[e.attribute.name] = value
+			}
+		}
+	}
+}
+
+// pushdownDuration keeps the narrowest bounds of the span duration, in nanoseconds.
+func pushdownDuration(op string, nanos float64, p *traceqlPushdown) {
+	if op == "=" || op == ">" || op == ">=" {
+		if d := time.Duration(math.Floor(nanos)); d > p.durationMin {
+			p.durationMin = d
+		}
+	}
+	if op == "=" || op == "<" || op == "<=" {
+		if d := time.Duration(math.Ceil(nanos)); d > 0 && (p.durationMax == 0 || d < p.durationMax) {
+			p.durationMax = d
+		}
+	}
+}
+
+// tagValue returns the literal as the string of a tag filter, the datasources compare the tags as strings
+// so only the literals with a single string form are pushed down.
+func tagValue(literal *traceqlLiteral) (string, bool) {
+	switch literal.kind {
+	case literalString, literalBool:
+		return literal.str, true
+	case literalNumber:
+		if literal.number == math.Trunc(literal.number) && math.Abs(literal.number) < 1<<53 {
+			return strconv.FormatFloat(literal.number, 'f', -1, 64), true
+		}
+	}
+	return "", false
+}
+
+// parseTraceQL parses a query of the supported TraceQL subset, errors are InvalidArgument.
+func parseTraceQL(input string) (*traceqlQuery, error) {
+	tokens, err := lexTraceQL(input)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid TraceQL query: %v", err)
+	}
+	p := &traceqlParser{tokens: tokens, query: &traceqlQuery{}}
+	expr, err := p.parseSpansetOr()
+	if err == nil && p.peek().kind != tokenEOF {
+		err = p.unexpected()
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid TraceQL query: %v", err)
+	}
+	p.query.expr = expr
+	return p.query, nil
+}
+
+type traceqlParser struct {
+	tokens []traceqlToken
+	pos    int
+	query  *traceqlQuery
+}
+
+func (p *traceqlParser) peek() traceqlToken {
+	return p.tokens[p.pos]
+}
+
+func (p *traceqlParser) next() traceqlToken {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *traceqlParser) isOperator(ops ...string) bool {
+	t := p.peek()
+	if t.kind != tokenOperator {
+		return false
+	}
+	for _, op := range ops {
+		if t.value == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *traceqlParser) unexpected() error {
+	t := p.peek()
+	if t.kind == tokenEOF {
+		return fmt.Errorf("unexpected end of query")
+	}
+	return fmt.Errorf("unexpected %q at position %d", t.value, t.pos)
+}
+
+func (p *traceqlParser) expect(kind traceqlTokenKind) error {
+	if p.peek().kind != kind {
+		return p.unexpected()
+	}
+	p.next()
+	return nil
+}
+
+func (p *traceqlParser) parseSpansetOr() (spansetExpr, error) {
+	left, err := p.parseSpansetAnd()
+	for err == nil && p.isOperator("||") {
+		op := p.next().value
+		var right spansetExpr
+		if right, err = p.parseSpansetAnd(); err == nil {
+			left = &spansetOperation{op: op, left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *traceqlParser) parseSpansetAnd() (spansetExpr, error) {
+	left, err := p.parseStructural()
+	for err == nil && p.isOperator("&&") {
+		op := p.next().value
+		var right spansetExpr
+		if right, err = p.parseStructural(); err == nil {
+			left = &spansetOperation{op: op, left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *traceqlParser) parseStructural() (spansetExpr, error) {
+	left, err := p.parseSpanset()
+	for err == nil && p.isOperator(">>", ">") {
+		op := p.next().value
+		var right spansetExpr
+		if right, err = p.parseSpanset(); err == nil {
+			left = &spansetOperation{op: op, left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *traceqlParser) parseSpanset() (spansetExpr, error) {
+	switch p.peek().kind {
+	case tokenOpenParen:
+		p.next()
+		expr, err := p.parseSpansetOr()
+		if err != nil {
+			return nil, err
+		}
+		return expr, p.expect(tokenCloseParen)
+	case tokenOpenBrace:
+		p.next()
+		if p.peek().kind == tokenCloseBrace {
+			p.next()
+			return &spansetFilter{}, nil
+		}
+		field, err := p.parseFieldOr()
+		if err != nil {
+			return nil, err
+		}
+		return &spansetFilter{field: field}, p.expect(tokenCloseBrace)
+	}
+	return nil, p.unexpected()
+}
+
+func (p *traceqlParser) parseFieldOr() (fieldExpr, error) {
+	left, err := p.parseFieldAnd()
+	for err == nil && p.isOperator("||") {
+		op := p.next().value
+		var right fieldExpr
+		if right, err = p.parseFieldAnd(); err == nil {
+			left = &fieldOperation{op: op, left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *traceqlParser) parseFieldAnd() (fieldExpr, error) {
+	left, err := p.parseField()
+	for err == nil && p.isOperator("&&") {
+		op := p.next().value
+		var right fieldExpr
+		if right, err = p.parseField(); err == nil {
+			left = &fieldOperation{op: op, left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *traceqlParser) parseField() (fieldExpr, error) {
+	if p.peek().kind == tokenOpenParen {
+		p.next()
+		expr, err := p.parseFieldOr()
+		if err != nil {
+			return nil, err
+		}
+		return expr, p.expect(tokenCloseParen)
+	}
+	return p.parseComparison()
+}
+
+func (p *traceqlParser) parseComparison() (fieldExpr, error) {
+	if p.peek().kind != tokenIdentifier {
+		return nil, p.unexpected()
+	}
+	attribute, err := parseTraceQLAttribute(p.next().value)
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenOperator || p.isOperator("&&", "||", ">>") {
+		return nil, p.unexpected()
+	}
+	op := p.next().value
+	literal, err := p.parseLiteral(attribute, op)
+	if err != nil {
+		return nil, err
+	}
+	if attribute.intrinsic == "" {
+		p.query.attributes = append(p.query.attributes, attribute)
+	}
+	return &comparison{attribute: attribute, op: op, literal: literal}, nil
+}
+
+func parseTraceQLAttribute(value string) (traceqlAttribute, error) {
+	switch {
+	case strings.HasPrefix(value, "."):
+		return traceqlAttribute{name: value[1:]}, nil
+	case strings.HasPrefix(value, scopeSpan+"."):
+		return traceqlAttribute{scope: scopeSpan, name: strings.TrimPrefix(value, scopeSpan+".")}, nil
+	case strings.HasPrefix(value, scopeResource+"."):
+		return traceqlAttribute{scope: scopeResource, name: strings.TrimPrefix(value, scopeResource+".")}, nil
+	}
+	switch value {
+	case intrinsicName, intrinsicDuration, intrinsicStatus, intrinsicKind:
+		return traceqlAttribute{intrinsic: value}, nil
+	}
+	return traceqlAttribute{}, fmt.Errorf("unknown intrinsic %q, attributes start with '.', 'span.' or 'resource.'", value)
+}
+
+func (p *traceqlParser) parseLiteral(attribute traceqlAttribute, op string) (traceqlLiteral, error) {
+	t := p.next()
+	var literal traceqlLiteral
+	switch t.kind {
+	case tokenString:
+		literal = traceqlLiteral{kind: literalString, str: t.value}
+	case tokenNumber:
+		if number, err := strconv.ParseFloat(t.value, 64); err == nil {
+			literal = traceqlLiteral{kind: literalNumber, number: number, str: t.value}
+		} else if d, err := time.ParseDuration(t.value); err == nil {
+			literal = traceqlLiteral{kind: literalDuration, number: float64(d), str: t.value}
+		} else {
+			return literal, fmt.Errorf("invalid number %q at position %d", t.value, t.pos)
+		}
+	case tokenIdentifier:
+		if s, ok := traceqlStatuses[t.value]; ok {
+			literal = traceqlLiteral{kind: literalStatus, status: s, str: t.value}
+		} else if k, ok := traceqlKinds[t.value]; ok {
+			literal = traceqlLiteral{kind: literalSpanKind, span: k, str: t.value}
+		} else if t.value == "true" || t.value == "false" {
+			literal = traceqlLiteral{kind: literalBool, str: t.value}
+		} else {
+			return literal, fmt.Errorf("unexpected %q at position %d", t.value, t.pos)
+		}
+	default:
+		p.pos--
+		return literal, p.unexpected()
+	}
+
+	switch attribute.intrinsic {
+	case intrinsicDuration:
+		if literal.kind != literalDuration {
+			return literal, fmt.Errorf("duration must be compared to a duration such as 100ms, got %q", literal.str)
+		}
+		return literal, nil
+	case intrinsicStatus, intrinsicKind:
+		if op != "=" && op != "!=" {
+			return literal, fmt.Errorf("%s only supports = and !=", attribute.intrinsic)
+		}
+		if attribute.intrinsic == intrinsicStatus && literal.kind != literalStatus ||
+			attribute.intrinsic == intrinsicKind && literal.kind != literalSpanKind {
+			return literal, fmt.Errorf("invalid %s %q", attribute.intrinsic, literal.str)
+		}
+		return literal, nil
+	}
+
+	if op == "=~" || op == "!~" {
+		if literal.kind != literalString {
+			return literal, fmt.Errorf("%s must be followed by a string", op)
+		}
+		re, err := regexp.Compile("^(?:" + literal.str + ")$")
+		if err != nil {
+			return literal, fmt.Errorf("invalid regular expression %q: %w", literal.str, err)
+		}
+		literal.regexp = re
+	}
+	return literal, nil
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestTraceQLMatch(t *testing.T) {
+	trace := newTraceQLTrace(mockTrace())
+
+	tests := []struct {
+		query string
+		spans []string
+	}{
+		{query: `{}`, spans: []string{"GET /hello", "SELECT"}},
+		{query: `{ .http.method = "GET" }`, spans: []string{"GET /hello"}},
+		{query: `{ span.http.method = "GET" }`, spans: []string{"GET /hello"}},
+		{query: `{ resource.http.method = "GET" }`},
+		{query: `{ resource.service.name = "frontend" && kind = client }`, spans: []string{"SELECT"}},
+		{query: `{ .service.name = "frontend" && (name = "nope" || status = error) }`, spans: []string{"SELECT"}},
+		{query: `{ .net.peer.port >= 3306 && .net.peer.port < 3307 }`, spans: []string{"SELECT"}},
+		{query: `{ duration > 1500us }`, spans: []string{"GET /hello"}},
+		{query: `{ duration <= 1ms }`, spans: []string{"SELECT"}},
+		{query: `{ name =~ "GET .*" }`, spans: []string{"GET /hello"}},
+		{query: `{ name =~ "GET" }`},
+		{query: `{ name !~ "GET .*" }`, spans: []string{"SELECT"}},
+		{query: `{ .missing != "x" }`},
+		{query: `{ status != ok }`, spans: []string{"GET /hello", "SELECT"}},
+		{query: `{ kind = server } >> { status = error }`, spans: []string{"SELECT"}},
+		{query: `{ kind = server } > { status = error }`, spans: []string{"SELECT"}},
+		{query: `{ kind = client } >> { }`},
+		{query: `{ kind = server } && { status = error }`, spans: []string{"GET /hello", "SELECT"}},
+		{query: `{ kind = server } && { kind = consumer }`},
+		{query: `({ kind = consumer } || { kind = server }) && { }`, spans: []string{"GET /hello", "SELECT"}},
+		{query: `{ kind = consumer } || { name = "SELECT" }`, spans: []string{"SELECT"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			query, err := parseTraceQL(tt.query)
+			require.NoError(t, err)
+			var names []string
+			for _, s := range query.matchedSpans(trace) {
+				names = append(names, s.span.Name)
+			}
+			assert.Equal(t, tt.spans, names)
+		})
+	}
+}
+
+func TestTraceQLParseErrors(t *testing.T) {
+	for _, query := range []string{
+		``,
+		`{`,
+		`{ .a = }`,
+		`{ .a "b" }`,
+		`{ foo = "b" }`,
+		`{ duration > 10 }`,
+		`{ status = bad }`,
+		`{ kind > server }`,
+		`{ .a =~ 1 }`,
+		`{ .a =~ "(" }`,
+		`{ .a = "b }`,
+		`{ .a = "b" } {}`,
+		`{ .a = "b" } | count() > 1`,
+	} {
+		t.Run(query, func(t *testing.T) {
+			_, err := parseTraceQL(query)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+}
+
+func TestTraceQLPushdown(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected *datasource.TraceQueryParameters
+	}{
+		{
+			query:    `{ resource.service.name = "frontend" && name = "GET /hello" }`,
+			expected: &datasource.TraceQueryParameters{ServiceName: "frontend", OperationName: "GET /hello"},
+		},
+		{
+			query:    `{ .service.name = "frontend" } >> { name = "SELECT" }`,
+			expected: &datasource.TraceQueryParameters{ServiceName: "frontend"},
+		},
+		{
+			query:    `{ } && { name = "SELECT" }`,
+			expected: &datasource.TraceQueryParameters{OperationName: "SELECT"},
+		},
+		{
+			query:    `{ span.http.status_code = 500 && .db.system = "mysql" && span.cached = true && .ratio = 0.5 }`,
+			expected: &datasource.TraceQueryParameters{Tags: map[string]string{"http.status_code": "500", "db.system": "mysql", "cached": "true"}},
+		},
+		{
+			query: `{ duration > 2s && duration <= 1m && duration >= 1s }`,
+			expected: &datasource.TraceQueryParameters{
+				DurationMin: durationpb.New(2 * time.Second),
+				DurationMax: durationpb.New(time.Minute),
+			},
+		},
+		{
+			query:    `{ status = error && kind = server }`,
+			expected: &datasource.TraceQueryParameters{StatusCode: "STATUS_CODE_ERROR"},
+		},
+		{query: `{ span.service.name = "frontend" }`, expected: &datasource.TraceQueryParameters{Tags: map[string]string{"service.name": "frontend"}}},
+		{query: `{ .service.name = "frontend" || name = "SELECT" }`, expected: &datasource.TraceQueryParameters{}},
+		{query: `{ .service.name = "frontend" } || { name = "SELECT" }`, expected: &datasource.TraceQueryParameters{}},
+		{query: `{ .service.name =~ "front.*" && .a != "b" && status != ok }`, expected: &datasource.TraceQueryParameters{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			query, err := parseTraceQL(tt.query)
+			require.NoError(t, err)
+			params := &datasource.TraceQueryParameters{}
+			query.pushdown(params)
+			assert.Equal(t, tt.expected, params)
+		})
+	}
+
+	// the narrowest duration of the query and the search parameters is kept
+	query, err := parseTraceQL(`{ duration > 2s }`)
+	require.NoError(t, err)
+	params := &datasource.TraceQueryParameters{DurationMin: durationpb.New(5 * time.Second), DurationMax: durationpb.New(time.Minute)}
+	query.pushdown(params)
+	assert.Equal(t, 5*time.Second, params.DurationMin.AsDuration())
+	assert.Equal(t, time.Minute, params.DurationMax.AsDuration())
+}
@@ -0,0 +1,283 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	semconv "go.opentelemetry.io/collector/semconv/v1.6.1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	zipkinDefaultLimit = 10
+	zipkinAllSpanNames = "all"
+)
+
+// ZipkinHandler serves the read endpoints of the Zipkin v2 API from the tracing datasource.
+// refs: https://zipkin.io/zipkin-api/
+type ZipkinHandler struct {
+	QueryService *QueryService
+	Limits       Limits
+	// Available reports whether the tracing datasource can serve queries, optional.
+	Available func() error
+}
+
+// RegisterRoutes adds the Zipkin v2 endpoints under `/api/v2` to router.
+func (z *ZipkinHandler) RegisterRoutes(router *mux.Router) {
+	r := router.PathPrefix("/api/v2").Subrouter()
+	r.HandleFunc("/services", z.getServices).Methods(http.MethodGet)
+	r.HandleFunc("/spans", z.getSpanNames).Methods(http.MethodGet)
+	r.HandleFunc("/traces", z.getTraces).Methods(http.MethodGet)
+	r.HandleFunc("/trace/{traceId}", z.getTrace).Methods(http.MethodGet)
+	r.HandleFunc("/dependencies", z.getDependencies).Methods(http.MethodGet)
+}
+
+func (z *ZipkinHandler) getServices(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel, err := beginRequest(r, z.Limits, z.Available)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer cancel()
+
+	resources, err := z.QueryService.Tracing().GetService(ctx)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	services := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		for _, kv := range resource.GetAttributes() {
+			if kv.Key == semconv.AttributeServiceName {
+				services = append(services, datasource.AnyValueString(kv.Value))
+			}
+		}
+	}
+	writeJSON(w, services)
+}
+
+func (z *ZipkinHandler) getSpanNames(w http.ResponseWriter, r *http.Request) {
+	serviceName := r.URL.Query().Get("serviceName")
+	if serviceName == "" {
+		writeError(w, status.Error(codes.InvalidArgument, "serviceName is required"))
+		return
+	}
+	ctx, cancel, err := beginRequest(r, z.Limits, z.Available)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer cancel()
+
+	names, err := z.QueryService.Tracing().GetOperations(ctx, &datasource.OperationsQueryParameters{ServiceName: serviceName})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if names == nil {
+		names = []string{}
+	}
+	writeJSON(w, names)
+}
+
+func (z *ZipkinHandler) getTraces(w http.ResponseWriter, r *http.Request) {
+	params, err := parseZipkinQueryParameters(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err = z.Limits.applyTraceQuery(params, time.Now()); err != nil {
+		writeError(w, err)
+		return
+	}
+	ctx, cancel, err := beginRequest(r, z.Limits, z.Available)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer cancel()
+
+	found, err := z.QueryService.Tracing().FindTraces(ctx, params)
+	if err != nil {
+		writeError(w, spanLimitError(err))
+		return
+	}
+	traces := [][]*zipkinSpan{}
+	for _, trace := range splitTraces(found) {
+		if err = z.Limits.checkTraceSpans(trace); err != nil {
+			writeError(w, err)
+			return
+		}
+		if spans := toZipkinSpans(trace); len(spans) > 0 {
+			traces = append(traces, spans)
+		}
+	}
+	writeJSON(w, traces)
+}
+
+func (z *ZipkinHandler) getTrace(w http.ResponseWriter, r *http.Request) {
+	traceID, err := normalizeTraceID(mux.Vars(r)["traceId"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	ctx, cancel, err := beginRequest(r, z.Limits, z.Available)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer cancel()
+
+	trace, err := z.QueryService.GetTrace(ctx, traceID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if err = z.Limits.checkTraceSpans(trace); err != nil {
+		writeError(w, err)
+		return
+	}
+	spans := toZipkinSpans(trace)
+	if len(spans) == 0 {
+		writeError(w, status.Errorf(codes.NotFound, "trace %s not found", traceID))
+		return
+	}
+	writeJSON(w, spans)
+}
+
+// getDependencies answers with the dependency links of the tracing datasource, no links when it
+// doesn't store them.
+func (z *ZipkinHandler) getDependencies(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	endTs, err := parseZipkinInt(query.Get("endTs"), "endTs")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if endTs == 0 {
+		writeError(w, status.Error(codes.InvalidArgument, "endTs is required"))
+		return
+	}
+	lookback, err := parseZipkinInt(query.Get("lookback"), "lookback")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	end := time.UnixMilli(endTs)
+	var start time.Time
+	if lookback > 0 {
+		start = end.Add(-time.Duration(lookback) * time.Millisecond)
+	}
+	if err = z.Limits.applyRecentTimeRange(&start, &end, time.Now()); err != nil {
+		writeError(w, err)
+		return
+	}
+	ctx, cancel, err := beginRequest(r, z.Limits, z.Available)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer cancel()
+
+	dependencies := []*zipkinDependencyLink{}
+	if reader := z.QueryService.Dependencies(); reader != nil {
+		links, err := reader.GetDependencies(ctx, end, end.Sub(start))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		for _, link := range links {
+			dependencies = append(dependencies, &zipkinDependencyLink{Parent: link.Parent, Child: link.Child, CallCount: link.CallCount})
+		}
+	}
+	writeJSON(w, dependencies)
+}
+
+// parseZipkinQueryParameters translates the `/api/v2/traces` query parameters.
+func parseZipkinQueryParameters(r *http.Request) (*datasource.TraceQueryParameters, error) {
+	query := r.URL.Query()
+	params := &datasource.TraceQueryParameters{
+		ServiceName: query.Get("serviceName"),
+		Tags:        map[string]string{},
+		NumTraces:   zipkinDefaultLimit,
+	}
+	if spanName := query.Get("spanName"); spanName != zipkinAllSpanNames {
+		params.OperationName = spanName
+	}
+
+	// a term is either key=value or a bare key matching the spans having the tag
+	if annotationQuery := query.Get("annotationQuery"); annotationQuery != "" {
+		for _, term := range strings.Split(annotationQuery, " and ") {
+			key, value, ok := strings.Cut(strings.TrimSpace(term), "=")
+			if key == "" {
+				return nil, status.Errorf(codes.InvalidArgument, "annotationQuery term %q has no key", term)
+			}
+			if !ok {
+				params.TagKeys = append(params.TagKeys, key)
+				continue
+			}
+			params.Tags[key] = value
+		}
+	}
+
+	minDuration, err := parseZipkinInt(query.Get("minDuration"), "minDuration")
+	if err != nil {
+		return nil, err
+	}
+	if minDuration > 0 {
+		params.DurationMin = durationpb.New(time.Duration(minDuration) * time.Microsecond)
+	}
+	maxDuration, err := parseZipkinInt(query.Get("maxDuration"), "maxDuration")
+	if err != nil {
+		return nil, err
+	}
+	if maxDuration > 0 {
+		params.DurationMax = durationpb.New(time.Duration(maxDuration) * time.Microsecond)
+	}
+
+	endTs, err := parseZipkinInt(query.Get("endTs"), "endTs")
+	if err != nil {
+		return nil, err
+	}
+	if endTs > 0 {
+		params.EndTime = time.UnixMilli(endTs)
+	}
+	lookback, err := parseZipkinInt(query.Get("lookback"), "lookback")
+	if err != nil {
+		return nil, err
+	}
+	if lookback > 0 {
+		end := params.EndTime
+		if end.IsZero() {
+			end = time.Now()
+		}
+		params.EndTime = end
+		params.StartTime = end.Add(-time.Duration(lookback) * time.Millisecond)
+	}
+
+	limit, err := parseZipkinInt(query.Get("limit"), "limit")
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 {
+		params.NumTraces = int(limit)
+	}
+	return params, nil
+}
+
+func parseZipkinInt(value, name string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	i, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || i < 0 {
+		return 0, status.Errorf(codes.InvalidArgument, "%s must be a positive integer: %q", name, value)
+	}
+	return i, nil
+}
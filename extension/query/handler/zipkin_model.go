@@ -0,0 +1,329 @@
+package handler
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	semconv "go.opentelemetry.io/collector/semconv/v1.6.1"
+	v1_common "go.opentelemetry.io/proto/otlp/common/v1"
+	v1_resource "go.opentelemetry.io/proto/otlp/resource/v1"
+	v1 "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// zipkinSpan is the Zipkin v2 span model.
+// refs: https://zipkin.io/zipkin-api/#/default/post_spans
+type zipkinSpan struct {
+	TraceID        string             `json:"traceId"`
+	ID             string             `json:"id"`
+	ParentID       string             `json:"parentId,omitempty"`
+	Name           string             `json:"name,omitempty"`
+	Kind           string             `json:"kind,omitempty"`
+	Timestamp      uint64             `json:"timestamp,omitempty"`
+	Duration       uint64             `json:"duration,omitempty"`
+	LocalEndpoint  *zipkinEndpoint    `json:"localEndpoint,omitempty"`
+	RemoteEndpoint *zipkinEndpoint    `json:"remoteEndpoint,omitempty"`
+	Annotations    []zipkinAnnotation `json:"annotations,omitempty"`
+	Tags           map[string]string  `json:"tags,omitempty"`
+}
+
+type zipkinEndpoint struct {
+	ServiceName string `json:"serviceName,omitempty"`
+	IPv4        string `json:"ipv4,omitempty"`
+	IPv6        string `json:"ipv6,omitempty"`
+	Port        int64  `json:"port,omitempty"`
+}
+
+type zipkinAnnotation struct {
+	Timestamp uint64 `json:"timestamp"`
+	Value     string `json:"value"`
+}
+
+// zipkinDependencyLink is the Zipkin v2 dependency link model.
+// refs: https://zipkin.io/zipkin-api/#/default/get_dependencies
+type zipkinDependencyLink struct {
+	Parent    string `json:"parent"`
+	Child     string `json:"child"`
+	CallCount uint64 `json:"callCount"`
+}
+
+const (
+	zipkinTagError        = "error"
+	zipkinTagStatusCode   = "otel.status_code"
+	zipkinTagLibraryName  = "otel.library.name"
+	zipkinTagLibraryVer   = "otel.library.version"
+	zipkinTagTraceState   = "w3c.tracestate"
+	zipkinDefaultService  = "unknown_service"
+	zipkinPeerServiceAttr = "peer.service"
+)
+
+// toZipkinSpans renders the OTLP spans as Zipkin v2 spans, ordered by start time.
+func toZipkinSpans(td *v1.TracesData) []*zipkinSpan {
+	var spans []*zipkinSpan
+	for _, rs := range td.GetResourceSpans() {
+		localEndpoint := &zipkinEndpoint{ServiceName: zipkinDefaultService}
+		resourceTags := map[string]string{}
+		for _, kv := range rs.GetResource().GetAttributes() {
+			if kv.Key == semconv.AttributeServiceName {
+				localEndpoint.ServiceName = datasource.AnyValueString(kv.Value)
+				continue
+			}
+			resourceTags[kv.Key] = datasource.AnyValueString(kv.Value)
+		}
+		for _, ss := range rs.GetScopeSpans() {
+			for _, span := range ss.GetSpans() {
+				zs := toZipkinSpan(span, ss.GetScope())
+				zs.LocalEndpoint = localEndpoint
+				for k, v := range resourceTags {
+					if _, ok := zs.Tags[k]; !ok {
+						zs.Tags[k] = v
+					}
+				}
+				if len(zs.Tags) == 0 {
+					zs.Tags = nil
+				}
+				spans = append(spans, zs)
+			}
+		}
+	}
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].Timestamp < spans[j].Timestamp })
+	return spans
+}
+
+func toZipkinSpan(span *v1.Span, scope *v1_common.InstrumentationScope) *zipkinSpan {
+	zs := &zipkinSpan{
+		TraceID:   string(span.TraceId),
+		ID:        string(span.SpanId),
+		ParentID:  string(span.ParentSpanId),
+		Name:      span.Name,
+		Kind:      zipkinKind(span.Kind),
+		Timestamp: span.StartTimeUnixNano / 1e3,
+		Tags:      map[string]string{},
+	}
+	if span.EndTimeUnixNano > span.StartTimeUnixNano {
+		zs.Duration = (span.EndTimeUnixNano - span.StartTimeUnixNano) / 1e3
+	}
+
+	remote := &zipkinEndpoint{}
+	for _, kv := range span.Attributes {
+		value := datasource.AnyValueString(kv.Value)
+		switch kv.Key {
+		case zipkinPeerServiceAttr:
+			remote.ServiceName = value
+		case semconv.AttributeNetPeerIP:
+			if strings.Contains(value, ":") {
+				remote.IPv6 = value
+			} else {
+				remote.IPv4 = value
+			}
+		case semconv.AttributeNetPeerPort:
+			remote.Port, _ = strconv.ParseInt(value, 10, 64)
+		}
+		zs.Tags[kv.Key] = value
+	}
+	if *remote != (zipkinEndpoint{}) {
+		zs.RemoteEndpoint = remote
+	}
+
+	switch span.GetStatus().GetCode() {
+	case v1.Status_STATUS_CODE_ERROR:
+		zs.Tags[zipkinTagStatusCode] = "ERROR"
+		zs.Tags[zipkinTagError] = span.GetStatus().GetMessage()
+		if zs.Tags[zipkinTagError] == "" {
+			zs.Tags[zipkinTagError] = "true"
+		}
+	case v1.Status_STATUS_CODE_OK:
+		zs.Tags[zipkinTagStatusCode] = "OK"
+	}
+	if span.TraceState != "" {
+		zs.Tags[zipkinTagTraceState] = span.TraceState
+	}
+	if scope.GetName() != "" {
+		zs.Tags[zipkinTagLibraryName] = scope.GetName()
+	}
+	if scope.GetVersion() != "" {
+		zs.Tags[zipkinTagLibraryVer] = scope.GetVersion()
+	}
+
+	for _, event := range span.Events {
+		zs.Annotations = append(zs.Annotations, zipkinAnnotation{
+			Timestamp: event.TimeUnixNano / 1e3,
+			Value:     zipkinAnnotationValue(event),
+		})
+	}
+	return zs
+}
+
+// zipkinAnnotationValue renders an event as its name followed by its attributes as JSON, if any.
+func zipkinAnnotationValue(event *v1.Span_Event) string {
+	if len(event.Attributes) == 0 {
+		return event.Name
+	}
+	attrs := make(map[string]string, len(event.Attributes))
+	for _, kv := range event.Attributes {
+		attrs[kv.Key] = datasource.AnyValueString(kv.Value)
+	}
+	b, err := json.Marshal(attrs)
+	if err != nil {
+		return event.Name
+	}
+	return event.Name + ": " + string(b)
+}
+
+func zipkinKind(kind v1.Span_SpanKind) string {
+	switch kind {
+	case v1.Span_SPAN_KIND_CLIENT:
+		return "CLIENT"
+	case v1.Span_SPAN_KIND_SERVER:
+		return "SERVER"
+	case v1.Span_SPAN_KIND_PRODUCER:
+		return "PRODUCER"
+	case v1.Span_SPAN_KIND_CONSUMER:
+		return "CONSUMER"
+	default:
+		return ""
+	}
+}
+
+// fromZipkinSpans converts Zipkin v2 spans grouped by local service and instrumentation library,
+// the tags set by toZipkinSpan are turned back into the OTLP fields. The tags are kept as
+// string attributes, and the remote endpoint becomes the peer attributes it is rendered from.
+func fromZipkinSpans(spans []*zipkinSpan) *v1.TracesData {
+	td := &v1.TracesData{}
+	resources := map[string]*v1.ResourceSpans{}
+	scopes := map[string]*v1.ScopeSpans{}
+	for _, zs := range spans {
+		serviceName := zipkinDefaultService
+		if zs.LocalEndpoint != nil && zs.LocalEndpoint.ServiceName != "" {
+			serviceName = zs.LocalEndpoint.ServiceName
+		}
+		rs, ok := resources[serviceName]
+		if !ok {
+			rs = &v1.ResourceSpans{Resource: &v1_resource.Resource{Attributes: []*v1_common.KeyValue{
+				zipkinStringAttribute(semconv.AttributeServiceName, serviceName),
+			}}}
+			resources[serviceName] = rs
+			td.ResourceSpans = append(td.ResourceSpans, rs)
+		}
+		span, scope := fromZipkinSpan(zs)
+		scopeKey := serviceName + "\x00" + scope.Name + "\x00" + scope.Version
+		ss, ok := scopes[scopeKey]
+		if !ok {
+			ss = &v1.ScopeSpans{Scope: scope}
+			scopes[scopeKey] = ss
+			rs.ScopeSpans = append(rs.ScopeSpans, ss)
+		}
+		ss.Spans = append(ss.Spans, span)
+	}
+	return td
+}
+
+func fromZipkinSpan(zs *zipkinSpan) (*v1.Span, *v1_common.InstrumentationScope) {
+	span := &v1.Span{
+		TraceId:           normalizeID(zs.TraceID, 32),
+		SpanId:            normalizeID(zs.ID, 16),
+		ParentSpanId:      normalizeID(zs.ParentID, 16),
+		Name:              zs.Name,
+		Kind:              fromZipkinKind(zs.Kind),
+		StartTimeUnixNano: zs.Timestamp * 1e3,
+		EndTimeUnixNano:   (zs.Timestamp + zs.Duration) * 1e3,
+		Status:            &v1.Status{},
+	}
+
+	scope := &v1_common.InstrumentationScope{}
+	keys := make([]string, 0, len(zs.Tags))
+	for key := range zs.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var isError bool
+	for _, key := range keys {
+		value := zs.Tags[key]
+		switch key {
+		case zipkinTagStatusCode:
+			if value == "OK" {
+				span.Status.Code = v1.Status_STATUS_CODE_OK
+			}
+		case zipkinTagError:
+			isError = true
+			if value != "true" {
+				span.Status.Message = value
+			}
+		case zipkinTagTraceState:
+			span.TraceState = value
+		case zipkinTagLibraryName:
+			scope.Name = value
+		case zipkinTagLibraryVer:
+			scope.Version = value
+		default:
+			span.Attributes = append(span.Attributes, zipkinStringAttribute(key, value))
+		}
+	}
+	if isError {
+		span.Status.Code = v1.Status_STATUS_CODE_ERROR
+	}
+
+	if remote := zs.RemoteEndpoint; remote != nil {
+		addMissing := func(key, value string) {
+			if _, ok := zs.Tags[key]; !ok && value != "" {
+				span.Attributes = append(span.Attributes, zipkinStringAttribute(key, value))
+			}
+		}
+		addMissing(zipkinPeerServiceAttr, remote.ServiceName)
+		addMissing(semconv.AttributeNetPeerIP, remote.IPv4)
+		addMissing(semconv.AttributeNetPeerIP, remote.IPv6)
+		if remote.Port > 0 {
+			addMissing(semconv.AttributeNetPeerPort, strconv.FormatInt(remote.Port, 10))
+		}
+	}
+
+	for _, annotation := range zs.Annotations {
+		span.Events = append(span.Events, fromZipkinAnnotation(annotation))
+	}
+	return span, scope
+}
+
+// fromZipkinAnnotation reverses zipkinAnnotationValue, a value not ending with a JSON object of
+// attributes is the event name.
+func fromZipkinAnnotation(annotation zipkinAnnotation) *v1.Span_Event {
+	event := &v1.Span_Event{TimeUnixNano: annotation.Timestamp * 1e3, Name: annotation.Value}
+	name, attributes, ok := strings.Cut(annotation.Value, ": {")
+	if !ok {
+		return event
+	}
+	var attrs map[string]string
+	if err := json.Unmarshal([]byte("{"+attributes), &attrs); err != nil {
+		return event
+	}
+	event.Name = name
+	keys := make([]string, 0, len(attrs))
+	for key := range attrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		event.Attributes = append(event.Attributes, zipkinStringAttribute(key, attrs[key]))
+	}
+	return event
+}
+
+func fromZipkinKind(kind string) v1.Span_SpanKind {
+	switch strings.ToUpper(kind) {
+	case "CLIENT":
+		return v1.Span_SPAN_KIND_CLIENT
+	case "SERVER":
+		return v1.Span_SPAN_KIND_SERVER
+	case "PRODUCER":
+		return v1.Span_SPAN_KIND_PRODUCER
+	case "CONSUMER":
+		return v1.Span_SPAN_KIND_CONSUMER
+	default:
+		return v1.Span_SPAN_KIND_UNSPECIFIED
+	}
+}
+
+func zipkinStringAttribute(key, value string) *v1_common.KeyValue {
+	return &v1_common.KeyValue{Key: key, Value: &v1_common.AnyValue{Value: &v1_common.AnyValue_StringValue{StringValue: value}}}
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newZipkinRouter(query *mockQuery, available func() error) *mux.Router {
+	router := mux.NewRouter()
+	z := &ZipkinHandler{
+		QueryService: &QueryService{TracingQuerySvc: query},
+		Limits:       Limits{DefaultRange: time.Hour, MaxSpansPerTrace: 100},
+		Available:    available,
+	}
+	z.RegisterRoutes(router)
+	return router
+}
+
+func serveZipkin(t *testing.T, router *mux.Router, url string, v interface{}) int {
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+	if v != nil && rec.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), v))
+	}
+	return rec.Code
+}
+
+func TestZipkinServicesAndSpans(t *testing.T) {
+	router := newZipkinRouter(&mockQuery{}, nil)
+
+	var services []string
+	assert.Equal(t, http.StatusOK, serveZipkin(t, router, "/api/v2/services", &services))
+	assert.Equal(t, []string{"frontend"}, services)
+
+	var names []string
+	assert.Equal(t, http.StatusOK, serveZipkin(t, router, "/api/v2/spans?serviceName=frontend", &names))
+	assert.Equal(t, []string{"GET /hello"}, names)
+	assert.Equal(t, http.StatusBadRequest, serveZipkin(t, router, "/api/v2/spans", nil))
+}
+
+type mockDependencies struct {
+	end      time.Time
+	lookback time.Duration
+}
+
+func (m *mockDependencies) GetDependencies(_ context.Context, endTime time.Time, lookback time.Duration) ([]*datasource.DependencyLink, error) {
+	m.end, m.lookback = endTime, lookback
+	return []*datasource.DependencyLink{{Parent: "frontend", Child: "mysql", CallCount: 3}}, nil
+}
+
+func TestZipkinDependencies(t *testing.T) {
+	router := newZipkinRouter(&mockQuery{}, nil)
+	var links []*zipkinDependencyLink
+	assert.Equal(t, http.StatusOK, serveZipkin(t, router, "/api/v2/dependencies?endTs=1681873445000", &links))
+	assert.Equal(t, []*zipkinDependencyLink{}, links)
+	assert.Equal(t, http.StatusBadRequest, serveZipkin(t, router, "/api/v2/dependencies", nil))
+	assert.Equal(t, http.StatusBadRequest, serveZipkin(t, router, "/api/v2/dependencies?endTs=1681873445000&lookback=-1", nil))
+
+	reader := &mockDependencies{}
+	router = mux.NewRouter()
+	z := &ZipkinHandler{QueryService: &QueryService{TracingQuerySvc: &mockQuery{}, DependencySvc: reader}}
+	z.RegisterRoutes(router)
+	assert.Equal(t, http.StatusOK, serveZipkin(t, router, "/api/v2/dependencies?endTs=1681873445000&lookback=86400000", &links))
+	assert.Equal(t, []*zipkinDependencyLink{{Parent: "frontend", Child: "mysql", CallCount: 3}}, links)
+	assert.Equal(t, time.UnixMilli(1681873445000), reader.end)
+	assert.Equal(t, 24*time.Hour, reader.lookback)
+}
+
+func TestZipkinGetTrace(t *testing.T) {
+	router := newZipkinRouter(&mockQuery{traces: map[string]*v1.TracesData{
+		"01020304050607080807060504030201": mockTrace(),
+	}}, nil)
+
+	var spans []*zipkinSpan
+	require.Equal(t, http.StatusOK, serveZipkin(t, router, "/api/v2/trace/01020304050607080807060504030201", &spans))
+	require.Equal(t, 2, len(spans))
+
+	server, client := spans[0], spans[1]
+	assert.Equal(t, "01020304050607080807060504030201", server.TraceID)
+	assert.Equal(t, "SERVER", server.Kind)
+	assert.Equal(t, uint64(1681873445000000), server.Timestamp)
+	assert.Equal(t, uint64(2000), server.Duration)
+	assert.Equal(t, "frontend", server.LocalEndpoint.ServiceName)
+	assert.Equal(t, "GET", server.Tags["http.method"])
+	assert.Equal(t, "localhost", server.Tags["host.name"])
+	assert.Equal(t, "net/http", server.Tags["otel.library.name"])
+
+	assert.Equal(t, "0102030405060708", client.ParentID)
+	assert.Equal(t, "CLIENT", client.Kind)
+	assert.Equal(t, &zipkinEndpoint{ServiceName: "mysql", IPv4: "10.0.0.1", Port: 3306}, client.RemoteEndpoint)
+	assert.Equal(t, "timeout", client.Tags["error"])
+	assert.Equal(t, []zipkinAnnotation{{Timestamp: 1681873445001000, Value: `exception: {"exception.message":"timeout"}`}}, client.Annotations)
+
+	assert.Equal(t, http.StatusNotFound, serveZipkin(t, router, "/api/v2/trace/0a0b", nil))
+	assert.Equal(t, http.StatusBadRequest, serveZipkin(t, router, "/api/v2/trace/0a0b'--", nil))
+}
+
+func TestZipkinGetTraces(t *testing.T) {
+	query := &mockQuery{traces: map[string]*v1.TracesData{
+		"01020304050607080807060504030201": mockTrace(),
+	}}
+	router := newZipkinRouter(query, nil)
+
+	var traces [][]*zipkinSpan
+	url := "/api/v2/traces?serviceName=frontend&spanName=all&annotationQuery=http.method%3DGET%20and%20error%3Dtimeout%20and%20db.system" +
+		"&minDuration=1000&endTs=1681873446000&lookback=60000&limit=5"
+	require.Equal(t, http.StatusOK, serveZipkin(t, router, url, &traces))
+	require.Equal(t, 1, len(traces))
+	assert.Equal(t, 2, len(traces[0]))
+	assert.Equal(t, 1, query.searches)
+	assert.Equal(t, 0, query.gets)
+
+	params := query.lastSearch
+	assert.Equal(t, "frontend", params.ServiceName)
+	assert.Equal(t, "", params.OperationName)
+	assert.Equal(t, map[string]string{"http.method": "GET", "error": "timeout"}, params.Tags)
+	assert.Equal(t, []string{"db.system"}, params.TagKeys)
+	assert.Equal(t, time.Millisecond, params.DurationMin.AsDuration())
+	assert.Equal(t, time.UnixMilli(1681873446000), params.EndTime)
+	assert.Equal(t, time.UnixMilli(1681873386000), params.StartTime)
+	assert.Equal(t, 5, params.NumTraces)
+
+	require.Equal(t, http.StatusOK, serveZipkin(t, router, "/api/v2/traces?annotationQuery=error", &traces))
+	assert.Equal(t, []string{"error"}, query.lastSearch.TagKeys)
+	assert.Equal(t, http.StatusBadRequest, serveZipkin(t, router, "/api/v2/traces?annotationQuery=%3Dtimeout", nil))
+	assert.Equal(t, http.StatusBadRequest, serveZipkin(t, router, "/api/v2/traces?limit=-1", nil))
+}
+
+func TestZipkinUnavailable(t *testing.T) {
+	router := newZipkinRouter(&mockQuery{}, func() error {
+		return status.Error(codes.Unavailable, "datasource is unavailable")
+	})
+	assert.Equal(t, http.StatusServiceUnavailable, serveZipkin(t, router, "/api/v2/services", nil))
+}
@@ -0,0 +1,287 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	statusUp   = "up"
+	statusDown = "down"
+)
+
+// datasourceHealth tracks the initialization and connectivity of one datasource.
+type datasourceHealth struct {
+	initialize func() error
+	ping       func(ctx context.Context) error
+
+	initialized bool
+	lastErr     error
+	lastCheck   time.Time
+}
+
+func (d *datasourceHealth) available() bool {
+	return d.initialized && d.lastErr == nil
+}
+
+// healthChecker initializes datasources in the background, retrying until they
+// succeed, and periodically checks their connectivity afterwards. The result is
+// reported through the gRPC health service, `/healthz` and `/readyz`.
+type healthChecker struct {
+	logger   *zap.Logger
+	interval time.Duration
+	timeout  time.Duration
+
+	grpcHealth *health.Server
+	// services are the gRPC services whose serving status follows the datasources.
+	services []string
+
+	mu            sync.RWMutex
+	pipelineReady bool
+	serving       bool
+	datasources   map[string]*datasourceHealth
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newHealthChecker(logger *zap.Logger, settings HealthCheckSettings, services ...string) *healthChecker {
+	hc := &healthChecker{
+		logger:      logger,
+		interval:    settings.Interval,
+		timeout:     settings.Timeout,
+		grpcHealth:  health.NewServer(),
+		services:    append([]string{""}, services...),
+		serving:     true,
+		datasources: map[string]*datasourceHealth{},
+		stopCh:      make(chan struct{}),
+	}
+	if hc.interval <= 0 {
+		hc.interval = defaultHealthCheckInterval
+	}
+	if hc.timeout <= 0 {
+		hc.timeout = defaultHealthCheckTimeout
+	}
+	hc.updateServingStatus()
+	return hc
+}
+
+// register adds a datasource. initialize is retried until it succeeds, then ping is called every interval.
+func (hc *healthChecker) register(name string, initialize func() error, ping func(ctx context.Context) error) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	hc.datasources[name] = &datasourceHealth{
+		initialize: initialize,
+		ping:       ping,
+		lastErr:    fmt.Errorf("%s is not initialized yet", name),
+	}
+}
+
+func (hc *healthChecker) start() {
+	hc.mu.RLock()
+	defer hc.mu.RUnlock()
+	for name, ds := range hc.datasources {
+		hc.wg.Add(1)
+		go hc.watch(name, ds)
+	}
+}
+
+func (hc *healthChecker) stop() {
+	select {
+	case <-hc.stopCh:
+	default:
+		close(hc.stopCh)
+	}
+	hc.wg.Wait()
+	hc.grpcHealth.Shutdown()
+}
+
+func (hc *healthChecker) watch(name string, ds *datasourceHealth) {
+	defer hc.wg.Done()
+	ticker := time.NewTicker(hc.interval)
+	defer ticker.Stop()
+	for {
+		hc.check(name, ds)
+		select {
+		case <-hc.stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (hc *healthChecker) check(name string, ds *datasourceHealth) {
+	hc.mu.RLock()
+	initialized := ds.initialized
+	hc.mu.RUnlock()
+
+	var err error
+	if !initialized {
+		if err = ds.initialize(); err != nil {
+			hc.logger.Warn("Failed to initialize datasource, will retry", zap.String("datasource", name), zap.Duration("interval", hc.interval), zap.Error(err))
+		} else {
+			hc.logger.Info("Datasource initialized", zap.String("datasource", name))
+			initialized = true
+		}
+	}
+	if initialized {
+		ctx, cancel := context.WithTimeout(context.Background(), hc.timeout)
+		err = ds.ping(ctx)
+		cancel()
+	}
+
+	hc.mu.Lock()
+	wasAvailable := ds.available()
+	ds.initialized = initialized
+	ds.lastErr = err
+	ds.lastCheck = time.Now()
+	if wasAvailable && err != nil {
+		hc.logger.Warn("Datasource is unavailable", zap.String("datasource", name), zap.Error(err))
+	} else if !wasAvailable && ds.available() {
+		hc.logger.Info("Datasource is available", zap.String("datasource", name))
+	}
+	hc.mu.Unlock()
+
+	hc.updateServingStatus()
+}
+
+// setPipelineReady records the collector pipeline state, see extension.PipelineWatcher.
+func (hc *healthChecker) setPipelineReady(ready bool) {
+	hc.mu.Lock()
+	hc.pipelineReady = ready
+	hc.mu.Unlock()
+	hc.updateServingStatus()
+}
+
+// setNotServing marks the server as permanently not serving, e.g. after a listener failed.
+func (hc *healthChecker) setNotServing() {
+	hc.mu.Lock()
+	hc.serving = false
+	hc.mu.Unlock()
+	hc.updateServingStatus()
+}
+
+// available returns a gRPC Unavailable error when the datasource is not initialized or not reachable.
+func (hc *healthChecker) available(name string) error {
+	hc.mu.RLock()
+	defer hc.mu.RUnlock()
+	ds, ok := hc.datasources[name]
+	if !ok {
+		return status.Errorf(codes.Unavailable, "datasource %q is not configured", name)
+	}
+	if !ds.available() {
+		return status.Errorf(codes.Unavailable, "datasource %q is unavailable: %v", name, ds.lastErr)
+	}
+	return nil
+}
+
+func (hc *healthChecker) ready() bool {
+	hc.mu.RLock()
+	defer hc.mu.RUnlock()
+	if !hc.serving || !hc.pipelineReady {
+		return false
+	}
+	for _, ds := range hc.datasources {
+		if !ds.available() {
+			return false
+		}
+	}
+	return true
+}
+
+func (hc *healthChecker) updateServingStatus() {
+	servingStatus := healthpb.HealthCheckResponse_NOT_SERVING
+	if hc.ready() {
+		servingStatus = healthpb.HealthCheckResponse_SERVING
+	}
+	for _, service := range hc.services {
+		hc.grpcHealth.SetServingStatus(service, servingStatus)
+	}
+}
+
+// unaryInterceptor rejects the calls of requiredDatasource with Unavailable while that datasource is down.
+func (hc *healthChecker) unaryInterceptor(requiredDatasource func(fullMethod string, req interface{}) (string, bool)) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if name, ok := requiredDatasource(info.FullMethod, req); ok {
+			if err := hc.available(name); err != nil {
+				return nil, err
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+type datasourceStatus struct {
+	Status    string     `json:"status"`
+	Error     string     `json:"error,omitempty"`
+	LastCheck *time.Time `json:"last_check,omitempty"`
+}
+
+type healthResponse struct {
+	Status      string                      `json:"status"`
+	Datasources map[string]datasourceStatus `json:"datasources"`
+}
+
+func (hc *healthChecker) response(up bool) healthResponse {
+	hc.mu.RLock()
+	defer hc.mu.RUnlock()
+	res := healthResponse{Status: statusDown, Datasources: map[string]datasourceStatus{}}
+	if up {
+		res.Status = statusUp
+	}
+	for name, ds := range hc.datasources {
+		s := datasourceStatus{Status: statusUp}
+		if !ds.available() {
+			s.Status = statusDown
+			s.Error = ds.lastErr.Error()
+		}
+		if !ds.lastCheck.IsZero() {
+			lastCheck := ds.lastCheck
+			s.LastCheck = &lastCheck
+		}
+		res.Datasources[name] = s
+	}
+	return res
+}
+
+// healthzHandler reports the server is alive together with the datasources status.
+func (hc *healthChecker) healthzHandler(w http.ResponseWriter, _ *http.Request) {
+	hc.mu.RLock()
+	serving := hc.serving
+	hc.mu.RUnlock()
+	writeHealthResponse(w, serving, hc.response(serving))
+}
+
+// readyzHandler reports whether the server can answer queries: the pipelines are
+// ready and all datasources are reachable.
+func (hc *healthChecker) readyzHandler(w http.ResponseWriter, _ *http.Request) {
+	ready := hc.ready()
+	writeHealthResponse(w, ready, hc.response(ready))
+}
+
+func writeHealthResponse(w http.ResponseWriter, ok bool, res healthResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	if ok {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+// isServiceMethod reports whether fullMethod, e.g. `/pkg.Service/Method`, belongs to service.
+func isServiceMethod(fullMethod, service string) bool {
+	return strings.HasPrefix(fullMethod, "/"+service+"/")
+}
@@ -0,0 +1,164 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/api/v1alpha1"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/handler"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestHealthCheckerRetriesInitialization(t *testing.T) {
+	hc := newHealthChecker(zap.NewNop(), HealthCheckSettings{Interval: 10 * time.Millisecond, Timeout: time.Second}, "query.Service")
+	var attempts, failing int32
+	hc.register("elasticsearch", func() error {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			return errors.New("connection refused")
+		}
+		return nil
+	}, func(ctx context.Context) error {
+		if atomic.LoadInt32(&failing) == 1 {
+			return errors.New("cluster health is red")
+		}
+		return nil
+	})
+
+	err := hc.available("elasticsearch")
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, codes.Unavailable, status.Code(hc.available("clickhouse")))
+
+	hc.start()
+	defer hc.stop()
+	hc.setPipelineReady(true)
+
+	require.Eventually(t, func() bool { return hc.available("elasticsearch") == nil }, time.Second, 5*time.Millisecond)
+	assert.GreaterOrEqual(t, atomic.LoadInt32(&attempts), int32(3))
+	assert.True(t, hc.ready())
+	assertGRPCHealth(t, hc, "", healthpb.HealthCheckResponse_SERVING)
+	assertGRPCHealth(t, hc, "query.Service", healthpb.HealthCheckResponse_SERVING)
+
+	atomic.StoreInt32(&failing, 1)
+	require.Eventually(t, func() bool { return hc.available("elasticsearch") != nil }, time.Second, 5*time.Millisecond)
+	assert.False(t, hc.ready())
+	assertGRPCHealth(t, hc, "", healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+func TestHealthCheckerPipelineReady(t *testing.T) {
+	hc := newHealthChecker(zap.NewNop(), HealthCheckSettings{})
+	assert.False(t, hc.ready())
+	hc.setPipelineReady(true)
+	assert.True(t, hc.ready())
+	hc.setNotServing()
+	assert.False(t, hc.ready())
+}
+
+func TestHealthCheckerHTTPHandlers(t *testing.T) {
+	hc := newHealthChecker(zap.NewNop(), HealthCheckSettings{})
+	hc.register("clickhouse", func() error { return errors.New("dial tcp: connection refused") }, nil)
+	hc.check("clickhouse", hc.datasources["clickhouse"])
+	hc.setPipelineReady(true)
+
+	rec := httptest.NewRecorder()
+	hc.healthzHandler(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	hc.readyzHandler(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	var res healthResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.Equal(t, statusDown, res.Status)
+	assert.Equal(t, statusDown, res.Datasources["clickhouse"].Status)
+	assert.Equal(t, "dial tcp: connection refused", res.Datasources["clickhouse"].Error)
+	assert.NotNil(t, res.Datasources["clickhouse"].LastCheck)
+}
+
+func TestHealthCheckerUnaryInterceptor(t *testing.T) {
+	hc := newHealthChecker(zap.NewNop(), HealthCheckSettings{})
+	hc.register("elasticsearch", func() error { return errors.New("connection refused") }, nil)
+	interceptor := hc.unaryInterceptor(func(fullMethod string, _ interface{}) (string, bool) {
+		return "elasticsearch", isServiceMethod(fullMethod, "query.Service")
+	})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/query.Service/GetServices"}, handler)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	res, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", res)
+}
+
+func TestQueryServerRequiredDatasource(t *testing.T) {
+	qs := &queryServer{config: &Config{
+		TracingQuery: &plugin.StorageConfig{StorageType: "clickhouse"},
+		LoggingQuery: &plugin.StorageConfig{StorageType: "elasticsearch"},
+	}}
+	service := "/" + v1alpha1.QueryService_ServiceDesc.ServiceName + "/"
+	tests := []struct {
+		method   string
+		req      interface{}
+		expected string
+		required bool
+	}{
+		{method: service + "SearchTraces", expected: "clickhouse", required: true},
+		{method: service + "SearchSpans", expected: "clickhouse", required: true},
+		{method: service + "SearchLogs", expected: "elasticsearch", required: true},
+		{
+			method:   service + "Aggregate",
+			req:      &v1alpha1.AggregateRequest{Query: &v1alpha1.AggregateRequest_SpanQuery{SpanQuery: &v1alpha1.SpanQueryParameters{}}},
+			expected: "clickhouse",
+			required: true,
+		},
+		{
+			method:   service + "Aggregate",
+			req:      &v1alpha1.AggregateRequest{Query: &v1alpha1.AggregateRequest_LogQuery{LogQuery: &v1alpha1.LogQueryParameters{}}},
+			expected: "elasticsearch",
+			required: true,
+		},
+		{method: service + "RunSavedQuery"},
+		{method: service + "CreateSavedQuery"},
+		{method: "/grpc.health.v1.Health/Check"},
+	}
+	for _, tt := range tests {
+		storageType, required := qs.requiredDatasource(tt.method, tt.req)
+		assert.Equal(t, tt.expected, storageType, tt.method)
+		assert.Equal(t, tt.required, required, tt.method)
+	}
+}
+
+func TestQueryServerStartInvalidAlertRule(t *testing.T) {
+	qs := &queryServer{
+		config: &Config{Alerting: handler.AlertingConfig{
+			Rules: []handler.AlertRule{{Name: "unknown", Type: "unknown"}},
+		}},
+		settings:     component.TelemetrySettings{Logger: zap.NewNop()},
+		queryService: &handler.QueryService{},
+	}
+	assert.ErrorContains(t, qs.Start(context.Background(), nil), "failed to create alert rules")
+	// the servers and listeners are not started
+	assert.Nil(t, qs.closeGRPCGateway)
+	assert.Nil(t, qs.grpcConn)
+	assert.Nil(t, qs.httpConn)
+}
+
+func assertGRPCHealth(t *testing.T, hc *healthChecker, service string, expected healthpb.HealthCheckResponse_ServingStatus) {
+	res, err := hc.grpcHealth.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	require.NoError(t, err)
+	assert.Equal(t, expected, res.Status)
+}
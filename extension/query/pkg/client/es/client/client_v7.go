@@ -1,12 +1,16 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/aquasecurity/esquery"
 	"github.com/elastic/go-elasticsearch/v7"
@@ -16,6 +20,8 @@ import (
 
 type Elastic struct {
 	Client *elasticsearch.Client
+	// OnSearch, when set, is called with the time Elasticsearch reported spending on each search.
+	OnSearch func(ctx context.Context, index string, took time.Duration)
 }
 
 func New(address []string, username, password string) (*Elastic, error) {
@@ -40,6 +46,275 @@ func (e *Elastic) Info() (*esapi.Response, error) {
 	return e.Client.Info()
 }
 
+// Ping checks that the cluster is reachable.
+func (e *Elastic) Ping(ctx context.Context) error {
+	res, err := e.Client.Ping(e.Client.Ping.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(res.Body)
+
+	if res.IsError() {
+		return fmt.Errorf("ping elasticsearch failed: %s", res.Status())
+	}
+	return nil
+}
+
+// ClusterHealth returns the cluster health status, one of green, yellow or red.
+func (e *Elastic) ClusterHealth(ctx context.Context) (string, error) {
+	res, err := e.Client.Cluster.Health(e.Client.Cluster.Health.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(res.Body)
+
+	if res.IsError() {
+		return "", parseError(res)
+	}
+
+	var health struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&health); err != nil {
+		return "", err
+	}
+	return health.Status, nil
+}
+
+// FieldNames returns the sorted names of the fields of index matching pattern, such as `Resource.*`.
+func (e *Elastic) FieldNames(ctx context.Context, index, pattern string) ([]string, error) {
+	res, err := e.Client.Indices.GetFieldMapping([]string{pattern},
+		e.Client.Indices.GetFieldMapping.WithContext(ctx),
+		e.Client.Indices.GetFieldMapping.WithIndex(index))
+	if err != nil {
+		return nil, err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(res.Body)
+
+	if res.IsError() {
+		return nil, parseError(res)
+	}
+
+	var indices map[string]struct {
+		Mappings map[string]json.RawMessage `json:"mappings"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&indices); err != nil {
+		return nil, err
+	}
+	set := map[string]struct{}{}
+	for _, index := range indices {
+		for name := range index.Mappings {
+			set[name] = struct{}{}
+		}
+	}
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Reindex copies the documents of source matching query into dest, keeping their IDs so copying
+// them again overwrites them. It refreshes dest and returns the number of documents copied.
+func (e *Elastic) Reindex(ctx context.Context, source, dest string, query esquery.Mappable) (int64, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"source": map[string]interface{}{
+			"index": source,
+			"query": query.Map(),
+		},
+		"dest": map[string]interface{}{
+			"index": dest,
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+	res, err := e.Client.Reindex(bytes.NewReader(body),
+		e.Client.Reindex.WithContext(ctx),
+		e.Client.Reindex.WithRefresh(true),
+		e.Client.Reindex.WithWaitForCompletion(true))
+	if err != nil {
+		return 0, err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(res.Body)
+
+	if res.IsError() {
+		return 0, parseError(res)
+	}
+
+	var result struct {
+		Total    int64             `json:"total"`
+		Failures []json.RawMessage `json:"failures"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return 0, err
+	}
+	if len(result.Failures) > 0 {
+		return 0, fmt.Errorf("reindex into %s failed: %s", dest, result.Failures[0])
+	}
+	return result.Total, nil
+}
+
+// CreateIndex creates index with mappings, it does nothing when the index exists.
+func (e *Elastic) CreateIndex(ctx context.Context, index string, mappings map[string]interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{"mappings": mappings})
+	if err != nil {
+		return err
+	}
+	res, err := e.Client.Indices.Create(index,
+		e.Client.Indices.Create.WithContext(ctx),
+		e.Client.Indices.Create.WithBody(bytes.NewReader(body)))
+	if err != nil {
+		return err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(res.Body)
+
+	if res.IsError() {
+		err = parseError(res)
+		if strings.Contains(err.Error(), "resource_already_exists_exception") {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// IndexMappings returns the mappings of the newest index, by name, of the indices matching
+// index, it returns ErrNotFound when there is none.
+func (e *Elastic) IndexMappings(ctx context.Context, index string) (map[string]interface{}, error) {
+	res, err := e.Client.Indices.GetMapping(
+		e.Client.Indices.GetMapping.WithContext(ctx),
+		e.Client.Indices.GetMapping.WithIndex(index))
+	if err != nil {
+		return nil, err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(res.Body)
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if res.IsError() {
+		return nil, parseError(res)
+	}
+
+	var indices map[string]struct {
+		Mappings map[string]interface{} `json:"mappings"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&indices); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(indices))
+	for name := range indices {
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil, ErrNotFound
+	}
+	sort.Strings(names)
+	return indices[names[len(names)-1]].Mappings, nil
+}
+
+// CreateDocument indexes doc with id and refreshes index, it returns ErrConflict if the id exists.
+func (e *Elastic) CreateDocument(ctx context.Context, index, id string, doc interface{}) error {
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	res, err := e.Client.Create(index, id, bytes.NewReader(body),
+		e.Client.Create.WithContext(ctx),
+		e.Client.Create.WithRefresh("true"))
+	if err != nil {
+		return err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(res.Body)
+	return documentError(res)
+}
+
+// UpdateDocument replaces the fields of the document with id by those of doc and refreshes index,
+// it returns ErrNotFound if the id doesn't exist.
+func (e *Elastic) UpdateDocument(ctx context.Context, index, id string, doc interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{"doc": doc})
+	if err != nil {
+		return err
+	}
+	res, err := e.Client.Update(index, id, bytes.NewReader(body),
+		e.Client.Update.WithContext(ctx),
+		e.Client.Update.WithRefresh("true"))
+	if err != nil {
+		return err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(res.Body)
+	return documentError(res)
+}
+
+// GetDocument decodes the source of the document with id into doc, it returns ErrNotFound if the
+// id doesn't exist.
+func (e *Elastic) GetDocument(ctx context.Context, index, id string, doc interface{}) error {
+	res, err := e.Client.Get(index, id, e.Client.Get.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(res.Body)
+	if err = documentError(res); err != nil {
+		return err
+	}
+	var hit struct {
+		Source json.RawMessage `json:"_source"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&hit); err != nil {
+		return err
+	}
+	return json.Unmarshal(hit.Source, doc)
+}
+
+// DeleteDocument deletes the document with id and refreshes index, it returns ErrNotFound if the
+// id doesn't exist.
+func (e *Elastic) DeleteDocument(ctx context.Context, index, id string) error {
+	res, err := e.Client.Delete(index, id,
+		e.Client.Delete.WithContext(ctx),
+		e.Client.Delete.WithRefresh("true"))
+	if err != nil {
+		return err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(res.Body)
+	return documentError(res)
+}
+
+// documentError maps the status of a document request to ErrNotFound and ErrConflict.
+func documentError(res *esapi.Response) error {
+	if !res.IsError() {
+		return nil
+	}
+	switch res.StatusCode {
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusConflict:
+		return ErrConflict
+	}
+	return parseError(res)
+}
+
 func parseError(response *esapi.Response) error {
 	var e Error
 	if err := json.NewDecoder(response.Body).Decode(&e); err != nil {
@@ -56,6 +331,21 @@ func parseError(response *esapi.Response) error {
 
 func (e *Elastic) DoSearch(ctx context.Context, index string, qsl *esquery.SearchRequest) (*SearchResult, error) {
 	res, err := qsl.Run(e.Client, e.Client.Search.WithContext(ctx), e.Client.Search.WithIndex(index))
+	return e.searchResult(ctx, index, res, err)
+}
+
+// DoSearchBody runs a search whose body esquery can't express, such as a script sort.
+func (e *Elastic) DoSearchBody(ctx context.Context, index string, body map[string]interface{}) (*SearchResult, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	res, err := e.Client.Search(e.Client.Search.WithContext(ctx), e.Client.Search.WithIndex(index),
+		e.Client.Search.WithBody(bytes.NewReader(b)))
+	return e.searchResult(ctx, index, res, err)
+}
+
+func (e *Elastic) searchResult(ctx context.Context, index string, res *esapi.Response, err error) (*SearchResult, error) {
 	if err != nil {
 		zap.S().Error("Failed searching for stuff: %s", err)
 		return nil, err
@@ -68,7 +358,11 @@ func (e *Elastic) DoSearch(ctx context.Context, index string, qsl *esquery.Searc
 		return nil, parseError(res)
 	}
 
-	return parseBody(res)
+	ret, err := parseBody(res)
+	if err == nil && e.OnSearch != nil {
+		e.OnSearch(ctx, index, time.Duration(ret.TookInMillis)*time.Millisecond)
+	}
+	return ret, err
 }
 
 func parseBody(response *esapi.Response) (*SearchResult, error) {
@@ -1,5 +1,14 @@
 package client
 
+import "errors"
+
+var (
+	// ErrNotFound is returned for the requests of a missing document or index.
+	ErrNotFound = errors.New("elasticsearch document not found")
+	// ErrConflict is returned when creating a document with the ID of an existing one.
+	ErrConflict = errors.New("elasticsearch document already exists")
+)
+
 type Error struct {
 	Status  int           `json:"status"`
 	Details *ErrorDetails `json:"error,omitempty"`
@@ -3,3 +3,24 @@ package plugin
 type StorageConfig struct {
 	StorageType string `mapstructure:"storage_type"`
 }
+
+const (
+	// SavedQueriesStorageFile keeps the saved queries in a local bbolt database file.
+	SavedQueriesStorageFile = "file"
+)
+
+// SavedQueriesConfig selects the storage of the saved queries, they are disabled without a
+// storage type.
+type SavedQueriesConfig struct {
+	// StorageType is elasticsearch, clickhouse or file.
+	StorageType string `mapstructure:"storage_type"`
+	// Path is the database file of the file storage type.
+	Path string `mapstructure:"path"`
+}
+
+func (c *SavedQueriesConfig) storageType() string {
+	if c == nil {
+		return ""
+	}
+	return c.StorageType
+}
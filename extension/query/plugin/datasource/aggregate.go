@@ -0,0 +1,100 @@
+package datasource
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// AggregationFunction is the function of an aggregation.
+type AggregationFunction string
+
+const (
+	AggregateCount      AggregationFunction = "count"
+	AggregateSum        AggregationFunction = "sum"
+	AggregateAvg        AggregationFunction = "avg"
+	AggregateMin        AggregationFunction = "min"
+	AggregateMax        AggregationFunction = "max"
+	AggregatePercentile AggregationFunction = "percentile"
+)
+
+// The fields of the spans and the log records which are grouped by or aggregated.
+const (
+	// FieldServiceName is the service.name resource attribute.
+	FieldServiceName = "service_name"
+	// FieldName is the span name.
+	FieldName = "name"
+	// FieldKind is the OTLP span kind, e.g. SPAN_KIND_CLIENT.
+	FieldKind = "kind"
+	// FieldStatusCode is the OTLP status code of a span, e.g. STATUS_CODE_ERROR.
+	FieldStatusCode = "status_code"
+	// FieldDuration is the duration of a span, aggregated in milliseconds.
+	FieldDuration = "duration"
+	// FieldBody is the body of a log record.
+	FieldBody = "body"
+	// FieldSeverityText is the severity text of a log record.
+	FieldSeverityText = "severity_text"
+
+	// AttributeFieldPrefix prefixes the key of a span or log record attribute.
+	AttributeFieldPrefix = "attributes."
+	// ResourceFieldPrefix prefixes the key of a resource attribute.
+	ResourceFieldPrefix = "resource."
+)
+
+// ErrUnsupportedAggregation is returned when a datasource can't group by or aggregate a field.
+var ErrUnsupportedAggregation = errors.New("unsupported aggregation")
+
+// Aggregation computes a value of the spans or the log records of a group.
+type Aggregation struct {
+	Function AggregationFunction
+	// Field is FieldDuration or a numeric attribute, unused by AggregateCount.
+	Field string
+	// Percentile is the percentile of AggregatePercentile, between 0 and 100.
+	Percentile float64
+}
+
+// AggregateParameters contains parameters of an aggregation, the filters are the parameters of
+// the span or log query.
+type AggregateParameters struct {
+	// GroupBy are the fields the spans or the log records are grouped by, an intrinsic field or
+	// an attribute prefixed by AttributeFieldPrefix or ResourceFieldPrefix.
+	GroupBy      []string
+	Aggregations []Aggregation
+	// Step buckets the values of every group by time when it's set, the buckets are aligned on
+	// the multiples of Step since the Unix epoch.
+	Step time.Duration
+	// Limit is the number of groups returned, the top groups by the first aggregation.
+	Limit int
+}
+
+// AggregateGroup is the values of the aggregations of a group.
+type AggregateGroup struct {
+	// Keys are the values of the GroupBy fields, empty for a missing attribute.
+	Keys []string
+	// Values are the values of the aggregations over the whole time range.
+	Values []float64
+	// Buckets are the values of the aggregations per Step, ordered by time.
+	Buckets []AggregateBucket
+}
+
+// AggregateBucket is the values of the aggregations in the bucket starting at Timestamp.
+type AggregateBucket struct {
+	Timestamp time.Time
+	Values    []float64
+}
+
+// AttributeField returns the attribute key of an AttributeFieldPrefix field.
+func AttributeField(field string) (string, bool) {
+	if !strings.HasPrefix(field, AttributeFieldPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(field, AttributeFieldPrefix), true
+}
+
+// ResourceField returns the resource attribute key of a ResourceFieldPrefix field.
+func ResourceField(field string) (string, bool) {
+	if !strings.HasPrefix(field, ResourceFieldPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(field, ResourceFieldPrefix), true
+}
@@ -0,0 +1,23 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+
+	v1_trace "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// ErrArchiveNotConfigured is returned when creating the archive of a datasource without an
+// archive index or table configured.
+var ErrArchiveNotConfigured = errors.New("trace archive is not configured")
+
+// TraceArchive keeps copies of the traces pinned by the users in a storage without retention,
+// so they outlive the ILM policy or the TTL of the primary storage, as the Jaeger archive storage.
+type TraceArchive interface {
+	// ArchiveTrace copies the spans of a trace from the primary storage into the archive and
+	// returns the number of spans copied, no spans if the primary storage doesn't have the trace.
+	// Archiving a trace again doesn't duplicate its spans.
+	ArchiveTrace(ctx context.Context, traceID string) (int, error)
+	// GetArchivedTrace returns the spans of an archived trace, no spans if it isn't archived.
+	GetArchivedTrace(ctx context.Context, traceID string) (*v1_trace.TracesData, error)
+}
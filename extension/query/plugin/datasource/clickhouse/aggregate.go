@@ -0,0 +1,223 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+)
+
+const (
+	AGGREGATE_SQL         = "SELECT %s AS Keys, %s AS Values FROM %s AS a %s GROUP BY Keys ORDER BY Values[1] DESC LIMIT %d"
+	AGGREGATE_BUCKETS_SQL = "SELECT %s AS Keys, toStartOfInterval(a.Timestamp, INTERVAL %d SECOND) AS Bucket, %s AS Values FROM %s AS a %s GROUP BY Keys, Bucket ORDER BY Bucket"
+)
+
+type AggregateModel struct {
+	Keys   []string  `ch:"Keys"`
+	Values []float64 `ch:"Values"`
+}
+
+type AggregateBucketModel struct {
+	Keys   []string  `ch:"Keys"`
+	Bucket time.Time `ch:"Bucket"`
+	Values []float64 `ch:"Values"`
+}
+
+// aggregateColumns resolves the aggregate fields to the expressions of a table aliased as a, the
+// map values are bound as query parameters.
+type aggregateColumns struct {
+	group func(field string) (string, []interface{}, bool)
+	value func(field string) (string, []interface{}, bool)
+}
+
+// spanAggregateColumns resolves the fields of the traces table.
+var spanAggregateColumns = &aggregateColumns{
+	group: func(field string) (string, []interface{}, bool) {
+		switch field {
+		case datasource.FieldServiceName:
+			return "a.ServiceName", nil, true
+		case datasource.FieldName:
+			return "a.SpanName", nil, true
+		case datasource.FieldKind:
+			return "a.SpanKind", nil, true
+		case datasource.FieldStatusCode:
+			return "a.StatusCode", nil, true
+		}
+		if key, ok := datasource.AttributeField(field); ok {
+			return "a.SpanAttributes[?]", []interface{}{key}, true
+		}
+		if key, ok := datasource.ResourceField(field); ok {
+			return "a.ResourceAttributes[?]", []interface{}{key}, true
+		}
+		return "", nil, false
+	},
+	value: func(field string) (string, []interface{}, bool) {
+		if field == datasource.FieldDuration {
+			// Duration is stored in nanoseconds.
+			return "a.Duration / 1000000", nil, true
+		}
+		if key, ok := datasource.AttributeField(field); ok {
+			return "toFloat64OrNull(a.SpanAttributes[?])", []interface{}{key}, true
+		}
+		return "", nil, false
+	},
+}
+
+// logAggregateColumns resolves the fields of the logs table.
+var logAggregateColumns = &aggregateColumns{
+	group: func(field string) (string, []interface{}, bool) {
+		switch field {
+		case datasource.FieldServiceName:
+			return "a.ServiceName", nil, true
+		case datasource.FieldBody:
+			return "a.Body", nil, true
+		case datasource.FieldSeverityText:
+			return "a.SeverityText", nil, true
+		}
+		if key, ok := datasource.AttributeField(field); ok {
+			return "a.LogAttributes[?]", []interface{}{key}, true
+		}
+		if key, ok := datasource.ResourceField(field); ok {
+			return "a.ResourceAttributes[?]", []interface{}{key}, true
+		}
+		return "", nil, false
+	},
+	value: func(field string) (string, []interface{}, bool) {
+		if key, ok := datasource.AttributeField(field); ok {
+			return "toFloat64OrNull(a.LogAttributes[?])", []interface{}{key}, true
+		}
+		return "", nil, false
+	},
+}
+
+func (q *ClickHouseQuery) AggregateSpans(ctx context.Context, query *datasource.SpanQueryParameters, agg *datasource.AggregateParameters) ([]*datasource.AggregateGroup, error) {
+	where, args := buildSpansCondition(query)
+	return aggregate(ctx, q.client, q.tracingTableName, where, args, spanAggregateColumns, agg)
+}
+
+func (q *ClickHouseLogQuery) AggregateLogs(ctx context.Context, query *datasource.LogQueryParameters, agg *datasource.AggregateParameters) ([]*datasource.AggregateGroup, error) {
+	where, args := buildLogsCondition(query)
+	return aggregate(ctx, q.client, q.loggingTableName, where, args, logAggregateColumns, agg)
+}
+
+// aggregate selects the top groups, then the buckets of these groups when a step is set.
+func aggregate(ctx context.Context, client clickhouse.Conn, tableName, where string, whereArgs []interface{}, columns *aggregateColumns, agg *datasource.AggregateParameters) ([]*datasource.AggregateGroup, error) {
+	sql, args, err := buildAggregateQuery(tableName, where, whereArgs, columns, agg)
+	if err != nil {
+		return nil, err
+	}
+	var result []AggregateModel
+	if err = client.Select(ctx, &result, sql, args...); err != nil {
+		return nil, err
+	}
+	groups := make([]*datasource.AggregateGroup, 0, len(result))
+	byKeys := map[string]*datasource.AggregateGroup{}
+	for _, r := range result {
+		group := &datasource.AggregateGroup{Keys: r.Keys, Values: r.Values}
+		groups = append(groups, group)
+		byKeys[strings.Join(r.Keys, "\x00")] = group
+	}
+	if agg.Step <= 0 || len(groups) == 0 {
+		return groups, nil
+	}
+
+	sql, args, err = buildAggregateBucketsQuery(tableName, where, whereArgs, columns, agg)
+	if err != nil {
+		return nil, err
+	}
+	var buckets []AggregateBucketModel
+	if err = client.Select(ctx, &buckets, sql, args...); err != nil {
+		return nil, err
+	}
+	for _, b := range buckets {
+		if group, ok := byKeys[strings.Join(b.Keys, "\x00")]; ok {
+			group.Buckets = append(group.Buckets, datasource.AggregateBucket{Timestamp: b.Bucket, Values: b.Values})
+		}
+	}
+	return groups, nil
+}
+
+// buildAggregateQuery groups the rows by the array of the group by keys and orders the groups by
+// the first aggregation.
+func buildAggregateQuery(tableName, where string, whereArgs []interface{}, columns *aggregateColumns, agg *datasource.AggregateParameters) (string, []interface{}, error) {
+	keys, keyArgs, err := aggregateKeys(columns, agg.GroupBy)
+	if err != nil {
+		return "", nil, err
+	}
+	values, valueArgs, err := aggregateValues(columns, agg.Aggregations)
+	if err != nil {
+		return "", nil, err
+	}
+	args := append(append(keyArgs, valueArgs...), whereArgs...)
+	return fmt.Sprintf(AGGREGATE_SQL, keys, values, tableName, where, agg.Limit), args, nil
+}
+
+// buildAggregateBucketsQuery aggregates the rows of the top groups per step.
+func buildAggregateBucketsQuery(tableName, where string, whereArgs []interface{}, columns *aggregateColumns, agg *datasource.AggregateParameters) (string, []interface{}, error) {
+	step := int64(agg.Step / time.Second)
+	if step <= 0 {
+		return "", nil, fmt.Errorf("step %s must be at least one second", agg.Step)
+	}
+	top, topArgs, err := buildAggregateQuery(tableName, where, whereArgs, columns, agg)
+	if err != nil {
+		return "", nil, err
+	}
+	keys, keyArgs, _ := aggregateKeys(columns, agg.GroupBy)
+	values, valueArgs, _ := aggregateValues(columns, agg.Aggregations)
+
+	condition := "Keys IN (SELECT Keys FROM (" + top + "))"
+	if where == "" {
+		where = "WHERE " + condition
+	} else {
+		where += " AND " + condition
+	}
+	args := append(append(append(keyArgs, valueArgs...), whereArgs...), topArgs...)
+	return fmt.Sprintf(AGGREGATE_BUCKETS_SQL, keys, step, values, tableName, where), args, nil
+}
+
+func aggregateKeys(columns *aggregateColumns, groupBy []string) (string, []interface{}, error) {
+	if len(groupBy) == 0 {
+		return "CAST([], 'Array(String)')", nil, nil
+	}
+	var exprs []string
+	var args []interface{}
+	for _, field := range groupBy {
+		expr, exprArgs, ok := columns.group(field)
+		if !ok {
+			return "", nil, fmt.Errorf("%w: group by %s", datasource.ErrUnsupportedAggregation, field)
+		}
+		exprs = append(exprs, "toString("+expr+")")
+		args = append(args, exprArgs...)
+	}
+	return "[" + strings.Join(exprs, ", ") + "]", args, nil
+}
+
+func aggregateValues(columns *aggregateColumns, aggregations []datasource.Aggregation) (string, []interface{}, error) {
+	if len(aggregations) == 0 {
+		aggregations = []datasource.Aggregation{{Function: datasource.AggregateCount}}
+	}
+	var exprs []string
+	var args []interface{}
+	for _, a := range aggregations {
+		if a.Function == datasource.AggregateCount {
+			exprs = append(exprs, "toFloat64(count())")
+			continue
+		}
+		expr, exprArgs, ok := columns.value(a.Field)
+		if !ok {
+			return "", nil, fmt.Errorf("%w: %s of %s", datasource.ErrUnsupportedAggregation, a.Function, a.Field)
+		}
+		function := string(a.Function)
+		if a.Function == datasource.AggregatePercentile {
+			function = "quantile(" + strconv.FormatFloat(a.Percentile/100, 'f', -1, 64) + ")"
+		}
+		// the aggregations of the attributes which are not numbers are null
+		exprs = append(exprs, "toFloat64(ifNull("+function+"("+expr+"), 0))")
+		args = append(args, exprArgs...)
+	}
+	return "[" + strings.Join(exprs, ", ") + "]", args, nil
+}
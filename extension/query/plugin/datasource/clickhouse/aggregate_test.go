@@ -0,0 +1,53 @@
+package clickhouse
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildAggregateQuery(t *testing.T) {
+	where, whereArgs := buildSpansCondition(&datasource.SpanQueryParameters{ServiceName: "checkout"})
+	agg := &datasource.AggregateParameters{
+		GroupBy: []string{"attributes.http.route", "status_code"},
+		Aggregations: []datasource.Aggregation{
+			{Function: datasource.AggregateCount},
+			{Function: datasource.AggregatePercentile, Field: "duration", Percentile: 99},
+			{Function: datasource.AggregateSum, Field: "attributes.http.response_content_length"},
+		},
+		Step:  time.Minute,
+		Limit: 10,
+	}
+
+	sql, args, err := buildAggregateQuery("otel_traces", where, whereArgs, spanAggregateColumns, agg)
+	require.NoError(t, err)
+	keys := "[toString(a.SpanAttributes[?]), toString(a.StatusCode)]"
+	values := "[toFloat64(count()), toFloat64(ifNull(quantile(0.99)(a.Duration / 1000000), 0)), " +
+		"toFloat64(ifNull(sum(toFloat64OrNull(a.SpanAttributes[?])), 0))]"
+	top := "SELECT " + keys + " AS Keys, " + values + " AS Values FROM otel_traces AS a WHERE a.ServiceName = ? " +
+		"GROUP BY Keys ORDER BY Values[1] DESC LIMIT 10"
+	assert.Equal(t, top, sql)
+	topArgs := []interface{}{"http.route", "http.response_content_length", "checkout"}
+	assert.Equal(t, topArgs, args)
+
+	sql, args, err = buildAggregateBucketsQuery("otel_traces", where, whereArgs, spanAggregateColumns, agg)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT "+keys+" AS Keys, toStartOfInterval(a.Timestamp, INTERVAL 60 SECOND) AS Bucket, "+values+" AS Values "+
+		"FROM otel_traces AS a WHERE a.ServiceName = ? AND Keys IN (SELECT Keys FROM ("+top+")) GROUP BY Keys, Bucket ORDER BY Bucket", sql)
+	assert.Equal(t, append(topArgs, topArgs...), args)
+
+	sql, args, err = buildAggregateQuery("otel_logs", "", nil, logAggregateColumns, &datasource.AggregateParameters{Limit: 5})
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT CAST([], 'Array(String)') AS Keys, [toFloat64(count())] AS Values FROM otel_logs AS a  "+
+		"GROUP BY Keys ORDER BY Values[1] DESC LIMIT 5", sql)
+	assert.Empty(t, args)
+
+	_, _, err = buildAggregateQuery("otel_logs", "", nil, logAggregateColumns, &datasource.AggregateParameters{
+		Aggregations: []datasource.Aggregation{{Function: datasource.AggregateAvg, Field: "duration"}},
+	})
+	assert.True(t, errors.Is(err, datasource.ErrUnsupportedAggregation))
+}
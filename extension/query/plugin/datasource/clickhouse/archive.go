@@ -0,0 +1,113 @@
+package clickhouse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	v1_trace "go.opentelemetry.io/proto/otlp/trace/v1"
+	"go.uber.org/zap"
+)
+
+const (
+	// CREATE_ARCHIVE_TABLE_SQL has the columns of the traces table of the clickhouse exporter,
+	// without its TTL and ordered by trace as the archive is only read by trace ID.
+	CREATE_ARCHIVE_TABLE_SQL = `CREATE TABLE IF NOT EXISTS %s (
+     Timestamp DateTime64(9) CODEC(Delta, ZSTD(1)),
+     TraceId String CODEC(ZSTD(1)),
+     SpanId String CODEC(ZSTD(1)),
+     ParentSpanId String CODEC(ZSTD(1)),
+     TraceState String CODEC(ZSTD(1)),
+     SpanName LowCardinality(String) CODEC(ZSTD(1)),
+     SpanKind LowCardinality(String) CODEC(ZSTD(1)),
+     ServiceName LowCardinality(String) CODEC(ZSTD(1)),
+     ResourceAttributes Map(LowCardinality(String), String) CODEC(ZSTD(1)),
+     ScopeName String CODEC(ZSTD(1)),
+     ScopeVersion String CODEC(ZSTD(1)),
+     SpanAttributes Map(LowCardinality(String), String) CODEC(ZSTD(1)),
+     Duration Int64 CODEC(ZSTD(1)),
+     StatusCode LowCardinality(String) CODEC(ZSTD(1)),
+     StatusMessage String CODEC(ZSTD(1)),
+     Events Nested (
+         Timestamp DateTime64(9),
+         Name LowCardinality(String),
+         Attributes Map(LowCardinality(String), String)
+     ) CODEC(ZSTD(1)),
+     Links Nested (
+         TraceId String,
+         SpanId String,
+         TraceState String,
+         Attributes Map(LowCardinality(String), String)
+     ) CODEC(ZSTD(1))
+) ENGINE MergeTree()
+PARTITION BY toYYYYMM(Timestamp)
+ORDER BY (TraceId, toUnixTimestamp(Timestamp))`
+	ARCHIVE_COLUMNS = `Timestamp, TraceId, SpanId, ParentSpanId, TraceState, SpanName, SpanKind, ServiceName,
+       ResourceAttributes, ScopeName, ScopeVersion, SpanAttributes, Duration, StatusCode, StatusMessage,
+       Events.Timestamp, Events.Name, Events.Attributes,
+       Links.TraceId, Links.SpanId, Links.TraceState, Links.Attributes`
+	// ARCHIVE_TRACE_SQL skips the spans already archived, so archiving a trace again doesn't duplicate them.
+	ARCHIVE_TRACE_SQL = `INSERT INTO %s (%s) SELECT %s FROM %s
+       WHERE TraceId = ? AND SpanId NOT IN (SELECT SpanId FROM %s WHERE TraceId = ?)`
+	COUNT_TRACE_SPANS_SQL = "SELECT count() AS Count FROM %s WHERE TraceId = ?"
+	ARCHIVED_TRACE_SQL    = "SELECT %s FROM %s AS a WHERE a.TraceId = ?"
+)
+
+var _ datasource.TraceArchive = (*ClickHouseTraceArchive)(nil)
+
+// ClickHouseTraceArchive copies the spans of a trace into an archive table without TTL.
+type ClickHouseTraceArchive struct {
+	logger           *zap.Logger
+	client           clickhouse.Conn
+	tracingTableName string
+	archiveTableName string
+}
+
+type countModel struct {
+	Count uint64 `ch:"Count"`
+}
+
+// createArchiveTable creates the archive table when it doesn't exist.
+func createArchiveTable(ctx context.Context, conn clickhouse.Conn, tableName string) error {
+	if err := conn.Exec(ctx, fmt.Sprintf(CREATE_ARCHIVE_TABLE_SQL, tableName)); err != nil {
+		return fmt.Errorf("create archive table %s: %w", tableName, err)
+	}
+	return nil
+}
+
+func (a *ClickHouseTraceArchive) ArchiveTrace(ctx context.Context, traceID string) (int, error) {
+	if traceID == "" {
+		return 0, errors.New("traceID must not empty")
+	}
+
+	var counts []countModel
+	if err := a.client.Select(ctx, &counts, fmt.Sprintf(COUNT_TRACE_SPANS_SQL, a.tracingTableName), traceID); err != nil {
+		return 0, err
+	}
+	if len(counts) == 0 || counts[0].Count == 0 {
+		return 0, nil
+	}
+	sql := fmt.Sprintf(ARCHIVE_TRACE_SQL, a.archiveTableName, ARCHIVE_COLUMNS, ARCHIVE_COLUMNS, a.tracingTableName, a.archiveTableName)
+	if err := a.client.Exec(ctx, sql, traceID, traceID); err != nil {
+		return 0, err
+	}
+	return int(counts[0].Count), nil
+}
+
+func (a *ClickHouseTraceArchive) GetArchivedTrace(ctx context.Context, traceID string) (*v1_trace.TracesData, error) {
+	if traceID == "" {
+		return nil, errors.New("traceID must not empty")
+	}
+
+	sql := fmt.Sprintf(ARCHIVED_TRACE_SQL, TRACES_COLUMNS, a.archiveTableName)
+	if limit := datasource.SpanLimit(ctx); limit > 0 {
+		sql = fmt.Sprintf("%s %s", sql, fmt.Sprintf(LIMIT_PATTERN, limit+1))
+	}
+	var result []TracesModel
+	if err := a.client.Select(ctx, &result, sql, traceID); err != nil {
+		return nil, err
+	}
+	return parseSpanResults(result), nil
+}
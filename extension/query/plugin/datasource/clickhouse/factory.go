@@ -2,6 +2,7 @@ package clickhouse
 
 import (
 	"context"
+	"errors"
 	"io"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
@@ -12,12 +13,16 @@ import (
 
 var (
 	_ io.Closer = (*Factory)(nil)
+
+	errNotInitialized = errors.New("clickhouse connection is not initialized")
 )
 
 const (
 	LoggingTableName = "otel_logs"
 	TracingTableName = "otel_traces"
 	MetricsTableName = "otel_metrics"
+	// SavedQueriesTableName is the default table of the saved queries.
+	SavedQueriesTableName = "otel_saved_queries"
 )
 
 type ClickhouseType struct {
@@ -26,6 +31,11 @@ type ClickhouseType struct {
 	LoggingTableName string                     `mapstructure:"logging_table_name"`
 	TracingTableName string                     `mapstructure:"tracing_table_name"`
 	MetricsTableName string                     `mapstructure:"metrics_table_name"`
+	// ArchiveTableName is the table without TTL the archived traces are copied into, it is created
+	// at start. The traces can't be archived when it is empty.
+	ArchiveTableName string `mapstructure:"archive_table_name"`
+	// SavedQueriesTableName is the table of the saved queries, created when they are stored in ClickHouse.
+	SavedQueriesTableName string `mapstructure:"saved_queries_table_name"`
 }
 
 // Factory implements storage.Factory for Elasticsearch as storage.
@@ -58,8 +68,15 @@ func (f *Factory) Initialize(logger *zap.Logger) error {
 		return err
 	}
 	if err = conn.Ping(context.Background()); err != nil {
+		_ = conn.Close()
 		return err
 	}
+	if f.cfg.ArchiveTableName != "" {
+		if err = createArchiveTable(context.Background(), conn, f.cfg.ArchiveTableName); err != nil {
+			_ = conn.Close()
+			return err
+		}
+	}
 
 	f.client = conn
 	f.logger = logger
@@ -78,6 +95,10 @@ func (f *Factory) CreatDefaultConfig(cfg *ClickhouseType) *ClickhouseType {
 	if cfg.MetricsTableName == "" {
 		cfg.MetricsTableName = MetricsTableName
 	}
+
+	if cfg.SavedQueriesTableName == "" {
+		cfg.SavedQueriesTableName = SavedQueriesTableName
+	}
 	return cfg
 }
 
@@ -91,9 +112,72 @@ func (f *Factory) CreateSpanQuery() (datasource.Query, error) {
 	}, nil
 }
 
+func (f *Factory) CreateLogQuery() (datasource.LogQuery, error) {
+	return &ClickHouseLogQuery{
+		logger:           f.logger,
+		client:           f.client,
+		loggingTableName: f.cfg.LoggingTableName,
+	}, nil
+}
+
+func (f *Factory) CreateMetricQuery() (datasource.MetricQuery, error) {
+	return &ClickHouseMetricQuery{
+		logger:           f.logger,
+		client:           f.client,
+		metricsTableName: f.cfg.MetricsTableName,
+	}, nil
+}
+
+// CreateTraceArchive creates the archive of the traces in the archive table.
+func (f *Factory) CreateTraceArchive() (datasource.TraceArchive, error) {
+	if f.cfg.ArchiveTableName == "" {
+		return nil, datasource.ErrArchiveNotConfigured
+	}
+	return &ClickHouseTraceArchive{
+		logger:           f.logger,
+		client:           f.client,
+		tracingTableName: f.cfg.TracingTableName,
+		archiveTableName: f.cfg.ArchiveTableName,
+	}, nil
+}
+
+// CreateDependencyReader returns datasource.ErrDependenciesNotConfigured, the dependency links
+// are not stored in ClickHouse.
+func (f *Factory) CreateDependencyReader() (datasource.DependencyReader, error) {
+	return nil, datasource.ErrDependenciesNotConfigured
+}
+
+// CreateSavedQueryStore creates the store of the saved queries, and their table if it doesn't exist.
+func (f *Factory) CreateSavedQueryStore() (datasource.SavedQueryStore, error) {
+	if f.client == nil {
+		return nil, errNotInitialized
+	}
+	if err := createSavedQueriesTable(context.Background(), f.client, f.cfg.SavedQueriesTableName); err != nil {
+		return nil, err
+	}
+	return &ClickHouseSavedQueryStore{
+		logger:    f.logger,
+		client:    f.client,
+		tableName: f.cfg.SavedQueriesTableName,
+	}, nil
+}
+
+// Ping checks the connectivity of the ClickHouse server.
+func (f *Factory) Ping(ctx context.Context) error {
+	if f.client == nil {
+		return errNotInitialized
+	}
+	return f.client.Ping(ctx)
+}
+
 // Close closes the resources held by the factory
 func (f *Factory) Close() error {
-	return nil
+	if f.client == nil {
+		return nil
+	}
+	err := f.client.Close()
+	f.client = nil
+	return err
 }
 
 // NewFactory creates a new Factory.
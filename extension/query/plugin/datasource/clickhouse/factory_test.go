@@ -3,6 +3,7 @@ package clickhouse
 import (
 	"testing"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/config/configtls"
 )
@@ -28,3 +29,24 @@ func TestCreateSpanQuery(t *testing.T) {
 	require.NotNil(t, query)
 	require.NoError(t, err)
 }
+
+func TestCreateTraceArchive(t *testing.T) {
+	_, err := NewFactory(&ct).CreateTraceArchive()
+	require.ErrorIs(t, err, datasource.ErrArchiveNotConfigured)
+
+	cfg := ct
+	cfg.ArchiveTableName = "otel_traces_archive"
+	archive, err := NewFactory(&cfg).CreateTraceArchive()
+	require.NoError(t, err)
+	require.Equal(t, "otel_traces_archive", archive.(*ClickHouseTraceArchive).archiveTableName)
+}
+
+func TestBuildSavedQueriesQuery(t *testing.T) {
+	sql, args := buildSavedQueriesQuery(nil, SavedQueriesTableName)
+	require.Equal(t, "SELECT Document FROM otel_saved_queries FINAL WHERE Deleted = 0", sql)
+	require.Empty(t, args)
+
+	sql, args = buildSavedQueriesQuery(&datasource.SavedQueryFilter{Owner: "oncall", Tag: "frontend"}, SavedQueriesTableName)
+	require.Equal(t, "SELECT Document FROM otel_saved_queries FINAL WHERE Deleted = 0 AND Owner = ? AND has(Tags, ?)", sql)
+	require.Equal(t, []interface{}{"oncall", "frontend"}, args)
+}
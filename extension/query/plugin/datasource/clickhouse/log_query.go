@@ -0,0 +1,222 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"go.uber.org/zap"
+)
+
+const (
+	QUERY_LOGS_SQL         = "SELECT Timestamp, Body, ResourceAttributes FROM %s %s ORDER BY Timestamp %s LIMIT %d"
+	COUNT_LOGS_SQL         = "SELECT ResourceAttributes, toStartOfInterval(Timestamp, INTERVAL %d SECOND) AS Bucket, count() AS Count FROM %s %s GROUP BY ResourceAttributes, Bucket ORDER BY Bucket"
+	QUERY_LOG_LABELS_SQL   = "SELECT DISTINCT arrayJoin(mapKeys(ResourceAttributes)) AS Label FROM %s %s ORDER BY Label LIMIT %d"
+	QUERY_LOG_VALUES_SQL   = "SELECT DISTINCT ResourceAttributes[?] AS Value FROM %s %s ORDER BY Value LIMIT %d"
+	QUERY_LOG_STREAMS_SQL  = "SELECT DISTINCT ResourceAttributes FROM %s %s LIMIT %d"
+	DEFAULT_LOG_LIMIT_NUM  = 100
+	MAX_LOG_LABELS_NUM     = 1000
+	MAX_LOG_STREAMS_NUM    = 1000
+	LOG_TIME_START_PATTERN = "Timestamp >= fromUnixTimestamp64Nano(?)"
+	LOG_TIME_END_PATTERN   = "Timestamp <= fromUnixTimestamp64Nano(?)"
+)
+
+// ClickHouseLogQuery reads the `otel_logs` table written by the clickhouse exporter,
+// the filters are bound as query parameters.
+type ClickHouseLogQuery struct {
+	logger           *zap.Logger
+	client           clickhouse.Conn
+	loggingTableName string
+}
+
+var _ datasource.LogQuery = (*ClickHouseLogQuery)(nil)
+
+type LogsModel struct {
+	Timestamp          time.Time         `ch:"Timestamp"`
+	Body               string            `ch:"Body"`
+	ResourceAttributes map[string]string `ch:"ResourceAttributes"`
+}
+
+type LogCountModel struct {
+	ResourceAttributes map[string]string `ch:"ResourceAttributes"`
+	Bucket             time.Time         `ch:"Bucket"`
+	Count              uint64            `ch:"Count"`
+}
+
+func (q *ClickHouseLogQuery) FindLogs(ctx context.Context, query *datasource.LogQueryParameters) ([]*datasource.LogEntry, error) {
+	sql, args := buildLogsQuery(query, q.loggingTableName)
+	var result []LogsModel
+	if err := q.client.Select(ctx, &result, sql, args...); err != nil {
+		return nil, err
+	}
+
+	entries := make([]*datasource.LogEntry, 0, len(result))
+	for _, r := range result {
+		entries = append(entries, &datasource.LogEntry{Timestamp: r.Timestamp, Labels: r.ResourceAttributes, Line: r.Body})
+	}
+	return entries, nil
+}
+
+func (q *ClickHouseLogQuery) CountLogs(ctx context.Context, query *datasource.LogQueryParameters) ([]*datasource.LogSeries, error) {
+	sql, args, err := buildCountLogsQuery(query, q.loggingTableName)
+	if err != nil {
+		return nil, err
+	}
+	var result []LogCountModel
+	if err = q.client.Select(ctx, &result, sql, args...); err != nil {
+		return nil, err
+	}
+
+	// group the buckets by stream, the rows are ordered by bucket
+	var series []*datasource.LogSeries
+	streams := map[string]*datasource.LogSeries{}
+	for _, r := range result {
+		id := generateAttributesId(r.ResourceAttributes)
+		s, ok := streams[id]
+		if !ok {
+			s = &datasource.LogSeries{Labels: r.ResourceAttributes}
+			streams[id] = s
+			series = append(series, s)
+		}
+		s.Samples = append(s.Samples, datasource.LogSample{Timestamp: r.Bucket, Count: int64(r.Count)})
+	}
+	return series, nil
+}
+
+func (q *ClickHouseLogQuery) LogLabelNames(ctx context.Context, start, end time.Time) ([]string, error) {
+	where, args := buildLogsCondition(&datasource.LogQueryParameters{StartTime: start, EndTime: end})
+	sql := fmt.Sprintf(QUERY_LOG_LABELS_SQL, q.loggingTableName, where, MAX_LOG_LABELS_NUM)
+
+	var result []struct {
+		Label string `ch:"Label"`
+	}
+	if err := q.client.Select(ctx, &result, sql, args...); err != nil {
+		return nil, err
+	}
+	labels := make([]string, 0, len(result))
+	for _, r := range result {
+		labels = append(labels, r.Label)
+	}
+	return labels, nil
+}
+
+func (q *ClickHouseLogQuery) LogLabelValues(ctx context.Context, name string, start, end time.Time) ([]string, error) {
+	where, args := buildLogsCondition(&datasource.LogQueryParameters{StartTime: start, EndTime: end})
+	if where == "" {
+		where = "WHERE mapContains(ResourceAttributes, ?)"
+	} else {
+		where += " AND mapContains(ResourceAttributes, ?)"
+	}
+	sql := fmt.Sprintf(QUERY_LOG_VALUES_SQL, q.loggingTableName, where, MAX_LOG_LABELS_NUM)
+	args = append([]interface{}{name}, append(args, name)...)
+
+	var result []struct {
+		Value string `ch:"Value"`
+	}
+	if err := q.client.Select(ctx, &result, sql, args...); err != nil {
+		return nil, err
+	}
+	values := make([]string, 0, len(result))
+	for _, r := range result {
+		values = append(values, r.Value)
+	}
+	return values, nil
+}
+
+func (q *ClickHouseLogQuery) LogStreams(ctx context.Context, query *datasource.LogQueryParameters) ([]map[string]string, error) {
+	where, args := buildLogsCondition(query)
+	sql := fmt.Sprintf(QUERY_LOG_STREAMS_SQL, q.loggingTableName, where, MAX_LOG_STREAMS_NUM)
+
+	var result []struct {
+		ResourceAttributes map[string]string `ch:"ResourceAttributes"`
+	}
+	if err := q.client.Select(ctx, &result, sql, args...); err != nil {
+		return nil, err
+	}
+	streams := make([]map[string]string, 0, len(result))
+	for _, r := range result {
+		streams = append(streams, r.ResourceAttributes)
+	}
+	sort.Slice(streams, func(i, j int) bool {
+		return generateAttributesId(streams[i]) < generateAttributesId(streams[j])
+	})
+	return streams, nil
+}
+
+func buildLogsQuery(query *datasource.LogQueryParameters, tableName string) (string, []interface{}) {
+	where, args := buildLogsCondition(query)
+	order := "DESC"
+	if query.Forward {
+		order = "ASC"
+	}
+	limit := DEFAULT_LOG_LIMIT_NUM
+	if query.Limit > 0 {
+		limit = query.Limit
+	}
+	return fmt.Sprintf(QUERY_LOGS_SQL, tableName, where, order, limit), args
+}
+
+func buildCountLogsQuery(query *datasource.LogQueryParameters, tableName string) (string, []interface{}, error) {
+	step := int64(query.Step / time.Second)
+	if step <= 0 {
+		return "", nil, fmt.Errorf("step %s must be at least one second", query.Step)
+	}
+	where, args := buildLogsCondition(query)
+	return fmt.Sprintf(COUNT_LOGS_SQL, step, tableName, where), args, nil
+}
+
+// buildLogsCondition builds the WHERE clause of the time range, label matchers and line filters.
+func buildLogsCondition(query *datasource.LogQueryParameters) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	if !query.StartTime.IsZero() {
+		conditions = append(conditions, LOG_TIME_START_PATTERN)
+		args = append(args, query.StartTime.UnixNano())
+	}
+	if !query.EndTime.IsZero() {
+		conditions = append(conditions, LOG_TIME_END_PATTERN)
+		args = append(args, query.EndTime.UnixNano())
+	}
+	for _, m := range query.Matchers {
+		switch m.Type {
+		case datasource.MatchEqual:
+			conditions = append(conditions, "ResourceAttributes[?] = ?")
+			args = append(args, m.Name, m.Value)
+		case datasource.MatchNotEqual:
+			conditions = append(conditions, "ResourceAttributes[?] != ?")
+			args = append(args, m.Name, m.Value)
+		case datasource.MatchRegexp:
+			conditions = append(conditions, "match(ResourceAttributes[?], ?)")
+			args = append(args, m.Name, anchorRegexp(m.Value))
+		case datasource.MatchNotRegexp:
+			conditions = append(conditions, "NOT match(ResourceAttributes[?], ?)")
+			args = append(args, m.Name, anchorRegexp(m.Value))
+		}
+	}
+	for _, f := range query.LineFilters {
+		switch f.Type {
+		case datasource.MatchEqual:
+			conditions = append(conditions, "position(Body, ?) > 0")
+		case datasource.MatchNotEqual:
+			conditions = append(conditions, "position(Body, ?) = 0")
+		case datasource.MatchRegexp:
+			conditions = append(conditions, "match(Body, ?)")
+		case datasource.MatchNotRegexp:
+			conditions = append(conditions, "NOT match(Body, ?)")
+		}
+		args = append(args, f.Value)
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// anchorRegexp makes the label regular expression match the whole value, as LogQL does.
+func anchorRegexp(re string) string {
+	return "^(?:" + re + ")$"
+}
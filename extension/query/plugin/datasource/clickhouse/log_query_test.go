@@ -0,0 +1,66 @@
+package clickhouse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildLogsQuery(t *testing.T) {
+	start := time.Unix(1681873000, 0)
+	end := time.Unix(1681876600, 0)
+	query := &datasource.LogQueryParameters{
+		Matchers: []datasource.LabelMatcher{
+			{Name: "service.name", Type: datasource.MatchEqual, Value: "frontend"},
+			{Name: "host.name", Type: datasource.MatchNotRegexp, Value: "test-.*"},
+		},
+		LineFilters: []datasource.LineFilter{
+			{Type: datasource.MatchEqual, Value: "error"},
+			{Type: datasource.MatchNotRegexp, Value: "timeout|refused"},
+		},
+		StartTime: start,
+		EndTime:   end,
+		Limit:     50,
+	}
+
+	sql, args := buildLogsQuery(query, "otel_logs")
+	assert.Equal(t, "SELECT Timestamp, Body, ResourceAttributes FROM otel_logs WHERE "+
+		"Timestamp >= fromUnixTimestamp64Nano(?) AND Timestamp <= fromUnixTimestamp64Nano(?) AND "+
+		"ResourceAttributes[?] = ? AND NOT match(ResourceAttributes[?], ?) AND "+
+		"position(Body, ?) > 0 AND NOT match(Body, ?) ORDER BY Timestamp DESC LIMIT 50", sql)
+	assert.Equal(t, []interface{}{
+		start.UnixNano(), end.UnixNano(),
+		"service.name", "frontend", "host.name", "^(?:test-.*)$",
+		"error", "timeout|refused",
+	}, args)
+
+	query.Forward = true
+	query.Limit = 0
+	sql, _ = buildLogsQuery(query, "otel_logs")
+	assert.Contains(t, sql, "ORDER BY Timestamp ASC LIMIT 100")
+}
+
+func TestBuildCountLogsQuery(t *testing.T) {
+	query := &datasource.LogQueryParameters{
+		Matchers: []datasource.LabelMatcher{{Name: "service.name", Type: datasource.MatchRegexp, Value: "front.*"}},
+		Step:     time.Minute,
+	}
+	sql, args, err := buildCountLogsQuery(query, "otel_logs")
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT ResourceAttributes, toStartOfInterval(Timestamp, INTERVAL 60 SECOND) AS Bucket, count() AS Count "+
+		"FROM otel_logs WHERE match(ResourceAttributes[?], ?) GROUP BY ResourceAttributes, Bucket ORDER BY Bucket", sql)
+	assert.Equal(t, []interface{}{"service.name", "^(?:front.*)$"}, args)
+
+	query.Step = 0
+	_, _, err = buildCountLogsQuery(query, "otel_logs")
+	assert.Error(t, err)
+}
+
+func TestCreateLogQuery(t *testing.T) {
+	query, err := NewFactory(&ct).CreateLogQuery()
+	require.NoError(t, err)
+	require.NotNil(t, query)
+}
@@ -0,0 +1,419 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"go.uber.org/zap"
+)
+
+const (
+	QUERY_GAUGE_SAMPLES_SQL     = "SELECT MetricName, ResourceAttributes['service.name'] AS Job, ResourceAttributes['service.instance.id'] AS Instance, Attributes, TimeUnix, Value, toInt32(0) AS AggTemp FROM %s_gauge %s ORDER BY TimeUnix LIMIT %d"
+	QUERY_SUM_SAMPLES_SQL       = "SELECT MetricName, ResourceAttributes['service.name'] AS Job, ResourceAttributes['service.instance.id'] AS Instance, Attributes, TimeUnix, Value, AggTemp FROM %s_sum %s ORDER BY TimeUnix LIMIT %d"
+	QUERY_HISTOGRAM_SAMPLES_SQL = "SELECT MetricName, ResourceAttributes['service.name'] AS Job, ResourceAttributes['service.instance.id'] AS Instance, Attributes, TimeUnix, Count, Sum, BucketCounts, ExplicitBounds FROM %s_histogram %s ORDER BY TimeUnix LIMIT %d"
+	QUERY_SERIES_SQL            = "SELECT DISTINCT MetricName, ResourceAttributes['service.name'] AS Job, ResourceAttributes['service.instance.id'] AS Instance, Attributes FROM %s_%s %s LIMIT %d"
+	QUERY_HISTOGRAM_SERIES_SQL  = "SELECT DISTINCT MetricName, ResourceAttributes['service.name'] AS Job, ResourceAttributes['service.instance.id'] AS Instance, Attributes, ExplicitBounds FROM %s_histogram %s LIMIT %d"
+	MAX_METRIC_SERIES_NUM       = 10000
+	MAX_METRIC_SAMPLES_NUM      = 500000
+	METRIC_TIME_START_PATTERN   = "TimeUnix >= fromUnixTimestamp64Nano(?)"
+	METRIC_TIME_END_PATTERN     = "TimeUnix <= fromUnixTimestamp64Nano(?)"
+	// METRIC_NAME_PATTERN matches the metric names sanitized into a name, as every invalid
+	// character is replaced by `_`, which is also the single character wildcard of LIKE.
+	METRIC_NAME_PATTERN = "MetricName LIKE ?"
+	// METRIC_PROM_NAME_EXPR is promMetricName of the metric name.
+	METRIC_PROM_NAME_EXPR = "replaceRegexpAll(MetricName, '[^a-zA-Z0-9_:]', '_')"
+	// METRIC_ATTRIBUTE_EXPR is the value of the attribute whose key is sanitized by
+	// promLabelName into the bound label name, or the empty value when there is none.
+	METRIC_ATTRIBUTE_EXPR = "arrayFirst((v, k) -> replaceRegexpAll(if(match(k, '^[0-9]'), concat('_', k), k), '[^a-zA-Z0-9_]', '_') = ?, mapValues(Attributes), mapKeys(Attributes))"
+	// METRIC_RESOURCE_EXPR is the value of a resource attribute mapped to a label, the attribute
+	// of the same label name is used when it is empty.
+	METRIC_RESOURCE_EXPR = "if(ResourceAttributes['%[1]s'] != '', ResourceAttributes['%[1]s'], " + METRIC_ATTRIBUTE_EXPR + ")"
+
+	gaugeTableSuffix     = "gauge"
+	sumTableSuffix       = "sum"
+	histogramTableSuffix = "histogram"
+
+	histogramBucketSuffix = "_bucket"
+	histogramSumSuffix    = "_sum"
+	histogramCountSuffix  = "_count"
+	histogramBucketLabel  = "le"
+	jobLabel              = "job"
+	instanceLabel         = "instance"
+
+	// aggregationTemporalityDelta is pmetric.AggregationTemporalityDelta.
+	aggregationTemporalityDelta = 1
+)
+
+// ClickHouseMetricQuery reads the `otel_metrics_gauge`, `otel_metrics_sum` and
+// `otel_metrics_histogram` tables written by the clickhouse exporter as Prometheus series.
+// The metric name and the attribute keys are sanitized into Prometheus names, the `job` and
+// `instance` labels are the `service.name` and `service.instance.id` resource attributes, and
+// a histogram is exposed as its `_bucket{le="..."}`, `_sum` and `_count` series. The delta sums
+// are accumulated into cumulative series over the time range.
+// The time range and the matchers are pushed down to SQL, the series are matched against all
+// the matchers again once their labels are built as the histogram series and the sanitized
+// names are only narrowed down by SQL. A query reading more than MAX_METRIC_SAMPLES_NUM rows
+// or MAX_METRIC_SERIES_NUM series fails with datasource.ErrTooManySamples.
+type ClickHouseMetricQuery struct {
+	logger           *zap.Logger
+	client           clickhouse.Conn
+	metricsTableName string
+}
+
+var _ datasource.MetricQuery = (*ClickHouseMetricQuery)(nil)
+
+type MetricSampleModel struct {
+	MetricName string            `ch:"MetricName"`
+	Job        string            `ch:"Job"`
+	Instance   string            `ch:"Instance"`
+	Attributes map[string]string `ch:"Attributes"`
+	TimeUnix   time.Time         `ch:"TimeUnix"`
+	Value      float64           `ch:"Value"`
+	AggTemp    int32             `ch:"AggTemp"`
+}
+
+type HistogramSampleModel struct {
+	MetricName     string            `ch:"MetricName"`
+	Job            string            `ch:"Job"`
+	Instance       string            `ch:"Instance"`
+	Attributes     map[string]string `ch:"Attributes"`
+	TimeUnix       time.Time         `ch:"TimeUnix"`
+	Count          int64             `ch:"Count"`
+	Sum            float64           `ch:"Sum"`
+	BucketCounts   []uint64          `ch:"BucketCounts"`
+	ExplicitBounds []float64         `ch:"ExplicitBounds"`
+}
+
+type MetricSeriesModel struct {
+	MetricName     string            `ch:"MetricName"`
+	Job            string            `ch:"Job"`
+	Instance       string            `ch:"Instance"`
+	Attributes     map[string]string `ch:"Attributes"`
+	ExplicitBounds []float64         `ch:"ExplicitBounds"`
+}
+
+func (q *ClickHouseMetricQuery) SelectSeries(ctx context.Context, query *datasource.MetricQueryParameters) ([]*datasource.MetricSeries, error) {
+	filter, err := datasource.NewLabelFilter(query.Matchers)
+	if err != nil {
+		return nil, err
+	}
+	builder := newSeriesBuilder(filter)
+	// rows is the number of rows the next table may return
+	rows := MAX_METRIC_SAMPLES_NUM
+
+	for _, table := range []struct {
+		suffix string
+		sql    string
+	}{{gaugeTableSuffix, QUERY_GAUGE_SAMPLES_SQL}, {sumTableSuffix, QUERY_SUM_SAMPLES_SQL}} {
+		where, args, ok := buildMetricsCondition(query, table.suffix)
+		if !ok {
+			continue
+		}
+		var result []MetricSampleModel
+		if err = q.client.Select(ctx, &result, fmt.Sprintf(table.sql, q.metricsTableName, where, rows+1), args...); err != nil {
+			return nil, err
+		}
+		if rows -= len(result); rows < 0 {
+			return nil, tooManySamplesError()
+		}
+		for _, r := range result {
+			labels := metricLabels(promMetricName(r.MetricName), r.Job, r.Instance, r.Attributes)
+			builder.add(labels, r.TimeUnix, r.Value, r.AggTemp == aggregationTemporalityDelta)
+		}
+	}
+
+	if where, args, ok := buildMetricsCondition(query, histogramTableSuffix); ok {
+		var result []HistogramSampleModel
+		if err = q.client.Select(ctx, &result, fmt.Sprintf(QUERY_HISTOGRAM_SAMPLES_SQL, q.metricsTableName, where, rows+1), args...); err != nil {
+			return nil, err
+		}
+		if len(result) > rows {
+			return nil, tooManySamplesError()
+		}
+		for _, r := range result {
+			name := promMetricName(r.MetricName)
+			var cumulative uint64
+			for i, bound := range r.ExplicitBounds {
+				if i < len(r.BucketCounts) {
+					cumulative += r.BucketCounts[i]
+				}
+				builder.add(bucketLabels(name, r.Job, r.Instance, r.Attributes, formatBound(bound)), r.TimeUnix, float64(cumulative), false)
+			}
+			builder.add(bucketLabels(name, r.Job, r.Instance, r.Attributes, "+Inf"), r.TimeUnix, float64(r.Count), false)
+			builder.add(metricLabels(name+histogramSumSuffix, r.Job, r.Instance, r.Attributes), r.TimeUnix, r.Sum, false)
+			builder.add(metricLabels(name+histogramCountSuffix, r.Job, r.Instance, r.Attributes), r.TimeUnix, float64(r.Count), false)
+		}
+	}
+	if len(builder.series) > MAX_METRIC_SERIES_NUM {
+		return nil, fmt.Errorf("%w: more than %d series", datasource.ErrTooManySamples, MAX_METRIC_SERIES_NUM)
+	}
+	return builder.build(), nil
+}
+
+func tooManySamplesError() error {
+	return fmt.Errorf("%w: more than %d samples", datasource.ErrTooManySamples, MAX_METRIC_SAMPLES_NUM)
+}
+
+func (q *ClickHouseMetricQuery) SeriesLabels(ctx context.Context, query *datasource.MetricQueryParameters) ([]map[string]string, error) {
+	filter, err := datasource.NewLabelFilter(query.Matchers)
+	if err != nil {
+		return nil, err
+	}
+	var series []map[string]string
+	seen := map[string]struct{}{}
+	add := func(labels map[string]string) {
+		if !filter.Matches(labels) {
+			return
+		}
+		id := generateAttributesId(labels)
+		if _, ok := seen[id]; ok {
+			return
+		}
+		seen[id] = struct{}{}
+		series = append(series, labels)
+	}
+
+	for _, suffix := range []string{gaugeTableSuffix, sumTableSuffix, histogramTableSuffix} {
+		where, args, ok := buildMetricsCondition(query, suffix)
+		if !ok {
+			continue
+		}
+		sql := fmt.Sprintf(QUERY_SERIES_SQL, q.metricsTableName, suffix, where, MAX_METRIC_SERIES_NUM+1)
+		if suffix == histogramTableSuffix {
+			sql = fmt.Sprintf(QUERY_HISTOGRAM_SERIES_SQL, q.metricsTableName, where, MAX_METRIC_SERIES_NUM+1)
+		}
+		var result []MetricSeriesModel
+		if err = q.client.Select(ctx, &result, sql, args...); err != nil {
+			return nil, err
+		}
+		if len(result) > MAX_METRIC_SERIES_NUM {
+			return nil, fmt.Errorf("%w: more than %d series", datasource.ErrTooManySamples, MAX_METRIC_SERIES_NUM)
+		}
+		for _, r := range result {
+			name := promMetricName(r.MetricName)
+			if suffix != histogramTableSuffix {
+				add(metricLabels(name, r.Job, r.Instance, r.Attributes))
+				continue
+			}
+			for _, bound := range r.ExplicitBounds {
+				add(bucketLabels(name, r.Job, r.Instance, r.Attributes, formatBound(bound)))
+			}
+			add(bucketLabels(name, r.Job, r.Instance, r.Attributes, "+Inf"))
+			add(metricLabels(name+histogramSumSuffix, r.Job, r.Instance, r.Attributes))
+			add(metricLabels(name+histogramCountSuffix, r.Job, r.Instance, r.Attributes))
+		}
+	}
+	if len(series) > MAX_METRIC_SERIES_NUM {
+		return nil, fmt.Errorf("%w: more than %d series", datasource.ErrTooManySamples, MAX_METRIC_SERIES_NUM)
+	}
+	sort.Slice(series, func(i, j int) bool {
+		return generateAttributesId(series[i]) < generateAttributesId(series[j])
+	})
+	return series, nil
+}
+
+// buildMetricsCondition builds the WHERE clause of a metrics table, it returns false when the
+// metric name matchers exclude every series of the table.
+func buildMetricsCondition(query *datasource.MetricQueryParameters, suffix string) (string, []interface{}, bool) {
+	var conditions []string
+	var args []interface{}
+	if !query.StartTime.IsZero() {
+		conditions = append(conditions, METRIC_TIME_START_PATTERN)
+		args = append(args, query.StartTime.UnixNano())
+	}
+	if !query.EndTime.IsZero() {
+		conditions = append(conditions, METRIC_TIME_END_PATTERN)
+		args = append(args, query.EndTime.UnixNano())
+	}
+	for _, m := range query.Matchers {
+		switch {
+		case m.Name == datasource.MetricNameLabel && m.Type == datasource.MatchEqual:
+			name := m.Value
+			if suffix == histogramTableSuffix {
+				var ok bool
+				if name, ok = histogramMetricName(name); !ok {
+					return "", nil, false
+				}
+			}
+			conditions = append(conditions, METRIC_NAME_PATTERN)
+			args = append(args, name)
+		case m.Name == datasource.MetricNameLabel && suffix == histogramTableSuffix:
+			if condition, ok := histogramNameCondition(m); ok {
+				conditions = append(conditions, condition)
+				args = append(args, m.Value, m.Value, m.Value)
+			}
+		case m.Name == datasource.MetricNameLabel:
+			conditions = append(conditions, matcherCondition(METRIC_PROM_NAME_EXPR, m.Type))
+			args = append(args, m.Value)
+		case m.Name == histogramBucketLabel && suffix == histogramTableSuffix:
+			// the bucket bounds are only labeled once the rows are read
+		default:
+			expr := METRIC_ATTRIBUTE_EXPR
+			switch m.Name {
+			case jobLabel:
+				expr = fmt.Sprintf(METRIC_RESOURCE_EXPR, "service.name")
+			case instanceLabel:
+				expr = fmt.Sprintf(METRIC_RESOURCE_EXPR, "service.instance.id")
+			}
+			conditions = append(conditions, matcherCondition(expr, m.Type))
+			args = append(args, m.Name, m.Value)
+		}
+	}
+	if len(conditions) == 0 {
+		return "", nil, true
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args, true
+}
+
+// matcherCondition matches the value of expr with a matcher whose value is bound after the
+// arguments of expr, the regular expressions are fully anchored as in datasource.LabelFilter.
+func matcherCondition(expr string, matchType datasource.MatchType) string {
+	switch matchType {
+	case datasource.MatchNotEqual:
+		return expr + " != ?"
+	case datasource.MatchRegexp:
+		return "match(" + expr + ", concat('^(?:', ?, ')$'))"
+	case datasource.MatchNotRegexp:
+		return "NOT match(" + expr + ", concat('^(?:', ?, ')$'))"
+	default:
+		return expr + " = ?"
+	}
+}
+
+// histogramNameCondition narrows a histogram down to the metrics with one of the `_bucket`,
+// `_sum` and `_count` series matching a regular expression matcher, it returns false for the
+// other matchers as every histogram has a series name which is not equal to the value.
+func histogramNameCondition(m datasource.LabelMatcher) (string, bool) {
+	var matches []string
+	for _, suffix := range []string{histogramBucketSuffix, histogramSumSuffix, histogramCountSuffix} {
+		matches = append(matches, matcherCondition("concat("+METRIC_PROM_NAME_EXPR+", '"+suffix+"')", datasource.MatchRegexp))
+	}
+	switch m.Type {
+	case datasource.MatchRegexp:
+		return "(" + strings.Join(matches, " OR ") + ")", true
+	case datasource.MatchNotRegexp:
+		return "NOT (" + strings.Join(matches, " AND ") + ")", true
+	default:
+		return "", false
+	}
+}
+
+// histogramMetricName returns the name of the histogram metric exposed as the series name.
+func histogramMetricName(name string) (string, bool) {
+	for _, suffix := range []string{histogramBucketSuffix, histogramSumSuffix, histogramCountSuffix} {
+		if strings.HasSuffix(name, suffix) {
+			return strings.TrimSuffix(name, suffix), true
+		}
+	}
+	return "", false
+}
+
+// promMetricName replaces the characters which are invalid in a Prometheus metric name by `_`.
+func promMetricName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		if !(c == '_' || c == ':' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+// promLabelName sanitizes an attribute key into a Prometheus label name.
+func promLabelName(key string) string {
+	b := []byte(key)
+	for i, c := range b {
+		if !(c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+			b[i] = '_'
+		}
+	}
+	if len(b) > 0 && b[0] >= '0' && b[0] <= '9' {
+		return "_" + string(b)
+	}
+	return string(b)
+}
+
+// metricLabels builds the labels of a series, the empty values are dropped as in Prometheus.
+func metricLabels(name, job, instance string, attributes map[string]string) map[string]string {
+	labels := make(map[string]string, len(attributes)+3)
+	for k, v := range attributes {
+		if v != "" {
+			labels[promLabelName(k)] = v
+		}
+	}
+	if job != "" {
+		labels[jobLabel] = job
+	}
+	if instance != "" {
+		labels[instanceLabel] = instance
+	}
+	labels[datasource.MetricNameLabel] = name
+	return labels
+}
+
+func bucketLabels(name, job, instance string, attributes map[string]string, le string) map[string]string {
+	labels := metricLabels(name+histogramBucketSuffix, job, instance, attributes)
+	labels[histogramBucketLabel] = le
+	return labels
+}
+
+func formatBound(bound float64) string {
+	return strconv.FormatFloat(bound, 'f', -1, 64)
+}
+
+// seriesBuilder groups the samples of the matching series by labels, the samples are added
+// in time order as the rows are ordered by time.
+type seriesBuilder struct {
+	filter *datasource.LabelFilter
+	series map[string]*datasource.MetricSeries
+	// excluded caches the series which don't match the filter
+	excluded map[string]struct{}
+}
+
+func newSeriesBuilder(filter *datasource.LabelFilter) *seriesBuilder {
+	return &seriesBuilder{
+		filter:   filter,
+		series:   map[string]*datasource.MetricSeries{},
+		excluded: map[string]struct{}{},
+	}
+}
+
+// add appends a sample to its series, a delta is added to the previous value.
+func (b *seriesBuilder) add(labels map[string]string, t time.Time, value float64, delta bool) {
+	id := generateAttributesId(labels)
+	if _, ok := b.excluded[id]; ok {
+		return
+	}
+	s, ok := b.series[id]
+	if !ok {
+		if !b.filter.Matches(labels) {
+			b.excluded[id] = struct{}{}
+			return
+		}
+		s = &datasource.MetricSeries{Labels: labels}
+		b.series[id] = s
+	}
+	if delta && len(s.Samples) > 0 {
+		value += s.Samples[len(s.Samples)-1].Value
+	}
+	s.Samples = append(s.Samples, datasource.MetricSample{Timestamp: t, Value: value})
+}
+
+func (b *seriesBuilder) build() []*datasource.MetricSeries {
+	ids := make([]string, 0, len(b.series))
+	for id := range b.series {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	series := make([]*datasource.MetricSeries, 0, len(ids))
+	for _, id := range ids {
+		series = append(series, b.series[id])
+	}
+	return series
+}
@@ -0,0 +1,115 @@
+package clickhouse
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMetricsCondition(t *testing.T) {
+	start := time.Unix(1681873000, 0)
+	end := time.Unix(1681876600, 0)
+	query := &datasource.MetricQueryParameters{
+		Matchers: []datasource.LabelMatcher{
+			{Name: datasource.MetricNameLabel, Type: datasource.MatchEqual, Value: "http_server_duration_bucket"},
+			{Name: "job", Type: datasource.MatchEqual, Value: "frontend"},
+		},
+		StartTime: start,
+		EndTime:   end,
+	}
+
+	where, args, ok := buildMetricsCondition(query, histogramTableSuffix)
+	require.True(t, ok)
+	assert.Equal(t, "WHERE TimeUnix >= fromUnixTimestamp64Nano(?) AND TimeUnix <= fromUnixTimestamp64Nano(?) AND MetricName LIKE ? AND "+
+		fmt.Sprintf(METRIC_RESOURCE_EXPR, "service.name")+" = ?", where)
+	assert.Equal(t, []interface{}{start.UnixNano(), end.UnixNano(), "http_server_duration", "job", "frontend"}, args)
+
+	where, args, ok = buildMetricsCondition(query, gaugeTableSuffix)
+	require.True(t, ok)
+	assert.Equal(t, "http_server_duration_bucket", args[2])
+
+	query.Matchers[0].Value = "up"
+	_, _, ok = buildMetricsCondition(query, histogramTableSuffix)
+	assert.False(t, ok)
+
+	where, args, ok = buildMetricsCondition(&datasource.MetricQueryParameters{}, sumTableSuffix)
+	require.True(t, ok)
+	assert.Empty(t, where)
+	assert.Empty(t, args)
+}
+
+func TestBuildMetricsConditionMatchers(t *testing.T) {
+	query := &datasource.MetricQueryParameters{
+		Matchers: []datasource.LabelMatcher{
+			{Name: datasource.MetricNameLabel, Type: datasource.MatchRegexp, Value: "http_.*"},
+			{Name: "job", Type: datasource.MatchNotEqual, Value: "frontend"},
+			{Name: "instance", Type: datasource.MatchNotRegexp, Value: "test-.*"},
+			{Name: "http_method", Type: datasource.MatchEqual, Value: "GET"},
+			{Name: "le", Type: datasource.MatchEqual, Value: "0.5"},
+		},
+	}
+
+	where, args, ok := buildMetricsCondition(query, sumTableSuffix)
+	require.True(t, ok)
+	assert.Equal(t, "WHERE "+strings.Join([]string{
+		"match(" + METRIC_PROM_NAME_EXPR + ", concat('^(?:', ?, ')$'))",
+		fmt.Sprintf(METRIC_RESOURCE_EXPR, "service.name") + " != ?",
+		"NOT match(" + fmt.Sprintf(METRIC_RESOURCE_EXPR, "service.instance.id") + ", concat('^(?:', ?, ')$'))",
+		METRIC_ATTRIBUTE_EXPR + " = ?",
+		METRIC_ATTRIBUTE_EXPR + " = ?",
+	}, " AND "), where)
+	assert.Equal(t, []interface{}{"http_.*", "job", "frontend", "instance", "test-.*", "http_method", "GET", "le", "0.5"}, args)
+
+	// the bucket bounds are not attributes and a histogram matches a name regexp by its series
+	where, args, ok = buildMetricsCondition(query, histogramTableSuffix)
+	require.True(t, ok)
+	assert.True(t, strings.HasPrefix(where, "WHERE (match(concat("+METRIC_PROM_NAME_EXPR+", '_bucket'), concat('^(?:', ?, ')$')) OR "))
+	assert.Equal(t, []interface{}{"http_.*", "http_.*", "http_.*", "job", "frontend", "instance", "test-.*", "http_method", "GET"}, args)
+
+	query.Matchers = []datasource.LabelMatcher{{Name: datasource.MetricNameLabel, Type: datasource.MatchNotEqual, Value: "up_count"}}
+	where, args, ok = buildMetricsCondition(query, histogramTableSuffix)
+	require.True(t, ok)
+	assert.Empty(t, where)
+	assert.Empty(t, args)
+}
+
+func TestMetricLabels(t *testing.T) {
+	assert.Equal(t, "http_server_duration", promMetricName("http.server.duration"))
+	assert.Equal(t, "_1_a", promLabelName("1.a"))
+	assert.Equal(t, map[string]string{
+		datasource.MetricNameLabel: "http_server_duration_bucket",
+		"http_method":              "GET",
+		"job":                      "frontend",
+		"le":                       "0.5",
+	}, bucketLabels("http_server_duration", "frontend", "", map[string]string{"http.method": "GET", "empty": ""}, formatBound(0.5)))
+}
+
+func TestSeriesBuilder(t *testing.T) {
+	filter, err := datasource.NewLabelFilter([]datasource.LabelMatcher{{Name: "job", Type: datasource.MatchRegexp, Value: "front.*"}})
+	require.NoError(t, err)
+	builder := newSeriesBuilder(filter)
+	frontend := map[string]string{datasource.MetricNameLabel: "requests", "job": "frontend"}
+	backend := map[string]string{datasource.MetricNameLabel: "requests", "job": "backend"}
+	builder.add(frontend, time.Unix(1, 0), 2, true)
+	builder.add(backend, time.Unix(1, 0), 1, true)
+	builder.add(frontend, time.Unix(2, 0), 3, true)
+
+	series := builder.build()
+	require.Len(t, series, 1)
+	assert.Equal(t, frontend, series[0].Labels)
+	assert.Equal(t, []datasource.MetricSample{
+		{Timestamp: time.Unix(1, 0), Value: 2},
+		{Timestamp: time.Unix(2, 0), Value: 5},
+	}, series[0].Samples)
+}
+
+func TestCreateMetricQuery(t *testing.T) {
+	query, err := NewFactory(&ct).CreateMetricQuery()
+	require.NoError(t, err)
+	require.NotNil(t, query)
+}
@@ -21,7 +21,6 @@ import (
 )
 
 const (
-	SUB_SQL        = "SELECT TraceId AS id FROM %s_trace_id_ts %s ORDER BY Start DESC %s"
 	TRACES_COLUMNS = `a.Timestamp,
        a.TraceId,
        a.SpanId,
@@ -41,10 +40,9 @@ const (
        a.Links.SpanId,
        a.Links.TraceState,
        a.Links.Attributes`
-	BASE_SQL = `SELECT %s FROM
-       (%s) AS b JOIN
-       %s AS a on b.id = a.TraceId %s`
-	TIME_PATTERN      = "Start BETWEEN '%s' AND '%s'"
+	// SEARCH_TRACES_SQL selects the spans of the newest traces having a span matching the condition.
+	SEARCH_TRACES_SQL = `SELECT %s FROM %s AS a WHERE a.TraceId IN
+       (SELECT a.TraceId FROM %s AS a %s GROUP BY a.TraceId ORDER BY max(a.Timestamp) DESC LIMIT %d)`
 	LIMIT_PATTERN     = "LIMIT %d"
 	DEFAULT_LIMIT_NUM = 20
 	DATETIME_LAYOUT   = "2006-01-02 15:04:05"
@@ -53,6 +51,7 @@ const (
 	QUERY_SERVICE_TIME_UNIT  = "DAY"
 	QUERY_SERVICE_TIME_VALUE = 1
 	QUERY_OPERATIONS_SQL     = "SELECT SpanName FROM %s %s GROUP BY SpanName"
+	QUERY_TRACE_SQL          = "SELECT %s FROM %s AS a WHERE a.TraceId = ?"
 )
 
 type ClickHouseQuery struct {
@@ -95,21 +94,9 @@ type TracesModel struct {
 }
 
 func (q *ClickHouseQuery) GetOperations(ctx context.Context, query *datasource.OperationsQueryParameters) ([]string, error) {
-	var whereKeywordList []string
-	if query.ServiceName != "" {
-		whereKeywordList = append(whereKeywordList, fmt.Sprintf("ServiceName='%s'", query.ServiceName))
-	}
-	if query.SpanKind != "" {
-		whereKeywordList = append(whereKeywordList, fmt.Sprintf("SpanKind='%s'", query.SpanKind))
-	}
-	whereKeywordCondition := fmt.Sprintf("WHERE %s", strings.Join(whereKeywordList, " AND "))
-	if len(whereKeywordList) == 0 {
-		whereKeywordCondition = ""
-	}
-
-	sql := fmt.Sprintf(QUERY_OPERATIONS_SQL, q.tracingTableName, whereKeywordCondition)
+	sql, args := buildOperationsQuery(query, q.tracingTableName)
 	var operationList []string
-	rows, err := q.client.Query(ctx, sql)
+	rows, err := q.client.Query(ctx, sql, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -131,6 +118,27 @@ func (q *ClickHouseQuery) GetOperations(ctx context.Context, query *datasource.O
 	return operationList, nil
 }
 
+// buildOperationsQuery builds the query of the span names, the filters are bound as arguments.
+func buildOperationsQuery(query *datasource.OperationsQueryParameters, tableName string) (string, []interface{}) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	if query.ServiceName != "" {
+		conditions = append(conditions, "ServiceName = ?")
+		args = append(args, query.ServiceName)
+	}
+	if query.SpanKind != "" {
+		conditions = append(conditions, "SpanKind = ?")
+		args = append(args, query.SpanKind)
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+	return fmt.Sprintf(QUERY_OPERATIONS_SQL, tableName, where), args
+}
+
 func (q *ClickHouseQuery) GetService(ctx context.Context) ([]*v1_resource.Resource, error) {
 	sql := fmt.Sprintf(QUERY_SERVICE_SQL, q.tracingTableName, q.tracingTableName)
 
@@ -147,9 +155,12 @@ func (q *ClickHouseQuery) GetTrace(ctx context.Context, traceID string) (*v1_tra
 		return nil, errors.New("traceID must not empty")
 	}
 
-	sql := fmt.Sprintf("SELECT %s FROM %s AS a WHERE a.TraceId='%s'", TRACES_COLUMNS, q.tracingTableName, traceID)
+	sql := fmt.Sprintf(QUERY_TRACE_SQL, TRACES_COLUMNS, q.tracingTableName)
+	if limit := datasource.SpanLimit(ctx); limit > 0 {
+		sql = fmt.Sprintf("%s %s", sql, fmt.Sprintf(LIMIT_PATTERN, limit+1))
+	}
 	var result []TracesModel
-	if err := q.client.Select(ctx, &result, sql); err != nil {
+	if err := q.client.Select(ctx, &result, sql, traceID); err != nil {
 		return nil, err
 	}
 
@@ -157,17 +168,39 @@ func (q *ClickHouseQuery) GetTrace(ctx context.Context, traceID string) (*v1_tra
 }
 
 func (q *ClickHouseQuery) SearchTraces(ctx context.Context, query *datasource.TraceQueryParameters) (*v1alpha1.TracesData, error) {
-	sql, err := buildQuery(query, q.tracingTableName)
+	traces, err := q.FindTraces(ctx, query)
 	if err != nil {
 		return nil, err
 	}
+	return datasource.DocumentsTracesConvert(traces)
+}
 
+// FindTraces returns the spans of the matching traces, at most the span limit of ctx and one more per trace.
+func (q *ClickHouseQuery) FindTraces(ctx context.Context, query *datasource.TraceQueryParameters) (*v1_trace.TracesData, error) {
+	sql, args := buildTracesQuery(query, q.tracingTableName, datasource.SpanLimit(ctx))
 	var result []TracesModel
-	if err = q.client.Select(ctx, &result, sql); err != nil {
+	if err := q.client.Select(ctx, &result, sql, args...); err != nil {
 		return nil, err
 	}
-	// TODO: fix response
-	return nil, nil
+	return parseTracesResults(result), nil
+}
+
+// parseTracesResults converts the rows of several traces, grouping the resource spans of every trace
+// in the order of the rows.
+func parseTracesResults(tracesModel []TracesModel) *v1_trace.TracesData {
+	var ids []string
+	rows := make(map[string][]TracesModel)
+	for _, item := range tracesModel {
+		if _, ok := rows[item.TraceId]; !ok {
+			ids = append(ids, item.TraceId)
+		}
+		rows[item.TraceId] = append(rows[item.TraceId], item)
+	}
+	traces := &v1_trace.TracesData{}
+	for _, id := range ids {
+		traces.ResourceSpans = append(traces.ResourceSpans, parseSpanResults(rows[id]).ResourceSpans...)
+	}
+	return traces
 }
 
 func (q *ClickHouseQuery) SearchLogs(ctx context.Context) (*v1_logs.LogsData, error) {
@@ -177,57 +210,19 @@ func (q *ClickHouseQuery) GetLog(ctx context.Context) (*v1_logs.LogsData, error)
 	return nil, nil
 }
 
-func buildQuery(query *datasource.TraceQueryParameters, tableName string) (string, error) {
-	var whereKeywordList []string
-	if query.ServiceName != "" {
-		whereKeywordList = append(whereKeywordList, fmt.Sprintf("a.ServiceName='%s'", query.ServiceName))
-	}
-	if query.OperationName != "" {
-		whereKeywordList = append(whereKeywordList, fmt.Sprintf("a.SpanName='%s'", query.OperationName))
-	}
-	if query.Tags != nil {
-		for key, value := range query.Tags {
-			whereKeywordList = append(whereKeywordList, fmt.Sprintf("a.SpanAttributes['%s']='%s'", key, value))
-		}
-	}
-	whereKeywordCondition := fmt.Sprintf("WHERE %s", strings.Join(whereKeywordList, " AND "))
-	if len(whereKeywordList) == 0 {
-		whereKeywordCondition = ""
-	}
-
-	// build time and LIMIT condition
-	var whereTimeList []string
-
-	//default past 1 hours
-	//end := time.Now()
-	//start := end.Add(-time.Hour)
-	//timeCondition := fmt.Sprintf(TIME_PATTERN, start.Format(DATETIME_LAYOUT), end.Format(DATETIME_LAYOUT))
-	if query.EndTime.After(query.StartTime) {
-		timeCondition := fmt.Sprintf(TIME_PATTERN,
-			query.StartTime.Format(DATETIME_LAYOUT),
-			query.EndTime.Format(DATETIME_LAYOUT))
-		whereTimeList = append(whereTimeList, timeCondition)
-	}
-
-	//DurationMin <= a.Duration <= DurationMax
-	if query.DurationMin != nil && query.DurationMax != nil && query.DurationMin.Nanos < query.DurationMax.Nanos {
-		whereTimeList = append(whereTimeList, fmt.Sprintf("(End - Start) BETWEEN %d AND %d", query.DurationMin.Nanos,
-			query.DurationMax.Nanos))
-	}
-	whereTimeCondition := fmt.Sprintf("WHERE %s", strings.Join(whereTimeList, " AND "))
-	if len(whereTimeList) == 0 {
-		whereTimeCondition = ""
-	}
-
-	//default limit
-	//limitCondition := fmt.Sprintf(LIMIT_PATTERN, 100)
-	limitCondition := fmt.Sprintf(LIMIT_PATTERN, DEFAULT_LIMIT_NUM)
+// buildTracesQuery builds the search of the spans of the traces matching query, at most spanLimit and one
+// more spans per trace when spanLimit is positive. The filters are bound as query parameters.
+func buildTracesQuery(query *datasource.TraceQueryParameters, tableName string, spanLimit int) (string, []interface{}) {
+	where, args := buildSpansCondition(query.SpanQuery())
+	numTraces := DEFAULT_LIMIT_NUM
 	if query.NumTraces != 0 {
-		limitCondition = fmt.Sprintf(LIMIT_PATTERN, query.NumTraces)
+		numTraces = query.NumTraces
 	}
-
-	subQuery := fmt.Sprintf(SUB_SQL, tableName, whereTimeCondition, limitCondition)
-	return fmt.Sprintf(BASE_SQL, TRACES_COLUMNS, subQuery, tableName, whereKeywordCondition), nil
+	sql := fmt.Sprintf(SEARCH_TRACES_SQL, TRACES_COLUMNS, tableName, tableName, where, numTraces)
+	if spanLimit > 0 {
+		sql = fmt.Sprintf("%s LIMIT %d BY a.TraceId", sql, spanLimit+1)
+	}
+	return sql, args
 }
 
 func parseServiceResults(models []ServiceModel) []*v1_resource.Resource {
@@ -243,33 +238,12 @@ func parseServiceResults(models []ServiceModel) []*v1_resource.Resource {
 func parseSpanResults(tracesModel []TracesModel) *v1_trace.TracesData {
 	rsMap := make(map[string]*v1_trace.ResourceSpans)
 	for _, item := range tracesModel {
-		s := v1_trace.Span{}
-		s.TraceId = []byte(item.TraceId)
-		s.SpanId = []byte(item.SpanId)
-		s.ParentSpanId = []byte(item.ParentSpanId)
-		s.TraceState = item.TraceState
-		s.Name = item.SpanName
-		s.Kind = v1_trace.Span_SpanKind(v1_trace.Span_SpanKind_value[item.SpanKind])
-		// item.ServiceName in attribute
-		s.StartTimeUnixNano = uint64(item.Timestamp.UnixNano())
-		duration, _ := time.ParseDuration(fmt.Sprintf("%dns", item.Duration))
-		s.EndTimeUnixNano = uint64(item.Timestamp.Add(duration).UnixNano())
-		s.Attributes = convertAttributes(item.SpanAttributes)
-		//s.DroppedAttributesCount
-		s.Events = convertEvents(item.EventsName, item.EventsTimestamp, item.EventsAttributes)
-		//s.DroppedEventsCount
-		s.Links = convertLinks(item.LinksTraceId, item.LinksSpanId, item.LinksTraceState, item.LinksAttributes)
-		//s.DroppedLinksCount
-		s.Status = &v1_trace.Status{
-			Message: item.StatusMessage,
-			Code:    v1_trace.Status_StatusCode(v1_trace.Span_SpanKind_value[item.StatusCode]),
-		}
-
+		s := convertSpan(item)
 		attrId := generateAttributesId(item.ResourceAttributes)
 		if _, ok := rsMap[attrId]; ok {
-			rsMap[attrId].ScopeSpans[0].Spans = append(rsMap[attrId].ScopeSpans[0].Spans, &s)
+			rsMap[attrId].ScopeSpans[0].Spans = append(rsMap[attrId].ScopeSpans[0].Spans, s)
 		} else {
-			spanSlice := []*v1_trace.Span{&s}
+			spanSlice := []*v1_trace.Span{s}
 			rsMap[attrId] = &v1_trace.ResourceSpans{
 				Resource:   &v1_resource.Resource{Attributes: convertAttributes(item.ResourceAttributes)},
 				ScopeSpans: []*v1_trace.ScopeSpans{{Spans: spanSlice}},
@@ -287,6 +261,28 @@ func parseSpanResults(tracesModel []TracesModel) *v1_trace.TracesData {
 	}
 }
 
+// convertSpan converts a row of the traces table into a span.
+func convertSpan(item TracesModel) *v1_trace.Span {
+	s := &v1_trace.Span{}
+	s.TraceId = []byte(item.TraceId)
+	s.SpanId = []byte(item.SpanId)
+	s.ParentSpanId = []byte(item.ParentSpanId)
+	s.TraceState = item.TraceState
+	s.Name = item.SpanName
+	s.Kind = v1_trace.Span_SpanKind(v1_trace.Span_SpanKind_value[item.SpanKind])
+	// item.ServiceName in attribute
+	s.StartTimeUnixNano = uint64(item.Timestamp.UnixNano())
+	s.EndTimeUnixNano = uint64(item.Timestamp.Add(time.Duration(item.Duration)).UnixNano())
+	s.Attributes = convertAttributes(item.SpanAttributes)
+	s.Events = convertEvents(item.EventsName, item.EventsTimestamp, item.EventsAttributes)
+	s.Links = convertLinks(item.LinksTraceId, item.LinksSpanId, item.LinksTraceState, item.LinksAttributes)
+	s.Status = &v1_trace.Status{
+		Message: item.StatusMessage,
+		Code:    v1_trace.Status_StatusCode(v1_trace.Status_StatusCode_value[item.StatusCode]),
+	}
+	return s
+}
+
 func generateAttributesId(attr map[string]string) string {
 	var attrList []string
 	for key, value := range attr {
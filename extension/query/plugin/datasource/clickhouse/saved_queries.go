@@ -0,0 +1,138 @@
+package clickhouse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/api/v1alpha1"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"go.uber.org/zap"
+)
+
+const (
+	// CREATE_SAVED_QUERIES_TABLE_SQL keeps the last version of every saved query, a deleted saved
+	// query is a version with Deleted set.
+	CREATE_SAVED_QUERIES_TABLE_SQL = `CREATE TABLE IF NOT EXISTS %s (
+     Id String,
+     Name String,
+     Owner LowCardinality(String),
+     Tags Array(String),
+     Document String,
+     Deleted UInt8,
+     Version UInt64
+) ENGINE ReplacingMergeTree(Version)
+ORDER BY Id`
+	INSERT_SAVED_QUERY_SQL = "INSERT INTO %s (Id, Name, Owner, Tags, Document, Deleted, Version) VALUES (?, ?, ?, ?, ?, ?, ?)"
+	SAVED_QUERIES_SQL      = "SELECT Document FROM %s FINAL WHERE Deleted = 0"
+)
+
+var _ datasource.SavedQueryStore = (*ClickHouseSavedQueryStore)(nil)
+
+// ClickHouseSavedQueryStore keeps the saved queries in a ReplacingMergeTree table, read with FINAL.
+type ClickHouseSavedQueryStore struct {
+	logger    *zap.Logger
+	client    clickhouse.Conn
+	tableName string
+}
+
+type savedQueryModel struct {
+	Document string `ch:"Document"`
+}
+
+// createSavedQueriesTable creates the saved queries table when it doesn't exist.
+func createSavedQueriesTable(ctx context.Context, conn clickhouse.Conn, tableName string) error {
+	if err := conn.Exec(ctx, fmt.Sprintf(CREATE_SAVED_QUERIES_TABLE_SQL, tableName)); err != nil {
+		return fmt.Errorf("create saved queries table %s: %w", tableName, err)
+	}
+	return nil
+}
+
+func (s *ClickHouseSavedQueryStore) CreateSavedQuery(ctx context.Context, query *v1alpha1.SavedQuery) error {
+	if _, err := s.GetSavedQuery(ctx, query.Id); err == nil {
+		return datasource.ErrSavedQueryExists
+	} else if !errors.Is(err, datasource.ErrSavedQueryNotFound) {
+		return err
+	}
+	return s.insert(ctx, query, false)
+}
+
+func (s *ClickHouseSavedQueryStore) GetSavedQuery(ctx context.Context, id string) (*v1alpha1.SavedQuery, error) {
+	var result []savedQueryModel
+	sql := fmt.Sprintf(SAVED_QUERIES_SQL, s.tableName) + " AND Id = ?"
+	if err := s.client.Select(ctx, &result, sql, id); err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, datasource.ErrSavedQueryNotFound
+	}
+	return datasource.UnmarshalSavedQuery([]byte(result[0].Document))
+}
+
+func (s *ClickHouseSavedQueryStore) ListSavedQueries(ctx context.Context, filter *datasource.SavedQueryFilter) ([]*v1alpha1.SavedQuery, error) {
+	sql, args := buildSavedQueriesQuery(filter, s.tableName)
+	var result []savedQueryModel
+	if err := s.client.Select(ctx, &result, sql, args...); err != nil {
+		return nil, err
+	}
+	queries := make([]*v1alpha1.SavedQuery, 0, len(result))
+	for _, model := range result {
+		query, err := datasource.UnmarshalSavedQuery([]byte(model.Document))
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, query)
+	}
+	datasource.SortSavedQueries(queries)
+	return queries, nil
+}
+
+func buildSavedQueriesQuery(filter *datasource.SavedQueryFilter, tableName string) (string, []interface{}) {
+	conditions := []string{fmt.Sprintf(SAVED_QUERIES_SQL, tableName)}
+	var args []interface{}
+	if filter != nil && filter.Owner != "" {
+		conditions = append(conditions, "Owner = ?")
+		args = append(args, filter.Owner)
+	}
+	if filter != nil && filter.Tag != "" {
+		conditions = append(conditions, "has(Tags, ?)")
+		args = append(args, filter.Tag)
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+func (s *ClickHouseSavedQueryStore) UpdateSavedQuery(ctx context.Context, query *v1alpha1.SavedQuery) error {
+	if _, err := s.GetSavedQuery(ctx, query.Id); err != nil {
+		return err
+	}
+	return s.insert(ctx, query, false)
+}
+
+func (s *ClickHouseSavedQueryStore) DeleteSavedQuery(ctx context.Context, id string) error {
+	query, err := s.GetSavedQuery(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.insert(ctx, query, true)
+}
+
+// insert adds a version of a saved query, newer than the existing ones.
+func (s *ClickHouseSavedQueryStore) insert(ctx context.Context, query *v1alpha1.SavedQuery, deleted bool) error {
+	b, err := datasource.MarshalSavedQuery(query)
+	if err != nil {
+		return err
+	}
+	var flag uint8
+	if deleted {
+		flag = 1
+	}
+	tags := query.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return s.client.Exec(ctx, fmt.Sprintf(INSERT_SAVED_QUERY_SQL, s.tableName),
+		query.Id, query.Name, query.Owner, tags, string(b), flag, uint64(time.Now().UnixNano()))
+}
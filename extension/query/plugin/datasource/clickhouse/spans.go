@@ -0,0 +1,109 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	v1_resource "go.opentelemetry.io/proto/otlp/resource/v1"
+	v1_trace "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+const SEARCH_SPANS_SQL = "SELECT %s FROM %s AS a %s ORDER BY %s %s LIMIT %d OFFSET %d"
+
+// spanSortColumns are the columns of the traces table ordering a span search.
+var spanSortColumns = map[datasource.SpanSortField]string{
+	datasource.SpanSortByStartTime:   "a.Timestamp",
+	datasource.SpanSortByDuration:    "a.Duration",
+	datasource.SpanSortByServiceName: "a.ServiceName",
+	datasource.SpanSortByName:        "a.SpanName",
+}
+
+func (q *ClickHouseQuery) SearchSpans(ctx context.Context, query *datasource.SpanQueryParameters) (*v1_trace.TracesData, error) {
+	sql, args := buildSpansQuery(query, q.tracingTableName)
+	var result []TracesModel
+	if err := q.client.Select(ctx, &result, sql, args...); err != nil {
+		return nil, err
+	}
+
+	rSpans := make([]*v1_trace.ResourceSpans, 0, len(result))
+	for _, item := range result {
+		rSpans = append(rSpans, &v1_trace.ResourceSpans{
+			Resource:   &v1_resource.Resource{Attributes: convertAttributes(item.ResourceAttributes)},
+			ScopeSpans: []*v1_trace.ScopeSpans{{Spans: []*v1_trace.Span{convertSpan(item)}}},
+		})
+	}
+	return &v1_trace.TracesData{ResourceSpans: rSpans}, nil
+}
+
+// buildSpansQuery builds the span search, the filters are bound as query parameters.
+func buildSpansQuery(query *datasource.SpanQueryParameters, tableName string) (string, []interface{}) {
+	where, args := buildSpansCondition(query)
+	column, ok := spanSortColumns[query.SortBy]
+	if !ok {
+		column = spanSortColumns[datasource.SpanSortByStartTime]
+	}
+	order := "DESC"
+	if query.Ascending {
+		order = "ASC"
+	}
+	return fmt.Sprintf(SEARCH_SPANS_SQL, TRACES_COLUMNS, tableName, where, column, order, query.Limit, query.Offset), args
+}
+
+// buildSpansCondition builds the WHERE clause of a span search over the traces table aliased as a.
+func buildSpansCondition(query *datasource.SpanQueryParameters) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	if query.ServiceName != "" {
+		conditions = append(conditions, "a.ServiceName = ?")
+		args = append(args, query.ServiceName)
+	}
+	if query.OperationName != "" {
+		conditions = append(conditions, "a.SpanName = ?")
+		args = append(args, query.OperationName)
+	}
+	if query.SpanKind != "" {
+		conditions = append(conditions, "a.SpanKind = ?")
+		args = append(args, query.SpanKind)
+	}
+	if query.StatusCode != "" {
+		conditions = append(conditions, "a.StatusCode = ?")
+		args = append(args, query.StatusCode)
+	}
+	if !query.StartTime.IsZero() {
+		conditions = append(conditions, "a.Timestamp >= fromUnixTimestamp64Nano(?)")
+		args = append(args, query.StartTime.UnixNano())
+	}
+	if !query.EndTime.IsZero() {
+		conditions = append(conditions, "a.Timestamp <= fromUnixTimestamp64Nano(?)")
+		args = append(args, query.EndTime.UnixNano())
+	}
+	if query.DurationMin > 0 {
+		conditions = append(conditions, "a.Duration >= ?")
+		args = append(args, query.DurationMin.Nanoseconds())
+	}
+	if query.DurationMax > 0 {
+		conditions = append(conditions, "a.Duration <= ?")
+		args = append(args, query.DurationMax.Nanoseconds())
+	}
+	// the tags are sorted for a stable query
+	keys := make([]string, 0, len(query.Tags))
+	for k := range query.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		conditions = append(conditions, "(a.SpanAttributes[?] = ? OR a.ResourceAttributes[?] = ?)")
+		args = append(args, k, query.Tags[k], k, query.Tags[k])
+	}
+	for _, k := range query.TagKeys {
+		conditions = append(conditions, "(mapContains(a.SpanAttributes, ?) OR mapContains(a.ResourceAttributes, ?))")
+		args = append(args, k, k)
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
@@ -0,0 +1,110 @@
+package clickhouse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestBuildSpansQuery(t *testing.T) {
+	start := time.Unix(1681873000, 0)
+	end := time.Unix(1681876600, 0)
+	query := &datasource.SpanQueryParameters{
+		ServiceName: "frontend",
+		SpanKind:    "SPAN_KIND_CLIENT",
+		StatusCode:  "STATUS_CODE_ERROR",
+		Tags:        map[string]string{"db.system": "mysql"},
+		StartTime:   start,
+		EndTime:     end,
+		DurationMin: time.Second,
+		SortBy:      datasource.SpanSortByDuration,
+		Offset:      20,
+		Limit:       10,
+	}
+
+	sql, args := buildSpansQuery(query, "otel_traces")
+	assert.Equal(t, "SELECT "+TRACES_COLUMNS+" FROM otel_traces AS a WHERE "+
+		"a.ServiceName = ? AND a.SpanKind = ? AND a.StatusCode = ? AND "+
+		"a.Timestamp >= fromUnixTimestamp64Nano(?) AND a.Timestamp <= fromUnixTimestamp64Nano(?) AND "+
+		"a.Duration >= ? AND (a.SpanAttributes[?] = ? OR a.ResourceAttributes[?] = ?) "+
+		"ORDER BY a.Duration DESC LIMIT 10 OFFSET 20", sql)
+	assert.Equal(t, []interface{}{
+		"frontend", "SPAN_KIND_CLIENT", "STATUS_CODE_ERROR",
+		start.UnixNano(), end.UnixNano(), int64(time.Second),
+		"db.system", "mysql", "db.system", "mysql",
+	}, args)
+
+	sql, args = buildSpansQuery(&datasource.SpanQueryParameters{Ascending: true, Limit: 5}, "otel_traces")
+	assert.Equal(t, "SELECT "+TRACES_COLUMNS+" FROM otel_traces AS a  ORDER BY a.Timestamp ASC LIMIT 5 OFFSET 0", sql)
+	assert.Empty(t, args)
+}
+
+func TestBuildTracesQuery(t *testing.T) {
+	start := time.Unix(1681873000, 0)
+	end := time.Unix(1681876600, 0)
+	query := &datasource.TraceQueryParameters{
+		ServiceName: "frontend",
+		StatusCode:  "STATUS_CODE_ERROR",
+		TagKeys:     []string{"error"},
+		StartTime:   start,
+		EndTime:     end,
+		DurationMin: durationpb.New(time.Second),
+		NumTraces:   5,
+	}
+
+	sql, args := buildTracesQuery(query, "otel_traces", 100)
+	assert.Equal(t, "SELECT "+TRACES_COLUMNS+" FROM otel_traces AS a WHERE a.TraceId IN\n"+
+		"       (SELECT a.TraceId FROM otel_traces AS a WHERE a.ServiceName = ? AND a.StatusCode = ? AND "+
+		"a.Timestamp >= fromUnixTimestamp64Nano(?) AND a.Timestamp <= fromUnixTimestamp64Nano(?) AND a.Duration >= ? AND "+
+		"(mapContains(a.SpanAttributes, ?) OR mapContains(a.ResourceAttributes, ?)) "+
+		"GROUP BY a.TraceId ORDER BY max(a.Timestamp) DESC LIMIT 5) LIMIT 101 BY a.TraceId", sql)
+	assert.Equal(t, []interface{}{
+		"frontend", "STATUS_CODE_ERROR", start.UnixNano(), end.UnixNano(), int64(time.Second), "error", "error",
+	}, args)
+
+	sql, args = buildTracesQuery(&datasource.TraceQueryParameters{}, "otel_traces", 0)
+	assert.Equal(t, "SELECT "+TRACES_COLUMNS+" FROM otel_traces AS a WHERE a.TraceId IN\n"+
+		"       (SELECT a.TraceId FROM otel_traces AS a  GROUP BY a.TraceId ORDER BY max(a.Timestamp) DESC LIMIT 20)", sql)
+	assert.Empty(t, args)
+}
+
+func TestBuildOperationsQuery(t *testing.T) {
+	sql, args := buildOperationsQuery(&datasource.OperationsQueryParameters{ServiceName: "front'end", SpanKind: "SPAN_KIND_SERVER"}, "otel_traces")
+	assert.Equal(t, "SELECT SpanName FROM otel_traces WHERE ServiceName = ? AND SpanKind = ? GROUP BY SpanName", sql)
+	assert.Equal(t, []interface{}{"front'end", "SPAN_KIND_SERVER"}, args)
+
+	sql, args = buildOperationsQuery(&datasource.OperationsQueryParameters{}, "otel_traces")
+	assert.Equal(t, "SELECT SpanName FROM otel_traces  GROUP BY SpanName", sql)
+	assert.Empty(t, args)
+}
+
+func TestParseTracesResults(t *testing.T) {
+	rows := []TracesModel{
+		{TraceId: "b", SpanId: "1", ResourceAttributes: map[string]string{"service.name": "frontend"}},
+		{TraceId: "a", SpanId: "2", ResourceAttributes: map[string]string{"service.name": "frontend"}},
+		{TraceId: "b", SpanId: "3", ParentSpanId: "1", ResourceAttributes: map[string]string{"service.name": "frontend"}},
+	}
+	traces, err := datasource.DocumentsTracesConvert(parseTracesResults(rows))
+	require.NoError(t, err)
+	require.Len(t, traces.Traces, 2)
+	assert.Equal(t, "b", traces.Traces[0].TraceId)
+	assert.Equal(t, uint32(2), traces.Traces[0].SpanCount)
+	assert.Equal(t, "a", traces.Traces[1].TraceId)
+	assert.Equal(t, uint32(1), traces.Traces[1].SpanCount)
+}
+
+func TestConvertSpanStatus(t *testing.T) {
+	span := convertSpan(TracesModel{
+		Timestamp:  time.Unix(1681873445, 0),
+		Duration:   int64(2 * time.Millisecond),
+		SpanKind:   "SPAN_KIND_CLIENT",
+		StatusCode: "STATUS_CODE_ERROR",
+	})
+	assert.Equal(t, "STATUS_CODE_ERROR", span.Status.Code.String())
+	assert.Equal(t, "SPAN_KIND_CLIENT", span.Kind.String())
+	assert.Equal(t, uint64(2*time.Millisecond), span.EndTimeUnixNano-span.StartTimeUnixNano)
+}
@@ -0,0 +1,26 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrDependenciesNotConfigured is returned when creating the dependency reader of a datasource
+// which doesn't store the dependency links between the services.
+var ErrDependenciesNotConfigured = errors.New("dependency links are not stored")
+
+// DependencyLink is the number of calls made by the parent service to the child service.
+type DependencyLink struct {
+	Parent    string
+	Child     string
+	CallCount uint64
+}
+
+// DependencyReader reads the dependency links aggregated out of the spans, as the Jaeger
+// dependency storage.
+type DependencyReader interface {
+	// GetDependencies returns the links written in the lookback before endTime, the calls between
+	// the same services are summed up.
+	GetDependencies(ctx context.Context, endTime time.Time, lookback time.Duration) ([]*DependencyLink, error)
+}
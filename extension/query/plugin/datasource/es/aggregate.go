@@ -0,0 +1,407 @@
+package es
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aquasecurity/esquery"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/pkg/client/es/client"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	v1_trace "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+const (
+	groupsAggregation  = "groups"
+	bucketsAggregation = "buckets"
+
+	// attributeKeyScript reads an attribute as a string, from its keyword sub field or from its
+	// value for the numbers and the booleans which have no keyword sub field.
+	attributeKeyScript = "String f = params.field; " +
+		"if (doc.containsKey(f + '.keyword') && doc[f + '.keyword'].size() > 0) { return doc[f + '.keyword'].value; } " +
+		"if (doc.containsKey(f) && doc[f].size() > 0) { return String.valueOf(doc[f].value); } " +
+		"return '';"
+)
+
+// aggregateField is the document field of a group by key or of an aggregated value.
+type aggregateField struct {
+	// source is the `field` or the `script` of the aggregation.
+	source map[string]interface{}
+	// missing is the key of the documents without the field.
+	missing interface{}
+	// scale converts the values to the unit of the aggregate field, 1 if it's unset.
+	scale float64
+	// key renders the keys of the buckets, fmt.Sprint if it's unset.
+	key func(value interface{}) string
+}
+
+// aggregateLayout resolves the aggregate fields to the fields of the documents of an index.
+type aggregateLayout struct {
+	timestamp string
+	group     func(field string) (aggregateField, bool)
+	value     func(field string) (aggregateField, bool)
+}
+
+func keywordField(field string) aggregateField {
+	return aggregateField{source: map[string]interface{}{"field": field}, missing: ""}
+}
+
+func attributeKeyField(field string) aggregateField {
+	return aggregateField{source: map[string]interface{}{
+		"script": map[string]interface{}{"source": attributeKeyScript, "params": map[string]interface{}{"field": field}},
+	}}
+}
+
+// spanAggregateLayout resolves the fields of the OTLP span documents.
+var spanAggregateLayout = &aggregateLayout{
+	timestamp: "@timestamp",
+	group: func(field string) (aggregateField, bool) {
+		switch field {
+		case datasource.FieldServiceName:
+			return keywordField("Resource.service.name.keyword"), true
+		case datasource.FieldName:
+			return keywordField("Name.keyword"), true
+		case datasource.FieldKind:
+			return keywordField("Kind.keyword"), true
+		case datasource.FieldStatusCode:
+			return aggregateField{
+				source:  map[string]interface{}{"field": "TraceStatus"},
+				missing: 0,
+				key: func(value interface{}) string {
+					code, _ := strconv.ParseInt(fmt.Sprint(value), 10, 32)
+					return v1_trace.Status_StatusCode(code).String()
+				},
+			}, true
+		}
+		if key, ok := datasource.AttributeField(field); ok {
+			return attributeKeyField("Attributes." + key), true
+		}
+		if key, ok := datasource.ResourceField(field); ok {
+			return attributeKeyField("Resource." + key), true
+		}
+		return aggregateField{}, false
+	},
+	value: func(field string) (aggregateField, bool) {
+		if field == datasource.FieldDuration {
+			return aggregateField{source: map[string]interface{}{"script": map[string]interface{}{"source": spanDurationScript}}}, true
+		}
+		if key, ok := datasource.AttributeField(field); ok {
+			return aggregateField{source: map[string]interface{}{"field": "Attributes." + key}}, true
+		}
+		return aggregateField{}, false
+	},
+}
+
+// jaegerAggregateLayout resolves the fields of the Jaeger span documents, the tags are nested
+// documents which can't be grouped by along with the spans.
+var jaegerAggregateLayout = &aggregateLayout{
+	timestamp: "startTimeMillis",
+	group: func(field string) (aggregateField, bool) {
+		switch field {
+		case datasource.FieldServiceName:
+			return keywordField("process.serviceName"), true
+		case datasource.FieldName:
+			return keywordField("operationName"), true
+		}
+		return aggregateField{}, false
+	},
+	value: func(field string) (aggregateField, bool) {
+		if field == datasource.FieldDuration {
+			// duration is stored in microseconds.
+			return aggregateField{source: map[string]interface{}{"field": "duration"}, scale: 0.001}, true
+		}
+		return aggregateField{}, false
+	},
+}
+
+// logAggregateLayout resolves the fields of the log documents, the bodies longer than the
+// `ignore_above` of the keyword sub field are grouped as empty.
+var logAggregateLayout = &aggregateLayout{
+	timestamp: logTimestampField,
+	group: func(field string) (aggregateField, bool) {
+		switch field {
+		case datasource.FieldServiceName:
+			return keywordField(resourceKeywordField("service.name")), true
+		case datasource.FieldBody:
+			return keywordField(logBodyField + keywordSuffix), true
+		case datasource.FieldSeverityText:
+			return keywordField("SeverityText" + keywordSuffix), true
+		}
+		if key, ok := datasource.AttributeField(field); ok {
+			return attributeKeyField("Attributes." + key), true
+		}
+		if key, ok := datasource.ResourceField(field); ok {
+			return attributeKeyField(logResourcePrefix + key), true
+		}
+		return aggregateField{}, false
+	},
+	value: func(field string) (aggregateField, bool) {
+		if key, ok := datasource.AttributeField(field); ok {
+			return aggregateField{source: map[string]interface{}{"field": "Attributes." + key}}, true
+		}
+		return aggregateField{}, false
+	},
+}
+
+func (q *ElasticsearchQuery) AggregateSpans(ctx context.Context, query *datasource.SpanQueryParameters, agg *datasource.AggregateParameters) ([]*datasource.AggregateGroup, error) {
+	return aggregate(ctx, q.client, q.SpanIndex, buildSpanCondition(query), spanAggregateLayout, agg)
+}
+
+func (q *JaegerElasticsearchQuery) AggregateSpans(ctx context.Context, query *datasource.SpanQueryParameters, agg *datasource.AggregateParameters) ([]*datasource.AggregateGroup, error) {
+	return aggregate(ctx, q.client, q.SpanIndex, buildJaegerSpanCondition(query), jaegerAggregateLayout, agg)
+}
+
+func (q *ElasticsearchLogQuery) AggregateLogs(ctx context.Context, query *datasource.LogQueryParameters, agg *datasource.AggregateParameters) ([]*datasource.AggregateGroup, error) {
+	return aggregate(ctx, q.client, q.LoggingIndex, buildLogsCondition(query), logAggregateLayout, agg)
+}
+
+func aggregate(ctx context.Context, c *client.Elastic, index string, query esquery.Mappable, layout *aggregateLayout, agg *datasource.AggregateParameters) ([]*datasource.AggregateGroup, error) {
+	body, err := buildAggregateSearch(query, layout, agg)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.DoSearchBody(ctx, index, body)
+	if err != nil {
+		return nil, err
+	}
+	return decodeAggregateGroups(res, layout, agg)
+}
+
+// buildAggregateSearch groups the documents by a terms aggregation, or a multi_terms aggregation
+// for several keys, ordered by the first aggregation. The buckets of the groups are a
+// date_histogram with the same metric aggregations, the counts are the doc_count of the buckets.
+func buildAggregateSearch(query esquery.Mappable, layout *aggregateLayout, agg *datasource.AggregateParameters) (map[string]interface{}, error) {
+	metrics := map[string]interface{}{}
+	for i, a := range agg.Aggregations {
+		if a.Function == datasource.AggregateCount {
+			continue
+		}
+		field, ok := layout.value(a.Field)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s of %s", datasource.ErrUnsupportedAggregation, a.Function, a.Field)
+		}
+		metrics[metricAggregationName(i)] = metricAggregation(a, field)
+	}
+
+	sub := map[string]interface{}{}
+	for name, metric := range metrics {
+		sub[name] = metric
+	}
+	if agg.Step > 0 {
+		step := int64(agg.Step / time.Second)
+		if step <= 0 {
+			return nil, fmt.Errorf("step %s must be at least one second", agg.Step)
+		}
+		histogram := map[string]interface{}{
+			"date_histogram": map[string]interface{}{
+				"field":          layout.timestamp,
+				"fixed_interval": fmt.Sprintf("%ds", step),
+				"min_doc_count":  1,
+			},
+		}
+		if len(metrics) > 0 {
+			histogram["aggs"] = metrics
+		}
+		sub[bucketsAggregation] = histogram
+	}
+
+	var group map[string]interface{}
+	if len(agg.GroupBy) == 0 {
+		group = map[string]interface{}{"filter": esquery.MatchAll().Map()}
+	} else {
+		terms := make([]map[string]interface{}, 0, len(agg.GroupBy))
+		for _, key := range agg.GroupBy {
+			field, ok := layout.group(key)
+			if !ok {
+				return nil, fmt.Errorf("%w: group by %s", datasource.ErrUnsupportedAggregation, key)
+			}
+			term := map[string]interface{}{}
+			for k, v := range field.source {
+				term[k] = v
+			}
+			if field.missing != nil {
+				term["missing"] = field.missing
+			}
+			terms = append(terms, term)
+		}
+		order := map[string]interface{}{aggregateOrder(agg.Aggregations): esquery.OrderDesc}
+		if len(terms) == 1 {
+			terms[0]["size"] = agg.Limit
+			terms[0]["order"] = order
+			group = map[string]interface{}{"terms": terms[0]}
+		} else {
+			group = map[string]interface{}{
+				"multi_terms": map[string]interface{}{"terms": terms, "size": agg.Limit, "order": order},
+			}
+		}
+	}
+	if len(sub) > 0 {
+		group["aggs"] = sub
+	}
+
+	return map[string]interface{}{
+		"size":  0,
+		"query": query.Map(),
+		"aggs":  map[string]interface{}{groupsAggregation: group},
+	}, nil
+}
+
+func metricAggregationName(i int) string {
+	return "v" + strconv.Itoa(i)
+}
+
+func metricAggregation(a datasource.Aggregation, field aggregateField) map[string]interface{} {
+	params := map[string]interface{}{}
+	for k, v := range field.source {
+		params[k] = v
+	}
+	if a.Function == datasource.AggregatePercentile {
+		params["percents"] = []float64{a.Percentile}
+		return map[string]interface{}{"percentiles": params}
+	}
+	return map[string]interface{}{string(a.Function): params}
+}
+
+// aggregateOrder is the path of the first aggregation ordering the groups.
+func aggregateOrder(aggregations []datasource.Aggregation) string {
+	if len(aggregations) == 0 || aggregations[0].Function == datasource.AggregateCount {
+		return "_count"
+	}
+	if aggregations[0].Function == datasource.AggregatePercentile {
+		return metricAggregationName(0) + "." + strconv.FormatFloat(aggregations[0].Percentile, 'f', -1, 64)
+	}
+	return metricAggregationName(0)
+}
+
+// decodeAggregateGroups reads the groups aggregation, a single bucket without group by keys.
+func decodeAggregateGroups(res *client.SearchResult, layout *aggregateLayout, agg *datasource.AggregateParameters) ([]*datasource.AggregateGroup, error) {
+	raw, ok := res.Aggregations[groupsAggregation]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	var buckets []map[string]json.RawMessage
+	if len(agg.GroupBy) == 0 {
+		var bucket map[string]json.RawMessage
+		if err := json.Unmarshal(*raw, &bucket); err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, bucket)
+	} else {
+		var groups struct {
+			Buckets []map[string]json.RawMessage `json:"buckets"`
+		}
+		if err := json.Unmarshal(*raw, &groups); err != nil {
+			return nil, err
+		}
+		buckets = groups.Buckets
+	}
+
+	scales := make([]float64, len(agg.Aggregations))
+	for i, a := range agg.Aggregations {
+		scales[i] = 1
+		if field, ok := layout.value(a.Field); ok && a.Function != datasource.AggregateCount && field.scale != 0 {
+			scales[i] = field.scale
+		}
+	}
+
+	groups := make([]*datasource.AggregateGroup, 0, len(buckets))
+	for _, bucket := range buckets {
+		group := &datasource.AggregateGroup{}
+		if len(agg.GroupBy) > 0 {
+			keys, err := decodeBucketKey(bucket["key"])
+			if err != nil {
+				return nil, err
+			}
+			for i, key := range keys {
+				if i >= len(agg.GroupBy) {
+					break
+				}
+				field, _ := layout.group(agg.GroupBy[i])
+				if field.key != nil {
+					group.Keys = append(group.Keys, field.key(key))
+				} else {
+					group.Keys = append(group.Keys, fmt.Sprint(key))
+				}
+			}
+		}
+		values, err := decodeAggregateValues(bucket, agg.Aggregations, scales)
+		if err != nil {
+			return nil, err
+		}
+		group.Values = values
+
+		if raw, ok := bucket[bucketsAggregation]; ok {
+			var histogram struct {
+				Buckets []map[string]json.RawMessage `json:"buckets"`
+			}
+			if err = json.Unmarshal(raw, &histogram); err != nil {
+				return nil, err
+			}
+			for _, b := range histogram.Buckets {
+				var key int64
+				if err = json.Unmarshal(b["key"], &key); err != nil {
+					return nil, err
+				}
+				values, err := decodeAggregateValues(b, agg.Aggregations, scales)
+				if err != nil {
+					return nil, err
+				}
+				group.Buckets = append(group.Buckets, datasource.AggregateBucket{Timestamp: time.UnixMilli(key).UTC(), Values: values})
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// decodeBucketKey reads the key of a terms bucket, or the keys of a multi_terms bucket.
+func decodeBucketKey(raw json.RawMessage) ([]interface{}, error) {
+	var key interface{}
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.UseNumber()
+	if err := d.Decode(&key); err != nil {
+		return nil, err
+	}
+	if keys, ok := key.([]interface{}); ok {
+		return keys, nil
+	}
+	return []interface{}{key}, nil
+}
+
+// decodeAggregateValues reads the doc_count or the metric of every aggregation of a bucket, a
+// metric without any value is 0.
+func decodeAggregateValues(bucket map[string]json.RawMessage, aggregations []datasource.Aggregation, scales []float64) ([]float64, error) {
+	values := make([]float64, len(aggregations))
+	for i, a := range aggregations {
+		if a.Function == datasource.AggregateCount {
+			if err := json.Unmarshal(bucket["doc_count"], &values[i]); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		raw, ok := bucket[metricAggregationName(i)]
+		if !ok {
+			continue
+		}
+		var metric struct {
+			Value  *float64            `json:"value"`
+			Values map[string]*float64 `json:"values"`
+		}
+		if err := json.Unmarshal(raw, &metric); err != nil {
+			return nil, err
+		}
+		if metric.Value != nil {
+			values[i] = *metric.Value * scales[i]
+		}
+		// a percentiles aggregation has the single requested percentile
+		for _, v := range metric.Values {
+			if v != nil {
+				values[i] = *v * scales[i]
+			}
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,117 @@
+package es
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/pkg/client/es/client"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildAggregateSearch(t *testing.T) {
+	agg := &datasource.AggregateParameters{
+		GroupBy: []string{"attributes.http.route", "status_code"},
+		Aggregations: []datasource.Aggregation{
+			{Function: datasource.AggregatePercentile, Field: "duration", Percentile: 99},
+			{Function: datasource.AggregateCount},
+		},
+		Step:  time.Minute,
+		Limit: 10,
+	}
+	body, err := buildAggregateSearch(buildSpanCondition(&datasource.SpanQueryParameters{ServiceName: "checkout"}), spanAggregateLayout, agg)
+	require.NoError(t, err)
+	b, err := json.Marshal(body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"size": 0,
+		"query": {"bool": {
+			"must": [{"match_all": {}}],
+			"filter": [{"term": {"Resource.service.name.keyword": {"value": "checkout"}}}]
+		}},
+		"aggs": {"groups": {
+			"multi_terms": {
+				"terms": [
+					{"script": {"source": `+jsonString(attributeKeyScript)+`, "params": {"field": "Attributes.http.route"}}},
+					{"field": "TraceStatus", "missing": 0}
+				],
+				"size": 10,
+				"order": {"v0.99": "desc"}
+			},
+			"aggs": {
+				"v0": {"percentiles": {"script": {"source": `+jsonString(spanDurationScript)+`}, "percents": [99]}},
+				"buckets": {
+					"date_histogram": {"field": "@timestamp", "fixed_interval": "60s", "min_doc_count": 1},
+					"aggs": {"v0": {"percentiles": {"script": {"source": `+jsonString(spanDurationScript)+`}, "percents": [99]}}}
+				}
+			}
+		}}
+	}`, string(b))
+
+	body, err = buildAggregateSearch(buildLogsCondition(&datasource.LogQueryParameters{}), logAggregateLayout, &datasource.AggregateParameters{
+		GroupBy:      []string{"body"},
+		Aggregations: []datasource.Aggregation{{Function: datasource.AggregateCount}},
+		Limit:        10,
+	})
+	require.NoError(t, err)
+	b, err = json.Marshal(body["aggs"])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"groups": {"terms": {"field": "Body.keyword", "missing": "", "size": 10, "order": {"_count": "desc"}}}}`, string(b))
+
+	_, err = buildAggregateSearch(buildJaegerSpanCondition(&datasource.SpanQueryParameters{}), jaegerAggregateLayout, &datasource.AggregateParameters{
+		GroupBy:      []string{"attributes.http.route"},
+		Aggregations: []datasource.Aggregation{{Function: datasource.AggregateCount}},
+	})
+	assert.True(t, errors.Is(err, datasource.ErrUnsupportedAggregation))
+}
+
+func TestDecodeAggregateGroups(t *testing.T) {
+	groups := json.RawMessage(`{"buckets":[
+		{"key":["checkout","POST /cart"],"doc_count":3,"v1":{"value":1500000},
+			"buckets":{"buckets":[{"key":1681873200000,"doc_count":2,"v1":{"value":1000000}},{"key":1681873260000,"doc_count":1,"v1":{"value":2500000}}]}},
+		{"key":["checkout","POST /pay"],"doc_count":1,"v1":{"value":null},"buckets":{"buckets":[]}}
+	]}`)
+	agg := &datasource.AggregateParameters{
+		GroupBy: []string{"service_name", "name"},
+		Aggregations: []datasource.Aggregation{
+			{Function: datasource.AggregateCount},
+			{Function: datasource.AggregateAvg, Field: "duration"},
+		},
+		Step: time.Minute,
+	}
+	result, err := decodeAggregateGroups(&client.SearchResult{Aggregations: client.Aggregations{"groups": &groups}}, jaegerAggregateLayout, agg)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, []string{"checkout", "POST /cart"}, result[0].Keys)
+	assert.Equal(t, []float64{3, 1500}, result[0].Values)
+	assert.Equal(t, []datasource.AggregateBucket{
+		{Timestamp: time.UnixMilli(1681873200000).UTC(), Values: []float64{2, 1000}},
+		{Timestamp: time.UnixMilli(1681873260000).UTC(), Values: []float64{1, 2500}},
+	}, result[0].Buckets)
+	assert.Equal(t, []float64{1, 0}, result[1].Values)
+
+	agg.GroupBy = []string{"status_code"}
+	groups = json.RawMessage(`{"buckets":[{"key":2,"doc_count":5,"v1":{"values":{"99.0":12.5}}}]}`)
+	agg.Aggregations[1] = datasource.Aggregation{Function: datasource.AggregatePercentile, Field: "duration", Percentile: 99}
+	result, err = decodeAggregateGroups(&client.SearchResult{Aggregations: client.Aggregations{"groups": &groups}}, spanAggregateLayout, agg)
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, []string{"STATUS_CODE_ERROR"}, result[0].Keys)
+	assert.Equal(t, []float64{5, 12.5}, result[0].Values)
+
+	agg.GroupBy = nil
+	groups = json.RawMessage(`{"doc_count":7,"v1":{"values":{"99.0":20}}}`)
+	result, err = decodeAggregateGroups(&client.SearchResult{Aggregations: client.Aggregations{"groups": &groups}}, spanAggregateLayout, agg)
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Empty(t, result[0].Keys)
+	assert.Equal(t, []float64{7, 20}, result[0].Values)
+}
+
+func jsonString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
@@ -0,0 +1,72 @@
+package es
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/aquasecurity/esquery"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/pkg/client/es/client"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	v1_trace "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+var _ datasource.TraceArchive = (*ElasticsearchTraceArchive)(nil)
+
+// ElasticsearchTraceArchive reindexes the documents of a trace into an archive index, which is
+// read as the span index of the mapping mode. The archive index is created with the mappings
+// of the span index before the first trace is archived.
+type ElasticsearchTraceArchive struct {
+	client       *client.Elastic
+	SpanIndex    string
+	ArchiveIndex string
+	// TraceIDField is the trace ID field of the documents of the mapping mode.
+	TraceIDField string
+	// archived reads the archive index.
+	archived datasource.Query
+
+	mu      sync.Mutex
+	created bool
+}
+
+func (a *ElasticsearchTraceArchive) ArchiveTrace(ctx context.Context, traceID string) (int, error) {
+	if err := a.createArchiveIndex(ctx); err != nil {
+		if errors.Is(err, client.ErrNotFound) {
+			// there is no span index, so no trace to archive
+			return 0, nil
+		}
+		return 0, err
+	}
+	copied, err := a.client.Reindex(ctx, a.SpanIndex, a.ArchiveIndex, esquery.Term(a.TraceIDField, traceID))
+	if err != nil {
+		return 0, err
+	}
+	return int(copied), nil
+}
+
+func (a *ElasticsearchTraceArchive) GetArchivedTrace(ctx context.Context, traceID string) (*v1_trace.TracesData, error) {
+	return a.archived.GetTrace(ctx, traceID)
+}
+
+// createArchiveIndex creates the archive index with the mappings of the span index, a reindex
+// would create it with dynamic mappings and lose the nested fields of the Jaeger spans. The
+// index is created once, the creation is tried again by the next archive when it fails.
+func (a *ElasticsearchTraceArchive) createArchiveIndex(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.created {
+		return nil
+	}
+	mappings, err := a.client.IndexMappings(ctx, a.SpanIndex)
+	if err != nil {
+		return fmt.Errorf("read the mappings of %s: %w", a.SpanIndex, err)
+	}
+	// a regular index can't have the metadata field of the backing indices of a data stream
+	delete(mappings, "_data_stream_timestamp")
+	if err = a.client.CreateIndex(ctx, a.ArchiveIndex, mappings); err != nil {
+		return fmt.Errorf("create archive index %s: %w", a.ArchiveIndex, err)
+	}
+	a.created = true
+	return nil
+}
@@ -0,0 +1,98 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/pkg/client/es/client"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestElasticsearchTraceArchive(t *testing.T) {
+	var reindex, created map[string]interface{}
+	creates := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/":
+			_, _ = w.Write([]byte(`{"version":{"number":"7.17.0"},"tagline":"You Know, for Search"}`))
+		case "/trace_index/_mapping":
+			_, _ = w.Write([]byte(`{
+				"trace_index-2023.04.18":{"mappings":{"properties":{"TraceId":{"type":"text"}}}},
+				"trace_index-2023.04.19":{"mappings":{"_data_stream_timestamp":{"enabled":true},"properties":{"TraceId":{"type":"keyword"}}}}
+			}`))
+		case "/trace_archive":
+			assert.Equal(t, http.MethodPut, r.Method)
+			creates++
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+			_, _ = w.Write([]byte(`{"acknowledged":true,"index":"trace_archive"}`))
+		case "/_reindex":
+			assert.Equal(t, "true", r.URL.Query().Get("refresh"))
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&reindex))
+			_, _ = w.Write([]byte(`{"took":3,"total":2,"created":2,"updated":0,"failures":[]}`))
+		case "/trace_archive/_search":
+			_, _ = w.Write([]byte(`{"took":1,"hits":{"hits":[{"_id":"01","_source":{"TraceId":"abc","SpanId":"01","Name":"GET /hello","Resource":{"service":{"name":"frontend"}}},"sort":["abc","01"]}]}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	c, err := client.New([]string{server.URL}, "", "")
+	require.NoError(t, err)
+	factory := NewFactory(&ElasticsearchType{TracesIndex: "trace_index", ArchiveTracesIndex: "trace_archive"}, nil)
+	factory.client = c
+	archive, err := factory.CreateTraceArchive()
+	require.NoError(t, err)
+
+	copied, err := archive.ArchiveTrace(context.Background(), "abc")
+	require.NoError(t, err)
+	assert.Equal(t, 2, copied)
+	// the archive index is created with the mappings of the newest span index
+	assert.Equal(t, map[string]interface{}{
+		"mappings": map[string]interface{}{
+			"properties": map[string]interface{}{"TraceId": map[string]interface{}{"type": "keyword"}},
+		},
+	}, created)
+	assert.Equal(t, map[string]interface{}{
+		"source": map[string]interface{}{
+			"index": "trace_index",
+			"query": map[string]interface{}{"term": map[string]interface{}{"TraceId": map[string]interface{}{"value": "abc"}}},
+		},
+		"dest": map[string]interface{}{"index": "trace_archive"},
+	}, reindex)
+
+	_, err = archive.ArchiveTrace(context.Background(), "abc")
+	require.NoError(t, err)
+	assert.Equal(t, 1, creates)
+
+	// there is nothing to archive without a span index
+	factory.cfg.TracesIndex = "missing_index"
+	missing, err := factory.CreateTraceArchive()
+	require.NoError(t, err)
+	copied, err = missing.ArchiveTrace(context.Background(), "abc")
+	require.NoError(t, err)
+	assert.Equal(t, 0, copied)
+
+	trace, err := archive.GetArchivedTrace(context.Background(), "abc")
+	require.NoError(t, err)
+	require.Len(t, trace.ResourceSpans, 1)
+	assert.Equal(t, "GET /hello", trace.ResourceSpans[0].ScopeSpans[0].Spans[0].Name)
+}
+
+func TestCreateTraceArchive(t *testing.T) {
+	_, err := NewFactory(&ElasticsearchType{TracesIndex: "trace_index"}, nil).CreateTraceArchive()
+	assert.ErrorIs(t, err, datasource.ErrArchiveNotConfigured)
+
+	archive, err := NewFactory(&ElasticsearchType{MappingMode: MappingModeJaeger, ArchiveTracesIndex: "jaeger-span-archive"}, nil).CreateTraceArchive()
+	require.NoError(t, err)
+	require.IsType(t, &ElasticsearchTraceArchive{}, archive)
+	assert.Equal(t, JaegerSpanReadAlias, archive.(*ElasticsearchTraceArchive).SpanIndex)
+	assert.Equal(t, "traceID", archive.(*ElasticsearchTraceArchive).TraceIDField)
+}
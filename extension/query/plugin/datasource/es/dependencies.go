@@ -0,0 +1,72 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/aquasecurity/esquery"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/pkg/client/es/client"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+)
+
+var _ datasource.DependencyReader = (*JaegerDependencyReader)(nil)
+
+// JaegerDependencyReader reads the dependency links stored as Jaeger dbmodel documents, each
+// document holds the links aggregated by the exporter over a flush interval.
+type JaegerDependencyReader struct {
+	client *client.Elastic
+	Index  string
+}
+
+type jaegerDependencyLink struct {
+	Parent    string `json:"parent"`
+	Child     string `json:"child"`
+	CallCount uint64 `json:"callCount"`
+}
+
+type jaegerDependencies struct {
+	Timestamp    time.Time              `json:"timestamp"`
+	Dependencies []jaegerDependencyLink `json:"dependencies"`
+}
+
+func (r *JaegerDependencyReader) GetDependencies(ctx context.Context, endTime time.Time, lookback time.Duration) ([]*datasource.DependencyLink, error) {
+	timeRange := esquery.Range("timestamp").
+		Gte(endTime.Add(-lookback).UTC().Format(time.RFC3339Nano)).
+		Lte(endTime.UTC().Format(time.RFC3339Nano))
+	qe := esquery.Search().Query(esquery.Bool().Filter(timeRange)).Size(maxResultWindow)
+	res, err := r.client.DoSearch(ctx, r.Index, qe)
+	if err != nil {
+		return nil, err
+	}
+
+	type pair struct{ parent, child string }
+	calls := make(map[pair]uint64)
+	if res.Hits != nil {
+		for _, hit := range res.Hits.Hits {
+			if hit.Source == nil {
+				continue
+			}
+			doc := &jaegerDependencies{}
+			if err = json.Unmarshal(*hit.Source, doc); err != nil {
+				return nil, err
+			}
+			for _, link := range doc.Dependencies {
+				calls[pair{link.Parent, link.Child}] += link.CallCount
+			}
+		}
+	}
+
+	links := make([]*datasource.DependencyLink, 0, len(calls))
+	for p, count := range calls {
+		links = append(links, &datasource.DependencyLink{Parent: p.parent, Child: p.child, CallCount: count})
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].Parent != links[j].Parent {
+			return links[i].Parent < links[j].Parent
+		}
+		return links[i].Child < links[j].Child
+	})
+	return links, nil
+}
@@ -0,0 +1,58 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/pkg/client/es/client"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJaegerDependencyReader(t *testing.T) {
+	var search map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/jaeger-dependencies-read/_search":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&search))
+			_, _ = w.Write([]byte(`{"took":1,"hits":{"hits":[
+				{"_source":{"timestamp":"2023-04-19T02:00:00Z","dependencies":[{"parent":"frontend","child":"mysql","callCount":2},{"parent":"frontend","child":"redis","callCount":1}]}},
+				{"_source":{"timestamp":"2023-04-19T02:30:00Z","dependencies":[{"parent":"frontend","child":"mysql","callCount":3}]}}
+			]}}`))
+		default:
+			_, _ = w.Write([]byte(`{"version":{"number":"7.17.0"},"tagline":"You Know, for Search"}`))
+		}
+	}))
+	defer server.Close()
+
+	c, err := client.New([]string{server.URL}, "", "")
+	require.NoError(t, err)
+	factory := NewFactory(&ElasticsearchType{MappingMode: MappingModeJaeger}, nil)
+	factory.client = c
+	reader, err := factory.CreateDependencyReader()
+	require.NoError(t, err)
+
+	end := time.Date(2023, 4, 19, 3, 0, 0, 0, time.UTC)
+	links, err := reader.GetDependencies(context.Background(), end, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []*datasource.DependencyLink{
+		{Parent: "frontend", Child: "mysql", CallCount: 5},
+		{Parent: "frontend", Child: "redis", CallCount: 1},
+	}, links)
+	timeRange := search["query"].(map[string]interface{})["bool"].(map[string]interface{})["filter"].([]interface{})[0]
+	assert.Equal(t, map[string]interface{}{"range": map[string]interface{}{"timestamp": map[string]interface{}{
+		"gte": "2023-04-19T02:00:00Z",
+		"lte": "2023-04-19T03:00:00Z",
+	}}}, timeRange)
+
+	factory = NewFactory(&ElasticsearchType{}, nil)
+	_, err = factory.CreateDependencyReader()
+	assert.ErrorIs(t, err, datasource.ErrDependenciesNotConfigured)
+}
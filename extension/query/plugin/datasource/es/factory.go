@@ -1,7 +1,11 @@
 package es
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io"
+	"time"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/pkg/client/es/client"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
@@ -10,6 +14,25 @@ import (
 
 var (
 	_ io.Closer = (*Factory)(nil)
+
+	errNotInitialized = errors.New("elasticsearch client is not initialized")
+	// errMetricQueryUnsupported is returned as the metric documents can't be read as Prometheus series.
+	errMetricQueryUnsupported = errors.New("elasticsearch does not support metric queries")
+	// errECSMappingUnsupported is returned as the ECS documents have none of the fields of the
+	// flattened documents read by the queries.
+	errECSMappingUnsupported = errors.New("elasticsearch mapping mode ecs is not supported, the data must be written with the none or jaeger mode")
+)
+
+// pingTimeout bounds the connectivity check done when initializing the client.
+const pingTimeout = 5 * time.Second
+
+const (
+	// MappingModeNone reads the flattened documents written by the exporter's default `none` mapping.
+	MappingModeNone = "none"
+	// MappingModeECS is the exporter's `ecs` mapping, which can't be read.
+	MappingModeECS = "ecs"
+	// MappingModeJaeger reads Jaeger dbmodel documents from the jaeger read aliases.
+	MappingModeJaeger = "jaeger"
 )
 
 type ElasticsearchType struct {
@@ -22,12 +45,24 @@ type ElasticsearchType struct {
 
 	// Password is used to configure HTTP Basic Authentication.
 	Password string `mapstructure:"password"`
+
+	// MappingMode must match the `mapping.mode` of the elasticsearch exporter writing the data.
+	// Supported modes are `none` (default) and `jaeger`, the `ecs` documents can't be read.
+	MappingMode string `mapstructure:"mapping_mode"`
+
+	// ArchiveTracesIndex is the index the archived traces are copied into, it must not be managed
+	// by an ILM policy. The traces can't be archived when it is empty.
+	ArchiveTracesIndex string `mapstructure:"archive_traces_index"`
+
+	// SavedQueriesIndex is the index of the saved queries, `otel-saved-queries` by default.
+	SavedQueriesIndex string `mapstructure:"saved_queries_index"`
 }
 
 // Factory implements storage.Factory for Elasticsearch as storage.
 type Factory struct {
-	client *client.Elastic
-	cfg    *ElasticsearchType
+	client    *client.Elastic
+	cfg       *ElasticsearchType
+	telemetry *datasource.Telemetry
 }
 
 func (f *Factory) Initialize(logger *zap.Logger) error {
@@ -36,27 +71,149 @@ func (f *Factory) Initialize(logger *zap.Logger) error {
 		logger.Error("initialize es client error")
 		return err
 	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+	if err = ping(ctx, c); err != nil {
+		return err
+	}
+	c.OnSearch = f.telemetry.RecordSearchTook
 	f.client = c
 	return nil
 }
 
+// Ping checks the cluster is reachable and its health is not red.
+func (f *Factory) Ping(ctx context.Context) error {
+	if f.client == nil {
+		return errNotInitialized
+	}
+	return ping(ctx, f.client)
+}
+
+func ping(ctx context.Context, c *client.Elastic) error {
+	if err := c.Ping(ctx); err != nil {
+		return err
+	}
+	status, err := c.ClusterHealth(ctx)
+	if err != nil {
+		return err
+	}
+	if status == "red" {
+		return fmt.Errorf("elasticsearch cluster health is %s", status)
+	}
+	return nil
+}
+
 func (f *Factory) CreateSpanQuery() (datasource.Query, error) {
-	return &ElasticsearchQuery{
+	switch f.cfg.MappingMode {
+	case "", MappingModeNone:
+		return &ElasticsearchQuery{
+			client:       f.client,
+			SpanIndex:    f.cfg.TracesIndex,
+			MetricsIndex: f.cfg.MetricsIndex,
+			LoggingIndex: f.cfg.LoggingIndex,
+		}, nil
+	case MappingModeECS:
+		return nil, errECSMappingUnsupported
+	case MappingModeJaeger:
+		return &JaegerElasticsearchQuery{
+			client:       f.client,
+			SpanIndex:    JaegerSpanReadAlias,
+			ServiceIndex: JaegerServiceReadAlias,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown elasticsearch mapping mode: %s", f.cfg.MappingMode)
+	}
+}
+
+// CreateTraceArchive creates the archive of the traces in the archive traces index, which
+// holds the documents of the mapping mode.
+func (f *Factory) CreateTraceArchive() (datasource.TraceArchive, error) {
+	if f.cfg.ArchiveTracesIndex == "" {
+		return nil, datasource.ErrArchiveNotConfigured
+	}
+	switch f.cfg.MappingMode {
+	case "", MappingModeNone:
+		return &ElasticsearchTraceArchive{
+			client:       f.client,
+			SpanIndex:    f.cfg.TracesIndex,
+			ArchiveIndex: f.cfg.ArchiveTracesIndex,
+			TraceIDField: "TraceId",
+			archived:     &ElasticsearchQuery{client: f.client, SpanIndex: f.cfg.ArchiveTracesIndex},
+		}, nil
+	case MappingModeECS:
+		return nil, errECSMappingUnsupported
+	case MappingModeJaeger:
+		return &ElasticsearchTraceArchive{
+			client:       f.client,
+			SpanIndex:    JaegerSpanReadAlias,
+			ArchiveIndex: f.cfg.ArchiveTracesIndex,
+			TraceIDField: "traceID",
+			archived:     &JaegerElasticsearchQuery{client: f.client, SpanIndex: f.cfg.ArchiveTracesIndex},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown elasticsearch mapping mode: %s", f.cfg.MappingMode)
+	}
+}
+
+// CreateDependencyReader creates the reader of the dependency links written by the elasticsearch
+// exporter in jaeger mapping mode, the other mapping modes don't store them.
+func (f *Factory) CreateDependencyReader() (datasource.DependencyReader, error) {
+	switch f.cfg.MappingMode {
+	case "", MappingModeNone:
+		return nil, datasource.ErrDependenciesNotConfigured
+	case MappingModeECS:
+		return nil, errECSMappingUnsupported
+	case MappingModeJaeger:
+		return &JaegerDependencyReader{client: f.client, Index: JaegerDependenciesReadAlias}, nil
+	default:
+		return nil, fmt.Errorf("unknown elasticsearch mapping mode: %s", f.cfg.MappingMode)
+	}
+}
+
+// CreateSavedQueryStore creates the store of the saved queries, and their index if it doesn't exist.
+func (f *Factory) CreateSavedQueryStore() (datasource.SavedQueryStore, error) {
+	if f.client == nil {
+		return nil, errNotInitialized
+	}
+	index := f.cfg.SavedQueriesIndex
+	if index == "" {
+		index = DefaultSavedQueriesIndex
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+	if err := f.client.CreateIndex(ctx, index, savedQueriesMappings); err != nil {
+		return nil, fmt.Errorf("create saved queries index %s: %w", index, err)
+	}
+	return &ElasticsearchSavedQueryStore{client: f.client, Index: index}, nil
+}
+
+// CreateLogQuery creates the reader of the logs index, which expects the documents of the
+// default mapping mode.
+func (f *Factory) CreateLogQuery() (datasource.LogQuery, error) {
+	if f.cfg.MappingMode == MappingModeECS {
+		return nil, errECSMappingUnsupported
+	}
+	return &ElasticsearchLogQuery{
 		client:       f.client,
-		SpanIndex:    f.cfg.TracesIndex,
-		MetricsIndex: f.cfg.MetricsIndex,
 		LoggingIndex: f.cfg.LoggingIndex,
 	}, nil
 }
 
+// CreateMetricQuery is not supported by Elasticsearch.
+func (f *Factory) CreateMetricQuery() (datasource.MetricQuery, error) {
+	return nil, errMetricQueryUnsupported
+}
+
 // Close closes the resources held by the factory
 func (f *Factory) Close() error {
 	return nil
 }
 
-// NewFactory creates a new Factory.
-func NewFactory(es *ElasticsearchType) *Factory {
+// NewFactory creates a new Factory, telemetry may be nil.
+func NewFactory(es *ElasticsearchType, telemetry *datasource.Telemetry) *Factory {
 	return &Factory{
-		cfg: es,
+		cfg:       es,
+		telemetry: telemetry,
 	}
 }
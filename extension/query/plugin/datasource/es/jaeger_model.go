@@ -0,0 +1,265 @@
+package es
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/pkg/client/es/client"
+	semconv "go.opentelemetry.io/collector/semconv/v1.6.1"
+	v1_common "go.opentelemetry.io/proto/otlp/common/v1"
+	v1_resource "go.opentelemetry.io/proto/otlp/resource/v1"
+	v1_trace "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// The types below mirror the Jaeger Elasticsearch `dbmodel` documents written by
+// the elasticsearch exporter when `mapping.mode: jaeger` is used.
+// refs: https://github.com/jaegertracing/jaeger/blob/main/plugin/storage/es/spanstore/dbmodel/model.go
+
+// JaegerSpan is the Jaeger span document stored in `jaeger-span-*`.
+type JaegerSpan struct {
+	TraceID         string                 `json:"traceID"`
+	SpanID          string                 `json:"spanID"`
+	ParentSpanID    string                 `json:"parentSpanID,omitempty"`
+	Flags           uint32                 `json:"flags,omitempty"`
+	OperationName   string                 `json:"operationName"`
+	References      []JaegerReference      `json:"references"`
+	StartTime       uint64                 `json:"startTime"`
+	StartTimeMillis uint64                 `json:"startTimeMillis"`
+	Duration        uint64                 `json:"duration"`
+	Tags            []JaegerKeyValue       `json:"tags"`
+	Tag             map[string]interface{} `json:"tag,omitempty"`
+	Logs            []JaegerLog            `json:"logs"`
+	Process         JaegerProcess          `json:"process,omitempty"`
+}
+
+// JaegerReference is a reference from one span to another.
+type JaegerReference struct {
+	RefType string `json:"refType"`
+	TraceID string `json:"traceID"`
+	SpanID  string `json:"spanID"`
+}
+
+// JaegerProcess is the process emitting a set of spans.
+type JaegerProcess struct {
+	ServiceName string                 `json:"serviceName"`
+	Tags        []JaegerKeyValue       `json:"tags"`
+	Tag         map[string]interface{} `json:"tag,omitempty"`
+}
+
+// JaegerLog is a log emitted in a span.
+type JaegerLog struct {
+	Timestamp uint64           `json:"timestamp"`
+	Fields    []JaegerKeyValue `json:"fields"`
+}
+
+// JaegerKeyValue is a key-value pair with a typed value.
+type JaegerKeyValue struct {
+	Key   string      `json:"key"`
+	Type  string      `json:"type,omitempty"`
+	Value interface{} `json:"value"`
+}
+
+const (
+	jaegerChildOf     = "CHILD_OF"
+	jaegerTagDotAlias = "@"
+
+	jaegerTagSpanKind          = "span.kind"
+	jaegerTagStatusCode        = "otel.status_code"
+	jaegerTagStatusDescription = "otel.status_description"
+	jaegerTagError             = "error"
+	jaegerTagTraceState        = "w3c.tracestate"
+	jaegerTagScopeName         = "otel.scope.name"
+	jaegerTagScopeVersion      = "otel.scope.version"
+	jaegerTagLibraryName       = "otel.library.name"
+	jaegerTagLibraryVersion    = "otel.library.version"
+	jaegerLogEventField        = "event"
+)
+
+// JaegerDocumentsResourceSpansConvert converts Jaeger span documents back into OTLP ResourceSpans,
+// one ResourceSpans per hit as DocumentsResourceSpansConvert does.
+func JaegerDocumentsResourceSpansConvert(searchHits *client.SearchHits) (*v1_trace.TracesData, error) {
+	rSpans := make([]*v1_trace.ResourceSpans, 0, len(searchHits.Hits))
+	for _, hit := range searchHits.Hits {
+		if hit.Source == nil {
+			continue
+		}
+		var jSpan JaegerSpan
+		d := json.NewDecoder(strings.NewReader(string(*hit.Source)))
+		d.UseNumber()
+		if err := d.Decode(&jSpan); err != nil {
+			return nil, err
+		}
+		rSpans = append(rSpans, jaegerSpanToResourceSpans(&jSpan))
+	}
+	return &v1_trace.TracesData{ResourceSpans: rSpans}, nil
+}
+
+func jaegerSpanToResourceSpans(jSpan *JaegerSpan) *v1_trace.ResourceSpans {
+	resource := &v1_resource.Resource{}
+	if jSpan.Process.ServiceName != "" {
+		resource.Attributes = append(resource.Attributes, &v1_common.KeyValue{
+			Key:   semconv.AttributeServiceName,
+			Value: &v1_common.AnyValue{Value: &v1_common.AnyValue_StringValue{StringValue: jSpan.Process.ServiceName}},
+		})
+	}
+	resource.Attributes = append(resource.Attributes, jaegerTagsToAttributes(jSpan.Process.Tags, jSpan.Process.Tag)...)
+
+	span := &v1_trace.Span{
+		TraceId:           []byte(jSpan.TraceID),
+		SpanId:            []byte(jSpan.SpanID),
+		Name:              jSpan.OperationName,
+		StartTimeUnixNano: jSpan.StartTime * 1000,
+		EndTimeUnixNano:   (jSpan.StartTime + jSpan.Duration) * 1000,
+		Status:            &v1_trace.Status{},
+	}
+	if jSpan.ParentSpanID != "" {
+		span.ParentSpanId = []byte(jSpan.ParentSpanID)
+	}
+
+	for _, ref := range jSpan.References {
+		if span.ParentSpanId == nil && ref.RefType == jaegerChildOf && ref.TraceID == jSpan.TraceID {
+			span.ParentSpanId = []byte(ref.SpanID)
+			continue
+		}
+		if ref.TraceID == jSpan.TraceID && ref.SpanID == string(span.ParentSpanId) {
+			continue
+		}
+		span.Links = append(span.Links, &v1_trace.Span_Link{
+			TraceId: []byte(ref.TraceID),
+			SpanId:  []byte(ref.SpanID),
+		})
+	}
+
+	scope := &v1_common.InstrumentationScope{}
+	hasStatusCode := false
+	hasError := false
+	for _, kv := range jaegerTagsToAttributes(jSpan.Tags, jSpan.Tag) {
+		value := anyValueToString(kv.Value)
+		switch kv.Key {
+		case jaegerTagSpanKind:
+			span.Kind = jaegerSpanKind(value)
+		case jaegerTagStatusCode:
+			hasStatusCode = true
+			switch strings.ToUpper(value) {
+			case "OK":
+				span.Status.Code = v1_trace.Status_STATUS_CODE_OK
+			case "ERROR":
+				span.Status.Code = v1_trace.Status_STATUS_CODE_ERROR
+			}
+		case jaegerTagStatusDescription:
+			span.Status.Message = value
+		case jaegerTagError:
+			hasError = value == "true"
+		case jaegerTagTraceState:
+			span.TraceState = value
+		case jaegerTagScopeName, jaegerTagLibraryName:
+			scope.Name = value
+		case jaegerTagScopeVersion, jaegerTagLibraryVersion:
+			scope.Version = value
+		default:
+			span.Attributes = append(span.Attributes, kv)
+		}
+	}
+	if !hasStatusCode && hasError {
+		span.Status.Code = v1_trace.Status_STATUS_CODE_ERROR
+	}
+
+	for _, log := range jSpan.Logs {
+		event := &v1_trace.Span_Event{TimeUnixNano: log.Timestamp * 1000}
+		for _, kv := range jaegerTagsToAttributes(log.Fields, nil) {
+			if kv.Key == jaegerLogEventField && event.Name == "" {
+				event.Name = anyValueToString(kv.Value)
+				continue
+			}
+			event.Attributes = append(event.Attributes, kv)
+		}
+		span.Events = append(span.Events, event)
+	}
+
+	return &v1_trace.ResourceSpans{
+		Resource: resource,
+		ScopeSpans: []*v1_trace.ScopeSpans{
+			{
+				Scope: scope,
+				Spans: []*v1_trace.Span{span},
+			},
+		},
+	}
+}
+
+// jaegerSpanKind maps the `span.kind` tag back to the OTLP span kind.
+func jaegerSpanKind(kind string) v1_trace.Span_SpanKind {
+	switch strings.ToLower(kind) {
+	case "client":
+		return v1_trace.Span_SPAN_KIND_CLIENT
+	case "server":
+		return v1_trace.Span_SPAN_KIND_SERVER
+	case "producer":
+		return v1_trace.Span_SPAN_KIND_PRODUCER
+	case "consumer":
+		return v1_trace.Span_SPAN_KIND_CONSUMER
+	case "internal":
+		return v1_trace.Span_SPAN_KIND_INTERNAL
+	default:
+		return v1_trace.Span_SPAN_KIND_UNSPECIFIED
+	}
+}
+
+// jaegerTagsToAttributes converts both the nested `tags` list and the flattened `tag` object.
+func jaegerTagsToAttributes(tags []JaegerKeyValue, tagMap map[string]interface{}) []*v1_common.KeyValue {
+	attributes := make([]*v1_common.KeyValue, 0, len(tags)+len(tagMap))
+	for _, kv := range tags {
+		attributes = append(attributes, &v1_common.KeyValue{
+			Key:   kv.Key,
+			Value: jaegerValueToAnyValue(kv.Type, kv.Value),
+		})
+	}
+	for _, kv := range toKeyValues(tagMap) {
+		kv.Key = strings.ReplaceAll(kv.Key, jaegerTagDotAlias, ".")
+		attributes = append(attributes, kv)
+	}
+	return attributes
+}
+
+// jaegerValueToAnyValue parses a dbmodel value, which is stored as a string for all types.
+func jaegerValueToAnyValue(valueType string, value interface{}) *v1_common.AnyValue {
+	str := anyValueToString(toAnyValue(value))
+	switch valueType {
+	case "bool":
+		if b, err := strconv.ParseBool(str); err == nil {
+			return &v1_common.AnyValue{Value: &v1_common.AnyValue_BoolValue{BoolValue: b}}
+		}
+	case "int64":
+		if i, err := strconv.ParseInt(str, 10, 64); err == nil {
+			return &v1_common.AnyValue{Value: &v1_common.AnyValue_IntValue{IntValue: i}}
+		}
+	case "float64":
+		if f, err := strconv.ParseFloat(str, 64); err == nil {
+			return &v1_common.AnyValue{Value: &v1_common.AnyValue_DoubleValue{DoubleValue: f}}
+		}
+	case "binary":
+		if b, err := hex.DecodeString(str); err == nil {
+			return &v1_common.AnyValue{Value: &v1_common.AnyValue_BytesValue{BytesValue: b}}
+		}
+	}
+	return &v1_common.AnyValue{Value: &v1_common.AnyValue_StringValue{StringValue: str}}
+}
+
+func anyValueToString(value *v1_common.AnyValue) string {
+	switch v := value.GetValue().(type) {
+	case *v1_common.AnyValue_StringValue:
+		return v.StringValue
+	case *v1_common.AnyValue_BoolValue:
+		return strconv.FormatBool(v.BoolValue)
+	case *v1_common.AnyValue_IntValue:
+		return strconv.FormatInt(v.IntValue, 10)
+	case *v1_common.AnyValue_DoubleValue:
+		return strconv.FormatFloat(v.DoubleValue, 'f', -1, 64)
+	case *v1_common.AnyValue_BytesValue:
+		return hex.EncodeToString(v.BytesValue)
+	default:
+		return ""
+	}
+}
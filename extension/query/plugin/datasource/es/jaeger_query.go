@@ -0,0 +1,270 @@
+package es
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aquasecurity/esquery"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/api/v1alpha1"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/pkg/client/es/client"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	v1_common "go.opentelemetry.io/proto/otlp/common/v1"
+	v1_logs "go.opentelemetry.io/proto/otlp/logs/v1"
+	v1_resource "go.opentelemetry.io/proto/otlp/resource/v1"
+	v1_trace "go.opentelemetry.io/proto/otlp/trace/v1"
+	"go.uber.org/zap"
+)
+
+const (
+	// JaegerSpanReadAlias, JaegerServiceReadAlias and JaegerDependenciesReadAlias are the read
+	// aliases created by the elasticsearch exporter index templates in jaeger mapping mode.
+	JaegerSpanReadAlias         = "jaeger-span-read"
+	JaegerServiceReadAlias      = "jaeger-service-read"
+	JaegerDependenciesReadAlias = "jaeger-dependencies-read"
+)
+
+var _ datasource.Query = (*JaegerElasticsearchQuery)(nil)
+
+// JaegerElasticsearchQuery reads spans stored as Jaeger dbmodel documents.
+type JaegerElasticsearchQuery struct {
+	client       *client.Elastic
+	SpanIndex    string
+	ServiceIndex string
+}
+
+func (q *JaegerElasticsearchQuery) GetService(ctx context.Context) ([]*v1_resource.Resource, error) {
+	query := esquery.Search()
+	query.Aggs(
+		esquery.TermsAgg("service_name_aggregation", "serviceName").Order(map[string]string{"_count": "desc"}).Size(100),
+	).Size(0)
+
+	res, err := q.client.DoSearch(ctx, q.ServiceIndex, query)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := decodeBucketKeys(res)
+	if err != nil {
+		return nil, err
+	}
+	services := make([]*v1_resource.Resource, 0, len(names))
+	for _, name := range names {
+		services = append(services, &v1_resource.Resource{
+			Attributes: []*v1_common.KeyValue{
+				{
+					Key:   "service.name",
+					Value: &v1_common.AnyValue{Value: &v1_common.AnyValue_StringValue{StringValue: name}},
+				},
+			},
+		})
+	}
+	return services, nil
+}
+
+func (q *JaegerElasticsearchQuery) GetOperations(ctx context.Context, params *datasource.OperationsQueryParameters) ([]string, error) {
+	query := esquery.Search()
+	boolQ := esquery.Bool()
+	index := q.ServiceIndex
+	serviceField := "serviceName"
+
+	// the service index does not record span kind, fall back to the span index then.
+	if params.SpanKind != "" {
+		index = q.SpanIndex
+		serviceField = "process.serviceName"
+		boolQ.Must(jaegerNestedTagQuery("tags", "span.kind", jaegerSpanKindTag(params.SpanKind)))
+	}
+	if params.ServiceName != "" {
+		boolQ.Must(esquery.Term(serviceField, params.ServiceName))
+	}
+
+	query.Query(boolQ)
+	query.Aggs(
+		esquery.TermsAgg("service_operations", "operationName").Order(map[string]string{"_count": "desc"}).Size(10000),
+	).Size(0)
+
+	res, err := q.client.DoSearch(ctx, index, query)
+	if err != nil {
+		return nil, err
+	}
+	return decodeBucketKeys(res)
+}
+
+func (q *JaegerElasticsearchQuery) SearchTraces(ctx context.Context, query *datasource.TraceQueryParameters) (*v1alpha1.TracesData, error) {
+	traces, err := q.FindTraces(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return datasource.DocumentsTracesConvert(traces)
+}
+
+// FindTraces returns the spans of the matching traces with a search of the trace IDs and a search of their spans.
+func (q *JaegerElasticsearchQuery) FindTraces(ctx context.Context, query *datasource.TraceQueryParameters) (*v1_trace.TracesData, error) {
+	ids, err := q.FindTraceIds(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return &v1_trace.TracesData{}, nil
+	}
+
+	traces, err := q.multiGetSpans(ctx, ids...)
+	if err != nil {
+		zap.S().Errorf("failed to query traces:%v", err)
+		return nil, err
+	}
+	orderTraces(traces, ids)
+	return traces, nil
+}
+
+func (q *JaegerElasticsearchQuery) MultiGetTraces(ctx context.Context, traceIds ...string) (*v1alpha1.TracesData, error) {
+	tracesData, err := q.multiGetSpans(ctx, traceIds...)
+	if err != nil {
+		return nil, err
+	}
+	return datasource.DocumentsTracesConvert(tracesData)
+}
+
+// multiGetSpans returns the spans of the given traces.
+func (q *JaegerElasticsearchQuery) multiGetSpans(ctx context.Context, traceIds ...string) (*v1_trace.TracesData, error) {
+	boolQ := esquery.Bool()
+	boolQ.Must(Terms("traceID", traceIds...))
+	hits, err := searchTraceSpans(ctx, q.client, q.SpanIndex, boolQ, "traceID", "spanID")
+	if err != nil {
+		return nil, err
+	}
+	return JaegerDocumentsResourceSpansConvert(hits)
+}
+
+func (q *JaegerElasticsearchQuery) GetTrace(ctx context.Context, traceID string) (*v1_trace.TracesData, error) {
+	boolQ := esquery.Bool()
+	boolQ.Must(esquery.Term("traceID", traceID))
+	hits, err := searchTraceSpans(ctx, q.client, q.SpanIndex, boolQ, "traceID", "spanID")
+	if err != nil {
+		return nil, err
+	}
+	return JaegerDocumentsResourceSpansConvert(hits)
+}
+
+func (q *JaegerElasticsearchQuery) SearchLogs(ctx context.Context) (*v1_logs.LogsData, error) {
+	return nil, nil
+}
+
+func (q *JaegerElasticsearchQuery) GetLog(ctx context.Context) (*v1_logs.LogsData, error) {
+	return nil, nil
+}
+
+func (q *JaegerElasticsearchQuery) FindTraceIds(ctx context.Context, queryParams *datasource.TraceQueryParameters) ([]string, error) {
+	idsQsl, err := buildJaegerTraceIdsQuery(queryParams)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := q.client.DoSearch(ctx, q.SpanIndex, idsQsl)
+	if err != nil {
+		return nil, err
+	}
+	return decodeBucketKeys(res)
+}
+
+func buildJaegerTraceIdsQuery(params *datasource.TraceQueryParameters) (*esquery.SearchRequest, error) {
+	q, err := buildJaegerTraceQuery(params)
+	if err != nil {
+		return nil, err
+	}
+
+	// newest traces first, as jaeger-query does.
+	aggs := esquery.TermsAgg("traceIDs", "traceID").
+		Order(map[string]string{"startTime": "desc"}).
+		Aggs(esquery.Max("startTime", "startTime"))
+	if params.NumTraces > 0 {
+		aggs.Size(uint64(params.NumTraces))
+	} else {
+		aggs.Size(uint64(20))
+	}
+
+	return q.Aggs(aggs).Size(0), nil
+}
+
+func buildJaegerTraceQuery(params *datasource.TraceQueryParameters) (*esquery.SearchRequest, error) {
+	q := esquery.Search()
+	if !params.StartTime.IsZero() && !params.EndTime.IsZero() && !params.StartTime.Before(params.EndTime) {
+		return q, errParsTime
+	}
+	return q.Query(buildJaegerSpanCondition(params.SpanQuery())), nil
+}
+
+// jaegerTagQuery matches a tag on the span, its process, or in the flattened
+// `tag` object used when the exporter is configured with tags as fields.
+func jaegerTagQuery(key, value string) esquery.Mappable {
+	return esquery.Bool().
+		Should(
+			jaegerNestedTagQuery("tags", key, value),
+			jaegerNestedTagQuery("process.tags", key, value),
+			esquery.Term("tag."+strings.ReplaceAll(key, ".", jaegerTagDotAlias), value),
+			esquery.Term("process.tag."+strings.ReplaceAll(key, ".", jaegerTagDotAlias), value),
+		).
+		MinimumShouldMatch(1)
+}
+
+// jaegerTagExistsQuery matches a span having the tag, whatever its value.
+func jaegerTagExistsQuery(key string) esquery.Mappable {
+	return esquery.Bool().
+		Should(
+			jaegerNestedTagKeyQuery("tags", key),
+			jaegerNestedTagKeyQuery("process.tags", key),
+			esquery.Exists("tag."+strings.ReplaceAll(key, ".", jaegerTagDotAlias)),
+			esquery.Exists("process.tag."+strings.ReplaceAll(key, ".", jaegerTagDotAlias)),
+		).
+		MinimumShouldMatch(1)
+}
+
+func jaegerNestedTagKeyQuery(path, key string) esquery.Mappable {
+	return esquery.CustomQuery(map[string]interface{}{
+		"nested": map[string]interface{}{
+			"path":  path,
+			"query": esquery.Term(path+".key", key).Map(),
+		},
+	})
+}
+
+func jaegerNestedTagQuery(path, key, value string) esquery.Mappable {
+	return esquery.CustomQuery(map[string]interface{}{
+		"nested": map[string]interface{}{
+			"path": path,
+			"query": esquery.Bool().Must(
+				esquery.Term(path+".key", key),
+				esquery.Term(path+".value", value),
+			).Map(),
+		},
+	})
+}
+
+// jaegerSpanKindTag maps the OTLP span kind (e.g. SPAN_KIND_SERVER) to the jaeger `span.kind` tag value.
+func jaegerSpanKindTag(kind string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.ToUpper(kind), "SPAN_KIND_"))
+}
+
+// decodeBucketKeys collects the keys of the terms aggregation buckets in res.
+func decodeBucketKeys(res *client.SearchResult) ([]string, error) {
+	var keys []string
+	for _, agg := range res.Aggregations {
+		rMaps, err := DecodeSearchResult(*agg)
+		if err != nil {
+			return nil, err
+		}
+		values, ok := rMaps["buckets"].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, value := range values {
+			bucket, ok := value.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if key, ok := bucket["key"].(string); ok {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys, nil
+}
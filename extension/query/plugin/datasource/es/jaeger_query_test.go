@@ -0,0 +1,123 @@
+package es
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/duration"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/pkg/client/es/client"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1_trace "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+func mockJaegerSearchHits() *client.SearchHits {
+	root := json.RawMessage(`{"traceID":"01020304050607080807060504030201","spanID":"0102030405060708","flags":1,"operationName":"/hello","references":[],"startTime":1681873445000000,"startTimeMillis":1681873445000,"duration":2000,"tags":[{"key":"span.kind","type":"string","value":"server"},{"key":"http.status_code","type":"int64","value":"200"}],"logs":[],"process":{"serviceName":"demo-server","tags":[{"key":"host.name","type":"string","value":"localhost"}]}}`)
+	child := json.RawMessage(`{"traceID":"01020304050607080807060504030201","spanID":"1112131415161718","operationName":"HTTP GET","references":[{"refType":"CHILD_OF","traceID":"01020304050607080807060504030201","spanID":"0102030405060708"},{"refType":"FOLLOWS_FROM","traceID":"0a0b0c0d0e0f","spanID":"1a1b1c1d1e1f"}],"startTime":1681873445000500,"startTimeMillis":1681873445000,"duration":1000,"tags":[{"key":"span.kind","type":"string","value":"client"},{"key":"otel.status_code","type":"string","value":"ERROR"},{"key":"otel.status_description","type":"string","value":"connection refused"},{"key":"error","type":"bool","value":"true"},{"key":"w3c.tracestate","type":"string","value":"rojo=00f067aa0ba902b7"},{"key":"otel.library.name","type":"string","value":"net/http"},{"key":"otel.library.version","type":"string","value":"0.36.0"},{"key":"retry","type":"float64","value":"1.5"}],"tag":{"peer@service":"demo-server"},"logs":[{"timestamp":1681873445000600,"fields":[{"key":"event","type":"string","value":"exception"},{"key":"exception.message","type":"string","value":"boom"}]}],"process":{"serviceName":"demo-client","tags":[]}}`)
+	return &client.SearchHits{
+		Hits: []*client.SearchHit{
+			{Index: "jaeger-span-2023-04-19", Type: "_doc", Source: &root},
+			{Index: "jaeger-span-2023-04-19", Type: "_doc", Source: &child},
+		},
+	}
+}
+
+func TestJaegerDocumentsConvert(t *testing.T) {
+	tracesData, err := JaegerDocumentsResourceSpansConvert(mockJaegerSearchHits())
+	require.NoError(t, err)
+	require.Equal(t, 2, len(tracesData.ResourceSpans))
+
+	root := tracesData.ResourceSpans[0]
+	assert.Equal(t, "service.name", root.Resource.Attributes[0].Key)
+	assert.Equal(t, "demo-server", root.Resource.Attributes[0].Value.GetStringValue())
+	assert.Equal(t, "localhost", root.Resource.Attributes[1].Value.GetStringValue())
+	rootSpan := root.ScopeSpans[0].Spans[0]
+	assert.Equal(t, v1_trace.Span_SPAN_KIND_SERVER, rootSpan.Kind)
+	assert.Equal(t, uint64(1681873445000000000), rootSpan.StartTimeUnixNano)
+	assert.Equal(t, uint64(1681873445002000000), rootSpan.EndTimeUnixNano)
+	assert.Nil(t, rootSpan.ParentSpanId)
+	require.Equal(t, 1, len(rootSpan.Attributes))
+	assert.Equal(t, int64(200), rootSpan.Attributes[0].Value.GetIntValue())
+
+	child := tracesData.ResourceSpans[1]
+	assert.Equal(t, "net/http", child.ScopeSpans[0].Scope.Name)
+	assert.Equal(t, "0.36.0", child.ScopeSpans[0].Scope.Version)
+	span := child.ScopeSpans[0].Spans[0]
+	assert.Equal(t, "HTTP GET", span.Name)
+	assert.Equal(t, []byte("0102030405060708"), span.ParentSpanId)
+	assert.Equal(t, v1_trace.Span_SPAN_KIND_CLIENT, span.Kind)
+	assert.Equal(t, v1_trace.Status_STATUS_CODE_ERROR, span.Status.Code)
+	assert.Equal(t, "connection refused", span.Status.Message)
+	assert.Equal(t, "rojo=00f067aa0ba902b7", span.TraceState)
+	require.Equal(t, 1, len(span.Links))
+	assert.Equal(t, []byte("1a1b1c1d1e1f"), span.Links[0].SpanId)
+	require.Equal(t, 2, len(span.Attributes))
+	assert.Equal(t, 1.5, span.Attributes[0].Value.GetDoubleValue())
+	assert.Equal(t, "peer.service", span.Attributes[1].Key)
+	require.Equal(t, 1, len(span.Events))
+	assert.Equal(t, "exception", span.Events[0].Name)
+	assert.Equal(t, uint64(1681873445000600000), span.Events[0].TimeUnixNano)
+	assert.Equal(t, "boom", span.Events[0].Attributes[0].Value.GetStringValue())
+
+	traces, err := datasource.DocumentsTracesConvert(tracesData)
+	require.NoError(t, err)
+	assert.Equal(t, "/hello", traces.Traces[0].OperationName)
+}
+
+func TestBuildJaegerTraceIdsQuery(t *testing.T) {
+	start := time.Unix(1681873445, 0)
+	params := &datasource.TraceQueryParameters{
+		ServiceName:   "demo-server",
+		OperationName: "/hello",
+		Tags:          map[string]string{"http.method": "GET"},
+		TagKeys:       []string{"error"},
+		StartTime:     start,
+		EndTime:       start.Add(time.Hour),
+		DurationMin:   &duration.Duration{Nanos: 1000000},
+		NumTraces:     10,
+	}
+	q, err := buildJaegerTraceIdsQuery(params)
+	require.NoError(t, err)
+
+	body, err := json.Marshal(q.Map())
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `{"term":{"process.serviceName":{"value":"demo-server"}}}`)
+	assert.Contains(t, string(body), `{"term":{"operationName":{"value":"/hello"}}}`)
+	assert.Contains(t, string(body), `"startTimeMillis":{"gte":1681873445000,"lte":1681877045000}`)
+	assert.Contains(t, string(body), `"duration":{"gte":1000}`)
+	assert.Contains(t, string(body), `{"term":{"tag.http@method":{"value":"GET"}}}`)
+	assert.Contains(t, string(body), `"path":"process.tags"`)
+	assert.Contains(t, string(body), `{"exists":{"field":"tag.error"}}`)
+	assert.Contains(t, string(body), `"query":{"term":{"tags.key":{"value":"error"}}}`)
+	assert.Contains(t, string(body), `"terms":{"field":"traceID","order":{"startTime":"desc"},"size":10}`)
+
+	params.EndTime = start.Add(-time.Hour)
+	_, err = buildJaegerTraceIdsQuery(params)
+	assert.ErrorIs(t, err, errParsTime)
+}
+
+func TestCreateSpanQueryMappingMode(t *testing.T) {
+	q, err := NewFactory(&ElasticsearchType{TracesIndex: "trace_index"}, nil).CreateSpanQuery()
+	require.NoError(t, err)
+	assert.IsType(t, &ElasticsearchQuery{}, q)
+
+	q, err = NewFactory(&ElasticsearchType{MappingMode: MappingModeJaeger}, nil).CreateSpanQuery()
+	require.NoError(t, err)
+	require.IsType(t, &JaegerElasticsearchQuery{}, q)
+	assert.Equal(t, JaegerSpanReadAlias, q.(*JaegerElasticsearchQuery).SpanIndex)
+	assert.Equal(t, JaegerServiceReadAlias, q.(*JaegerElasticsearchQuery).ServiceIndex)
+
+	_, err = NewFactory(&ElasticsearchType{MappingMode: "unknown"}, nil).CreateSpanQuery()
+	assert.Error(t, err)
+
+	// the ECS documents are rejected instead of returning empty results
+	factory := NewFactory(&ElasticsearchType{MappingMode: MappingModeECS, ArchiveTracesIndex: "trace_archive"}, nil)
+	_, err = factory.CreateSpanQuery()
+	assert.ErrorIs(t, err, errECSMappingUnsupported)
+	_, err = factory.CreateTraceArchive()
+	assert.ErrorIs(t, err, errECSMappingUnsupported)
+	_, err = factory.CreateLogQuery()
+	assert.ErrorIs(t, err, errECSMappingUnsupported)
+}
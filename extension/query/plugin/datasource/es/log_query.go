@@ -0,0 +1,341 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aquasecurity/esquery"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/pkg/client/es/client"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+)
+
+const (
+	logTimestampField = "@timestamp"
+	logBodyField      = "Body"
+	logResourcePrefix = "Resource."
+	keywordSuffix     = ".keyword"
+
+	defaultLogSearchSize = 100
+	// maxLogBuckets bounds the streams and label values returned by the aggregations.
+	maxLogBuckets = 1000
+
+	streamsAggregation   = "streams"
+	histogramAggregation = "histogram"
+)
+
+// ElasticsearchLogQuery reads the log documents written by the exporter to the logs index.
+// The stream labels are the string resource attributes, read from their keyword sub fields.
+type ElasticsearchLogQuery struct {
+	client       *client.Elastic
+	LoggingIndex string
+}
+
+var _ datasource.LogQuery = (*ElasticsearchLogQuery)(nil)
+
+func (q *ElasticsearchLogQuery) FindLogs(ctx context.Context, query *datasource.LogQueryParameters) ([]*datasource.LogEntry, error) {
+	res, err := q.client.DoSearch(ctx, q.LoggingIndex, buildLogsQuery(query))
+	if err != nil {
+		return nil, err
+	}
+	return decodeLogEntries(res.Hits)
+}
+
+func (q *ElasticsearchLogQuery) CountLogs(ctx context.Context, query *datasource.LogQueryParameters) ([]*datasource.LogSeries, error) {
+	labels, err := q.LogLabelNames(ctx, query.StartTime, query.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	qe, err := buildCountLogsQuery(query, labels)
+	if err != nil {
+		return nil, err
+	}
+	res, err := q.client.DoSearch(ctx, q.LoggingIndex, qe)
+	if err != nil {
+		return nil, err
+	}
+	return decodeLogSeries(res, labels)
+}
+
+// LogLabelNames reads the resource attribute keys from the index mapping, regardless of the time range.
+func (q *ElasticsearchLogQuery) LogLabelNames(ctx context.Context, _, _ time.Time) ([]string, error) {
+	fields, err := q.client.FieldNames(ctx, q.LoggingIndex, logResourcePrefix+"*")
+	if err != nil {
+		return nil, err
+	}
+	var labels []string
+	for _, field := range fields {
+		if strings.HasSuffix(field, keywordSuffix) {
+			labels = append(labels, strings.TrimSuffix(strings.TrimPrefix(field, logResourcePrefix), keywordSuffix))
+		}
+	}
+	return labels, nil
+}
+
+func (q *ElasticsearchLogQuery) LogLabelValues(ctx context.Context, name string, start, end time.Time) ([]string, error) {
+	qe := esquery.Search().
+		Query(buildLogsCondition(&datasource.LogQueryParameters{StartTime: start, EndTime: end})).
+		Aggs(esquery.TermsAgg("values", resourceKeywordField(name)).Size(maxLogBuckets)).
+		Size(0)
+	res, err := q.client.DoSearch(ctx, q.LoggingIndex, qe)
+	if err != nil {
+		return nil, err
+	}
+	values, err := decodeBucketKeys(res)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(values)
+	return values, nil
+}
+
+func (q *ElasticsearchLogQuery) LogStreams(ctx context.Context, query *datasource.LogQueryParameters) ([]map[string]string, error) {
+	labels, err := q.LogLabelNames(ctx, query.StartTime, query.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	if len(labels) == 0 {
+		return nil, nil
+	}
+	qe := esquery.Search().
+		Query(buildLogsCondition(query)).
+		Aggs(esquery.CustomAgg(streamsAggregation, streamsAggregationMap(labels, nil))).
+		Size(0)
+	res, err := q.client.DoSearch(ctx, q.LoggingIndex, qe)
+	if err != nil {
+		return nil, err
+	}
+	series, err := decodeLogSeries(res, labels)
+	if err != nil {
+		return nil, err
+	}
+	streams := make([]map[string]string, 0, len(series))
+	for _, s := range series {
+		streams = append(streams, s.Labels)
+	}
+	return streams, nil
+}
+
+func buildLogsQuery(query *datasource.LogQueryParameters) *esquery.SearchRequest {
+	order := esquery.OrderDesc
+	if query.Forward {
+		order = esquery.OrderAsc
+	}
+	size := defaultLogSearchSize
+	if query.Limit > 0 {
+		size = query.Limit
+	}
+	if size > maxResultWindow {
+		size = maxResultWindow
+	}
+	return esquery.Search().Query(buildLogsCondition(query)).Sort(logTimestampField, order).Size(uint64(size))
+}
+
+// buildCountLogsQuery counts the documents per stream of the labels and per Step bucket.
+func buildCountLogsQuery(query *datasource.LogQueryParameters, labels []string) (*esquery.SearchRequest, error) {
+	step := int64(query.Step / time.Second)
+	if step <= 0 {
+		return nil, fmt.Errorf("step %s must be at least one second", query.Step)
+	}
+	histogram := map[string]interface{}{
+		"date_histogram": map[string]interface{}{
+			"field":          logTimestampField,
+			"fixed_interval": fmt.Sprintf("%ds", step),
+			"min_doc_count":  1,
+		},
+	}
+	qe := esquery.Search().Query(buildLogsCondition(query)).Size(0)
+	if len(labels) == 0 {
+		return qe.Aggs(esquery.CustomAgg(histogramAggregation, histogram)), nil
+	}
+	return qe.Aggs(esquery.CustomAgg(streamsAggregation, streamsAggregationMap(labels, histogram))), nil
+}
+
+// streamsAggregationMap groups the documents by the values of the labels, an absent label is empty.
+func streamsAggregationMap(labels []string, sub map[string]interface{}) map[string]interface{} {
+	var agg map[string]interface{}
+	if len(labels) == 1 {
+		agg = map[string]interface{}{
+			"terms": map[string]interface{}{"field": resourceKeywordField(labels[0]), "size": maxLogBuckets, "missing": ""},
+		}
+	} else {
+		terms := make([]map[string]interface{}, 0, len(labels))
+		for _, label := range labels {
+			terms = append(terms, map[string]interface{}{"field": resourceKeywordField(label), "missing": ""})
+		}
+		agg = map[string]interface{}{
+			"multi_terms": map[string]interface{}{"terms": terms, "size": maxLogBuckets},
+		}
+	}
+	if sub != nil {
+		agg["aggs"] = map[string]interface{}{histogramAggregation: sub}
+	}
+	return agg
+}
+
+// buildLogsCondition builds the filters of the time range, label matchers and line filters.
+// A line filter matches the keyword sub field, which only holds the short bodies, or the phrase
+// in the analyzed body.
+func buildLogsCondition(query *datasource.LogQueryParameters) *esquery.BoolQuery {
+	boolQ := esquery.Bool()
+	if !query.StartTime.IsZero() || !query.EndTime.IsZero() {
+		timeRange := esquery.Range(logTimestampField)
+		if !query.StartTime.IsZero() {
+			timeRange.Gte(query.StartTime.UTC().Format(DATE_LAYOUT))
+		}
+		if !query.EndTime.IsZero() {
+			timeRange.Lte(query.EndTime.UTC().Format(DATE_LAYOUT))
+		}
+		boolQ.Filter(timeRange)
+	}
+
+	for _, m := range query.Matchers {
+		field := resourceKeywordField(m.Name)
+		switch m.Type {
+		case datasource.MatchEqual:
+			boolQ.Filter(esquery.Term(field, m.Value))
+		case datasource.MatchNotEqual:
+			boolQ.MustNot(esquery.Term(field, m.Value))
+		case datasource.MatchRegexp:
+			boolQ.Filter(esquery.Regexp(field, m.Value))
+		case datasource.MatchNotRegexp:
+			boolQ.MustNot(esquery.Regexp(field, m.Value))
+		}
+	}
+
+	for _, f := range query.LineFilters {
+		switch f.Type {
+		case datasource.MatchEqual:
+			boolQ.Filter(lineContains(f.Value))
+		case datasource.MatchNotEqual:
+			boolQ.MustNot(lineContains(f.Value))
+		case datasource.MatchRegexp:
+			boolQ.Filter(esquery.Regexp(logBodyField+keywordSuffix, ".*("+f.Value+").*"))
+		case datasource.MatchNotRegexp:
+			boolQ.MustNot(esquery.Regexp(logBodyField+keywordSuffix, ".*("+f.Value+").*"))
+		}
+	}
+	return boolQ
+}
+
+func lineContains(value string) esquery.Mappable {
+	return esquery.Bool().
+		Should(
+			esquery.Wildcard(logBodyField+keywordSuffix, "*"+escapeWildcard(value)+"*"),
+			esquery.MatchPhrase(logBodyField, value),
+		).
+		MinimumShouldMatch(1)
+}
+
+func escapeWildcard(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`).Replace(value)
+}
+
+func resourceKeywordField(label string) string {
+	return logResourcePrefix + label + keywordSuffix
+}
+
+// decodeLogEntries reads the timestamp, body and resource attributes of the log documents.
+func decodeLogEntries(hits *client.SearchHits) ([]*datasource.LogEntry, error) {
+	entries := make([]*datasource.LogEntry, 0, len(hits.Hits))
+	for _, hit := range hits.Hits {
+		source, err := DecodeSearchResult(*hit.Source)
+		if err != nil {
+			return nil, err
+		}
+		fields := map[string]interface{}{}
+		flattenFields(fields, "", source)
+
+		entry := &datasource.LogEntry{Labels: map[string]string{}}
+		for k, v := range fields {
+			switch {
+			case k == logTimestampField:
+				entry.Timestamp = parseLogTimestamp(fmt.Sprint(v))
+			case k == logBodyField:
+				entry.Line = fmt.Sprint(v)
+			case strings.HasPrefix(k, logResourcePrefix):
+				entry.Labels[strings.TrimPrefix(k, logResourcePrefix)] = fmt.Sprint(v)
+			}
+		}
+		// a structured body is flattened to its keys, render it back as JSON
+		if _, ok := fields[logBodyField]; !ok {
+			if body, ok := source[logBodyField]; ok {
+				b, _ := json.Marshal(body)
+				entry.Line = string(b)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func parseLogTimestamp(value string) time.Time {
+	if t, err := time.Parse(DATE_LAYOUT, value); err == nil {
+		return t
+	}
+	t, _ := time.Parse(time.RFC3339Nano, value)
+	return t
+}
+
+type streamBucket struct {
+	Key       interface{} `json:"key"`
+	DocCount  int64       `json:"doc_count"`
+	Histogram struct {
+		Buckets []histogramBucket `json:"buckets"`
+	} `json:"histogram"`
+}
+
+type histogramBucket struct {
+	Key      int64 `json:"key"`
+	DocCount int64 `json:"doc_count"`
+}
+
+// decodeLogSeries reads the streams aggregation, or the histogram of the documents without labels.
+func decodeLogSeries(res *client.SearchResult, labels []string) ([]*datasource.LogSeries, error) {
+	if raw, ok := res.Aggregations[histogramAggregation]; ok && raw != nil {
+		var histogram struct {
+			Buckets []histogramBucket `json:"buckets"`
+		}
+		if err := json.Unmarshal(*raw, &histogram); err != nil {
+			return nil, err
+		}
+		return []*datasource.LogSeries{{Labels: map[string]string{}, Samples: toLogSamples(histogram.Buckets)}}, nil
+	}
+
+	raw, ok := res.Aggregations[streamsAggregation]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	var streams struct {
+		Buckets []streamBucket `json:"buckets"`
+	}
+	if err := json.Unmarshal(*raw, &streams); err != nil {
+		return nil, err
+	}
+	series := make([]*datasource.LogSeries, 0, len(streams.Buckets))
+	for _, bucket := range streams.Buckets {
+		values, ok := bucket.Key.([]interface{})
+		if !ok {
+			values = []interface{}{bucket.Key}
+		}
+		s := &datasource.LogSeries{Labels: map[string]string{}, Samples: toLogSamples(bucket.Histogram.Buckets)}
+		for i, v := range values {
+			if value := fmt.Sprint(v); i < len(labels) && value != "" {
+				s.Labels[labels[i]] = value
+			}
+		}
+		series = append(series, s)
+	}
+	return series, nil
+}
+
+func toLogSamples(buckets []histogramBucket) []datasource.LogSample {
+	samples := make([]datasource.LogSample, 0, len(buckets))
+	for _, b := range buckets {
+		samples = append(samples, datasource.LogSample{Timestamp: time.UnixMilli(b.Key), Count: b.DocCount})
+	}
+	return samples
+}
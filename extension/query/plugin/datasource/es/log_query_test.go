@@ -0,0 +1,107 @@
+package es
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/pkg/client/es/client"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildLogsQuery(t *testing.T) {
+	query := &datasource.LogQueryParameters{
+		Matchers: []datasource.LabelMatcher{
+			{Name: "service.name", Type: datasource.MatchEqual, Value: "frontend"},
+			{Name: "host.name", Type: datasource.MatchNotRegexp, Value: "test-.*"},
+		},
+		LineFilters: []datasource.LineFilter{
+			{Type: datasource.MatchEqual, Value: "a*b"},
+			{Type: datasource.MatchRegexp, Value: "timeout|refused"},
+		},
+		StartTime: time.Date(2023, 4, 19, 3, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2023, 4, 19, 4, 0, 0, 0, time.UTC),
+		Limit:     50,
+		Forward:   true,
+	}
+
+	b, err := json.Marshal(buildLogsQuery(query).Map())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"query": {"bool": {
+			"filter": [
+				{"range": {"@timestamp": {"gte": "2023-04-19T03:00:00.000000000Z", "lte": "2023-04-19T04:00:00.000000000Z"}}},
+				{"term": {"Resource.service.name.keyword": {"value": "frontend"}}},
+				{"bool": {"should": [
+					{"wildcard": {"Body.keyword": {"value": "*a\\*b*"}}},
+					{"match_phrase": {"Body": {"query": "a*b"}}}
+				], "minimum_should_match": 1}},
+				{"regexp": {"Body.keyword": {"value": ".*(timeout|refused).*"}}}
+			],
+			"must_not": [{"regexp": {"Resource.host.name.keyword": {"value": "test-.*"}}}]
+		}},
+		"sort": [{"@timestamp": {"order": "asc"}}],
+		"size": 50
+	}`, string(b))
+}
+
+func TestBuildCountLogsQuery(t *testing.T) {
+	query := &datasource.LogQueryParameters{Step: time.Minute}
+	qe, err := buildCountLogsQuery(query, []string{"service.name", "host.name"})
+	require.NoError(t, err)
+	b, err := json.Marshal(qe.Map()["aggs"])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"streams": {
+		"multi_terms": {"terms": [
+			{"field": "Resource.service.name.keyword", "missing": ""},
+			{"field": "Resource.host.name.keyword", "missing": ""}
+		], "size": 1000},
+		"aggs": {"histogram": {"date_histogram": {"field": "@timestamp", "fixed_interval": "60s", "min_doc_count": 1}}}
+	}}`, string(b))
+
+	qe, err = buildCountLogsQuery(query, nil)
+	require.NoError(t, err)
+	assert.Contains(t, qe.Map()["aggs"], "histogram")
+
+	_, err = buildCountLogsQuery(&datasource.LogQueryParameters{}, nil)
+	assert.Error(t, err)
+}
+
+func TestDecodeLogEntries(t *testing.T) {
+	flat := json.RawMessage(`{"@timestamp":"2023-04-19T03:04:05.000000006Z","Body":"connection refused","SeverityText":"ERROR","Resource.service.name":"frontend","Resource.host.name":"localhost"}`)
+	nested := json.RawMessage(`{"@timestamp":"2023-04-19T03:04:06.000000000Z","Body":{"msg":"hello"},"Resource":{"service":{"name":"backend"}}}`)
+	entries, err := decodeLogEntries(&client.SearchHits{Hits: []*client.SearchHit{{Source: &flat}, {Source: &nested}}})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, time.Date(2023, 4, 19, 3, 4, 5, 6, time.UTC), entries[0].Timestamp)
+	assert.Equal(t, "connection refused", entries[0].Line)
+	assert.Equal(t, map[string]string{"service.name": "frontend", "host.name": "localhost"}, entries[0].Labels)
+
+	assert.Equal(t, `{"msg":"hello"}`, entries[1].Line)
+	assert.Equal(t, map[string]string{"service.name": "backend"}, entries[1].Labels)
+}
+
+func TestDecodeLogSeries(t *testing.T) {
+	streams := json.RawMessage(`{"buckets":[
+		{"key":["frontend",""],"doc_count":3,"histogram":{"buckets":[{"key":1681873200000,"doc_count":1},{"key":1681873260000,"doc_count":2}]}},
+		{"key":["backend","localhost"],"doc_count":1,"histogram":{"buckets":[{"key":1681873200000,"doc_count":1}]}}
+	]}`)
+	series, err := decodeLogSeries(&client.SearchResult{Aggregations: client.Aggregations{"streams": &streams}}, []string{"service.name", "host.name"})
+	require.NoError(t, err)
+	require.Len(t, series, 2)
+	assert.Equal(t, map[string]string{"service.name": "frontend"}, series[0].Labels)
+	assert.Equal(t, []datasource.LogSample{
+		{Timestamp: time.UnixMilli(1681873200000), Count: 1},
+		{Timestamp: time.UnixMilli(1681873260000), Count: 2},
+	}, series[0].Samples)
+	assert.Equal(t, map[string]string{"service.name": "backend", "host.name": "localhost"}, series[1].Labels)
+
+	histogram := json.RawMessage(`{"buckets":[{"key":1681873200000,"doc_count":4}]}`)
+	series, err = decodeLogSeries(&client.SearchResult{Aggregations: client.Aggregations{"histogram": &histogram}}, nil)
+	require.NoError(t, err)
+	require.Len(t, series, 1)
+	assert.Equal(t, int64(4), series[0].Samples[0].Count)
+}
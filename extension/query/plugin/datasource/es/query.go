@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -25,6 +26,10 @@ var (
 
 const (
 	DATE_LAYOUT = "2006-01-02T15:04:05.000000000Z"
+
+	defaultSpanSearchSize = 5000
+	// maxResultWindow is the default `index.max_result_window` of Elasticsearch.
+	maxResultWindow = 10000
 )
 
 type ElasticsearchQuery struct {
@@ -77,47 +82,59 @@ func (q *ElasticsearchQuery) GetService(ctx context.Context) ([]*v1_resource.Res
 }
 
 func (q *ElasticsearchQuery) SearchTraces(ctx context.Context, query *datasource.TraceQueryParameters) (*v1alpha1.TracesData, error) {
+	traces, err := q.FindTraces(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return datasource.DocumentsTracesConvert(traces)
+}
+
+// FindTraces returns the spans of the matching traces with a search of the trace IDs and a search of their spans.
+func (q *ElasticsearchQuery) FindTraces(ctx context.Context, query *datasource.TraceQueryParameters) (*v1_trace.TracesData, error) {
 	ids, err := q.FindTraceIds(ctx, query)
 	if err != nil {
 		return nil, err
 	}
+	if len(ids) == 0 {
+		return &v1_trace.TracesData{}, nil
+	}
 
-	traces, err := q.MultiGetTraces(ctx, ids...)
+	traces, err := q.multiGetSpans(ctx, ids...)
 	if err != nil {
 		zap.S().Errorf("failed to query traces:%v", err)
+		return nil, err
 	}
+	orderTraces(traces, ids)
 	return traces, nil
 }
 
 func (q *ElasticsearchQuery) MultiGetTraces(ctx context.Context, traceIds ...string) (*v1alpha1.TracesData, error) {
-	qe := esquery.Search()
-	boolQ := esquery.Bool()
-	boolQ.Must(Terms("TraceId", traceIds...))
-	// TODO(jian): 65536(es default limit) right?
-	qe.Query(boolQ).Size(5000)
-	res, err := q.client.DoSearch(ctx, q.SpanIndex, qe)
+	tracesData, err := q.multiGetSpans(ctx, traceIds...)
 	if err != nil {
 		return nil, err
 	}
-	tracesData, err := DocumentsResourceSpansConvert(res.Hits)
+	return datasource.DocumentsTracesConvert(tracesData)
+}
+
+// multiGetSpans returns the spans of the given traces.
+func (q *ElasticsearchQuery) multiGetSpans(ctx context.Context, traceIds ...string) (*v1_trace.TracesData, error) {
+	boolQ := esquery.Bool()
+	boolQ.Must(Terms("TraceId", traceIds...))
+	hits, err := searchTraceSpans(ctx, q.client, q.SpanIndex, boolQ, "TraceId.keyword", "SpanId.keyword")
 	if err != nil {
 		return nil, err
 	}
-
-	return datasource.DocumentsTracesConvert(tracesData)
+	return DocumentsResourceSpansConvert(hits)
 }
 
 func (q *ElasticsearchQuery) GetTrace(ctx context.Context, traceID string) (*v1_trace.TracesData, error) {
-	qe := esquery.Search()
 	boolQ := esquery.Bool()
 	boolQ.Must(esquery.Term("TraceId", traceID))
-	qe.Query(boolQ)
-
-	res, err := q.client.DoSearch(ctx, q.SpanIndex, qe)
+	hits, err := searchTraceSpans(ctx, q.client, q.SpanIndex, boolQ, "TraceId.keyword", "SpanId.keyword")
 	if err != nil {
 		return nil, err
 	}
-	return DocumentsResourceSpansConvert(res.Hits)
+	return DocumentsResourceSpansConvert(hits)
 }
 
 func (q *ElasticsearchQuery) SearchLogs(ctx context.Context) (*v1_logs.LogsData, error) {
@@ -213,50 +230,90 @@ func buildTraceIdsQuery(params *datasource.TraceQueryParameters) (*esquery.Searc
 		return nil, err
 	}
 
-	aggs := esquery.TermsAgg("traceIDs", "TraceId.keyword").Order(map[string]string{"_count": "desc"})
+	// newest traces first
+	aggs := esquery.TermsAgg("traceIDs", "TraceId.keyword").
+		Order(map[string]string{"startTime": "desc"}).
+		Aggs(esquery.Max("startTime", "@timestamp"))
 	if params.NumTraces > 0 {
 		aggs.Size(uint64(params.NumTraces))
 	} else {
 		aggs.Size(uint64(20))
 	}
 
-	return q.Aggs(aggs), nil
+	return q.Aggs(aggs).Size(0), nil
 }
 
-// Build the request body.
-func buildTraceQuery(params *datasource.TraceQueryParameters) (*esquery.SearchRequest, error) {
-	// boolean search query
-	q := esquery.Search()
-	boolQ := esquery.Bool()
-	if params.ServiceName != "" {
-		boolQ.Must(esquery.Term("Resource.service.name.keyword", params.ServiceName))
+// orderTraces sorts the resource spans in the order of the trace IDs, the search of their spans
+// returns them in no particular order.
+func orderTraces(traces *v1_trace.TracesData, ids []string) {
+	rank := make(map[string]int, len(ids))
+	for i, id := range ids {
+		rank[id] = i
 	}
-	if params.OperationName != "" {
-		boolQ.Must(esquery.Term("Name.keyword", params.OperationName))
+	traceRank := func(rs *v1_trace.ResourceSpans) int {
+		for _, ss := range rs.GetScopeSpans() {
+			for _, span := range ss.GetSpans() {
+				if r, ok := rank[string(span.TraceId)]; ok {
+					return r
+				}
+			}
+		}
+		return len(ids)
 	}
+	sort.SliceStable(traces.ResourceSpans, func(i, j int) bool {
+		return traceRank(traces.ResourceSpans[i]) < traceRank(traces.ResourceSpans[j])
+	})
+}
 
-	if !params.StartTime.IsZero() && params.EndTime.IsZero() {
-		boolQ.Must(esquery.Range("@timestamp").Gte(params.StartTime.Format(DATE_LAYOUT)))
+// searchTraceSpans returns the span documents matching query, paging through them with search_after
+// sorted by traceField and spanField. It returns ErrTooManySpans when a single trace has more spans
+// than the span limit of ctx, or than defaultSpanSearchSize when no limit is set.
+func searchTraceSpans(ctx context.Context, c *client.Elastic, index string, query esquery.Mappable, traceField, spanField string) (*client.SearchHits, error) {
+	limit := datasource.SpanLimit(ctx)
+	if limit <= 0 {
+		limit = defaultSpanSearchSize
 	}
-
-	if params.StartTime.IsZero() && !params.EndTime.IsZero() {
-		boolQ.Must(esquery.Range("@timestamp").Lte(params.EndTime.Format(DATE_LAYOUT)))
+	hits := &client.SearchHits{}
+	spans := make(map[string]int)
+	var after []interface{}
+	for {
+		qe := esquery.Search().Query(query).Size(maxResultWindow).
+			Sort(traceField, esquery.OrderAsc).
+			Sort(spanField, esquery.OrderAsc)
+		if after != nil {
+			qe.SearchAfter(after...)
+		}
+		res, err := c.DoSearch(ctx, index, qe)
+		if err != nil {
+			return nil, err
+		}
+		if res.Hits == nil || len(res.Hits.Hits) == 0 {
+			return hits, nil
+		}
+		for _, hit := range res.Hits.Hits {
+			if len(hit.Sort) == 0 {
+				return nil, fmt.Errorf("span %s has no sort values", hit.Id)
+			}
+			traceID := fmt.Sprint(hit.Sort[0])
+			if spans[traceID]++; spans[traceID] > limit {
+				return nil, fmt.Errorf("%w: trace %s has more than %d spans", datasource.ErrTooManySpans, traceID, limit)
+			}
+		}
+		hits.Hits = append(hits.Hits, res.Hits.Hits...)
+		if len(res.Hits.Hits) < maxResultWindow {
+			return hits, nil
+		}
+		after = res.Hits.Hits[len(res.Hits.Hits)-1].Sort
 	}
+}
 
-	if !params.StartTime.IsZero() && !params.EndTime.IsZero() && params.StartTime.Before(params.EndTime) {
-		boolQ.Must(esquery.Range("@timestamp").Gte(params.StartTime.Format(DATE_LAYOUT)).Lte(params.EndTime.Format(DATE_LAYOUT)))
-	} else {
+// buildTraceQuery builds the search of the spans matching params, the time range is required.
+func buildTraceQuery(params *datasource.TraceQueryParameters) (*esquery.SearchRequest, error) {
+	q := esquery.Search()
+	if params.StartTime.IsZero() || params.EndTime.IsZero() || !params.StartTime.Before(params.EndTime) {
 		return q, errParsTime
 	}
-
-	if len(params.Tags) > 0 {
-		for k, v := range params.Tags {
-			boolQ.Must(esquery.Term(k, v))
-		}
-	}
-
-	q.Query(boolQ)
-	return q, nil
+	return q.Query(buildSpanCondition(params.SpanQuery())), nil
 }
 
 func DecodeSearchResult(jsonRaw json.RawMessage) (map[string]interface{}, error) {
@@ -279,19 +336,34 @@ func DocumentsResourceSpansConvert(searchHits *client.SearchHits) (*v1_trace.Tra
 		if err != nil {
 			return nil, err
 		}
+		// documents written with `dedot` enabled are nested objects, flatten them
+		// so that both layouts are decoded the same way.
+		fields := make(map[string]interface{}, len(rSpansMaps))
+		flattenFields(fields, "", rSpansMaps)
+
+		keys := make([]string, 0, len(fields))
+		for k := range fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
 
-		span := v1_trace.Span{}
+		span := v1_trace.Span{Status: &v1_trace.Status{}}
 		resource := v1_resource.Resource{}
+		scope := v1_common.InstrumentationScope{}
 		var sAttributes []*v1_common.KeyValue
 		var rAttributes []*v1_common.KeyValue
-		for k, v := range rSpansMaps {
+		for _, k := range keys {
+			v := fields[k]
 			switch k {
 			case "TraceId":
 				span.TraceId = []byte(v.(string))
+			case "TraceState":
+				span.TraceState = fmt.Sprint(v)
 			case "TraceStatus":
 				statusCode, _ := v.(json.Number).Int64()
-				span.Status = &v1_trace.Status{Code: v1_trace.Status_StatusCode(statusCode)}
-				span.TraceState = v1_trace.Status_StatusCode_name[int32(statusCode)]
+				span.Status.Code = v1_trace.Status_StatusCode(statusCode)
+			case "TraceStatusDescription":
+				span.Status.Message = fmt.Sprint(v)
 			case "Name":
 				span.Name = v.(string)
 			case "EndTimestamp":
@@ -312,30 +384,46 @@ func DocumentsResourceSpansConvert(searchHits *client.SearchHits) (*v1_trace.Tra
 				span.SpanId = []byte(v.(string))
 			case "Kind":
 				span.Kind = v1_trace.Span_SpanKind(v1_trace.Span_SpanKind_value[v.(string)])
+			case "Link":
+				links, err := decodeLinks(fmt.Sprint(v))
+				if err != nil {
+					zap.S().Errorf("failed to decode span links %s", err)
+				}
+				span.Links = links
+			case "Scope.name":
+				scope.Name = fmt.Sprint(v)
+			case "Scope.version":
+				scope.Version = fmt.Sprint(v)
 			}
 
-			if strings.Contains(k, "Attributes.") {
+			switch {
+			case strings.HasPrefix(k, "Attributes."):
 				sAttributes = append(sAttributes, &v1_common.KeyValue{
 					Key:   strings.TrimPrefix(k, "Attributes."),
-					Value: &v1_common.AnyValue{Value: &v1_common.AnyValue_StringValue{StringValue: fmt.Sprint(v)}},
+					Value: toAnyValue(v),
 				})
-			}
-
-			if strings.Contains(k, "Resource.") {
+			case strings.HasPrefix(k, "Resource."):
 				rAttributes = append(rAttributes, &v1_common.KeyValue{
 					Key:   strings.TrimPrefix(k, "Resource."),
-					Value: &v1_common.AnyValue{Value: &v1_common.AnyValue_StringValue{StringValue: fmt.Sprint(v)}},
+					Value: toAnyValue(v),
+				})
+			case strings.HasPrefix(k, "Scope.Attributes."):
+				scope.Attributes = append(scope.Attributes, &v1_common.KeyValue{
+					Key:   strings.TrimPrefix(k, "Scope.Attributes."),
+					Value: toAnyValue(v),
 				})
 			}
 		}
 
 		span.Attributes = sAttributes
+		span.Events = decodeEvents(fields)
 		resource.Attributes = rAttributes
 
 		rSpans[i] = &v1_trace.ResourceSpans{
 			Resource: &resource,
 			ScopeSpans: []*v1_trace.ScopeSpans{
 				{
+					Scope: &scope,
 					Spans: []*v1_trace.Span{&span},
 				},
 			},
@@ -344,6 +432,149 @@ func DocumentsResourceSpansConvert(searchHits *client.SearchHits) (*v1_trace.Tra
 	return &v1_trace.TracesData{ResourceSpans: rSpans}, nil
 }
 
+// flattenFields joins nested objects of a document source into dotted keys.
+func flattenFields(dst map[string]interface{}, prefix string, src map[string]interface{}) {
+	for k, v := range src {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if m, ok := v.(map[string]interface{}); ok {
+			flattenFields(dst, key, m)
+			continue
+		}
+		dst[key] = v
+	}
+}
+
+// decodeEvents rebuilds span events from the `Events.<name>.time` and
+// `Events.<name>.<attribute>` fields written by the exporter.
+func decodeEvents(fields map[string]interface{}) []*v1_trace.Span_Event {
+	events := map[string]*v1_trace.Span_Event{}
+	var names []string
+	for k, v := range fields {
+		if !strings.HasPrefix(k, "Events.") || !strings.HasSuffix(k, ".time") {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(k, "Events."), ".time")
+		event := &v1_trace.Span_Event{Name: name}
+		if t, err := time.Parse(DATE_LAYOUT, fmt.Sprint(v)); err == nil {
+			event.TimeUnixNano = uint64(t.UnixNano())
+		}
+		events[name] = event
+		names = append(names, name)
+	}
+	if len(events) == 0 {
+		return nil
+	}
+
+	// match the longest event name first, event names and attribute keys may both contain dots.
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+	attrKeys := make([]string, 0, len(fields))
+	for k := range fields {
+		if strings.HasPrefix(k, "Events.") && !strings.HasSuffix(k, ".time") {
+			attrKeys = append(attrKeys, k)
+		}
+	}
+	sort.Strings(attrKeys)
+	for _, k := range attrKeys {
+		rest := strings.TrimPrefix(k, "Events.")
+		for _, name := range names {
+			if strings.HasPrefix(rest, name+".") {
+				events[name].Attributes = append(events[name].Attributes, &v1_common.KeyValue{
+					Key:   strings.TrimPrefix(rest, name+"."),
+					Value: toAnyValue(fields[k]),
+				})
+				break
+			}
+		}
+	}
+
+	result := make([]*v1_trace.Span_Event, 0, len(events))
+	for _, event := range events {
+		result = append(result, event)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].TimeUnixNano == result[j].TimeUnixNano {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].TimeUnixNano < result[j].TimeUnixNano
+	})
+	return result
+}
+
+// decodeLinks parses the JSON encoded `Link` field written by the exporter.
+func decodeLinks(raw string) ([]*v1_trace.Span_Link, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var docs []map[string]interface{}
+	d := json.NewDecoder(strings.NewReader(raw))
+	d.UseNumber()
+	if err := d.Decode(&docs); err != nil {
+		return nil, err
+	}
+
+	var links []*v1_trace.Span_Link
+	for _, doc := range docs {
+		link := &v1_trace.Span_Link{}
+		if traceID, ok := doc["traceID"].(string); ok {
+			link.TraceId = []byte(traceID)
+		}
+		if spanID, ok := doc["spanID"].(string); ok {
+			link.SpanId = []byte(spanID)
+		}
+		if traceState, ok := doc["traceState"].(string); ok {
+			link.TraceState = traceState
+		}
+		if attributes, ok := doc["attribute"].(map[string]interface{}); ok {
+			link.Attributes = toKeyValues(attributes)
+		}
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+func toKeyValues(m map[string]interface{}) []*v1_common.KeyValue {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	kvs := make([]*v1_common.KeyValue, 0, len(m))
+	for _, k := range keys {
+		kvs = append(kvs, &v1_common.KeyValue{Key: k, Value: toAnyValue(m[k])})
+	}
+	return kvs
+}
+
+// toAnyValue converts a decoded JSON value into an OTLP AnyValue, keeping its type.
+func toAnyValue(v interface{}) *v1_common.AnyValue {
+	switch val := v.(type) {
+	case string:
+		return &v1_common.AnyValue{Value: &v1_common.AnyValue_StringValue{StringValue: val}}
+	case bool:
+		return &v1_common.AnyValue{Value: &v1_common.AnyValue_BoolValue{BoolValue: val}}
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return &v1_common.AnyValue{Value: &v1_common.AnyValue_IntValue{IntValue: i}}
+		}
+		f, _ := val.Float64()
+		return &v1_common.AnyValue{Value: &v1_common.AnyValue_DoubleValue{DoubleValue: f}}
+	case []interface{}:
+		values := make([]*v1_common.AnyValue, 0, len(val))
+		for _, item := range val {
+			values = append(values, toAnyValue(item))
+		}
+		return &v1_common.AnyValue{Value: &v1_common.AnyValue_ArrayValue{ArrayValue: &v1_common.ArrayValue{Values: values}}}
+	case map[string]interface{}:
+		return &v1_common.AnyValue{Value: &v1_common.AnyValue_KvlistValue{KvlistValue: &v1_common.KeyValueList{Values: toKeyValues(val)}}}
+	default:
+		return &v1_common.AnyValue{Value: &v1_common.AnyValue_StringValue{StringValue: fmt.Sprint(v)}}
+	}
+}
+
 type TermsQuery struct {
 	field  string
 	values []string
@@ -1,13 +1,21 @@
 package es
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/aquasecurity/esquery"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/pkg/client/es/client"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	v1_trace "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
 func mockSearchHits() *client.SearchHits {
@@ -43,3 +51,128 @@ func TestDocumentsTracesConvert(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "HTTP GET", traces.Traces[0].OperationName)
 }
+
+func TestDocumentsConvertEventsLinksAndScope(t *testing.T) {
+	source := json.RawMessage(`{"@timestamp":"2023-04-19T03:04:05.000000006Z","Attributes.service.instance.id":"23","EndTimestamp":"2023-04-19T03:04:06.000000006Z","Events.fooEvent.evnetMockBar":"bar","Events.fooEvent.evnetMockFoo":"foo","Events.fooEvent.time":"2023-04-19T03:04:05.000000006Z","Events.db.query.rows":3,"Events.db.query.time":"2023-04-19T03:04:05.500000000Z","Kind":"SPAN_KIND_CLIENT","Link":"[{\"attribute\":{\"peer\":\"a\"},\"spanID\":\"1920212223242527\",\"traceID\":\"01020304050607080807060504030200\",\"traceState\":\"congo=t61rcWkgMzE\"}]","Name":"client span","Resource.service.name":"some-service","Scope.Attributes.name":"driver","Scope.name":"io.opentelemetry.contrib.mongodb","Scope.version":"1.0.0","SpanId":"1920212223242526","TraceId":"01020304050607080807060504030201","TraceState":"rojo=00f067aa0ba902b7","TraceStatus":2,"TraceStatusDescription":"connection refused"}`)
+	tracesData, err := DocumentsResourceSpansConvert(&client.SearchHits{Hits: []*client.SearchHit{{Source: &source}}})
+	require.NoError(t, err)
+	require.Len(t, tracesData.ResourceSpans, 1)
+
+	scopeSpans := tracesData.ResourceSpans[0].ScopeSpans[0]
+	assert.Equal(t, "io.opentelemetry.contrib.mongodb", scopeSpans.Scope.Name)
+	assert.Equal(t, "1.0.0", scopeSpans.Scope.Version)
+	require.Len(t, scopeSpans.Scope.Attributes, 1)
+	assert.Equal(t, "name", scopeSpans.Scope.Attributes[0].Key)
+	assert.Equal(t, "driver", scopeSpans.Scope.Attributes[0].Value.GetStringValue())
+
+	span := scopeSpans.Spans[0]
+	assert.Equal(t, "rojo=00f067aa0ba902b7", span.TraceState)
+	assert.Equal(t, v1_trace.Status_STATUS_CODE_ERROR, span.Status.Code)
+	assert.Equal(t, "connection refused", span.Status.Message)
+
+	require.Len(t, span.Events, 2)
+	assert.Equal(t, "fooEvent", span.Events[0].Name)
+	assert.Len(t, span.Events[0].Attributes, 2)
+	assert.Equal(t, "db.query", span.Events[1].Name)
+	require.Len(t, span.Events[1].Attributes, 1)
+	assert.Equal(t, "rows", span.Events[1].Attributes[0].Key)
+	assert.Equal(t, int64(3), span.Events[1].Attributes[0].Value.GetIntValue())
+
+	require.Len(t, span.Links, 1)
+	assert.Equal(t, "01020304050607080807060504030200", string(span.Links[0].TraceId))
+	assert.Equal(t, "1920212223242527", string(span.Links[0].SpanId))
+	assert.Equal(t, "congo=t61rcWkgMzE", span.Links[0].TraceState)
+	assert.Equal(t, "peer", span.Links[0].Attributes[0].Key)
+}
+
+func TestDocumentsConvertDedotted(t *testing.T) {
+	source := json.RawMessage(`{"@timestamp":"2023-04-19T03:04:05.000000006Z","Attributes":{"http":{"status_code":200}},"Events":{"fooEvent":{"time":"2023-04-19T03:04:05.000000006Z","foo":"bar"}},"Name":"client span","Resource":{"service":{"name":"some-service"}},"Scope":{"name":"io.opentelemetry.contrib.mongodb"},"SpanId":"1920212223242526","TraceId":"01020304050607080807060504030201","TraceStatus":0}`)
+	tracesData, err := DocumentsResourceSpansConvert(&client.SearchHits{Hits: []*client.SearchHit{{Source: &source}}})
+	require.NoError(t, err)
+
+	rs := tracesData.ResourceSpans[0]
+	assert.Equal(t, "service.name", rs.Resource.Attributes[0].Key)
+	assert.Equal(t, "some-service", rs.Resource.Attributes[0].Value.GetStringValue())
+	assert.Equal(t, "io.opentelemetry.contrib.mongodb", rs.ScopeSpans[0].Scope.Name)
+
+	span := rs.ScopeSpans[0].Spans[0]
+	assert.Equal(t, "http.status_code", span.Attributes[0].Key)
+	assert.Equal(t, int64(200), span.Attributes[0].Value.GetIntValue())
+	require.Len(t, span.Events, 1)
+	assert.Equal(t, "foo", span.Events[0].Attributes[0].Key)
+}
+
+func TestSearchTraceSpans(t *testing.T) {
+	var requests []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/spans/_search" {
+			_, _ = w.Write([]byte(`{"version":{"number":"7.17.0"},"tagline":"You Know, for Search"}`))
+			return
+		}
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requests = append(requests, body)
+		n := 5
+		if len(requests) == 1 {
+			n = maxResultWindow
+		}
+		hits := make([]map[string]interface{}, n)
+		for i := range hits {
+			traceID := fmt.Sprintf("trace-%d", (i*3)/n)
+			spanID := fmt.Sprintf("%d-%05d", len(requests), i)
+			hits[i] = map[string]interface{}{
+				"_id":     spanID,
+				"_source": map[string]interface{}{"TraceId": traceID, "SpanId": spanID},
+				"sort":    []interface{}{traceID, spanID},
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"hits": map[string]interface{}{"hits": hits}})
+	}))
+	defer server.Close()
+	c, err := client.New([]string{server.URL}, "", "")
+	require.NoError(t, err)
+
+	// the traces together have more spans than the result window, none is over the default limit
+	hits, err := searchTraceSpans(context.Background(), c, "spans", esquery.Terms("TraceId", "trace-0", "trace-1", "trace-2"), "TraceId.keyword", "SpanId.keyword")
+	require.NoError(t, err)
+	assert.Len(t, hits.Hits, maxResultWindow+5)
+	require.Len(t, requests, 2)
+	assert.Nil(t, requests[0]["search_after"])
+	assert.Equal(t, []interface{}{"trace-2", fmt.Sprintf("1-%05d", maxResultWindow-1)}, requests[1]["search_after"])
+
+	// a single trace over the span limit fails the search
+	requests = nil
+	ctx := datasource.ContextWithSpanLimit(context.Background(), 3000)
+	_, err = searchTraceSpans(ctx, c, "spans", esquery.Terms("TraceId", "trace-0"), "TraceId.keyword", "SpanId.keyword")
+	assert.ErrorIs(t, err, datasource.ErrTooManySpans)
+}
+
+func TestBuildTraceIdsQuery(t *testing.T) {
+	start := time.Date(2023, 4, 19, 3, 0, 0, 0, time.UTC)
+	params := &datasource.TraceQueryParameters{
+		ServiceName: "frontend",
+		Tags:        map[string]string{"http.status_code": "500"},
+		TagKeys:     []string{"error"},
+		StartTime:   start,
+		EndTime:     start.Add(time.Hour),
+		DurationMax: durationpb.New(1500 * time.Microsecond),
+		NumTraces:   10,
+	}
+	q, err := buildTraceIdsQuery(params)
+	require.NoError(t, err)
+	body, err := json.Marshal(q.Map())
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `{"term":{"Resource.service.name.keyword":{"value":"frontend"}}}`)
+	assert.Contains(t, string(body), `{"range":{"@timestamp":{"gte":"2023-04-19T03:00:00.000000000Z","lte":"2023-04-19T04:00:00.000000000Z"}}}`)
+	assert.Contains(t, string(body), `"params":{"max":2,"min":0}`)
+	assert.Contains(t, string(body), `{"term":{"Attributes.http.status_code":{"value":"500"}}}`)
+	assert.Contains(t, string(body), `{"exists":{"field":"Attributes.error"}}`)
+	assert.Contains(t, string(body), `"terms":{"field":"TraceId.keyword","order":{"startTime":"desc"},"size":10}`)
+	assert.Contains(t, string(body), `"size":0`)
+
+	params.EndTime = time.Time{}
+	_, err = buildTraceIdsQuery(params)
+	assert.ErrorIs(t, err, errParsTime)
+}
@@ -0,0 +1,141 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/aquasecurity/esquery"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/api/v1alpha1"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/pkg/client/es/client"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+)
+
+// DefaultSavedQueriesIndex is the index of the saved queries when none is configured.
+const DefaultSavedQueriesIndex = "otel-saved-queries"
+
+// savedQueriesMappings indexes the fields the saved queries are listed by, the saved query
+// itself is kept as a JSON string.
+var savedQueriesMappings = map[string]interface{}{
+	"dynamic": false,
+	"properties": map[string]interface{}{
+		"id":       map[string]interface{}{"type": "keyword"},
+		"name":     map[string]interface{}{"type": "keyword"},
+		"owner":    map[string]interface{}{"type": "keyword"},
+		"tags":     map[string]interface{}{"type": "keyword"},
+		"document": map[string]interface{}{"type": "keyword", "index": false, "doc_values": false},
+	},
+}
+
+var _ datasource.SavedQueryStore = (*ElasticsearchSavedQueryStore)(nil)
+
+// ElasticsearchSavedQueryStore keeps a document per saved query, with the saved query ID as
+// document ID.
+type ElasticsearchSavedQueryStore struct {
+	client *client.Elastic
+	Index  string
+}
+
+// savedQueryDocument is the document of a saved query.
+type savedQueryDocument struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Owner    string   `json:"owner"`
+	Tags     []string `json:"tags"`
+	Document string   `json:"document"`
+}
+
+func newSavedQueryDocument(query *v1alpha1.SavedQuery) (*savedQueryDocument, error) {
+	b, err := datasource.MarshalSavedQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	return &savedQueryDocument{
+		ID:       query.Id,
+		Name:     query.Name,
+		Owner:    query.Owner,
+		Tags:     query.Tags,
+		Document: string(b),
+	}, nil
+}
+
+func (s *ElasticsearchSavedQueryStore) CreateSavedQuery(ctx context.Context, query *v1alpha1.SavedQuery) error {
+	doc, err := newSavedQueryDocument(query)
+	if err != nil {
+		return err
+	}
+	err = s.client.CreateDocument(ctx, s.Index, query.Id, doc)
+	if errors.Is(err, client.ErrConflict) {
+		return datasource.ErrSavedQueryExists
+	}
+	return err
+}
+
+func (s *ElasticsearchSavedQueryStore) GetSavedQuery(ctx context.Context, id string) (*v1alpha1.SavedQuery, error) {
+	doc := &savedQueryDocument{}
+	if err := s.client.GetDocument(ctx, s.Index, id, doc); err != nil {
+		if errors.Is(err, client.ErrNotFound) {
+			return nil, datasource.ErrSavedQueryNotFound
+		}
+		return nil, err
+	}
+	return datasource.UnmarshalSavedQuery([]byte(doc.Document))
+}
+
+func (s *ElasticsearchSavedQueryStore) ListSavedQueries(ctx context.Context, filter *datasource.SavedQueryFilter) ([]*v1alpha1.SavedQuery, error) {
+	res, err := s.client.DoSearch(ctx, s.Index, buildSavedQueriesQuery(filter))
+	if err != nil {
+		return nil, err
+	}
+	var queries []*v1alpha1.SavedQuery
+	if res.Hits == nil {
+		return queries, nil
+	}
+	for _, hit := range res.Hits.Hits {
+		if hit.Source == nil {
+			continue
+		}
+		doc := &savedQueryDocument{}
+		if err = json.Unmarshal(*hit.Source, doc); err != nil {
+			return nil, err
+		}
+		query, err := datasource.UnmarshalSavedQuery([]byte(doc.Document))
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, query)
+	}
+	datasource.SortSavedQueries(queries)
+	return queries, nil
+}
+
+func buildSavedQueriesQuery(filter *datasource.SavedQueryFilter) *esquery.SearchRequest {
+	boolQ := esquery.Bool().Must(esquery.MatchAll())
+	if filter != nil && filter.Owner != "" {
+		boolQ.Filter(esquery.Term("owner", filter.Owner))
+	}
+	if filter != nil && filter.Tag != "" {
+		boolQ.Filter(esquery.Term("tags", filter.Tag))
+	}
+	return esquery.Search().Query(boolQ).Size(maxResultWindow)
+}
+
+func (s *ElasticsearchSavedQueryStore) UpdateSavedQuery(ctx context.Context, query *v1alpha1.SavedQuery) error {
+	doc, err := newSavedQueryDocument(query)
+	if err != nil {
+		return err
+	}
+	err = s.client.UpdateDocument(ctx, s.Index, query.Id, doc)
+	if errors.Is(err, client.ErrNotFound) {
+		return datasource.ErrSavedQueryNotFound
+	}
+	return err
+}
+
+func (s *ElasticsearchSavedQueryStore) DeleteSavedQuery(ctx context.Context, id string) error {
+	err := s.client.DeleteDocument(ctx, s.Index, id)
+	if errors.Is(err, client.ErrNotFound) {
+		return datasource.ErrSavedQueryNotFound
+	}
+	return err
+}
@@ -0,0 +1,200 @@
+package es
+
+import (
+	"context"
+	"time"
+
+	"github.com/aquasecurity/esquery"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	v1_trace "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// spanDurationScript computes the duration of a span document in milliseconds, the OTLP documents
+// only have its start and end timestamps.
+const spanDurationScript = "doc['EndTimestamp'].value.toInstant().toEpochMilli() - doc['@timestamp'].value.toInstant().toEpochMilli()"
+
+// spanSortFields are the fields of the OTLP documents ordering a span search.
+var spanSortFields = map[datasource.SpanSortField]string{
+	datasource.SpanSortByStartTime:   "@timestamp",
+	datasource.SpanSortByServiceName: "Resource.service.name.keyword",
+	datasource.SpanSortByName:        "Name.keyword",
+}
+
+// jaegerSpanSortFields are the fields of the Jaeger documents ordering a span search.
+var jaegerSpanSortFields = map[datasource.SpanSortField]string{
+	datasource.SpanSortByStartTime:   "startTime",
+	datasource.SpanSortByDuration:    "duration",
+	datasource.SpanSortByServiceName: "process.serviceName",
+	datasource.SpanSortByName:        "operationName",
+}
+
+func (q *ElasticsearchQuery) SearchSpans(ctx context.Context, query *datasource.SpanQueryParameters) (*v1_trace.TracesData, error) {
+	res, err := q.client.DoSearchBody(ctx, q.SpanIndex, buildSpanSearch(query))
+	if err != nil {
+		return nil, err
+	}
+	return DocumentsResourceSpansConvert(res.Hits)
+}
+
+// buildSpanSearch builds the body of a span search, sorting by duration needs a script sort which
+// esquery doesn't support.
+func buildSpanSearch(params *datasource.SpanQueryParameters) map[string]interface{} {
+	body := esquery.Search().Query(buildSpanCondition(params)).From(uint64(params.Offset)).Size(uint64(params.Limit)).Map()
+	order := esquery.OrderDesc
+	if params.Ascending {
+		order = esquery.OrderAsc
+	}
+	if params.SortBy == datasource.SpanSortByDuration {
+		body["sort"] = []interface{}{map[string]interface{}{
+			"_script": map[string]interface{}{
+				"type":   "number",
+				"script": map[string]interface{}{"source": spanDurationScript},
+				"order":  order,
+			},
+		}}
+	} else {
+		field, ok := spanSortFields[params.SortBy]
+		if !ok {
+			field = spanSortFields[datasource.SpanSortByStartTime]
+		}
+		body["sort"] = []interface{}{map[string]interface{}{field: map[string]interface{}{"order": order}}}
+	}
+	return body
+}
+
+// buildSpanCondition builds the filters of a span search.
+func buildSpanCondition(params *datasource.SpanQueryParameters) *esquery.BoolQuery {
+	boolQ := esquery.Bool().Must(esquery.MatchAll())
+	if params.ServiceName != "" {
+		boolQ.Filter(esquery.Term("Resource.service.name.keyword", params.ServiceName))
+	}
+	if params.OperationName != "" {
+		boolQ.Filter(esquery.Term("Name.keyword", params.OperationName))
+	}
+	if params.SpanKind != "" {
+		boolQ.Filter(esquery.Term("Kind.keyword", params.SpanKind))
+	}
+	if params.StatusCode != "" {
+		boolQ.Filter(esquery.Term("TraceStatus", v1_trace.Status_StatusCode_value[params.StatusCode]))
+	}
+	if !params.StartTime.IsZero() || !params.EndTime.IsZero() {
+		timeRange := esquery.Range("@timestamp")
+		if !params.StartTime.IsZero() {
+			timeRange.Gte(params.StartTime.UTC().Format(DATE_LAYOUT))
+		}
+		if !params.EndTime.IsZero() {
+			timeRange.Lte(params.EndTime.UTC().Format(DATE_LAYOUT))
+		}
+		boolQ.Filter(timeRange)
+	}
+	if params.DurationMin > 0 || params.DurationMax > 0 {
+		source := "long d = " + spanDurationScript + "; return d >= params.min && (params.max < 0 || d <= params.max);"
+		max := int64(-1)
+		if params.DurationMax > 0 {
+			// rounded up, the script truncates the timestamps to milliseconds
+			max = (params.DurationMax + time.Millisecond - 1).Milliseconds()
+		}
+		boolQ.Filter(esquery.CustomQuery(map[string]interface{}{
+			"script": map[string]interface{}{
+				"script": map[string]interface{}{
+					"source": source,
+					"params": map[string]interface{}{"min": params.DurationMin.Milliseconds(), "max": max},
+				},
+			},
+		}))
+	}
+	// the string attributes are matched on their keyword sub-field
+	for k, v := range params.Tags {
+		boolQ.Filter(esquery.Bool().Should(
+			esquery.Term("Attributes."+k, v),
+			esquery.Term("Attributes."+k+".keyword", v),
+			esquery.Term("Resource."+k, v),
+			esquery.Term("Resource."+k+".keyword", v),
+		).MinimumShouldMatch(1))
+	}
+	for _, k := range params.TagKeys {
+		boolQ.Filter(esquery.Bool().Should(
+			esquery.Exists("Attributes."+k),
+			esquery.Exists("Resource."+k),
+		).MinimumShouldMatch(1))
+	}
+	return boolQ
+}
+
+func (q *JaegerElasticsearchQuery) SearchSpans(ctx context.Context, query *datasource.SpanQueryParameters) (*v1_trace.TracesData, error) {
+	res, err := q.client.DoSearch(ctx, q.SpanIndex, buildJaegerSpanSearch(query))
+	if err != nil {
+		return nil, err
+	}
+	return JaegerDocumentsResourceSpansConvert(res.Hits)
+}
+
+func buildJaegerSpanSearch(params *datasource.SpanQueryParameters) *esquery.SearchRequest {
+	field, ok := jaegerSpanSortFields[params.SortBy]
+	if !ok {
+		field = jaegerSpanSortFields[datasource.SpanSortByStartTime]
+	}
+	order := esquery.OrderDesc
+	if params.Ascending {
+		order = esquery.OrderAsc
+	}
+	return esquery.Search().Query(buildJaegerSpanCondition(params)).Sort(field, order).From(uint64(params.Offset)).Size(uint64(params.Limit))
+}
+
+// buildJaegerSpanCondition builds the filters of a span search over the Jaeger documents.
+func buildJaegerSpanCondition(params *datasource.SpanQueryParameters) *esquery.BoolQuery {
+	boolQ := esquery.Bool().Must(esquery.MatchAll())
+	if params.ServiceName != "" {
+		boolQ.Filter(esquery.Term("process.serviceName", params.ServiceName))
+	}
+	if params.OperationName != "" {
+		boolQ.Filter(esquery.Term("operationName", params.OperationName))
+	}
+	if params.SpanKind != "" {
+		boolQ.Filter(jaegerNestedTagQuery("tags", "span.kind", jaegerSpanKindTag(params.SpanKind)))
+	}
+	// the OTLP status is translated to the `otel.status_code` tag, and the `error` tag for errors
+	switch params.StatusCode {
+	case v1_trace.Status_STATUS_CODE_ERROR.String():
+		boolQ.Filter(esquery.Bool().Should(
+			jaegerNestedTagQuery("tags", "otel.status_code", "ERROR"),
+			jaegerNestedTagQuery("tags", "error", "true"),
+		).MinimumShouldMatch(1))
+	case v1_trace.Status_STATUS_CODE_OK.String():
+		boolQ.Filter(jaegerNestedTagQuery("tags", "otel.status_code", "OK"))
+	case v1_trace.Status_STATUS_CODE_UNSET.String():
+		boolQ.MustNot(
+			jaegerNestedTagQuery("tags", "otel.status_code", "ERROR"),
+			jaegerNestedTagQuery("tags", "otel.status_code", "OK"),
+			jaegerNestedTagQuery("tags", "error", "true"),
+		)
+	}
+	if !params.StartTime.IsZero() || !params.EndTime.IsZero() {
+		timeRange := esquery.Range("startTimeMillis")
+		if !params.StartTime.IsZero() {
+			timeRange.Gte(params.StartTime.UnixMilli())
+		}
+		if !params.EndTime.IsZero() {
+			timeRange.Lte(params.EndTime.UnixMilli())
+		}
+		boolQ.Filter(timeRange)
+	}
+	// duration is stored in microseconds.
+	if params.DurationMin > 0 || params.DurationMax > 0 {
+		durationRange := esquery.Range("duration")
+		if params.DurationMin > 0 {
+			durationRange.Gte(params.DurationMin.Microseconds())
+		}
+		if params.DurationMax > 0 {
+			durationRange.Lte(params.DurationMax.Microseconds())
+		}
+		boolQ.Filter(durationRange)
+	}
+	for k, v := range params.Tags {
+		boolQ.Filter(jaegerTagQuery(k, v))
+	}
+	for _, k := range params.TagKeys {
+		boolQ.Filter(jaegerTagExistsQuery(k))
+	}
+	return boolQ
+}
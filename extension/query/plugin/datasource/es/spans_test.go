@@ -0,0 +1,81 @@
+package es
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aquasecurity/esquery"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildSpanSearch(t *testing.T) {
+	body := buildSpanSearch(&datasource.SpanQueryParameters{
+		ServiceName: "frontend",
+		StatusCode:  "STATUS_CODE_ERROR",
+		Tags:        map[string]string{"db.system": "mysql"},
+		StartTime:   time.Date(2023, 4, 19, 3, 0, 0, 0, time.UTC),
+		DurationMin: time.Second,
+		SortBy:      datasource.SpanSortByDuration,
+		Offset:      20,
+		Limit:       10,
+	})
+	b, err := json.Marshal(body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"query": {"bool": {
+			"must": [{"match_all": {}}],
+			"filter": [
+				{"term": {"Resource.service.name.keyword": {"value": "frontend"}}},
+				{"term": {"TraceStatus": {"value": 2}}},
+				{"range": {"@timestamp": {"gte": "2023-04-19T03:00:00.000000000Z"}}},
+				{"script": {"script": {
+					"source": "long d = `+spanDurationScript+`; return d >= params.min && (params.max < 0 || d <= params.max);",
+					"params": {"min": 1000, "max": -1}
+				}}},
+				{"bool": {"should": [
+					{"term": {"Attributes.db.system": {"value": "mysql"}}},
+					{"term": {"Attributes.db.system.keyword": {"value": "mysql"}}},
+					{"term": {"Resource.db.system": {"value": "mysql"}}},
+					{"term": {"Resource.db.system.keyword": {"value": "mysql"}}}
+				], "minimum_should_match": 1}}
+			]
+		}},
+		"from": 20,
+		"size": 10,
+		"sort": [{"_script": {"type": "number", "script": {"source": "`+spanDurationScript+`"}, "order": "desc"}}]
+	}`, string(b))
+
+	body = buildSpanSearch(&datasource.SpanQueryParameters{SortBy: datasource.SpanSortByName, Ascending: true, Limit: 5})
+	assert.Equal(t, []interface{}{map[string]interface{}{"Name.keyword": map[string]interface{}{"order": esquery.OrderAsc}}}, body["sort"])
+}
+
+func TestBuildJaegerSpanSearch(t *testing.T) {
+	req := buildJaegerSpanSearch(&datasource.SpanQueryParameters{
+		OperationName: "SELECT",
+		SpanKind:      "SPAN_KIND_CLIENT",
+		DurationMin:   time.Second,
+		SortBy:        datasource.SpanSortByDuration,
+		Limit:         10,
+	})
+	b, err := json.Marshal(req.Map())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"query": {"bool": {
+			"must": [{"match_all": {}}],
+			"filter": [
+				{"term": {"operationName": {"value": "SELECT"}}},
+				{"nested": {"path": "tags", "query": {"bool": {"must": [
+					{"term": {"tags.key": {"value": "span.kind"}}},
+					{"term": {"tags.value": {"value": "client"}}}
+				]}}}},
+				{"range": {"duration": {"gte": 1000000}}}
+			]
+		}},
+		"from": 0,
+		"size": 10,
+		"sort": [{"duration": {"order": "desc"}}]
+	}`, string(b))
+}
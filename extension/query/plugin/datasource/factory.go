@@ -1,6 +1,8 @@
 package datasource
 
 import (
+	"context"
+
 	"go.uber.org/zap"
 )
 
@@ -8,4 +10,18 @@ type Factory interface {
 	Initialize(logger *zap.Logger) error
 	// CreateSpanQuery creates a datasource.Query.
 	CreateSpanQuery() (Query, error)
+	// CreateLogQuery creates a datasource.LogQuery.
+	CreateLogQuery() (LogQuery, error)
+	// CreateMetricQuery creates a datasource.MetricQuery.
+	CreateMetricQuery() (MetricQuery, error)
+	// CreateTraceArchive creates a datasource.TraceArchive, it returns ErrArchiveNotConfigured
+	// without an archive index or table configured.
+	CreateTraceArchive() (TraceArchive, error)
+	// CreateDependencyReader creates a datasource.DependencyReader, it returns
+	// ErrDependenciesNotConfigured when the datasource doesn't store the dependency links.
+	CreateDependencyReader() (DependencyReader, error)
+	// CreateSavedQueryStore creates a datasource.SavedQueryStore.
+	CreateSavedQueryStore() (SavedQueryStore, error)
+	// Ping checks the connectivity of the initialized datasource.
+	Ping(ctx context.Context) error
 }
@@ -0,0 +1,127 @@
+package file
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/api/v1alpha1"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	bolt "go.etcd.io/bbolt"
+)
+
+// openTimeout bounds the wait for the lock of a database file held by another process.
+const openTimeout = time.Second
+
+var savedQueriesBucket = []byte("saved_queries")
+
+var (
+	_ datasource.SavedQueryStore = (*SavedQueryStore)(nil)
+	_ io.Closer                  = (*SavedQueryStore)(nil)
+)
+
+// SavedQueryStore keeps the saved queries in a local bbolt database file, for the deployments
+// without Elasticsearch or ClickHouse to store them.
+type SavedQueryStore struct {
+	db *bolt.DB
+}
+
+// NewSavedQueryStore opens the database file at path, creating it if needed.
+func NewSavedQueryStore(path string) (*SavedQueryStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(savedQueriesBucket)
+		return err
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &SavedQueryStore{db: db}, nil
+}
+
+func (s *SavedQueryStore) CreateSavedQuery(_ context.Context, query *v1alpha1.SavedQuery) error {
+	return s.put(query, func(exists bool) error {
+		if exists {
+			return datasource.ErrSavedQueryExists
+		}
+		return nil
+	})
+}
+
+func (s *SavedQueryStore) GetSavedQuery(_ context.Context, id string) (*v1alpha1.SavedQuery, error) {
+	var query *v1alpha1.SavedQuery
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(savedQueriesBucket).Get([]byte(id))
+		if b == nil {
+			return datasource.ErrSavedQueryNotFound
+		}
+		var err error
+		query, err = datasource.UnmarshalSavedQuery(b)
+		return err
+	})
+	return query, err
+}
+
+func (s *SavedQueryStore) ListSavedQueries(_ context.Context, filter *datasource.SavedQueryFilter) ([]*v1alpha1.SavedQuery, error) {
+	var queries []*v1alpha1.SavedQuery
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(savedQueriesBucket).ForEach(func(_, b []byte) error {
+			query, err := datasource.UnmarshalSavedQuery(b)
+			if err != nil {
+				return err
+			}
+			if filter.Match(query) {
+				queries = append(queries, query)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	datasource.SortSavedQueries(queries)
+	return queries, nil
+}
+
+func (s *SavedQueryStore) UpdateSavedQuery(_ context.Context, query *v1alpha1.SavedQuery) error {
+	return s.put(query, func(exists bool) error {
+		if !exists {
+			return datasource.ErrSavedQueryNotFound
+		}
+		return nil
+	})
+}
+
+func (s *SavedQueryStore) DeleteSavedQuery(_ context.Context, id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(savedQueriesBucket)
+		if bucket.Get([]byte(id)) == nil {
+			return datasource.ErrSavedQueryNotFound
+		}
+		return bucket.Delete([]byte(id))
+	})
+}
+
+// put writes a saved query in a transaction checking whether its ID exists with check.
+func (s *SavedQueryStore) put(query *v1alpha1.SavedQuery, check func(exists bool) error) error {
+	b, err := datasource.MarshalSavedQuery(query)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(savedQueriesBucket)
+		if err := check(bucket.Get([]byte(query.Id)) != nil); err != nil {
+			return err
+		}
+		return bucket.Put([]byte(query.Id), b)
+	})
+}
+
+// Close closes the database file.
+func (s *SavedQueryStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,73 @@
+package file
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/api/v1alpha1"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestSavedQueryStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "saved_queries.db")
+	store, err := NewSavedQueryStore(path)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	frontend := &v1alpha1.SavedQuery{
+		Id:       "aZ3kP9xQ",
+		Name:     "frontend errors",
+		Owner:    "oncall",
+		Tags:     []string{"frontend"},
+		Lookback: durationpb.New(3600e9),
+		Query: &v1alpha1.SavedQuery_TraceQuery{TraceQuery: &v1alpha1.TraceQueryParameters{
+			ServiceName: "frontend",
+			Attributes:  map[string]string{"error": "true"},
+		}},
+	}
+	logs := &v1alpha1.SavedQuery{
+		Id:    "b7Hq2LmN",
+		Name:  "backend timeouts",
+		Owner: "backend",
+		Query: &v1alpha1.SavedQuery_LogQuery{LogQuery: &v1alpha1.LogQueryParameters{Logql: `{service_name="backend"} |= "timeout"`}},
+	}
+	require.NoError(t, store.CreateSavedQuery(ctx, frontend))
+	require.NoError(t, store.CreateSavedQuery(ctx, logs))
+	assert.ErrorIs(t, store.CreateSavedQuery(ctx, frontend), datasource.ErrSavedQueryExists)
+
+	got, err := store.GetSavedQuery(ctx, frontend.Id)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(frontend, got), got)
+	_, err = store.GetSavedQuery(ctx, "missing")
+	assert.ErrorIs(t, err, datasource.ErrSavedQueryNotFound)
+
+	list, err := store.ListSavedQueries(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "backend timeouts", list[0].Name)
+	list, err = store.ListSavedQueries(ctx, &datasource.SavedQueryFilter{Tag: "frontend"})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, frontend.Id, list[0].Id)
+
+	logs.Name = "backend deadline exceeded"
+	require.NoError(t, store.UpdateSavedQuery(ctx, logs))
+	assert.ErrorIs(t, store.UpdateSavedQuery(ctx, &v1alpha1.SavedQuery{Id: "missing"}), datasource.ErrSavedQueryNotFound)
+	require.NoError(t, store.DeleteSavedQuery(ctx, frontend.Id))
+	assert.ErrorIs(t, store.DeleteSavedQuery(ctx, frontend.Id), datasource.ErrSavedQueryNotFound)
+	require.NoError(t, store.Close())
+
+	// the saved queries outlive a restart
+	store, err = NewSavedQueryStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+	list, err = store.ListSavedQueries(ctx, &datasource.SavedQueryFilter{Owner: "backend"})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "backend deadline exceeded", list[0].Name)
+}
@@ -2,12 +2,16 @@ package datasource
 
 import (
 	"context"
+	"encoding/hex"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/elliotchance/orderedmap/v2"
 	"github.com/golang/protobuf/ptypes/duration"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/api/v1alpha1"
 	semconv "go.opentelemetry.io/collector/semconv/v1.6.1"
+	v1_common "go.opentelemetry.io/proto/otlp/common/v1"
 	v1_logs "go.opentelemetry.io/proto/otlp/logs/v1"
 	v1_resource "go.opentelemetry.io/proto/otlp/resource/v1"
 	v1_trace "go.opentelemetry.io/proto/otlp/trace/v1"
@@ -16,10 +20,18 @@ import (
 type Query interface {
 	GetTrace(ctx context.Context, traceID string) (*v1_trace.TracesData, error)
 	SearchTraces(ctx context.Context, query *TraceQueryParameters) (*v1alpha1.TracesData, error)
+	// FindTraces returns the spans of the traces matching query, the spans are fetched with the
+	// search rather than with a GetTrace per trace.
+	FindTraces(ctx context.Context, query *TraceQueryParameters) (*v1_trace.TracesData, error)
 	SearchLogs(ctx context.Context) (*v1_logs.LogsData, error)
 	GetLog(ctx context.Context) (*v1_logs.LogsData, error)
 	GetService(ctx context.Context) ([]*v1_resource.Resource, error)
 	GetOperations(ctx context.Context, query *OperationsQueryParameters) ([]string, error)
+	// SearchSpans returns the matching spans in order, with a ResourceSpans per span.
+	SearchSpans(ctx context.Context, query *SpanQueryParameters) (*v1_trace.TracesData, error)
+	// AggregateSpans groups the matching spans and aggregates every group, the top groups by the
+	// first aggregation are returned in descending order.
+	AggregateSpans(ctx context.Context, query *SpanQueryParameters, agg *AggregateParameters) ([]*AggregateGroup, error)
 
 	//TODO: add metrics query.
 }
@@ -29,11 +41,35 @@ type TraceQueryParameters struct {
 	ServiceName   string
 	OperationName string
 	Tags          map[string]string
-	StartTime     time.Time
-	EndTime       time.Time
-	DurationMin   *duration.Duration
-	DurationMax   *duration.Duration
-	NumTraces     int
+	// TagKeys are the attributes the spans must have, whatever their value.
+	TagKeys     []string
+	StartTime   time.Time
+	EndTime     time.Time
+	DurationMin *duration.Duration
+	DurationMax *duration.Duration
+	// StatusCode is an OTLP status code, e.g. STATUS_CODE_ERROR.
+	StatusCode string
+	NumTraces  int
+}
+
+// SpanQuery returns the span filters of the trace query, a trace matches when one of its spans does.
+func (p *TraceQueryParameters) SpanQuery() *SpanQueryParameters {
+	query := &SpanQueryParameters{
+		ServiceName:   p.ServiceName,
+		OperationName: p.OperationName,
+		StatusCode:    p.StatusCode,
+		Tags:          p.Tags,
+		TagKeys:       p.TagKeys,
+		StartTime:     p.StartTime,
+		EndTime:       p.EndTime,
+	}
+	if p.DurationMin != nil {
+		query.DurationMin = p.DurationMin.AsDuration()
+	}
+	if p.DurationMax != nil {
+		query.DurationMax = p.DurationMax.AsDuration()
+	}
+	return query
 }
 
 type OperationsQueryParameters struct {
@@ -109,10 +145,7 @@ func DocumentsTracesConvert(otlpTraces *v1_trace.TracesData) (*v1alpha1.TracesDa
 				if attribute.Key == "service.name" {
 					pro.ServiceName = attribute.Value.GetStringValue()
 				} else {
-					pro.Tags = append(pro.Tags, &v1alpha1.KeyValue{
-						Key:  attribute.Key,
-						VStr: attribute.Value.GetStringValue(),
-					})
+					pro.Tags = append(pro.Tags, toProcessTag(attribute))
 				}
 			}
 			process = append(process, &v1alpha1.Trace_ResourceProcess{Process: pro})
@@ -160,3 +193,57 @@ func DocumentsTracesConvert(otlpTraces *v1_trace.TracesData) (*v1alpha1.TracesDa
 
 	return &v1alpha1.TracesData{Traces: traces}, nil
 }
+
+// toProcessTag converts an OTLP attribute into a typed process tag.
+func toProcessTag(attribute *v1_common.KeyValue) *v1alpha1.KeyValue {
+	tag := &v1alpha1.KeyValue{Key: attribute.Key}
+	switch v := attribute.Value.GetValue().(type) {
+	case *v1_common.AnyValue_BoolValue:
+		tag.VType = v1alpha1.ValueType_BOOL
+		tag.VBool = v.BoolValue
+	case *v1_common.AnyValue_IntValue:
+		tag.VType = v1alpha1.ValueType_INT64
+		tag.VInt64 = v.IntValue
+	case *v1_common.AnyValue_DoubleValue:
+		tag.VType = v1alpha1.ValueType_FLOAT64
+		tag.VFloat64 = v.DoubleValue
+	case *v1_common.AnyValue_BytesValue:
+		tag.VType = v1alpha1.ValueType_BINARY
+		tag.VBinary = v.BytesValue
+	case *v1_common.AnyValue_StringValue:
+		tag.VStr = v.StringValue
+	default:
+		tag.VStr = AnyValueString(attribute.Value)
+	}
+	return tag
+}
+
+// AnyValueString renders an AnyValue as a plain string.
+func AnyValueString(value *v1_common.AnyValue) string {
+	switch v := value.GetValue().(type) {
+	case *v1_common.AnyValue_StringValue:
+		return v.StringValue
+	case *v1_common.AnyValue_BoolValue:
+		return strconv.FormatBool(v.BoolValue)
+	case *v1_common.AnyValue_IntValue:
+		return strconv.FormatInt(v.IntValue, 10)
+	case *v1_common.AnyValue_DoubleValue:
+		return strconv.FormatFloat(v.DoubleValue, 'f', -1, 64)
+	case *v1_common.AnyValue_BytesValue:
+		return hex.EncodeToString(v.BytesValue)
+	case *v1_common.AnyValue_ArrayValue:
+		values := make([]string, 0, len(v.ArrayValue.GetValues()))
+		for _, item := range v.ArrayValue.GetValues() {
+			values = append(values, AnyValueString(item))
+		}
+		return "[" + strings.Join(values, ",") + "]"
+	case *v1_common.AnyValue_KvlistValue:
+		values := make([]string, 0, len(v.KvlistValue.GetValues()))
+		for _, kv := range v.KvlistValue.GetValues() {
+			values = append(values, kv.Key+":"+AnyValueString(kv.Value))
+		}
+		return "{" + strings.Join(values, ",") + "}"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,29 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrTooManySpans is returned when the spans of the searched traces don't fit in a search
+// response, the traces would be truncated otherwise.
+var ErrTooManySpans = errors.New("the traces have more spans than a search returns")
+
+// ErrTooManySamples is returned when a metric query selects more series or samples than a
+// datasource reads.
+var ErrTooManySamples = errors.New("the query selects more samples than a datasource reads")
+
+type spanLimitKey struct{}
+
+// ContextWithSpanLimit returns a context carrying the maximum number of spans a
+// datasource should return for a trace. Datasources fetch one span more than the
+// limit so the caller can tell the trace was truncated.
+func ContextWithSpanLimit(ctx context.Context, limit int) context.Context {
+	return context.WithValue(ctx, spanLimitKey{}, limit)
+}
+
+// SpanLimit returns the span limit carried by ctx, or 0 if there is none.
+func SpanLimit(ctx context.Context) int {
+	limit, _ := ctx.Value(spanLimitKey{}).(int)
+	return limit
+}
@@ -0,0 +1,84 @@
+package datasource
+
+import (
+	"context"
+	"time"
+)
+
+// LogQuery reads the log records of a logging datasource, the stream labels of a record are
+// the attributes of its resource.
+type LogQuery interface {
+	// FindLogs returns up to Limit matching log records, newest first unless Forward is set.
+	FindLogs(ctx context.Context, query *LogQueryParameters) ([]*LogEntry, error)
+	// CountLogs returns the number of matching log records of every stream per Step bucket,
+	// the buckets are aligned on the multiples of Step since the Unix epoch.
+	CountLogs(ctx context.Context, query *LogQueryParameters) ([]*LogSeries, error)
+	// LogLabelNames returns the resource attribute keys of the log records.
+	LogLabelNames(ctx context.Context, start, end time.Time) ([]string, error)
+	// LogLabelValues returns the values of a resource attribute of the log records.
+	LogLabelValues(ctx context.Context, name string, start, end time.Time) ([]string, error)
+	// LogStreams returns the distinct resource attributes of the matching log records.
+	LogStreams(ctx context.Context, query *LogQueryParameters) ([]map[string]string, error)
+	// AggregateLogs groups the matching log records and aggregates every group, the top groups by
+	// the first aggregation are returned in descending order.
+	AggregateLogs(ctx context.Context, query *LogQueryParameters, agg *AggregateParameters) ([]*AggregateGroup, error)
+}
+
+// MatchType is the comparison of a label matcher or a line filter.
+type MatchType int
+
+const (
+	// MatchEqual is a label equal to the value, or a line containing it.
+	MatchEqual MatchType = iota
+	// MatchNotEqual is a label different from the value, or a line not containing it.
+	MatchNotEqual
+	// MatchRegexp is a label fully matching the regular expression, or a line containing a match.
+	MatchRegexp
+	// MatchNotRegexp is the negation of MatchRegexp.
+	MatchNotRegexp
+)
+
+// LabelMatcher selects the log records by a resource attribute.
+type LabelMatcher struct {
+	Name  string
+	Type  MatchType
+	Value string
+}
+
+// LineFilter selects the log records by their body.
+type LineFilter struct {
+	Type  MatchType
+	Value string
+}
+
+// LogQueryParameters contains parameters of a log query.
+type LogQueryParameters struct {
+	Matchers    []LabelMatcher
+	LineFilters []LineFilter
+	StartTime   time.Time
+	EndTime     time.Time
+	Limit       int
+	// Forward returns the oldest records first.
+	Forward bool
+	// Step is the width of the CountLogs buckets.
+	Step time.Duration
+}
+
+// LogEntry is a log record with the labels of its stream.
+type LogEntry struct {
+	Timestamp time.Time
+	Labels    map[string]string
+	Line      string
+}
+
+// LogSeries is the number of log records of a stream per bucket.
+type LogSeries struct {
+	Labels  map[string]string
+	Samples []LogSample
+}
+
+// LogSample is the number of log records in the bucket starting at Timestamp.
+type LogSample struct {
+	Timestamp time.Time
+	Count     int64
+}
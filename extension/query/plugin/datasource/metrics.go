@@ -0,0 +1,86 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// MetricNameLabel is the label of the metric name of a series.
+const MetricNameLabel = "__name__"
+
+// MetricQuery reads the data points of a metrics datasource as Prometheus series, a series is
+// identified by its labels which include the metric name as MetricNameLabel.
+type MetricQuery interface {
+	// SelectSeries returns the series matching all the matchers with their samples in the time
+	// range, ordered by time.
+	SelectSeries(ctx context.Context, query *MetricQueryParameters) ([]*MetricSeries, error)
+	// SeriesLabels returns the labels of the series matching all the matchers which have samples
+	// in the time range.
+	SeriesLabels(ctx context.Context, query *MetricQueryParameters) ([]map[string]string, error)
+}
+
+// MetricQueryParameters contains parameters of a metric query.
+type MetricQueryParameters struct {
+	Matchers  []LabelMatcher
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// MetricSeries is the samples of a series.
+type MetricSeries struct {
+	Labels  map[string]string
+	Samples []MetricSample
+}
+
+// MetricSample is the value of a series at Timestamp.
+type MetricSample struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// LabelFilter matches label sets against label matchers, as Prometheus does: a missing label
+// has the empty value and the regular expressions are fully anchored.
+type LabelFilter struct {
+	matchers []LabelMatcher
+	regexps  []*regexp.Regexp
+}
+
+// NewLabelFilter compiles the regular expressions of matchers.
+func NewLabelFilter(matchers []LabelMatcher) (*LabelFilter, error) {
+	f := &LabelFilter{matchers: matchers, regexps: make([]*regexp.Regexp, len(matchers))}
+	for i, m := range matchers {
+		if m.Type != MatchRegexp && m.Type != MatchNotRegexp {
+			continue
+		}
+		re, err := regexp.Compile("^(?:" + m.Value + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q of label %s: %w", m.Value, m.Name, err)
+		}
+		f.regexps[i] = re
+	}
+	return f, nil
+}
+
+// Matches reports whether labels match all the matchers.
+func (f *LabelFilter) Matches(labels map[string]string) bool {
+	for i, m := range f.matchers {
+		value := labels[m.Name]
+		var matched bool
+		switch m.Type {
+		case MatchEqual:
+			matched = value == m.Value
+		case MatchNotEqual:
+			matched = value != m.Value
+		case MatchRegexp:
+			matched = f.regexps[i].MatchString(value)
+		case MatchNotRegexp:
+			matched = !f.regexps[i].MatchString(value)
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
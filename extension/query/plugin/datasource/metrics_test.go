@@ -0,0 +1,27 @@
+package datasource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabelFilter(t *testing.T) {
+	filter, err := NewLabelFilter([]LabelMatcher{
+		{Name: MetricNameLabel, Type: MatchEqual, Value: "up"},
+		{Name: "job", Type: MatchRegexp, Value: "front|back"},
+		{Name: "instance", Type: MatchNotRegexp, Value: "test-.*"},
+		{Name: "env", Type: MatchNotEqual, Value: "dev"},
+	})
+	require.NoError(t, err)
+
+	assert.True(t, filter.Matches(map[string]string{MetricNameLabel: "up", "job": "front", "instance": "prod-1"}))
+	assert.False(t, filter.Matches(map[string]string{MetricNameLabel: "up", "job": "frontend"}))
+	assert.False(t, filter.Matches(map[string]string{MetricNameLabel: "up", "job": "back", "instance": "test-1"}))
+	assert.False(t, filter.Matches(map[string]string{MetricNameLabel: "up", "job": "back", "env": "dev"}))
+	assert.False(t, filter.Matches(map[string]string{MetricNameLabel: "down", "job": "back"}))
+
+	_, err = NewLabelFilter([]LabelMatcher{{Name: "job", Type: MatchRegexp, Value: "("}})
+	assert.Error(t, err)
+}
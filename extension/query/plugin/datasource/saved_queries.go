@@ -0,0 +1,80 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/api/v1alpha1"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+var (
+	// ErrSavedQueryNotFound is returned for the IDs without a saved query.
+	ErrSavedQueryNotFound = errors.New("saved query not found")
+	// ErrSavedQueryExists is returned when creating a saved query with the ID of another one.
+	ErrSavedQueryExists = errors.New("saved query already exists")
+)
+
+// SavedQueryStore keeps the saved queries, by ID.
+type SavedQueryStore interface {
+	// CreateSavedQuery stores a new saved query, it returns ErrSavedQueryExists if the ID is taken.
+	CreateSavedQuery(ctx context.Context, query *v1alpha1.SavedQuery) error
+	// GetSavedQuery returns a saved query or ErrSavedQueryNotFound.
+	GetSavedQuery(ctx context.Context, id string) (*v1alpha1.SavedQuery, error)
+	// ListSavedQueries returns the saved queries matching filter, ordered by name.
+	ListSavedQueries(ctx context.Context, filter *SavedQueryFilter) ([]*v1alpha1.SavedQuery, error)
+	// UpdateSavedQuery replaces a saved query or returns ErrSavedQueryNotFound.
+	UpdateSavedQuery(ctx context.Context, query *v1alpha1.SavedQuery) error
+	// DeleteSavedQuery deletes a saved query or returns ErrSavedQueryNotFound.
+	DeleteSavedQuery(ctx context.Context, id string) error
+}
+
+// SavedQueryFilter selects the saved queries to list, the empty fields match all.
+type SavedQueryFilter struct {
+	Owner string
+	Tag   string
+}
+
+// Match reports whether the saved query matches the filter.
+func (f *SavedQueryFilter) Match(query *v1alpha1.SavedQuery) bool {
+	if f == nil {
+		return true
+	}
+	if f.Owner != "" && query.Owner != f.Owner {
+		return false
+	}
+	if f.Tag == "" {
+		return true
+	}
+	for _, tag := range query.Tags {
+		if tag == f.Tag {
+			return true
+		}
+	}
+	return false
+}
+
+// SortSavedQueries orders the saved queries by name, then by ID.
+func SortSavedQueries(queries []*v1alpha1.SavedQuery) {
+	sort.Slice(queries, func(i, j int) bool {
+		if queries[i].Name != queries[j].Name {
+			return queries[i].Name < queries[j].Name
+		}
+		return queries[i].Id < queries[j].Id
+	})
+}
+
+// MarshalSavedQuery encodes a saved query as the stores keep it, in the JSON of the REST API.
+func MarshalSavedQuery(query *v1alpha1.SavedQuery) ([]byte, error) {
+	return protojson.Marshal(query)
+}
+
+// UnmarshalSavedQuery decodes a saved query encoded by MarshalSavedQuery.
+func UnmarshalSavedQuery(b []byte) (*v1alpha1.SavedQuery, error) {
+	query := &v1alpha1.SavedQuery{}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(b, query); err != nil {
+		return nil, err
+	}
+	return query, nil
+}
@@ -0,0 +1,36 @@
+package datasource
+
+import "time"
+
+// SpanSortField is the field a span search is ordered by.
+type SpanSortField string
+
+const (
+	SpanSortByStartTime   SpanSortField = "start_time"
+	SpanSortByDuration    SpanSortField = "duration"
+	SpanSortByServiceName SpanSortField = "service_name"
+	SpanSortByName        SpanSortField = "name"
+)
+
+// SpanQueryParameters contains parameters of a span search, the zero values match all the spans.
+type SpanQueryParameters struct {
+	ServiceName   string
+	OperationName string
+	// SpanKind is an OTLP span kind, e.g. SPAN_KIND_CLIENT.
+	SpanKind string
+	// StatusCode is an OTLP status code, e.g. STATUS_CODE_ERROR.
+	StatusCode string
+	// Tags are matched against the span or the resource attributes.
+	Tags map[string]string
+	// TagKeys are the span or resource attributes the spans must have, whatever their value.
+	TagKeys     []string
+	StartTime   time.Time
+	EndTime     time.Time
+	DurationMin time.Duration
+	DurationMax time.Duration
+	// SortBy orders the spans, newest first unless Ascending is set.
+	SortBy    SpanSortField
+	Ascending bool
+	Offset    int
+	Limit     int
+}
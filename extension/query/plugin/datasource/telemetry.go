@@ -0,0 +1,392 @@
+package datasource
+
+import (
+	"context"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/api/v1alpha1"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric/instrument"
+	"go.opentelemetry.io/otel/metric/unit"
+	"go.opentelemetry.io/otel/trace"
+	v1_logs "go.opentelemetry.io/proto/otlp/logs/v1"
+	v1_resource "go.opentelemetry.io/proto/otlp/resource/v1"
+	v1_trace "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// InstrumentationName is the instrumentation scope of the query extension self-telemetry.
+const InstrumentationName = "github.com/open-telemetry/opentelemetry-collector-contrib/extension/query"
+
+var (
+	backendKey = attribute.Key("backend")
+	methodKey  = attribute.Key("method")
+	indexKey   = attribute.Key("index")
+)
+
+// Telemetry records metrics and spans for the calls made to the datasources.
+type Telemetry struct {
+	tracer trace.Tracer
+
+	requests   instrument.Int64Counter
+	errors     instrument.Int64Counter
+	duration   instrument.Float64Histogram
+	resultSize instrument.Int64Histogram
+	searchTook instrument.Int64Histogram
+}
+
+// NewTelemetry creates the datasource instruments from the collector telemetry settings.
+func NewTelemetry(settings component.TelemetrySettings) (*Telemetry, error) {
+	meter := settings.MeterProvider.Meter(InstrumentationName)
+	t := &Telemetry{
+		tracer: settings.TracerProvider.Tracer(InstrumentationName),
+	}
+
+	var err error
+	if t.requests, err = meter.Int64Counter("query/datasource_requests",
+		instrument.WithDescription("Number of calls made to the datasources."),
+		instrument.WithUnit(unit.Dimensionless)); err != nil {
+		return nil, err
+	}
+	if t.errors, err = meter.Int64Counter("query/datasource_errors",
+		instrument.WithDescription("Number of datasource calls which failed."),
+		instrument.WithUnit(unit.Dimensionless)); err != nil {
+		return nil, err
+	}
+	if t.duration, err = meter.Float64Histogram("query/datasource_duration",
+		instrument.WithDescription("Latency of the datasource calls."),
+		instrument.WithUnit(unit.Milliseconds)); err != nil {
+		return nil, err
+	}
+	if t.resultSize, err = meter.Int64Histogram("query/datasource_result_size",
+		instrument.WithDescription("Number of items (traces, spans, services, operations, log records or series) returned by the datasource calls."),
+		instrument.WithUnit(unit.Dimensionless)); err != nil {
+		return nil, err
+	}
+	if t.searchTook, err = meter.Int64Histogram("query/elasticsearch_took",
+		instrument.WithDescription("Time Elasticsearch reported spending on the search requests."),
+		instrument.WithUnit(unit.Milliseconds)); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// RecordSearchTook records the `took` time reported by Elasticsearch for a search on index.
+func (t *Telemetry) RecordSearchTook(ctx context.Context, index string, took time.Duration) {
+	if t == nil {
+		return
+	}
+	t.searchTook.Record(ctx, took.Milliseconds(), indexKey.String(index))
+}
+
+// WrapQuery instruments the calls made to q, backend is the storage type serving it.
+func (t *Telemetry) WrapQuery(backend string, q Query) Query {
+	if t == nil || q == nil {
+		return q
+	}
+	return &instrumentedQuery{telemetry: t, backend: backend, query: q}
+}
+
+// start creates the span of a datasource call, the returned func ends it and records the metrics.
+func (t *Telemetry) start(ctx context.Context, backend, method string) (context.Context, func(size int, err error)) {
+	attrs := []attribute.KeyValue{backendKey.String(backend), methodKey.String(method)}
+	ctx, span := t.tracer.Start(ctx, "datasource/"+method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	start := time.Now()
+	return ctx, func(size int, err error) {
+		t.requests.Add(ctx, 1, attrs...)
+		t.duration.Record(ctx, float64(time.Since(start).Microseconds())/1e3, attrs...)
+		if err != nil {
+			t.errors.Add(ctx, 1, attrs...)
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		} else {
+			t.resultSize.Record(ctx, int64(size), attrs...)
+			span.SetAttributes(attribute.Int("result_size", size))
+		}
+		span.End()
+	}
+}
+
+var _ Query = (*instrumentedQuery)(nil)
+
+type instrumentedQuery struct {
+	telemetry *Telemetry
+	backend   string
+	query     Query
+}
+
+func (q *instrumentedQuery) GetTrace(ctx context.Context, traceID string) (*v1_trace.TracesData, error) {
+	ctx, end := q.telemetry.start(ctx, q.backend, "GetTrace")
+	res, err := q.query.GetTrace(ctx, traceID)
+	end(countSpans(res), err)
+	return res, err
+}
+
+func (q *instrumentedQuery) SearchTraces(ctx context.Context, query *TraceQueryParameters) (*v1alpha1.TracesData, error) {
+	ctx, end := q.telemetry.start(ctx, q.backend, "SearchTraces")
+	res, err := q.query.SearchTraces(ctx, query)
+	end(len(res.GetTraces()), err)
+	return res, err
+}
+
+func (q *instrumentedQuery) FindTraces(ctx context.Context, query *TraceQueryParameters) (*v1_trace.TracesData, error) {
+	ctx, end := q.telemetry.start(ctx, q.backend, "FindTraces")
+	res, err := q.query.FindTraces(ctx, query)
+	end(countSpans(res), err)
+	return res, err
+}
+
+func (q *instrumentedQuery) SearchSpans(ctx context.Context, query *SpanQueryParameters) (*v1_trace.TracesData, error) {
+	ctx, end := q.telemetry.start(ctx, q.backend, "SearchSpans")
+	res, err := q.query.SearchSpans(ctx, query)
+	end(countSpans(res), err)
+	return res, err
+}
+
+func (q *instrumentedQuery) AggregateSpans(ctx context.Context, query *SpanQueryParameters, agg *AggregateParameters) ([]*AggregateGroup, error) {
+	ctx, end := q.telemetry.start(ctx, q.backend, "AggregateSpans")
+	res, err := q.query.AggregateSpans(ctx, query, agg)
+	end(len(res), err)
+	return res, err
+}
+
+func (q *instrumentedQuery) SearchLogs(ctx context.Context) (*v1_logs.LogsData, error) {
+	ctx, end := q.telemetry.start(ctx, q.backend, "SearchLogs")
+	res, err := q.query.SearchLogs(ctx)
+	end(countLogRecords(res), err)
+	return res, err
+}
+
+func (q *instrumentedQuery) GetLog(ctx context.Context) (*v1_logs.LogsData, error) {
+	ctx, end := q.telemetry.start(ctx, q.backend, "GetLog")
+	res, err := q.query.GetLog(ctx)
+	end(countLogRecords(res), err)
+	return res, err
+}
+
+func (q *instrumentedQuery) GetService(ctx context.Context) ([]*v1_resource.Resource, error) {
+	ctx, end := q.telemetry.start(ctx, q.backend, "GetService")
+	res, err := q.query.GetService(ctx)
+	end(len(res), err)
+	return res, err
+}
+
+func (q *instrumentedQuery) GetOperations(ctx context.Context, query *OperationsQueryParameters) ([]string, error) {
+	ctx, end := q.telemetry.start(ctx, q.backend, "GetOperations")
+	res, err := q.query.GetOperations(ctx, query)
+	end(len(res), err)
+	return res, err
+}
+
+// WrapLogQuery instruments the calls made to q, backend is the storage type serving it.
+func (t *Telemetry) WrapLogQuery(backend string, q LogQuery) LogQuery {
+	if t == nil || q == nil {
+		return q
+	}
+	return &instrumentedLogQuery{telemetry: t, backend: backend, query: q}
+}
+
+var _ LogQuery = (*instrumentedLogQuery)(nil)
+
+type instrumentedLogQuery struct {
+	telemetry *Telemetry
+	backend   string
+	query     LogQuery
+}
+
+func (q *instrumentedLogQuery) FindLogs(ctx context.Context, query *LogQueryParameters) ([]*LogEntry, error) {
+	ctx, end := q.telemetry.start(ctx, q.backend, "FindLogs")
+	res, err := q.query.FindLogs(ctx, query)
+	end(len(res), err)
+	return res, err
+}
+
+func (q *instrumentedLogQuery) CountLogs(ctx context.Context, query *LogQueryParameters) ([]*LogSeries, error) {
+	ctx, end := q.telemetry.start(ctx, q.backend, "CountLogs")
+	res, err := q.query.CountLogs(ctx, query)
+	end(len(res), err)
+	return res, err
+}
+
+func (q *instrumentedLogQuery) LogLabelNames(ctx context.Context, start, end time.Time) ([]string, error) {
+	ctx, done := q.telemetry.start(ctx, q.backend, "LogLabelNames")
+	res, err := q.query.LogLabelNames(ctx, start, end)
+	done(len(res), err)
+	return res, err
+}
+
+func (q *instrumentedLogQuery) LogLabelValues(ctx context.Context, name string, start, end time.Time) ([]string, error) {
+	ctx, done := q.telemetry.start(ctx, q.backend, "LogLabelValues")
+	res, err := q.query.LogLabelValues(ctx, name, start, end)
+	done(len(res), err)
+	return res, err
+}
+
+func (q *instrumentedLogQuery) LogStreams(ctx context.Context, query *LogQueryParameters) ([]map[string]string, error) {
+	ctx, end := q.telemetry.start(ctx, q.backend, "LogStreams")
+	res, err := q.query.LogStreams(ctx, query)
+	end(len(res), err)
+	return res, err
+}
+
+func (q *instrumentedLogQuery) AggregateLogs(ctx context.Context, query *LogQueryParameters, agg *AggregateParameters) ([]*AggregateGroup, error) {
+	ctx, end := q.telemetry.start(ctx, q.backend, "AggregateLogs")
+	res, err := q.query.AggregateLogs(ctx, query, agg)
+	end(len(res), err)
+	return res, err
+}
+
+// WrapMetricQuery instruments the calls made to q, backend is the storage type serving it.
+func (t *Telemetry) WrapMetricQuery(backend string, q MetricQuery) MetricQuery {
+	if t == nil || q == nil {
+		return q
+	}
+	return &instrumentedMetricQuery{telemetry: t, backend: backend, query: q}
+}
+
+var _ MetricQuery = (*instrumentedMetricQuery)(nil)
+
+type instrumentedMetricQuery struct {
+	telemetry *Telemetry
+	backend   string
+	query     MetricQuery
+}
+
+func (q *instrumentedMetricQuery) SelectSeries(ctx context.Context, query *MetricQueryParameters) ([]*MetricSeries, error) {
+	ctx, end := q.telemetry.start(ctx, q.backend, "SelectSeries")
+	res, err := q.query.SelectSeries(ctx, query)
+	end(len(res), err)
+	return res, err
+}
+
+func (q *instrumentedMetricQuery) SeriesLabels(ctx context.Context, query *MetricQueryParameters) ([]map[string]string, error) {
+	ctx, end := q.telemetry.start(ctx, q.backend, "SeriesLabels")
+	res, err := q.query.SeriesLabels(ctx, query)
+	end(len(res), err)
+	return res, err
+}
+
+// WrapTraceArchive instruments the calls made to a, backend is the storage type serving it.
+func (t *Telemetry) WrapTraceArchive(backend string, a TraceArchive) TraceArchive {
+	if t == nil || a == nil {
+		return a
+	}
+	return &instrumentedTraceArchive{telemetry: t, backend: backend, archive: a}
+}
+
+var _ TraceArchive = (*instrumentedTraceArchive)(nil)
+
+type instrumentedTraceArchive struct {
+	telemetry *Telemetry
+	backend   string
+	archive   TraceArchive
+}
+
+func (a *instrumentedTraceArchive) ArchiveTrace(ctx context.Context, traceID string) (int, error) {
+	ctx, end := a.telemetry.start(ctx, a.backend, "ArchiveTrace")
+	res, err := a.archive.ArchiveTrace(ctx, traceID)
+	end(res, err)
+	return res, err
+}
+
+func (a *instrumentedTraceArchive) GetArchivedTrace(ctx context.Context, traceID string) (*v1_trace.TracesData, error) {
+	ctx, end := a.telemetry.start(ctx, a.backend, "GetArchivedTrace")
+	res, err := a.archive.GetArchivedTrace(ctx, traceID)
+	end(countSpans(res), err)
+	return res, err
+}
+
+// WrapDependencyReader instruments the calls made to r, backend is the storage type serving it.
+func (t *Telemetry) WrapDependencyReader(backend string, r DependencyReader) DependencyReader {
+	if t == nil || r == nil {
+		return r
+	}
+	return &instrumentedDependencyReader{telemetry: t, backend: backend, reader: r}
+}
+
+var _ DependencyReader = (*instrumentedDependencyReader)(nil)
+
+type instrumentedDependencyReader struct {
+	telemetry *Telemetry
+	backend   string
+	reader    DependencyReader
+}
+
+func (r *instrumentedDependencyReader) GetDependencies(ctx context.Context, endTime time.Time, lookback time.Duration) ([]*DependencyLink, error) {
+	ctx, end := r.telemetry.start(ctx, r.backend, "GetDependencies")
+	res, err := r.reader.GetDependencies(ctx, endTime, lookback)
+	end(len(res), err)
+	return res, err
+}
+
+// WrapSavedQueryStore instruments the calls made to s, backend is the storage type serving it.
+func (t *Telemetry) WrapSavedQueryStore(backend string, s SavedQueryStore) SavedQueryStore {
+	if t == nil || s == nil {
+		return s
+	}
+	return &instrumentedSavedQueryStore{telemetry: t, backend: backend, store: s}
+}
+
+var _ SavedQueryStore = (*instrumentedSavedQueryStore)(nil)
+
+type instrumentedSavedQueryStore struct {
+	telemetry *Telemetry
+	backend   string
+	store     SavedQueryStore
+}
+
+func (s *instrumentedSavedQueryStore) CreateSavedQuery(ctx context.Context, query *v1alpha1.SavedQuery) error {
+	ctx, end := s.telemetry.start(ctx, s.backend, "CreateSavedQuery")
+	err := s.store.CreateSavedQuery(ctx, query)
+	end(1, err)
+	return err
+}
+
+func (s *instrumentedSavedQueryStore) GetSavedQuery(ctx context.Context, id string) (*v1alpha1.SavedQuery, error) {
+	ctx, end := s.telemetry.start(ctx, s.backend, "GetSavedQuery")
+	res, err := s.store.GetSavedQuery(ctx, id)
+	end(1, err)
+	return res, err
+}
+
+func (s *instrumentedSavedQueryStore) ListSavedQueries(ctx context.Context, filter *SavedQueryFilter) ([]*v1alpha1.SavedQuery, error) {
+	ctx, end := s.telemetry.start(ctx, s.backend, "ListSavedQueries")
+	res, err := s.store.ListSavedQueries(ctx, filter)
+	end(len(res), err)
+	return res, err
+}
+
+func (s *instrumentedSavedQueryStore) UpdateSavedQuery(ctx context.Context, query *v1alpha1.SavedQuery) error {
+	ctx, end := s.telemetry.start(ctx, s.backend, "UpdateSavedQuery")
+	err := s.store.UpdateSavedQuery(ctx, query)
+	end(1, err)
+	return err
+}
+
+func (s *instrumentedSavedQueryStore) DeleteSavedQuery(ctx context.Context, id string) error {
+	ctx, end := s.telemetry.start(ctx, s.backend, "DeleteSavedQuery")
+	err := s.store.DeleteSavedQuery(ctx, id)
+	end(1, err)
+	return err
+}
+
+func countSpans(td *v1_trace.TracesData) int {
+	count := 0
+	for _, rs := range td.GetResourceSpans() {
+		for _, ss := range rs.GetScopeSpans() {
+			count += len(ss.GetSpans())
+		}
+	}
+	return count
+}
+
+func countLogRecords(ld *v1_logs.LogsData) int {
+	count := 0
+	for _, rl := range ld.GetResourceLogs() {
+		for _, sl := range rl.GetScopeLogs() {
+			count += len(sl.GetLogRecords())
+		}
+	}
+	return count
+}
@@ -0,0 +1,255 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	v1_logs "go.opentelemetry.io/proto/otlp/logs/v1"
+	v1_resource "go.opentelemetry.io/proto/otlp/resource/v1"
+	v1_trace "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+type mockQuery struct {
+	err error
+}
+
+func (m *mockQuery) GetTrace(context.Context, string) (*v1_trace.TracesData, error) {
+	return &v1_trace.TracesData{ResourceSpans: []*v1_trace.ResourceSpans{
+		{ScopeSpans: []*v1_trace.ScopeSpans{{Spans: []*v1_trace.Span{{}, {}}}}},
+	}}, m.err
+}
+
+func (m *mockQuery) SearchTraces(context.Context, *TraceQueryParameters) (*v1alpha1.TracesData, error) {
+	return &v1alpha1.TracesData{Traces: []*v1alpha1.Trace{{}}}, m.err
+}
+
+func (m *mockQuery) FindTraces(context.Context, *TraceQueryParameters) (*v1_trace.TracesData, error) {
+	return &v1_trace.TracesData{}, m.err
+}
+
+func (m *mockQuery) SearchSpans(context.Context, *SpanQueryParameters) (*v1_trace.TracesData, error) {
+	return &v1_trace.TracesData{}, m.err
+}
+
+func (m *mockQuery) AggregateSpans(context.Context, *SpanQueryParameters, *AggregateParameters) ([]*AggregateGroup, error) {
+	return []*AggregateGroup{{}}, m.err
+}
+
+func (m *mockQuery) SearchLogs(context.Context) (*v1_logs.LogsData, error) {
+	return nil, m.err
+}
+
+func (m *mockQuery) GetLog(context.Context) (*v1_logs.LogsData, error) {
+	return nil, m.err
+}
+
+func (m *mockQuery) GetService(context.Context) ([]*v1_resource.Resource, error) {
+	return []*v1_resource.Resource{{}, {}, {}}, m.err
+}
+
+func (m *mockQuery) GetOperations(context.Context, *OperationsQueryParameters) ([]string, error) {
+	return []string{"op"}, m.err
+}
+
+func newTestTelemetry(t *testing.T) (*Telemetry, sdkmetric.Reader, *tracetest.SpanRecorder) {
+	reader := sdkmetric.NewManualReader()
+	recorder := tracetest.NewSpanRecorder()
+	settings := componenttest.NewNopTelemetrySettings()
+	settings.MeterProvider = sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	settings.TracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	telemetry, err := NewTelemetry(settings)
+	require.NoError(t, err)
+	return telemetry, reader, recorder
+}
+
+func collectMetrics(t *testing.T, reader sdkmetric.Reader) map[string]metricdata.Metrics {
+	rm, err := reader.Collect(context.Background())
+	require.NoError(t, err)
+	metrics := map[string]metricdata.Metrics{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m
+		}
+	}
+	return metrics
+}
+
+func TestInstrumentedQuery(t *testing.T) {
+	telemetry, reader, recorder := newTestTelemetry(t)
+	q := telemetry.WrapQuery("elasticsearch", &mockQuery{})
+
+	_, err := q.GetTrace(context.Background(), "01020304050607080807060504030201")
+	require.NoError(t, err)
+	_, err = q.GetService(context.Background())
+	require.NoError(t, err)
+	telemetry.RecordSearchTook(context.Background(), "trace_index", 12*time.Millisecond)
+
+	metrics := collectMetrics(t, reader)
+	requests := metrics["query/datasource_requests"].Data.(metricdata.Sum[int64])
+	assert.Equal(t, 2, len(requests.DataPoints))
+	_, ok := metrics["query/datasource_errors"]
+	assert.False(t, ok)
+
+	sizes := metrics["query/datasource_result_size"].Data.(metricdata.Histogram)
+	for _, dp := range sizes.DataPoints {
+		method, _ := dp.Attributes.Value(attribute.Key("method"))
+		switch method.AsString() {
+		case "GetTrace":
+			assert.Equal(t, float64(2), dp.Sum)
+		case "GetService":
+			assert.Equal(t, float64(3), dp.Sum)
+		}
+	}
+	took := metrics["query/elasticsearch_took"].Data.(metricdata.Histogram)
+	require.Equal(t, 1, len(took.DataPoints))
+	assert.Equal(t, float64(12), took.DataPoints[0].Sum)
+
+	spans := recorder.Ended()
+	require.Equal(t, 2, len(spans))
+	assert.Equal(t, "datasource/GetTrace", spans[0].Name())
+	assert.Equal(t, "datasource/GetService", spans[1].Name())
+}
+
+func TestInstrumentedQueryError(t *testing.T) {
+	telemetry, reader, recorder := newTestTelemetry(t)
+	q := telemetry.WrapQuery("clickhouse", &mockQuery{err: errors.New("connection refused")})
+
+	_, err := q.SearchTraces(context.Background(), &TraceQueryParameters{})
+	assert.Error(t, err)
+
+	metrics := collectMetrics(t, reader)
+	errs := metrics["query/datasource_errors"].Data.(metricdata.Sum[int64])
+	require.Equal(t, 1, len(errs.DataPoints))
+	assert.Equal(t, int64(1), errs.DataPoints[0].Value)
+	backend, _ := errs.DataPoints[0].Attributes.Value(attribute.Key("backend"))
+	assert.Equal(t, "clickhouse", backend.AsString())
+
+	spans := recorder.Ended()
+	require.Equal(t, 1, len(spans))
+	assert.Equal(t, "connection refused", spans[0].Status().Description)
+}
+
+func TestWrapQueryWithoutTelemetry(t *testing.T) {
+	var telemetry *Telemetry
+	q := &mockQuery{}
+	assert.Equal(t, Query(q), telemetry.WrapQuery("elasticsearch", q))
+}
+
+type mockLogQuery struct{}
+
+func (m *mockLogQuery) FindLogs(context.Context, *LogQueryParameters) ([]*LogEntry, error) {
+	return []*LogEntry{{}, {}}, nil
+}
+
+func (m *mockLogQuery) CountLogs(context.Context, *LogQueryParameters) ([]*LogSeries, error) {
+	return []*LogSeries{{}}, nil
+}
+
+func (m *mockLogQuery) LogLabelNames(context.Context, time.Time, time.Time) ([]string, error) {
+	return []string{"service.name"}, nil
+}
+
+func (m *mockLogQuery) LogLabelValues(context.Context, string, time.Time, time.Time) ([]string, error) {
+	return []string{"frontend"}, nil
+}
+
+func (m *mockLogQuery) LogStreams(context.Context, *LogQueryParameters) ([]map[string]string, error) {
+	return []map[string]string{{}}, nil
+}
+
+func (m *mockLogQuery) AggregateLogs(context.Context, *LogQueryParameters, *AggregateParameters) ([]*AggregateGroup, error) {
+	return []*AggregateGroup{{}}, nil
+}
+
+func TestInstrumentedLogQuery(t *testing.T) {
+	telemetry, reader, recorder := newTestTelemetry(t)
+	q := telemetry.WrapLogQuery("clickhouse", &mockLogQuery{})
+
+	entries, err := q.FindLogs(context.Background(), &LogQueryParameters{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(entries))
+
+	metrics := collectMetrics(t, reader)
+	sizes := metrics["query/datasource_result_size"].Data.(metricdata.Histogram)
+	require.Equal(t, 1, len(sizes.DataPoints))
+	assert.Equal(t, float64(2), sizes.DataPoints[0].Sum)
+
+	spans := recorder.Ended()
+	require.Equal(t, 1, len(spans))
+	assert.Equal(t, "datasource/FindLogs", spans[0].Name())
+
+	var nop *Telemetry
+	assert.Nil(t, nop.WrapLogQuery("clickhouse", nil))
+}
+
+type mockMetricQuery struct{}
+
+func (m *mockMetricQuery) SelectSeries(context.Context, *MetricQueryParameters) ([]*MetricSeries, error) {
+	return []*MetricSeries{{}, {}, {}}, nil
+}
+
+func (m *mockMetricQuery) SeriesLabels(context.Context, *MetricQueryParameters) ([]map[string]string, error) {
+	return []map[string]string{{}}, nil
+}
+
+func TestInstrumentedMetricQuery(t *testing.T) {
+	telemetry, reader, recorder := newTestTelemetry(t)
+	q := telemetry.WrapMetricQuery("clickhouse", &mockMetricQuery{})
+
+	series, err := q.SelectSeries(context.Background(), &MetricQueryParameters{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, len(series))
+
+	metrics := collectMetrics(t, reader)
+	sizes := metrics["query/datasource_result_size"].Data.(metricdata.Histogram)
+	require.Equal(t, 1, len(sizes.DataPoints))
+	assert.Equal(t, float64(3), sizes.DataPoints[0].Sum)
+
+	spans := recorder.Ended()
+	require.Equal(t, 1, len(spans))
+	assert.Equal(t, "datasource/SelectSeries", spans[0].Name())
+
+	var nop *Telemetry
+	assert.Nil(t, nop.WrapMetricQuery("clickhouse", nil))
+}
+
+type mockTraceArchive struct{}
+
+func (m *mockTraceArchive) ArchiveTrace(context.Context, string) (int, error) {
+	return 0, errors.New("trace not found")
+}
+
+func (m *mockTraceArchive) GetArchivedTrace(context.Context, string) (*v1_trace.TracesData, error) {
+	return &v1_trace.TracesData{}, nil
+}
+
+func TestInstrumentedTraceArchive(t *testing.T) {
+	telemetry, reader, recorder := newTestTelemetry(t)
+	a := telemetry.WrapTraceArchive("elasticsearch", &mockTraceArchive{})
+
+	_, err := a.ArchiveTrace(context.Background(), "abc")
+	require.Error(t, err)
+
+	metrics := collectMetrics(t, reader)
+	errs := metrics["query/datasource_errors"].Data.(metricdata.Sum[int64])
+	require.Equal(t, 1, len(errs.DataPoints))
+	assert.Equal(t, int64(1), errs.DataPoints[0].Value)
+
+	spans := recorder.Ended()
+	require.Equal(t, 1, len(spans))
+	assert.Equal(t, "datasource/ArchiveTrace", spans[0].Name())
+
+	var nop *Telemetry
+	assert.Nil(t, nop.WrapTraceArchive("elasticsearch", nil))
+}
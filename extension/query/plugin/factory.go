@@ -1,7 +1,10 @@
 package plugin
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"sort"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource/clickhouse"
@@ -28,6 +31,10 @@ type FactoryConfig struct {
 	TracingQuery         *StorageConfig
 	MetricsQuery         *StorageConfig
 	LoggingQuery         *StorageConfig
+	// SavedQueries is optional.
+	SavedQueries *SavedQueriesConfig
+	// Telemetry instruments the datasources, optional.
+	Telemetry *datasource.Telemetry
 	//TODO: add others
 }
 type Factory struct {
@@ -39,7 +46,7 @@ func (f *Factory) getFactoryOfType(factoryType string) (datasource.Factory, erro
 	switch factoryType {
 	//TODO: refactoring
 	case elasticsearchQueryType:
-		return es.NewFactory(f.sConfig.ElasticsearchStorage), nil
+		return es.NewFactory(f.sConfig.ElasticsearchStorage, f.sConfig.Telemetry), nil
 	case clickhouseQueryType:
 		return clickhouse.NewFactory(f.sConfig.ClickhouseStorage), nil
 	default:
@@ -60,6 +67,9 @@ func NewFactory(cfg *FactoryConfig) (*Factory, error) {
 	if cfg.MetricsQuery.StorageType != "" {
 		uniqueTypes = append(uniqueTypes, cfg.MetricsQuery.StorageType)
 	}
+	if t := cfg.SavedQueries.storageType(); t != "" && t != SavedQueriesStorageFile {
+		uniqueTypes = append(uniqueTypes, t)
+	}
 	f.factories = make(map[string]datasource.Factory)
 
 	for _, t := range uniqueTypes {
@@ -83,10 +93,123 @@ func (f *Factory) Initialize(logger *zap.Logger) error {
 	return nil
 }
 
+// StorageTypes returns the sorted storage types of the configured datasources.
+func (f *Factory) StorageTypes() []string {
+	types := make([]string, 0, len(f.factories))
+	for t := range f.factories {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// InitializeStorage initializes the datasource of the given storage type only.
+func (f *Factory) InitializeStorage(storageType string, logger *zap.Logger) error {
+	factory, ok := f.factories[storageType]
+	if !ok {
+		return fmt.Errorf("no %s backend registered", storageType)
+	}
+	return factory.Initialize(logger)
+}
+
+// CloseStorage closes the client of the datasource of the given storage type, so it can be initialized again.
+func (f *Factory) CloseStorage(storageType string) error {
+	factory, ok := f.factories[storageType]
+	if !ok {
+		return fmt.Errorf("no %s backend registered", storageType)
+	}
+	if closer, ok := factory.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Ping checks the connectivity of the datasource of the given storage type.
+func (f *Factory) Ping(ctx context.Context, storageType string) error {
+	factory, ok := f.factories[storageType]
+	if !ok {
+		return fmt.Errorf("no %s backend registered", storageType)
+	}
+	return factory.Ping(ctx)
+}
+
 func (f *Factory) CreateSpanQuery() (datasource.Query, error) {
 	factory, ok := f.factories[f.sConfig.TracingQuery.StorageType]
 	if !ok {
 		return nil, fmt.Errorf("no %s backend registered for span store", f.sConfig.TracingQuery.StorageType)
 	}
-	return factory.CreateSpanQuery()
+	q, err := factory.CreateSpanQuery()
+	if err != nil {
+		return nil, err
+	}
+	return f.sConfig.Telemetry.WrapQuery(f.sConfig.TracingQuery.StorageType, q), nil
+}
+
+// CreateLogQuery creates the query of the logging datasource.
+func (f *Factory) CreateLogQuery() (datasource.LogQuery, error) {
+	factory, ok := f.factories[f.sConfig.LoggingQuery.StorageType]
+	if !ok {
+		return nil, fmt.Errorf("no %s backend registered for log store", f.sConfig.LoggingQuery.StorageType)
+	}
+	q, err := factory.CreateLogQuery()
+	if err != nil {
+		return nil, err
+	}
+	return f.sConfig.Telemetry.WrapLogQuery(f.sConfig.LoggingQuery.StorageType, q), nil
+}
+
+// CreateMetricQuery creates the query of the metrics datasource.
+func (f *Factory) CreateMetricQuery() (datasource.MetricQuery, error) {
+	factory, ok := f.factories[f.sConfig.MetricsQuery.StorageType]
+	if !ok {
+		return nil, fmt.Errorf("no %s backend registered for metric store", f.sConfig.MetricsQuery.StorageType)
+	}
+	q, err := factory.CreateMetricQuery()
+	if err != nil {
+		return nil, err
+	}
+	return f.sConfig.Telemetry.WrapMetricQuery(f.sConfig.MetricsQuery.StorageType, q), nil
+}
+
+// CreateTraceArchive creates the archive of the tracing datasource, it returns
+// datasource.ErrArchiveNotConfigured when the datasource has no archive configured.
+func (f *Factory) CreateTraceArchive() (datasource.TraceArchive, error) {
+	factory, ok := f.factories[f.sConfig.TracingQuery.StorageType]
+	if !ok {
+		return nil, fmt.Errorf("no %s backend registered for span store", f.sConfig.TracingQuery.StorageType)
+	}
+	a, err := factory.CreateTraceArchive()
+	if err != nil {
+		return nil, err
+	}
+	return f.sConfig.Telemetry.WrapTraceArchive(f.sConfig.TracingQuery.StorageType, a), nil
+}
+
+// CreateDependencyReader creates the dependency reader of the tracing datasource, it returns
+// datasource.ErrDependenciesNotConfigured when the datasource doesn't store the dependency links.
+func (f *Factory) CreateDependencyReader() (datasource.DependencyReader, error) {
+	factory, ok := f.factories[f.sConfig.TracingQuery.StorageType]
+	if !ok {
+		return nil, fmt.Errorf("no %s backend registered for span store", f.sConfig.TracingQuery.StorageType)
+	}
+	r, err := factory.CreateDependencyReader()
+	if err != nil {
+		return nil, err
+	}
+	return f.sConfig.Telemetry.WrapDependencyReader(f.sConfig.TracingQuery.StorageType, r), nil
+}
+
+// CreateSavedQueryStore creates the store of the saved queries in a datasource, the file storage
+// type is created by file.NewSavedQueryStore.
+func (f *Factory) CreateSavedQueryStore() (datasource.SavedQueryStore, error) {
+	storageType := f.sConfig.SavedQueries.storageType()
+	factory, ok := f.factories[storageType]
+	if !ok {
+		return nil, fmt.Errorf("no %s backend registered for saved queries", storageType)
+	}
+	s, err := factory.CreateSavedQueryStore()
+	if err != nil {
+		return nil, err
+	}
+	return f.sConfig.Telemetry.WrapSavedQueryStore(storageType, s), nil
 }
@@ -2,8 +2,12 @@ package query
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"net"
 	"net/http"
+	"strings"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/handler"
 	"go.opentelemetry.io/collector/extension"
@@ -12,11 +16,14 @@ import (
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/api/v1alpha1"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource/file"
 	"github.com/soheilhy/cmux"
 	"go.opentelemetry.io/collector/component"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 // max msg size 20M
@@ -33,83 +40,285 @@ type queryServer struct {
 	httpConn         net.Listener
 	GatewayServerMux *runtime.ServeMux
 	settings         component.TelemetrySettings
+	queryService     *handler.QueryService
+	health           *healthChecker
+	telemetry        *serverTelemetry
+	dsTelemetry      *datasource.Telemetry
+	closeGRPCGateway context.CancelFunc
+	// savedQueryStore is the saved queries file, closed on shutdown.
+	savedQueryStore io.Closer
+	// alerts is nil without alert rules.
+	alerts *handler.AlertEngine
 }
 
 var _ extension.PipelineWatcher = (*queryServer)(nil)
 
 func (qs *queryServer) Start(_ context.Context, host component.Host) error {
+	// the alert rules are checked before the servers and listeners are started, so none is left open
+	if len(qs.config.Alerting.Rules) > 0 {
+		alerts, err := handler.NewAlertEngine(&qs.config.Alerting, qs.queryService, qs.config.Limits, host, qs.settings)
+		if err != nil {
+			return fmt.Errorf("failed to create alert rules: %w", err)
+		}
+		qs.alerts = alerts
+	}
 	closeGRPCGateway, err := qs.Server()
 	if err != nil {
-		closeGRPCGateway()
+		if closeGRPCGateway != nil {
+			closeGRPCGateway()
+		}
 		return err
 	}
+	qs.closeGRPCGateway = closeGRPCGateway
+	qs.health.start()
+	if qs.alerts != nil {
+		qs.alerts.Start()
+	}
 
-	go func() {
-		err = qs.grpcServer.Serve(qs.grpcConn)
-		if err != nil {
-			zap.S().Fatalf("grpc listener failed: %v", err)
-		}
-	}()
-
-	go func() {
-		err = qs.httpServer.Serve(qs.httpConn)
-		if err != nil {
-			zap.S().Fatalf("http listener failed: %v", err)
-		}
-	}()
-
-	go func() {
-		err = qs.cmux.Serve()
-		if err != nil {
-			zap.S().Fatalf("grpc gateway cmux listener failed: %v", err)
-		}
-	}()
+	go qs.serve("grpc listener", func() error { return qs.grpcServer.Serve(qs.grpcConn) })
+	go qs.serve("http listener", func() error { return qs.httpServer.Serve(qs.httpConn) })
+	go qs.serve("grpc gateway cmux listener", qs.cmux.Serve)
 	return nil
 }
 
+// serve runs a listener, a failure is reported through the health status instead of exiting the collector.
+func (qs *queryServer) serve(name string, serve func() error) {
+	err := serve()
+	if err == nil || errors.Is(err, grpc.ErrServerStopped) || errors.Is(err, http.ErrServerClosed) ||
+		errors.Is(err, cmux.ErrListenerClosed) || errors.Is(err, cmux.ErrServerClosed) || errors.Is(err, net.ErrClosed) {
+		return
+	}
+	qs.logger.Error("Query server listener failed", zap.String("listener", name), zap.Error(err))
+	qs.health.setNotServing()
+}
+
 func (qs *queryServer) Shutdown(context.Context) error {
-	qs.grpcServer.Stop()
-	err := qs.httpServer.Close()
-	if err != nil {
-		return err
+	if qs.alerts != nil {
+		qs.alerts.Stop()
+	}
+	if qs.health != nil {
+		qs.health.stop()
+	}
+	if qs.closeGRPCGateway != nil {
+		qs.closeGRPCGateway()
+	}
+	if qs.grpcServer != nil {
+		qs.grpcServer.Stop()
+	}
+	if qs.httpServer != nil {
+		if err := qs.httpServer.Close(); err != nil {
+			return err
+		}
+	}
+	if qs.savedQueryStore != nil {
+		return qs.savedQueryStore.Close()
 	}
 	return nil
 }
 
 func (qs *queryServer) Ready() error {
+	qs.health.setPipelineReady(true)
 	return nil
 }
 
 func (qs *queryServer) NotReady() error {
+	qs.health.setPipelineReady(false)
 	return nil
 }
 
-func NewQueryServer(config *Config, settings component.TelemetrySettings) *queryServer {
+func NewQueryServer(config *Config, settings component.TelemetrySettings) (*queryServer, error) {
+	telemetry, err := newServerTelemetry(settings)
+	if err != nil {
+		return nil, err
+	}
+	dsTelemetry, err := datasource.NewTelemetry(settings)
+	if err != nil {
+		return nil, err
+	}
 	qs := &queryServer{
-		config:   config,
-		logger:   settings.Logger,
-		settings: settings,
+		config:       config,
+		logger:       settings.Logger,
+		settings:     settings,
+		queryService: &handler.QueryService{},
+		health:       newHealthChecker(settings.Logger, config.HealthCheck, v1alpha1.QueryService_ServiceDesc.ServiceName),
+		telemetry:    telemetry,
+		dsTelemetry:  dsTelemetry,
 	}
-	return qs
+	return qs, nil
 }
 
-func (qs *queryServer) initFactories() (*plugin.Factory, error) {
+// initFactories creates the datasource factories and registers them to the health checker,
+// which initializes them in the background so an unreachable backend doesn't fail the collector.
+func (qs *queryServer) initFactories() error {
 	factories, err := plugin.NewFactory(&plugin.FactoryConfig{
 		ElasticsearchStorage: qs.config.Storage.ElasticsearchType,
 		ClickhouseStorage:    qs.config.Storage.ClickhouseType,
 		TracingQuery:         qs.config.TracingQuery,
 		MetricsQuery:         qs.config.MetricsQuery,
 		LoggingQuery:         qs.config.LoggingQuery,
+		SavedQueries:         qs.config.SavedQueries,
+		Telemetry:            qs.dsTelemetry,
 	})
 	if err != nil {
-		qs.logger.Fatal("Failed init factories", zap.Error(err))
-		return nil, err
+		return fmt.Errorf("failed to init factories: %w", err)
 	}
-	if err := factories.Initialize(qs.logger); err != nil {
-		qs.logger.Fatal("Failed to init storage factory", zap.Error(err))
-		return nil, err
+
+	if qs.savedQueriesStorageType() == plugin.SavedQueriesStorageFile {
+		if qs.config.SavedQueries.Path == "" {
+			return errors.New("saved_queries.path is required with the file storage type")
+		}
+		store, err := file.NewSavedQueryStore(qs.config.SavedQueries.Path)
+		if err != nil {
+			return fmt.Errorf("failed to open saved queries file: %w", err)
+		}
+		qs.savedQueryStore = store
+		savedQuerySvc := qs.dsTelemetry.WrapSavedQueryStore(plugin.SavedQueriesStorageFile, store)
+		qs.queryService.Update(func(s *handler.QueryService) {
+			s.SavedQuerySvc = savedQuerySvc
+		})
+	}
+
+	for _, storageType := range factories.StorageTypes() {
+		storageType := storageType
+		qs.health.register(storageType, func() error {
+			if err := factories.InitializeStorage(storageType, qs.logger); err != nil {
+				return err
+			}
+			if err := qs.createQueries(factories, storageType); err != nil {
+				// the client is created again on the next attempt
+				if closeErr := factories.CloseStorage(storageType); closeErr != nil {
+					qs.logger.Warn("failed to close the datasource", zap.String("storage_type", storageType), zap.Error(closeErr))
+				}
+				return err
+			}
+			return nil
+		}, func(ctx context.Context) error {
+			return factories.Ping(ctx, storageType)
+		})
 	}
-	return factories, nil
+	return nil
+}
+
+// createQueries creates the queries served by the initialized datasource of the given storage type.
+func (qs *queryServer) createQueries(factories *plugin.Factory, storageType string) error {
+	var (
+		tracingQuerySvc datasource.Query
+		traceArchiveSvc datasource.TraceArchive
+		dependencySvc   datasource.DependencyReader
+		loggingQuerySvc datasource.LogQuery
+		metricsQuerySvc datasource.MetricQuery
+		savedQuerySvc   datasource.SavedQueryStore
+		err             error
+	)
+	if storageType == qs.config.TracingQuery.StorageType {
+		if tracingQuerySvc, err = factories.CreateSpanQuery(); err != nil {
+			return fmt.Errorf("failed to create span reader: %w", err)
+		}
+		traceArchiveSvc, err = factories.CreateTraceArchive()
+		if err != nil && !errors.Is(err, datasource.ErrArchiveNotConfigured) {
+			return fmt.Errorf("failed to create trace archive: %w", err)
+		}
+		dependencySvc, err = factories.CreateDependencyReader()
+		if err != nil && !errors.Is(err, datasource.ErrDependenciesNotConfigured) {
+			return fmt.Errorf("failed to create dependency reader: %w", err)
+		}
+	}
+	if storageType == qs.config.LoggingQuery.StorageType {
+		if loggingQuerySvc, err = factories.CreateLogQuery(); err != nil {
+			return fmt.Errorf("failed to create log reader: %w", err)
+		}
+	}
+	// the metrics datasource only serves the Prometheus API
+	if storageType == qs.config.MetricsQuery.StorageType && qs.config.APIs.Prometheus {
+		if metricsQuerySvc, err = factories.CreateMetricQuery(); err != nil {
+			return fmt.Errorf("failed to create metric reader: %w", err)
+		}
+	}
+	if storageType == qs.savedQueriesStorageType() {
+		if savedQuerySvc, err = factories.CreateSavedQueryStore(); err != nil {
+			return fmt.Errorf("failed to create saved queries store: %w", err)
+		}
+	}
+
+	qs.queryService.Update(func(s *handler.QueryService) {
+		if storageType == qs.config.TracingQuery.StorageType {
+			s.TracingQuerySvc = tracingQuerySvc
+			s.TraceArchiveSvc = traceArchiveSvc
+			s.DependencySvc = dependencySvc
+		}
+		if loggingQuerySvc != nil {
+			s.LoggingQuerySvc = loggingQuerySvc
+		}
+		if metricsQuerySvc != nil {
+			s.MetricsQuerySvc = metricsQuerySvc
+		}
+		if savedQuerySvc != nil {
+			s.SavedQuerySvc = savedQuerySvc
+		}
+	})
+	return nil
+}
+
+// tracingAvailable returns a gRPC Unavailable error while the tracing datasource is down.
+func (qs *queryServer) tracingAvailable() error {
+	return qs.health.available(qs.config.TracingQuery.StorageType)
+}
+
+// loggingAvailable returns a gRPC Unavailable error while the logging datasource is down.
+func (qs *queryServer) loggingAvailable() error {
+	return qs.health.available(qs.config.LoggingQuery.StorageType)
+}
+
+// metricsAvailable returns a gRPC Unavailable error while the metrics datasource is down.
+func (qs *queryServer) metricsAvailable() error {
+	return qs.health.available(qs.config.MetricsQuery.StorageType)
+}
+
+// savedQueriesStorageType returns the storage type of the saved queries, empty if they are disabled.
+func (qs *queryServer) savedQueriesStorageType() string {
+	if qs.config.SavedQueries == nil {
+		return ""
+	}
+	return qs.config.SavedQueries.StorageType
+}
+
+// savedQueryMethods are the QueryService methods of the saved queries, served by their storage.
+var savedQueryMethods = map[string]bool{
+	"CreateSavedQuery": true,
+	"GetSavedQuery":    true,
+	"ListSavedQueries": true,
+	"UpdateSavedQuery": true,
+	"DeleteSavedQuery": true,
+}
+
+// logMethods are the QueryService methods of the logs, served by the logging datasource.
+var logMethods = map[string]bool{
+	"SearchLogs": true,
+}
+
+// requiredDatasource returns the storage type a gRPC method queries, false if it doesn't need one.
+func (qs *queryServer) requiredDatasource(fullMethod string, req interface{}) (string, bool) {
+	if isServiceMethod(fullMethod, v1alpha1.QueryService_ServiceDesc.ServiceName) {
+		method := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+		// a saved query runs on the tracing or logging datasource, checked by the handler
+		if method == "RunSavedQuery" {
+			return "", false
+		}
+		if savedQueryMethods[method] {
+			storageType := qs.savedQueriesStorageType()
+			return storageType, storageType != "" && storageType != plugin.SavedQueriesStorageFile
+		}
+		if method == "Aggregate" {
+			if request, ok := req.(*v1alpha1.AggregateRequest); ok && request.GetLogQuery() != nil {
+				return qs.config.LoggingQuery.StorageType, true
+			}
+		}
+		if logMethods[method] {
+			return qs.config.LoggingQuery.StorageType, true
+		}
+		return qs.config.TracingQuery.StorageType, true
+	}
+	return "", false
 }
 
 func (qs *queryServer) Server() (context.CancelFunc, error) {
@@ -121,7 +330,10 @@ func (qs *queryServer) Server() (context.CancelFunc, error) {
 	marshaller := &runtime.JSONPb{}
 	marshaller.UseProtoNames = false
 	marshaller.EmitUnpopulated = true
-	qs.GatewayServerMux = runtime.NewServeMux(runtime.WithMarshalerOption(runtime.MIMEWildcard, marshaller))
+	qs.GatewayServerMux = runtime.NewServeMux(
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, marshaller),
+		runtime.WithMetadata(qs.telemetry.gatewayMetadata),
+	)
 
 	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
 	extendMsgSizeOpt := grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxMsgSize))
@@ -129,20 +341,12 @@ func (qs *queryServer) Server() (context.CancelFunc, error) {
 
 	ctx, closeGRPCGateway := context.WithCancel(context.Background())
 
-	factories, err := qs.initFactories()
-	if err != nil {
+	if err = qs.initFactories(); err != nil {
 		return closeGRPCGateway, err
 	}
-	tracingQuerySvc, err := factories.CreateSpanQuery()
-	if err != nil {
-		qs.logger.Fatal("Failed to create span reader", zap.Error(err))
-	}
 
-	qSvc := &handler.QueryService{
-		TracingQuerySvc: tracingQuerySvc,
-	}
-
-	v1alpha1.RegisterQueryServiceServer(qs.grpcServer, &handler.Handler{QueryService: qSvc})
+	healthpb.RegisterHealthServer(qs.grpcServer, qs.health.grpcHealth)
+	v1alpha1.RegisterQueryServiceServer(qs.grpcServer, &handler.Handler{QueryService: qs.queryService, Limits: qs.config.Limits})
 	err = v1alpha1.RegisterQueryServiceHandlerFromEndpoint(ctx, qs.GatewayServerMux, qs.config.Protocols.Http.Endpoint, extraOpt)
 	if err != nil {
 		closeGRPCGateway()
@@ -150,6 +354,47 @@ func (qs *queryServer) Server() (context.CancelFunc, error) {
 	}
 
 	qs.router = mux.NewRouter()
+	qs.router.Use(qs.telemetry.httpMiddleware)
+	qs.router.HandleFunc("/healthz", qs.health.healthzHandler).Methods(http.MethodGet)
+	qs.router.HandleFunc("/readyz", qs.health.readyzHandler).Methods(http.MethodGet)
+	traceFileHandler := &handler.TraceFileHandler{
+		QueryService: qs.queryService,
+		Limits:       qs.config.Limits,
+		Available:    qs.tracingAvailable,
+	}
+	traceFileHandler.RegisterRoutes(qs.router)
+	if qs.config.APIs.Zipkin {
+		zipkinHandler := &handler.ZipkinHandler{
+			QueryService: qs.queryService,
+			Limits:       qs.config.Limits,
+			Available:    qs.tracingAvailable,
+		}
+		zipkinHandler.RegisterRoutes(qs.router)
+	}
+	if qs.config.APIs.Tempo {
+		tempoHandler := &handler.TempoHandler{
+			QueryService: qs.queryService,
+			Limits:       qs.config.Limits,
+			Available:    qs.tracingAvailable,
+		}
+		tempoHandler.RegisterRoutes(qs.router)
+	}
+	if qs.config.APIs.Loki {
+		lokiHandler := &handler.LokiHandler{
+			QueryService: qs.queryService,
+			Limits:       qs.config.Limits,
+			Available:    qs.loggingAvailable,
+		}
+		lokiHandler.RegisterRoutes(qs.router)
+	}
+	if qs.config.APIs.Prometheus {
+		prometheusHandler := &handler.PrometheusHandler{
+			QueryService: qs.queryService,
+			Limits:       qs.config.Limits,
+			Available:    qs.metricsAvailable,
+		}
+		prometheusHandler.RegisterRoutes(qs.router)
+	}
 	qs.router.PathPrefix("/").Handler(qs.GatewayServerMux)
 	qs.httpServer.Handler = qs.router
 	qs.cmux = cmux.New(qs.httpConn)
@@ -160,7 +405,14 @@ func (qs *queryServer) Server() (context.CancelFunc, error) {
 
 func (qs *queryServer) initListener() error {
 	// Create protocol servers
-	qs.grpcServer = grpc.NewServer(grpc.MaxSendMsgSize(maxMsgSize))
+	qs.grpcServer = grpc.NewServer(
+		grpc.MaxSendMsgSize(maxMsgSize),
+		grpc.ChainUnaryInterceptor(
+			qs.telemetry.unaryInterceptor(),
+			qs.health.unaryInterceptor(qs.requiredDatasource),
+			qs.config.Limits.UnaryInterceptor(),
+		),
+	)
 	qs.httpServer = &http.Server{Addr: qs.config.Http.Endpoint}
 
 	var err error
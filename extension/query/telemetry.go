@@ -0,0 +1,177 @@
+package query
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/query/plugin/datasource"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric/instrument"
+	"go.opentelemetry.io/otel/metric/unit"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	rpcMethodKey      = attribute.Key("rpc.method")
+	rpcStatusCodeKey  = attribute.Key("rpc.grpc.status_code")
+	httpMethodKey     = attribute.Key("http.method")
+	httpRouteKey      = attribute.Key("http.route")
+	httpStatusCodeKey = attribute.Key("http.status_code")
+)
+
+// serverTelemetry records metrics and spans for the gRPC and HTTP requests served by the query server.
+type serverTelemetry struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+
+	rpcRequests  instrument.Int64Counter
+	rpcDuration  instrument.Float64Histogram
+	httpRequests instrument.Int64Counter
+	httpDuration instrument.Float64Histogram
+}
+
+func newServerTelemetry(settings component.TelemetrySettings) (*serverTelemetry, error) {
+	meter := settings.MeterProvider.Meter(datasource.InstrumentationName)
+	st := &serverTelemetry{
+		tracer:     settings.TracerProvider.Tracer(datasource.InstrumentationName),
+		propagator: propagation.TraceContext{},
+	}
+
+	var err error
+	if st.rpcRequests, err = meter.Int64Counter("query/rpc_requests",
+		instrument.WithDescription("Number of gRPC requests served, by method and status code."),
+		instrument.WithUnit(unit.Dimensionless)); err != nil {
+		return nil, err
+	}
+	if st.rpcDuration, err = meter.Float64Histogram("query/rpc_duration",
+		instrument.WithDescription("Latency of the gRPC requests."),
+		instrument.WithUnit(unit.Milliseconds)); err != nil {
+		return nil, err
+	}
+	if st.httpRequests, err = meter.Int64Counter("query/http_requests",
+		instrument.WithDescription("Number of HTTP requests served, by route and status code."),
+		instrument.WithUnit(unit.Dimensionless)); err != nil {
+		return nil, err
+	}
+	if st.httpDuration, err = meter.Float64Histogram("query/http_duration",
+		instrument.WithDescription("Latency of the HTTP requests."),
+		instrument.WithUnit(unit.Milliseconds)); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// unaryInterceptor creates a server span for each gRPC call, continuing the trace
+// propagated by the HTTP gateway or the client, and records the request metrics.
+func (st *serverTelemetry) unaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			ctx = st.propagator.Extract(ctx, metadataCarrier(md))
+		}
+		ctx, span := st.tracer.Start(ctx, info.FullMethod, trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(rpcMethodKey.String(info.FullMethod)))
+		defer span.End()
+
+		start := time.Now()
+		res, err := handler(ctx, req)
+		code := status.Code(err)
+
+		attrs := []attribute.KeyValue{rpcMethodKey.String(info.FullMethod), rpcStatusCodeKey.String(code.String())}
+		st.rpcRequests.Add(ctx, 1, attrs...)
+		st.rpcDuration.Record(ctx, sinceMillis(start), attrs...)
+		span.SetAttributes(rpcStatusCodeKey.String(code.String()))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return res, err
+	}
+}
+
+// httpMiddleware creates a server span for each HTTP request and records the request metrics.
+func (st *serverTelemetry) httpMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := r.URL.Path
+		if current := mux.CurrentRoute(r); current != nil {
+			if tpl, err := current.GetPathTemplate(); err == nil {
+				route = tpl
+			}
+		}
+		ctx := st.propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := st.tracer.Start(ctx, r.Method+" "+route, trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(httpMethodKey.String(r.Method), httpRouteKey.String(route)))
+		defer span.End()
+
+		start := time.Now()
+		rw := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rw, r.WithContext(ctx))
+
+		attrs := []attribute.KeyValue{httpMethodKey.String(r.Method), httpRouteKey.String(route), httpStatusCodeKey.Int(rw.status)}
+		st.httpRequests.Add(ctx, 1, attrs...)
+		st.httpDuration.Record(ctx, sinceMillis(start), attrs...)
+		span.SetAttributes(httpStatusCodeKey.Int(rw.status))
+		if rw.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, strconv.Itoa(rw.status))
+		}
+	})
+}
+
+// gatewayMetadata propagates the HTTP request span to the gRPC call made by the gateway.
+func (st *serverTelemetry) gatewayMetadata(ctx context.Context, _ *http.Request) metadata.MD {
+	md := metadata.MD{}
+	st.propagator.Inject(ctx, metadataCarrier(md))
+	return md
+}
+
+func sinceMillis(start time.Time) float64 {
+	return float64(time.Since(start).Microseconds()) / 1e3
+}
+
+// metadataCarrier adapts gRPC metadata to a propagation.TextMapCarrier.
+type metadataCarrier metadata.MD
+
+func (mc metadataCarrier) Get(key string) string {
+	values := metadata.MD(mc).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (mc metadataCarrier) Set(key, value string) {
+	metadata.MD(mc).Set(key, value)
+}
+
+func (mc metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(mc))
+	for k := range mc {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// statusRecorder captures the status code written by the wrapped handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sr *statusRecorder) WriteHeader(status int) {
+	sr.status = status
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+func (sr *statusRecorder) Flush() {
+	if f, ok := sr.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
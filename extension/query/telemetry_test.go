@@ -0,0 +1,57 @@
+package query
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestServerTelemetryPropagatesGatewaySpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	settings := componenttest.NewNopTelemetrySettings()
+	settings.TracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	st, err := newServerTelemetry(settings)
+	require.NoError(t, err)
+
+	var md metadata.MD
+	router := mux.NewRouter()
+	router.Use(st.httpMiddleware)
+	router.HandleFunc("/api/v1/traces/{traceID}", func(w http.ResponseWriter, r *http.Request) {
+		md = st.gatewayMetadata(r.Context(), r)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/traces/0102", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	interceptor := st.unaryInterceptor()
+	var rpcSpan trace.SpanContext
+	_, err = interceptor(metadata.NewIncomingContext(context.Background(), md), nil,
+		&grpc.UnaryServerInfo{FullMethod: "/query.Service/GetTrace"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			rpcSpan = trace.SpanContextFromContext(ctx)
+			return nil, status.Error(codes.Unavailable, "datasource is unavailable")
+		})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	spans := recorder.Ended()
+	require.Equal(t, 2, len(spans))
+	httpSpan, grpcSpan := spans[0], spans[1]
+	assert.Equal(t, "GET /api/v1/traces/{traceID}", httpSpan.Name())
+	assert.Equal(t, "/query.Service/GetTrace", grpcSpan.Name())
+	assert.Equal(t, httpSpan.SpanContext().TraceID(), rpcSpan.TraceID())
+	assert.Equal(t, httpSpan.SpanContext().SpanID(), grpcSpan.Parent().SpanID())
+}